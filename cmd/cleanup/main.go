@@ -8,6 +8,7 @@ import (
 
 	"lingua-ai/internal/config"
 	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
 
 	"go.uber.org/zap"
 )
@@ -64,68 +65,93 @@ func cleanupUserMessages(ctx context.Context, store store.Store, userID int64, k
 		return fmt.Errorf("пользователь не найден: %w", err)
 	}
 
+	_, err = cleanupMessages(ctx, store, user, keepCount, dryRun, logger)
+	return err
+}
+
+// cleanupMessages удаляет старые сообщения пользователя сверх keepCount.
+// Возвращает количество удаленных (в режиме dry run — подлежащих удалению) сообщений.
+func cleanupMessages(ctx context.Context, store store.Store, user *models.User, keepCount int, dryRun bool, logger *zap.Logger) (int, error) {
 	// Получаем текущее количество сообщений
-	currentCount, err := store.Message().GetMessageCount(ctx, userID)
+	currentCount, err := store.Message().GetMessageCount(ctx, user.ID)
 	if err != nil {
-		return fmt.Errorf("ошибка получения количества сообщений: %w", err)
+		return 0, fmt.Errorf("ошибка получения количества сообщений: %w", err)
 	}
 
 	toDelete := currentCount - keepCount
 	if toDelete <= 0 {
 		logger.Info("Нет сообщений для удаления",
-			zap.Int64("user_id", userID),
+			zap.Int64("user_id", user.ID),
 			zap.String("username", user.Username),
 			zap.Int("current_count", currentCount),
 			zap.Int("keep_count", keepCount))
-		return nil
+		return 0, nil
 	}
 
 	if dryRun {
 		logger.Info("DRY RUN: Будет удалено сообщений",
-			zap.Int64("user_id", userID),
+			zap.Int64("user_id", user.ID),
 			zap.String("username", user.Username),
 			zap.Int("current_count", currentCount),
 			zap.Int("to_delete", toDelete),
 			zap.Int("keep_count", keepCount))
-		return nil
+		return toDelete, nil
 	}
 
 	// Выполняем очистку
-	err = store.Message().CleanupOldMessages(ctx, userID, keepCount)
+	err = store.Message().CleanupOldMessages(ctx, user.ID, keepCount)
 	if err != nil {
-		return fmt.Errorf("ошибка очистки сообщений пользователя %d: %w", userID, err)
+		return 0, fmt.Errorf("ошибка очистки сообщений пользователя %d: %w", user.ID, err)
 	}
 
 	logger.Info("Очищены сообщения пользователя",
-		zap.Int64("user_id", userID),
+		zap.Int64("user_id", user.ID),
 		zap.String("username", user.Username),
 		zap.Int("deleted_count", toDelete),
 		zap.Int("keep_count", keepCount))
 
-	return nil
+	return toDelete, nil
 }
 
 func cleanupAllUsersMessages(ctx context.Context, store store.Store, keepCount int, dryRun bool, logger *zap.Logger) error {
-	// Получаем список всех пользователей с сообщениями
-	// Для этого нам нужно добавить метод получения пользователей с сообщениями
-	// Пока используем простой подход - получаем всех пользователей
-
 	logger.Info("Начинаем массовую очистку сообщений",
 		zap.Int("keep_count", keepCount),
 		zap.Bool("dry_run", dryRun))
 
-	// Здесь нужно было бы получить список всех пользователей
-	// Но поскольку у нас нет такого метода в UserRepository,
-	// сделаем это через прямой SQL запрос или добавим метод
+	users, err := store.User().GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка получения списка пользователей: %w", err)
+	}
 
 	totalDeleted := 0
 	processedUsers := 0
+	failedUsers := 0
+
+	for _, user := range users {
+		// Ошибка одного пользователя не прерывает очистку остальных
+		deleted, err := cleanupMessages(ctx, store, user, keepCount, dryRun, logger)
+		if err != nil {
+			failedUsers++
+			logger.Error("Ошибка очистки сообщений пользователя",
+				zap.Int64("user_id", user.ID),
+				zap.Error(err))
+			continue
+		}
+
+		totalDeleted += deleted
+		processedUsers++
+	}
 
-	// Для демонстрации - обработаем несколько пользователей
-	// В реальности здесь должен быть цикл по всем пользователям
 	logger.Info("Массовая очистка завершена",
+		zap.Int("total_users", len(users)),
 		zap.Int("processed_users", processedUsers),
-		zap.Int("total_deleted", totalDeleted))
+		zap.Int("failed_users", failedUsers),
+		zap.Int("total_deleted", totalDeleted),
+		zap.Bool("dry_run", dryRun))
+
+	if failedUsers > 0 {
+		return fmt.Errorf("не удалось очистить сообщения %d из %d пользователей", failedUsers, len(users))
+	}
 
 	return nil
 }
@@ -9,10 +9,14 @@ import (
 	"syscall"
 	"time"
 
+	"lingua-ai/internal/admin"
 	"lingua-ai/internal/ai"
+	"lingua-ai/internal/backup"
 	"lingua-ai/internal/bot"
+	"lingua-ai/internal/challenge"
 	"lingua-ai/internal/config"
 	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/logging"
 	"lingua-ai/internal/message"
 	"lingua-ai/internal/metrics"
 	"lingua-ai/internal/migrations"
@@ -31,8 +35,15 @@ import (
 )
 
 func main() {
+	// Загрузка конфигурации: от нее зависят файлы логов, поэтому до логгера
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Ошибка загрузки конфигурации: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Инициализация логгера
-	logger, err := initLogger()
+	logger, err := initLogger(cfg.Log)
 	if err != nil {
 		fmt.Printf("Ошибка инициализации логгера: %v\n", err)
 		os.Exit(1)
@@ -41,12 +52,6 @@ func main() {
 
 	logger.Info("запуск приложения Lingua AI")
 
-	// Загрузка конфигурации
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Fatal("ошибка загрузки конфигурации", zap.Error(err))
-	}
-
 	// Инициализация базы данных
 	store, err := store.NewStore(cfg, logger)
 	if err != nil {
@@ -64,6 +69,9 @@ func main() {
 		zap.String("provider", cfg.AI.Provider),
 		zap.String("model", cfg.AI.Model))
 
+	// Метрики нужны AI клиенту для учета повторных запросов
+	metricsSystem := metrics.New(logger)
+
 	aiClient, err := ai.NewAIClient(&ai.AIConfig{
 		Provider:    cfg.AI.Provider,
 		Model:       cfg.AI.Model,
@@ -73,27 +81,52 @@ func main() {
 			APIKey:  cfg.AI.DeepSeek.APIKey,
 			BaseURL: cfg.AI.DeepSeek.BaseURL,
 		},
+		DebugPrompts:   cfg.AI.DebugPrompts,
+		MaxRetries:     cfg.AI.MaxRetries,
+		RetryBaseDelay: time.Duration(cfg.AI.RetryBaseDelayMs) * time.Millisecond,
+		RetryMetrics:   metricsSystem,
 	}, logger)
 	if err != nil {
 		logger.Fatal("ошибка создания AI клиента", zap.Error(err))
 	}
 
+	// При недоступности провайдера бот переключается на занятия без AI и сам возвращается в чат
+	aiBreaker := ai.NewCircuitBreaker(aiClient, cfg.AI.BreakerFailures,
+		time.Duration(cfg.AI.BreakerCooldownSec)*time.Second, logger)
+	aiClient = aiBreaker
+
 	// Инициализация Whisper клиента
 	whisperClient := whisper.NewClient(cfg.Whisper.APIURL, logger)
+	whisperClient.SetDurationLimits(
+		time.Duration(cfg.Whisper.MaxDurationSec)*time.Second,
+		time.Duration(cfg.Whisper.PremiumMaxDurationSec)*time.Second)
 
 	// Инициализация TTS сервиса
 	var ttsService tts.TTSService
+	var ttsChain *tts.ChainService
 	if cfg.TTS.Enabled {
-		ttsService = tts.NewPiperService(logger, cfg.TTS.BaseURL)
-		logger.Info("Piper TTS сервис инициализирован")
+		// Если основной сервис недоступен, текст озвучивают запасные по порядку
+		services := []tts.NamedService{{Name: "piper", Service: tts.NewPiperService(logger, cfg.TTS.BaseURL)}}
+		for i, url := range cfg.TTS.FallbackURLs {
+			services = append(services, tts.NamedService{
+				Name:    fmt.Sprintf("fallback_%d", i+1),
+				Service: tts.NewPiperService(logger, url),
+			})
+		}
+		ttsChain = tts.NewChainService(logger, services...)
+		ttsService = ttsChain
+		logger.Info("Piper TTS сервис инициализирован", zap.Int("fallbacks", len(cfg.TTS.FallbackURLs)))
 	} else {
 		logger.Info("TTS сервис отключен")
 	}
 
 	// Инициализация сервисов
 	userService := user.NewService(store, logger)
+	if err := userService.SetDefaultLevel(cfg.App.DefaultLevel); err != nil {
+		logger.Fatal("некорректный уровень новых пользователей", zap.Error(err))
+	}
 	messageService := message.NewService(store, logger)
-	flashcardService := flashcards.NewService(store.Flashcard(), logger)
+	flashcardService := flashcards.NewService(store.Flashcard(), spacedRepetitionConfig(cfg.App), logger)
 
 	// Инициализация YooKassa клиента
 	yukassaClient := payment.NewYukassaClient(cfg.YooKassa.ShopID, cfg.YooKassa.SecretKey, cfg.YooKassa.TestMode, logger)
@@ -101,17 +134,57 @@ func main() {
 
 	// Инициализация premium service
 	premiumService := premium.NewService(userService, store.Payment(), yukassaClient, logger)
+	resetLoc, err := time.LoadLocation(cfg.App.DailyResetTZ)
+	if err != nil {
+		logger.Fatal("ошибка загрузки часового пояса сброса лимитов", zap.Error(err))
+	}
+	premiumService.SetResetLocation(resetLoc)
+	if err := premiumService.ValidatePlans(cfg.YooKassa.TestMode); err != nil {
+		logger.Fatal("некорректные цены премиум-планов", zap.Error(err))
+	}
 
 	// Инициализация referral сервиса
 	referralService := referral.NewService(store.Referral(), store.User(), logger)
+	referralService.SetRewardPolicy(referral.RewardPolicy{
+		ReferralsPerReward: referral.DefaultRewardPolicy.ReferralsPerReward,
+		MaxRewards:         cfg.App.ReferralMaxRewards,
+		MinMessages:        cfg.App.ReferralMinMessages,
+		MinActiveDays:      cfg.App.ReferralMinActiveDays,
+	})
 
 	// Инициализация метрик
-	metricsSystem := metrics.New(logger)
+	metricsSystem.SetActiveUsersConfig(resetLoc, cfg.App.ActiveUsersLimit)
 	userMetrics := metricsSystem
 	aiMetrics := metricsSystem
 
 	// Инициализация HTTP handler для метрик
+	metricsSystem.SetAIStatus(aiBreaker.Healthy)
+	if ttsChain != nil {
+		ttsChain.SetMetrics(metricsSystem)
+	}
 	metricsHandler := metrics.NewHandler(metricsSystem, logger)
+	metricsHandler.SetHealthCheck("database", store.DB().Ping, true)
+	metricsHandler.SetHealthCheck("ai", aiBreaker.HealthCheck, false)
+	metricsHandler.SetHealthCheck("whisper", whisperClient.HealthCheck, false)
+
+	// Контроль пула словарных карточек и генерация примеров
+	cardGenerator := flashcards.NewAICardGenerator(aiClient, logger)
+	flashcardService.SetExampleGenerator(cardGenerator, cfg.App.FlashcardExampleRefreshes)
+	flashcardService.SetPoolMetrics(metricsSystem)
+	flashcardService.SetPaceSource(userService)
+	flashcardService.SetWeeklyTargetSource(userService)
+	flashcardService.SetLocation(resetLoc)
+	flashcardService.SetSpacedIntroduction(cfg.App.FlashcardSpacedIntro)
+	flashcardService.SetRelearnGap(cfg.App.FlashcardRelearnGap)
+	if cfg.App.FlashcardAutoSeed {
+		flashcardService.SetPoolSeeding(cardGenerator, flashcards.PoolSeedConfig{
+			BatchSize:   cfg.App.FlashcardAutoSeedBatch,
+			MinInterval: time.Duration(cfg.App.FlashcardAutoSeedInterval) * time.Minute,
+		})
+		logger.Info("автопополнение пула карточек включено",
+			zap.Int("batch", cfg.App.FlashcardAutoSeedBatch),
+			zap.Int("interval_min", cfg.App.FlashcardAutoSeedInterval))
+	}
 
 	// Инициализация Telegram бота
 	botAPI, err := tgbotapi.NewBotAPI(cfg.Telegram.BotToken)
@@ -135,14 +208,66 @@ func main() {
 
 	// Инициализация обработчика
 	handler := bot.NewHandler(botAPI, userService, messageService, aiClient, whisperClient, ttsService, logger, userMetrics, aiMetrics, premiumService, referralService, flashcardService, store)
+	handler.SetGroupsEnabled(cfg.Telegram.GroupsEnabled)
+	handler.SetFirstRunLevelPicker(cfg.App.FirstRunLevelPick, cfg.App.FirstRunAllowSkip)
+	handler.SetMessageLocale(bot.Locale(cfg.App.MessageLocale))
+	sender := handler.Dispatcher()
+
+	// Челлендж «Фраза дня» требует озвучки
+	phraseChallengeEnabled := cfg.App.PhraseChallenge && cfg.TTS.Enabled
+	if phraseChallengeEnabled {
+		phraseChallenge := challenge.NewService(store.PhraseChallenge(), challenge.Config{
+			MinScore: cfg.App.PhraseChallengeScore,
+			XP:       cfg.App.PhraseChallengeXP,
+		}, logger)
+		phraseChallenge.SetLocation(resetLoc)
+		handler.SetPhraseChallenge(phraseChallenge)
+	}
+	handler.SetDialogMemory(cfg.App.DialogMaxMsgs, cfg.App.DialogKeepMsgs)
+	handler.SetChatHistoryLimit(cfg.App.ChatHistoryMsgs)
+	handler.SetStoredMessagesLimit(cfg.App.MaxStoredMsgs)
+	handler.SetAudioLanguageCheck(cfg.Whisper.LanguageCheck, cfg.Whisper.LanguageMinWords)
+	if cfg.App.DialogPersist {
+		handler.SetDialogPersistence(store.DialogContext(), cfg.App.DialogPersistMsgs)
+	}
+	handler.SetLevelTestPersistence(store.LevelTest())
+	handler.SetQuickReplies(cfg.App.QuickReplies, cfg.App.QuickReplyLevels)
+	handler.SetRateLimitWarningCooldown(time.Duration(cfg.App.RateLimitWarningCooldownSec) * time.Second)
+	handler.SetXPMinWords(cfg.App.XPMinWords)
+	handler.SetUnsupportedLanguageReply(cfg.App.UnsupportedLanguageReply, cfg.App.UnsupportedLanguageTranslate)
+	handler.SetLearningLanguages(cfg.App.LearningLanguages)
+
+	// Жалобы на карточки: кнопка в боте и рассмотрение через /admin
+	flashcardReports := flashcards.NewReportService(store.FlashcardReport(), cfg.App.FlashcardReportThreshold, logger)
+	handler.SetFlashcardReports(flashcardReports)
+
+	premiumFeatures, err := premium.ParseFeatureGate(cfg.App.PremiumFeatures)
+	if err != nil {
+		logger.Fatal("ошибка разбора PREMIUM_FEATURES", zap.Error(err))
+	}
+	handler.SetPremiumFeatures(premiumFeatures)
 
 	// Инициализация планировщика задач
 	taskScheduler := scheduler.NewScheduler(logger)
+	taskScheduler.SetRecorder(metricsSystem)
+
+	// Средние показатели учеников для /compare считаем первыми: остальные джобы могут идти долго
+	taskScheduler.AddJob(scheduler.NewPlatformStatsJob(userService, logger))
 
 	// Добавляем джобу для неактивных пользователей
-	inactiveUsersJob := scheduler.NewInactiveUsersJob(userService, messageService, aiClient, botAPI, logger)
+	inactiveUsersJob := scheduler.NewInactiveUsersJob(userService, messageService, aiClient, sender, logger)
+	inactiveUsersJob.SetPhraseOfDayButton(phraseChallengeEnabled)
 	taskScheduler.AddJob(inactiveUsersJob)
 
+	// Добавляем джобу выгрузки таблиц, если она включена
+	if cfg.Backup.Enabled {
+		backupJob, err := newBackupJob(cfg, store, logger)
+		if err != nil {
+			logger.Fatal("ошибка инициализации выгрузки таблиц", zap.Error(err))
+		}
+		taskScheduler.AddJob(backupJob)
+	}
+
 	// Создание канала для graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -152,11 +277,43 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Запуск HTTP сервера для метрик
-	go startMetricsServer(ctx, cfg.App.Port, metricsHandler, premiumService, cfg.YooKassa.SecretKey, logger)
+	adminHandler := admin.NewHandler(taskScheduler, cfg.App.AdminToken, logger)
+	adminHandler.SetFlashcardReports(flashcardReports)
+	adminHandler.SetFlashcardImporter(flashcardService)
+	adminHandler.SetRateLimitResetter(handler)
+	go startMetricsServer(ctx, cfg.App.Port, metricsHandler, adminHandler, premiumService, cfg.YooKassa.SecretKey, logger)
 
 	// Запуск планировщика задач (каждые 4 часа)
 	go taskScheduler.Start(ctx, 4*time.Hour)
 
+	// Сброс дневных лимитов в полночь пояса сброса
+	go taskScheduler.StartTimed(ctx, scheduler.NewDailyResetJob(premiumService, logger))
+
+	// Вечернее предупреждение о серии под угрозой, за несколько часов до полуночи пояса сброса
+	if cfg.App.StreakWarnings {
+		go taskScheduler.StartTimed(ctx, scheduler.NewStreakWarningJob(userService, sender, botInfo.UserName, resetLoc, cfg.App.StreakWarningHours, logger))
+	}
+
+	// Ежедневное напоминание о занятиях в фиксированный час пояса сброса
+	if cfg.App.DailyReminders {
+		go taskScheduler.StartTimed(ctx, scheduler.NewDailyReminderJob(userService, sender, resetLoc, cfg.App.DailyReminderHour, cfg.App.DailyReminderRate, logger))
+	}
+
+	// Слово дня в фиксированный час пояса сброса тем, у кого включены напоминания
+	if cfg.App.WordOfDay {
+		go taskScheduler.StartTimed(ctx, scheduler.NewWordOfDayJob(userService, flashcardService, sender, resetLoc, cfg.App.WordOfDayHour, cfg.App.DailyReminderRate, logger))
+	}
+
+	// Напоминание о невыполненной недельной цели в последний день недели
+	if cfg.App.WeeklyTargetReminders {
+		go taskScheduler.StartTimed(ctx, scheduler.NewWeeklyTargetReminderJob(userService, flashcardService, sender, resetLoc, logger))
+	}
+
+	// Напоминание о продлении премиума и уведомление о его окончании
+	if cfg.App.PremiumExpiryReminders {
+		go taskScheduler.StartTimed(ctx, scheduler.NewPremiumExpiryJob(userService, premiumService, sender, cfg.App.PremiumExpiryReminderDays, logger))
+	}
+
 	// Запуск обработки обновлений
 	go handleUpdates(ctx, botAPI, handler, logger)
 
@@ -178,19 +335,66 @@ func main() {
 	logger.Info("приложение завершено")
 }
 
-// initLogger инициализирует логгер
-func initLogger() (*zap.Logger, error) {
-	// В продакшене можно использовать JSON формат
-	config := zap.NewDevelopmentConfig()
-	config.OutputPaths = []string{"stdout", "logs/app.log"}
-	config.ErrorOutputPaths = []string{"stderr", "logs/error.log"}
+// initLogger инициализирует логгер с ротацией файлов логов
+func initLogger(cfg config.LogConfig) (*zap.Logger, error) {
+	return logging.New(logging.Options{
+		ToFiles: cfg.ToFiles,
+		Dir:     cfg.Dir,
+		Rotate: logging.RotateOptions{
+			MaxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		},
+	})
+}
 
-	// Создаем директорию для логов если её нет
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		return nil, fmt.Errorf("ошибка создания директории логов: %w", err)
+// newBackupJob создает джобу выгрузки таблиц в настроенное хранилище
+func newBackupJob(cfg *config.Config, st store.Store, logger *zap.Logger) (*scheduler.BackupJob, error) {
+	var dest backup.Destination
+	switch cfg.Backup.Destination {
+	case "s3":
+		s3, err := backup.NewS3Destination(backup.S3Config{
+			Endpoint:  cfg.Backup.S3Endpoint,
+			Region:    cfg.Backup.S3Region,
+			Bucket:    cfg.Backup.S3Bucket,
+			Prefix:    cfg.Backup.S3Prefix,
+			AccessKey: cfg.Backup.S3AccessKey,
+			SecretKey: cfg.Backup.S3SecretKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dest = s3
+	default:
+		local, err := backup.NewLocalDestination(cfg.Backup.Dir)
+		if err != nil {
+			return nil, err
+		}
+		dest = local
 	}
 
-	return config.Build()
+	exporter := backup.NewExporter(st.DB(), dest, backup.DefaultTables, cfg.Backup.Retention, logger)
+	interval := time.Duration(cfg.Backup.IntervalHours) * time.Hour
+
+	logger.Info("выгрузка таблиц включена",
+		zap.String("destination", cfg.Backup.Destination),
+		zap.Duration("interval", interval),
+		zap.Int("retention", cfg.Backup.Retention))
+
+	return scheduler.NewBackupJob(exporter, interval, logger), nil
+}
+
+// spacedRepetitionConfig собирает интервалы повторения карточек из конфигурации
+func spacedRepetitionConfig(cfg config.AppConfig) flashcards.SpacedRepetitionConfig {
+	intervals := make([]time.Duration, 0, len(cfg.FlashcardIntervalDays))
+	for _, days := range cfg.FlashcardIntervalDays {
+		intervals = append(intervals, time.Duration(days)*24*time.Hour)
+	}
+	return flashcards.SpacedRepetitionConfig{
+		Intervals:     intervals,
+		WrongInterval: time.Duration(cfg.FlashcardWrongIntervalMin) * time.Minute,
+		Jitter:        cfg.FlashcardIntervalJitter,
+	}
 }
 
 // handleUpdates обрабатывает обновления от Telegram
@@ -233,11 +437,14 @@ func handleUpdates(ctx context.Context, bot *tgbotapi.BotAPI, handler *bot.Handl
 }
 
 // startMetricsServer запускает HTTP сервер для метрик и webhook'ов
-func startMetricsServer(ctx context.Context, port int, handler *metrics.Handler, premiumService *premium.Service, yukassaSecretKey string, logger *zap.Logger) {
+func startMetricsServer(ctx context.Context, port int, handler *metrics.Handler, adminHandler *admin.Handler, premiumService *premium.Service, yukassaSecretKey string, logger *zap.Logger) {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", handler.MetricsHandler())
 	mux.HandleFunc("/health", handler.HealthHandler)
 
+	// Служебные эндпоинты администратора (без ADMIN_TOKEN все запросы отклоняются)
+	adminHandler.Register(mux)
+
 	// Webhook endpoint для ЮKassa
 	webhookHandler := webhook.NewYooKassaWebhookHandler(premiumService, yukassaSecretKey, logger)
 	mux.HandleFunc("/webhook/yukassa", webhookHandler.HandleWebhook)
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"lingua-ai/internal/config"
+	"lingua-ai/internal/migrations"
+
+	"go.uber.org/zap"
+)
+
+const usage = `Использование: migrate <команда>
+
+Команды:
+  status   показать примененные и ожидающие миграции
+  up       применить все ожидающие миграции
+  down     откатить последнюю примененную миграцию
+  version  показать текущую версию схемы
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Инициализация логгера
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("Ошибка инициализации логгера:", err)
+	}
+	defer logger.Sync()
+
+	// Загрузка конфигурации
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Ошибка загрузки конфигурации", zap.Error(err))
+	}
+
+	switch command := flag.Arg(0); command {
+	case "status":
+		err = printStatus(cfg, logger)
+	case "up":
+		err = migrations.Up(cfg, logger)
+	case "down":
+		err = migrations.Down(cfg, logger)
+	case "version":
+		var version int64
+		version, err = migrations.Version(cfg, logger)
+		if err == nil {
+			fmt.Printf("Текущая версия схемы: %d\n", version)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестная команда %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logger.Fatal("Ошибка выполнения команды миграций", zap.Error(err))
+	}
+}
+
+// printStatus выводит таблицу миграций с датой применения или отметкой «ожидает»
+func printStatus(cfg *config.Config, logger *zap.Logger) error {
+	statuses, err := migrations.Status(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ВЕРСИЯ\tМИГРАЦИЯ\tПРИМЕНЕНА")
+
+	pending := 0
+	for _, status := range statuses {
+		appliedAt := "ожидает"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nВсего: %d, применено: %d, ожидает: %d\n", len(statuses), len(statuses)-pending, pending)
+	return nil
+}
@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/scheduler"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// ManualJobTimeout ограничивает ручной запуск задачи
+const ManualJobTimeout = 10 * time.Minute
+
+// MaxFlashcardImportSize максимальный размер CSV для импорта карточек
+const MaxFlashcardImportSize = 10 << 20
+
+// JobsProvider источник сводки по фоновым задачам и их ручного запуска
+type JobsProvider interface {
+	Stats() []scheduler.JobStats
+	RunJob(ctx context.Context, name string) (scheduler.JobStats, error)
+}
+
+// FlashcardReportsProvider источник жалоб на карточки и их рассмотрения
+type FlashcardReportsProvider interface {
+	OpenReports(ctx context.Context) ([]models.FlashcardReportSummary, error)
+	ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error)
+}
+
+// FlashcardImporter импортирует карточки в общий пул из CSV
+type FlashcardImporter interface {
+	ImportCSV(ctx context.Context, r io.Reader) (*flashcards.ImportSummary, error)
+}
+
+// RateLimitResetter снимает ограничение запросов с пользователя
+type RateLimitResetter interface {
+	ResetRateLimit(telegramID int64)
+}
+
+// Handler обрабатывает служебные HTTP запросы администратора
+type Handler struct {
+	jobs      JobsProvider
+	reports   FlashcardReportsProvider
+	importer  FlashcardImporter
+	rateLimit RateLimitResetter
+	token     string
+	logger    *zap.Logger
+}
+
+// NewHandler создает обработчик админских запросов.
+// Все запросы требуют заголовок Authorization: Bearer <token>.
+func NewHandler(jobs JobsProvider, token string, logger *zap.Logger) *Handler {
+	return &Handler{
+		jobs:   jobs,
+		token:  token,
+		logger: logger,
+	}
+}
+
+// SetFlashcardReports включает эндпоинты рассмотрения жалоб на карточки.
+// Вызывается до Register.
+func (h *Handler) SetFlashcardReports(reports FlashcardReportsProvider) {
+	h.reports = reports
+}
+
+// SetFlashcardImporter включает эндпоинт импорта карточек из CSV.
+// Вызывается до Register.
+func (h *Handler) SetFlashcardImporter(importer FlashcardImporter) {
+	h.importer = importer
+}
+
+// SetRateLimitResetter включает эндпоинт сброса лимита запросов пользователя.
+// Вызывается до Register.
+func (h *Handler) SetRateLimitResetter(resetter RateLimitResetter) {
+	h.rateLimit = resetter
+}
+
+// Register добавляет админские маршруты в mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/jobs", h.requireToken(h.JobsHandler))
+	mux.HandleFunc("POST /admin/jobs/{name}/run", h.requireToken(h.RunJobHandler))
+	if h.reports != nil {
+		mux.HandleFunc("GET /admin/flashcard-reports", h.requireToken(h.FlashcardReportsHandler))
+		mux.HandleFunc("POST /admin/flashcard-reports/{id}/resolve", h.requireToken(h.ResolveFlashcardReportsHandler))
+	}
+	if h.importer != nil {
+		mux.HandleFunc("POST /admin/flashcards/import", h.requireToken(h.ImportFlashcardsHandler))
+	}
+	if h.rateLimit != nil {
+		mux.HandleFunc("POST /admin/ratelimit/{user_id}/reset", h.requireToken(h.ResetRateLimitHandler))
+	}
+}
+
+// requireToken пропускает только запросы с правильным токеном администратора
+func (h *Handler) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			h.logger.Warn("отклонен неавторизованный админский запрос",
+				zap.String("path", r.URL.Path),
+				zap.String("remote_addr", r.RemoteAddr))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// JobsHandler возвращает сводку о здоровье фоновых задач
+func (h *Handler) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": h.jobs.Stats()})
+}
+
+// RunJobHandler немедленно запускает задачу и возвращает ее сводку после выполнения
+func (h *Handler) RunJobHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	// Задача не должна прерываться, если клиент закрыл соединение
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), ManualJobTimeout)
+	defer cancel()
+
+	stats, err := h.jobs.RunJob(ctx, name)
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+	case errors.Is(err, scheduler.ErrJobRunning):
+		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error(), "job": stats})
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"job": stats})
+	}
+}
+
+// FlashcardReportsHandler возвращает карточки с нерассмотренными жалобами
+func (h *Handler) FlashcardReportsHandler(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.reports.OpenReports(r.Context())
+	if err != nil {
+		h.logger.Error("ошибка получения жалоб на карточки", zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	if reports == nil {
+		reports = []models.FlashcardReportSummary{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"reports": reports})
+}
+
+// ResolveFlashcardReportsHandler закрывает жалобы на карточку.
+// Решение передается параметром resolution: restore — вернуть в выдачу, suspend — убрать насовсем.
+func (h *Handler) ResolveFlashcardReportsHandler(w http.ResponseWriter, r *http.Request) {
+	flashcardID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "некорректный id карточки"})
+		return
+	}
+
+	resolved, err := h.reports.ResolveReports(r.Context(), flashcardID, r.URL.Query().Get("resolution"))
+	switch {
+	case errors.Is(err, flashcards.ErrInvalidReportResolution):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+	case errors.Is(err, store.ErrFlashcardReportNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+	case err != nil:
+		h.logger.Error("ошибка рассмотрения жалоб на карточку", zap.Error(err), zap.Int64("flashcard_id", flashcardID))
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"flashcard_id": flashcardID, "resolved": resolved})
+	}
+}
+
+// ImportFlashcardsHandler добавляет карточки из CSV в теле запроса
+// (word,translation,example,level,category) и возвращает итог импорта
+func (h *Handler) ImportFlashcardsHandler(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, MaxFlashcardImportSize)
+
+	// Импорт не должен обрываться на середине, если клиент закрыл соединение
+	summary, err := h.importer.ImportCSV(context.WithoutCancel(r.Context()), body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{"error": "файл слишком большой"})
+	case errors.Is(err, flashcards.ErrInvalidImportCSV):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+	case err != nil:
+		h.logger.Error("ошибка импорта карточек", zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, summary)
+	}
+}
+
+// ResetRateLimitHandler снимает ограничение запросов с пользователя по его Telegram ID,
+// например если лимит сработал по ошибке
+func (h *Handler) ResetRateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	telegramID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil || telegramID <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "некорректный id пользователя"})
+		return
+	}
+
+	h.rateLimit.ResetRateLimit(telegramID)
+	writeJSON(w, http.StatusOK, map[string]any{"user_id": telegramID, "reset": true})
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
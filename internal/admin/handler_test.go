@@ -0,0 +1,322 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/scheduler"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+type fakeJobs struct{}
+
+func (fakeJobs) Stats() []scheduler.JobStats {
+	return []scheduler.JobStats{{Name: "backup", Runs: 3, Errors: 1}}
+}
+
+func (fakeJobs) RunJob(ctx context.Context, name string) (scheduler.JobStats, error) {
+	switch name {
+	case "backup":
+		return scheduler.JobStats{Name: name, Runs: 4, LastRows: 12}, nil
+	case "busy":
+		return scheduler.JobStats{}, scheduler.ErrJobRunning
+	case "broken":
+		return scheduler.JobStats{Name: name}, errors.New("boom")
+	default:
+		return scheduler.JobStats{}, scheduler.ErrJobNotFound
+	}
+}
+
+func TestJobsHandlerRequiresToken(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(fakeJobs{}, "secret", zap.NewNop()).Register(mux)
+
+	for _, header := range []string{"", "Bearer wrong"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("для заголовка %q ожидался 401, получено %d", header, rec.Code)
+		}
+	}
+}
+
+func TestJobsHandlerDisabledWithoutToken(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(fakeJobs{}, "", zap.NewNop()).Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("без настроенного токена ожидался 401, получено %d", rec.Code)
+	}
+}
+
+func TestJobsHandlerReturnsStats(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(fakeJobs{}, "secret", zap.NewNop()).Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получено %d", rec.Code)
+	}
+	var body struct {
+		Jobs []scheduler.JobStats `json:"jobs"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("некорректный JSON: %v", err)
+	}
+	if len(body.Jobs) != 1 || body.Jobs[0].Name != "backup" || body.Jobs[0].Errors != 1 {
+		t.Errorf("неожиданная сводка: %+v", body.Jobs)
+	}
+}
+
+func TestRunJobHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(fakeJobs{}, "secret", zap.NewNop()).Register(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		want   int
+	}{
+		{"backup", http.MethodPost, http.StatusOK},
+		{"busy", http.MethodPost, http.StatusConflict},
+		{"broken", http.MethodPost, http.StatusInternalServerError},
+		{"missing", http.MethodPost, http.StatusNotFound},
+		{"backup", http.MethodGet, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/admin/jobs/"+tt.name+"/run", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: ожидался %d, получено %d", tt.method, tt.name, tt.want, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/jobs/backup/run", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("без токена ожидался 401, получено %d", rec.Code)
+	}
+}
+
+type fakeReports struct{}
+
+func (fakeReports) OpenReports(ctx context.Context) ([]models.FlashcardReportSummary, error) {
+	return []models.FlashcardReportSummary{{
+		FlashcardID: 7,
+		Word:        "affect",
+		Reports:     3,
+		Reasons:     map[string]int{models.ReportReasonTranslation: 3},
+		Suspended:   true,
+	}}, nil
+}
+
+func (fakeReports) ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error) {
+	if resolution != models.ReportResolutionRestore && resolution != models.ReportResolutionSuspend {
+		return 0, flashcards.ErrInvalidReportResolution
+	}
+	if flashcardID != 7 {
+		return 0, store.ErrFlashcardReportNotFound
+	}
+	return 3, nil
+}
+
+func TestFlashcardReportsHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	h := NewHandler(fakeJobs{}, "secret", zap.NewNop())
+	h.SetFlashcardReports(fakeReports{})
+	h.Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/flashcard-reports", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получено %d", rec.Code)
+	}
+	var body struct {
+		Reports []models.FlashcardReportSummary `json:"reports"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("некорректный JSON: %v", err)
+	}
+	if len(body.Reports) != 1 || body.Reports[0].FlashcardID != 7 || !body.Reports[0].Suspended {
+		t.Errorf("неожиданный список жалоб: %+v", body.Reports)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/flashcard-reports", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("без токена ожидался 401, получено %d", rec.Code)
+	}
+}
+
+func TestResolveFlashcardReportsHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	h := NewHandler(fakeJobs{}, "secret", zap.NewNop())
+	h.SetFlashcardReports(fakeReports{})
+	h.Register(mux)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/admin/flashcard-reports/7/resolve?resolution=restore", http.StatusOK},
+		{"/admin/flashcard-reports/7/resolve?resolution=suspend", http.StatusOK},
+		{"/admin/flashcard-reports/7/resolve?resolution=delete", http.StatusBadRequest},
+		{"/admin/flashcard-reports/abc/resolve?resolution=restore", http.StatusBadRequest},
+		{"/admin/flashcard-reports/8/resolve?resolution=restore", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: ожидался %d, получено %d", tt.path, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestFlashcardReportsDisabledWithoutProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(fakeJobs{}, "secret", zap.NewNop()).Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/flashcard-reports", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("без сервиса жалоб ожидался 404, получено %d", rec.Code)
+	}
+}
+
+// fakeImporter считает строки CSV добавленными, строку с «bad» — некорректным файлом
+type fakeImporter struct{}
+
+func (fakeImporter) ImportCSV(ctx context.Context, r io.Reader) (*flashcards.ImportSummary, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(data), "bad") {
+		return nil, flashcards.ErrInvalidImportCSV
+	}
+	return &flashcards.ImportSummary{Inserted: strings.Count(string(data), "\n"), Errors: []flashcards.ImportRowError{}}, nil
+}
+
+func TestImportFlashcardsHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	h := NewHandler(fakeJobs{}, "secret", zap.NewNop())
+	h.SetFlashcardImporter(fakeImporter{})
+	h.Register(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/flashcards/import", strings.NewReader("ticket,билет,,beginner,travel\nhouse,дом,,beginner,general\n"))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получено %d", rec.Code)
+	}
+	var summary flashcards.ImportSummary
+	if err := json.NewDecoder(rec.Body).Decode(&summary); err != nil {
+		t.Fatalf("некорректный JSON: %v", err)
+	}
+	if summary.Inserted != 2 {
+		t.Errorf("ожидалось 2 добавленных карточки, получено %+v", summary)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		token  string
+		method string
+		want   int
+	}{
+		{"некорректный CSV", "bad", "secret", http.MethodPost, http.StatusBadRequest},
+		{"без токена", "ticket,билет,,beginner,travel", "", http.MethodPost, http.StatusUnauthorized},
+		{"слишком большой файл", strings.Repeat("x", MaxFlashcardImportSize+1), "secret", http.MethodPost, http.StatusRequestEntityTooLarge},
+		{"GET", "", "secret", http.MethodGet, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/admin/flashcards/import", strings.NewReader(tt.body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: ожидался %d, получено %d", tt.name, tt.want, rec.Code)
+		}
+	}
+}
+
+// fakeRateLimit запоминает пользователей, которым сброшен лимит
+type fakeRateLimit struct {
+	reset []int64
+}
+
+func (f *fakeRateLimit) ResetRateLimit(telegramID int64) {
+	f.reset = append(f.reset, telegramID)
+}
+
+func TestResetRateLimitHandler(t *testing.T) {
+	limiter := &fakeRateLimit{}
+	h := NewHandler(fakeJobs{}, "secret", zap.NewNop())
+	h.SetRateLimitResetter(limiter)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"без токена", "/admin/ratelimit/42/reset", "", http.StatusUnauthorized},
+		{"некорректный id", "/admin/ratelimit/abc/reset", "secret", http.StatusBadRequest},
+		{"сброс", "/admin/ratelimit/42/reset", "secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: ожидался %d, получено %d", tt.name, tt.want, rec.Code)
+		}
+	}
+
+	if len(limiter.reset) != 1 || limiter.reset[0] != 42 {
+		t.Errorf("ожидался сброс лимита пользователя 42, получено %v", limiter.reset)
+	}
+}
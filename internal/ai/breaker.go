@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Параметры автоматического выключателя по умолчанию
+const (
+	DefaultBreakerFailures = 3
+	DefaultBreakerCooldown = time.Minute
+)
+
+// ErrProviderUnavailable провайдер AI временно недоступен, запрос не отправлялся
+var ErrProviderUnavailable = errors.New("AI провайдер временно недоступен")
+
+// CircuitBreaker перестает обращаться к провайдеру после нескольких ошибок подряд.
+// По истечении паузы пропускает один пробный запрос: успех возвращает провайдер в работу.
+type CircuitBreaker struct {
+	next     AIClient
+	failures int
+	cooldown time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu          sync.Mutex
+	consecutive int       // ошибок подряд
+	openedAt    time.Time // когда провайдер признан недоступным (нулевое — доступен)
+	probing     bool      // пробный запрос уже отправлен
+}
+
+// NewCircuitBreaker оборачивает AI клиент выключателем: после failures ошибок подряд
+// запросы не отправляются в течение cooldown
+func NewCircuitBreaker(next AIClient, failures int, cooldown time.Duration, logger *zap.Logger) *CircuitBreaker {
+	if failures <= 0 {
+		failures = DefaultBreakerFailures
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &CircuitBreaker{
+		next:     next,
+		failures: failures,
+		cooldown: cooldown,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Healthy сообщает, работает ли провайдер: последний запрос не признал его недоступным
+func (b *CircuitBreaker) Healthy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openedAt.IsZero()
+}
+
+// Available сообщает, можно ли сейчас отправить запрос провайдеру.
+// После паузы возвращает true, пока не отправлен пробный запрос.
+func (b *CircuitBreaker) Available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openedAt.IsZero() || (!b.probing && b.now().Sub(b.openedAt) >= b.cooldown)
+}
+
+// GenerateResponse отправляет запрос провайдеру, если он не признан недоступным
+func (b *CircuitBreaker) GenerateResponse(ctx context.Context, messages []Message, options GenerationOptions) (*Response, error) {
+	if !b.acquire() {
+		return nil, ErrProviderUnavailable
+	}
+
+	response, err := b.next.GenerateResponse(ctx, messages, options)
+	b.record(ctx, err)
+	return response, err
+}
+
+// GenerateResponseStream открывает поток ответа, если провайдер не признан недоступным.
+// Учитывается только открытие потока: обрыв посреди ответа приходит фрагментом с ошибкой.
+func (b *CircuitBreaker) GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error) {
+	if !b.acquire() {
+		return nil, ErrProviderUnavailable
+	}
+
+	chunks, err := b.next.GenerateResponseStream(ctx, messages, options)
+	if errors.Is(err, ErrStreamingUnsupported) {
+		// Запрос не дошел до провайдера, пробный запрос остается за обычным вызовом
+		b.release()
+		return nil, err
+	}
+	b.record(ctx, err)
+	return chunks, err
+}
+
+// GetName возвращает название обернутого провайдера
+func (b *CircuitBreaker) GetName() string {
+	return b.next.GetName()
+}
+
+// acquire решает, пропустить ли запрос; после паузы пропускает ровно один пробный
+func (b *CircuitBreaker) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// release отпускает пробный запрос, не меняя доступность провайдера
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record учитывает результат запроса и меняет доступность провайдера
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+
+	// Отмена запроса на нашей стороне не говорит о состоянии провайдера
+	if err != nil && ctx.Err() != nil {
+		b.probing = false
+		b.mu.Unlock()
+		return
+	}
+
+	wasAvailable := b.openedAt.IsZero()
+	if err == nil {
+		b.consecutive = 0
+		b.openedAt = time.Time{}
+	} else {
+		b.consecutive++
+		if b.probing || b.consecutive >= b.failures {
+			b.openedAt = b.now()
+		}
+	}
+	b.probing = false
+
+	available := b.openedAt.IsZero()
+	b.mu.Unlock()
+
+	if available == wasAvailable {
+		return
+	}
+	if available {
+		b.logger.Info("AI провайдер снова доступен", zap.String("provider", b.next.GetName()))
+	} else {
+		b.logger.Warn("AI провайдер недоступен, бот переходит в режим без AI",
+			zap.String("provider", b.next.GetName()),
+			zap.Int("failures", b.consecutive),
+			zap.Duration("cooldown", b.cooldown),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// flakyClient отвечает ошибкой, пока fail=true, и считает запросы
+type flakyClient struct {
+	fail     bool
+	noStream bool // провайдер не поддерживает потоковые ответы
+	calls    int
+}
+
+func (c *flakyClient) GenerateResponse(ctx context.Context, messages []Message, options GenerationOptions) (*Response, error) {
+	c.calls++
+	if c.fail {
+		return nil, errors.New("502 bad gateway")
+	}
+	return &Response{Content: "ok"}, nil
+}
+
+func (c *flakyClient) GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error) {
+	if c.noStream {
+		return nil, ErrStreamingUnsupported
+	}
+	c.calls++
+	if c.fail {
+		return nil, errors.New("502 bad gateway")
+	}
+	chunks := make(chan StreamChunk, 1)
+	chunks <- StreamChunk{Content: "ok"}
+	close(chunks)
+	return chunks, nil
+}
+
+func (c *flakyClient) GetName() string { return "flaky" }
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	client := &flakyClient{fail: true}
+	breaker := NewCircuitBreaker(client, 2, time.Minute, zap.NewNop())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Одна ошибка еще не выключает провайдера
+	breaker.GenerateResponse(ctx, nil, GenerationOptions{})
+	if !breaker.Available() || !breaker.Healthy() {
+		t.Fatal("после одной ошибки провайдер должен оставаться доступным")
+	}
+
+	breaker.GenerateResponse(ctx, nil, GenerationOptions{})
+	if breaker.Available() || breaker.Healthy() {
+		t.Fatal("после двух ошибок подряд провайдер должен быть недоступен")
+	}
+
+	// Во время паузы запросы не доходят до провайдера
+	if _, err := breaker.GenerateResponse(ctx, nil, GenerationOptions{}); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("ожидалась ErrProviderUnavailable, получено %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("ожидалось 2 запроса к провайдеру, получено %d", client.calls)
+	}
+
+	// Неудачная проба снова откладывает обращения
+	now = now.Add(time.Minute)
+	if !breaker.Available() {
+		t.Fatal("после паузы должен быть разрешен пробный запрос")
+	}
+	breaker.GenerateResponse(ctx, nil, GenerationOptions{})
+	if breaker.Available() {
+		t.Fatal("после неудачной пробы провайдер должен оставаться недоступным")
+	}
+
+	// Успешная проба возвращает провайдера в работу
+	now = now.Add(time.Minute)
+	client.fail = false
+	if _, err := breaker.GenerateResponse(ctx, nil, GenerationOptions{}); err != nil {
+		t.Fatalf("неожиданная ошибка пробного запроса: %v", err)
+	}
+	if !breaker.Available() || !breaker.Healthy() {
+		t.Error("после успешной пробы провайдер должен быть доступен")
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	client := &flakyClient{fail: true}
+	breaker := NewCircuitBreaker(client, 1, time.Minute, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	breaker.GenerateResponse(ctx, nil, GenerationOptions{})
+	if !breaker.Healthy() {
+		t.Error("отмененный запрос не должен выключать провайдера")
+	}
+}
+
+func TestCircuitBreakerStreamUnsupportedKeepsProbe(t *testing.T) {
+	client := &flakyClient{fail: true}
+	breaker := NewCircuitBreaker(client, 1, time.Minute, zap.NewNop())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := breaker.GenerateResponseStream(ctx, nil, GenerationOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка открытия потока")
+	}
+	if breaker.Healthy() {
+		t.Fatal("ошибка открытия потока должна выключать провайдера")
+	}
+
+	// Провайдер без потоков не тратит пробный запрос: его выполняет обычный вызов
+	now = now.Add(time.Minute)
+	client.fail = false
+	client.noStream = true
+	if _, err := breaker.GenerateResponseStream(ctx, nil, GenerationOptions{}); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Fatalf("ожидалась ErrStreamingUnsupported, получено %v", err)
+	}
+	if !breaker.Available() {
+		t.Fatal("пробный запрос должен остаться доступным")
+	}
+	if _, err := breaker.GenerateResponse(ctx, nil, GenerationOptions{}); err != nil {
+		t.Fatalf("неожиданная ошибка пробного запроса: %v", err)
+	}
+	if !breaker.Healthy() {
+		t.Error("после успешной пробы провайдер должен быть доступен")
+	}
+}
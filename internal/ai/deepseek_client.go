@@ -1,23 +1,51 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// Параметры повторов запросов к DeepSeek по умолчанию
+const (
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// RetryMetrics получатель метрик повторных запросов к AI провайдеру
+type RetryMetrics interface {
+	RecordAIRetry(provider, reason string)
+}
+
+// retryableError временная ошибка провайдера, после которой запрос стоит повторить
+type retryableError struct {
+	reason string // network, rate_limit, server_error
+	err    error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
 // DeepSeekClient клиент для работы с DeepSeek API
 type DeepSeekClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
 	logger     *zap.Logger
+
+	maxRetries int           // сколько раз повторять запрос после временной ошибки
+	baseDelay  time.Duration // пауза перед первым повтором, дальше удваивается
+	metrics    RetryMetrics
 }
 
 // NewDeepSeekClient создает новый клиент DeepSeek
@@ -32,10 +60,30 @@ func NewDeepSeekClient(apiKey, baseURL string, logger *zap.Logger) *DeepSeekClie
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
-		logger: logger,
+		logger:     logger,
+		maxRetries: DefaultMaxRetries,
+		baseDelay:  DefaultRetryBaseDelay,
 	}
 }
 
+// SetRetry задает число повторов после сетевых ошибок и ответов 429/5xx и паузу
+// перед первым повтором. 0 повторов отключает их.
+func (c *DeepSeekClient) SetRetry(maxRetries int, baseDelay time.Duration) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	c.maxRetries = maxRetries
+	c.baseDelay = baseDelay
+}
+
+// SetRetryMetrics задает получателя метрик повторных запросов
+func (c *DeepSeekClient) SetRetryMetrics(metrics RetryMetrics) {
+	c.metrics = metrics
+}
+
 // DeepSeekRequest представляет запрос к DeepSeek API
 type DeepSeekRequest struct {
 	Model       string            `json:"model"`
@@ -68,6 +116,18 @@ type DeepSeekChoice struct {
 	FinishReason string          `json:"finish_reason"`
 }
 
+// DeepSeekStreamChunk представляет фрагмент потокового ответа (событие SSE)
+type DeepSeekStreamChunk struct {
+	Choices []DeepSeekStreamChoice `json:"choices"`
+}
+
+// DeepSeekStreamChoice представляет приращение текста в потоковом ответе
+type DeepSeekStreamChoice struct {
+	Index        int             `json:"index"`
+	Delta        DeepSeekMessage `json:"delta"`
+	FinishReason string          `json:"finish_reason"`
+}
+
 // DeepSeekUsage представляет статистику использования токенов
 type DeepSeekUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
@@ -82,6 +142,128 @@ func (c *DeepSeekClient) GenerateResponse(ctx context.Context, messages []Messag
 		zap.Float64("temperature", options.Temperature),
 		zap.Int("max_tokens", options.MaxTokens))
 
+	requestBody, err := newDeepSeekRequestBody(messages, options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := c.sendWithRetry(ctx, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Парсим ответ
+	var deepSeekResp DeepSeekResponse
+	err = json.Unmarshal(responseBody, &deepSeekResp)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+
+	// Проверяем наличие вариантов ответа
+	if len(deepSeekResp.Choices) == 0 {
+		return nil, fmt.Errorf("нет вариантов ответа от DeepSeek")
+	}
+
+	choice := deepSeekResp.Choices[0]
+
+	c.logger.Debug("получен ответ от DeepSeek",
+		zap.String("model", deepSeekResp.Model),
+		zap.Int("prompt_tokens", deepSeekResp.Usage.PromptTokens),
+		zap.Int("completion_tokens", deepSeekResp.Usage.CompletionTokens),
+		zap.String("finish_reason", choice.FinishReason))
+
+	return &Response{
+		Content: choice.Message.Content,
+		Model:   deepSeekResp.Model,
+		Usage: Usage{
+			PromptTokens:     deepSeekResp.Usage.PromptTokens,
+			CompletionTokens: deepSeekResp.Usage.CompletionTokens,
+			TotalTokens:      deepSeekResp.Usage.TotalTokens,
+		},
+		FinishReason: choice.FinishReason,
+		Provider:     "deepseek",
+	}, nil
+}
+
+// GenerateResponseStream генерирует ответ в потоковом режиме DeepSeek (SSE).
+// Ошибка возвращается, если поток не удалось открыть; обрыв потока передается
+// последним фрагментом канала.
+func (c *DeepSeekClient) GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error) {
+	requestBody, err := newDeepSeekRequestBody(messages, options, true)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания HTTP запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ошибка DeepSeek API (статус %d): %s", resp.StatusCode, string(responseBody))
+	}
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+		if err := readDeepSeekStream(ctx, resp.Body, chunks); err != nil {
+			c.logger.Warn("поток ответа DeepSeek прерван", zap.Error(err))
+			select {
+			case chunks <- StreamChunk{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return chunks, nil
+}
+
+// readDeepSeekStream читает события SSE и передает приращения текста в chunks до события [DONE]
+func readDeepSeekStream(ctx context.Context, body io.Reader, chunks chan<- StreamChunk) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Пустые строки разделяют события, строки с ":" — комментарии keep-alive
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk DeepSeekStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("ошибка парсинга фрагмента ответа: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		select {
+		case chunks <- StreamChunk{Content: chunk.Choices[0].Delta.Content}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ошибка чтения потока: %w", err)
+	}
+	return fmt.Errorf("поток закончился без события [DONE]")
+}
+
+// newDeepSeekRequestBody сериализует запрос к DeepSeek API
+func newDeepSeekRequestBody(messages []Message, options GenerationOptions, stream bool) ([]byte, error) {
 	// Конвертируем сообщения в формат DeepSeek
 	deepSeekMessages := make([]DeepSeekMessage, len(messages))
 	for i, msg := range messages {
@@ -91,21 +273,57 @@ func (c *DeepSeekClient) GenerateResponse(ctx context.Context, messages []Messag
 		}
 	}
 
-	// Создаем запрос
 	request := DeepSeekRequest{
 		Model:       "deepseek-chat", // Используем основную модель DeepSeek
 		Messages:    deepSeekMessages,
 		Temperature: options.Temperature,
 		MaxTokens:   options.MaxTokens,
-		Stream:      false,
+		Stream:      stream,
 	}
 
-	// Сериализуем запрос
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
 	}
+	return requestBody, nil
+}
+
+// sendWithRetry отправляет запрос и повторяет его с экспоненциальной паузой после
+// временных ошибок. Повтор не начинается, если пауза не укладывается в дедлайн контекста.
+func (c *DeepSeekClient) sendWithRetry(ctx context.Context, requestBody []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		responseBody, err := c.send(ctx, requestBody)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= c.maxRetries {
+			return responseBody, err
+		}
 
+		delay := c.baseDelay << attempt
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+
+		c.logger.Warn("временная ошибка DeepSeek API, повторяем запрос",
+			zap.Error(err),
+			zap.String("reason", retryable.reason),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay))
+		if c.metrics != nil {
+			c.metrics.RecordAIRetry("deepseek", retryable.reason)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// send выполняет один HTTP запрос к DeepSeek API и возвращает тело успешного ответа
+func (c *DeepSeekClient) send(ctx context.Context, requestBody []byte) ([]byte, error) {
 	// Создаем HTTP запрос
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(requestBody))
 	if err != nil {
@@ -119,7 +337,11 @@ func (c *DeepSeekClient) GenerateResponse(ctx context.Context, messages []Messag
 	// Отправляем запрос
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка отправки запроса: %w", err)
+		err = fmt.Errorf("ошибка отправки запроса: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &retryableError{reason: "network", err: err}
 	}
 	defer resp.Body.Close()
 
@@ -134,40 +356,17 @@ func (c *DeepSeekClient) GenerateResponse(ctx context.Context, messages []Messag
 		c.logger.Error("ошибка DeepSeek API",
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("response", string(responseBody)))
-		return nil, fmt.Errorf("ошибка DeepSeek API (статус %d): %s", resp.StatusCode, string(responseBody))
-	}
-
-	// Парсим ответ
-	var deepSeekResp DeepSeekResponse
-	err = json.Unmarshal(responseBody, &deepSeekResp)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка парсинга ответа: %w", err)
-	}
-
-	// Проверяем наличие вариантов ответа
-	if len(deepSeekResp.Choices) == 0 {
-		return nil, fmt.Errorf("нет вариантов ответа от DeepSeek")
+		err := fmt.Errorf("ошибка DeepSeek API (статус %d): %s", resp.StatusCode, string(responseBody))
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return nil, &retryableError{reason: "rate_limit", err: err}
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return nil, &retryableError{reason: "server_error", err: err}
+		}
+		return nil, err
 	}
 
-	choice := deepSeekResp.Choices[0]
-
-	c.logger.Debug("получен ответ от DeepSeek",
-		zap.String("model", deepSeekResp.Model),
-		zap.Int("prompt_tokens", deepSeekResp.Usage.PromptTokens),
-		zap.Int("completion_tokens", deepSeekResp.Usage.CompletionTokens),
-		zap.String("finish_reason", choice.FinishReason))
-
-	return &Response{
-		Content: choice.Message.Content,
-		Model:   deepSeekResp.Model,
-		Usage: Usage{
-			PromptTokens:     deepSeekResp.Usage.PromptTokens,
-			CompletionTokens: deepSeekResp.Usage.CompletionTokens,
-			TotalTokens:      deepSeekResp.Usage.TotalTokens,
-		},
-		FinishReason: choice.FinishReason,
-		Provider:     "deepseek",
-	}, nil
+	return responseBody, nil
 }
 
 // GetName возвращает название провайдера
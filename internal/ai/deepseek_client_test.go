@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// retryRecorder запоминает причины повторных запросов
+type retryRecorder struct {
+	reasons []string
+}
+
+func (r *retryRecorder) RecordAIRetry(provider, reason string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+const deepSeekOKResponse = `{"model":"deepseek-chat","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`
+
+// newDeepSeekTestServer отвечает статусами из statuses по очереди, затем успешным ответом
+func newDeepSeekTestServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if int(n) <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			w.Write([]byte(`{"error":"temporary"}`))
+			return
+		}
+		w.Write([]byte(deepSeekOKResponse))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestDeepSeekClientRetriesTransientErrors(t *testing.T) {
+	server, calls := newDeepSeekTestServer(t, http.StatusTooManyRequests, http.StatusBadGateway)
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+	client.SetRetry(3, time.Millisecond)
+	recorder := &retryRecorder{}
+	client.SetRetryMetrics(recorder)
+
+	response, err := client.GenerateResponse(context.Background(), nil, GenerationOptions{})
+	if err != nil {
+		t.Fatalf("ожидался успешный ответ после повторов, получено %v", err)
+	}
+	if response.Content != "ok" {
+		t.Errorf("неожиданный ответ: %q", response.Content)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("ожидалось 3 запроса, получено %d", got)
+	}
+	if len(recorder.reasons) != 2 || recorder.reasons[0] != "rate_limit" || recorder.reasons[1] != "server_error" {
+		t.Errorf("неожиданные причины повторов: %v", recorder.reasons)
+	}
+}
+
+func TestDeepSeekClientStopsAfterMaxRetries(t *testing.T) {
+	server, calls := newDeepSeekTestServer(t, 500, 500, 500, 500, 500)
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+	client.SetRetry(2, time.Millisecond)
+
+	if _, err := client.GenerateResponse(context.Background(), nil, GenerationOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка после исчерпания повторов")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("ожидалось 3 запроса (1 + 2 повтора), получено %d", got)
+	}
+}
+
+func TestDeepSeekClientDoesNotRetryClientErrors(t *testing.T) {
+	server, calls := newDeepSeekTestServer(t, http.StatusUnauthorized)
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+	client.SetRetry(3, time.Millisecond)
+
+	if _, err := client.GenerateResponse(context.Background(), nil, GenerationOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка авторизации")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("ошибку 401 не нужно повторять, запросов: %d", got)
+	}
+}
+
+func TestDeepSeekClientRespectsContextDeadline(t *testing.T) {
+	server, calls := newDeepSeekTestServer(t, 503, 503, 503)
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+	client.SetRetry(3, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GenerateResponse(ctx, nil, GenerationOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка: пауза перед повтором не укладывается в дедлайн")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("клиент ждал повтора дольше дедлайна: %v", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("ожидался 1 запрос, получено %d", got)
+	}
+}
+
+func TestDeepSeekClientStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": keep-alive\n\n" +
+			`data: {"choices":[{"delta":{"role":"assistant","content":""}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":"Hello"}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":", world!"},"finish_reason":"stop"}]}` + "\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+
+	chunks, err := client.GenerateResponseStream(context.Background(), nil, GenerationOptions{})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка открытия потока: %v", err)
+	}
+	var got []string
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatalf("неожиданная ошибка потока: %v", chunk.Err)
+		}
+		got = append(got, chunk.Content)
+	}
+	if strings.Join(got, "|") != "Hello|, world!" {
+		t.Errorf("неожиданные фрагменты ответа: %q", got)
+	}
+}
+
+func TestDeepSeekClientStreamError(t *testing.T) {
+	server, _ := newDeepSeekTestServer(t, http.StatusServiceUnavailable)
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+
+	if _, err := client.GenerateResponseStream(context.Background(), nil, GenerationOptions{}); err == nil {
+		t.Fatal("ожидалась ошибка открытия потока при ответе 503")
+	}
+}
+
+func TestDeepSeekClientStreamWithoutDoneReportsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"choices":[{"delta":{"content":"Hello"}}]}` + "\n\n"))
+	}))
+	defer server.Close()
+	client := NewDeepSeekClient("key", server.URL, zap.NewNop())
+
+	chunks, err := client.GenerateResponseStream(context.Background(), nil, GenerationOptions{})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка открытия потока: %v", err)
+	}
+	var content string
+	var streamErr error
+	for chunk := range chunks {
+		content += chunk.Content
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	if content != "Hello" {
+		t.Errorf("ожидался текст до обрыва, получено %q", content)
+	}
+	if streamErr == nil {
+		t.Error("обрыв потока без [DONE] должен приходить фрагментом с ошибкой")
+	}
+}
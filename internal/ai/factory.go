@@ -8,12 +8,24 @@ import (
 
 // NewAIClient создает новый AI клиент на основе конфигурации
 func NewAIClient(cfg *AIConfig, logger *zap.Logger) (AIClient, error) {
+	var client AIClient
 	switch cfg.Provider {
 	case "deepseek":
-		return NewDeepSeekClient(cfg.DeepSeek.APIKey, cfg.DeepSeek.BaseURL, logger), nil
+		deepSeek := NewDeepSeekClient(cfg.DeepSeek.APIKey, cfg.DeepSeek.BaseURL, logger)
+		deepSeek.SetRetry(cfg.MaxRetries, cfg.RetryBaseDelay)
+		if cfg.RetryMetrics != nil {
+			deepSeek.SetRetryMetrics(cfg.RetryMetrics)
+		}
+		client = deepSeek
 	case "openrouter":
-		return NewOpenRouterClient(cfg.OpenRouter.APIKey, cfg.OpenRouter.SiteURL, cfg.OpenRouter.SiteName, logger), nil
+		client = NewOpenRouterClient(cfg.OpenRouter.APIKey, cfg.OpenRouter.SiteURL, cfg.OpenRouter.SiteName, logger)
 	default:
 		return nil, fmt.Errorf("неподдерживаемый AI провайдер: %s. Поддерживаются: 'deepseek', 'openrouter'", cfg.Provider)
 	}
+
+	if cfg.DebugPrompts {
+		logger.Warn("включено отладочное логирование промптов AI")
+		client = NewPromptLoggingClient(client, logger)
+	}
+	return client, nil
 }
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HealthChecker клиент, доступность которого можно проверить без генерации ответа
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck проверяет доступность DeepSeek API запросом списка моделей
+func (c *DeepSeekClient) HealthCheck(ctx context.Context) error {
+	return probeModels(ctx, c.httpClient, c.baseURL, c.apiKey)
+}
+
+// HealthCheck проверяет доступность OpenRouter API запросом списка моделей
+func (c *OpenRouterClient) HealthCheck(ctx context.Context) error {
+	return probeModels(ctx, c.httpClient, c.baseURL, c.apiKey)
+}
+
+// HealthCheck проверяет провайдер напрямую, минуя выключатель: проверка не считается
+// запросом и не влияет на его состояние
+func (b *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	return checkHealth(ctx, b.next)
+}
+
+// HealthCheck проверяет обернутый провайдер
+func (c *promptLoggingClient) HealthCheck(ctx context.Context) error {
+	return checkHealth(ctx, c.next)
+}
+
+// checkHealth проверяет клиент, если он это поддерживает; иначе считает его доступным
+func checkHealth(ctx context.Context, client AIClient) error {
+	checker, ok := client.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	return checker.HealthCheck(ctx)
+}
+
+// probeModels запрашивает список моделей OpenAI-совместимого API: запрос бесплатный
+// и заодно проверяет API ключ
+func probeModels(ctx context.Context, httpClient *http.Client, baseURL, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("нездоровый статус API: %d", resp.StatusCode)
+	}
+	return nil
+}
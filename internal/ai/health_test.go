@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestHealthCheckProbesModelsThroughWrappers(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("неожиданный запрос проверки: %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	breaker := NewCircuitBreaker(NewPromptLoggingClient(NewDeepSeekClient("key", server.URL, zap.NewNop()), zap.NewNop()),
+		1, time.Minute, zap.NewNop())
+	if err := breaker.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("доступный провайдер признан нездоровым: %v", err)
+	}
+
+	status = http.StatusUnauthorized
+	if err := breaker.HealthCheck(context.Background()); err == nil {
+		t.Fatal("ожидалась ошибка при ответе 401")
+	}
+	if !breaker.Healthy() {
+		t.Error("проверка здоровья не должна влиять на выключатель")
+	}
+}
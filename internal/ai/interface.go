@@ -2,9 +2,11 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"html"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Message представляет сообщение для AI
@@ -35,23 +37,43 @@ type GenerationOptions struct {
 	MaxTokens   int     `json:"max_tokens,omitempty"`
 }
 
+// StreamChunk фрагмент потокового ответа. Err заполнен только в последнем фрагменте,
+// если поток оборвался: тогда уже полученный текст неполный.
+type StreamChunk struct {
+	Content string
+	Err     error
+}
+
+// ErrStreamingUnsupported провайдер не умеет отдавать ответ частями, нужен обычный запрос
+var ErrStreamingUnsupported = errors.New("потоковые ответы не поддерживаются провайдером")
+
 // AIClient интерфейс для работы с AI провайдерами
 type AIClient interface {
 	// GenerateResponse генерирует ответ на основе сообщений
 	GenerateResponse(ctx context.Context, messages []Message, options GenerationOptions) (*Response, error)
 
+	// GenerateResponseStream генерирует ответ частями по мере готовности. Канал
+	// закрывается, когда ответ закончен, прерван ошибкой или отменен контекст;
+	// при обрыве последним приходит фрагмент с ошибкой.
+	GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error)
+
 	// GetName возвращает название провайдера
 	GetName() string
 }
 
 // AIConfig содержит конфигурацию для AI клиентов
 type AIConfig struct {
-	Provider    string
-	Model       string
-	MaxTokens   int
-	Temperature float64
-	DeepSeek    DeepSeekConfig
-	OpenRouter  OpenRouterConfig
+	Provider     string
+	Model        string
+	MaxTokens    int
+	Temperature  float64
+	DeepSeek     DeepSeekConfig
+	OpenRouter   OpenRouterConfig
+	DebugPrompts bool // логировать промпты на уровне Debug (только для отладки)
+
+	MaxRetries     int           // повторы запроса после сетевых ошибок и ответов 429/5xx
+	RetryBaseDelay time.Duration // пауза перед первым повтором, дальше удваивается
+	RetryMetrics   RetryMetrics  // получатель метрик повторов (может быть nil)
 }
 
 // DeepSeekConfig конфигурация DeepSeek
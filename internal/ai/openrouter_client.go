@@ -185,6 +185,11 @@ func (c *OpenRouterClient) GenerateResponse(ctx context.Context, messages []Mess
 	}, nil
 }
 
+// GenerateResponseStream потоковый режим для OpenRouter не реализован: бот использует обычный запрос
+func (c *OpenRouterClient) GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error) {
+	return nil, ErrStreamingUnsupported
+}
+
 func (c *OpenRouterClient) GetName() string {
 	return "OpenRouter"
 }
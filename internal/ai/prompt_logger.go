@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+// Шаблоны персональных данных, которые вырезаются из отладочных логов промптов
+var (
+	emailRegexp    = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardRegexp     = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	phoneRegexp    = regexp.MustCompile(`\+?\d[\d\s\-()]{8,}\d`)
+	usernameRegexp = regexp.MustCompile(`@[A-Za-z][A-Za-z0-9_]{3,31}`)
+)
+
+// redactPII заменяет e-mail, номера карт и телефонов и @username на заглушки
+func redactPII(text string) string {
+	text = emailRegexp.ReplaceAllString(text, "[email]")
+	text = cardRegexp.ReplaceAllString(text, "[card]")
+	text = phoneRegexp.ReplaceAllString(text, "[phone]")
+	text = usernameRegexp.ReplaceAllString(text, "[username]")
+	return text
+}
+
+// promptLoggingClient логирует собранные промпты перед отправкой провайдеру.
+// Используется только для отладки, включается через AI_DEBUG_PROMPTS.
+type promptLoggingClient struct {
+	next   AIClient
+	logger *zap.Logger
+}
+
+// NewPromptLoggingClient оборачивает AI клиент логированием промптов на уровне Debug
+func NewPromptLoggingClient(next AIClient, logger *zap.Logger) AIClient {
+	return &promptLoggingClient{next: next, logger: logger}
+}
+
+// GenerateResponse логирует промпт и передает запрос дальше
+func (c *promptLoggingClient) GenerateResponse(ctx context.Context, messages []Message, options GenerationOptions) (*Response, error) {
+	c.logPrompt(messages, options)
+	return c.next.GenerateResponse(ctx, messages, options)
+}
+
+// GenerateResponseStream логирует промпт и передает потоковый запрос дальше
+func (c *promptLoggingClient) GenerateResponseStream(ctx context.Context, messages []Message, options GenerationOptions) (<-chan StreamChunk, error) {
+	c.logPrompt(messages, options)
+	return c.next.GenerateResponseStream(ctx, messages, options)
+}
+
+// logPrompt пишет промпт в лог без персональных данных
+func (c *promptLoggingClient) logPrompt(messages []Message, options GenerationOptions) {
+	if ce := c.logger.Check(zap.DebugLevel, "🐞 промпт для AI"); ce != nil {
+		redacted := make([]Message, len(messages))
+		for i, msg := range messages {
+			redacted[i] = Message{Role: msg.Role, Content: redactPII(msg.Content)}
+		}
+		ce.Write(
+			zap.String("provider", c.next.GetName()),
+			zap.Float64("temperature", options.Temperature),
+			zap.Int("max_tokens", options.MaxTokens),
+			zap.Int("messages_count", len(messages)),
+			zap.Any("messages", redacted))
+	}
+}
+
+// GetName возвращает название обернутого провайдера
+func (c *promptLoggingClient) GetName() string {
+	return c.next.GetName()
+}
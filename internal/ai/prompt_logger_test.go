@@ -0,0 +1,20 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactPII(t *testing.T) {
+	input := "Write to john.doe@example.com or +7 (912) 345-67-89, card 4111 1111 1111 1111, ask @john_doe. I have 3 cats."
+	got := redactPII(input)
+
+	for _, leaked := range []string{"john.doe@example.com", "345-67-89", "4111", "@john_doe"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("в результате осталось %q: %q", leaked, got)
+		}
+	}
+	if !strings.Contains(got, "I have 3 cats.") {
+		t.Errorf("обычный текст не должен меняться, получено %q", got)
+	}
+}
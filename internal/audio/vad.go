@@ -127,7 +127,7 @@ func (vad *VADProcessor) SplitAudioBySilence(inputFile string, maxSegmentDuratio
 		zap.Float64("max_duration", maxSegmentDuration))
 
 	// Получаем общую длительность аудио
-	totalDuration, err := vad.getAudioDuration(inputFile)
+	totalDuration, err := vad.GetAudioDuration(inputFile)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения длительности аудио: %w", err)
 	}
@@ -163,8 +163,8 @@ func (vad *VADProcessor) SplitAudioBySilence(inputFile string, maxSegmentDuratio
 	return speechSegments, nil
 }
 
-// getAudioDuration получает длительность аудиофайла
-func (vad *VADProcessor) getAudioDuration(inputFile string) (float64, error) {
+// GetAudioDuration получает длительность аудиофайла в секундах
+func (vad *VADProcessor) GetAudioDuration(inputFile string) (float64, error) {
 	cmd := exec.Command("ffprobe",
 		"-v", "quiet",
 		"-show_entries", "format=duration",
@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpiredFiles(t *testing.T) {
+	names := []string{
+		"backup_20260101T000000Z_users.jsonl.gz",
+		"backup_20260101T000000Z_payments.jsonl.gz",
+		"backup_20260102T000000Z_users.jsonl.gz",
+		"backup_20260103T000000Z_users.jsonl.gz",
+		"notes.txt",
+		"backup_broken_users.jsonl.gz",
+	}
+
+	got := expiredFiles(names, 2)
+	expected := []string{
+		"backup_20260101T000000Z_payments.jsonl.gz",
+		"backup_20260101T000000Z_users.jsonl.gz",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ожидалось %v, получено %v", expected, got)
+	}
+
+	if got := expiredFiles(names, 5); len(got) != 0 {
+		t.Errorf("ожидалось отсутствие устаревших файлов, получено %v", got)
+	}
+}
+
+func TestLocalDestination(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	dest, err := NewLocalDestination(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	src := filepath.Join(dir, "src.gz")
+	if err := os.WriteFile(src, []byte("data"), 0o600); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	name := fileName("20260101T000000Z", "users")
+	if err := dest.Put(ctx, name, src); err != nil {
+		t.Fatalf("ошибка сохранения: %v", err)
+	}
+
+	names, err := dest.List(ctx)
+	if err != nil {
+		t.Fatalf("ошибка списка: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{name}) {
+		t.Errorf("ожидался список [%s], получен %v", name, names)
+	}
+
+	if err := dest.Delete(ctx, name); err != nil {
+		t.Fatalf("ошибка удаления: %v", err)
+	}
+	if names, _ := dest.List(ctx); len(names) != 0 {
+		t.Errorf("ожидался пустой каталог, получено %v", names)
+	}
+}
+
+func TestParseS3Endpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		host     string
+		secure   bool
+		wantErr  bool
+	}{
+		{endpoint: "https://storage.yandexcloud.net/", host: "storage.yandexcloud.net", secure: true},
+		{endpoint: "http://localhost:9000", host: "localhost:9000", secure: false},
+		{endpoint: "s3.amazonaws.com", host: "s3.amazonaws.com", secure: true},
+		{endpoint: "https://storage.yandexcloud.net/bucket", wantErr: true},
+		{endpoint: "ftp://example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		host, secure, err := parseS3Endpoint(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: ожидалась ошибка", tt.endpoint)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: неожиданная ошибка: %v", tt.endpoint, err)
+			continue
+		}
+		if host != tt.host || secure != tt.secure {
+			t.Errorf("%s: ожидалось %s (secure=%v), получено %s (secure=%v)", tt.endpoint, tt.host, tt.secure, host, secure)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package backup выгружает ключевые таблицы в сжатый JSON Lines с ротацией
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// filePrefix префикс имен файлов выгрузки
+const filePrefix = "backup_"
+
+// timestampLayout формат метки времени в имени файла
+const timestampLayout = "20060102T150405Z"
+
+// DefaultTables таблицы, которые выгружаются по умолчанию
+var DefaultTables = []string{"users", "payments", "referrals"}
+
+// Querier выполняет запросы к базе (реализуется *pgxpool.Pool)
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Destination хранилище выгрузок
+type Destination interface {
+	// Put сохраняет локальный файл под указанным именем
+	Put(ctx context.Context, name, path string) error
+	// List возвращает имена сохраненных файлов выгрузки
+	List(ctx context.Context) ([]string, error)
+	// Delete удаляет файл выгрузки
+	Delete(ctx context.Context, name string) error
+}
+
+// Result итог одной выгрузки
+type Result struct {
+	Timestamp time.Time
+	Rows      map[string]int64
+	Duration  time.Duration
+	Removed   int // Количество удаленных старых файлов
+}
+
+// Exporter выгружает таблицы в хранилище
+type Exporter struct {
+	db        Querier
+	dest      Destination
+	tables    []string
+	retention int
+	logger    *zap.Logger
+}
+
+// NewExporter создает экспортер таблиц
+func NewExporter(db Querier, dest Destination, tables []string, retention int, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		db:        db,
+		dest:      dest,
+		tables:    tables,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Export выгружает все таблицы и удаляет выгрузки сверх лимита хранения
+func (e *Exporter) Export(ctx context.Context) (*Result, error) {
+	start := time.Now()
+	result := &Result{
+		Timestamp: start.UTC(),
+		Rows:      make(map[string]int64, len(e.tables)),
+	}
+	stamp := result.Timestamp.Format(timestampLayout)
+
+	for _, table := range e.tables {
+		count, err := e.exportTable(ctx, table, fileName(stamp, table))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка выгрузки таблицы %s: %w", table, err)
+		}
+		result.Rows[table] = count
+	}
+
+	removed, err := e.rotate(ctx)
+	if err != nil {
+		// Выгрузка уже сохранена, ошибку ротации только логируем
+		e.logger.Error("ошибка ротации выгрузок", zap.Error(err))
+	}
+	result.Removed = removed
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+// exportTable построчно пишет таблицу во временный gzip-файл и отправляет его в хранилище
+func (e *Exporter) exportTable(ctx context.Context, table, name string) (int64, error) {
+	tmp, err := os.CreateTemp("", "lingua-backup-*.jsonl.gz")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания временного файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	count, err := e.writeTable(ctx, table, tmp)
+	if err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("ошибка закрытия временного файла: %w", err)
+	}
+
+	if err := e.dest.Put(ctx, name, tmp.Name()); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения выгрузки: %w", err)
+	}
+
+	return count, nil
+}
+
+// writeTable стримит строки таблицы как JSON Lines, не загружая таблицу в память
+func (e *Exporter) writeTable(ctx context.Context, table string, f *os.File) (int64, error) {
+	// Имя таблицы берется только из конфигурации экспортера, не из пользовательского ввода
+	query := fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", pgx.Identifier{table}.Sanitize())
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer rows.Close()
+
+	buf := bufio.NewWriter(f)
+	gz := gzip.NewWriter(buf)
+
+	var count int64
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return 0, fmt.Errorf("ошибка чтения строки: %w", err)
+		}
+		if _, err := gz.Write([]byte(line + "\n")); err != nil {
+			return 0, fmt.Errorf("ошибка записи строки: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения строк: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("ошибка сжатия: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	return count, nil
+}
+
+// rotate удаляет файлы выгрузок старше последних retention запусков
+func (e *Exporter) rotate(ctx context.Context) (int, error) {
+	if e.retention <= 0 {
+		return 0, nil
+	}
+
+	names, err := e.dest.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, name := range expiredFiles(names, e.retention) {
+		if err := e.dest.Delete(ctx, name); err != nil {
+			return removed, fmt.Errorf("ошибка удаления %s: %w", name, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// fileName формирует имя файла выгрузки таблицы
+func fileName(stamp, table string) string {
+	return fmt.Sprintf("%s%s_%s.jsonl.gz", filePrefix, stamp, table)
+}
+
+// expiredFiles возвращает файлы, не входящие в последние retention выгрузок
+func expiredFiles(names []string, retention int) []string {
+	byStamp := make(map[string][]string)
+	for _, name := range names {
+		stamp, ok := stampOf(name)
+		if !ok {
+			continue
+		}
+		byStamp[stamp] = append(byStamp[stamp], name)
+	}
+
+	stamps := make([]string, 0, len(byStamp))
+	for stamp := range byStamp {
+		stamps = append(stamps, stamp)
+	}
+	// Формат метки времени сортируется лексикографически
+	sort.Sort(sort.Reverse(sort.StringSlice(stamps)))
+
+	var expired []string
+	for i, stamp := range stamps {
+		if i >= retention {
+			expired = append(expired, byStamp[stamp]...)
+		}
+	}
+	sort.Strings(expired)
+
+	return expired
+}
+
+// stampOf извлекает метку времени из имени файла выгрузки
+func stampOf(name string) (string, bool) {
+	if !strings.HasPrefix(name, filePrefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(name, filePrefix)
+	if len(rest) <= len(timestampLayout) {
+		return "", false
+	}
+
+	stamp := rest[:len(timestampLayout)]
+	if _, err := time.Parse(timestampLayout, stamp); err != nil {
+		return "", false
+	}
+	return stamp, true
+}
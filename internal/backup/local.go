@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalDestination хранит выгрузки в локальном каталоге
+type LocalDestination struct {
+	dir string
+}
+
+// NewLocalDestination создает локальное хранилище выгрузок
+func NewLocalDestination(dir string) (*LocalDestination, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога выгрузок: %w", err)
+	}
+	return &LocalDestination{dir: dir}, nil
+}
+
+// Put копирует файл в каталог выгрузок атомарно через временное имя
+func (d *LocalDestination) Put(ctx context.Context, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла: %w", err)
+	}
+	defer src.Close()
+
+	target := filepath.Join(d.dir, name)
+	tmp := target + ".tmp"
+
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("ошибка копирования файла: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ошибка закрытия файла: %w", err)
+	}
+
+	return os.Rename(tmp, target)
+}
+
+// List возвращает имена файлов выгрузок в каталоге
+func (d *LocalDestination) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога выгрузок: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete удаляет файл выгрузки
+func (d *LocalDestination) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(d.dir, filepath.Base(name)))
+}
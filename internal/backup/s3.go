@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config параметры S3-совместимого хранилища
+type S3Config struct {
+	Endpoint  string // Например https://storage.yandexcloud.net
+	Region    string
+	Bucket    string
+	Prefix    string // Префикс ключей, например lingua/backups/
+	AccessKey string
+	SecretKey string
+}
+
+// S3Destination хранит выгрузки в S3-совместимом хранилище (path-style запросы)
+type S3Destination struct {
+	cfg    S3Config
+	client *minio.Client
+}
+
+// NewS3Destination создает S3-хранилище выгрузок
+func NewS3Destination(cfg S3Config) (*S3Destination, error) {
+	endpoint, secure, err := parseS3Endpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       secure,
+		Region:       cfg.Region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания S3 клиента: %w", err)
+	}
+
+	return &S3Destination{cfg: cfg, client: client}, nil
+}
+
+// Put загружает файл в бакет
+func (d *S3Destination) Put(ctx context.Context, name, path string) error {
+	_, err := d.client.FPutObject(ctx, d.cfg.Bucket, d.cfg.Prefix+name, path, minio.PutObjectOptions{
+		ContentType: "application/gzip",
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки в S3: %w", err)
+	}
+	return nil
+}
+
+// List возвращает имена файлов выгрузок под префиксом
+func (d *S3Destination) List(ctx context.Context) ([]string, error) {
+	var names []string
+	for obj := range d.client.ListObjects(ctx, d.cfg.Bucket, minio.ListObjectsOptions{Prefix: d.cfg.Prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("ошибка получения списка объектов S3: %w", obj.Err)
+		}
+		names = append(names, strings.TrimPrefix(obj.Key, d.cfg.Prefix))
+	}
+	return names, nil
+}
+
+// Delete удаляет объект выгрузки
+func (d *S3Destination) Delete(ctx context.Context, name string) error {
+	if err := d.client.RemoveObject(ctx, d.cfg.Bucket, d.cfg.Prefix+name, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("ошибка удаления объекта S3: %w", err)
+	}
+	return nil
+}
+
+// parseS3Endpoint выделяет хост и схему из адреса хранилища.
+// Адрес без схемы считается HTTPS.
+func parseS3Endpoint(endpoint string) (string, bool, error) {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, true, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", false, fmt.Errorf("некорректный адрес S3: %s", endpoint)
+	}
+	if u.Path != "" {
+		return "", false, fmt.Errorf("адрес S3 не должен содержать путь: %s", endpoint)
+	}
+	switch u.Scheme {
+	case "https":
+		return u.Host, true, nil
+	case "http":
+		return u.Host, false, nil
+	default:
+		return "", false, fmt.Errorf("неподдерживаемая схема адреса S3: %s", u.Scheme)
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// audioExtensions расширения аудиофайлов, которые принимает распознавание речи
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".m4a":  true,
+	".mp4":  true,
+	".aac":  true,
+	".wav":  true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+	".flac": true,
+	".webm": true,
+}
+
+// audioMIMEExtensions расширения для MIME-типов, когда у файла нет имени
+var audioMIMEExtensions = map[string]string{
+	"audio/mpeg":   ".mp3",
+	"audio/mp3":    ".mp3",
+	"audio/mp4":    ".m4a",
+	"audio/m4a":    ".m4a",
+	"audio/x-m4a":  ".m4a",
+	"audio/aac":    ".aac",
+	"audio/wav":    ".wav",
+	"audio/wave":   ".wav",
+	"audio/x-wav":  ".wav",
+	"audio/ogg":    ".ogg",
+	"audio/opus":   ".opus",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+	"audio/webm":   ".webm",
+}
+
+// audioExtension определяет расширение аудиофайла по имени, затем по MIME-типу.
+// Возвращает false, если файл не похож на аудио.
+func audioExtension(fileName, mimeType string) (string, bool) {
+	if ext := strings.ToLower(filepath.Ext(fileName)); audioExtensions[ext] {
+		return ext, true
+	}
+
+	// MIME-тип может содержать параметры: "audio/ogg; codecs=opus"
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	if ext, ok := audioMIMEExtensions[mimeType]; ok {
+		return ext, true
+	}
+	return "", false
+}
+
+// isAudioDocument сообщает, что документ является аудиофайлом
+func isAudioDocument(doc *tgbotapi.Document) bool {
+	if doc == nil {
+		return false
+	}
+	_, ok := audioExtension(doc.FileName, doc.MimeType)
+	return ok
+}
+
+// audioSource описывает файл аудио из сообщения
+type audioSource struct {
+	fileID string
+	ext    string
+	size   int
+}
+
+// audioSourceOf возвращает аудиофайл голосового, аудио сообщения или аудио документа
+func audioSourceOf(message *tgbotapi.Message) (audioSource, bool) {
+	switch {
+	case message.Voice != nil:
+		return audioSource{fileID: message.Voice.FileID, ext: ".ogg", size: message.Voice.FileSize}, true
+	case message.Audio != nil:
+		ext, ok := audioExtension(message.Audio.FileName, message.Audio.MimeType)
+		if !ok {
+			ext = ".mp3"
+		}
+		return audioSource{fileID: message.Audio.FileID, ext: ext, size: message.Audio.FileSize}, true
+	case message.Document != nil:
+		ext, ok := audioExtension(message.Document.FileName, message.Document.MimeType)
+		if !ok {
+			return audioSource{}, false
+		}
+		return audioSource{fileID: message.Document.FileID, ext: ext, size: message.Document.FileSize}, true
+	}
+	return audioSource{}, false
+}
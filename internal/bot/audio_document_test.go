@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestAudioSourceOfDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     tgbotapi.Document
+		wantExt string
+		wantOK  bool
+	}{
+		{"m4a", tgbotapi.Document{FileID: "a", FileName: "lesson.M4A", MimeType: "audio/x-m4a"}, ".m4a", true},
+		{"wav", tgbotapi.Document{FileID: "b", FileName: "speech.wav"}, ".wav", true},
+		{"ogg", tgbotapi.Document{FileID: "c", FileName: "voice.ogg", MimeType: "audio/ogg"}, ".ogg", true},
+		{"ogg без имени", tgbotapi.Document{FileID: "d", MimeType: "audio/ogg; codecs=opus"}, ".ogg", true},
+		{"pdf", tgbotapi.Document{FileID: "e", FileName: "homework.pdf", MimeType: "application/pdf"}, "", false},
+		{"подмена расширения", tgbotapi.Document{FileID: "f", FileName: "../../etc/passwd", MimeType: "text/plain"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := tt.doc
+			source, ok := audioSourceOf(&tgbotapi.Message{Document: &doc})
+			if ok != tt.wantOK {
+				t.Fatalf("ожидалось ok=%v, получено %v", tt.wantOK, ok)
+			}
+			if source.ext != tt.wantExt {
+				t.Errorf("ожидалось расширение %q, получено %q", tt.wantExt, source.ext)
+			}
+			if ok && source.fileID != doc.FileID {
+				t.Errorf("ожидался файл %q, получено %q", doc.FileID, source.fileID)
+			}
+		})
+	}
+}
+
+func TestAudioSourceOfAudioKeepsExtension(t *testing.T) {
+	source, _ := audioSourceOf(&tgbotapi.Message{Audio: &tgbotapi.Audio{FileID: "a", FileName: "track.flac"}})
+	if source.ext != ".flac" {
+		t.Errorf("ожидалось расширение .flac, получено %q", source.ext)
+	}
+
+	source, _ = audioSourceOf(&tgbotapi.Message{Audio: &tgbotapi.Audio{FileID: "b"}})
+	if source.ext != ".mp3" {
+		t.Errorf("без имени ожидалось расширение .mp3, получено %q", source.ext)
+	}
+}
+
+func TestDocumentRouting(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+
+	send := func(doc *tgbotapi.Document) []string {
+		th.sender.reset()
+		err := th.handler.HandleUpdate(context.Background(), tgbotapi.Update{Message: &tgbotapi.Message{
+			MessageID: 2,
+			From:      &tgbotapi.User{ID: 100, FirstName: "Test"},
+			Chat:      &tgbotapi.Chat{ID: 100, Type: "private"},
+			Document:  doc,
+		}})
+		if err != nil {
+			t.Fatalf("ошибка обработки документа: %v", err)
+		}
+		return th.sender.texts()
+	}
+
+	texts := send(&tgbotapi.Document{FileID: "audio", FileName: "voice.m4a", FileSize: 1024})
+	if len(texts) == 0 || !strings.Contains(texts[0], "Обрабатываю аудио") {
+		t.Errorf("аудио документ ожидался в распознавании речи, получено %q", texts)
+	}
+
+	texts = send(&tgbotapi.Document{FileID: "big", FileName: "lecture.wav", FileSize: MaxFileSize + 1})
+	if len(texts) != 2 || !strings.Contains(texts[1], "слишком большой") {
+		t.Errorf("ожидался отказ из-за размера, получено %q", texts)
+	}
+
+	texts = send(&tgbotapi.Document{FileID: "pdf", FileName: "homework.pdf", MimeType: "application/pdf"})
+	if len(texts) != 1 || !strings.Contains(texts[0], "только аудиофайлы") {
+		t.Errorf("ожидался отказ для не аудио документа, получено %q", texts)
+	}
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/whisper"
+	"lingua-ai/pkg/models"
+)
+
+// Режимы проверки языка голосовых сообщений
+const (
+	AudioLanguageCheckOff  = "off"  // язык не проверяется
+	AudioLanguageCheckWarn = "warn" // подсказка, затем обычный ответ
+	AudioLanguageCheckSkip = "skip" // подсказка вместо ответа AI
+)
+
+// DefaultAudioLanguageMinWords минимум слов в распознанном тексте для проверки языка:
+// на коротких фразах Whisper часто ошибается с языком
+const DefaultAudioLanguageMinWords = 3
+
+// maxDebugSegments сколько фрагментов показывает /transcribe_debug
+const maxDebugSegments = 30
+
+// whisperLanguageNames коды языков по полным названиям, которые может вернуть Whisper
+var whisperLanguageNames = map[string]string{
+	"english":   "en",
+	"russian":   "ru",
+	"spanish":   "es",
+	"german":    "de",
+	"french":    "fr",
+	"italian":   "it",
+	"ukrainian": "uk",
+}
+
+// spokenLanguageAdverbs «по-русски» и т.п. для подсказки о языке речи
+var spokenLanguageAdverbs = map[string]string{
+	"en": "по-английски",
+	"ru": "по-русски",
+	"es": "по-испански",
+	"de": "по-немецки",
+	"fr": "по-французски",
+	"it": "по-итальянски",
+	"uk": "по-украински",
+}
+
+// transcriptionLog последние распознавания пользователей для /transcribe_debug
+type transcriptionLog struct {
+	mu   sync.Mutex
+	last map[int64]*whisper.TranscribeResponse
+}
+
+func newTranscriptionLog() *transcriptionLog {
+	return &transcriptionLog{last: make(map[int64]*whisper.TranscribeResponse)}
+}
+
+func (l *transcriptionLog) put(userID int64, transcription *whisper.TranscribeResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.last[userID] = transcription
+}
+
+func (l *transcriptionLog) get(userID int64) (*whisper.TranscribeResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	transcription, ok := l.last[userID]
+	return transcription, ok
+}
+
+// SetAudioLanguageCheck задает реакцию на голосовые не на изучаемом языке
+// и минимальную длину распознанного текста для проверки
+func (h *Handler) SetAudioLanguageCheck(mode string, minWords int) {
+	h.audioLanguageCheck = mode
+	h.audioLanguageMinWords = minWords
+}
+
+// normalizeWhisperLanguage приводит язык из ответа Whisper к двухбуквенному коду
+func normalizeWhisperLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, ok := whisperLanguageNames[language]; ok {
+		return code
+	}
+	return language
+}
+
+// audioLanguageHint возвращает подсказку, если речь распознана не на изучаемом языке.
+// Пустая строка — язык совпадает, не определен или текст слишком короткий для проверки.
+func (h *Handler) audioLanguageHint(transcription *whisper.TranscribeResponse, user *models.User) string {
+	if h.audioLanguageCheck == AudioLanguageCheckOff {
+		return ""
+	}
+
+	detected := normalizeWhisperLanguage(transcription.Language)
+	learning := models.GetLearningLanguage(user.LearningLanguage)
+	if detected == "" || detected == learning.Code {
+		return ""
+	}
+	if len(strings.Fields(transcription.Text)) < h.audioLanguageMinWords {
+		return ""
+	}
+
+	spoken := "не на " + learning.Prepositional
+	if adverb, ok := spokenLanguageAdverbs[detected]; ok {
+		spoken = adverb
+	}
+	text := fmt.Sprintf("🗣 Похоже, вы говорили %s. Попробуйте сказать то же самое на %s — так практика будет полезнее!",
+		spoken, learning.Prepositional)
+	if h.audioLanguageCheck == AudioLanguageCheckSkip {
+		text += "\n\n💡 Голосовые на других языках я не разбираю — можно написать вопрос текстом."
+	}
+	return text
+}
+
+// handleTranscribeDebugCommand показывает язык и тайминги фрагментов последнего голосового
+func (h *Handler) handleTranscribeDebugCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+
+	if !h.featureEnabled(user, premium.FeatureTranscribeDebug) {
+		return h.sendMessage(chatID, premiumFeatureHint(premium.FeatureTranscribeDebug))
+	}
+
+	transcription, ok := h.transcriptions.get(user.ID)
+	if !ok {
+		return h.sendMessage(chatID, "🎤 Сначала отправьте голосовое сообщение, а затем повторите /transcribe_debug.")
+	}
+	return h.sendMessage(chatID, formatTranscriptionDebug(transcription))
+}
+
+// formatTranscriptionDebug форматирует язык, длительность и фрагменты распознавания
+func formatTranscriptionDebug(transcription *whisper.TranscribeResponse) string {
+	var b strings.Builder
+	b.WriteString("🔬 <b>Разбор распознавания</b>\n\n")
+
+	language := transcription.Language
+	if language == "" {
+		language = "не определен"
+	}
+	fmt.Fprintf(&b, "🌐 Язык: <b>%s</b>\n", html.EscapeString(language))
+	if transcription.Duration > 0 {
+		fmt.Fprintf(&b, "⏱ Длительность: %.1f с\n", transcription.Duration)
+	}
+
+	if len(transcription.Segments) == 0 {
+		b.WriteString("\nФрагменты в ответе Whisper отсутствуют.")
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	for i, segment := range transcription.Segments {
+		if i == maxDebugSegments {
+			fmt.Fprintf(&b, "… и еще %d", len(transcription.Segments)-maxDebugSegments)
+			break
+		}
+		fmt.Fprintf(&b, "<code>%s–%s</code> %s\n",
+			formatSegmentTime(segment.Start), formatSegmentTime(segment.End),
+			html.EscapeString(strings.TrimSpace(segment.Text)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatSegmentTime форматирует секунды как мм:сс.д
+func formatSegmentTime(seconds float64) string {
+	tenths := int(seconds*10 + 0.5)
+	return fmt.Sprintf("%02d:%02d.%d", tenths/600, tenths/10%60, tenths%10)
+}
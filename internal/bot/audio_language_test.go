@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/whisper"
+	"lingua-ai/pkg/models"
+)
+
+func TestAudioLanguageHint(t *testing.T) {
+	th := newTestHarness(t)
+	user := &models.User{LearningLanguage: models.LanguageEnglish}
+	russian := &whisper.TranscribeResponse{Language: "ru", Text: "привет как у тебя дела"}
+
+	if hint := th.handler.audioLanguageHint(russian, user); !strings.Contains(hint, "по-русски") || !strings.Contains(hint, "на английском") {
+		t.Errorf("ожидалась подсказка о русской речи, получено %q", hint)
+	}
+	if hint := th.handler.audioLanguageHint(&whisper.TranscribeResponse{Language: "English", Text: "how are you doing"}, user); hint != "" {
+		t.Errorf("речь на изучаемом языке не требует подсказки, получено %q", hint)
+	}
+	if hint := th.handler.audioLanguageHint(&whisper.TranscribeResponse{Language: "ru", Text: "да"}, user); hint != "" {
+		t.Errorf("короткую фразу не проверяем, получено %q", hint)
+	}
+	if hint := th.handler.audioLanguageHint(&whisper.TranscribeResponse{Language: "pl", Text: "jak się masz dzisiaj"}, user); !strings.Contains(hint, "не на английском") {
+		t.Errorf("для языка без названия ожидалась общая подсказка, получено %q", hint)
+	}
+
+	th.handler.SetAudioLanguageCheck(AudioLanguageCheckOff, 0)
+	if hint := th.handler.audioLanguageHint(russian, user); hint != "" {
+		t.Errorf("выключенная проверка не должна подсказывать, получено %q", hint)
+	}
+}
+
+func TestFormatTranscriptionDebug(t *testing.T) {
+	transcription := &whisper.TranscribeResponse{
+		Language: "en",
+		Duration: 4.25,
+		Segments: []whisper.Segment{
+			{Start: 0, End: 2.3, Text: " Hello <there>"},
+			{Start: 62.04, End: 65.5, Text: " bye"},
+		},
+	}
+
+	got := formatTranscriptionDebug(transcription)
+	for _, part := range []string{"Язык: <b>en</b>", "4.2 с", "<code>00:00.0–00:02.3</code> Hello &lt;there&gt;", "<code>01:02.0–01:05.5</code> bye"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("ожидалось %q в %q", part, got)
+		}
+	}
+}
+
+func TestTranscribeDebugCommand(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	user := th.user(t, 100)
+	th.sender.reset()
+
+	th.sendText(t, 100, "/transcribe_debug")
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "/premium") {
+		t.Fatalf("без премиума ожидалась подсказка, получено %q", texts)
+	}
+
+	th.handler.SetPremiumFeatures(premium.NewFeatureGate())
+	th.sender.reset()
+	th.sendText(t, 100, "/transcribe_debug")
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "Сначала отправьте голосовое") {
+		t.Fatalf("без голосовых ожидалась подсказка, получено %q", texts)
+	}
+
+	th.handler.transcriptions.put(user.ID, &whisper.TranscribeResponse{Language: "en", Segments: []whisper.Segment{{End: 1, Text: "hi"}}})
+	th.sender.reset()
+	th.sendText(t, 100, "/transcribe_debug")
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "Разбор распознавания") {
+		t.Fatalf("ожидался разбор последнего голосового, получено %q", texts)
+	}
+}
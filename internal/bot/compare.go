@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// minCompareUsers меньше учеников — средние почти совпадают с показателями отдельных людей, не показываем их
+const minCompareUsers = 10
+
+// compareMetric показатель ученика и средний по платформе
+type compareMetric struct {
+	icon    string
+	name    string
+	value   int
+	average float64
+	deciles []int
+}
+
+// handleCompareCommand обрабатывает команду /compare — сравнение с другими учениками
+func (h *Handler) handleCompareCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+
+	h.updateStudyActivity(user)
+
+	stats, err := h.userService.PlatformStats(ctx)
+	if err != nil {
+		h.logger.Error("ошибка получения средних показателей", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Не удалось получить статистику, попробуй позже")
+	}
+	if stats.Users < minCompareUsers {
+		return h.sendMessage(chatID, "👥 Пока учеников слишком мало для честного сравнения. Загляни позже — а пока продолжай заниматься!")
+	}
+
+	words, err := h.flashcardHandler.flashcardService.LearnedWordsCount(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения выученных слов для сравнения", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось получить статистику, попробуй позже")
+	}
+
+	return h.sendMessage(chatID, compareText(stats, []compareMetric{
+		{icon: "⭐", name: "XP", value: user.XP, average: stats.AvgXP, deciles: stats.XPDeciles},
+		{icon: "🔥", name: "Серия", value: user.StudyStreak, average: stats.AvgStreak, deciles: stats.StreakDeciles},
+		{icon: "📚", name: "Выучено слов", value: words, average: stats.AvgWords, deciles: stats.WordsDeciles},
+	}))
+}
+
+// compareText оформляет сравнение: по каждому показателю — значение, среднее и место среди учеников.
+// Отстающим не называем место, а показываем, сколько осталось до среднего.
+func compareText(stats *models.PlatformStats, metrics []compareMetric) string {
+	var b strings.Builder
+	b.WriteString("📊 <b>Ты и другие ученики</b>\n")
+	fmt.Fprintf(&b, "<i>Среди %d учеников, занимавшихся за последний месяц</i>\n\n", stats.Users)
+
+	best := 0
+	for _, m := range metrics {
+		average := int(math.Round(m.average))
+		rank := models.PercentileRank(m.deciles, m.value)
+		best = max(best, rank)
+
+		fmt.Fprintf(&b, "%s <b>%s:</b> %d · в среднем %d\n", m.icon, m.name, m.value, average)
+		switch {
+		case rank >= 50:
+			fmt.Fprintf(&b, "    ↳ больше, чем у %d%% учеников 💪\n", rank)
+		case m.value >= average:
+			b.WriteString("    ↳ на уровне среднего 👍\n")
+		default:
+			fmt.Fprintf(&b, "    ↳ до среднего осталось %d\n", average-m.value)
+		}
+	}
+
+	b.WriteString("\n")
+	switch {
+	case best >= 90:
+		b.WriteString("🏆 Ты в числе самых упорных учеников — так держать!")
+	case best >= 50:
+		b.WriteString("🚀 Ты опережаешь большинство по одному из показателей. Подтяни остальные — и будешь в лидерах!")
+	default:
+		b.WriteString("🌱 Каждый начинал с нуля. Пара сообщений и карточек в день — и ты быстро догонишь среднего ученика!")
+	}
+	return b.String()
+}
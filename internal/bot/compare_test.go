@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+var testPlatformStats = &models.PlatformStats{
+	Users:         120,
+	AvgXP:         340.4,
+	AvgStreak:     2.4,
+	AvgWords:      25,
+	XPDeciles:     []int{10, 30, 60, 100, 180, 300, 450, 700, 1200},
+	StreakDeciles: []int{0, 1, 1, 2, 3, 4, 5, 8, 14},
+	WordsDeciles:  []int{0, 2, 5, 9, 15, 22, 35, 50, 90},
+}
+
+func TestCompareTextLeader(t *testing.T) {
+	text := compareText(testPlatformStats, []compareMetric{
+		{icon: "⭐", name: "XP", value: 1500, average: testPlatformStats.AvgXP, deciles: testPlatformStats.XPDeciles},
+		{icon: "📚", name: "Выучено слов", value: 10, average: testPlatformStats.AvgWords, deciles: testPlatformStats.WordsDeciles},
+	})
+
+	for _, want := range []string{"Среди 120 учеников", "XP:</b> 1500 · в среднем 340", "больше, чем у 90% учеников", "до среднего осталось 15", "🏆"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("ожидалось %q в тексте:\n%s", want, text)
+		}
+	}
+}
+
+func TestCompareTextBeginnerIsEncouraged(t *testing.T) {
+	text := compareText(testPlatformStats, []compareMetric{
+		{icon: "⭐", name: "XP", value: 5, average: testPlatformStats.AvgXP, deciles: testPlatformStats.XPDeciles},
+		{icon: "🔥", name: "Серия", value: 2, average: testPlatformStats.AvgStreak, deciles: testPlatformStats.StreakDeciles},
+	})
+
+	if strings.Contains(text, "% учеников") {
+		t.Errorf("отстающему ученику не показываем место:\n%s", text)
+	}
+	if !strings.Contains(text, "на уровне среднего") || !strings.Contains(text, "🌱") {
+		t.Errorf("ожидалось ободряющее сравнение:\n%s", text)
+	}
+}
+
+// compareUsers пользователи со средними показателями платформы
+type compareUsers struct {
+	*memoryUsers
+	stats *models.PlatformStats
+}
+
+func (r *compareUsers) GetPlatformStats(ctx context.Context, activeSince time.Time) (*models.PlatformStats, error) {
+	return r.stats, nil
+}
+
+// learnedWordsRepo число выученных слов пользователя
+type learnedWordsRepo struct {
+	store.FlashcardRepository
+	learned int
+}
+
+func (r *learnedWordsRepo) GetLearnedWordsCount(ctx context.Context, userID int64) (int, error) {
+	return r.learned, nil
+}
+
+// compareStore хранилище с пользователями, у которых есть средние показатели
+type compareStore struct {
+	*memoryStore
+	users *compareUsers
+}
+
+func (s *compareStore) User() store.UserRepository { return s.users }
+
+func TestCompareCommand(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		stats *models.PlatformStats
+		want  string
+	}{
+		{"достаточно учеников", testPlatformStats, "Выучено слов:</b> 40 · в среднем 25"},
+		{"мало учеников", &models.PlatformStats{Users: minCompareUsers - 1}, "слишком мало"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			th := newTestHarness(t)
+			logger := zaptest.NewLogger(t)
+			th.handler.userService = user.NewService(&compareStore{
+				memoryStore: th.store,
+				users:       &compareUsers{memoryUsers: th.store.users, stats: tt.stats},
+			}, logger)
+			th.handler.flashcardHandler.flashcardService = flashcards.NewService(&learnedWordsRepo{learned: 40}, flashcards.DefaultSpacedRepetitionConfig, logger)
+
+			th.sendText(t, 100, "/compare")
+
+			texts := th.sender.texts()
+			if len(texts) == 0 || !strings.Contains(texts[len(texts)-1], tt.want) {
+				t.Errorf("ожидалось %q, получено %q", tt.want, texts)
+			}
+		})
+	}
+}
@@ -2,16 +2,38 @@ package bot
 
 import (
 	"strings"
+	"sync"
 	"time"
 )
 
+// Параметры памяти диалога по умолчанию
+const (
+	DefaultDialogMaxMessages = 20 // После скольких сообщений старые сворачиваются в краткое содержание
+	DefaultDialogKeepRecent  = 8  // Сколько последних сообщений всегда передается AI дословно
+)
+
+// dialogStaleAfter через сколько без сообщений разговор начинается заново
+const dialogStaleAfter = time.Hour
+
 // DialogContext содержит контекст диалога с пользователем
 type DialogContext struct {
 	UserID       int64
 	Level        string
 	SystemPrompt string
 	Messages     []DialogMessage
+	Summary      string // краткое содержание ранней части разговора
 	LastActivity time.Time
+
+	mu          sync.Mutex
+	summarizing bool // идет фоновое сворачивание старых сообщений
+	generation  int  // увеличивается при очистке истории, чтобы отбросить устаревшее сворачивание
+	summaryGen  int  // поколение истории, для которого запущено сворачивание
+
+	// Когда последний раз просили писать развернуто
+	shortHintAt time.Time
+
+	// Темы, на которые в этом разговоре уже предлагали начать беседу
+	starterTopics map[string]bool
 }
 
 // DialogMessage представляет сообщение в диалоге
@@ -34,40 +56,106 @@ func NewDialogContext(userID int64, level string, systemPrompt string) *DialogCo
 
 // AddUserMessage добавляет сообщение пользователя в контекст
 func (dc *DialogContext) AddUserMessage(content string) {
-	dc.Messages = append(dc.Messages, DialogMessage{
-		Role:      "user",
-		Content:   content,
-		Timestamp: time.Now(),
-	})
-	dc.LastActivity = time.Now()
+	dc.addMessage("user", content)
 }
 
 // AddAssistantMessage добавляет ответ ассистента в контекст
 func (dc *DialogContext) AddAssistantMessage(content string) {
+	dc.addMessage("assistant", content)
+}
+
+// addMessage добавляет сообщение в контекст
+func (dc *DialogContext) addMessage(role, content string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
 	dc.Messages = append(dc.Messages, DialogMessage{
-		Role:      "assistant",
+		Role:      role,
 		Content:   content,
 		Timestamp: time.Now(),
 	})
 	dc.LastActivity = time.Now()
 }
 
+// Snapshot возвращает краткое содержание и копию сообщений, еще не свернутых в него
+func (dc *DialogContext) Snapshot() (string, []DialogMessage) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	messages := make([]DialogMessage, len(dc.Messages))
+	copy(messages, dc.Messages)
+	return dc.Summary, messages
+}
+
+// BeginSummary проверяет, пора ли свернуть старые сообщения. Если да, помечает
+// контекст как сворачиваемый и возвращает текущее краткое содержание и сообщения,
+// которые нужно в него добавить; последние keepRecent сообщений остаются дословно.
+func (dc *DialogContext) BeginSummary(maxMessages, keepRecent int) (string, []DialogMessage, bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if dc.summarizing || maxMessages <= 0 || len(dc.Messages) <= maxMessages {
+		return "", nil, false
+	}
+
+	count := len(dc.Messages) - max(keepRecent, 0)
+	if count <= 0 {
+		return "", nil, false
+	}
+
+	dc.summarizing = true
+	dc.summaryGen = dc.generation
+	oldest := make([]DialogMessage, count)
+	copy(oldest, dc.Messages[:count])
+	return dc.Summary, oldest, true
+}
+
+// ApplySummary сохраняет новое краткое содержание и удаляет count самых старых
+// сообщений, которые в него вошли. Сообщения, добавленные во время сворачивания, сохраняются.
+func (dc *DialogContext) ApplySummary(summary string, count int) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	dc.summarizing = false
+	if dc.summaryGen != dc.generation || count > len(dc.Messages) {
+		// История была очищена во время сворачивания — результат уже неактуален
+		return
+	}
+
+	dc.Summary = summary
+	dc.Messages = append([]DialogMessage(nil), dc.Messages[count:]...)
+}
+
+// AbortSummary снимает отметку сворачивания после ошибки, чтобы повторить позже
+func (dc *DialogContext) AbortSummary() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	dc.summarizing = false
+}
+
 // BuildFullPrompt строит полный промпт для AI с учетом контекста
 func (dc *DialogContext) BuildFullPrompt() string {
+	summary, messages := dc.Snapshot()
+
 	var prompt strings.Builder
 
 	// Добавляем системный промпт
 	prompt.WriteString(dc.SystemPrompt)
 	prompt.WriteString("\n\n")
 
+	if summary != "" {
+		prompt.WriteString("Summary: " + summary + "\n")
+	}
+
 	// Добавляем историю диалога (последние 10 сообщений для экономии токенов)
 	start := 0
-	if len(dc.Messages) > 10 {
-		start = len(dc.Messages) - 10
+	if len(messages) > 10 {
+		start = len(messages) - 10
 	}
 
-	for i := start; i < len(dc.Messages); i++ {
-		msg := dc.Messages[i]
+	for i := start; i < len(messages); i++ {
+		msg := messages[i]
 		if msg.Role == "user" {
 			prompt.WriteString("User: " + msg.Content + "\n")
 		} else {
@@ -80,11 +168,19 @@ func (dc *DialogContext) BuildFullPrompt() string {
 
 // IsStale проверяет, не устарел ли контекст (больше 1 часа)
 func (dc *DialogContext) IsStale() bool {
-	return time.Since(dc.LastActivity) > time.Hour
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	return time.Since(dc.LastActivity) > dialogStaleAfter
 }
 
-// ClearHistory очищает историю сообщений, оставляя системный промпт
+// ClearHistory очищает историю сообщений и краткое содержание, оставляя системный промпт
 func (dc *DialogContext) ClearHistory() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
 	dc.Messages = make([]DialogMessage, 0)
+	dc.Summary = ""
+	dc.generation++
 	dc.LastActivity = time.Now()
 }
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func fillDialog(dc *DialogContext, pairs int, offset int) {
+	for i := 0; i < pairs; i++ {
+		dc.AddUserMessage(fmt.Sprintf("user %d", offset+i))
+		dc.AddAssistantMessage(fmt.Sprintf("assistant %d", offset+i))
+	}
+}
+
+func TestDialogSummaryKeepsContinuity(t *testing.T) {
+	dc := NewDialogContext(1, "beginner", "system")
+	fillDialog(dc, 6, 0) // 12 сообщений
+
+	if _, _, ok := dc.BeginSummary(20, 4); ok {
+		t.Fatal("сворачивание не должно запускаться, пока история короче лимита")
+	}
+
+	previous, oldest, ok := dc.BeginSummary(10, 4)
+	if !ok {
+		t.Fatal("ожидалось сворачивание при превышении лимита")
+	}
+	if previous != "" || len(oldest) != 8 {
+		t.Fatalf("ожидалось 8 старых сообщений без прошлого содержания, получено %d и %q", len(oldest), previous)
+	}
+
+	// Повторный запуск во время сворачивания не допускается
+	if _, _, ok := dc.BeginSummary(10, 4); ok {
+		t.Fatal("сворачивание не должно запускаться дважды")
+	}
+
+	// Пока AI сворачивает историю, разговор продолжается
+	dc.AddUserMessage("user during summary")
+	dc.ApplySummary("ученик рассказал про 0-3", len(oldest))
+
+	summary, recent := dc.Snapshot()
+	if summary != "ученик рассказал про 0-3" {
+		t.Errorf("ожидалось сохраненное краткое содержание, получено %q", summary)
+	}
+
+	want := []string{"user 4", "assistant 4", "user 5", "assistant 5", "user during summary"}
+	if len(recent) != len(want) {
+		t.Fatalf("ожидалось %d последних сообщений, получено %d", len(want), len(recent))
+	}
+	for i, content := range want {
+		if recent[i].Content != content {
+			t.Errorf("сообщение %d: ожидалось %q, получено %q", i, content, recent[i].Content)
+		}
+	}
+
+	// AI получает системный промпт, краткое содержание и последние сообщения по порядку
+	messages := dialogAIMessages("system", summary, recent)
+	if len(messages) != len(want)+2 {
+		t.Fatalf("ожидалось %d сообщений для AI, получено %d", len(want)+2, len(messages))
+	}
+	if messages[0].Content != "system" || messages[1].Role != "system" {
+		t.Error("первыми должны идти системный промпт и краткое содержание")
+	}
+	if last := messages[len(messages)-1]; last.Role != "user" || last.Content != "user during summary" {
+		t.Errorf("последним должно идти текущее сообщение пользователя, получено %+v", last)
+	}
+
+	// Следующее сворачивание получает прошлое краткое содержание для объединения
+	fillDialog(dc, 4, 6)
+	previous, _, ok = dc.BeginSummary(10, 4)
+	if !ok || previous != "ученик рассказал про 0-3" {
+		t.Errorf("ожидалось прошлое краткое содержание, получено %q (ok=%v)", previous, ok)
+	}
+}
+
+func TestDialogSummaryDiscardedAfterClear(t *testing.T) {
+	dc := NewDialogContext(1, "beginner", "system")
+	fillDialog(dc, 6, 0)
+
+	_, oldest, ok := dc.BeginSummary(10, 4)
+	if !ok {
+		t.Fatal("ожидалось сворачивание")
+	}
+
+	dc.ClearHistory()
+	fillDialog(dc, 5, 100)
+	dc.ApplySummary("устаревшее", len(oldest))
+
+	summary, recent := dc.Snapshot()
+	if summary != "" || len(recent) != 10 {
+		t.Errorf("после очистки сворачивание не должно применяться: %q, %d сообщений", summary, len(recent))
+	}
+
+	if _, _, ok := dc.BeginSummary(10, 4); ok {
+		t.Error("история из 10 сообщений не превышает лимит")
+	}
+	dc.AddUserMessage("еще одно")
+	if _, _, ok := dc.BeginSummary(10, 4); !ok {
+		t.Error("после отброшенного сворачивания можно запускать новое")
+	}
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Параметры объяснений исправлений
+const (
+	CorrectionContextTTL   = 24 * time.Hour // Сколько хранится контекст исправления для кнопки «Почему?»
+	MaxCorrectionFollowUps = 2              // Сколько объяснений можно запросить по одному исправлению
+	correctionCallbackPref = "why_"
+	correctionMark         = "✏️" // Метка строки с исправленной фразой в ответе на сообщение ученика
+)
+
+// correctionKey ключ контекста исправления: пользователь и его сообщение
+type correctionKey struct {
+	userID    int64
+	messageID int
+}
+
+// correctionContext исходный текст пользователя и ответ бота с исправлением
+type correctionContext struct {
+	original  string
+	corrected string
+	level     string
+	followUps int
+	createdAt time.Time
+}
+
+// correctionStore хранит контексты исправлений для кнопки «Почему?»
+type correctionStore struct {
+	mu      sync.Mutex
+	entries map[correctionKey]*correctionContext
+	now     func() time.Time
+}
+
+// newCorrectionStore создает хранилище контекстов исправлений
+func newCorrectionStore() *correctionStore {
+	return &correctionStore{
+		entries: make(map[correctionKey]*correctionContext),
+		now:     time.Now,
+	}
+}
+
+// save сохраняет контекст исправления и удаляет устаревшие записи
+func (s *correctionStore) save(key correctionKey, original, corrected, level string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, entry := range s.entries {
+		if now.Sub(entry.createdAt) > CorrectionContextTTL {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[key] = &correctionContext{
+		original:  original,
+		corrected: corrected,
+		level:     level,
+		createdAt: now,
+	}
+}
+
+// take резервирует одно объяснение по исправлению.
+// Возвращает копию контекста и номер объяснения (с 1), либо false, если
+// контекст устарел или лимит объяснений исчерпан.
+func (s *correctionStore) take(key correctionKey) (correctionContext, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || s.now().Sub(entry.createdAt) > CorrectionContextTTL {
+		delete(s.entries, key)
+		return correctionContext{}, 0, false
+	}
+	if entry.followUps >= MaxCorrectionFollowUps {
+		return correctionContext{}, 0, false
+	}
+
+	entry.followUps++
+	return *entry, entry.followUps, true
+}
+
+// correctionButtonRow кнопка «Почему?» под ответом с исправлением
+func correctionButtonRow(messageID int, label string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(label, correctionCallbackPref+strconv.Itoa(messageID)),
+	)
+}
+
+// hasCorrection проверяет, что в ответе есть строка с исправлением фразы ученика
+func hasCorrection(response string) bool {
+	return strings.Contains(response, correctionMark)
+}
+
+// rememberCorrection сохраняет контекст ответа на английское сообщение и возвращает кнопку «Почему?».
+// Если ответ ничего не исправляет, кнопка не нужна и возвращается nil.
+func (h *Handler) rememberCorrection(message *tgbotapi.Message, user *models.User, response string) []tgbotapi.InlineKeyboardButton {
+	if !hasCorrection(response) {
+		return nil
+	}
+	english := postProcessText(response, historyOptions)
+	h.corrections.save(correctionKey{userID: user.ID, messageID: message.MessageID}, message.Text, english, user.Level)
+	return correctionButtonRow(message.MessageID, "❓ Почему?")
+}
+
+// handleCorrectionCallback объясняет правило, стоящее за исправлением
+func (h *Handler) handleCorrectionCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	messageID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, correctionCallbackPref))
+	if err != nil {
+		h.logger.Warn("некорректный callback объяснения", zap.String("data", callback.Data))
+		return nil
+	}
+
+	correction, attempt, ok := h.corrections.take(correctionKey{userID: user.ID, messageID: messageID})
+	if !ok {
+		return h.sendMessage(chatID, "🤷 Объяснение по этому сообщению больше недоступно. Напиши новую фразу — и я разберу ее!")
+	}
+
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.prompts.GetCorrectionExplanationPrompt(correction.level, user.LearningLanguage, attempt > 1)},
+		{Role: "user", Content: fmt.Sprintf("Фраза ученика: %s\n\nОтвет учителя: %s", correction.original, correction.corrected)},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.3,
+		MaxTokens:   400,
+	})
+	h.aiMetrics.RecordAIRequest("correction_explanation", err == nil, time.Since(start).Seconds())
+
+	if err != nil {
+		h.logger.Error("ошибка генерации объяснения исправления", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось получить объяснение, попробуй позже")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, postProcessText(response.Content, aiTextOptions))
+	msg.ParseMode = "HTML"
+	if attempt < MaxCorrectionFollowUps {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(correctionButtonRow(messageID, "🔍 Объясни по-другому"))
+	}
+
+	_, err = h.sender.Send(msg)
+	return err
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorrectionStoreLimitsFollowUps(t *testing.T) {
+	store := newCorrectionStore()
+	key := correctionKey{userID: 1, messageID: 10}
+	store.save(key, "I goes home", "I go home", "beginner")
+
+	for i := 1; i <= MaxCorrectionFollowUps; i++ {
+		ctx, attempt, ok := store.take(key)
+		if !ok {
+			t.Fatalf("объяснение %d: ожидался доступный контекст", i)
+		}
+		if attempt != i {
+			t.Errorf("ожидался номер объяснения %d, получено %d", i, attempt)
+		}
+		if ctx.original != "I goes home" {
+			t.Errorf("ожидался исходный текст, получено %q", ctx.original)
+		}
+	}
+
+	if _, _, ok := store.take(key); ok {
+		t.Error("после исчерпания лимита объяснение должно быть недоступно")
+	}
+}
+
+func TestCorrectionStoreExpires(t *testing.T) {
+	now := time.Now()
+	store := newCorrectionStore()
+	store.now = func() time.Time { return now }
+
+	key := correctionKey{userID: 1, messageID: 10}
+	store.save(key, "a", "b", "beginner")
+
+	now = now.Add(CorrectionContextTTL + time.Minute)
+	if _, _, ok := store.take(key); ok {
+		t.Error("устаревший контекст не должен возвращаться")
+	}
+
+	if _, _, ok := store.take(correctionKey{userID: 2, messageID: 10}); ok {
+		t.Error("контекст другого пользователя не должен возвращаться")
+	}
+}
+
+func TestCorrectionButtonOnlyForCorrectedReplies(t *testing.T) {
+	hasWhy := func(th *testHarness) bool {
+		for _, data := range th.sender.callbacks() {
+			if strings.HasPrefix(data, correctionCallbackPref) {
+				return true
+			}
+		}
+		return false
+	}
+
+	th := newTestHarness(t, "<b>Great! Do you like coffee too?</b>\n\n<tg-spoiler>🇷🇺 Отлично!</tg-spoiler>")
+	th.sendText(t, 100, "I like green tea very much")
+	if hasWhy(th) {
+		t.Error("ответ без исправления не должен предлагать кнопку «Почему?»")
+	}
+
+	th = newTestHarness(t, "✏️ I go home\n<b>Nice! When do you go home?</b>\n\n<tg-spoiler>🇷🇺 Отлично!</tg-spoiler>")
+	th.sendText(t, 100, "I goes home")
+	if !hasWhy(th) {
+		t.Error("ответ с исправлением должен предлагать кнопку «Почему?»")
+	}
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"context"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// aiAvailability AI клиент, который знает, доступен ли провайдер (ai.CircuitBreaker)
+type aiAvailability interface {
+	Available() bool
+}
+
+// aiAvailable сообщает, можно ли отправлять запросы AI.
+// Клиент без проверки доступности считается всегда доступным.
+func (h *Handler) aiAvailable() bool {
+	checker, ok := h.aiClient.(aiAvailability)
+	return !ok || checker.Available()
+}
+
+// sendOfflineMode сообщает, что чат временно недоступен, и предлагает занятия без AI
+func (h *Handler) sendOfflineMode(ctx context.Context, chatID int64, user *models.User) error {
+	h.logger.Info("AI недоступен, предлагаем занятия без AI", zap.Int64("user_id", user.ID))
+
+	msg := tgbotapi.NewMessage(chatID, h.messages.AIUnavailable())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = inlineKeyboard(offlineMenuLayout)
+
+	_, err := h.sender.Send(msg)
+	return err
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/internal/ai"
+)
+
+// stubAI AI клиент с управляемой доступностью
+type stubAI struct {
+	ai.AIClient
+	available bool
+}
+
+func (s stubAI) Available() bool { return s.available }
+
+// plainAI AI клиент без проверки доступности
+type plainAI struct{ ai.AIClient }
+
+func (plainAI) GenerateResponse(ctx context.Context, messages []ai.Message, options ai.GenerationOptions) (*ai.Response, error) {
+	return &ai.Response{}, nil
+}
+
+func TestAIAvailable(t *testing.T) {
+	tests := []struct {
+		client ai.AIClient
+		want   bool
+	}{
+		{stubAI{available: true}, true},
+		{stubAI{available: false}, false},
+		{plainAI{}, true},
+	}
+	for _, tt := range tests {
+		h := &Handler{aiClient: tt.client}
+		if got := h.aiAvailable(); got != tt.want {
+			t.Errorf("%T: ожидалось %v, получено %v", tt.client, tt.want, got)
+		}
+	}
+}
+
+func TestOfflineMenuHasInlineButtons(t *testing.T) {
+	keyboard := inlineKeyboard(offlineMenuLayout)
+
+	buttons := 0
+	for _, row := range keyboard.InlineKeyboard {
+		buttons += len(row)
+	}
+	want := 0
+	for _, row := range offlineMenuLayout {
+		want += len(row)
+	}
+	if buttons != want {
+		t.Errorf("ожидалось %d кнопок занятий без AI, получено %d", want, buttons)
+	}
+}
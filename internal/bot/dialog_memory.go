@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/ai"
+
+	"go.uber.org/zap"
+)
+
+// dialogSummaryTimeout ограничивает фоновое сворачивание истории
+const dialogSummaryTimeout = 30 * time.Second
+
+// SetDialogMemory задает, после скольких сообщений история сворачивается
+// и сколько последних сообщений передается AI дословно
+func (h *Handler) SetDialogMemory(maxMessages, keepRecent int) {
+	if keepRecent < 0 {
+		keepRecent = 0
+	}
+	if maxMessages > 0 && keepRecent >= maxMessages {
+		keepRecent = maxMessages - 1
+	}
+	h.dialogMaxMessages = maxMessages
+	h.dialogKeepRecent = keepRecent
+}
+
+// dialogAIMessages собирает сообщения для AI: системный промпт, краткое содержание
+// ранней части разговора и последние сообщения дословно
+func dialogAIMessages(systemPrompt, summary string, recent []DialogMessage) []ai.Message {
+	messages := make([]ai.Message, 0, len(recent)+2)
+	messages = append(messages, ai.Message{Role: "system", Content: systemPrompt})
+
+	if summary != "" {
+		messages = append(messages, ai.Message{
+			Role:    "system",
+			Content: "Краткое содержание предыдущей части разговора с учеником:\n" + summary,
+		})
+	}
+
+	for _, msg := range recent {
+		messages = append(messages, ai.Message{Role: msg.Role, Content: msg.Content})
+	}
+	return messages
+}
+
+// maybeSummarizeDialog запускает фоновое сворачивание старых сообщений, если история выросла
+func (h *Handler) maybeSummarizeDialog(dialogContext *DialogContext) {
+	previous, oldest, ok := dialogContext.BeginSummary(h.dialogMaxMessages, h.dialogKeepRecent)
+	if !ok {
+		return
+	}
+
+	go h.summarizeDialog(dialogContext, previous, oldest)
+}
+
+// summarizeDialog сворачивает старые сообщения в краткое содержание, не блокируя ответы
+func (h *Handler) summarizeDialog(dialogContext *DialogContext, previous string, oldest []DialogMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialogSummaryTimeout)
+	defer cancel()
+
+	var transcript strings.Builder
+	if previous != "" {
+		transcript.WriteString("Предыдущее краткое содержание:\n" + previous + "\n\n")
+	}
+	transcript.WriteString("Новые сообщения:\n")
+	for _, msg := range oldest {
+		role := "Ученик"
+		if msg.Role == "assistant" {
+			role = "Учитель"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, postProcessText(msg.Content, plainTextOptions))
+	}
+
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.prompts.GetDialogSummaryPrompt()},
+		{Role: "user", Content: transcript.String()},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.2,
+		MaxTokens:   300,
+	})
+	h.aiMetrics.RecordAIRequest("dialog_summary", err == nil, time.Since(start).Seconds())
+
+	if err != nil || strings.TrimSpace(response.Content) == "" {
+		h.logger.Warn("не удалось свернуть историю диалога",
+			zap.Error(err),
+			zap.Int64("user_id", dialogContext.UserID))
+		dialogContext.AbortSummary()
+		return
+	}
+
+	dialogContext.ApplySummary(strings.TrimSpace(response.Content), len(oldest))
+	h.saveDialogContext(ctx, dialogContext)
+	h.logger.Debug("история диалога свернута",
+		zap.Int64("user_id", dialogContext.UserID),
+		zap.Int("summarized_messages", len(oldest)))
+}
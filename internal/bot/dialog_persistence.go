@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// Ограничения размера сохраненного контекста диалога
+const (
+	DefaultDialogPersistMessages = 20   // Сколько последних сообщений сохраняется по умолчанию
+	maxSavedDialogRunes          = 2000 // Длиннее сообщение или краткое содержание обрезается
+)
+
+// dialogPersistTimeout ограничивает запись контекста, чтобы не задерживать ответы
+const dialogPersistTimeout = 5 * time.Second
+
+// SetDialogPersistence включает сохранение контекста диалога в БД (nil — только в памяти)
+// и задает, сколько последних сообщений хранится
+func (h *Handler) SetDialogPersistence(repo store.DialogContextRepository, maxMessages int) {
+	if maxMessages <= 0 {
+		maxMessages = DefaultDialogPersistMessages
+	}
+	h.dialogStore = repo
+	h.dialogPersistMsgs = maxMessages
+}
+
+// loadDialogContext восстанавливает контекст, сохраненный до перезапуска.
+// Возвращает nil, если сохранения нет или разговор уже устарел.
+func (h *Handler) loadDialogContext(ctx context.Context, userID int64, level, systemPrompt string) *DialogContext {
+	if h.dialogStore == nil {
+		return nil
+	}
+
+	saved, err := h.dialogStore.Get(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, store.ErrDialogContextNotFound) {
+			h.logger.Warn("не удалось загрузить контекст диалога", zap.Error(err), zap.Int64("user_id", userID))
+		}
+		return nil
+	}
+	if time.Since(saved.UpdatedAt) > dialogStaleAfter {
+		return nil
+	}
+
+	h.logger.Debug("контекст диалога восстановлен",
+		zap.Int64("user_id", userID),
+		zap.Int("messages", len(saved.Messages)))
+	return restoreDialogContext(saved, level, systemPrompt)
+}
+
+// rebuildDialogContext собирает контекст из истории сообщений, если сохраненного нет.
+// Загружается только последний разговор: сообщения до паузы длиннее dialogStaleAfter
+// не попадают в контекст. Последнее сообщение ученика — текущее, его добавит вызывающий код.
+func (h *Handler) rebuildDialogContext(ctx context.Context, userID int64, level, systemPrompt string) *DialogContext {
+	history, err := h.messageService.GetChatHistory(ctx, userID, h.chatHistoryLimit)
+	if err != nil {
+		h.logger.Warn("не удалось загрузить историю для контекста диалога", zap.Error(err), zap.Int64("user_id", userID))
+		return nil
+	}
+
+	messages := recentConversation(history.Messages, time.Now())
+	if n := len(messages); n > 0 && messages[n-1].Role == "user" {
+		messages = messages[:n-1]
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	dialogContext := NewDialogContext(userID, level, systemPrompt)
+	for _, msg := range messages {
+		dialogContext.Messages = append(dialogContext.Messages, DialogMessage{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Timestamp: msg.CreatedAt,
+		})
+	}
+	dialogContext.LastActivity = messages[len(messages)-1].CreatedAt
+
+	h.logger.Debug("контекст диалога восстановлен из истории сообщений",
+		zap.Int64("user_id", userID),
+		zap.Int("messages", len(messages)))
+	return dialogContext
+}
+
+// recentConversation оставляет реплики ученика и бота из последнего разговора:
+// от конца истории до первой паузы длиннее dialogStaleAfter
+func recentConversation(messages []models.UserMessage, now time.Time) []models.UserMessage {
+	var conversation []models.UserMessage
+	last := now
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if last.Sub(msg.CreatedAt) > dialogStaleAfter {
+			break
+		}
+		last = msg.CreatedAt
+		if msg.Role == "user" || msg.Role == "assistant" {
+			conversation = append(conversation, msg)
+		}
+	}
+	slices.Reverse(conversation)
+	return conversation
+}
+
+// startNewDialog начинает разговор заново: прежний контекст не восстанавливается
+// ни из сохранения, ни из истории сообщений
+func (h *Handler) startNewDialog(ctx context.Context, user *models.User) {
+	h.forgetDialogContext(ctx, user.ID)
+	dialogContext := NewDialogContext(user.ID, user.Level, h.prompts.GetEnglishMessagePrompt(user.Level, user.LearningLanguage))
+	h.dialogMutex.Lock()
+	h.dialogContexts[user.ID] = dialogContext
+	h.dialogMutex.Unlock()
+	h.saveDialogContext(ctx, dialogContext)
+}
+
+// saveDialogContext сохраняет краткое содержание и последние сообщения диалога
+func (h *Handler) saveDialogContext(ctx context.Context, dialogContext *DialogContext) {
+	if h.dialogStore == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dialogPersistTimeout)
+	defer cancel()
+
+	if err := h.dialogStore.Save(ctx, savedDialogContext(dialogContext, h.dialogPersistMsgs)); err != nil {
+		h.logger.Warn("не удалось сохранить контекст диалога", zap.Error(err), zap.Int64("user_id", dialogContext.UserID))
+	}
+}
+
+// forgetDialogContext удаляет контекст из памяти и из БД
+func (h *Handler) forgetDialogContext(ctx context.Context, userID int64) {
+	h.dialogMutex.Lock()
+	delete(h.dialogContexts, userID)
+	h.dialogMutex.Unlock()
+	if h.dialogStore == nil {
+		return
+	}
+
+	if err := h.dialogStore.Delete(ctx, userID); err != nil {
+		h.logger.Warn("не удалось удалить сохраненный контекст диалога", zap.Error(err), zap.Int64("user_id", userID))
+	}
+}
+
+// savedDialogContext готовит контекст к сохранению: не больше maxMessages последних
+// сообщений, длинные тексты обрезаются
+func savedDialogContext(dialogContext *DialogContext, maxMessages int) *models.SavedDialogContext {
+	summary, messages := dialogContext.Snapshot()
+	if len(messages) > maxMessages {
+		messages = messages[len(messages)-maxMessages:]
+	}
+
+	saved := &models.SavedDialogContext{
+		UserID:    dialogContext.UserID,
+		Summary:   truncateRunes(summary, maxSavedDialogRunes),
+		Messages:  make([]models.SavedDialogMessage, 0, len(messages)),
+		UpdatedAt: time.Now(),
+	}
+	for _, msg := range messages {
+		saved.Messages = append(saved.Messages, models.SavedDialogMessage{
+			Role:      msg.Role,
+			Content:   truncateRunes(msg.Content, maxSavedDialogRunes),
+			Timestamp: msg.Timestamp,
+		})
+	}
+	return saved
+}
+
+// restoreDialogContext собирает контекст из сохранения с актуальным системным промптом
+func restoreDialogContext(saved *models.SavedDialogContext, level, systemPrompt string) *DialogContext {
+	dialogContext := NewDialogContext(saved.UserID, level, systemPrompt)
+	dialogContext.Summary = saved.Summary
+	for _, msg := range saved.Messages {
+		dialogContext.Messages = append(dialogContext.Messages, DialogMessage{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Timestamp: msg.Timestamp,
+		})
+	}
+	dialogContext.LastActivity = saved.UpdatedAt
+	return dialogContext
+}
+
+// truncateRunes обрезает текст до limit символов
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
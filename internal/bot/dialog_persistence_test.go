@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+)
+
+// memoryDialogs сохраненные контексты диалога в памяти
+type memoryDialogs struct {
+	mu      sync.Mutex
+	dialogs map[int64]models.SavedDialogContext
+}
+
+func newMemoryDialogs() *memoryDialogs {
+	return &memoryDialogs{dialogs: make(map[int64]models.SavedDialogContext)}
+}
+
+func (r *memoryDialogs) Get(ctx context.Context, userID int64) (*models.SavedDialogContext, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved, ok := r.dialogs[userID]
+	if !ok {
+		return nil, fmt.Errorf("%w: user_id %d", store.ErrDialogContextNotFound, userID)
+	}
+	return &saved, nil
+}
+
+func (r *memoryDialogs) Save(ctx context.Context, dialog *models.SavedDialogContext) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dialogs[dialog.UserID] = *dialog
+	return nil
+}
+
+func (r *memoryDialogs) Delete(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.dialogs, userID)
+	return nil
+}
+
+func TestDialogContextSurvivesRestart(t *testing.T) {
+	dialogs := newMemoryDialogs()
+	th := newTestHarness(t, "I love tea too! Do you drink it every day?")
+	th.handler.SetDialogPersistence(dialogs, 20)
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	// Перезапуск: контексты в памяти потеряны, сохраненные остались
+	th.handler.dialogContexts = make(map[int64]*DialogContext)
+	th.sendText(t, 100, "Yes, I drink it every morning")
+
+	if len(th.ai.calls) != 2 {
+		t.Fatalf("ожидалось 2 запроса к AI, получено %d", len(th.ai.calls))
+	}
+	var contents []string
+	for _, msg := range th.ai.calls[1] {
+		contents = append(contents, msg.Content)
+	}
+	history := strings.Join(contents, "\n")
+	for _, want := range []string{"I like green tea very much", "Do you drink it every day?", "Yes, I drink it every morning"} {
+		if !strings.Contains(history, want) {
+			t.Errorf("после перезапуска в запросе к AI ожидалась реплика %q", want)
+		}
+	}
+
+	u := th.user(t, 100)
+	saved, err := dialogs.Get(context.Background(), u.ID)
+	if err != nil {
+		t.Fatalf("контекст не сохранен: %v", err)
+	}
+	if len(saved.Messages) != 4 {
+		t.Errorf("ожидалось 4 сохраненных сообщения, получено %d", len(saved.Messages))
+	}
+}
+
+func TestClearForgetsSavedDialogContext(t *testing.T) {
+	dialogs := newMemoryDialogs()
+	th := newTestHarness(t, "Nice!")
+	th.handler.SetDialogPersistence(dialogs, 20)
+
+	th.sendText(t, 100, "I like green tea very much")
+	th.sendText(t, 100, "/clear")
+
+	u := th.user(t, 100)
+	if _, err := dialogs.Get(context.Background(), u.ID); err == nil {
+		t.Error("после /clear сохраненный контекст должен быть удален")
+	}
+}
+
+func TestSavedDialogContextIsBounded(t *testing.T) {
+	dialogContext := NewDialogContext(1, models.LevelBeginner, "prompt")
+	dialogContext.Summary = strings.Repeat("с", maxSavedDialogRunes+100)
+	for i := 0; i < 30; i++ {
+		dialogContext.AddUserMessage(fmt.Sprintf("message %d", i))
+	}
+	dialogContext.AddAssistantMessage(strings.Repeat("a", maxSavedDialogRunes*2))
+
+	saved := savedDialogContext(dialogContext, 10)
+	if len(saved.Messages) != 10 {
+		t.Fatalf("ожидалось 10 сообщений, получено %d", len(saved.Messages))
+	}
+	if saved.Messages[0].Content != "message 21" {
+		t.Errorf("ожидались последние сообщения, первое сохраненное %q", saved.Messages[0].Content)
+	}
+	if got := len([]rune(saved.Messages[9].Content)); got != maxSavedDialogRunes {
+		t.Errorf("ожидалось сообщение длиной %d символов, получено %d", maxSavedDialogRunes, got)
+	}
+	if got := len([]rune(saved.Summary)); got != maxSavedDialogRunes {
+		t.Errorf("ожидалось краткое содержание длиной %d символов, получено %d", maxSavedDialogRunes, got)
+	}
+}
+
+func TestStaleSavedDialogContextIsIgnored(t *testing.T) {
+	dialogs := newMemoryDialogs()
+	dialogs.dialogs[1] = models.SavedDialogContext{
+		UserID:    1,
+		Summary:   "old talk",
+		Messages:  []models.SavedDialogMessage{{Role: "user", Content: "hello"}},
+		UpdatedAt: time.Now().Add(-2 * dialogStaleAfter),
+	}
+	th := newTestHarness(t)
+	th.handler.SetDialogPersistence(dialogs, 20)
+
+	dialogContext := th.handler.getOrCreateDialogContext(context.Background(), 1, models.LevelBeginner, models.LanguageEnglish)
+	if summary, messages := dialogContext.Snapshot(); summary != "" || len(messages) != 0 {
+		t.Errorf("устаревший контекст не должен восстанавливаться, получено %q и %d сообщений", summary, len(messages))
+	}
+}
+
+func TestDialogContextRebuiltFromHistoryAfterRestart(t *testing.T) {
+	th := newTestHarness(t, "I love tea too! Do you drink it every day?")
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	// Перезапуск без сохраненного контекста: остается только история сообщений в БД
+	th.handler.dialogContexts = make(map[int64]*DialogContext)
+	th.sendText(t, 100, "Yes, I drink it every morning")
+
+	if len(th.ai.calls) != 2 {
+		t.Fatalf("ожидалось 2 запроса к AI, получено %d", len(th.ai.calls))
+	}
+	var roles []string
+	var contents []string
+	for _, msg := range th.ai.calls[1][1:] {
+		roles = append(roles, msg.Role)
+		contents = append(contents, msg.Content)
+	}
+	history := strings.Join(contents, "\n")
+	for _, want := range []string{"I like green tea very much", "Do you drink it every day?"} {
+		if !strings.Contains(history, want) {
+			t.Errorf("после перезапуска в запросе к AI ожидалась реплика %q", want)
+		}
+	}
+	if got := strings.Count(history, "Yes, I drink it every morning"); got != 1 {
+		t.Errorf("текущее сообщение должно попасть в запрос один раз, получено %d", got)
+	}
+	if strings.Join(roles, ",") != "user,assistant,user" {
+		t.Errorf("неожиданные роли сообщений: %v", roles)
+	}
+}
+
+func TestDialogContextsConcurrentAccess(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	u := th.user(t, 100)
+
+	// Запускать под -race: разговор, /new и очистка одновременно меняют карту контекстов
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			th.handler.getOrCreateDialogContext(context.Background(), u.ID, u.Level, u.LearningLanguage)
+		}()
+		go func() {
+			defer wg.Done()
+			th.handler.startNewDialog(context.Background(), u)
+		}()
+		go func() {
+			defer wg.Done()
+			th.handler.forgetDialogContext(context.Background(), u.ID)
+		}()
+	}
+	wg.Wait()
+
+	if got := th.handler.getOrCreateDialogContext(context.Background(), u.ID, u.Level, u.LearningLanguage); got == nil {
+		t.Fatal("ожидался контекст диалога")
+	}
+}
+
+func TestRecentConversationStopsAtPause(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	messages := []models.UserMessage{
+		{Role: "user", Content: "yesterday", CreatedAt: now.Add(-24 * time.Hour)},
+		{Role: "user", Content: "hello", CreatedAt: now.Add(-50 * time.Minute)},
+		{Role: "system", Content: "note", CreatedAt: now.Add(-45 * time.Minute)},
+		{Role: "assistant", Content: "hi", CreatedAt: now.Add(-40 * time.Minute)},
+	}
+
+	var contents []string
+	for _, msg := range recentConversation(messages, now) {
+		contents = append(contents, msg.Content)
+	}
+	if strings.Join(contents, ",") != "hello,hi" {
+		t.Errorf("ожидался последний разговор без служебных сообщений, получено %q", contents)
+	}
+
+	if got := recentConversation(messages, now.Add(2*dialogStaleAfter)); len(got) != 0 {
+		t.Errorf("после долгой паузы история не должна загружаться, получено %d сообщений", len(got))
+	}
+}
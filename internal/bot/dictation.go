@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math/rand"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/internal/pronunciation"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// DictationTTL время, в течение которого ожидается ответ на диктант
+const DictationTTL = 30 * time.Minute
+
+// dictationSession активный диктант пользователя
+type dictationSession struct {
+	sentence  string
+	createdAt time.Time
+}
+
+// fallbackDictationSentences предложения на случай недоступности AI
+var fallbackDictationSentences = map[string][]string{
+	models.LevelBeginner: {
+		"I drink coffee every morning.",
+		"My sister is reading a book.",
+		"We go to the park on Sundays.",
+		"The cat is sleeping on the sofa.",
+	},
+	models.LevelIntermediate: {
+		"I have never been to a concert like this before.",
+		"We should leave early to avoid the traffic.",
+		"She was cooking dinner when I called her.",
+		"They are going to travel around Europe next summer.",
+	},
+	models.LevelAdvanced: {
+		"If I had known about the meeting, I would have prepared a presentation.",
+		"The project was finally approved after several rounds of discussion.",
+		"Despite the heavy rain, the festival attracted thousands of visitors.",
+		"She has been working on her novel for almost three years now.",
+	},
+}
+
+// dictationXP возвращает награду за диктант по точности
+func dictationXP(score int) int {
+	switch {
+	case score >= 90:
+		return 15
+	case score >= 60:
+		return 10
+	default:
+		return 3
+	}
+}
+
+// handleDictationButton запускает новый диктант
+func (h *Handler) handleDictationButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	return h.startDictation(ctx, message.Chat.ID, user)
+}
+
+// startDictation генерирует предложение, озвучивает его и ждет ответ пользователя
+func (h *Handler) startDictation(ctx context.Context, chatID int64, user *models.User) error {
+	if h.ttsService == nil {
+		return h.sendMessage(chatID, "🎧 Диктант временно недоступен: озвучка отключена.")
+	}
+
+	sentence := h.generateDictationSentence(ctx, user.Level, user.LearningLanguage)
+
+	audioData, err := h.ttsService.SynthesizeText(ctx, sentence)
+	if err != nil {
+		h.logger.Error("ошибка генерации аудио для диктанта", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось озвучить диктант")
+	}
+
+	audio := tgbotapi.NewAudio(chatID, tgbotapi.FileBytes{
+		Name:  "dictation.wav",
+		Bytes: audioData,
+	})
+	audio.Caption = "🎧 Диктант: послушай и напиши, что услышал(а)"
+	audio.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🙈 Показать ответ", "dictation_reveal"),
+		),
+	)
+
+	if _, err := h.sender.Send(audio); err != nil {
+		return err
+	}
+
+	h.dictationMutex.Lock()
+	h.activeDictations[user.ID] = &dictationSession{sentence: sentence, createdAt: time.Now()}
+	h.dictationMutex.Unlock()
+
+	return nil
+}
+
+// generateDictationSentence получает предложение от AI или из запасного списка
+func (h *Handler) generateDictationSentence(ctx context.Context, level, lang string) string {
+	aiMessages := []ai.Message{
+		{Role: "user", Content: h.prompts.GetDictationPrompt(level, lang)},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 1.0,
+		MaxTokens:   60,
+	})
+	h.aiMetrics.RecordAIRequest("dictation_generation", err == nil, time.Since(start).Seconds())
+
+	if err == nil {
+		sentence := strings.Trim(strings.TrimSpace(response.Content), `"'«»`)
+		// Берем только первую строку на случай лишних пояснений
+		sentence, _, _ = strings.Cut(sentence, "\n")
+		if words := pronunciation.CountWords(sentence); words >= 3 && words <= 25 {
+			return sentence
+		}
+		h.logger.Warn("AI вернул неподходящее предложение для диктанта", zap.String("content", response.Content))
+	} else {
+		h.logger.Error("ошибка генерации предложения для диктанта", zap.Error(err))
+	}
+
+	sentences, ok := fallbackDictationSentences[level]
+	if !ok {
+		sentences = fallbackDictationSentences[models.LevelBeginner]
+	}
+	return sentences[rand.Intn(len(sentences))]
+}
+
+// takeDictation возвращает и завершает активный диктант пользователя
+func (h *Handler) takeDictation(userID int64) (*dictationSession, bool) {
+	h.dictationMutex.Lock()
+	defer h.dictationMutex.Unlock()
+
+	session, ok := h.activeDictations[userID]
+	if !ok {
+		return nil, false
+	}
+	delete(h.activeDictations, userID)
+
+	if time.Since(session.createdAt) > DictationTTL {
+		return nil, false
+	}
+	return session, true
+}
+
+// hasActiveDictation проверяет, ждет ли бот ответ на диктант
+func (h *Handler) hasActiveDictation(userID int64) bool {
+	h.dictationMutex.Lock()
+	defer h.dictationMutex.Unlock()
+
+	session, ok := h.activeDictations[userID]
+	return ok && time.Since(session.createdAt) <= DictationTTL
+}
+
+// handleDictationAnswer проверяет ответ пользователя на диктант
+func (h *Handler) handleDictationAnswer(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	session, ok := h.takeDictation(user.ID)
+	if !ok {
+		return h.sendMessage(message.Chat.ID, "⏰ Время диктанта истекло. Начни новый в меню «📚 Обучение».")
+	}
+
+	result := pronunciation.Compare(session.sentence, message.Text)
+	xp := dictationXP(result.Score)
+
+	h.addXP(user, xp)
+	h.updateStudyActivity(user)
+	h.userMetrics.RecordXP(user.ID, xp, "dictation")
+
+	var verdict string
+	switch {
+	case result.Score == 100:
+		verdict = "🎉 <b>Идеально!</b>"
+	case result.Score >= 90:
+		verdict = "👏 <b>Отлично!</b> Почти без ошибок."
+	case result.Score >= 60:
+		verdict = "👍 <b>Хорошо!</b> Есть неточности."
+	default:
+		verdict = "💪 <b>Неплохая попытка!</b> Послушай еще раз и сравни."
+	}
+
+	text := fmt.Sprintf("%s\n\n🎯 Точность: <b>%d%%</b>\n📝 Оригинал: <i>%s</i>",
+		verdict, result.Score, html.EscapeString(session.sentence))
+	if missed := result.Mismatched(); len(missed) > 0 && result.Score < 100 {
+		text += fmt.Sprintf("\n❗ Пропущено или с ошибкой: %s", html.EscapeString(strings.Join(missed, ", ")))
+	}
+	text += fmt.Sprintf("\n\n⭐ +%d XP", xp)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = dictationNextKeyboard()
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handleDictationCallback обрабатывает кнопки диктанта
+func (h *Handler) handleDictationCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	switch callback.Data {
+	case "dictation_next":
+		return h.startDictation(ctx, chatID, user)
+
+	case "dictation_reveal":
+		session, ok := h.takeDictation(user.ID)
+		if !ok {
+			return h.sendMessage(chatID, "⏰ Этот диктант уже завершен.")
+		}
+
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📝 Ответ: <i>%s</i>", html.EscapeString(session.sentence)))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = dictationNextKeyboard()
+		_, err := h.sender.Send(msg)
+		return err
+
+	default:
+		h.logger.Warn("неизвестный callback диктанта", zap.String("data", callback.Data))
+		return nil
+	}
+}
+
+// dictationNextKeyboard кнопка следующего диктанта
+func dictationNextKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎧 Еще диктант", "dictation_next"),
+		),
+	)
+}
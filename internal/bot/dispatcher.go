@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Ограничения Telegram на частоту отправки сообщений
+const (
+	PrivateChatSendInterval = 100 * time.Millisecond // Минимальный интервал между сообщениями в личном чате
+	GroupChatSendInterval   = 3 * time.Second        // Telegram допускает ~20 сообщений в минуту в группе
+	MaxFloodRetries         = 3                      // Максимум повторов после ответа 429
+	MaxRetryAfter           = 60 * time.Second       // Верхняя граница ожидания из retry_after
+	chatStateIdleTimeout    = 10 * time.Minute       // Через сколько неактивные чаты удаляются из памяти
+	chatStateSweepThreshold = 10000                  // Размер карты, при котором запускается очистка
+)
+
+// chatSendState хранит состояние отправки для одного чата
+type chatSendState struct {
+	mu       sync.Mutex
+	lastSent time.Time
+	users    int // Число отправок, получивших состояние и еще не завершившихся (под SendDispatcher.mu)
+}
+
+// SendDispatcher сериализует отправку сообщений по чатам и обрабатывает flood control (429)
+type SendDispatcher struct {
+	bot    Sender
+	logger *zap.Logger
+	sleep  func(time.Duration)
+
+	mu    sync.Mutex
+	chats map[int64]*chatSendState
+}
+
+// NewSendDispatcher создает новый диспетчер отправки
+func NewSendDispatcher(bot Sender, logger *zap.Logger) *SendDispatcher {
+	return &SendDispatcher{
+		bot:    bot,
+		logger: logger,
+		sleep:  time.Sleep,
+		chats:  make(map[int64]*chatSendState),
+	}
+}
+
+// Send отправляет сообщение с соблюдением лимитов чата и повтором при 429
+func (d *SendDispatcher) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	err := d.dispatch(c, func() error {
+		var err error
+		msg, err = d.bot.Send(c)
+		return err
+	})
+	return msg, err
+}
+
+// Request выполняет запрос без сообщения в ответе (например, удаление сообщения)
+// с теми же лимитами чата и повтором при 429
+func (d *SendDispatcher) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	var resp *tgbotapi.APIResponse
+	err := d.dispatch(c, func() error {
+		var err error
+		resp, err = d.bot.Request(c)
+		return err
+	})
+	return resp, err
+}
+
+// dispatch выполняет вызов API с соблюдением интервала отправки в чат
+func (d *SendDispatcher) dispatch(c tgbotapi.Chattable, call func() error) error {
+	chatID := chatIDOf(c)
+	if chatID == 0 {
+		return d.callWithRetry(chatID, call)
+	}
+
+	state := d.chatState(chatID)
+	defer d.releaseChatState(state)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if wait := sendInterval(chatID) - time.Since(state.lastSent); wait > 0 {
+		d.sleep(wait)
+	}
+
+	err := d.callWithRetry(chatID, call)
+	state.lastSent = time.Now()
+	return err
+}
+
+// callWithRetry выполняет вызов API, повторяя попытку после паузы из retry_after
+func (d *SendDispatcher) callWithRetry(chatID int64, call func() error) error {
+	var err error
+	for attempt := 0; attempt <= MaxFloodRetries; attempt++ {
+		err = call()
+		retryAfter, isFlood := retryAfterFromError(err)
+		if !isFlood || attempt == MaxFloodRetries {
+			return err
+		}
+
+		d.logger.Warn("превышен лимит Telegram, ожидаем перед повтором",
+			zap.Int64("chat_id", chatID),
+			zap.Duration("retry_after", retryAfter),
+			zap.Int("attempt", attempt+1))
+		d.sleep(retryAfter)
+	}
+
+	return err
+}
+
+// chatState возвращает состояние чата, создавая его при необходимости.
+// Состояние помечается как занятое, пока вызывающий не вызовет releaseChatState,
+// чтобы очистка не удалила его между получением и захватом state.mu
+func (d *SendDispatcher) chatState(chatID int64) *chatSendState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.chats) >= chatStateSweepThreshold {
+		d.sweepIdleChats()
+	}
+
+	state, exists := d.chats[chatID]
+	if !exists {
+		state = &chatSendState{}
+		d.chats[chatID] = state
+	}
+	state.users++
+	return state
+}
+
+// releaseChatState снимает отметку об использовании состояния чата
+func (d *SendDispatcher) releaseChatState(state *chatSendState) {
+	d.mu.Lock()
+	state.users--
+	d.mu.Unlock()
+}
+
+// sweepIdleChats удаляет давно неактивные чаты (вызывается под d.mu)
+func (d *SendDispatcher) sweepIdleChats() {
+	for chatID, state := range d.chats {
+		// lastSent безопасно читать: без активных отправок его последняя запись
+		// упорядочена с нами через d.mu в releaseChatState
+		if state.users == 0 && time.Since(state.lastSent) > chatStateIdleTimeout {
+			delete(d.chats, chatID)
+		}
+	}
+}
+
+// sendInterval возвращает минимальный интервал между сообщениями для чата
+func sendInterval(chatID int64) time.Duration {
+	if chatID < 0 {
+		return GroupChatSendInterval
+	}
+	return PrivateChatSendInterval
+}
+
+// retryAfterFromError проверяет, является ли ошибка ответом 429, и возвращает время ожидания
+func retryAfterFromError(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 429 {
+		return 0, false
+	}
+
+	retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	if retryAfter > MaxRetryAfter {
+		retryAfter = MaxRetryAfter
+	}
+	return retryAfter, true
+}
+
+// chatIDOf извлекает ID чата из отправляемого объекта
+func chatIDOf(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.AudioConfig:
+		return v.ChatID
+	case tgbotapi.VoiceConfig:
+		return v.ChatID
+	case tgbotapi.PhotoConfig:
+		return v.ChatID
+	case tgbotapi.DocumentConfig:
+		return v.ChatID
+	case tgbotapi.ChatActionConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID
+	case tgbotapi.DeleteMessageConfig:
+		return v.ChatID
+	default:
+		return 0
+	}
+}
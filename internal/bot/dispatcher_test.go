@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRetryAfterFromError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantWait  time.Duration
+		wantFlood bool
+	}{
+		{"нет ошибки", nil, 0, false},
+		{"обычная ошибка", errors.New("network error"), 0, false},
+		{"ошибка API не 429", &tgbotapi.Error{Code: 400, Message: "Bad Request"}, 0, false},
+		{"429 с retry_after", &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}, 5 * time.Second, true},
+		{"429 без retry_after", &tgbotapi.Error{Code: 429}, time.Second, true},
+		{"429 с огромным retry_after", &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 3600}}, MaxRetryAfter, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, flood := retryAfterFromError(tt.err)
+			if flood != tt.wantFlood {
+				t.Errorf("ожидался flood=%v, получен %v", tt.wantFlood, flood)
+			}
+			if wait != tt.wantWait {
+				t.Errorf("ожидалось ожидание %v, получено %v", tt.wantWait, wait)
+			}
+		})
+	}
+}
+
+func TestChatIDOf(t *testing.T) {
+	if got := chatIDOf(tgbotapi.NewMessage(42, "hi")); got != 42 {
+		t.Errorf("ожидался chat_id 42, получен %d", got)
+	}
+	if got := chatIDOf(tgbotapi.NewEditMessageText(-100, 1, "text")); got != -100 {
+		t.Errorf("ожидался chat_id -100, получен %d", got)
+	}
+	if got := chatIDOf(tgbotapi.NewCallback("id", "text")); got != 0 {
+		t.Errorf("для callback ожидался chat_id 0, получен %d", got)
+	}
+}
+
+func TestSendInterval(t *testing.T) {
+	if got := sendInterval(123); got != PrivateChatSendInterval {
+		t.Errorf("для личного чата ожидался интервал %v, получен %v", PrivateChatSendInterval, got)
+	}
+	if got := sendInterval(-123); got != GroupChatSendInterval {
+		t.Errorf("для группы ожидался интервал %v, получен %v", GroupChatSendInterval, got)
+	}
+}
+
+func TestSweepIdleChatsKeepsStatesInUse(t *testing.T) {
+	d := NewSendDispatcher(nil, nil)
+
+	// Состояние получено отправкой, но state.mu еще не захвачен
+	inUse := d.chatState(1)
+	inUse.lastSent = time.Now().Add(-2 * chatStateIdleTimeout)
+	idle := d.chatState(2)
+	idle.lastSent = time.Now().Add(-2 * chatStateIdleTimeout)
+	d.releaseChatState(idle)
+
+	d.mu.Lock()
+	d.sweepIdleChats()
+	_, keptInUse := d.chats[1]
+	_, keptIdle := d.chats[2]
+	d.mu.Unlock()
+
+	if !keptInUse {
+		t.Error("очистка не должна удалять состояние чата, которое используется отправкой")
+	}
+	if keptIdle {
+		t.Error("неактивное состояние без отправок должно удаляться")
+	}
+
+	d.releaseChatState(inUse)
+	if got := d.chatState(1); got != inUse {
+		t.Error("следующая отправка должна получить то же состояние чата")
+	}
+}
@@ -0,0 +1,257 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Параметры упражнений-квизов
+const (
+	exerciseAnswerCallbackPrefix = "exercise_answer_" // exercise_answer_<номер варианта>
+	exerciseQuizTTL              = 24 * time.Hour     // сколько ждать ответа на упражнение
+	minExerciseOptions           = 2
+	maxExerciseOptions           = 4
+)
+
+// exerciseQuiz упражнение с вариантами ответа в ответе AI
+type exerciseQuiz struct {
+	Type        string   `json:"type"`
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	Correct     int      `json:"correct"`
+	Translation string   `json:"translation"`
+	Explanation string   `json:"explanation"`
+}
+
+// fallbackExerciseQuiz упражнение на случай, если AI не ответил или ответил не по формату
+var fallbackExerciseQuiz = exerciseQuiz{
+	Type:        "Choose the correct verb form",
+	Question:    "She _____ to work every day.",
+	Options:     []string{"go", "goes", "going"},
+	Correct:     1,
+	Translation: "Она ходит на работу каждый день.",
+	Explanation: "В Present Simple с she/he/it к глаголу добавляется окончание -s: she goes.",
+}
+
+// pendingExerciseQuiz упражнение, которое ждет ответа пользователя
+type pendingExerciseQuiz struct {
+	quiz      exerciseQuiz
+	messageID int
+	createdAt time.Time
+}
+
+// exerciseQuizzes последние упражнения пользователей, ожидающие ответа
+type exerciseQuizzes struct {
+	mu      sync.Mutex
+	pending map[int64]*pendingExerciseQuiz // по ID пользователя
+	now     func() time.Time
+}
+
+// newExerciseQuizzes создает хранилище упражнений, ожидающих ответа
+func newExerciseQuizzes() *exerciseQuizzes {
+	return &exerciseQuizzes{
+		pending: make(map[int64]*pendingExerciseQuiz),
+		now:     time.Now,
+	}
+}
+
+// put запоминает упражнение пользователя вместо предыдущего и удаляет устаревшие
+func (q *exerciseQuizzes) put(userID int64, messageID int, quiz exerciseQuiz) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	for id, pending := range q.pending {
+		if now.Sub(pending.createdAt) > exerciseQuizTTL {
+			delete(q.pending, id)
+		}
+	}
+	q.pending[userID] = &pendingExerciseQuiz{quiz: quiz, messageID: messageID, createdAt: now}
+}
+
+// take забирает упражнение, отправленное сообщением messageID. Упражнение отдается
+// один раз, поэтому повторное нажатие кнопки не засчитывается.
+func (q *exerciseQuizzes) take(userID int64, messageID int) (exerciseQuiz, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending, ok := q.pending[userID]
+	if !ok || pending.messageID != messageID {
+		return exerciseQuiz{}, false
+	}
+	delete(q.pending, userID)
+	if q.now().Sub(pending.createdAt) > exerciseQuizTTL {
+		return exerciseQuiz{}, false
+	}
+	return pending.quiz, true
+}
+
+// parseExerciseQuiz разбирает JSON-ответ AI и проверяет, что упражнение можно оценить
+func parseExerciseQuiz(content string) (exerciseQuiz, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return exerciseQuiz{}, fmt.Errorf("в ответе нет JSON-объекта")
+	}
+
+	var quiz exerciseQuiz
+	if err := json.Unmarshal([]byte(content[start:end+1]), &quiz); err != nil {
+		return exerciseQuiz{}, fmt.Errorf("ошибка разбора упражнения: %w", err)
+	}
+
+	quiz.Type = strings.TrimSpace(quiz.Type)
+	quiz.Question = strings.TrimSpace(quiz.Question)
+	quiz.Translation = strings.TrimSpace(quiz.Translation)
+	quiz.Explanation = strings.TrimSpace(quiz.Explanation)
+	if quiz.Question == "" {
+		return exerciseQuiz{}, fmt.Errorf("в упражнении нет вопроса")
+	}
+
+	options := make([]string, 0, len(quiz.Options))
+	for _, option := range quiz.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return exerciseQuiz{}, fmt.Errorf("в упражнении пустой вариант ответа")
+		}
+		options = append(options, option)
+	}
+	if len(options) < minExerciseOptions || len(options) > maxExerciseOptions {
+		return exerciseQuiz{}, fmt.Errorf("в упражнении %d вариантов, ожидалось от %d до %d",
+			len(options), minExerciseOptions, maxExerciseOptions)
+	}
+	if quiz.Correct < 0 || quiz.Correct >= len(options) {
+		return exerciseQuiz{}, fmt.Errorf("неверный номер правильного ответа: %d", quiz.Correct)
+	}
+	quiz.Options = options
+	return quiz, nil
+}
+
+// formatExerciseQuiz оформляет вопрос упражнения для Telegram
+func formatExerciseQuiz(quiz exerciseQuiz, levelText string) string {
+	var b strings.Builder
+	if quiz.Type != "" {
+		fmt.Fprintf(&b, "🧩 <b>%s</b>\n\n", html.EscapeString(quiz.Type))
+	}
+	fmt.Fprintf(&b, "%s\n\n", html.EscapeString(quiz.Question))
+	if quiz.Translation != "" {
+		fmt.Fprintf(&b, "<tg-spoiler>🇷🇺 %s</tg-spoiler>\n\n", html.EscapeString(quiz.Translation))
+	}
+	fmt.Fprintf(&b, "Выбери ответ 👇\n<i>Уровень: %s</i>", levelText)
+	return b.String()
+}
+
+// exerciseQuizHistory английская запись упражнения для истории: по ней AI избегает повторов
+func exerciseQuizHistory(quiz exerciseQuiz) string {
+	return fmt.Sprintf("Exercise: %s\nQuestion: %s\nOptions: %s", quiz.Type, quiz.Question, strings.Join(quiz.Options, "/"))
+}
+
+// exerciseQuizKeyboard кнопки вариантов ответа и отзыва о сложности
+func exerciseQuizKeyboard(quiz exerciseQuiz) tgbotapi.InlineKeyboardMarkup {
+	var answers []tgbotapi.InlineKeyboardButton
+	for i, option := range quiz.Options {
+		answers = append(answers, tgbotapi.NewInlineKeyboardButtonData(option, exerciseAnswerCallbackPrefix+strconv.Itoa(i)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(answers, exerciseFeedbackRow())
+}
+
+// exerciseFeedbackRow кнопки отзыва о сложности упражнения
+func exerciseFeedbackRow() []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("😴 Слишком легко", "exercise_easy"),
+		tgbotapi.NewInlineKeyboardButtonData("🤯 Слишком сложно", "exercise_hard"),
+	)
+}
+
+// sendExerciseQuiz отправляет упражнение с кнопками ответов и запоминает его до ответа
+func (h *Handler) sendExerciseQuiz(chatID int64, user *models.User, quiz exerciseQuiz) error {
+	msg := tgbotapi.NewMessage(chatID, formatExerciseQuiz(quiz, h.getLevelText(user.Level)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = exerciseQuizKeyboard(quiz)
+
+	sent, err := h.sender.Send(msg)
+	if err != nil {
+		return err
+	}
+	h.quizzes.put(user.ID, sent.MessageID, quiz)
+	return nil
+}
+
+// handleExerciseAnswerCallback оценивает выбранный вариант ответа на упражнение.
+// Опыт начисляется только за верный ответ, точность учитывается для подстройки сложности.
+func (h *Handler) handleExerciseAnswerCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	choice, err := strconv.Atoi(strings.TrimPrefix(callback.Data, exerciseAnswerCallbackPrefix))
+	if err != nil {
+		h.logger.Warn("неверный ответ в кнопке упражнения", zap.String("data", callback.Data))
+		return nil
+	}
+
+	quiz, ok := h.quizzes.take(user.ID, callback.Message.MessageID)
+	if !ok {
+		return h.sendMessage(chatID, "⌛ Это упражнение уже завершено. Попроси новое — и продолжим!")
+	}
+	if choice < 0 || choice >= len(quiz.Options) {
+		h.logger.Warn("номер ответа вне вариантов упражнения", zap.Int("choice", choice), zap.Int64("user_id", user.ID))
+		return nil
+	}
+	correct := choice == quiz.Correct
+
+	// Убираем кнопки ответов, оставляя отзыв о сложности
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(exerciseFeedbackRow())
+	if _, err := h.sender.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, keyboard)); err != nil {
+		h.logger.Warn("не удалось убрать кнопки ответов упражнения", zap.Error(err))
+	}
+
+	var b strings.Builder
+	if correct {
+		h.addXP(user, models.ExerciseCorrectXP)
+		h.userMetrics.RecordXP(user.ID, models.ExerciseCorrectXP, "exercise_correct")
+		fmt.Fprintf(&b, "✅ <b>Верно!</b> +%d XP\n", models.ExerciseCorrectXP)
+	} else {
+		fmt.Fprintf(&b, "❌ <b>Не совсем.</b> Правильный ответ: <b>%s</b>\n", html.EscapeString(quiz.Options[quiz.Correct]))
+	}
+	if quiz.Explanation != "" {
+		fmt.Fprintf(&b, "\n💡 %s\n", html.EscapeString(quiz.Explanation))
+	}
+
+	delta, bias, err := h.userService.RecordExerciseAnswer(ctx, user.ID, correct)
+	if err != nil {
+		h.logger.Error("ошибка сохранения ответа на упражнение", zap.Error(err), zap.Int64("user_id", user.ID))
+	} else if delta != 0 && bias != user.ExerciseDifficultyBias {
+		user.ExerciseDifficultyBias = bias
+		if delta > 0 {
+			b.WriteString("\n📈 Ты отлично справляешься — следующие упражнения будут сложнее.")
+		} else {
+			b.WriteString("\n📉 Сделаю следующие упражнения попроще, чтобы закрепить основы.")
+		}
+	}
+
+	text := strings.TrimRight(b.String(), "\n")
+
+	// Фразу с правильным ответом можно сразу потренировать вслух
+	target := exercisePronunciationTarget(quiz)
+	if target == "" {
+		return h.sendMessage(chatID, text)
+	}
+	h.offerPronunciation(user.ID, target)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🎤 Произнести фразу", pronounceOfferCallback),
+	))
+	_, err = h.sender.Send(msg)
+	return err
+}
@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const pastSimpleQuizJSON = `{"type": "Choose the correct form", "question": "Yesterday I _____ a letter.", "options": [" write ", "wrote", "written"], "correct": 1, "translation": "Вчера я написал письмо.", "explanation": "Past Simple: wrote."}`
+
+func TestParseExerciseQuiz(t *testing.T) {
+	quiz, err := parseExerciseQuiz("Here it is:\n" + pastSimpleQuizJSON)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if quiz.Question != "Yesterday I _____ a letter." || quiz.Correct != 1 || quiz.Options[0] != "write" {
+		t.Errorf("неверный разбор: %+v", quiz)
+	}
+
+	for _, content := range []string{
+		"Sorry, I can't",
+		`{"question": "Q", "options": ["a"], "correct": 0}`,
+		`{"question": "Q", "options": ["a", "b", "c", "d", "e"], "correct": 0}`,
+		`{"question": "Q", "options": ["a", "b"], "correct": 2}`,
+		`{"question": "Q", "options": ["a", " "], "correct": 0}`,
+		`{"question": "", "options": ["a", "b"], "correct": 0}`,
+	} {
+		if _, err := parseExerciseQuiz(content); err == nil {
+			t.Errorf("ожидалась ошибка для %q", content)
+		}
+	}
+}
+
+func TestExerciseQuizzesTakeOnce(t *testing.T) {
+	q := newExerciseQuizzes()
+	now := time.Now()
+	q.now = func() time.Time { return now }
+
+	q.put(1, 10, fallbackExerciseQuiz)
+	if _, ok := q.take(1, 11); ok {
+		t.Error("ответ на другое сообщение не должен засчитываться")
+	}
+	if _, ok := q.take(1, 10); !ok {
+		t.Fatal("ожидалось упражнение, ожидающее ответа")
+	}
+	if _, ok := q.take(1, 10); ok {
+		t.Error("повторный ответ не должен засчитываться")
+	}
+
+	q.put(2, 20, fallbackExerciseQuiz)
+	now = now.Add(exerciseQuizTTL + time.Second)
+	if _, ok := q.take(2, 20); ok {
+		t.Error("устаревшее упражнение не должно засчитываться")
+	}
+}
+
+// answerExercise нажимает кнопку ответа под сообщением с упражнением
+func (th *testHarness) answerExercise(t *testing.T, telegramID int64, messageID, option int) {
+	t.Helper()
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:   "callback",
+		From: &tgbotapi.User{ID: telegramID, FirstName: "Test"},
+		Message: &tgbotapi.Message{
+			MessageID: messageID,
+			Chat:      &tgbotapi.Chat{ID: telegramID, Type: "private"},
+		},
+		Data: exerciseAnswerCallbackPrefix + strconv.Itoa(option),
+	}
+	if err := th.handler.HandleUpdate(context.Background(), tgbotapi.Update{CallbackQuery: callback}); err != nil {
+		t.Fatalf("ошибка обработки ответа на упражнение: %v", err)
+	}
+}
+
+// lastQuizMessageID номер последнего отправленного упражнения с кнопками ответов
+func (th *testHarness) lastQuizMessageID(t *testing.T) int {
+	t.Helper()
+
+	th.sender.mu.Lock()
+	defer th.sender.mu.Unlock()
+	for i := len(th.sender.sent) - 1; i >= 0; i-- {
+		msg, ok := th.sender.sent[i].(tgbotapi.MessageConfig)
+		if !ok {
+			continue
+		}
+		if keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok &&
+			strings.HasPrefix(*keyboard.InlineKeyboard[0][0].CallbackData, exerciseAnswerCallbackPrefix) {
+			return i + 1 // fakeSender нумерует сообщения по порядку отправки
+		}
+	}
+	t.Fatal("упражнение с кнопками ответов не отправлено")
+	return 0
+}
+
+func TestExerciseQuizAwardsXPOnlyForCorrectAnswer(t *testing.T) {
+	th := newTestHarness(t, pastSimpleQuizJSON, "not a quiz")
+
+	th.sendText(t, 100, "дай мне упражнение")
+	xpBefore := th.user(t, 100).XP
+	th.answerExercise(t, 100, th.lastQuizMessageID(t), 0)
+
+	if xp := th.user(t, 100).XP; xp != xpBefore {
+		t.Errorf("за неверный ответ опыт не начисляется: было %d, стало %d", xpBefore, xp)
+	}
+	texts := th.sender.texts()
+	if feedback := texts[len(texts)-1]; !strings.Contains(feedback, "❌") || !strings.Contains(feedback, "wrote") {
+		t.Errorf("ожидался разбор неверного ответа, получено %q", feedback)
+	}
+
+	// AI ответил не по формату — отправляется запасное упражнение
+	th.sendText(t, 100, "дай мне упражнение")
+	messageID := th.lastQuizMessageID(t)
+	th.answerExercise(t, 100, messageID, fallbackExerciseQuiz.Correct)
+	th.answerExercise(t, 100, messageID, fallbackExerciseQuiz.Correct)
+
+	if xp := th.user(t, 100).XP; xp != xpBefore+models.ExerciseCorrectXP {
+		t.Errorf("ожидалось %d XP за один верный ответ, получено %d", xpBefore+models.ExerciseCorrectXP, xp)
+	}
+	texts = th.sender.texts()
+	if !strings.Contains(texts[len(texts)-2], "✅") || !strings.Contains(texts[len(texts)-1], "завершено") {
+		t.Errorf("ожидались верный ответ и отказ в повторе, получено %q", texts[len(texts)-2:])
+	}
+	if got := th.store.users.exerciseAnswers; got != 2 {
+		t.Errorf("ожидалось 2 учтенных ответа, получено %d", got)
+	}
+}
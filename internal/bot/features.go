@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/pkg/models"
+)
+
+// EssayMinWords минимальная длина английского текста в словах, с которой он проверяется как эссе
+const EssayMinWords = 80
+
+// SetPremiumFeatures задает возможности, доступные только по премиуму
+func (h *Handler) SetPremiumFeatures(gate premium.FeatureGate) {
+	h.premiumFeatures = gate
+}
+
+// featureEnabled проверяет, доступна ли возможность пользователю с учетом его подписки
+func (h *Handler) featureEnabled(user *models.User, feature premium.Feature) bool {
+	return h.premiumFeatures.Enabled(user, feature)
+}
+
+// premiumFeatureHint подсказка о том, что возможность открывается премиумом
+func premiumFeatureHint(feature premium.Feature) string {
+	return fmt.Sprintf("💎 <b>%s</b> — возможность премиум-подписки. Подробнее: /premium", feature.Title())
+}
+
+// isEssay проверяет, достаточно ли длинный текст для проверки как эссе
+func isEssay(text string) bool {
+	return len(strings.Fields(text)) >= EssayMinWords
+}
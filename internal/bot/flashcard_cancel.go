@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+// flashcardSessionCanceledText подтверждает завершение сессии карточек по команде
+const flashcardSessionCanceledText = "📚 Сессия карточек завершена, прогресс сохранен."
+
+// hasActiveSession сообщает, есть ли у пользователя незавершенная сессия карточек
+func (h *FlashcardHandler) hasActiveSession(userID int64) bool {
+	return h.flashcardService.GetCurrentSession(userID) != nil
+}
+
+// cancelSession завершает активную сессию с сохранением прогресса.
+// Возвращает false, если завершать было нечего.
+func (h *FlashcardHandler) cancelSession(userID int64) bool {
+	if !h.hasActiveSession(userID) {
+		return false
+	}
+	h.flashcardService.EndSession(userID)
+	return true
+}
+
+// sendActiveSessionPrompt напоминает о незавершенной сессии вместо ответа на текст
+func (h *FlashcardHandler) sendActiveSessionPrompt(chatID int64) error {
+	msg := tgbotapi.NewMessage(chatID, "📚 <b>У вас идет сессия карточек</b>\n\nПродолжите ее или завершите, чтобы вернуться к диалогу. Прогресс сохранится.")
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ Продолжить", "flashcard_next"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Завершить", "flashcard_end"),
+		),
+	)
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handleCancelCommand обрабатывает /cancel: завершает активную сессию карточек
+func (h *Handler) handleCancelCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	if !h.flashcardHandler.cancelSession(user.ID) {
+		return h.sendMessage(message.Chat.ID, "Сейчас нечего отменять.")
+	}
+	return h.sendMessage(message.Chat.ID, flashcardSessionCanceledText)
+}
+
+// endFlashcardSessionBeforeCommand завершает сессию карточек, если пользователь
+// ушел из нее другой командой. /flashcards продолжает сессию, поэтому ее не трогаем.
+func (h *Handler) endFlashcardSessionBeforeCommand(message *tgbotapi.Message, user *models.User) {
+	switch message.Command() {
+	case "flashcards", "cancel":
+		return
+	}
+	if !h.flashcardHandler.cancelSession(user.ID) {
+		return
+	}
+	if err := h.sendMessage(message.Chat.ID, flashcardSessionCanceledText); err != nil {
+		h.logger.Error("ошибка отправки подтверждения завершения сессии карточек",
+			zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+}
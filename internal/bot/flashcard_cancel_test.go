@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+)
+
+// reviewCardsRepo отдает одну карточку на повторение и запоминает сохраненный прогресс
+type reviewCardsRepo struct {
+	store.FlashcardRepository
+	updated []*models.UserFlashcard
+}
+
+func (r *reviewCardsRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return []*models.UserFlashcard{{
+		ID:           1,
+		UserID:       userID,
+		FlashcardID:  1,
+		NextReviewAt: time.Now(),
+		Flashcard:    &models.Flashcard{ID: 1, Word: "apple", Translation: "яблоко"},
+	}}, nil
+}
+
+func (r *reviewCardsRepo) UpdateUserFlashcard(ctx context.Context, card *models.UserFlashcard) error {
+	r.updated = append(r.updated, card)
+	return nil
+}
+
+// startTestFlashcardSession подключает сервис карточек и начинает сессию для пользователя
+func startTestFlashcardSession(t *testing.T, th *testHarness, userID int64) *flashcards.Service {
+	t.Helper()
+	service := flashcards.NewService(&reviewCardsRepo{}, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.handler.flashcardHandler.flashcardService = service
+	if _, err := service.StartFlashcardSession(context.Background(), userID, "beginner"); err != nil {
+		t.Fatalf("не удалось начать сессию карточек: %v", err)
+	}
+	return service
+}
+
+func TestCancelCommandEndsFlashcardSession(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	service := startTestFlashcardSession(t, th, th.user(t, 100).ID)
+	th.sender.reset()
+
+	th.sendText(t, 100, "/cancel")
+
+	if service.GetCurrentSession(th.user(t, 100).ID) != nil {
+		t.Fatal("сессия карточек должна быть завершена после /cancel")
+	}
+	if texts := th.sender.texts(); len(texts) != 1 || texts[0] != flashcardSessionCanceledText {
+		t.Fatalf("ожидалось подтверждение завершения, получено %q", texts)
+	}
+
+	th.sender.reset()
+	th.sendText(t, 100, "/cancel")
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "нечего отменять") {
+		t.Fatalf("без сессии ожидался ответ, что отменять нечего, получено %q", texts)
+	}
+}
+
+func TestOtherCommandEndsFlashcardSession(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	service := startTestFlashcardSession(t, th, th.user(t, 100).ID)
+	th.sender.reset()
+
+	th.sendText(t, 100, "/help")
+
+	if service.GetCurrentSession(th.user(t, 100).ID) != nil {
+		t.Fatal("любая команда должна завершать сессию карточек")
+	}
+	texts := th.sender.texts()
+	if len(texts) < 2 || texts[0] != flashcardSessionCanceledText {
+		t.Fatalf("ожидалось подтверждение перед справкой, получено %q", texts)
+	}
+}
+
+func TestTextDuringFlashcardSessionPromptsToFinish(t *testing.T) {
+	th := newTestHarness(t, "should not be used")
+	th.sendText(t, 100, "/start")
+	service := startTestFlashcardSession(t, th, th.user(t, 100).ID)
+	th.sender.reset()
+
+	th.sendText(t, 100, "Hello, how are you?")
+
+	if len(th.ai.calls) != 0 {
+		t.Fatalf("текст во время сессии не должен уходить в диалог, запросов к AI: %d", len(th.ai.calls))
+	}
+	if service.GetCurrentSession(th.user(t, 100).ID) == nil {
+		t.Fatal("напоминание не должно завершать сессию")
+	}
+	prompt, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(prompt.Text, "сессия карточек") {
+		t.Fatalf("ожидалось напоминание о сессии, получено %#v", th.sender.last())
+	}
+	row := prompt.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0]
+	if *row[0].CallbackData != "flashcard_next" || *row[1].CallbackData != "flashcard_end" {
+		t.Errorf("неожиданные кнопки напоминания: %v", row)
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+const (
+	// flashcardCategoriesCallback открывает выбор темы карточек
+	flashcardCategoriesCallback = "flashcard_categories"
+	// flashcardCategoryCallbackPrefix выбор темы: flashcard_category_<код>
+	flashcardCategoryCallbackPrefix = "flashcard_category_"
+	// allFlashcardCategoriesCode код кнопки «Все темы» в callback data
+	allFlashcardCategoriesCode = "all"
+)
+
+// showCategoryPicker предлагает выбрать тему для сессии карточек
+func (h *FlashcardHandler) showCategoryPicker(chatID int64, current string) error {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, category := range models.FlashcardCategories {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(categoryButtonLabel(category.Name, category.Code == current),
+			flashcardCategoryCallbackPrefix+category.Code))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(categoryButtonLabel(models.AllFlashcardCategoriesName, current == ""),
+			flashcardCategoryCallbackPrefix+allFlashcardCategoriesCode),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, "🗂 <b>Выберите тему</b>\n\nНовые слова и повторения будут только из этой темы. Выбор сохранится для следующих сессий.")
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// categoryButtonLabel отмечает галочкой текущую тему
+func categoryButtonLabel(name string, selected bool) string {
+	if selected {
+		return "✅ " + name
+	}
+	return name
+}
+
+// handleCategoryChoice запоминает выбранную тему и начинает сессию по ней.
+// Незавершенная сессия по другой теме завершается с сохранением прогресса.
+func (h *FlashcardHandler) handleCategoryChoice(ctx context.Context, chatID int64, user *models.User, data string) error {
+	category := strings.TrimPrefix(data, flashcardCategoryCallbackPrefix)
+	if category == allFlashcardCategoriesCode {
+		category = ""
+	}
+	if !models.IsValidFlashcardCategory(category) {
+		return fmt.Errorf("неизвестная категория карточек: %s", category)
+	}
+
+	if h.setCategory != nil {
+		if err := h.setCategory(ctx, user.ID, category); err != nil {
+			// Сессию все равно начинаем, тема просто не запомнится
+			h.logger.Error("ошибка сохранения категории карточек", zap.Error(err), zap.Int64("user_id", user.ID))
+		}
+	}
+
+	h.flashcardService.EndSession(user.ID)
+	return h.startFlashcardSession(ctx, chatID, user.ID, user.Level, category)
+}
+
+// sendCategoryExhausted сообщает, что в теме закончились слова, и предлагает другую
+func (h *FlashcardHandler) sendCategoryExhausted(chatID int64, category string) error {
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📭 <b>В теме «%s» пока нечего учить</b>\n\nНовых слов вашего уровня не осталось, а повторения еще не подошли. Выберите другую тему.",
+		models.FlashcardCategoryName(category)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗂 Выбрать тему", flashcardCategoriesCallback),
+		),
+	)
+	_, err := h.sender.Send(msg)
+	return err
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+)
+
+// categoryCardsRepo отдает по одному новому слову на тему и запоминает запрошенные темы
+type categoryCardsRepo struct {
+	store.FlashcardRepository
+	requested []string
+}
+
+func (r *categoryCardsRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return nil, nil
+}
+
+func (r *categoryCardsRepo) GetNextCardToReview(ctx context.Context, userID int64) (*models.UserFlashcard, error) {
+	return nil, store.ErrFlashcardNotFound
+}
+
+func (r *categoryCardsRepo) GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int, order store.NewCardOrder) ([]*models.Flashcard, error) {
+	r.requested = append(r.requested, "")
+	return []*models.Flashcard{{ID: 1, Word: "house", Translation: "дом", Category: "general"}}, nil
+}
+
+func (r *categoryCardsRepo) GetNewCardsForUserByCategory(ctx context.Context, userID int64, level, category string, limit int) ([]*models.Flashcard, error) {
+	r.requested = append(r.requested, category)
+	return []*models.Flashcard{{ID: 2, Word: "ticket", Translation: "билет", Category: category}}, nil
+}
+
+func (r *categoryCardsRepo) GetUnlearnedFlashcards(ctx context.Context, userID int64) ([]*models.Flashcard, error) {
+	return nil, nil
+}
+
+func (r *categoryCardsRepo) CountNewCardsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	return 0, nil
+}
+
+func (r *categoryCardsRepo) CreateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error {
+	return nil
+}
+
+func TestFlashcardCategoryChoiceIsRemembered(t *testing.T) {
+	th := newTestHarness(t)
+	repo := &categoryCardsRepo{}
+	service := flashcards.NewService(repo, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.handler.flashcardHandler.flashcardService = service
+	th.sendText(t, 100, "/start")
+
+	th.pressButton(t, 100, flashcardCategoriesCallback)
+	picker, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(picker.Text, "Выберите тему") {
+		t.Fatalf("ожидался выбор темы, получено %#v", th.sender.last())
+	}
+
+	th.pressButton(t, 100, flashcardCategoryCallbackPrefix+"travel")
+	user := th.user(t, 100)
+	if user.FlashcardCategory != "travel" {
+		t.Fatalf("тема должна сохраниться, получено %q", user.FlashcardCategory)
+	}
+	session := service.GetCurrentSession(user.ID)
+	if session == nil || session.CurrentCard.Flashcard.Word != "ticket" {
+		t.Fatalf("ожидалась сессия по теме travel, получено %#v", session)
+	}
+
+	// «Начать изучение» после завершения сессии повторяет выбранную тему
+	service.EndSession(user.ID)
+	th.pressButton(t, 100, "flashcard_start")
+	if got := repo.requested; len(got) != 2 || got[1] != "travel" {
+		t.Errorf("повторный старт должен брать слова темы travel, запрошены темы %q", got)
+	}
+
+	service.EndSession(user.ID)
+	th.pressButton(t, 100, flashcardCategoryCallbackPrefix+allFlashcardCategoriesCode)
+	if user := th.user(t, 100); user.FlashcardCategory != "" {
+		t.Errorf("«Все темы» должны сбрасывать выбор, получено %q", user.FlashcardCategory)
+	}
+	if got := repo.requested; got[len(got)-1] != "" {
+		t.Errorf("без темы ожидалась обычная выборка новых слов, запрошены темы %q", got)
+	}
+}
+
+func TestFlashcardMenuShowsCurrentCategory(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(&categoryCardsRepo{}, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.sendText(t, 100, "/start")
+	user := th.user(t, 100)
+	user.FlashcardCategory = "business"
+
+	th.sender.reset()
+	if err := th.handler.flashcardHandler.HandleFlashcardsCommand(context.Background(), 100, user); err != nil {
+		t.Fatalf("ошибка показа меню карточек: %v", err)
+	}
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "Тема: 💼 Бизнес") {
+		t.Errorf("в меню ожидалась текущая тема, получено %q", texts)
+	}
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Кнопки жалобы на карточку: flashcard_report_<id> открывает выбор причины,
+// flashcard_reason_<id>_<причина> сохраняет жалобу
+const (
+	reportCallbackPrefix = "flashcard_report_"
+	reasonCallbackPrefix = "flashcard_reason_"
+)
+
+// reportReasonButtons подписи причин жалобы в порядке показа
+var reportReasonButtons = []struct {
+	reason string
+	label  string
+}{
+	{models.ReportReasonTranslation, "🔤 Неверный перевод"},
+	{models.ReportReasonExample, "📝 Ошибка в примере"},
+	{models.ReportReasonOther, "❓ Другое"},
+}
+
+// SetFlashcardReports включает кнопку жалобы на открытой карточке
+func (h *Handler) SetFlashcardReports(reports *flashcards.ReportService) {
+	h.flashcardHandler.reports = reports
+}
+
+// reportButtonRow возвращает строку с кнопкой жалобы или nil, если жалобы выключены
+func (h *FlashcardHandler) reportButtonRow(card *models.Flashcard) []tgbotapi.InlineKeyboardButton {
+	if h.reports == nil || card == nil {
+		return nil
+	}
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⚠️ Ошибка в карточке", reportCallbackPrefix+strconv.FormatInt(card.ID, 10)),
+	)
+}
+
+// handleReportRequest предлагает выбрать, что не так с карточкой
+func (h *FlashcardHandler) handleReportRequest(chatID int64, data string) error {
+	flashcardID := strings.TrimPrefix(data, reportCallbackPrefix)
+	if _, err := strconv.ParseInt(flashcardID, 10, 64); err != nil {
+		return fmt.Errorf("некорректный id карточки в жалобе: %s", data)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(reportReasonButtons))
+	for _, button := range reportReasonButtons {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(button.label, reasonCallbackPrefix+flashcardID+"_"+button.reason),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "⚠️ Что не так с карточкой?")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// parseReportReason разбирает callback причины жалобы: flashcard_reason_<id>_<причина>
+func parseReportReason(data string) (int64, string, bool) {
+	idPart, reason, ok := strings.Cut(strings.TrimPrefix(data, reasonCallbackPrefix), "_")
+	if !ok || !models.IsValidReportReason(reason) {
+		return 0, "", false
+	}
+	flashcardID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return flashcardID, reason, true
+}
+
+// handleReportReason сохраняет жалобу на карточку
+func (h *FlashcardHandler) handleReportReason(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64) error {
+	chatID := callback.Message.Chat.ID
+	if h.reports == nil {
+		return nil
+	}
+
+	flashcardID, reason, ok := parseReportReason(callback.Data)
+	if !ok {
+		return fmt.Errorf("некорректная причина жалобы: %s", callback.Data)
+	}
+
+	if _, err := h.reports.ReportCard(ctx, userID, flashcardID, reason); err != nil {
+		h.logger.Error("ошибка сохранения жалобы на карточку", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось отправить жалобу. Попробуйте позже.")
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		"🙏 Спасибо! Мы проверим карточку и исправим ошибку.")
+	_, err := h.sender.Send(editMsg)
+	return err
+}
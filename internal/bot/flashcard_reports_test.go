@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"testing"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestParseReportReason(t *testing.T) {
+	id, reason, ok := parseReportReason(reasonCallbackPrefix + "42_" + models.ReportReasonTranslation)
+	if !ok || id != 42 || reason != models.ReportReasonTranslation {
+		t.Errorf("ожидалось 42 и %q, получено %d, %q, %v", models.ReportReasonTranslation, id, reason, ok)
+	}
+
+	for _, data := range []string{
+		reasonCallbackPrefix + "42",
+		reasonCallbackPrefix + "42_spam",
+		reasonCallbackPrefix + "abc_" + models.ReportReasonOther,
+		reasonCallbackPrefix + "_" + models.ReportReasonOther,
+	} {
+		if _, _, ok := parseReportReason(data); ok {
+			t.Errorf("callback %q не должен разбираться", data)
+		}
+	}
+}
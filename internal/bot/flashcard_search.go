@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/pkg/models"
+)
+
+// findPageSize сколько найденных слов показывается на одной странице /find
+const findPageSize = 5
+
+// findPageCallbackPrefix префикс кнопок листания результатов /find: find_page_<номер>
+const findPageCallbackPrefix = "find_page_"
+
+// vocabularySearches последний запрос /find каждого пользователя: кнопки листания
+// повторяют поиск, а сам запрос в callback data не помещается
+type vocabularySearches struct {
+	mu   sync.Mutex
+	last map[int64]string
+}
+
+func newVocabularySearches() *vocabularySearches {
+	return &vocabularySearches{last: make(map[int64]string)}
+}
+
+func (s *vocabularySearches) put(userID int64, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last[userID] = query
+}
+
+func (s *vocabularySearches) get(userID int64) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query, ok := s.last[userID]
+	return query, ok
+}
+
+// HandleFindCommand обрабатывает команду /find <запрос> — поиск по изученным словам и переводам
+func (h *FlashcardHandler) HandleFindCommand(ctx context.Context, chatID int64, userID int64, query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return h.sendMessage(chatID, "🔎 Укажите, что искать: <code>/find house</code> или <code>/find дом</code>")
+	}
+
+	cards, truncated, err := h.flashcardService.SearchVocabulary(ctx, userID, query)
+	if err != nil {
+		h.logger.Error("ошибка поиска по словарю", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось выполнить поиск")
+	}
+
+	if len(cards) == 0 {
+		return h.sendMessage(chatID, fmt.Sprintf(
+			"🤷 Среди изученных слов ничего не нашлось по запросу <b>%s</b>.\n\nНовые слова появляются в /flashcards.",
+			html.EscapeString(query)))
+	}
+
+	h.searches.put(userID, query)
+	text, keyboard := formatFindPage(query, cards, truncated, 0, time.Now())
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	_, err = h.sender.Send(msg)
+	return err
+}
+
+// HandleFindPageCallback листает результаты /find в том же сообщении
+func (h *FlashcardHandler) HandleFindPageCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64) error {
+	chatID := callback.Message.Chat.ID
+	page, err := strconv.Atoi(strings.TrimPrefix(callback.Data, findPageCallbackPrefix))
+	if err != nil || page < 0 {
+		h.logger.Warn("некорректная страница поиска в кнопке", zap.String("data", callback.Data))
+		return nil
+	}
+
+	query, ok := h.searches.get(userID)
+	if !ok {
+		return h.sendMessage(chatID, "🔎 Результаты поиска устарели, повторите /find.")
+	}
+
+	cards, truncated, err := h.flashcardService.SearchVocabulary(ctx, userID, query)
+	if err != nil {
+		h.logger.Error("ошибка поиска по словарю", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось выполнить поиск")
+	}
+	if len(cards) == 0 {
+		return h.sendMessage(chatID, "🔎 Результаты поиска устарели, повторите /find.")
+	}
+
+	text, keyboard := formatFindPage(query, cards, truncated, page, time.Now())
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = keyboard
+	_, err = h.sender.Send(edit)
+	return err
+}
+
+// formatFindPage форматирует страницу результатов поиска и кнопки листания
+// (nil, если результаты помещаются на одну страницу). Номер страницы за пределами
+// результатов приводится к последней.
+func formatFindPage(query string, cards []*models.UserFlashcard, truncated bool, page int, now time.Time) (string, *tgbotapi.InlineKeyboardMarkup) {
+	pages := (len(cards) + findPageSize - 1) / findPageSize
+	page = min(max(page, 0), pages-1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔎 <b>Поиск: %s</b>\n", html.EscapeString(query))
+	found := fmt.Sprintf("Найдено слов: %d", len(cards))
+	if truncated {
+		found = fmt.Sprintf("Показаны первые %d совпадений — уточните запрос", len(cards))
+	}
+	b.WriteString(found)
+	if pages > 1 {
+		fmt.Fprintf(&b, " • стр. %d/%d", page+1, pages)
+	}
+	b.WriteString("\n")
+
+	end := min((page+1)*findPageSize, len(cards))
+	for _, card := range cards[page*findPageSize : end] {
+		b.WriteString("\n" + formatFoundWord(card, now) + "\n")
+	}
+
+	if pages == 1 {
+		return strings.TrimRight(b.String(), "\n"), nil
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", findPageCallbackPrefix+strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("Вперед ➡️", findPageCallbackPrefix+strconv.Itoa(page+1)))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return strings.TrimRight(b.String(), "\n"), &keyboard
+}
+
+// formatFoundWord одна строка результата поиска: слово, перевод и состояние повторения
+func formatFoundWord(userCard *models.UserFlashcard, now time.Time) string {
+	word := ""
+	if userCard.Flashcard != nil {
+		word = fmt.Sprintf("<b>%s</b> — %s",
+			html.EscapeString(userCard.Flashcard.Word), html.EscapeString(userCard.Flashcard.Translation))
+	}
+
+	var status string
+	switch {
+	case userCard.IsLearned:
+		status = "✅ выучено"
+	case !userCard.NextReviewAt.After(now):
+		status = "⏰ пора повторить"
+	default:
+		status = "⏰ повторение " + flashcards.FormatTimeUntil(userCard.NextReviewAt.Sub(now))
+	}
+	return word + "\n   " + status
+}
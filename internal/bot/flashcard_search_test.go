@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+)
+
+// searchRepo отдает заранее заданные совпадения и запоминает запросы поиска
+type searchRepo struct {
+	store.FlashcardRepository
+	found   []*models.UserFlashcard
+	queries []string
+}
+
+func (r *searchRepo) SearchUserFlashcards(ctx context.Context, userID int64, query string, limit int) ([]*models.UserFlashcard, error) {
+	r.queries = append(r.queries, query)
+	return r.found[:min(limit, len(r.found))], nil
+}
+
+func TestFindCommandPaginatesResults(t *testing.T) {
+	th := newTestHarness(t)
+	repo := &searchRepo{}
+	for i := range 7 {
+		repo.found = append(repo.found, &models.UserFlashcard{
+			IsLearned:    i == 0,
+			NextReviewAt: time.Now().Add(48*time.Hour + time.Minute),
+			Flashcard:    &models.Flashcard{Word: fmt.Sprintf("house%d", i), Translation: "дом"},
+		})
+	}
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(repo, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.sendText(t, 100, "/start")
+
+	th.sender.reset()
+	th.sendText(t, 100, "/find  Дом ")
+	reply, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(reply.Text, "Найдено слов: 7") || !strings.Contains(reply.Text, "стр. 1/2") {
+		t.Fatalf("ожидалась первая страница результатов, получено %#v", th.sender.last())
+	}
+	if !strings.Contains(reply.Text, "house0</b> — дом\n   ✅ выучено") || !strings.Contains(reply.Text, "повторение через 2 дн") {
+		t.Errorf("ожидались статус и время повторения, получено %q", reply.Text)
+	}
+	if strings.Contains(reply.Text, "house5") {
+		t.Errorf("на первой странице не должно быть шестого слова: %q", reply.Text)
+	}
+	if repo.queries[0] != "Дом" {
+		t.Errorf("ожидался запрос без пробелов, получено %q", repo.queries[0])
+	}
+	next := reply.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0]
+	if len(next) != 1 || *next[0].CallbackData != findPageCallbackPrefix+"1" {
+		t.Fatalf("на первой странице ожидалась только кнопка вперед, получено %#v", next)
+	}
+
+	th.sender.reset()
+	th.pressButton(t, 100, *next[0].CallbackData)
+	edit, ok := th.sender.last().(tgbotapi.EditMessageTextConfig)
+	if !ok || !strings.Contains(edit.Text, "house6") || strings.Contains(edit.Text, "house4") {
+		t.Fatalf("ожидалась вторая страница в том же сообщении, получено %#v", th.sender.last())
+	}
+	if prev := edit.ReplyMarkup.InlineKeyboard[0]; len(prev) != 1 || *prev[0].CallbackData != findPageCallbackPrefix+"0" {
+		t.Errorf("на последней странице ожидалась только кнопка назад, получено %#v", prev)
+	}
+}
+
+func TestFindCommandEmptyAndNoMatch(t *testing.T) {
+	th := newTestHarness(t)
+	repo := &searchRepo{}
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(repo, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.sendText(t, 100, "/start")
+
+	th.sender.reset()
+	th.sendText(t, 100, "/find")
+	if got := th.sender.texts(); len(got) != 1 || !strings.Contains(got[0], "Укажите, что искать") {
+		t.Fatalf("ожидалась подсказка с примером, получено %q", got)
+	}
+	if len(repo.queries) != 0 {
+		t.Error("пустой запрос не должен уходить в БД")
+	}
+
+	th.sender.reset()
+	th.sendText(t, 100, "/find <xyz>")
+	if got := th.sender.texts(); len(got) != 1 || !strings.Contains(got[0], "ничего не нашлось по запросу <b>&lt;xyz&gt;</b>") {
+		t.Fatalf("ожидалось сообщение об отсутствии совпадений, получено %q", got)
+	}
+
+	th.sender.reset()
+	th.pressButton(t, 100, findPageCallbackPrefix+"1")
+	if got := th.sender.texts(); len(got) != 1 || !strings.Contains(got[0], "повторите /find") {
+		t.Errorf("без сохраненного поиска ожидалась просьба повторить /find, получено %q", got)
+	}
+}
@@ -2,7 +2,10 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,22 +18,35 @@ import (
 
 // FlashcardHandler обработчик команд для словарных карточек
 type FlashcardHandler struct {
-	bot              *tgbotapi.BotAPI
+	bot              Sender
+	sender           *SendDispatcher
 	flashcardService *flashcards.Service
+	reports          *flashcards.ReportService // жалобы на карточки (nil — кнопка скрыта)
 	logger           *zap.Logger
+
+	// onWordLearned вызывается после ответа, которым слово стало выученным (nil — не вызывается)
+	onWordLearned func(ctx context.Context, chatID int64, userID int64)
+	// setCategory сохраняет выбранную тему карточек (nil — тема не запоминается)
+	setCategory func(ctx context.Context, userID int64, category string) error
+	// searches последние запросы /find для листания результатов
+	searches *vocabularySearches
 }
 
 // NewFlashcardHandler создает новый обработчик карточек
-func NewFlashcardHandler(bot *tgbotapi.BotAPI, flashcardService *flashcards.Service, logger *zap.Logger) *FlashcardHandler {
+func NewFlashcardHandler(bot Sender, sender *SendDispatcher, flashcardService *flashcards.Service, logger *zap.Logger) *FlashcardHandler {
 	return &FlashcardHandler{
 		bot:              bot,
+		sender:           sender,
 		flashcardService: flashcardService,
 		logger:           logger,
+		searches:         newVocabularySearches(),
 	}
 }
 
 // HandleFlashcardsCommand обрабатывает команду /flashcards
-func (h *FlashcardHandler) HandleFlashcardsCommand(ctx context.Context, chatID int64, userID int64, userLevel string) error {
+func (h *FlashcardHandler) HandleFlashcardsCommand(ctx context.Context, chatID int64, user *models.User) error {
+	userID := user.ID
+
 	// Проверяем, есть ли активная сессия
 	session := h.flashcardService.GetCurrentSession(userID)
 	if session != nil {
@@ -49,12 +65,17 @@ func (h *FlashcardHandler) HandleFlashcardsCommand(ctx context.Context, chatID i
 
 %s
 
-Выберите действие:`, recommendation)
+🗂 Тема: %s
+
+Выберите действие:`, recommendation, models.FlashcardCategoryName(user.FlashcardCategory))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🎯 Начать изучение", "flashcard_start"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗂 Выбрать тему", flashcardCategoriesCallback),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("📊 Моя статистика", "flashcard_stats"),
 		),
@@ -67,14 +88,15 @@ func (h *FlashcardHandler) HandleFlashcardsCommand(ctx context.Context, chatID i
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	return err
 }
 
 // HandleFlashcardCallback обрабатывает callback от inline кнопок
-func (h *FlashcardHandler) HandleFlashcardCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64, userLevel string) error {
+func (h *FlashcardHandler) HandleFlashcardCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
 	data := callback.Data
 	chatID := callback.Message.Chat.ID
+	userID := user.ID
 
 	h.logger.Debug("обработка flashcard callback",
 		zap.String("data", data),
@@ -82,7 +104,11 @@ func (h *FlashcardHandler) HandleFlashcardCallback(ctx context.Context, callback
 
 	switch {
 	case data == "flashcard_start":
-		return h.startFlashcardSession(ctx, chatID, userID, userLevel)
+		return h.startFlashcardSession(ctx, chatID, userID, user.Level, user.FlashcardCategory)
+	case data == flashcardCategoriesCallback:
+		return h.showCategoryPicker(chatID, user.FlashcardCategory)
+	case strings.HasPrefix(data, flashcardCategoryCallbackPrefix):
+		return h.handleCategoryChoice(ctx, chatID, user, data)
 	case data == "flashcard_stats":
 		return h.showFlashcardStats(ctx, chatID, userID)
 	case data == "flashcard_back":
@@ -93,22 +119,37 @@ func (h *FlashcardHandler) HandleFlashcardCallback(ctx context.Context, callback
 		return h.handleCardAnswer(ctx, callback, userID)
 	case data == "flashcard_next":
 		return h.showCurrentCard(ctx, chatID, userID)
+	case strings.HasPrefix(data, reportCallbackPrefix):
+		return h.handleReportRequest(chatID, data)
+	case strings.HasPrefix(data, reasonCallbackPrefix):
+		return h.handleReportReason(ctx, callback, userID)
+	case data == "flashcard_new_example":
+		return h.handleNewExample(ctx, callback, userID)
+	case data == "flashcard_skip":
+		return h.handleSkipCard(ctx, chatID, userID)
 	case data == "flashcard_end":
-		return h.endFlashcardSession(ctx, chatID, userID)
+		return h.endFlashcardSession(ctx, chatID, user)
 	case data == "flashcard_results":
 		session := h.flashcardService.GetCurrentSession(userID)
 		if session != nil {
 			return h.showSessionResults(ctx, chatID, userID, session)
 		}
-		return h.HandleFlashcardsCommand(ctx, chatID, userID, userLevel)
+		return h.HandleFlashcardsCommand(ctx, chatID, user)
 	default:
 		return fmt.Errorf("неизвестная команда карточек: %s", data)
 	}
 }
 
-// startFlashcardSession начинает новую сессию изучения
-func (h *FlashcardHandler) startFlashcardSession(ctx context.Context, chatID int64, userID int64, userLevel string) error {
-	session, err := h.flashcardService.StartFlashcardSession(ctx, userID, userLevel)
+// startFlashcardSession продолжает активную сессию или начинает новую по теме category.
+// Используется всеми точками входа, включая кнопку на экране статистики.
+func (h *FlashcardHandler) startFlashcardSession(ctx context.Context, chatID int64, userID int64, userLevel, category string) error {
+	session, _, err := h.flashcardService.ResumeOrStartSession(ctx, userID, userLevel, category)
+	if errors.Is(err, flashcards.ErrCategoryExhausted) {
+		return h.sendCategoryExhausted(chatID, category)
+	}
+	if errors.Is(err, flashcards.ErrDailyPaceReached) {
+		return h.sendMessage(chatID, "🎯 <b>Норма новых слов на сегодня выполнена!</b>\n\nПовторять пока нечего — возвращайтесь завтра. Изменить темп можно командой /pace.")
+	}
 	if err != nil {
 		h.logger.Error("ошибка начала сессии карточек", zap.Error(err))
 		return h.sendMessage(chatID, "❌ Ошибка начала изучения. Попробуйте позже.")
@@ -144,6 +185,11 @@ func (h *FlashcardHandler) showCurrentCard(ctx context.Context, chatID int64, us
 		return h.showSessionResults(ctx, chatID, userID, session)
 	}
 
+	return h.sendRevealedCard(chatID, userID, session)
+}
+
+// sendRevealedCard отправляет карточку с переводом, примером и вариантами ответа
+func (h *FlashcardHandler) sendRevealedCard(chatID int64, userID int64, session *models.FlashcardSession) error {
 	card := session.CurrentCard.Flashcard
 	progress := h.flashcardService.GetSessionProgress(userID)
 
@@ -165,6 +211,7 @@ func (h *FlashcardHandler) showCurrentCard(ctx context.Context, chatID int64, us
 			tgbotapi.NewInlineKeyboardButtonData("👀 Показать перевод", "flashcard_show_translation"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", "flashcard_skip"),
 			tgbotapi.NewInlineKeyboardButtonData("❌ Завершить", "flashcard_end"),
 		),
 	)
@@ -173,10 +220,23 @@ func (h *FlashcardHandler) showCurrentCard(ctx context.Context, chatID int64, us
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
+// handleSkipCard откладывает текущую карточку и показывает следующую
+func (h *FlashcardHandler) handleSkipCard(ctx context.Context, chatID int64, userID int64) error {
+	skipped, err := h.flashcardService.SkipCard(userID)
+	if err != nil {
+		h.logger.Warn("не удалось пропустить карточку", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Активная карточка не найдена.\n\nПопробуйте начать изучение заново, нажав на кнопку \"📝 Словарные карточки\".")
+	}
+	if !skipped {
+		return h.sendMessage(chatID, "⏭ Это последняя карточка в сессии — пропускать некуда. Покажите перевод или завершите сессию.")
+	}
+	return h.showCurrentCard(ctx, chatID, userID)
+}
+
 // handleShowTranslation показывает перевод и варианты ответа (редактирует сообщение)
 func (h *FlashcardHandler) handleShowTranslation(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64) error {
 	chatID := callback.Message.Chat.ID
@@ -203,10 +263,10 @@ func (h *FlashcardHandler) handleShowTranslation(ctx context.Context, callback *
 		progress["total_cards"].(int),
 		card.Word,
 		card.Translation,
-		card.Example,
+		html.EscapeString(card.Example),
 	)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+	rows := [][]tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("😊 Легко", "flashcard_answer_easy"),
 			tgbotapi.NewInlineKeyboardButtonData("🤔 Хорошо", "flashcard_answer_good"),
@@ -215,16 +275,45 @@ func (h *FlashcardHandler) handleShowTranslation(ctx context.Context, callback *
 			tgbotapi.NewInlineKeyboardButtonData("😓 Сложно", "flashcard_answer_hard"),
 			tgbotapi.NewInlineKeyboardButtonData("❌ Не знал", "flashcard_answer_wrong"),
 		),
-	)
+	}
+	if h.flashcardService.ExamplesEnabled() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Новый пример", "flashcard_new_example"),
+		))
+	}
+	if row := h.reportButtonRow(card); row != nil {
+		rows = append(rows, row)
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
+// handleNewExample подбирает для открытой карточки новый пример и показывает ее заново
+func (h *FlashcardHandler) handleNewExample(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64) error {
+	chatID := callback.Message.Chat.ID
+
+	_, err := h.flashcardService.RefreshExample(ctx, userID)
+	if errors.Is(err, flashcards.ErrExampleLimitReached) {
+		return h.sendMessage(chatID, "🔁 Лимит новых примеров в этой сессии исчерпан. Оцените, насколько хорошо вы знали слово.")
+	}
+	if err != nil {
+		h.logger.Error("ошибка получения нового примера", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось подобрать новый пример. Попробуйте позже.")
+	}
+
+	session := h.flashcardService.GetCurrentSession(userID)
+	if session == nil || session.CurrentCard == nil {
+		return h.sendMessage(chatID, "❌ Активная карточка не найдена.")
+	}
+	return h.sendRevealedCard(chatID, userID, session)
+}
+
 // handleCardAnswer обрабатывает ответ пользователя на карточку
 func (h *FlashcardHandler) handleCardAnswer(ctx context.Context, callback *tgbotapi.CallbackQuery, userID int64) error {
 	data := callback.Data
@@ -250,12 +339,18 @@ func (h *FlashcardHandler) handleCardAnswer(ctx context.Context, callback *tgbot
 		return fmt.Errorf("неизвестный ответ: %s", data)
 	}
 
+	// Запоминаем карточку до ответа: после него сессия переходит к следующей
+	var flashcardID int64
+	if session := h.flashcardService.GetCurrentSession(userID); session != nil && session.CurrentCard != nil {
+		flashcardID = session.CurrentCard.FlashcardID
+	}
+
 	answer, err := h.flashcardService.AnswerCard(ctx, userID, isCorrect, difficulty)
 	if err != nil {
 		h.logger.Error("ошибка обработки ответа", zap.Error(err))
 
 		// Если сессия потеряна, попробуем восстановить её
-		if err.Error() == "активная сессия не найдена" {
+		if errors.Is(err, flashcards.ErrNoActiveSession) {
 			h.logger.Info("попытка восстановления сессии карточек", zap.Int64("user_id", userID))
 			return h.sendMessage(chatID, "❌ Активная карточка не найдена.\n\nПопробуйте начать изучение заново, нажав на кнопку \"📝 Словарные карточки\".")
 		}
@@ -267,7 +362,10 @@ func (h *FlashcardHandler) handleCardAnswer(ctx context.Context, callback *tgbot
 	var resultEmoji string
 	var nextReviewText string
 
-	if answer.IsCorrect {
+	if answer.FastTracked {
+		resultEmoji = "🚀"
+		nextReviewText = "Слово вам уже знакомо — отмечено как выученное"
+	} else if answer.IsCorrect {
 		resultEmoji = "✅"
 		hours := int(answer.NextReviewIn.Hours())
 		if hours < 1 {
@@ -275,14 +373,16 @@ func (h *FlashcardHandler) handleCardAnswer(ctx context.Context, callback *tgbot
 		} else {
 			nextReviewText = fmt.Sprintf("Повторим через %d ч", hours)
 		}
+	} else if answer.RequeuedInSession {
+		resultEmoji = "❌"
+		nextReviewText = "Покажем это слово еще раз в этой сессии"
 	} else {
 		resultEmoji = "❌"
 		nextReviewText = "Повторим скоро"
 	}
 
-	// Проверяем, есть ли еще карточки
-	session := h.flashcardService.GetCurrentSession(userID)
-	hasMoreCards := session != nil && session.CurrentCard != nil
+	// Остались ли карточки, сообщает сам ответ: после последней сессия уже завершена
+	hasMoreCards := answer.HasMoreCards
 
 	messageText := fmt.Sprintf(`%s <b>Ответ записан!</b>
 
@@ -309,13 +409,22 @@ func (h *FlashcardHandler) handleCardAnswer(ctx context.Context, callback *tgbot
 			}(),
 		),
 	)
+	if flashcardID != 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎤 Произнести слово", pronounceCardCallbackPrefix+strconv.FormatInt(flashcardID, 10)),
+		))
+	}
 
 	// Редактируем сообщение
 	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, messageText)
 	editMsg.ParseMode = "HTML"
 	editMsg.ReplyMarkup = &keyboard
 
-	_, err = h.bot.Send(editMsg)
+	_, err = h.sender.Send(editMsg)
+
+	if answer.Learned && h.onWordLearned != nil {
+		h.onWordLearned(ctx, chatID, userID)
+	}
 	return err
 }
 
@@ -355,7 +464,7 @@ func (h *FlashcardHandler) showSessionResults(ctx context.Context, chatID int64,
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 
 	// Завершаем сессию
 	h.flashcardService.EndSession(userID)
@@ -363,6 +472,14 @@ func (h *FlashcardHandler) showSessionResults(ctx context.Context, chatID int64,
 	return err
 }
 
+// reviewForecastText краткий прогноз повторений на ближайшие дни
+func reviewForecastText(f *models.ReviewForecast) string {
+	return fmt.Sprintf(`🔮 <b>Прогноз повторений:</b>
+• Сегодня: %d
+• Завтра: %d
+• За %d дней: %d`, f.Today, f.Tomorrow, models.ReviewForecastDays, f.Week)
+}
+
 // showFlashcardStats показывает статистику пользователя
 func (h *FlashcardHandler) showFlashcardStats(ctx context.Context, chatID int64, userID int64) error {
 	stats, err := h.flashcardService.GetUserStats(ctx, userID)
@@ -376,23 +493,55 @@ func (h *FlashcardHandler) showFlashcardStats(ctx context.Context, chatID int64,
 	cardsToReview := stats["cards_to_review"].(int)
 	accuracy := stats["accuracy_percentage"].(float64)
 
+	pace := ""
+	if introduced, perDay, err := h.flashcardService.DailyNewCards(ctx, userID); err != nil {
+		h.logger.Warn("не удалось получить дневную норму новых слов", zap.Error(err))
+	} else {
+		pace = fmt.Sprintf("\n• Новых слов сегодня: %d из %d (/pace)", min(introduced, perDay), perDay)
+	}
+
+	weekly := ""
+	if progress, err := h.flashcardService.WeeklyProgress(ctx, userID); err != nil {
+		h.logger.Warn("не удалось получить недельную цель", zap.Error(err))
+	} else if progress.HasTarget() {
+		weekly = "\n" + weeklyTargetProgressText(progress) + " (/weeklytarget)"
+	}
+
+	forecast := ""
+	if f, err := h.flashcardService.ReviewForecast(ctx, userID); err != nil {
+		h.logger.Warn("не удалось получить прогноз повторений", zap.Error(err))
+	} else {
+		forecast = "\n\n" + reviewForecastText(f)
+	}
+
+	vocabulary := ""
+	if trend, err := h.flashcardService.VocabularyTrend(ctx, userID, flashcards.DefaultVocabularyWeeks); err != nil {
+		h.logger.Warn("не удалось получить динамику словарного запаса", zap.Error(err))
+	} else {
+		vocabulary = "\n\n" + vocabularyTrendText(trend)
+	}
+
 	messageText := fmt.Sprintf(`📊 <b>Статистика карточек</b>
 
 📚 <b>Общее:</b>
 • Всего карточек: %d
 • Выучено слов: %d
 • К повторению: %d
-• Точность ответов: %.1f%%
+• Точность ответов: %.1f%%%s%s%s
 
 📈 <b>Прогресс:</b>
-%s
+%s%s
 
 %s`,
 		totalCards,
 		learnedCards,
 		cardsToReview,
 		accuracy,
+		pace,
+		weekly,
+		forecast,
 		h.getProgressBar(learnedCards, totalCards),
+		vocabulary,
 		func() string {
 			if cardsToReview > 0 {
 				return fmt.Sprintf("🎯 Рекомендуем повторить %d карточек сегодня!", cardsToReview)
@@ -401,9 +550,14 @@ func (h *FlashcardHandler) showFlashcardStats(ctx context.Context, chatID int64,
 		}(),
 	)
 
+	startLabel := "🎯 Начать изучение"
+	if session := h.flashcardService.GetCurrentSession(userID); session != nil && session.CurrentCard != nil {
+		startLabel = "▶️ Продолжить изучение"
+	}
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🎯 Начать изучение", "flashcard_start"),
+			tgbotapi.NewInlineKeyboardButtonData(startLabel, "flashcard_start"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "flashcard_back"),
@@ -414,18 +568,18 @@ func (h *FlashcardHandler) showFlashcardStats(ctx context.Context, chatID int64,
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	return err
 }
 
 // endFlashcardSession завершает сессию карточек
-func (h *FlashcardHandler) endFlashcardSession(ctx context.Context, chatID int64, userID int64) error {
-	session := h.flashcardService.GetCurrentSession(userID)
+func (h *FlashcardHandler) endFlashcardSession(ctx context.Context, chatID int64, user *models.User) error {
+	session := h.flashcardService.GetCurrentSession(user.ID)
 	if session == nil {
-		return h.HandleFlashcardsCommand(ctx, chatID, userID, "beginner") // Fallback
+		return h.HandleFlashcardsCommand(ctx, chatID, user) // Fallback
 	}
 
-	h.flashcardService.EndSession(userID)
+	h.flashcardService.EndSession(user.ID)
 
 	messageText := `📚 <b>Сессия завершена</b>
 
@@ -442,7 +596,7 @@ func (h *FlashcardHandler) endFlashcardSession(ctx context.Context, chatID int64
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
@@ -454,55 +608,105 @@ func (h *FlashcardHandler) showMainMenu(ctx context.Context, chatID int64) error
 
 Выберите действие:`
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📚 Обучение", "learning_menu"),
-			tgbotapi.NewInlineKeyboardButtonData("📊 Статистика", "main_stats"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏆 Рейтинг", "main_rating"),
-			tgbotapi.NewInlineKeyboardButtonData("💎 Премиум", "main_premium"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❓ Помощь", "main_help"),
-		),
-	)
+	keyboard := inlineKeyboard(mainMenuLayout)
 
 	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
+// HandleWhenCommand обрабатывает команду /when <слово> — когда слово вернется на повторение
+func (h *FlashcardHandler) HandleWhenCommand(ctx context.Context, chatID int64, userID int64, word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return h.sendMessage(chatID, "🔎 Укажите слово: <code>/when apple</code>")
+	}
+
+	userCard, err := h.flashcardService.GetWordSchedule(ctx, userID, word)
+	if err != nil {
+		h.logger.Error("ошибка получения расписания слова", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось получить информацию о слове")
+	}
+
+	if userCard == nil {
+		return h.sendMessage(chatID, fmt.Sprintf(
+			"🤔 Вы еще не начали учить слово <b>%s</b>.\n\nНовые слова появляются в /flashcards.",
+			html.EscapeString(word)))
+	}
+
+	return h.sendMessage(chatID, formatWordSchedule(userCard, time.Now()))
+}
+
+// HandleForgetCommand обрабатывает команду /forget <слово> — возвращает выученное слово на повторение
+func (h *FlashcardHandler) HandleForgetCommand(ctx context.Context, chatID int64, userID int64, word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return h.sendMessage(chatID, "🔎 Укажите слово: <code>/forget apple</code>")
+	}
+
+	userCard, err := h.flashcardService.ForgetWord(ctx, userID, word)
+	switch {
+	case errors.Is(err, flashcards.ErrWordNotInDeck):
+		return h.sendMessage(chatID, fmt.Sprintf("🤔 Слова <b>%s</b> нет в колоде карточек.", html.EscapeString(word)))
+	case errors.Is(err, flashcards.ErrWordNotStudied):
+		return h.sendMessage(chatID, fmt.Sprintf(
+			"🤔 Вы еще не учили слово <b>%s</b>.\n\nНовые слова появляются в /flashcards.",
+			html.EscapeString(word)))
+	case err != nil:
+		h.logger.Error("ошибка возврата слова на повторение", zap.Error(err), zap.Int64("user_id", userID))
+		return h.sendMessage(chatID, "❌ Не удалось вернуть слово на повторение")
+	}
+
+	return h.sendMessage(chatID, fmt.Sprintf(
+		"🔁 Слово <b>%s</b> — %s снова в повторении.\n\nОно попадется в следующей сессии /flashcards.",
+		html.EscapeString(userCard.Flashcard.Word), html.EscapeString(userCard.Flashcard.Translation)))
+}
+
+// formatWordSchedule формирует описание расписания повторения слова
+func formatWordSchedule(userCard *models.UserFlashcard, now time.Time) string {
+	var next string
+	switch {
+	case userCard.IsLearned:
+		next = "✅ Слово выучено, повторять не нужно"
+	case !userCard.NextReviewAt.After(now):
+		next = "⏰ Пора повторить прямо сейчас"
+	default:
+		next = "⏰ Следующее повторение " + flashcards.FormatTimeUntil(userCard.NextReviewAt.Sub(now))
+	}
+
+	accuracy := 0
+	if userCard.ReviewCount > 0 {
+		accuracy = userCard.CorrectCount * 100 / userCard.ReviewCount
+	}
+
+	word := ""
+	if userCard.Flashcard != nil {
+		word = fmt.Sprintf("<b>%s</b> — %s\n\n",
+			html.EscapeString(userCard.Flashcard.Word), html.EscapeString(userCard.Flashcard.Translation))
+	}
+
+	text := fmt.Sprintf("📅 %s%s\n🔁 Повторений: %d\n🎯 Точность: %d%%",
+		word, next, userCard.ReviewCount, accuracy)
+	if userCard.BestPronunciationScore != nil {
+		text += fmt.Sprintf("\n🎤 Лучшее произношение: %d%%", *userCard.BestPronunciationScore)
+	}
+	return text
+}
+
 // sendMessage отправляет простое текстовое сообщение
 func (h *FlashcardHandler) sendMessage(chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
 // getProgressBar создает текстовый прогресс-бар
 func (h *FlashcardHandler) getProgressBar(current, total int) string {
-	if total == 0 {
-		return "▱▱▱▱▱▱▱▱▱▱ 0%"
-	}
-
-	percentage := float64(current) / float64(total) * 100
-	filled := int(percentage / 10)
-
-	bar := ""
-	for i := 0; i < 10; i++ {
-		if i < filled {
-			bar += "▰"
-		} else {
-			bar += "▱"
-		}
-	}
-
-	return fmt.Sprintf("%s %.1f%%", bar, percentage)
+	return progressBar(current, total)
 }
 
 
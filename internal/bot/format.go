@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale определяет правила форматирования чисел и дат
+type Locale string
+
+const (
+	LocaleRU Locale = "ru"
+	LocaleEN Locale = "en"
+
+	// DefaultLocale локаль по умолчанию для сообщений бота
+	DefaultLocale = LocaleRU
+)
+
+// FormatNumber форматирует целое число с разделителями разрядов.
+// Для ru используется неразрывный пробел (10 000), для en — запятая (10,000).
+func FormatNumber(n int, locale Locale) string {
+	separator := "\u00a0"
+	if locale == LocaleEN {
+		separator = ","
+	}
+
+	digits := strconv.Itoa(n)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if i > 0 {
+			sb.WriteString(separator)
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+
+	return sb.String()
+}
+
+// FormatDate форматирует дату для показа пользователю.
+// Нулевая дата отображается как прочерк.
+func FormatDate(t time.Time, locale Locale) string {
+	if t.IsZero() {
+		return "—"
+	}
+	if locale == LocaleEN {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("02.01.2006")
+}
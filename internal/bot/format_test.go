@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n      int
+		locale Locale
+		want   string
+	}{
+		{0, LocaleRU, "0"},
+		{999, LocaleRU, "999"},
+		{1000, LocaleRU, "1\u00a0000"},
+		{9999, LocaleRU, "9\u00a0999"},
+		{20000, LocaleRU, "20\u00a0000"},
+		{1234567, LocaleRU, "1\u00a0234\u00a0567"},
+		{-1500, LocaleRU, "-1\u00a0500"},
+		{-999, LocaleRU, "-999"},
+		{1234567, LocaleEN, "1,234,567"},
+		{100000, LocaleEN, "100,000"},
+		{42, "unknown", "42"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNumber(tt.n, tt.locale); got != tt.want {
+			t.Errorf("FormatNumber(%d, %s): ожидалось %q, получено %q", tt.n, tt.locale, tt.want, got)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 23, 59, 0, 0, time.UTC)
+
+	if got := FormatDate(date, LocaleRU); got != "05.03.2024" {
+		t.Errorf("ожидалось %q, получено %q", "05.03.2024", got)
+	}
+	if got := FormatDate(date, LocaleEN); got != "Mar 5, 2024" {
+		t.Errorf("ожидалось %q, получено %q", "Mar 5, 2024", got)
+	}
+	if got := FormatDate(time.Time{}, LocaleRU); got != "—" {
+		t.Errorf("для нулевой даты ожидался прочерк, получено %q", got)
+	}
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// chatKind — тип чата с точки зрения бота
+type chatKind int
+
+const (
+	chatKindUnknown chatKind = iota
+	chatKindPrivate
+	chatKindGroup
+	chatKindChannel
+)
+
+// classifyChat явно разбирает все значения Chat.Type
+func classifyChat(chat *tgbotapi.Chat) chatKind {
+	if chat == nil {
+		return chatKindUnknown
+	}
+	switch chat.Type {
+	case "private":
+		return chatKindPrivate
+	case "group", "supergroup":
+		return chatKindGroup
+	case "channel":
+		return chatKindChannel
+	default:
+		return chatKindUnknown
+	}
+}
+
+// isGroupChat проверяет, пришло ли сообщение из группы
+func isGroupChat(message *tgbotapi.Message) bool {
+	return message != nil && classifyChat(message.Chat) == chatKindGroup
+}
+
+// privateOnlyCommands — команды, ответы на которые уходят в личку, чтобы не спамить в группе
+var privateOnlyCommands = map[string]bool{
+	"start":      true,
+	"learning":   true,
+	"flashcards": true,
+	"premium":    true,
+	"gift":       true,
+	"tour":       true,
+}
+
+// privateOnlyActions — кнопки меню, которые обрабатываются только в личке
+var privateOnlyActions = map[MenuAction]bool{
+	ActionLearning:   true,
+	ActionPremium:    true,
+	ActionReferral:   true,
+	ActionFlashcards: true,
+	ActionLevelTest:  true,
+	ActionWordPack:   true,
+	ActionDictation:  true,
+	ActionPhrase:     true,
+	ActionStartTest:  true,
+	ActionCancelTest: true,
+	ActionBackToMain: true,
+}
+
+// SetGroupsEnabled включает или выключает работу бота в группах
+func (h *Handler) SetGroupsEnabled(enabled bool) {
+	h.groupsEnabled = enabled
+}
+
+// isAddressedToBot проверяет, обращено ли групповое сообщение к боту:
+// упоминание @username, команда вида /cmd@bot или ответ на сообщение бота
+func isAddressedToBot(message *tgbotapi.Message, botID int64, botUsername string) bool {
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil &&
+		message.ReplyToMessage.From.ID == botID {
+		return true
+	}
+
+	if message.IsCommand() {
+		parts := strings.SplitN(message.CommandWithAt(), "@", 2)
+		return len(parts) == 2 && strings.EqualFold(parts[1], botUsername)
+	}
+
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+	}
+	entities := message.Entities
+	if len(entities) == 0 {
+		entities = message.CaptionEntities
+	}
+
+	for _, entity := range entities {
+		switch entity.Type {
+		case "mention":
+			mention := entityText(text, entity)
+			if strings.EqualFold(strings.TrimPrefix(mention, "@"), botUsername) {
+				return true
+			}
+		case "text_mention":
+			if entity.User != nil && entity.User.ID == botID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entityText вырезает текст сущности (смещения в Telegram считаются в UTF-16)
+func entityText(text string, entity tgbotapi.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if entity.Offset < 0 || end > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[entity.Offset:end]))
+}
+
+// stripBotMention убирает упоминание бота из текста сообщения
+func stripBotMention(text, botUsername string) string {
+	if botUsername == "" {
+		return strings.TrimSpace(text)
+	}
+	// Ищем без учета регистра прямо в исходном тексте: у ToLower другая длина
+	// для части символов, и его индексы нельзя применять к оригиналу
+	mention := regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(botUsername) + `\b`)
+	text = mention.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// prepareGroupMessage решает, нужно ли обрабатывать сообщение из группы.
+// Возвращает false, если сообщение не адресовано боту или группы отключены.
+func (h *Handler) prepareGroupMessage(message *tgbotapi.Message) bool {
+	if !h.groupsEnabled {
+		h.logger.Debug("сообщение из группы проигнорировано: поддержка групп выключена",
+			zap.Int64("chat_id", message.Chat.ID))
+		return false
+	}
+
+	if !isAddressedToBot(message, h.self.ID, h.self.UserName) {
+		return false
+	}
+
+	// Убираем упоминание, чтобы оно не попадало в AI и не мешало распознаванию кнопок
+	if !message.IsCommand() && message.Text != "" {
+		message.Text = stripBotMention(message.Text, h.self.UserName)
+		message.Entities = nil
+	}
+	return true
+}
+
+// needsPrivateChat проверяет, должно ли групповое сообщение обрабатываться в личке
+func needsPrivateChat(message *tgbotapi.Message) bool {
+	if message.IsCommand() {
+		return privateOnlyCommands[message.Command()]
+	}
+	action, ok := menuActionByText(message.Text)
+	return ok && privateOnlyActions[action]
+}
+
+// privateCopy возвращает копию сообщения, адресованную в личный чат автора
+func privateCopy(message *tgbotapi.Message) *tgbotapi.Message {
+	private := *message
+	private.Chat = &tgbotapi.Chat{
+		ID:        message.From.ID,
+		Type:      "private",
+		UserName:  message.From.UserName,
+		FirstName: message.From.FirstName,
+		LastName:  message.From.LastName,
+	}
+	return &private
+}
+
+// routeToPrivateChat выполняет обработчик в личке пользователя и оставляет короткую пометку в группе
+func (h *Handler) routeToPrivateChat(ctx context.Context, message *tgbotapi.Message, user *models.User,
+	handle func(context.Context, *tgbotapi.Message, *models.User) error) error {
+	err := handle(ctx, privateCopy(message), user)
+	if err != nil {
+		// Чаще всего пользователь ещё не начинал диалог с ботом и Telegram запрещает писать первым
+		h.logger.Warn("не удалось ответить в личные сообщения",
+			zap.Error(err),
+			zap.Int64("user_id", user.ID),
+			zap.Int64("group_chat_id", message.Chat.ID))
+		return h.sendMessage(message.Chat.ID, fmt.Sprintf(
+			"✉️ Не могу написать вам в личку. Откройте https://t.me/%s и нажмите «Старт», затем повторите.",
+			h.self.UserName))
+	}
+	return h.sendMessage(message.Chat.ID, "📬 Ответил вам в личные сообщения.")
+}
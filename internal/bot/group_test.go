@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestClassifyChat(t *testing.T) {
+	cases := map[string]chatKind{
+		"private":    chatKindPrivate,
+		"group":      chatKindGroup,
+		"supergroup": chatKindGroup,
+		"channel":    chatKindChannel,
+		"":           chatKindUnknown,
+	}
+	for chatType, want := range cases {
+		if got := classifyChat(&tgbotapi.Chat{Type: chatType}); got != want {
+			t.Errorf("для типа %q ожидалось %v, получено %v", chatType, want, got)
+		}
+	}
+}
+
+func TestIsAddressedToBot(t *testing.T) {
+	const botID = 42
+	const botName = "LinguaBot"
+
+	cases := []struct {
+		name    string
+		message *tgbotapi.Message
+		want    bool
+	}{
+		{
+			name: "упоминание",
+			message: &tgbotapi.Message{
+				Text:     "привет @linguabot как дела",
+				Entities: []tgbotapi.MessageEntity{{Type: "mention", Offset: 7, Length: 10}},
+			},
+			want: true,
+		},
+		{
+			name: "упоминание другого бота",
+			message: &tgbotapi.Message{
+				Text:     "@otherbot hi",
+				Entities: []tgbotapi.MessageEntity{{Type: "mention", Offset: 0, Length: 9}},
+			},
+			want: false,
+		},
+		{
+			name: "ответ на сообщение бота",
+			message: &tgbotapi.Message{
+				Text:           "thanks",
+				ReplyToMessage: &tgbotapi.Message{From: &tgbotapi.User{ID: botID}},
+			},
+			want: true,
+		},
+		{
+			name: "команда с именем бота",
+			message: &tgbotapi.Message{
+				Text:     "/stats@LinguaBot",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 16}},
+			},
+			want: true,
+		},
+		{
+			name: "команда без имени бота",
+			message: &tgbotapi.Message{
+				Text:     "/stats",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 6}},
+			},
+			want: false,
+		},
+		{
+			name:    "обычное сообщение",
+			message: &tgbotapi.Message{Text: "hello everyone"},
+			want:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		if got := isAddressedToBot(tc.message, botID, botName); got != tc.want {
+			t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestStripBotMention(t *testing.T) {
+	got := stripBotMention("@LinguaBot  how are you?", "linguabot")
+	if got != "how are you?" {
+		t.Errorf("ожидалось %q, получено %q", "how are you?", got)
+	}
+}
+
+func TestStripBotMentionKeepsTextWithCaseChangingRunes(t *testing.T) {
+	// У "İ" строчная форма длиннее заглавной, индексы ToLower сместились бы
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"İİİ @LinguaBot привет", "İİİ привет"},
+		{"ȺȺ @LINGUABOT what?", "ȺȺ what?"},
+		{"@linguabot_fan hi", "@linguabot_fan hi"},
+	}
+	for _, tc := range cases {
+		if got := stripBotMention(tc.text, "linguabot"); got != tc.want {
+			t.Errorf("stripBotMention(%q): ожидалось %q, получено %q", tc.text, tc.want, got)
+		}
+	}
+}
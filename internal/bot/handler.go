@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -22,6 +23,7 @@ import (
 	"lingua-ai/internal/tts"
 
 	"lingua-ai/internal/ai"
+	"lingua-ai/internal/challenge"
 	"lingua-ai/internal/flashcards"
 	"lingua-ai/internal/message"
 	"lingua-ai/internal/metrics"
@@ -37,7 +39,7 @@ import (
 const (
 	// Оптимальные значения для истории сообщений
 	ChatHistoryForTranslation  = 5  // Для поиска переводов
-	ChatHistoryForConversation = 10 // Для обычного общения
+	ChatHistoryForConversation = 10 // Для обычного общения, когда контекст диалога пуст
 	ChatHistoryForAudio        = 8  // Для аудио обработки
 
 	// Лимиты безопасности
@@ -48,28 +50,72 @@ const (
 	// Rate limiting
 	MaxRequestsPerMinute = 30 // Максимум запросов в минуту на пользователя
 	RateLimitWindow      = time.Minute
+	// MaxRateLimitedUsers сколько пользователей отслеживается одновременно. Когда карта
+	// заполнена, новый пользователь вытесняет случайного из отслеживаемых
+	MaxRateLimitedUsers = 10000
 )
 
 // RateLimiter простой rate limiter для пользователей
 type RateLimiter struct {
-	requests map[int64][]time.Time
-	mutex    sync.RWMutex
+	requests        map[int64][]time.Time
+	warnedAt        map[int64]time.Time // когда пользователь последний раз получил предупреждение о лимите
+	warningCooldown time.Duration       // не чаще одного предупреждения за этот интервал
+	lastSweep       time.Time           // когда последний раз удалялись неактивные пользователи
+	now             func() time.Time
+	mutex           sync.RWMutex
 }
 
 // NewRateLimiter создает новый rate limiter
 func NewRateLimiter() *RateLimiter {
 	return &RateLimiter{
-		requests: make(map[int64][]time.Time),
+		requests:        make(map[int64][]time.Time),
+		warnedAt:        make(map[int64]time.Time),
+		warningCooldown: RateLimitWindow,
+		now:             time.Now,
 	}
 }
 
+// SetWarningCooldown задает, как часто пользователь может получать предупреждение о лимите
+func (rl *RateLimiter) SetWarningCooldown(cooldown time.Duration) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.warningCooldown = cooldown
+}
+
+// ShouldWarn сообщает, нужно ли предупредить пользователя о превышении лимита.
+// Предупреждение отправляется не чаще раза за интервал, остальные запросы отбрасываются молча.
+func (rl *RateLimiter) ShouldWarn(userID int64) bool {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := rl.now()
+	if last, ok := rl.warnedAt[userID]; ok && now.Sub(last) < rl.warningCooldown {
+		return false
+	}
+
+	// Удаляем устаревшие отметки, чтобы карта не росла
+	for id, last := range rl.warnedAt {
+		if now.Sub(last) >= rl.warningCooldown {
+			delete(rl.warnedAt, id)
+		}
+	}
+	rl.warnedAt[userID] = now
+	return true
+}
+
 // IsAllowed проверяет, разрешен ли запрос для пользователя
 func (rl *RateLimiter) IsAllowed(userID int64) bool {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
-	now := time.Now()
-	userRequests := rl.requests[userID]
+	now := rl.now()
+	if now.Sub(rl.lastSweep) >= RateLimitWindow {
+		rl.sweep(now)
+	}
+	userRequests, tracked := rl.requests[userID]
+	if !tracked && len(rl.requests) >= MaxRateLimitedUsers {
+		rl.evictOne()
+	}
 
 	// Удаляем старые запросы
 	var validRequests []time.Time
@@ -91,9 +137,46 @@ func (rl *RateLimiter) IsAllowed(userID int64) bool {
 	return true
 }
 
+// sweep удаляет пользователей без запросов за последнее окно. Вызывается под mutex
+// не чаще раза за окно, поэтому проход по карте не ложится на каждый запрос.
+func (rl *RateLimiter) sweep(now time.Time) {
+	rl.lastSweep = now
+
+	for id, userRequests := range rl.requests {
+		// Отметки идут по возрастанию: достаточно проверить последнюю
+		if len(userRequests) == 0 || now.Sub(userRequests[len(userRequests)-1]) >= RateLimitWindow {
+			delete(rl.requests, id)
+		}
+	}
+}
+
+// evictOne освобождает место в заполненной карте, удаляя случайного пользователя
+// (порядок обхода карты случаен). Вызывается под mutex.
+func (rl *RateLimiter) evictOne() {
+	for id := range rl.requests {
+		delete(rl.requests, id)
+		return
+	}
+}
+
+// Reset снимает ограничение с пользователя, например если лимит сработал по ошибке
+func (rl *RateLimiter) Reset(userID int64) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	delete(rl.requests, userID)
+	delete(rl.warnedAt, userID)
+}
+
+// trackedUsers возвращает число пользователей, для которых хранятся запросы
+func (rl *RateLimiter) trackedUsers() int {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+	return len(rl.requests)
+}
+
 // Handler представляет обработчик сообщений Telegram
 type Handler struct {
-	bot              *tgbotapi.BotAPI
+	bot              Sender
 	userService      *user.Service
 	messageService   *message.Service
 	aiClient         ai.AIClient
@@ -103,21 +186,68 @@ type Handler struct {
 	logger           *zap.Logger
 	userMetrics      *metrics.Metrics
 	aiMetrics        *metrics.Metrics
-	activeLevelTests map[int64]*models.LevelTest // Хранилище активных тестов
+	activeLevelTests map[int64]*models.LevelTest // Активные тесты в памяти; при levelTestStore — кэш сохраненных в БД
 	prompts          *SystemPrompts
-	dialogContexts   map[int64]*DialogContext // контекст диалога для каждого пользователя
-	premiumService   *premium.Service         // сервис премиум-подписки
-	referralService  *referral.Service        // сервис реферальной системы
-	rateLimiter      *RateLimiter             // rate limiter для защиты от спама
-	flashcardHandler *FlashcardHandler        // обработчик словарных карточек
-	store            store.Store              // хранилище для доступа к payment repo
-	ttsTextCache     map[string]string        // кэш для TTS текстов
-	ttsCacheMutex    sync.RWMutex             // мьютекс для кэша TTS
+	dialogContexts   map[int64]*DialogContext    // контекст диалога для каждого пользователя
+	dialogMutex      sync.Mutex                  // мьютекс для контекстов диалога
+	storedMessages   int                         // сколько сообщений пользователя хранится в БД (0 — без ограничения)
+	premiumService   *premium.Service            // сервис премиум-подписки
+	referralService  *referral.Service           // сервис реферальной системы
+	rateLimiter      *RateLimiter                // rate limiter для защиты от спама
+	flashcardHandler *FlashcardHandler           // обработчик словарных карточек
+	wordPackService  *flashcards.WordPackService // сервис наборов слов недели
+	sender           *SendDispatcher             // диспетчер отправки с учетом лимитов Telegram
+	store            store.Store                 // хранилище для доступа к payment repo
+	ttsTexts         *ttsTextStore               // тексты для кнопок озвучки
+	referralQR       *referralQRCache            // кэш QR-кодов реферальных ссылок
+	activeDictations map[int64]*dictationSession // активные диктанты пользователей
+	dictationMutex   sync.Mutex                  // мьютекс для диктантов
+	phraseChallenge  *challenge.Service          // челлендж «Фраза дня» (nil — выключен)
+	activePhrases    map[int64]*phraseSession    // попытки произнести фразу дня
+	phraseMutex      sync.Mutex                  // мьютекс для попыток фразы дня
+	groupsEnabled    bool                        // отвечать ли в группах на упоминания
+	levelPicker      bool                        // предлагать ли новым пользователям выбрать уровень перед приветствием
+	levelPickerSkip  bool                        // можно ли пропустить выбор уровня и тур
+	corrections      *correctionStore            // контексты исправлений для кнопки «Почему?»
+	premiumFeatures  premium.FeatureGate         // возможности, доступные только по премиуму
+
+	pronunciations map[int64]*pronunciationSession // проверки произношения слов и фраз
+	pronounceMutex sync.Mutex                      // мьютекс для проверок произношения
+
+	dialogMaxMessages int // после скольких сообщений история сворачивается в краткое содержание
+	dialogKeepRecent  int // сколько последних сообщений передается AI дословно
+	xpMinWords        int // минимум слов для полного XP за сообщение на английском (beginner)
+	chatHistoryLimit  int // сколько сообщений истории из БД восстанавливается, когда контекста диалога нет в памяти
+
+	dialogStore       store.DialogContextRepository // сохранение контекста диалога между перезапусками (nil — только в памяти)
+	levelTestStore    store.LevelTestRepository     // сохранение незавершенных тестов уровня (nil — только в памяти)
+	dialogPersistMsgs int                           // сколько последних сообщений диалога сохраняется
+
+	quickRepliesEnabled bool             // предлагать ли варианты ответа кнопками после вопроса бота
+	quickReplyLevels    map[string]bool  // уровни, на которых предлагаются быстрые ответы
+	quickReplies        *quickReplyStore // варианты ответа на последний вопрос бота
+
+	idioms *idiomCache // разборы идиом из /idiom
+
+	quizzes *exerciseQuizzes // упражнения, ожидающие ответа кнопкой
+
+	unsupportedLanguageReply string // ответ на сообщение на третьем языке (пустой — стандартный)
+	offerForeignTranslation  bool   // предлагать ли перевести такое сообщение на изучаемый язык
+
+	learningLanguages []string // языки, которые можно выбрать командой /language
+
+	audioLanguageCheck    string            // реакция на голосовые не на изучаемом языке: off, warn, skip
+	audioLanguageMinWords int               // минимум распознанных слов для проверки языка
+	transcriptions        *transcriptionLog // последние распознавания для /transcribe_debug
+
+	self  tgbotapi.User       // аккаунт бота: ID и username для упоминаний и ссылок
+	files *tgbotapi.BotAPI    // клиент для скачивания файлов (nil — голосовые не обрабатываются)
+	pause func(time.Duration) // пауза перед следующим вопросом теста
 }
 
 // NewHandler создает новый обработчик
 func NewHandler(
-	bot *tgbotapi.BotAPI,
+	bot Sender,
 	userService *user.Service,
 	messageService *message.Service,
 	aiClient ai.AIClient,
@@ -149,18 +279,107 @@ func NewHandler(
 		referralService:  referralService,
 		rateLimiter:      NewRateLimiter(),
 		store:            store,
-		ttsTextCache:     make(map[string]string),
+		ttsTexts:         newTTSTextStore(),
+		referralQR:       newReferralQRCache(referralQRCacheSize),
+		corrections:      newCorrectionStore(),
+		premiumFeatures:  premium.NewFeatureGate(premium.DefaultPremiumFeatures...),
+		activeDictations: make(map[int64]*dictationSession),
+		activePhrases:    make(map[int64]*phraseSession),
+		pronunciations:   make(map[int64]*pronunciationSession),
+
+		dialogMaxMessages: DefaultDialogMaxMessages,
+		dialogKeepRecent:  DefaultDialogKeepRecent,
+		xpMinWords:        DefaultXPMinWords,
+		chatHistoryLimit:  ChatHistoryForConversation,
+
+		dialogPersistMsgs: DefaultDialogPersistMessages,
+
+		quickReplies: newQuickReplyStore(),
+		idioms:       newIdiomCache(),
+		quizzes:      newExerciseQuizzes(),
+
+		offerForeignTranslation: true,
+
+		learningLanguages: []string{models.DefaultLearningLanguage},
+
+		audioLanguageCheck:    AudioLanguageCheckWarn,
+		audioLanguageMinWords: DefaultAudioLanguageMinWords,
+		transcriptions:        newTranscriptionLog(),
 	}
+	handler.self, handler.files = botIdentity(bot)
+	handler.pause = time.Sleep
+
+	// Все отправки идут через диспетчер, чтобы не упираться в flood control
+	handler.sender = NewSendDispatcher(bot, logger)
 
 	// Инициализируем обработчик карточек
-	handler.flashcardHandler = NewFlashcardHandler(bot, flashcardService, logger)
+	handler.flashcardHandler = NewFlashcardHandler(bot, handler.sender, flashcardService, logger)
+	handler.flashcardHandler.onWordLearned = handler.checkWeeklyTarget
+	handler.flashcardHandler.setCategory = handler.userService.SetFlashcardCategory
+	handler.wordPackService = flashcards.NewWordPackService(store.WordPack(), logger)
 
 	return handler
 }
 
+// SetMessageLocale задает локаль чисел и дат в сообщениях бота
+func (h *Handler) SetMessageLocale(locale Locale) {
+	h.messages.SetLocale(locale)
+}
+
+// Dispatcher возвращает диспетчер отправки бота, чтобы фоновые рассылки
+// соблюдали те же лимиты чатов, что и ответы пользователям
+func (h *Handler) Dispatcher() *SendDispatcher {
+	return h.sender
+}
+
+// SetChatHistoryLimit задает, сколько сообщений истории из БД передается AI, когда контекст диалога пуст
+func (h *Handler) SetChatHistoryLimit(limit int) {
+	if limit < 1 {
+		limit = ChatHistoryForConversation
+	}
+	h.chatHistoryLimit = limit
+}
+
+// SetRateLimitWarningCooldown задает, как часто пользователь может получать предупреждение о лимите запросов
+func (h *Handler) SetRateLimitWarningCooldown(cooldown time.Duration) {
+	h.rateLimiter.SetWarningCooldown(cooldown)
+}
+
+// ResetRateLimit снимает ограничение запросов с пользователя Telegram
+func (h *Handler) ResetRateLimit(telegramID int64) {
+	h.rateLimiter.Reset(telegramID)
+	h.logger.Info("лимит запросов пользователя сброшен", zap.Int64("telegram_id", telegramID))
+}
+
 // HandleUpdate обрабатывает входящее обновление
 func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
-	// Получаем ID пользователя для rate limiting
+	if update.Message != nil {
+		switch classifyChat(update.Message.Chat) {
+		case chatKindPrivate:
+		case chatKindGroup:
+			// В группе отвечаем только на обращения к боту
+			if !h.prepareGroupMessage(update.Message) {
+				return nil
+			}
+		case chatKindChannel:
+			return nil
+		default:
+			h.logger.Debug("сообщение из неизвестного типа чата проигнорировано",
+				zap.String("chat_type", update.Message.Chat.Type))
+			return nil
+		}
+
+		// Сообщения от имени каналов и анонимных администраторов не привязаны к пользователю
+		if update.Message.From == nil || update.Message.SenderChat != nil {
+			return nil
+		}
+	} else if update.CallbackQuery == nil {
+		// Прочие типы обновлений (посты каналов, правки) не обрабатываем
+		return nil
+	}
+
+	// Получаем ID пользователя для rate limiting.
+	// Состояние всегда ведется по From.ID, а не по ID чата, чтобы в группах не смешивать пользователей
 	var userID int64
 	if update.Message != nil {
 		userID = update.Message.From.ID
@@ -171,11 +390,11 @@ func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) erro
 	// Проверяем rate limit
 	if userID != 0 && !h.rateLimiter.IsAllowed(userID) {
 		h.logger.Warn("rate limit exceeded", zap.Int64("user_id", userID))
-		// Для обычных сообщений отправляем предупреждение
-		if update.Message != nil {
+		// Для обычных сообщений отправляем предупреждение, но не на каждое заблокированное
+		if update.Message != nil && h.rateLimiter.ShouldWarn(userID) {
 			return h.sendErrorMessage(update.Message.Chat.ID, "⚠️ Слишком много запросов. Подождите минуту.")
 		}
-		// Для callback просто игнорируем
+		// Остальное просто игнорируем
 		return nil
 	}
 
@@ -206,22 +425,40 @@ func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) erro
 		return h.sendErrorMessage(update.Message.Chat.ID, "Ошибка обработки запроса")
 	}
 
+	// Карточки, тесты и меню в группе отправляем в личку, чтобы не спамить
+	if isGroupChat(update.Message) && needsPrivateChat(update.Message) {
+		if update.Message.IsCommand() {
+			return h.routeToPrivateChat(ctx, update.Message, user, h.handleCommand)
+		}
+		return h.routeToPrivateChat(ctx, update.Message, user, h.handleButtonPress)
+	}
+
 	// Обрабатываем команды
 	if update.Message.IsCommand() {
 		return h.handleCommand(ctx, update.Message, user)
 	}
 
-	// Обрабатываем аудио сообщения
-	if update.Message.Voice != nil || update.Message.Audio != nil {
+	// Обрабатываем аудио сообщения, в том числе аудиофайлы, отправленные документом
+	if update.Message.Voice != nil || update.Message.Audio != nil || isAudioDocument(update.Message.Document) {
 		return h.handleAudioMessage(ctx, update.Message, user)
 	}
 
+	// Другие документы не разбираем
+	if update.Message.Document != nil {
+		if isGroupChat(update.Message) {
+			return nil
+		}
+		return h.sendMessage(update.Message.Chat.ID, "📎 Я понимаю только аудиофайлы (mp3, m4a, wav, ogg, opus, flac). Пришли запись или напиши сообщение текстом.")
+	}
+
 	// Обрабатываем кнопки и обычные сообщения
 	return h.handleButtonPress(ctx, update.Message, user)
 }
 
 // handleCommand обрабатывает команды
 func (h *Handler) handleCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	h.endFlashcardSessionBeforeCommand(message, user)
+
 	switch message.Command() {
 	case "start":
 		return h.handleStartCommand(ctx, message, user)
@@ -232,12 +469,50 @@ func (h *Handler) handleCommand(ctx context.Context, message *tgbotapi.Message,
 
 	case "clear":
 		return h.handleClearCommand(ctx, message, user)
+	case "history":
+		return h.handleHistoryCommand(ctx, message, user)
 	case "premium":
 		return h.handlePremiumCommand(ctx, message, user)
 	case "flashcards":
-		return h.flashcardHandler.HandleFlashcardsCommand(ctx, message.Chat.ID, user.ID, user.Level)
+		return h.flashcardHandler.HandleFlashcardsCommand(ctx, message.Chat.ID, user)
+	case "cancel":
+		return h.handleCancelCommand(ctx, message, user)
+	case "review":
+		return h.handleReviewCommand(ctx, message, user)
+	case "transcribe_debug":
+		return h.handleTranscribeDebugCommand(ctx, message, user)
 	case "learning":
 		return h.handleLearningCommand(ctx, message, user)
+	case "gift":
+		return h.handleGiftCommand(ctx, message, user)
+	case "tour":
+		return h.handleTourCommand(ctx, message, user)
+	case "when":
+		return h.flashcardHandler.HandleWhenCommand(ctx, message.Chat.ID, user.ID, message.CommandArguments())
+	case "find":
+		return h.flashcardHandler.HandleFindCommand(ctx, message.Chat.ID, user.ID, message.CommandArguments())
+	case "forget":
+		return h.flashcardHandler.HandleForgetCommand(ctx, message.Chat.ID, user.ID, message.CommandArguments())
+	case "pace":
+		return h.handlePaceCommand(ctx, message, user)
+	case "weeklytarget":
+		return h.handleWeeklyTargetCommand(ctx, message, user)
+	case "streakwarnings":
+		return h.handleStreakWarningsCommand(ctx, message, user)
+	case "reminders":
+		return h.handleRemindersCommand(ctx, message, user)
+	case "timezone":
+		return h.handleTimezoneCommand(ctx, message, user)
+	case "streak":
+		return h.handleStreakCommand(ctx, message, user)
+	case "payments":
+		return h.handlePaymentsCommand(ctx, message, user)
+	case "idiom":
+		return h.handleIdiomCommand(ctx, message, user)
+	case "compare":
+		return h.handleCompareCommand(ctx, message, user)
+	case "language":
+		return h.handleLanguageCommand(ctx, message, user)
 
 	default:
 		return h.sendMessage(message.Chat.ID, h.messages.UnknownCommand())
@@ -295,6 +570,12 @@ func (h *Handler) validateFileSize(size int) bool {
 	return size > 0 && size <= MaxFileSize
 }
 
+// formatAudioDuration форматирует длительность аудио в виде "м:сс"
+func formatAudioDuration(d time.Duration) string {
+	total := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
 // handleCallbackQuery обрабатывает inline кнопки
 func (h *Handler) handleCallbackQuery(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
 	// Получаем пользователя с валидацией
@@ -341,16 +622,15 @@ func (h *Handler) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Ca
 
 	// Обработка карточек
 	case strings.HasPrefix(data, "flashcard_") || data == "flashcard_show_translation":
-		return h.flashcardHandler.HandleFlashcardCallback(ctx, callback, user.ID, user.Level)
+		return h.flashcardHandler.HandleFlashcardCallback(ctx, callback, user)
 
-	case strings.HasPrefix(data, "test_answer_"):
+	case strings.HasPrefix(data, testAnswerCallbackPrefix):
 		// Обрабатываем ответ на вопрос теста
 		h.logger.Info("получен ответ на тест", zap.String("data", data), zap.Int64("user_id", user.ID))
-		answerStr := strings.TrimPrefix(data, "test_answer_")
-		answer, err := strconv.Atoi(answerStr)
-		if err != nil {
-			h.logger.Error("ошибка парсинга ответа теста", zap.Error(err))
-			return err
+		answer, ok := parseTestAnswerCallback(data)
+		if !ok {
+			h.logger.Warn("неверный номер ответа в кнопке теста", zap.String("data", data))
+			return nil
 		}
 		return h.handleLevelTestCallback(ctx, callback, user, answer)
 
@@ -367,28 +647,78 @@ func (h *Handler) handleCallbackQuery(ctx context.Context, callback *tgbotapi.Ca
 		// Оставляем текущий уровень
 		return h.handleKeepCurrentLevelCallback(ctx, callback, user)
 
-	// Обработка главного меню
-	case data == "main_help":
-		return h.handleMainHelpCallback(ctx, callback, user)
+	// Обработка inline-кнопок меню
+	case isMenuCallback(data):
+		action, _ := menuActionByCallback(data)
+		return h.handleMenuAction(ctx, callback.Message, user, action)
+
+	case strings.HasPrefix(data, "wordpack_"):
+		return h.handleWordPackCallback(ctx, callback, user)
+
+	case data == "exercise_easy" || data == "exercise_hard":
+		return h.handleExerciseFeedbackCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, exerciseAnswerCallbackPrefix):
+		return h.handleExerciseAnswerCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, "tour_"):
+		return h.handleOnboardingCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, levelPickerCallbackPrefix):
+		return h.handleLevelPickerCallback(ctx, callback, user)
+
+	case data == foreignTranslateCallback:
+		return h.handleForeignTranslateCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, quickReplyCallbackPrefix):
+		return h.handleQuickReplyCallback(ctx, callback, user)
+	case strings.HasPrefix(data, idiomCallbackPrefix):
+		return h.handleIdiomSaveCallback(ctx, callback, user)
+
+	case data == models.StreakWarningOffCallback || data == models.StreakWarningSnoozeCallback:
+		return h.handleStreakWarningCallback(ctx, callback, user)
+
+	case data == models.DailyReminderOffCallback:
+		return h.handleRemindersOffCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, findPageCallbackPrefix):
+		return h.flashcardHandler.HandleFindPageCallback(ctx, callback, user.ID)
+
+	case strings.HasPrefix(data, leaderboardCallbackPrefix):
+		return h.handleLeaderboardCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, paceCallbackPrefix):
+		return h.handlePaceCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, weeklyTargetCallbackPrefix):
+		return h.handleWeeklyTargetCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, learningLanguageCallbackPrefix):
+		return h.handleLearningLanguageCallback(ctx, callback, user)
+
+	case strings.HasPrefix(data, pronounceCardCallbackPrefix) || data == pronounceOfferCallback:
+		return h.handlePronunciationCallback(ctx, callback, user)
+	case strings.HasPrefix(data, "dictation_"):
+		return h.handleDictationCallback(ctx, callback, user)
 
-	case data == "main_premium":
-		return h.handleMainPremiumCallback(ctx, callback, user)
+	case strings.HasPrefix(data, correctionCallbackPref):
+		return h.handleCorrectionCallback(ctx, callback, user)
 
-	case data == "main_rating":
-		return h.handleMainRatingCallback(ctx, callback, user)
+	case data == "referral_qr":
+		return h.handleReferralQRCallback(ctx, callback, user)
 
-	case data == "learning_menu":
-		return h.handleLearningMenuCallback(ctx, callback, user)
+	case strings.HasPrefix(data, models.WordOfDayTTSCallbackPrefix):
+		return h.handleWordOfDayTTSCallback(ctx, callback, user)
 
-	case data == "main_stats":
-		return h.handleMainStatsCallback(ctx, callback, user)
+	case strings.HasPrefix(data, ttsSlowCallbackPrefix):
+		// Повторная озвучка того же текста в замедленном темпе
+		textID := strings.TrimPrefix(data, ttsSlowCallbackPrefix)
+		return h.handleTTSCallback(ctx, callback, user, textID, tts.SlowSpeed)
 
-	case strings.HasPrefix(data, "tts_"):
-		// Обрабатываем TTS callback
-		encodedText := strings.TrimPrefix(data, "tts_")
-		// Теперь encodedText содержит ID текста, а не сам текст
-		textID := encodedText
-		return h.handleTTSCallback(ctx, callback, user, textID)
+	case strings.HasPrefix(data, ttsCallbackPrefix):
+		// В callback лежит токен текста, сам текст хранится на сервере
+		textID := strings.TrimPrefix(data, ttsCallbackPrefix)
+		return h.handleTTSCallback(ctx, callback, user, textID, tts.NormalSpeed)
 
 	default:
 		h.logger.Warn("неизвестный callback", zap.String("data", data))
@@ -461,53 +791,22 @@ func (h *Handler) handlePremiumPlanSelection(ctx context.Context, chatID int64,
 	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = "HTML"
 
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	return err
 }
 
 // handleButtonPress обрабатывает нажатия кнопок
 func (h *Handler) handleButtonPress(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	text := message.Text
+	if action, ok := menuActionByText(message.Text); ok {
+		return h.handleMenuAction(ctx, message, user, action)
+	}
 
-	switch text {
-	case "📊 Статистика":
-		return h.handleStatsCommand(ctx, message, user)
-	case "❓ Помощь":
-		return h.handleHelpCommand(ctx, message, user)
-	case "🗑 Очистить диалог":
-		return h.handleClearCommand(ctx, message, user)
-	case "🔙 Назад к меню":
-		// Если пользователь в тесте, завершаем его без результатов
-		if user.CurrentState == models.StateInLevelTest {
-			return h.cancelLevelTest(ctx, message, user)
-		}
-		return h.handleStartCommand(ctx, message, user)
-	case "🎯 Тест уровня":
-		return h.handleLevelTestButton(ctx, message, user)
-	case "🎓 Тест уровня":
-		return h.handleLevelTestButton(ctx, message, user)
-	case "🏆 Рейтинг":
-		return h.handleLeaderboardButton(ctx, message, user)
-	case "🎯 Начать тест":
-		return h.handleStartLevelTest(ctx, message, user)
-	case "💎 Премиум":
-		return h.handlePremiumCommand(ctx, message, user)
-	case "📚 Обучение":
-		return h.handleLearningButton(ctx, message, user)
-	case "🔗 Реферальная ссылка":
-		return h.handleReferralButton(ctx, message, user)
-	case "📝 Словарные карточки":
-		return h.flashcardHandler.HandleFlashcardsCommand(ctx, message.Chat.ID, user.ID, user.Level)
-	case "🔙 Назад в главное меню":
-		return h.handleStartCommand(ctx, message, user)
-	default:
-		// Если это не кнопка, а обычное сообщение
-		if message.Text != "" {
-			return h.handleMessage(ctx, message, user)
-		}
-		// Игнорируем неизвестные кнопки
-		return nil
+	// Если это не кнопка, а обычное сообщение
+	if message.Text != "" {
+		return h.handleMessage(ctx, message, user)
 	}
+	// Игнорируем неизвестные кнопки
+	return nil
 }
 
 // addXP добавляет опыт пользователю
@@ -517,8 +816,8 @@ func (h *Handler) addXP(user *models.User, xp int) {
 
 	user.XP += xp
 
-	// Определяем новый уровень на основе XP
-	newLevel := models.GetLevelByXP(user.XP)
+	// Определяем новый уровень на основе XP (уровень из теста не понижается)
+	newLevel := models.PromoteLevelByXP(user.Level, user.XP)
 
 	// Проверяем, повысился ли уровень
 	if oldLevel != newLevel {
@@ -532,6 +831,14 @@ func (h *Handler) addXP(user *models.User, xp int) {
 			zap.String("old_level", oldLevel),
 			zap.String("new_level", newLevel),
 			zap.Int("total_xp", user.XP))
+	} else if levelProgressStep(oldXP) != levelProgressStep(user.XP) {
+		// Показываем прогресс, когда заполняется очередное деление шкалы
+		go h.sendXPProgressNotification(user.TelegramID, xp, user.XP)
+	}
+
+	// Оценившим уровень самостоятельно один раз предлагаем его проверить
+	if shouldSuggestLevelTest(user, oldXP) {
+		go h.sendLevelTestSuggestion(user.TelegramID, user.Level)
 	}
 
 	// Обновляем пользователя в базе данных
@@ -551,6 +858,13 @@ func (h *Handler) addXP(user *models.User, xp int) {
 	}
 }
 
+// sendXPProgressNotification отправляет прогресс до следующего ранга после получения XP
+func (h *Handler) sendXPProgressNotification(chatID int64, gainedXP, totalXP int) {
+	if err := h.sendMessage(chatID, h.messages.XPProgress(gainedXP, totalXP)); err != nil {
+		h.logger.Error("ошибка отправки прогресса XP", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
 // updateUserDataFromDB обновляет данные пользователя из базы данных
 func (h *Handler) updateUserDataFromDB(ctx context.Context, user *models.User) {
 	updatedUser, err := h.userService.GetUserByID(ctx, user.ID)
@@ -584,58 +898,27 @@ func (h *Handler) handleMessageLimit(ctx context.Context, chatID int64, user *mo
 	return h.sendMessage(chatID, limitMessage)
 }
 
-// updateStudyActivity обновляет активность обучения
+// updateStudyActivity отмечает занятие. Вызывается при каждом учебном действии:
+// логика streak целиком в репозитории, повторный вызов в тот же день ничего не меняет.
+// Здесь только обновляем пользователя в памяти.
 func (h *Handler) updateStudyActivity(user *models.User) {
-	// Проверяем, нужно ли обновлять study streak
-	now := time.Now()
-	shouldUpdate := false
-
-	if user.LastStudyDate.IsZero() {
-		// Первое изучение
-		shouldUpdate = true
-	} else {
-		// Проверяем, занимается ли пользователь в тот же день
-		lastStudyDate := user.LastStudyDate.Truncate(24 * time.Hour)
-		currentDate := now.Truncate(24 * time.Hour)
-
-		if lastStudyDate.Before(currentDate) {
-			// Пользователь занимается в новый день
-			shouldUpdate = true
-		}
-		// Если lastStudyDate == currentDate, то пользователь уже занимался сегодня
-	}
-
-	if !shouldUpdate {
-		// Не обновляем, если пользователь уже занимался сегодня
-		return
-	}
-
-	err := h.userService.UpdateStudyActivity(context.Background(), user.ID)
+	streak, err := h.userService.UpdateStudyActivity(context.Background(), user.ID)
 	if err != nil {
 		h.logger.Error("ошибка обновления активности обучения", zap.Error(err))
 		return
 	}
 
-	// Обновляем данные пользователя в памяти
-	updatedUser, err := h.userService.GetUserByID(context.Background(), user.ID)
-	if err != nil {
-		h.logger.Error("ошибка получения обновленного пользователя", zap.Error(err))
-		return
-	}
-
-	if updatedUser != nil {
-		user.StudyStreak = updatedUser.StudyStreak
-		user.LastStudyDate = updatedUser.LastStudyDate
-
-		// Записываем метрику study streak
-		// h.userMetrics.RecordStudyStreak(user.ID, updatedUser.StudyStreak) // TODO: добавить метрику
-	}
+	user.StudyStreak = streak
+	user.LastStudyDate = time.Now()
 }
 
 // handleMessage обрабатывает обычные сообщения
 func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	// Тест уровня и диктант проходят только в личке, в группе отвечаем как обычный собеседник
+	inPrivate := !isGroupChat(message)
+
 	// Проверяем, находится ли пользователь в тесте уровня
-	if user.CurrentState == models.StateInLevelTest {
+	if inPrivate && user.CurrentState == models.StateInLevelTest {
 		// Проверяем, не хочет ли пользователь отменить тест
 		if message.Text == "❌ Отменить тест" {
 			return h.cancelLevelTest(ctx, message, user)
@@ -644,16 +927,26 @@ func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message,
 		return h.handleLevelTestAnswer(ctx, message, user)
 	}
 
-	// Активируем реферал если пользователь был приглашен и отправляет первое сообщение
+	// Ответ на активный диктант
+	if inPrivate && h.hasActiveDictation(user.ID) {
+		return h.handleDictationAnswer(ctx, message, user)
+	}
+
+	// Во время сессии карточек текст не уходит в диалог: предлагаем продолжить или завершить
+	if inPrivate && h.flashcardHandler.hasActiveSession(user.ID) {
+		return h.flashcardHandler.sendActiveSessionPrompt(message.Chat.ID)
+	}
+
+	// Засчитываем реферал, когда приглашенный пользователь проявил достаточную активность
 	if user.ReferredBy != nil {
-		err := h.referralService.ActivateReferral(ctx, user.ID)
+		activated, err := h.referralService.ActivateReferral(ctx, user.ID)
 		if err != nil {
 			h.logger.Error("ошибка активации реферала",
 				zap.Error(err),
 				zap.Int64("user_id", user.ID),
 				zap.Int64("referred_by", *user.ReferredBy))
 			// Не возвращаем ошибку, продолжаем обработку сообщения
-		} else {
+		} else if activated {
 			h.logger.Info("реферал активирован",
 				zap.Int64("user_id", user.ID),
 				zap.Int64("referred_by", *user.ReferredBy))
@@ -663,6 +956,11 @@ func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message,
 	// Записываем метрику сообщения пользователя
 	h.userMetrics.RecordUserMessage("text")
 
+	// Без AI не на что ответить: предлагаем занятия, которым он не нужен
+	if !h.aiAvailable() {
+		return h.sendOfflineMode(ctx, message.Chat.ID, user)
+	}
+
 	// Сохраняем сообщение пользователя с санитизацией
 	sanitizedText := h.sanitizeText(message.Text)
 	_, err := h.messageService.SaveUserMessage(ctx, user.ID, sanitizedText)
@@ -671,32 +969,17 @@ func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message,
 		return h.sendErrorMessage(message.Chat.ID, "Ошибка сохранения сообщения")
 	}
 
-	// Проверяем, на английском ли сообщение
-	if h.isEnglishMessage(message.Text) {
+	if isTargetLanguage(message.Text, user.LearningLanguage) {
 		return h.handleEnglishMessage(ctx, message, user)
 	}
-
-	// Если сообщение на русском, переводим в режим общения
-	return h.handleRussianMessage(ctx, message, user)
-}
-
-// isEnglishMessage проверяет, написано ли сообщение на английском
-func (h *Handler) isEnglishMessage(text string) bool {
-	// Простая проверка: если в тексте больше латинских букв, чем кириллических
-	englishChars := 0
-	russianChars := 0
-
-	for _, char := range strings.ToLower(text) {
-		if char >= 'a' && char <= 'z' {
-			englishChars++
-		} else if (char >= 'а' && char <= 'я') || char == 'ё' {
-			russianChars++
-		}
+	switch detectLanguage(message.Text) {
+	case langRussian, langUnknown:
+		// Если сообщение на русском, переводим в режим общения
+		return h.handleRussianMessage(ctx, message, user)
 	}
 
-	result := englishChars > russianChars && englishChars > 0
-	h.logger.Info("🔍 isEnglishMessage", zap.String("text", text), zap.Int("english_chars", englishChars), zap.Int("russian_chars", russianChars), zap.Bool("is_english", result))
-	return result
+	// Другой язык: объясняем, какому языку учит бот
+	return h.handleUnsupportedLanguage(ctx, message, user)
 }
 
 // handleEnglishMessage обрабатывает сообщения на английском языке
@@ -714,84 +997,106 @@ func (h *Handler) handleEnglishMessage(ctx context.Context, message *tgbotapi.Me
 		return h.handleMessageLimit(ctx, message.Chat.ID, user)
 	}
 
-	// Получаем историю диалога для контекста (пока не используется)
-	_, err = h.messageService.GetChatHistory(ctx, user.ID, ChatHistoryForConversation)
-	if err != nil {
-		h.logger.Error("ошибка получения истории диалога", zap.Error(err))
-		// Продолжаем без контекста
-	}
-
 	// Получаем или создаем контекст диалога
-	dialogContext := h.getOrCreateDialogContext(user.ID, user.Level)
+	dialogContext := h.getOrCreateDialogContext(ctx, user.ID, user.Level, user.LearningLanguage)
 
 	// Добавляем сообщение пользователя в контекст
 	dialogContext.AddUserMessage(message.Text)
 
-	// Создаем AI сообщения с контекстом диалога
-	var aiMessages []ai.Message
-
-	// Системный промпт для английских сообщений (отправляется только один раз)
-	aiMessages = append(aiMessages, ai.Message{
-		Role:    "system",
-		Content: h.prompts.GetEnglishMessagePrompt(user.Level),
-	})
-
-	// Добавляем текущее сообщение пользователя
-	aiMessages = append(aiMessages, ai.Message{
-		Role:    "user",
-		Content: message.Text,
-	})
-
-	start := time.Now()
+	// Системный промпт для английских сообщений
+	systemPrompt := h.prompts.GetEnglishMessagePrompt(user.Level, user.LearningLanguage)
+	requestType := "english_with_translation"
 	options := ai.GenerationOptions{
 		Temperature: 0.7,
 		MaxTokens:   500,
 	}
-	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, options)
+
+	// Длинные тексты подробно разбираем как эссе, если возможность доступна
+	if isEssay(message.Text) && h.featureEnabled(user, premium.FeatureEssayReview) {
+		systemPrompt = h.prompts.GetEssayReviewPrompt(user.Level, user.LearningLanguage)
+		requestType = "essay_review"
+		options.MaxTokens = 1200
+	} else {
+		systemPrompt = h.withQuickReplies(systemPrompt, message, user)
+	}
+
+	// Краткое содержание старой части разговора и последние сообщения, включая текущее
+	summary, recent := dialogContext.Snapshot()
+	aiMessages := dialogAIMessages(systemPrompt, summary, recent)
+	aiMessages = h.applyReplyFocus(message, aiMessages, recent)
+
+	start := time.Now()
+	response, draftID, err := h.generateConversationReply(ctx, message.Chat.ID, aiMessages, options)
 	duration := time.Since(start)
 
-	h.aiMetrics.RecordAIRequest("english_with_translation", err == nil, duration.Seconds())
+	h.aiMetrics.RecordAIRequest(requestType, err == nil, duration.Seconds())
 
 	if err != nil {
 		h.logger.Error("ошибка генерации ответа с переводом", zap.Error(err))
 		return h.sendErrorMessage(message.Chat.ID, "Произошла ошибка при генерации ответа")
 	}
 
+	// Варианты быстрых ответов приходят отдельной строкой в конце ответа
+	var quickReplies []string
+	response.Content, quickReplies = extractQuickReplies(response.Content)
+
+	// Приводим ответ к ожидаемому формату, если модель его нарушила
+	if !hasExpectedFormat(response.Content) {
+		h.logger.Warn("ответ AI не соответствует формату", zap.Int64("user_id", user.ID))
+	}
+	response.Content = postProcessText(response.Content, aiReplyOptions)
+
 	// Сохраняем ответ ассистента (только английская часть, без перевода)
-	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, response.Content)
+	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, postProcessText(response.Content, historyOptions))
 	if err != nil {
 		h.logger.Error("ошибка сохранения ответа", zap.Error(err))
 	}
 
 	// Добавляем ответ ассистента в контекст диалога
 	dialogContext.AddAssistantMessage(response.Content)
+	h.maybeSummarizeDialog(dialogContext)
+	h.saveDialogContext(ctx, dialogContext)
 
 	// Увеличиваем счетчик сообщений пользователя
 	if err := h.premiumService.IncrementMessageCount(ctx, user.ID); err != nil {
 		h.logger.Error("ошибка увеличения счетчика сообщений", zap.Error(err))
 	}
 
-	// Даем XP за любое общение на английском
-	xp := 15 // Все получают максимум - главное общение
+	// Полный XP за развернутое сообщение или ответ на вопрос, за "ok" — меньше
+	xp, short := englishMessageReward(message.Text, user.Level, h.xpMinWords,
+		answersBotQuestion(message, h.self.ID, recent))
 
 	// Добавляем XP и обновляем активность
 	h.addXP(user, xp)
-	h.updateStudyActivity(user) // Обновляем study streak только раз в день
+	h.updateStudyActivity(user)
 	h.userMetrics.RecordXP(user.ID, xp, "english_message")
 
-	return h.sendMessageWithTTS(message.Chat.ID, h.cleanAIResponse(response.Content))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if row := h.rememberCorrection(message, user, response.Content); row != nil {
+		rows = append(rows, row)
+	}
+	rows = append(rows, h.quickReplyRows(user.ID, quickReplies)...)
+	if err := h.sendReplyWithTTS(message.Chat.ID, draftID, user, response.Content, rows...); err != nil {
+		return err
+	}
+
+	if short && dialogContext.takeShortHint(time.Now()) {
+		return h.sendMessage(message.Chat.ID, h.messages.ShortMessageHint(englishMessageXP))
+	}
+	return nil
 }
 
 // handleRussianMessage обрабатывает сообщения на русском языке
 func (h *Handler) handleRussianMessage(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
 	// Проверяем, просит ли пользователь перевод
 	lowerText := strings.ToLower(message.Text)
-	// Проверяем, просит ли пользователь задание
-	if strings.Contains(lowerText, "задание") ||
+	// Проверяем, просит ли пользователь задание. Ответ на конкретное сообщение
+	// ("дай мне пример с этим словом") — это вопрос о цитате, а не запрос упражнения.
+	if message.ReplyToMessage == nil && (strings.Contains(lowerText, "задание") ||
 		strings.Contains(lowerText, "упражнение") ||
 		strings.Contains(lowerText, "урок") ||
 		strings.Contains(lowerText, "дай мне") ||
-		strings.Contains(lowerText, "exercise") {
+		strings.Contains(lowerText, "exercise")) {
 		return h.handleExerciseRequest(ctx, message, user)
 	}
 
@@ -806,64 +1111,26 @@ func (h *Handler) handleRussianMessage(ctx context.Context, message *tgbotapi.Me
 		return h.handleMessageLimit(ctx, message.Chat.ID, user)
 	}
 
-	// Получаем историю диалога для контекста (пока не используется)
-	_, err = h.messageService.GetChatHistory(ctx, user.ID, ChatHistoryForConversation)
-	if err != nil {
-		h.logger.Error("ошибка получения истории диалога", zap.Error(err))
-		// Продолжаем без контекста
-	}
-
 	// Получаем или создаем контекст диалога
-	dialogContext := h.getOrCreateDialogContext(user.ID, user.Level)
+	dialogContext := h.getOrCreateDialogContext(ctx, user.ID, user.Level, user.LearningLanguage)
 
 	// Добавляем сообщение пользователя в контекст
 	dialogContext.AddUserMessage(message.Text)
 
-	// Получаем историю диалога для контекста
-	history, err := h.messageService.GetChatHistory(ctx, user.ID, 10) // Последние 10 сообщений
-	if err != nil {
-		h.logger.Error("ошибка получения истории диалога", zap.Error(err))
-		// Продолжаем без контекста
-	}
-
-	// Создаем AI сообщения с контекстом диалога
-	var aiMessages []ai.Message
-
 	// Системный промпт для русских сообщений
-	aiMessages = append(aiMessages, ai.Message{
-		Role:    "system",
-		Content: h.prompts.GetRussianMessagePrompt(user.Level),
-	})
+	systemPrompt := h.withQuickReplies(h.prompts.GetRussianMessagePrompt(user.Level, user.LearningLanguage), message, user)
 
-	// Добавляем историю диалога для контекста
-	if history != nil && len(history.Messages) > 1 {
-		// Берем последние 8 сообщений (исключая текущее)
-		start := 0
-		if len(history.Messages) > 8 {
-			start = len(history.Messages) - 8
-		}
-
-		for i := start; i < len(history.Messages)-1; i++ { // -1 чтобы исключить текущее сообщение
-			msg := history.Messages[i]
-			aiMessages = append(aiMessages, ai.Message{
-				Role:    msg.Role,
-				Content: msg.Content,
-			})
-		}
-	}
-
-	// Добавляем текущее сообщение пользователя
-	aiMessages = append(aiMessages, ai.Message{
-		Role:    "user",
-		Content: message.Text,
-	})
+	// Краткое содержание старой части разговора и последние сообщения, включая текущее
+	summary, recent := dialogContext.Snapshot()
+	aiMessages := dialogAIMessages(systemPrompt, summary, recent)
+	aiMessages = h.applyReplyFocus(message, aiMessages, recent)
 
 	start := time.Now()
 	options := ai.GenerationOptions{
 		Temperature: 0.7,
 		MaxTokens:   500,
 	}
-	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, options)
+	response, draftID, err := h.generateConversationReply(ctx, message.Chat.ID, aiMessages, options)
 	duration := time.Since(start)
 
 	h.aiMetrics.RecordAIRequest("russian_with_translation", err == nil, duration.Seconds())
@@ -873,8 +1140,18 @@ func (h *Handler) handleRussianMessage(ctx context.Context, message *tgbotapi.Me
 		return h.sendMessage(message.Chat.ID, "Let's try chatting in English! 🇬🇧\n\n<tg-spoiler>🇷🇺 Давай попробуем общаться на английском!</tg-spoiler>")
 	}
 
+	// Варианты быстрых ответов приходят отдельной строкой в конце ответа
+	var quickReplies []string
+	response.Content, quickReplies = extractQuickReplies(response.Content)
+
+	// Приводим ответ к ожидаемому формату, если модель его нарушила
+	if !hasExpectedFormat(response.Content) {
+		h.logger.Warn("ответ AI не соответствует формату", zap.Int64("user_id", user.ID))
+	}
+	response.Content = postProcessText(response.Content, aiReplyOptions)
+
 	// Извлекаем только английскую часть для сохранения в БД
-	englishOnly := h.extractEnglishFromResponse(response.Content)
+	englishOnly := postProcessText(response.Content, historyOptions)
 
 	// Сохраняем ответ ассистента (только английская часть)
 	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, englishOnly)
@@ -884,6 +1161,8 @@ func (h *Handler) handleRussianMessage(ctx context.Context, message *tgbotapi.Me
 
 	// Добавляем ответ ассистента в контекст диалога
 	dialogContext.AddAssistantMessage(response.Content)
+	h.maybeSummarizeDialog(dialogContext)
+	h.saveDialogContext(ctx, dialogContext)
 
 	// Увеличиваем счетчик сообщений пользователя
 	if err := h.premiumService.IncrementMessageCount(ctx, user.ID); err != nil {
@@ -892,10 +1171,10 @@ func (h *Handler) handleRussianMessage(ctx context.Context, message *tgbotapi.Me
 
 	// Небольшой XP за участие
 	h.addXP(user, 3)
-	h.updateStudyActivity(user) // Обновляем study streak только раз в день
+	h.updateStudyActivity(user)
 	h.userMetrics.RecordXP(user.ID, 3, "russian_message")
 
-	return h.sendMessageWithTTS(message.Chat.ID, response.Content)
+	return h.sendReplyWithTTS(message.Chat.ID, draftID, user, response.Content, h.quickReplyRows(user.ID, quickReplies)...)
 }
 
 // handleExerciseRequest обрабатывает запросы на упражнения/задания
@@ -908,7 +1187,7 @@ func (h *Handler) handleExerciseRequest(ctx context.Context, message *tgbotapi.M
 	}
 
 	// Генерируем быстрое упражнение в зависимости от уровня с учетом истории
-	exercisePrompt := h.prompts.GetExercisePromptWithHistory(user.Level, recentHistory)
+	exercisePrompt := h.prompts.GetExercisePromptWithHistory(user.Level, user.LearningLanguage, recentHistory, user.ExerciseDifficultyBias)
 
 	aiMessages := []ai.Message{
 		{Role: "user", Content: exercisePrompt},
@@ -924,37 +1203,57 @@ func (h *Handler) handleExerciseRequest(ctx context.Context, message *tgbotapi.M
 
 	h.aiMetrics.RecordAIRequest("exercise_generation", err == nil, duration.Seconds())
 
+	quiz := fallbackExerciseQuiz
 	if err != nil {
 		h.logger.Error("ошибка генерации упражнения", zap.Error(err))
-		return h.sendMessage(message.Chat.ID, fmt.Sprintf(`Exercise: Choose the correct form of the verb
-Question: She _____ to work every day.
-Options: go/goes/going
-
-<tg-spoiler>🇷🇺 Выбери правильную форму глагола: Она ... на работу каждый день</tg-spoiler>
+	} else if parsed, parseErr := parseExerciseQuiz(response.Content); parseErr != nil {
+		h.logger.Warn("AI вернул упражнение не по формату", zap.Error(parseErr), zap.Int64("user_id", user.ID))
+	} else {
+		quiz = parsed
+	}
 
-*Уровень: %s*`, h.getLevelText(user.Level)))
+	// Сохраняем упражнение в историю на английском, чтобы AI не повторялся
+	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, exerciseQuizHistory(quiz))
+	if err != nil {
+		h.logger.Error("ошибка сохранения упражнения", zap.Error(err))
 	}
 
-	// Извлекаем только английскую часть для сохранения в БД
-	englishOnly := h.extractEnglishFromResponse(response.Content)
+	// Опыт начисляется только за верный ответ, здесь лишь отмечаем занятие
+	h.updateStudyActivity(user)
 
-	// Сохраняем ответ ассистента
-	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, englishOnly)
+	return h.sendExerciseQuiz(message.Chat.ID, user, quiz)
+}
+
+// handleExerciseFeedbackCallback обрабатывает отзыв о сложности упражнения
+func (h *Handler) handleExerciseFeedbackCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	delta := 1 // exercise_easy: следующие упражнения сложнее
+	if callback.Data == "exercise_hard" {
+		delta = -1
+	}
+
+	bias, err := h.userService.AdjustExerciseDifficultyBias(ctx, user.ID, delta)
 	if err != nil {
-		h.logger.Error("ошибка сохранения упражнения", zap.Error(err))
+		h.logger.Error("ошибка сохранения отзыва об упражнении", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(callback.Message.Chat.ID, "Не удалось сохранить отзыв")
 	}
 
-	// Даем XP за запрос упражнения
-	h.addXP(user, 5)
-	h.updateStudyActivity(user) // Обновляем study streak только раз в день
-	h.userMetrics.RecordXP(user.ID, 5, "exercise_request")
+	var text string
+	switch {
+	case delta > 0 && bias == models.MaxExerciseDifficultyBias:
+		text = "💪 Понял! Упражнения уже на максимальной сложности для вашего уровня."
+	case delta > 0:
+		text = "💪 Понял! Следующие упражнения будут сложнее."
+	case bias == models.MinExerciseDifficultyBias:
+		text = "🙂 Понял! Упражнения уже максимально упрощены для вашего уровня."
+	default:
+		text = "🙂 Понял! Следующие упражнения будут проще."
+	}
 
-	return h.sendMessageWithTTS(message.Chat.ID, response.Content)
+	return h.sendMessage(callback.Message.Chat.ID, text)
 }
 
 // handleStartCommand обрабатывает команду /start
 func (h *Handler) handleStartCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	// Обновляем study streak только раз в день
 	h.updateStudyActivity(user)
 
 	// Проверяем реферальные параметры
@@ -988,15 +1287,38 @@ func (h *Handler) handleStartCommand(ctx context.Context, message *tgbotapi.Mess
 				}
 			}
 		}
+
+		// Ссылка из предупреждения о серии сразу открывает короткое упражнение
+		if args == models.QuickStudyStartParam {
+			return h.handleExerciseRequest(ctx, message, user)
+		}
 	}
 
+	// Новым пользователям сначала предлагаем выбрать уровень, приветствие придет после выбора
+	if message.IsCommand() && h.levelPicker && needsLevelPicker(user) {
+		return h.sendLevelPicker(message.Chat.ID, user.FirstName)
+	}
+
+	// Новым пользователям автоматически показываем тур (только по команде /start)
+	return h.sendWelcome(message.Chat.ID, user, message.IsCommand())
+}
+
+// sendWelcome отправляет приветствие с главным меню и при необходимости запускает тур
+func (h *Handler) sendWelcome(chatID int64, user *models.User, allowOnboarding bool) error {
 	welcomeText := h.messages.Welcome(user.FirstName, h.getLevelText(user.Level), user.XP)
-	return h.sendMessageWithKeyboard(message.Chat.ID, welcomeText, h.messages.GetMainKeyboard())
+	if err := h.sendMessageWithKeyboard(chatID, welcomeText, h.messages.GetMainKeyboard()); err != nil {
+		return err
+	}
+
+	if allowOnboarding && shouldAutoStartOnboarding(user) {
+		return h.startOnboarding(chatID)
+	}
+
+	return nil
 }
 
 // handleHelpCommand обрабатывает команду /help
 func (h *Handler) handleHelpCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	// Обновляем study streak только раз в день
 	h.updateStudyActivity(user)
 
 	return h.sendMessage(message.Chat.ID, h.messages.Help())
@@ -1004,7 +1326,6 @@ func (h *Handler) handleHelpCommand(ctx context.Context, message *tgbotapi.Messa
 
 // handleStatsCommand обрабатывает команду /stats
 func (h *Handler) handleStatsCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	// Обновляем study streak только раз в день
 	h.updateStudyActivity(user)
 
 	stats, err := h.userService.GetUserStats(ctx, user.ID)
@@ -1018,8 +1339,13 @@ func (h *Handler) handleStatsCommand(ctx context.Context, message *tgbotapi.Mess
 		h.getLevelText(user.Level),
 		user.XP,
 		stats.StudyStreak,
-		stats.LastStudyDate.Format(time.DateTime),
+		stats.LastStudyDate,
 	)
+	if stats.ExerciseAnswers > 0 {
+		statsText += fmt.Sprintf("\n🧩 Упражнения: %s из %s верно (%s%%)",
+			h.messages.Number(stats.ExerciseCorrect), h.messages.Number(stats.ExerciseAnswers),
+			h.messages.Number(stats.ExerciseCorrect*100/stats.ExerciseAnswers))
+	}
 
 	return h.sendMessage(message.Chat.ID, statsText)
 }
@@ -1037,7 +1363,10 @@ func (h *Handler) handleClearCommand(ctx context.Context, message *tgbotapi.Mess
 	user.CurrentState = models.StateIdle
 
 	// Удаляем активный тест уровня, если есть
-	delete(h.activeLevelTests, user.ID)
+	h.finishLevelTest(ctx, user.ID)
+
+	// Забываем контекст диалога вместе с кратким содержанием, в том числе сохраненный
+	h.forgetDialogContext(ctx, user.ID)
 
 	// Обновляем пользователя в базе данных
 	currentState := models.StateIdle
@@ -1054,6 +1383,70 @@ func (h *Handler) handleClearCommand(ctx context.Context, message *tgbotapi.Mess
 		h.messages.GetMainKeyboard())
 }
 
+// handleGiftCommand обрабатывает команду /gift <реферальный_код|@username>
+func (h *Handler) handleGiftCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		return h.sendMessage(message.Chat.ID, fmt.Sprintf(`🎁 <b>Подарить премиум</b>
+
+Использование: /gift &lt;реферальный код или @username&gt;
+
+Получатель получит премиум на %d дней. Дарить можно не более %d раз за %d дней.`,
+			premium.GiftDurationDays, premium.MaxGiftsPerPeriod, premium.GiftLimitPeriodDays))
+	}
+
+	recipient, err := h.findGiftRecipient(ctx, arg)
+	if errors.Is(err, store.ErrUserNotFound) {
+		h.logger.Info("получатель подарка не найден", zap.String("arg", arg))
+		return h.sendMessage(message.Chat.ID, "❌ Пользователь не найден. Проверьте реферальный код или username.")
+	}
+	if err != nil {
+		h.logger.Error("ошибка поиска получателя подарка", zap.String("arg", arg), zap.Error(err))
+		return h.sendErrorMessage(message.Chat.ID, "Не удалось найти получателя")
+	}
+
+	if _, err := h.premiumService.GiftPremium(ctx, user.ID, recipient.ID); err != nil {
+		switch {
+		case errors.Is(err, premium.ErrSelfGift):
+			return h.sendMessage(message.Chat.ID, "😊 Нельзя подарить премиум самому себе.")
+		case errors.Is(err, premium.ErrGifterNotPremium):
+			return h.sendMessage(message.Chat.ID, "💎 Дарить премиум могут только пользователи с активной подпиской. Подробнее: /premium")
+		case errors.Is(err, premium.ErrGiftLimitExceeded):
+			return h.sendMessage(message.Chat.ID, fmt.Sprintf("⏳ Можно дарить не более %d подарков за %d дней.",
+				premium.MaxGiftsPerPeriod, premium.GiftLimitPeriodDays))
+		}
+		h.logger.Error("ошибка подарка премиума", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(message.Chat.ID, "Не удалось подарить премиум")
+	}
+
+	gifterName := user.FirstName
+	if user.Username != "" {
+		gifterName = fmt.Sprintf("%s (@%s)", user.FirstName, user.Username)
+	}
+	notification := fmt.Sprintf("🎁 <b>Вам подарили премиум!</b>\n\n%s дарит вам премиум-подписку на %d дней. Приятного обучения!",
+		gifterName, premium.GiftDurationDays)
+	if err := h.sendMessage(recipient.TelegramID, notification); err != nil {
+		h.logger.Warn("не удалось уведомить получателя подарка", zap.Error(err), zap.Int64("recipient_id", recipient.ID))
+	}
+
+	return h.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Вы подарили премиум на %d дней пользователю %s!",
+		premium.GiftDurationDays, recipient.FirstName))
+}
+
+// findGiftRecipient ищет получателя подарка по реферальному коду или username
+func (h *Handler) findGiftRecipient(ctx context.Context, arg string) (*models.User, error) {
+	if !strings.HasPrefix(arg, "@") {
+		recipient, err := h.store.Referral().GetUserByReferralCode(ctx, strings.ToUpper(arg))
+		if err == nil {
+			return recipient, nil
+		}
+		if !errors.Is(err, store.ErrUserNotFound) {
+			return nil, err
+		}
+	}
+	return h.userService.GetUserByUsername(ctx, arg)
+}
+
 // handlePremiumCommand обрабатывает команду премиум-подписки
 func (h *Handler) handlePremiumCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
 	// Получаем статистику пользователя
@@ -1084,11 +1477,9 @@ func (h *Handler) handlePremiumCommand(ctx context.Context, message *tgbotapi.Me
 	// Формируем сообщение
 	var messageText string
 	if stats["is_premium"].(bool) {
-		var expiresAt string
-		if stats["premium_expires_at"] != nil {
-			expiresAt = stats["premium_expires_at"].(string)
-		} else {
-			expiresAt = "неизвестно"
+		expiresAt := "неизвестно"
+		if t, ok := stats["premium_expires_at"].(time.Time); ok {
+			expiresAt = h.messages.Date(t.In(user.Location(h.premiumService.ResetLocation())))
 		}
 
 		messageText = fmt.Sprintf(`🌟 <b>Премиум-подписка активна!</b>
@@ -1125,7 +1516,7 @@ func (h *Handler) handlePremiumCommand(ctx context.Context, message *tgbotapi.Me
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = inlineKeyboard
 
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	return err
 }
 
@@ -1146,8 +1537,9 @@ func (h *Handler) handleLevelTestButton(ctx context.Context, message *tgbotapi.M
 func (h *Handler) handleStartLevelTest(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
 	// Проверяем, проходил ли пользователь тест сегодня
 	today := time.Now().Format("2006-01-02")
-	if user.LastTestDate != nil && user.LastTestDate.Format("2006-01-02") == today {
-		return h.sendMessage(message.Chat.ID, `❌ <b>Тест уже пройден сегодня!</b>
+	if user.LastTestDate != nil && user.LastTestDate.Format("2006-01-02") == today &&
+		!h.featureEnabled(user, premium.FeatureExtraTestAttempts) {
+		text := `❌ <b>Тест уже пройден сегодня!</b>
 
 🕐 <b>Ограничение:</b> Тест уровня можно проходить только <b>один раз в день</b>
 
@@ -1157,12 +1549,19 @@ func (h *Handler) handleStartLevelTest(ctx context.Context, message *tgbotapi.Me
 🎯 <b>Команды для практики:</b>
 • Напиши мне на английском
 • Попроси <b>"дай задание"</b> для упражнений
-• Используй <b>/stats</b> для просмотра прогресса`)
+• Используй <b>/stats</b> для просмотра прогресса`
+		if h.premiumFeatures.PremiumOnly(premium.FeatureExtraTestAttempts) {
+			text += "\n\n" + premiumFeatureHint(premium.FeatureExtraTestAttempts)
+		}
+		return h.sendMessage(message.Chat.ID, text)
 	}
 
 	// Создаем новый тест
 	levelTest := h.generateLevelTest(user.ID)
-	h.activeLevelTests[user.ID] = levelTest
+	if err := h.startLevelTestSession(ctx, levelTest); err != nil {
+		h.logger.Error("ошибка сохранения теста уровня", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(message.Chat.ID, "Ошибка запуска теста")
+	}
 
 	// Обновляем состояние пользователя
 	newState := models.StateInLevelTest
@@ -1183,7 +1582,7 @@ func (h *Handler) handleStartLevelTest(ctx context.Context, message *tgbotapi.Me
 
 // showCurrentQuestion показывает текущий вопрос теста
 func (h *Handler) showCurrentQuestion(ctx context.Context, chatID int64, user *models.User) error {
-	levelTest, exists := h.activeLevelTests[user.ID]
+	levelTest, exists := h.activeLevelTest(ctx, user.ID)
 	if !exists {
 		return h.sendErrorMessage(chatID, "Тест не найден. Начните новый тест.")
 	}
@@ -1224,7 +1623,7 @@ func (h *Handler) showCurrentQuestion(ctx context.Context, chatID int64, user *m
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	if err != nil {
 		h.logger.Error("ошибка отправки вопроса с клавиатурой", zap.Error(err))
 	}
@@ -1233,7 +1632,7 @@ func (h *Handler) showCurrentQuestion(ctx context.Context, chatID int64, user *m
 
 // completeLevelTest завершает тест и показывает результаты
 func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *models.User) error {
-	levelTest, exists := h.activeLevelTests[user.ID]
+	levelTest, exists := h.activeLevelTest(ctx, user.ID)
 	if !exists {
 		return h.sendErrorMessage(chatID, "Тест не найден.")
 	}
@@ -1245,6 +1644,9 @@ func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *mod
 	// Определяем рекомендуемый уровень на основе теста
 	recommendedLevel, levelDescription := h.calculateLevel(levelTest.Score, levelTest.MaxScore)
 
+	// Тест вместо выбора уровня при первом запуске: уровень сразу применяется по результату
+	firstRun := h.levelPicker && needsLevelPicker(user) && h.completeFirstRunLevelTest(ctx, user, recommendedLevel)
+
 	// Сбрасываем состояние пользователя и записываем дату прохождения теста
 	newState := models.StateIdle
 	updateReq := &models.UpdateUserRequest{
@@ -1265,9 +1667,6 @@ func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *mod
 	h.addXP(user, xp)
 	h.userMetrics.RecordXP(user.ID, xp, "level_test_completed")
 
-	// Обновляем локальный XP для отображения
-	user.XP += xp
-
 	correctAnswer := 0
 	for _, level := range levelTest.Answers {
 		if level.IsCorrect {
@@ -1279,7 +1678,9 @@ func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *mod
 
 	var recommendationText string
 
-	if recommendedLevel != user.Level {
+	if firstRun {
+		recommendationText = fmt.Sprintf("\n\n✅ <b>Уровень сохранен:</b> %s\n💡 Задания, карточки и ответы уже подстроены под него.", h.getLevelText(user.Level))
+	} else if recommendedLevel != user.Level {
 		recommendationText = fmt.Sprintf("\n\n🎯 <b>Рекомендация:</b> По результатам теста твой уровень - <b>%s</b>\n💡 Хочешь переключиться на этот уровень для более подходящих заданий?", h.getLevelText(recommendedLevel))
 	} else {
 		recommendationText = "\n\n✅ <b>Отлично!</b> Результаты теста соответствуют твоему текущему уровню."
@@ -1295,7 +1696,8 @@ func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *mod
 📝 <b>%s</b>
 
 ⭐ <b>Получено XP:</b> +%d
-💰 <b>Общий XP:</b> %d%s
+💰 <b>Общий XP:</b> %d
+%s%s
 
 🎯 Продолжай общаться на английском, чтобы повышать свой уровень!`,
 		correctAnswer,
@@ -1305,10 +1707,19 @@ func (h *Handler) completeLevelTest(ctx context.Context, chatID int64, user *mod
 		levelDescription,
 		xp,
 		user.XP,
+		h.messages.LevelProgress(user.XP),
 		recommendationText)
 
 	// Удаляем тест из активных
-	delete(h.activeLevelTests, user.ID)
+	h.finishLevelTest(ctx, user.ID)
+
+	// После теста при первом запуске продолжаем знакомство с ботом
+	if firstRun {
+		if err := h.sendMessage(chatID, resultText); err != nil {
+			return err
+		}
+		return h.sendWelcome(chatID, user, true)
+	}
 
 	// Если уровень отличается, показываем кнопки выбора
 	if recommendedLevel != user.Level {
@@ -1340,14 +1751,14 @@ func (h *Handler) sendTestResultsWithLevelChoice(chatID int64, resultText, recom
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	return err
 }
 
 // cancelLevelTest отменяет тест уровня без результатов
 func (h *Handler) cancelLevelTest(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
 	// Проверяем, есть ли активный тест
-	levelTest, exists := h.activeLevelTests[user.ID]
+	levelTest, exists := h.activeLevelTest(ctx, user.ID)
 	if !exists {
 		// Если теста нет, просто возвращаемся в главное меню
 		return h.handleStartCommand(ctx, message, user)
@@ -1371,7 +1782,7 @@ func (h *Handler) cancelLevelTest(ctx context.Context, message *tgbotapi.Message
 	user.CurrentState = models.StateIdle
 
 	// Удаляем тест из активных
-	delete(h.activeLevelTests, user.ID)
+	h.finishLevelTest(ctx, user.ID)
 
 	// Логируем отмену теста
 	h.logger.Info("пользователь отменил тест уровня",
@@ -1397,7 +1808,7 @@ func (h *Handler) cancelLevelTest(ctx context.Context, message *tgbotapi.Message
 
 // handleLevelTestAnswer обрабатывает ответ на вопрос теста
 func (h *Handler) handleLevelTestAnswer(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	levelTest, exists := h.activeLevelTests[user.ID]
+	levelTest, exists := h.activeLevelTest(ctx, user.ID)
 	if !exists {
 		return h.sendErrorMessage(message.Chat.ID, "Тест не найден. Начните новый тест.")
 	}
@@ -1406,44 +1817,23 @@ func (h *Handler) handleLevelTestAnswer(ctx context.Context, message *tgbotapi.M
 		return h.completeLevelTest(ctx, message.Chat.ID, user)
 	}
 
-	// Парсим ответ пользователя
-	answer := -1
-	switch message.Text {
-	case "1":
-		answer = 0
-	case "2":
-		answer = 1
-	case "3":
-		answer = 2
-	case "4":
-		answer = 3
-	default:
-		return h.sendMessage(message.Chat.ID, "❌ Пожалуйста, отправьте номер ответа (1, 2, 3 или 4)")
+	// Парсим ответ пользователя и засчитываем его
+	answer, ok := parseTypedTestAnswer(message.Text)
+	if !ok {
+		answer = -1
 	}
-
-	currentQ := levelTest.Questions[levelTest.CurrentQuestion]
-	isCorrect := answer == currentQ.CorrectAnswer
-	points := 0
-	if isCorrect {
-		points = currentQ.Points
-		levelTest.Score += points
+	currentQ, isCorrect, ok := scoreLevelTestAnswer(levelTest, answer)
+	if !ok {
+		return h.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Пожалуйста, отправьте номер ответа (%s)",
+			testAnswerNumbers(len(currentQ.Options))))
 	}
 
-	// Сохраняем ответ
-	levelTest.Answers = append(levelTest.Answers, models.LevelTestAnswer{
-		QuestionID: currentQ.ID,
-		Answer:     answer,
-		IsCorrect:  isCorrect,
-		Points:     points,
-	})
-
 	// Показываем результат ответа
 	var feedback string
 	if isCorrect {
 		feedback = "✅ Правильно!"
 	} else {
-		correctOption := currentQ.Options[currentQ.CorrectAnswer]
-		feedback = fmt.Sprintf("❌ Неправильно. Правильный ответ: %d. %s", currentQ.CorrectAnswer+1, correctOption)
+		feedback = "❌ Неправильно. Правильный ответ: " + correctTestOption(currentQ)
 	}
 
 	// Добавляем информацию о возможности отмены
@@ -1456,9 +1846,10 @@ func (h *Handler) handleLevelTestAnswer(ctx context.Context, message *tgbotapi.M
 
 	// Переходим к следующему вопросу
 	levelTest.CurrentQuestion++
+	h.saveLevelTestProgress(ctx, levelTest)
 
 	// Небольшая пауза перед следующим вопросом
-	time.Sleep(2 * time.Second)
+	h.pause(2 * time.Second)
 
 	return h.showCurrentQuestion(ctx, message.Chat.ID, user)
 }
@@ -1468,8 +1859,19 @@ func (h *Handler) sendMessage(chatID int64, text string) error {
 	return h.sendSafeMessage(chatID, text, false)
 }
 
-// sendSafeMessage отправляет сообщение с защитой от битых HTML тегов
+// sendSafeMessage отправляет сообщение с защитой от битых HTML тегов.
+// Длинный текст отправляется несколькими сообщениями.
 func (h *Handler) sendSafeMessage(chatID int64, text string, forceHTML bool) error {
+	for _, part := range PostProcess(text, sendOptions) {
+		if err := h.sendSafePart(chatID, part, forceHTML); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendSafePart отправляет одну часть сообщения, при ошибке HTML повторяет отправку обычным текстом
+func (h *Handler) sendSafePart(chatID int64, text string, forceHTML bool) error {
 	// Проверяем, содержит ли текст HTML теги
 	hasHTML := strings.Contains(text, "<") && strings.Contains(text, ">")
 
@@ -1477,11 +1879,11 @@ func (h *Handler) sendSafeMessage(chatID int64, text string, forceHTML bool) err
 	var parseMode string
 
 	if hasHTML || forceHTML {
-		// Если есть HTML теги, декодируем HTML-сущности и используем как HTML
-		cleanText = html.UnescapeString(text)
+		// Текст уже прошел PostProcess: теги отфильтрованы, спецсимволы экранированы
+		cleanText = text
 		parseMode = "HTML"
 	} else {
-		// Если HTML тегов нет, все равно декодируем HTML-сущности
+		// Если HTML тегов нет, декодируем HTML-сущности
 		cleanText = html.UnescapeString(text)
 		parseMode = ""
 	}
@@ -1491,7 +1893,7 @@ func (h *Handler) sendSafeMessage(chatID int64, text string, forceHTML bool) err
 		msg.ParseMode = parseMode
 	}
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	if err != nil {
 		h.logger.Error("ошибка отправки сообщения",
 			zap.Int64("chat_id", chatID),
@@ -1502,9 +1904,9 @@ func (h *Handler) sendSafeMessage(chatID int64, text string, forceHTML bool) err
 		if parseMode == "HTML" {
 			h.logger.Info("повторная отправка как обычный текст", zap.Int64("chat_id", chatID))
 			// Удаляем HTML теги для fallback
-			fallbackText := h.stripHTMLTags(text)
+			fallbackText := postProcessText(text, plainTextOptions)
 			fallbackMsg := tgbotapi.NewMessage(chatID, fallbackText)
-			_, fallbackErr := h.bot.Send(fallbackMsg)
+			_, fallbackErr := h.sender.Send(fallbackMsg)
 			return fallbackErr
 		}
 		return err
@@ -1515,19 +1917,25 @@ func (h *Handler) sendSafeMessage(chatID int64, text string, forceHTML bool) err
 
 // sendMessageWithKeyboard отправляет сообщение с клавиатурой
 func (h *Handler) sendMessageWithKeyboard(chatID int64, text string, keyboard [][]string) error {
+	// Клавиатура прикрепляется к последней части длинного сообщения
+	parts := PostProcess(text, sendOptions)
+	for _, part := range parts[:len(parts)-1] {
+		if err := h.sendSafePart(chatID, part, false); err != nil {
+			return err
+		}
+	}
+	text = parts[len(parts)-1]
+
 	// Проверяем, содержит ли текст HTML теги
 	hasHTML := strings.Contains(text, "<") && strings.Contains(text, ">")
 
 	var msg tgbotapi.MessageConfig
 	if hasHTML {
-		// Если есть HTML теги, очищаем их безопасно
-		cleanText := h.cleanTextForTelegram(text)
-		msg = tgbotapi.NewMessage(chatID, cleanText)
+		msg = tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
 	} else {
-		// Если HTML тегов нет, экранируем опасные символы
-		safeText := html.EscapeString(text)
-		msg = tgbotapi.NewMessage(chatID, safeText)
+		// Если HTML тегов нет, декодируем HTML-сущности
+		msg = tgbotapi.NewMessage(chatID, html.UnescapeString(text))
 	}
 
 	// Создаем клавиатуру
@@ -1548,7 +1956,7 @@ func (h *Handler) sendMessageWithKeyboard(chatID int64, text string, keyboard []
 
 	msg.ReplyMarkup = keyboardMarkup
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	if err != nil {
 		h.logger.Error("ошибка отправки сообщения с клавиатурой",
 			zap.Int64("chat_id", chatID),
@@ -1564,7 +1972,7 @@ func (h *Handler) removeKeyboard(chatID int64) error {
 	msg := tgbotapi.NewMessage(chatID, "")
 	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 
-	_, err := h.bot.Send(msg)
+	_, err := h.sender.Send(msg)
 	if err != nil {
 		h.logger.Error("ошибка удаления клавиатуры",
 			zap.Int64("chat_id", chatID),
@@ -1604,7 +2012,7 @@ func (h *Handler) buildAIMessagesForAudio(messages []models.UserMessage, user *m
 
 // buildSystemPromptForAudio создает специальный системный промпт для аудио сообщений
 func (h *Handler) buildSystemPromptForAudio(user *models.User) string {
-	return h.prompts.GetAudioPrompt(user.Level)
+	return h.prompts.GetAudioPrompt(user.Level, user.LearningLanguage)
 }
 
 // getLevelText возвращает текстовое представление уровня
@@ -1635,164 +2043,95 @@ func (h *Handler) getLevelEmoji(level string) string {
 	}
 }
 
-// extractTextFromHTML извлекает чистый текст из HTML, удаляя все теги
-
-// extractEnglishFromResponse извлекает только английскую часть из ответа с переводом
-func (h *Handler) extractEnglishFromResponse(responseWithTranslation string) string {
-	// Ищем начало спойлера с переводом
-	spoilerStart := strings.Index(responseWithTranslation, "<tg-spoiler>")
-	if spoilerStart == -1 {
-		// Если спойлера нет, возвращаем весь текст
-		return strings.TrimSpace(responseWithTranslation)
-	}
-
-	// Извлекаем только английскую часть (до спойлера)
-	englishPart := responseWithTranslation[:spoilerStart]
-	return strings.TrimSpace(englishPart)
-}
-
-// cleanTextForTelegram очищает текст для корректного отображения в Telegram
-func (h *Handler) cleanTextForTelegram(text string) string {
-	// Очищаем текст от потенциально опасных HTML тегов
-	// Оставляем только безопасные теги для HTML режима
-
-	// Заменяем переносы строк
-	text = strings.ReplaceAll(text, "<br>", "\n")
-	text = strings.ReplaceAll(text, "<br/>", "\n")
-	text = strings.ReplaceAll(text, "<br />", "\n")
-
-	// Заменяем горизонтальные линии
-	text = strings.ReplaceAll(text, "<hr>", "\n"+strings.Repeat("-", 20)+"\n")
-	text = strings.ReplaceAll(text, "<hr/>", "\n"+strings.Repeat("-", 20)+"\n")
-	text = strings.ReplaceAll(text, "<hr />", "\n"+strings.Repeat("-", 20)+"\n")
-
-	// Удаляем div и p теги, оставляя содержимое
-	text = strings.ReplaceAll(text, "<div>", "")
-	text = strings.ReplaceAll(text, "</div>", "\n")
-	text = strings.ReplaceAll(text, "<p>", "")
-	text = strings.ReplaceAll(text, "</p>", "\n")
-
-	// Удаляем span теги, оставляя содержимое
-	text = strings.ReplaceAll(text, "<span>", "")
-	text = strings.ReplaceAll(text, "</span>", "")
-
-	// Удаляем лишние переносы строк
-	text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
-	text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
-
-	return strings.TrimSpace(text)
-}
-
-// stripHTMLTags удаляет все HTML теги из текста и декодирует HTML-сущности
-func (h *Handler) stripHTMLTags(text string) string {
-	// Декодируем HTML-сущности
-	text = html.UnescapeString(text)
-	// Удаляем HTML теги
-	re := regexp.MustCompile(`<[^>]*>`)
-	return re.ReplaceAllString(text, "")
-}
-
 // getOrCreateDialogContext получает или создает контекст диалога для пользователя
-func (h *Handler) getOrCreateDialogContext(userID int64, level string) *DialogContext {
-	if context, exists := h.dialogContexts[userID]; exists && !context.IsStale() {
-		return context
+func (h *Handler) getOrCreateDialogContext(ctx context.Context, userID int64, level, lang string) *DialogContext {
+	h.dialogMutex.Lock()
+	existing, exists := h.dialogContexts[userID]
+	h.dialogMutex.Unlock()
+	if exists && !existing.IsStale() {
+		return existing
 	}
 
 	// Создаем новый контекст с системным промптом
-	var systemPrompt string
-	if level == "beginner" {
-		systemPrompt = h.prompts.GetEnglishMessagePrompt(level)
-	} else if level == "intermediate" {
-		systemPrompt = h.prompts.GetEnglishMessagePrompt(level)
-	} else {
-		systemPrompt = h.prompts.GetEnglishMessagePrompt(level)
-	}
-
-	context := NewDialogContext(userID, level, systemPrompt)
-	h.dialogContexts[userID] = context
-	return context
-}
-
-// cleanAIResponse очищает ответ AI от неподдерживаемых HTML-тегов
-func (h *Handler) cleanAIResponse(text string) string {
-	// Удаляем неподдерживаемые теги, оставляя содержимое
-	text = strings.ReplaceAll(text, "<ul>", "")
-	text = strings.ReplaceAll(text, "</ul>", "\n")
-	text = strings.ReplaceAll(text, "<li>", "• ")
-	text = strings.ReplaceAll(text, "</li>", "\n")
-	text = strings.ReplaceAll(text, "<div>", "")
-	text = strings.ReplaceAll(text, "</div>", "\n")
-	text = strings.ReplaceAll(text, "<p>", "")
-	text = strings.ReplaceAll(text, "</p>", "\n")
-	text = strings.ReplaceAll(text, "<span>", "")
-	text = strings.ReplaceAll(text, "</span>", "")
-
-	// Заменяем Markdown разметку на HTML теги
-	text = regexp.MustCompile(`\*\*([^*]+)\*\*`).ReplaceAllString(text, "<b>$1</b>")
-
-	// Заменяем заголовки # на жирный текст
-	text = regexp.MustCompile(`^#+\s+(.+)$`).ReplaceAllString(text, "<b>$1</b>")
-	text = regexp.MustCompile(`\n#+\s+(.+)$`).ReplaceAllString(text, "\n<b>$1</b>")
+	systemPrompt := h.prompts.GetEnglishMessagePrompt(level, lang)
 
-	// Удаляем лишние символы разметки
-	text = strings.ReplaceAll(text, "---", "")
-	text = strings.ReplaceAll(text, "___", "")
-
-	// Очищаем от лишних пробелов и переносов
-	text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
-	text = strings.TrimSpace(text)
+	// После перезапуска продолжаем разговор с того места, где он остановился:
+	// из сохраненного контекста, а если его нет — из истории сообщений.
+	// Загрузка идет без блокировки, чтобы не задерживать других пользователей.
+	var dialogContext *DialogContext
+	if !exists {
+		dialogContext = h.loadDialogContext(ctx, userID, level, systemPrompt)
+		if dialogContext == nil {
+			dialogContext = h.rebuildDialogContext(ctx, userID, level, systemPrompt)
+		}
+	}
+	if dialogContext == nil {
+		dialogContext = NewDialogContext(userID, level, systemPrompt)
+	}
 
-	return text
+	h.dialogMutex.Lock()
+	defer h.dialogMutex.Unlock()
+	// Параллельное сообщение того же пользователя могло успеть создать контекст
+	if current, ok := h.dialogContexts[userID]; ok && current != existing && !current.IsStale() {
+		return current
+	}
+	h.dialogContexts[userID] = dialogContext
+	return dialogContext
 }
 
 // handleAudioMessage обрабатывает голосовые и аудио сообщения
 func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	// Проверяем лимит сообщений для бесплатных пользователей
-	canSend, err := h.premiumService.CanSendMessage(ctx, user.ID)
-	if err != nil {
-		h.logger.Error("ошибка проверки лимита сообщений", zap.Error(err))
-		return h.sendErrorMessage(message.Chat.ID, "Ошибка проверки лимита сообщений")
+	// Голосовое с фразой дня или проверкой произношения не расходует лимит сообщений
+	phraseAttempt := !isGroupChat(message) && h.hasActivePhraseChallenge(user.ID)
+	pronunciationAttempt := !isGroupChat(message) && !phraseAttempt && h.hasActivePronunciation(user.ID)
+	practiceAttempt := phraseAttempt || pronunciationAttempt
+
+	// Фразе дня и произношению AI не нужен, остальные голосовые без него не разобрать
+	if !practiceAttempt && !h.aiAvailable() {
+		return h.sendOfflineMode(ctx, message.Chat.ID, user)
 	}
 
-	if !canSend {
-		return h.handleMessageLimit(ctx, message.Chat.ID, user)
+	// Проверяем лимит сообщений для бесплатных пользователей
+	if !practiceAttempt {
+		canSend, err := h.premiumService.CanSendMessage(ctx, user.ID)
+		if err != nil {
+			h.logger.Error("ошибка проверки лимита сообщений", zap.Error(err))
+			return h.sendErrorMessage(message.Chat.ID, "Ошибка проверки лимита сообщений")
+		}
+
+		if !canSend {
+			return h.handleMessageLimit(ctx, message.Chat.ID, user)
+		}
 	}
 
-	// Обновляем study streak только раз в день
 	h.updateStudyActivity(user)
 
 	// Отправляем сообщение о начале обработки
 	processingMsg := tgbotapi.NewMessage(message.Chat.ID, "🎤 Обрабатываю аудио сообщение...")
 	processingMsg.ReplyToMessageID = message.MessageID
-	_, err = h.bot.Send(processingMsg)
+	_, err := h.sender.Send(processingMsg)
 	if err != nil {
 		h.logger.Error("ошибка отправки сообщения о обработке", zap.Error(err))
 	}
 
 	// Определяем тип аудио и получаем файл
-	var fileID string
-	var fileExt string
-
-	if message.Voice != nil {
-		fileID = message.Voice.FileID
-		fileExt = ".ogg"
-		// Проверяем размер голосового сообщения
-		if message.Voice.FileSize > MaxFileSize {
-			return h.sendErrorMessage(message.Chat.ID, "Файл слишком большой. Максимум 25MB.")
-		}
-	} else if message.Audio != nil {
-		fileID = message.Audio.FileID
-		fileExt = ".mp3"
-		// Проверяем размер аудио файла
-		if message.Audio.FileSize > MaxFileSize {
-			return h.sendErrorMessage(message.Chat.ID, "Файл слишком большой. Максимум 25MB.")
-		}
-	} else {
+	source, ok := audioSourceOf(message)
+	if !ok {
 		return h.sendErrorMessage(message.Chat.ID, "Неподдерживаемый тип аудио")
 	}
+	fileID, fileExt := source.fileID, source.ext
+
+	// Проверяем размер файла
+	if source.size > MaxFileSize {
+		return h.sendErrorMessage(message.Chat.ID, "Файл слишком большой. Максимум 25MB.")
+	}
+
+	if h.files == nil {
+		return h.sendErrorMessage(message.Chat.ID, "Ошибка получения аудио")
+	}
 
 	// Получаем файл от Telegram
-	file, err := h.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	file, err := h.files.GetFile(tgbotapi.FileConfig{FileID: fileID})
 	if err != nil {
 		h.logger.Error("ошибка получения файла от Telegram", zap.Error(err))
 		return h.sendErrorMessage(message.Chat.ID, "Ошибка получения аудио")
@@ -1831,7 +2170,7 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 		Timeout: 30 * time.Second,
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", file.Link(h.bot.Token), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", file.Link(h.files.Token), nil)
 	if err != nil {
 		h.logger.Error("ошибка создания запроса", zap.Error(err))
 		return h.sendErrorMessage(message.Chat.ID, "Ошибка скачивания аудио")
@@ -1884,6 +2223,26 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 		return h.sendErrorMessage(message.Chat.ID, "Ошибка сохранения аудио")
 	}
 
+	// Проверяем длительность аудио до транскрибации
+	longAudio := h.featureEnabled(user, premium.FeatureLongAudio)
+	maxDuration := h.whisperClient.MaxDuration(longAudio)
+	duration, err := h.whisperClient.GetAudioDuration(filePath)
+	if err != nil {
+		h.logger.Warn("не удалось определить длительность аудио", zap.Error(err))
+	} else if duration > maxDuration {
+		h.logger.Info("аудио превышает лимит длительности",
+			zap.Int64("user_id", user.ID),
+			zap.Duration("duration", duration),
+			zap.Duration("max_duration", maxDuration))
+		text := fmt.Sprintf("⏱ Аудио слишком длинное (%s). Максимум — %s.\n\n💡 Разделите запись на несколько коротких сообщений.",
+			formatAudioDuration(duration), formatAudioDuration(maxDuration))
+		if !longAudio {
+			text += fmt.Sprintf("\n💎 С премиумом можно отправлять аудио до %s: /premium",
+				formatAudioDuration(h.whisperClient.MaxDuration(true)))
+		}
+		return h.sendMessage(message.Chat.ID, text)
+	}
+
 	// Транскрибируем аудио
 	transcription, err := h.whisperClient.TranscribeFile(ctx, filePath)
 	if err != nil {
@@ -1896,6 +2255,15 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 		return h.sendErrorMessage(message.Chat.ID, "Не удалось распознать речь")
 	}
 
+	h.logger.Info("язык голосового сообщения",
+		zap.Int64("user_id", user.ID),
+		zap.String("detected", transcription.Language),
+		zap.String("learning", user.LearningLanguage),
+		zap.Int("segments", len(transcription.Segments)))
+	if h.featureEnabled(user, premium.FeatureTranscribeDebug) {
+		h.transcriptions.put(user.ID, transcription)
+	}
+
 	// Отправляем результат транскрибации
 	transcriptionMsg := fmt.Sprintf(
 		"🎤 <b>Распознанная речь:</b>\n\n<blockquote>%s</blockquote>",
@@ -1904,12 +2272,30 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 	msg := tgbotapi.NewMessage(message.Chat.ID, transcriptionMsg)
 	msg.ParseMode = "HTML"
 	msg.ReplyToMessageID = message.MessageID
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	if err != nil {
 		h.logger.Error("ошибка отправки результата транскрибации", zap.Error(err))
 		return err
 	}
 
+	// Попытка произнести фразу дня оценивается отдельно и не уходит в диалог с AI
+	if pronunciationAttempt {
+		return h.handlePronunciationAnswer(ctx, message.Chat.ID, user, transcription.Text)
+	}
+	if phraseAttempt {
+		return h.handlePhraseChallengeAnswer(ctx, message.Chat.ID, user, transcription.Text)
+	}
+
+	// Речь не на изучаемом языке: мягко подсказываем, в режиме skip не отвечаем через AI
+	if hint := h.audioLanguageHint(transcription, user); hint != "" {
+		if err := h.sendMessage(message.Chat.ID, hint); err != nil {
+			h.logger.Error("ошибка отправки подсказки о языке речи", zap.Error(err))
+		}
+		if h.audioLanguageCheck == AudioLanguageCheckSkip {
+			return nil
+		}
+	}
+
 	// Сохраняем транскрибированный текст как сообщение пользователя
 	_, err = h.messageService.SaveUserMessage(ctx, user.ID, transcription.Text)
 	if err != nil {
@@ -1937,6 +2323,7 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 		h.logger.Error("ошибка генерации ответа", zap.Error(err))
 		return h.sendErrorMessage(message.Chat.ID, "Ошибка генерации ответа")
 	}
+	response.Content = postProcessText(response.Content, aiTextOptions)
 
 	// Сохраняем ответ ассистента
 	_, err = h.messageService.SaveAssistantMessage(ctx, user.ID, response.Content)
@@ -1956,7 +2343,7 @@ func (h *Handler) handleAudioMessage(ctx context.Context, message *tgbotapi.Mess
 
 // handleLevelTestCallback обрабатывает ответ на вопрос теста через callback
 func (h *Handler) handleLevelTestCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User, answer int) error {
-	levelTest, exists := h.activeLevelTests[user.ID]
+	levelTest, exists := h.activeLevelTest(ctx, user.ID)
 	if !exists {
 		return h.sendMessage(callback.Message.Chat.ID, "❌ Тест не найден. Начните новый тест.")
 	}
@@ -1965,29 +2352,22 @@ func (h *Handler) handleLevelTestCallback(ctx context.Context, callback *tgbotap
 		return h.completeLevelTest(ctx, callback.Message.Chat.ID, user)
 	}
 
-	currentQ := levelTest.Questions[levelTest.CurrentQuestion]
-	isCorrect := answer == currentQ.CorrectAnswer
-	points := 0
-	if isCorrect {
-		points = currentQ.Points
-		levelTest.Score += points
+	currentQ, isCorrect, ok := scoreLevelTestAnswer(levelTest, answer)
+	if !ok {
+		// Кнопка от другого вопроса или подделанный callback
+		h.logger.Warn("номер ответа вне вариантов вопроса",
+			zap.Int("answer", answer),
+			zap.Int("options_count", len(currentQ.Options)),
+			zap.Int64("user_id", user.ID))
+		return nil
 	}
 
-	// Сохраняем ответ
-	levelTest.Answers = append(levelTest.Answers, models.LevelTestAnswer{
-		QuestionID: currentQ.ID,
-		Answer:     answer,
-		IsCorrect:  isCorrect,
-		Points:     points,
-	})
-
 	// Показываем результат ответа
 	var feedback string
 	if isCorrect {
 		feedback = "✅ <b>Правильно!</b>"
 	} else {
-		correctOption := currentQ.Options[currentQ.CorrectAnswer]
-		feedback = fmt.Sprintf("❌ <b>Неправильно.</b> Правильный ответ: <b>%d. %s</b>", currentQ.CorrectAnswer+1, correctOption)
+		feedback = fmt.Sprintf("❌ <b>Неправильно.</b> Правильный ответ: <b>%s</b>", correctTestOption(currentQ))
 	}
 
 	// Редактируем сообщение с результатом
@@ -2005,15 +2385,16 @@ func (h *Handler) handleLevelTestCallback(ctx context.Context, callback *tgbotap
 			feedback))
 	editMsg.ParseMode = "HTML"
 
-	if _, err := h.bot.Send(editMsg); err != nil {
+	if _, err := h.sender.Send(editMsg); err != nil {
 		h.logger.Error("ошибка редактирования сообщения теста", zap.Error(err))
 	}
 
 	// Переходим к следующему вопросу
 	levelTest.CurrentQuestion++
+	h.saveLevelTestProgress(ctx, levelTest)
 
 	// Небольшая пауза перед следующим вопросом
-	time.Sleep(2 * time.Second)
+	h.pause(2 * time.Second)
 
 	return h.showCurrentQuestion(ctx, callback.Message.Chat.ID, user)
 }
@@ -2021,7 +2402,7 @@ func (h *Handler) handleLevelTestCallback(ctx context.Context, callback *tgbotap
 // handleTestCancelCallback обрабатывает отмену теста через callback
 func (h *Handler) handleTestCancelCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
 	// Удаляем активный тест
-	delete(h.activeLevelTests, user.ID)
+	h.finishLevelTest(ctx, user.ID)
 
 	// Сбрасываем состояние пользователя
 	newState := models.StateIdle
@@ -2055,7 +2436,7 @@ func (h *Handler) handleTestCancelCallback(ctx context.Context, callback *tgbota
 		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
 	}
 
-	if _, err := h.bot.Send(editMsg); err != nil {
+	if _, err := h.sender.Send(editMsg); err != nil {
 		h.logger.Error("ошибка редактирования сообщения об отмене теста", zap.Error(err))
 		// Если не удалось отредактировать, отправляем новое сообщение
 		return h.sendMessageWithKeyboard(callback.Message.Chat.ID, cancelMessage, h.messages.GetMainKeyboard())
@@ -2084,6 +2465,13 @@ func (h *Handler) handleLevelChangeCallback(ctx context.Context, callback *tgbot
 	// Обновляем локальные данные
 	user.Level = newLevel
 
+	// Уровень принят по результату теста
+	if err := h.userService.SetLevelAssessment(ctx, user.ID, models.LevelAssessmentTest); err != nil {
+		h.logger.Warn("не удалось сохранить способ определения уровня", zap.Error(err), zap.Int64("user_id", user.ID))
+	} else {
+		user.LevelAssessment = models.LevelAssessmentTest
+	}
+
 	successMessage := fmt.Sprintf(`✅ <b>Уровень изменен!</b>
 
 📚 <b>Новый уровень:</b> %s
@@ -2104,7 +2492,7 @@ func (h *Handler) handleLevelChangeCallback(ctx context.Context, callback *tgbot
 		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
 	}
 
-	if _, err := h.bot.Send(editMsg); err != nil {
+	if _, err := h.sender.Send(editMsg); err != nil {
 		h.logger.Error("ошибка редактирования сообщения о смене уровня", zap.Error(err))
 		// Если не удалось отредактировать, отправляем новое сообщение
 		return h.sendMessageWithKeyboard(callback.Message.Chat.ID, successMessage, h.messages.GetMainKeyboard())
@@ -2130,7 +2518,7 @@ func (h *Handler) handleKeepCurrentLevelCallback(ctx context.Context, callback *
 		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
 	}
 
-	if _, err := h.bot.Send(editMsg); err != nil {
+	if _, err := h.sender.Send(editMsg); err != nil {
 		h.logger.Error("ошибка редактирования сообщения о сохранении уровня", zap.Error(err))
 		// Если не удалось отредактировать, отправляем новое сообщение
 		return h.sendMessageWithKeyboard(callback.Message.Chat.ID, keepMessage, h.messages.GetMainKeyboard())
@@ -2160,17 +2548,17 @@ func (h *Handler) generateLevelTest(userID int64) *models.LevelTest {
 	}
 }
 
+// levelTestDescriptions описания рекомендуемых уровней после теста
+var levelTestDescriptions = map[string]string{
+	models.LevelAdvanced:     "Отличный результат! Ты владеешь английским на продвинутом уровне. Можешь изучать сложные темы и общаться на любые темы.",
+	models.LevelIntermediate: "Хороший результат! Ты владеешь английским на среднем уровне. Можешь изучать более сложные темы и улучшать разговорные навыки.",
+	models.LevelBeginner:     "Хорошее начало! Ты владеешь английским на начальном уровне. Стоит изучать основы грамматики и базовую лексику.",
+}
+
 // calculateLevel определяет уровень пользователя на основе результатов теста
 func (h *Handler) calculateLevel(score, maxScore int) (string, string) {
-	percentage := float64(score) / float64(maxScore) * 100
-
-	if percentage >= 80 {
-		return models.LevelAdvanced, "Отличный результат! Ты владеешь английским на продвинутом уровне. Можешь изучать сложные темы и общаться на любые темы."
-	} else if percentage >= 60 {
-		return models.LevelIntermediate, "Хороший результат! Ты владеешь английским на среднем уровне. Можешь изучать более сложные темы и улучшать разговорные навыки."
-	} else {
-		return models.LevelBeginner, "Хорошее начало! Ты владеешь английским на начальном уровне. Стоит изучать основы грамматики и базовую лексику."
-	}
+	level := models.GetLevelByTestScore(score, maxScore)
+	return level, levelTestDescriptions[level]
 }
 
 // selectRandomQuestions выбирает случайные вопросы из разных уровней
@@ -2346,18 +2734,55 @@ func (h *Handler) sendLevelUpNotification(userID int64, oldLevel, newLevel strin
 
 // / handleLeaderboardButton показывает рейтинг пользователей прямо в Telegram
 func (h *Handler) handleLeaderboardButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
-	// Получаем топ пользователей (с большим лимитом для статистики)
-	users, err := h.userService.GetTopUsersByStreak(ctx, 100)
+	return h.sendLeaderboard(ctx, message.Chat.ID, user, leaderboardByXP)
+}
+
+// sendLeaderboard отправляет рейтинг с кнопкой переключения на другой рейтинг
+func (h *Handler) sendLeaderboard(ctx context.Context, chatID int64, user *models.User, kind leaderboardKind) error {
+	text, err := h.buildLeaderboard(ctx, user, kind)
 	if err != nil {
 		h.logger.Error("ошибка получения пользователей для рейтинга",
 			zap.Error(err))
-		return h.sendErrorMessage(message.Chat.ID, "Ошибка загрузки рейтинга")
+		return h.sendErrorMessage(chatID, "Ошибка загрузки рейтинга")
+	}
+
+	// Отправляем сообщение
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = leaderboardKeyboard(kind)
+
+	if _, err := h.sender.Send(msg); err != nil {
+		h.logger.Error("ошибка отправки рейтинга",
+			zap.Error(err),
+			zap.Int64("chat_id", chatID))
+		return err
+	}
+
+	return nil
+}
+
+// buildLeaderboard формирует текст рейтинга по XP или по сериям занятий
+func (h *Handler) buildLeaderboard(ctx context.Context, user *models.User, kind leaderboardKind) (string, error) {
+	// Получаем топ пользователей (с большим лимитом для статистики)
+	var users []*models.User
+	var err error
+	if kind == leaderboardByStreak {
+		users, err = h.userService.GetTopUsersByStreakOnly(ctx, 100)
+	} else {
+		users, err = h.userService.GetTopUsersByStreak(ctx, 100)
+	}
+	if err != nil {
+		return "", err
 	}
 
 	var leaderboardText strings.Builder
 
 	// Заголовок
-	leaderboardText.WriteString("🏆 <b>Рейтинг пользователей Lingua AI</b>\n\n")
+	if kind == leaderboardByStreak {
+		leaderboardText.WriteString("🔥 <b>Рейтинг серий Lingua AI</b>\n\n")
+	} else {
+		leaderboardText.WriteString("🏆 <b>Рейтинг пользователей Lingua AI</b>\n\n")
+	}
 
 	// Общая статистика
 	leaderboardText.WriteString("📊 <b>Общая статистика</b>\n")
@@ -2401,9 +2826,12 @@ func (h *Handler) handleLeaderboardButton(ctx context.Context, message *tgbotapi
 			username += fmt.Sprintf(" (@%s)", hiddenUsername)
 		}
 
-		// Формат строки
-		leaderboardText.WriteString(fmt.Sprintf(
-			"%s <b>%s</b>\n   %s %s • 🔥 %d дн. • ⭐ <b>%d XP</b>\n\n",
+		// Формат строки: в рейтинге серий серия выделяется вместо XP
+		format := "%s <b>%s</b>\n   %s %s • 🔥 %d дн. • ⭐ <b>%d XP</b>\n\n"
+		if kind == leaderboardByStreak {
+			format = "%s <b>%s</b>\n   %s %s • 🔥 <b>%d дн.</b> • ⭐ %d XP\n\n"
+		}
+		leaderboardText.WriteString(fmt.Sprintf(format,
 			rankIcon, username,
 			h.getLevelEmoji(u.Level),
 			h.getLevelText(u.Level),
@@ -2415,30 +2843,23 @@ func (h *Handler) handleLeaderboardButton(ctx context.Context, message *tgbotapi
 	// Позиция текущего пользователя
 	for i, u := range users {
 		if u.ID == user.ID {
+			score := fmt.Sprintf("⭐ <b>%d XP</b>", user.XP)
+			if kind == leaderboardByStreak {
+				score = fmt.Sprintf("🔥 <b>%d дн.</b>", u.StudyStreak)
+			}
 			leaderboardText.WriteString("📍 <b>Твоя позиция</b>\n")
 			leaderboardText.WriteString(fmt.Sprintf(
-				"   №%d • %s %s • ⭐ <b>%d XP</b>\n",
+				"   №%d • %s %s • %s\n",
 				i+1,
 				h.getLevelEmoji(user.Level),
 				h.getLevelText(user.Level),
-				user.XP,
+				score,
 			))
 			break
 		}
 	}
 
-	// Отправляем сообщение
-	msg := tgbotapi.NewMessage(message.Chat.ID, leaderboardText.String())
-	msg.ParseMode = "HTML"
-
-	if _, err := h.bot.Send(msg); err != nil {
-		h.logger.Error("ошибка отправки рейтинга",
-			zap.Error(err),
-			zap.Int64("chat_id", message.Chat.ID))
-		return err
-	}
-
-	return nil
+	return leaderboardText.String(), nil
 }
 
 // handleLearningCommand обрабатывает команду /learning
@@ -2454,6 +2875,8 @@ func (h *Handler) handleLearningButton(ctx context.Context, message *tgbotapi.Me
 
 🎯 <b>Доступные методы:</b>
 📝 Словарные карточки — изучение новых слов с интервальным повторением
+📦 Набор недели — тематическая подборка слов для вашего уровня
+🎧 Диктант — послушайте фразу и напишите, что услышали
 🎓 Тест уровня — определите свой текущий уровень английского
 
 Что хотите попробовать?`
@@ -2461,68 +2884,6 @@ func (h *Handler) handleLearningButton(ctx context.Context, message *tgbotapi.Me
 	return h.sendMessageWithKeyboard(message.Chat.ID, messageText, h.messages.GetLearningKeyboard())
 }
 
-// handleMainHelpCallback обрабатывает callback для помощи
-func (h *Handler) handleMainHelpCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
-	helpText := `❓ <b>Помощь по использованию бота</b>
-
-🤖 <b>Основные команды:</b>
-/start — Начать работу с ботом
-/help — Показать это сообщение
-/learning — Открыть меню обучения
-/stats — Показать вашу статистику
-/premium — Информация о премиум-подписке
-
-📚 <b>Функции обучения:</b>
-• Отправляйте текстовые сообщения для перевода
-• Отправляйте голосовые сообщения для транскрибации
-• Изучайте слова с помощью карточек
-• Проходите тесты для определения уровня
-
-💡 <b>Советы:</b>
-• Регулярно занимайтесь для лучшего результата
-• Используйте карточки для запоминания слов
-• Отправляйте голосовые сообщения для практики произношения
-
-Если у вас есть вопросы, обратитесь к администратору.`
-
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, helpText)
-	msg.ParseMode = "HTML"
-
-	_, err := h.bot.Send(msg)
-	return err
-}
-
-// handleMainPremiumCallback обрабатывает callback для премиума
-func (h *Handler) handleMainPremiumCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
-	return h.handlePremiumCommand(ctx, callback.Message, user)
-}
-
-// handleMainRatingCallback обрабатывает callback для рейтинга
-func (h *Handler) handleMainRatingCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
-	// Используем существующую функцию для показа рейтинга
-	return h.handleLeaderboardButton(ctx, callback.Message, user)
-}
-
-// handleLearningMenuCallback обрабатывает callback для меню обучения
-func (h *Handler) handleLearningMenuCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
-	return h.handleLearningButton(ctx, callback.Message, user)
-}
-
-// handleMainStatsCallback обрабатывает callback для статистики
-func (h *Handler) handleMainStatsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
-	// Используем существующую функцию Stats из Messages
-	levelText := h.getLevelText(user.Level)
-	lastStudyDate := user.LastStudyDate.Format("02.01.2006")
-
-	messageText := h.messages.Stats(user.FirstName, levelText, user.XP, user.StudyStreak, lastStudyDate)
-
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, messageText)
-	msg.ParseMode = "HTML"
-
-	_, err := h.bot.Send(msg)
-	return err
-}
-
 // handleReferralButton обрабатывает нажатие кнопки "Реферальная ссылка"
 func (h *Handler) handleReferralButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
 	// Получаем или генерируем реферальный код
@@ -2539,24 +2900,23 @@ func (h *Handler) handleReferralButton(ctx context.Context, message *tgbotapi.Me
 		// Не возвращаем ошибку, показываем ссылку без статистики
 	}
 
-	// Формируем дополнительную информацию о статусе премиума
+	policy := h.referralService.RewardPolicy()
+	rewardText := fmt.Sprintf(`🎁 <b>Награда:</b>
+За каждые <b>%d друзей</b>, которые начали заниматься, вы получаете <b>месяц премиума</b>.
+<em>Друг засчитывается после %d сообщений боту в разные дни (минимум %d дн.). Всего можно получить до %d мес. премиума.</em>`,
+		policy.ReferralsPerReward, policy.MinMessages, policy.MinActiveDays, policy.MaxRewards)
+
+	// Формируем информацию о прогрессе к следующей награде
 	var premiumStatus string
-	if user.IsPremium {
-		if user.PremiumExpiresAt != nil {
-			premiumStatus = fmt.Sprintf("🌟 <b>У вас уже есть премиум до %s</b>\n\n💡 <em>Продолжайте приглашать друзей! Премиум будет продлен на месяц при достижении 10 рефералов.</em>",
-				user.PremiumExpiresAt.Format("02.01.2006"))
-		} else {
-			premiumStatus = "🌟 <b>У вас уже есть активная премиум-подписка</b>\n\n💡 <em>Продолжайте приглашать друзей! Премиум будет продлен на месяц при достижении 10 рефералов.</em>"
-		}
-	} else {
-		if stats != nil && stats.TotalReferrals >= 10 {
-			premiumStatus = "🎉 <b>Поздравляем! У вас 10+ рефералов!</b>\n\n✅ <em>Премиум уже предоставлен на месяц.</em>"
-		} else if stats != nil {
-			remaining := 10 - stats.TotalReferrals
-			premiumStatus = fmt.Sprintf("📈 <b>До премиума осталось: %d рефералов</b>\n\n💪 <em>Продолжайте приглашать друзей!</em>", remaining)
-		} else {
-			premiumStatus = "📈 <b>До премиума нужно: 10 рефералов</b>\n\n💪 <em>Начните приглашать друзей прямо сейчас!</em>"
-		}
+	switch {
+	case stats == nil:
+		premiumStatus = fmt.Sprintf("📈 <b>До премиума нужно: %d рефералов</b>\n\n💪 <em>Начните приглашать друзей прямо сейчас!</em>", policy.ReferralsPerReward)
+	case stats.RewardsEarned >= policy.MaxRewards:
+		premiumStatus = fmt.Sprintf("🏆 <b>Получены все награды за рефералов: %d из %d</b>\n\n💙 <em>Спасибо, что рассказываете о нас друзьям!</em>", stats.RewardsEarned, policy.MaxRewards)
+	default:
+		remaining := policy.ReferralsPerReward - stats.CompletedReferrals%policy.ReferralsPerReward
+		premiumStatus = fmt.Sprintf("📈 <b>До следующего месяца премиума: %d рефералов</b>\n🎁 Получено наград: %d из %d\n\n💪 <em>Продолжайте приглашать друзей!</em>",
+			remaining, stats.RewardsEarned, policy.MaxRewards)
 	}
 
 	// Формируем сообщение
@@ -2569,45 +2929,47 @@ func (h *Handler) handleReferralButton(ctx context.Context, message *tgbotapi.Me
 
 📊 <b>Ваша статистика:</b>
 • Приглашено друзей: <b>%d</b>
-• Завершено регистраций: <b>%d</b>
-• Ожидают регистрации: <b>%d</b>
+• Засчитано: <b>%d</b>
+• Ждут активности друга: <b>%d</b>
 
-🎁 <b>Награда:</b>
-За <b>10 приглашенных друзей</b> вы получите <b>премиум на месяц</b>!
-<em>(только если у вас еще нет активной премиум-подписки)</em>
+%s
 
 %s
 
 💡 <b>Как это работает:</b>
 1. Отправьте ссылку другу
-2. Друг переходит по ссылке и регистрируется
-3. Вы получаете +1 к счетчику приглашений
-4. При 10 приглашениях — премиум на месяц!`,
-			h.bot.Self.UserName, referralCode, stats.TotalReferrals, stats.CompletedReferrals, stats.PendingReferrals, premiumStatus)
+2. Друг переходит по ссылке и начинает заниматься
+3. После нескольких дней занятий друг засчитывается
+4. За каждые %d засчитанных друзей — месяц премиума!`,
+			h.self.UserName, referralCode, stats.TotalReferrals, stats.CompletedReferrals, stats.PendingReferrals,
+			rewardText, premiumStatus, policy.ReferralsPerReward)
 	} else {
 		messageText = fmt.Sprintf(`🔗 <b>Ваша реферальная ссылка</b>
 
 📱 <b>Поделитесь этой ссылкой с друзьями:</b>
 <code>https://t.me/%s?start=ref_%s</code>
 
-🎁 <b>Награда:</b>
-За <b>10 приглашенных друзей</b> вы получите <b>премиум на месяц</b>!
-<em>(только если у вас еще нет активной премиум-подписки)</em>
+%s
 
 %s
 
 💡 <b>Как это работает:</b>
 1. Отправьте ссылку другу
-2. Друг переходит по ссылку и регистрируется
-3. Вы получаете +1 к счетчику приглашений
-4. При 10 приглашениях — премиум на месяц!`,
-			h.bot.Self.UserName, referralCode, premiumStatus)
+2. Друг переходит по ссылке и начинает заниматься
+3. После нескольких дней занятий друг засчитывается
+4. За каждые %d засчитанных друзей — месяц премиума!`,
+			h.self.UserName, referralCode, rewardText, premiumStatus, policy.ReferralsPerReward)
 	}
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, messageText)
 	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📷 Получить QR", "referral_qr"),
+		),
+	)
 
-	_, err = h.bot.Send(msg)
+	_, err = h.sender.Send(msg)
 	return err
 }
 
@@ -2640,18 +3002,15 @@ func (h *Handler) hideUsername(username string) string {
 }
 
 // handleTTSCallback обрабатывает запрос на озвучку текста
-func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User, textID string) error {
-	h.logger.Info("обработка TTS callback", zap.String("text_id", textID))
-
-	// Получаем текст из кэша
-	h.ttsCacheMutex.RLock()
-	text, exists := h.ttsTextCache[textID]
-	h.ttsCacheMutex.RUnlock()
+func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User, textID string, speed float64) error {
+	h.logger.Info("обработка TTS callback", zap.String("text_id", textID), zap.Float64("speed", speed))
 
+	// Получаем текст по токену из callback
+	entry, exists := h.ttsTexts.get(textID)
 	if !exists {
 		h.logger.Error("текст не найден в кэше",
 			zap.String("text_id", textID),
-			zap.Int("cache_size", len(h.ttsTextCache)))
+			zap.Int("cache_size", h.ttsTexts.size()))
 
 		// Показываем пользователю более информативное сообщение
 		msg := tgbotapi.NewCallback(callback.ID, "❌ Текст для озвучки устарел. Попробуйте снова.")
@@ -2659,11 +3018,23 @@ func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.Call
 		return nil
 	}
 
-	// Удаляем текст из кэша после использования
-	h.ttsCacheMutex.Lock()
-	delete(h.ttsTextCache, textID)
-	h.ttsCacheMutex.Unlock()
+	// Токен выдан другому пользователю: чужой текст не озвучиваем
+	if entry.userID != user.ID {
+		h.logger.Warn("попытка озвучить чужой текст",
+			zap.String("text_id", textID),
+			zap.Int64("user_id", user.ID))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, "❌ Эта кнопка озвучки не для тебя"))
+		return nil
+	}
+	text := entry.text
 
+	// Озвучка может быть доступна только по премиуму
+	if !h.featureEnabled(user, premium.FeatureVoiceReplies) {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, "🔊 Озвучка доступна в премиуме"))
+		return h.sendMessage(callback.Message.Chat.ID, premiumFeatureHint(premium.FeatureVoiceReplies))
+	}
+
+	// Текст остаётся в хранилище: по нему работает кнопка замедленной озвучки
 	h.logger.Info("текст найден в кэше", zap.String("text", text))
 
 	// Проверяем, что TTS сервис доступен
@@ -2678,7 +3049,7 @@ func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.Call
 	h.bot.Request(msg)
 
 	// Генерируем аудио
-	audioData, err := h.ttsService.SynthesizeText(ctx, text)
+	audioData, err := h.ttsService.SynthesizeTextWithSpeed(ctx, text, speed)
 	if err != nil {
 		h.logger.Error("ошибка генерации TTS", zap.Error(err))
 		msg := tgbotapi.NewCallback(callback.ID, "❌ Ошибка генерации аудио")
@@ -2692,10 +3063,15 @@ func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.Call
 		Bytes: audioData,
 	})
 	// Очищаем текст от HTML тегов для заголовка
-	cleanText := h.stripHTMLTags(text)
+	cleanText := postProcessText(text, plainTextOptions)
 	audio.Caption = "🔊 Озвучка: " + cleanText
+	if speed < tts.NormalSpeed {
+		audio.Caption = "🐢 Медленная озвучка: " + cleanText
+	} else {
+		audio.ReplyMarkup = slowTTSKeyboard(textID)
+	}
 
-	if _, err := h.bot.Send(audio); err != nil {
+	if _, err := h.sender.Send(audio); err != nil {
 		h.logger.Error("ошибка отправки аудио", zap.Error(err))
 		return err
 	}
@@ -2704,56 +3080,63 @@ func (h *Handler) handleTTSCallback(ctx context.Context, callback *tgbotapi.Call
 	return nil
 }
 
-// createTTSButton создает кнопку для озвучки текста
-func (h *Handler) createTTSButton(text string) tgbotapi.InlineKeyboardButton {
-	// Очищаем текст от HTML тегов для озвучки
-	cleanText := h.stripHTMLTags(text)
+const (
+	// ttsCallbackPrefix — префикс callback для озвучки
+	ttsCallbackPrefix = "tts_"
+	// ttsSlowCallbackPrefix — префикс callback для замедленной озвучки
+	ttsSlowCallbackPrefix = "tts_slow_"
+)
 
-	// Создаем уникальный ID для текста
-	textID := fmt.Sprintf("%d", time.Now().UnixNano())
+// slowTTSKeyboard создает кнопку повторной озвучки в замедленном темпе.
+// В callback передается только ID текста, сам текст хранится на сервере.
+func slowTTSKeyboard(textID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🐢 Медленнее", ttsSlowCallbackPrefix+textID),
+		),
+	)
+}
 
-	// Сохраняем оригинальный текст в кэше
-	h.ttsCacheMutex.Lock()
-	h.ttsTextCache[textID] = cleanText
-	h.ttsCacheMutex.Unlock()
+// createTTSButton создает кнопку для озвучки текста пользователя userID
+func (h *Handler) createTTSButton(userID int64, text string) (tgbotapi.InlineKeyboardButton, error) {
+	// Очищаем текст от HTML тегов для озвучки
+	cleanText := stripHTML(text)
 
-	// Используем короткий ID в callback data
-	callbackData := "tts_" + textID
+	// Текст хранится на сервере, в callback data передаем только короткий токен:
+	// Telegram ограничивает callback_data 64 байтами
+	token, err := h.ttsTexts.put(userID, cleanText)
+	if err != nil {
+		return tgbotapi.InlineKeyboardButton{}, err
+	}
 
-	return tgbotapi.NewInlineKeyboardButtonData("🔊 Озвучить", callbackData)
+	return tgbotapi.NewInlineKeyboardButtonData("🔊 Озвучить", ttsCallbackPrefix+token), nil
 }
 
-// sendMessageWithTTS отправляет сообщение с кнопкой озвучки (если TTS включен)
-func (h *Handler) sendMessageWithTTS(chatID int64, text string) error {
+// sendMessageWithTTS отправляет сообщение с кнопкой озвучки (если TTS включен и озвучка
+// доступна пользователю). extraRows добавляются под кнопкой озвучки.
+func (h *Handler) sendMessageWithTTS(chatID int64, user *models.User, text string, extraRows ...[]tgbotapi.InlineKeyboardButton) error {
 	h.logger.Info("🔍 sendMessageWithTTS вызван", zap.String("text", text), zap.Bool("tts_enabled", h.ttsService != nil))
 
-	// Если TTS отключен, отправляем обычное сообщение
-	if h.ttsService == nil {
-		h.logger.Info("🔍 TTS отключен, отправляем обычное сообщение")
+	rows := h.ttsRows(user, text, extraRows...)
+	if len(rows) == 0 {
 		return h.sendMessage(chatID, text)
 	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	// Извлекаем английский текст из ответа AI
-	englishText := h.extractEnglishText(text)
-	h.logger.Info("🔍 extractEnglishText результат", zap.String("original", text), zap.String("extracted", englishText))
-	if englishText == "" {
-		// Если английского текста нет, отправляем обычное сообщение
-		h.logger.Info("🔍 Английский текст не найден, отправляем обычное сообщение")
-		return h.sendMessage(chatID, text)
+	// Кнопки прикрепляются к последней части длинного сообщения
+	parts := PostProcess(text, sendOptions)
+	for _, part := range parts[:len(parts)-1] {
+		if err := h.sendSafePart(chatID, part, true); err != nil {
+			return err
+		}
 	}
 
-	// Создаем кнопку озвучки
-	ttsButton := h.createTTSButton(englishText)
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(ttsButton),
-	)
-
-	// Отправляем сообщение с кнопкой
-	msg := tgbotapi.NewMessage(chatID, text)
+	// Отправляем сообщение с кнопками
+	msg := tgbotapi.NewMessage(chatID, parts[len(parts)-1])
 	msg.ReplyMarkup = keyboard
 	msg.ParseMode = "HTML"
 
-	if _, err := h.bot.Send(msg); err != nil {
+	if _, err := h.sender.Send(msg); err != nil {
 		h.logger.Error("ошибка отправки сообщения с TTS", zap.Error(err))
 		return err
 	}
@@ -2761,6 +3144,30 @@ func (h *Handler) sendMessageWithTTS(chatID int64, text string) error {
 	return nil
 }
 
+// ttsRows кнопка озвучки английской части текста (если TTS включен и озвучка доступна
+// пользователю) и extraRows под ней
+func (h *Handler) ttsRows(user *models.User, text string, extraRows ...[]tgbotapi.InlineKeyboardButton) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if h.ttsService == nil {
+		h.logger.Info("🔍 TTS отключен, отправляем сообщение без озвучки")
+	} else if !h.featureEnabled(user, premium.FeatureVoiceReplies) {
+		h.logger.Debug("озвучка доступна только по премиуму, кнопка не показывается")
+	} else if englishText := h.extractEnglishText(text); englishText != "" {
+		h.logger.Info("🔍 extractEnglishText результат", zap.String("original", text), zap.String("extracted", englishText))
+		// Создаем кнопку озвучки
+		if button, err := h.createTTSButton(user.ID, englishText); err != nil {
+			h.logger.Error("ошибка создания кнопки озвучки", zap.Error(err))
+		} else {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+		}
+	} else {
+		h.logger.Info("🔍 Английский текст не найден, отправляем сообщение без озвучки")
+	}
+
+	return append(rows, extraRows...)
+}
+
 // extractEnglishText извлекает английский текст из ответа AI
 func (h *Handler) extractEnglishText(text string) string {
 	h.logger.Info("🔍 extractEnglishText вызван", zap.String("text", text))
@@ -2769,14 +3176,14 @@ func (h *Handler) extractEnglishText(text string) string {
 	lines := strings.Split(text, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		// Пропускаем пустые строки и строки с эмодзи флагами
-		if line == "" || strings.Contains(line, "🇷🇺") || strings.Contains(line, "🇺🇸") {
+		// Пропускаем пустые строки, строки с эмодзи флагами и исправление фразы ученика
+		if line == "" || strings.Contains(line, "🇷🇺") || strings.Contains(line, "🇺🇸") || strings.HasPrefix(line, correctionMark) {
 			continue
 		}
 		// Если строка содержит английские буквы, возвращаем её
 		if h.containsEnglish(line) {
 			// Дополнительная проверка: строка должна содержать больше английских букв чем русских
-			if h.isEnglishMessage(line) {
+			if isTargetLanguage(line, models.LanguageEnglish) {
 				h.logger.Info("🔍 Найден английский текст в строке", zap.String("line", line))
 				return line
 			}
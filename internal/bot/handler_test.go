@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestStartCommandSendsWelcome(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/start")
+
+	u := th.user(t, 100)
+	texts := th.sender.texts()
+	if len(texts) == 0 {
+		t.Fatal("ожидалось приветствие, сообщений не отправлено")
+	}
+	want := th.handler.messages.Welcome(u.FirstName, th.handler.getLevelText(u.Level), u.XP)
+	if texts[0] != want {
+		t.Errorf("ожидалось приветствие %q, получено %q", want, texts[0])
+	}
+	if len(th.ai.calls) != 0 {
+		t.Errorf("на /start AI не вызывается, получено %d запросов", len(th.ai.calls))
+	}
+}
+
+func TestEnglishMessageGetsAIReply(t *testing.T) {
+	reply := "Green tea is great! What do you like about it?\n\n(Зеленый чай — это здорово! Что тебе в нем нравится?)"
+	th := newTestHarness(t, reply)
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	if len(th.ai.calls) != 1 {
+		t.Fatalf("ожидался 1 запрос к AI, получено %d", len(th.ai.calls))
+	}
+	request := th.ai.calls[0]
+	if last := request[len(request)-1]; last.Role != "user" || last.Content != "I like green tea very much" {
+		t.Errorf("последним в запросе ожидалось сообщение ученика, получено %+v", last)
+	}
+
+	found := false
+	for _, text := range th.sender.texts() {
+		if strings.Contains(text, "Green tea is great!") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ответ AI не отправлен пользователю, отправлено: %q", th.sender.texts())
+	}
+
+	u := th.user(t, 100)
+	if u.MessagesCount != 1 {
+		t.Errorf("ожидался счетчик сообщений 1, получено %d", u.MessagesCount)
+	}
+}
+
+func TestLevelTestRound(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, menuButtons[ActionStartTest].Text)
+
+	u := th.user(t, 100)
+	if u.CurrentState != models.StateInLevelTest {
+		t.Fatalf("ожидалось состояние %q, получено %q", models.StateInLevelTest, u.CurrentState)
+	}
+	levelTest := th.handler.activeLevelTests[u.ID]
+	if levelTest == nil {
+		t.Fatal("тест уровня не создан")
+	}
+	first, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(first.Text, "Вопрос 1 из") {
+		t.Fatalf("ожидался первый вопрос, получено %#v", th.sender.last())
+	}
+
+	// Отвечаем правильно на первый вопрос
+	question := levelTest.Questions[0]
+	th.sender.reset()
+	th.pressButton(t, 100, testAnswerCallback(question.CorrectAnswer))
+
+	if levelTest.Score != question.Points {
+		t.Errorf("ожидалось %d очков, получено %d", question.Points, levelTest.Score)
+	}
+	texts := th.sender.texts()
+	if len(texts) != 2 {
+		t.Fatalf("ожидались отметка ответа и следующий вопрос, получено %q", texts)
+	}
+	if !strings.Contains(texts[0], "Правильно") {
+		t.Errorf("ожидалась отметка правильного ответа, получено %q", texts[0])
+	}
+	if !strings.Contains(texts[1], "Вопрос 2 из") {
+		t.Errorf("ожидался второй вопрос, получено %q", texts[1])
+	}
+}
+
+func TestRateLimitWarningSentOncePerWindow(t *testing.T) {
+	th := newTestHarness(t)
+
+	for i := 0; i < MaxRequestsPerMinute+20; i++ {
+		th.sendText(t, 100, "/help")
+	}
+
+	warnings := 0
+	for _, text := range th.sender.texts() {
+		if strings.Contains(text, "Слишком много запросов") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("ожидалось одно предупреждение о лимите, получено %d", warnings)
+	}
+}
+
+func TestRateLimiterWarningCooldown(t *testing.T) {
+	rl := NewRateLimiter()
+	if !rl.ShouldWarn(1) {
+		t.Fatal("первое предупреждение должно отправляться")
+	}
+	if rl.ShouldWarn(1) {
+		t.Error("повторное предупреждение в течение интервала не ожидалось")
+	}
+	if !rl.ShouldWarn(2) {
+		t.Error("интервал считается отдельно для каждого пользователя")
+	}
+
+	rl.SetWarningCooldown(0)
+	if !rl.ShouldWarn(1) {
+		t.Error("без интервала предупреждение отправляется каждый раз")
+	}
+}
+
+func TestRateLimiterEvictsInactiveUsers(t *testing.T) {
+	rl := NewRateLimiter()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	for userID := int64(1); userID <= 5; userID++ {
+		rl.IsAllowed(userID)
+	}
+	if got := rl.trackedUsers(); got != 5 {
+		t.Fatalf("ожидалось 5 пользователей, получено %d", got)
+	}
+
+	now = now.Add(RateLimitWindow)
+	if !rl.IsAllowed(6) {
+		t.Fatal("новый пользователь должен проходить лимит")
+	}
+	if got := rl.trackedUsers(); got != 1 {
+		t.Errorf("после окна должны остаться только активные пользователи, получено %d", got)
+	}
+}
+
+func TestRateLimiterCapsTrackedUsers(t *testing.T) {
+	rl := NewRateLimiter()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	// Все пользователи активны в пределах окна: очистка их не удалит
+	for userID := int64(1); userID <= MaxRateLimitedUsers+10; userID++ {
+		if !rl.IsAllowed(userID) {
+			t.Fatalf("первый запрос пользователя %d должен проходить", userID)
+		}
+	}
+	if got := rl.trackedUsers(); got != MaxRateLimitedUsers {
+		t.Errorf("ожидалось не больше %d пользователей, получено %d", MaxRateLimitedUsers, got)
+	}
+}
+
+func TestRateLimiterReset(t *testing.T) {
+	rl := NewRateLimiter()
+	for range MaxRequestsPerMinute {
+		rl.IsAllowed(1)
+	}
+	if rl.IsAllowed(1) {
+		t.Fatal("сверх лимита запрос должен отклоняться")
+	}
+
+	rl.Reset(1)
+	if !rl.IsAllowed(1) {
+		t.Error("после сброса запросы пользователя должны проходить")
+	}
+}
+
+func TestChatHistoryQueriedOncePerMessage(t *testing.T) {
+	th := newTestHarness(t,
+		"<b>Hello! I'm fine.</b>\n\n<tg-spoiler>🇷🇺 Привет! У меня все хорошо.</tg-spoiler>",
+	)
+	th.handler.SetChatHistoryLimit(4)
+	th.sendText(t, 100, "/start")
+
+	calls := func() int {
+		th.store.messages.mu.Lock()
+		defer th.store.messages.mu.Unlock()
+		return th.store.messages.historyCalls
+	}
+
+	// Контекст диалога пуст: история берется из базы один раз
+	before := calls()
+	th.sendText(t, 100, "Привет, как у тебя дела?")
+	if got := calls() - before; got != 1 {
+		t.Errorf("ожидался 1 запрос истории на сообщение, получено %d", got)
+	}
+
+	// Контекст уже в памяти: база не нужна
+	before = calls()
+	th.sendText(t, 100, "Расскажи что-нибудь интересное")
+	th.sendText(t, 100, "I am fine, thank you very much")
+	if got := calls() - before; got != 0 {
+		t.Errorf("при заполненном контексте история не запрашивается, получено %d запросов", got)
+	}
+}
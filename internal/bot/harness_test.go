@@ -0,0 +1,521 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/message"
+	"lingua-ai/internal/metrics"
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/referral"
+	"lingua-ai/internal/store"
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeSender отправитель Telegram, который запоминает исходящие сообщения вместо отправки
+type fakeSender struct {
+	mu       sync.Mutex
+	sent     []tgbotapi.Chattable
+	requests []tgbotapi.Chattable
+	nextID   int
+}
+
+func (s *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, c)
+	s.nextID++
+	return tgbotapi.Message{MessageID: s.nextID, Chat: &tgbotapi.Chat{ID: chatIDOf(c)}}, nil
+}
+
+func (s *fakeSender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+// texts возвращает тексты отправленных и отредактированных сообщений
+func (s *fakeSender) texts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var texts []string
+	for _, c := range s.sent {
+		switch m := c.(type) {
+		case tgbotapi.MessageConfig:
+			texts = append(texts, m.Text)
+		case tgbotapi.EditMessageTextConfig:
+			texts = append(texts, m.Text)
+		}
+	}
+	return texts
+}
+
+// callbacks возвращает данные inline-кнопок отправленных и отредактированных сообщений
+func (s *fakeSender) callbacks() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var data []string
+	for _, c := range s.sent {
+		var keyboard *tgbotapi.InlineKeyboardMarkup
+		switch m := c.(type) {
+		case tgbotapi.MessageConfig:
+			if markup, ok := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+				keyboard = &markup
+			}
+		case tgbotapi.EditMessageTextConfig:
+			keyboard = m.ReplyMarkup
+		}
+		if keyboard == nil {
+			continue
+		}
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				if button.CallbackData != nil {
+					data = append(data, *button.CallbackData)
+				}
+			}
+		}
+	}
+	return data
+}
+
+// last возвращает последнее отправленное сообщение
+func (s *fakeSender) last() tgbotapi.Chattable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sent) == 0 {
+		return nil
+	}
+	return s.sent[len(s.sent)-1]
+}
+
+// reset забывает отправленные сообщения
+func (s *fakeSender) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+	s.requests = nil
+}
+
+// fakeAI AI клиент с заготовленными ответами; после последнего повторяет его
+type fakeAI struct {
+	mu        sync.Mutex
+	responses []string
+	calls     [][]ai.Message
+	stream    bool // отдавать ответы частями по словам
+	truncate  bool // обрывать поток ошибкой после первой половины ответа
+}
+
+func (f *fakeAI) GenerateResponse(ctx context.Context, messages []ai.Message, options ai.GenerationOptions) (*ai.Response, error) {
+	content, err := f.next(messages)
+	if err != nil {
+		return nil, err
+	}
+	return &ai.Response{Content: content}, nil
+}
+
+func (f *fakeAI) GenerateResponseStream(ctx context.Context, messages []ai.Message, options ai.GenerationOptions) (<-chan ai.StreamChunk, error) {
+	f.mu.Lock()
+	stream, truncate := f.stream, f.truncate
+	f.mu.Unlock()
+	if !stream {
+		return nil, ai.ErrStreamingUnsupported
+	}
+
+	content, err := f.next(messages)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.SplitAfter(content, " ")
+	chunks := make(chan ai.StreamChunk, len(words)+1)
+	if truncate {
+		words = words[:len(words)/2]
+	}
+	for _, word := range words {
+		chunks <- ai.StreamChunk{Content: word}
+	}
+	if truncate {
+		chunks <- ai.StreamChunk{Err: fmt.Errorf("поток закончился без события [DONE]")}
+	}
+	close(chunks)
+	return chunks, nil
+}
+
+// next запоминает запрос и возвращает очередной заготовленный ответ
+func (f *fakeAI) next(messages []ai.Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, messages)
+	if len(f.responses) == 0 {
+		return "", fmt.Errorf("нет заготовленного ответа")
+	}
+	content := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+	return content, nil
+}
+
+func (f *fakeAI) GetName() string { return "fake" }
+
+// memoryUsers хранилище пользователей в памяти; остальные методы не нужны тестам обработчика
+type memoryUsers struct {
+	store.UserRepository
+	mu     sync.Mutex
+	users  map[int64]*models.User
+	nextID int64
+
+	exerciseAnswers int // сколько ответов на упражнения учтено
+}
+
+func (r *memoryUsers) Create(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	u.ID = r.nextID
+	u.CreatedAt = time.Now()
+	if u.MaxMessages == 0 {
+		u.MaxMessages = premium.DefaultFreeMessageLimit // значение по умолчанию колонки max_messages
+	}
+	stored := *u
+	r.users[u.ID] = &stored
+	return nil
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", store.ErrUserNotFound, id)
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *memoryUsers) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.TelegramID == telegramID {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: telegram_id %d", store.ErrUserNotFound, telegramID)
+}
+
+func (r *memoryUsers) Update(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *u
+	r.users[u.ID] = &stored
+	return nil
+}
+
+func (r *memoryUsers) UpdateState(ctx context.Context, userID int64, state string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].CurrentState = state
+	return nil
+}
+
+func (r *memoryUsers) UpdateLastSeen(ctx context.Context, userID int64) error { return nil }
+
+func (r *memoryUsers) UpdateStudyActivity(ctx context.Context, userID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	if u.StudyStreak == 0 {
+		u.StudyStreak = 1
+	}
+	return u.StudyStreak, nil
+}
+
+func (r *memoryUsers) AddXP(ctx context.Context, userID int64, xp int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].XP += xp
+	return nil
+}
+
+func (r *memoryUsers) IncrementMessagesCount(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].MessagesCount++
+	return nil
+}
+
+func (r *memoryUsers) RecordExerciseAnswer(ctx context.Context, userID int64, correct bool, window int) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exerciseAnswers++
+	return r.exerciseAnswers % window, 0, nil
+}
+
+func (r *memoryUsers) SetInitialLevel(ctx context.Context, userID int64, level, assessment string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	if u.LevelSelectedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	u.Level = level
+	u.LevelAssessment = assessment
+	u.LevelSelectedAt = &now
+	return true, nil
+}
+
+func (r *memoryUsers) SetLevelAssessment(ctx context.Context, userID int64, assessment string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].LevelAssessment = assessment
+	return nil
+}
+
+func (r *memoryUsers) SetLearningLanguage(ctx context.Context, userID int64, language string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].LearningLanguage = language
+	return nil
+}
+
+func (r *memoryUsers) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].RemindersEnabled = enabled
+	return nil
+}
+
+func (r *memoryUsers) SetFlashcardCategory(ctx context.Context, userID int64, category string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].FlashcardCategory = category
+	return nil
+}
+
+func (r *memoryUsers) SetTimezone(ctx context.Context, userID int64, timezone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].Timezone = timezone
+	return nil
+}
+
+func (r *memoryUsers) GetTopUsersByStreak(ctx context.Context, limit int) ([]*models.User, error) {
+	return r.top(limit, func(a, b *models.User) bool { return a.XP > b.XP }), nil
+}
+
+func (r *memoryUsers) GetTopUsersByStreakOnly(ctx context.Context, limit int) ([]*models.User, error) {
+	return r.top(limit, func(a, b *models.User) bool {
+		if a.StudyStreak != b.StudyStreak {
+			return a.StudyStreak > b.StudyStreak
+		}
+		return a.XP > b.XP
+	}), nil
+}
+
+// top копии пользователей в порядке less, не больше limit
+func (r *memoryUsers) top(limit int, less func(a, b *models.User) bool) []*models.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*models.User
+	for _, u := range r.users {
+		copied := *u
+		users = append(users, &copied)
+	}
+	sort.Slice(users, func(i, j int) bool { return less(users[i], users[j]) })
+	return users[:min(limit, len(users))]
+}
+
+// memoryMessages история сообщений в памяти
+type memoryMessages struct {
+	store.MessageRepository
+	mu           sync.Mutex
+	messages     []models.UserMessage
+	historyCalls int // сколько раз запрашивалась история
+}
+
+func (r *memoryMessages) Create(ctx context.Context, msg *models.UserMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.ID = int64(len(r.messages) + 1)
+	msg.CreatedAt = time.Now()
+	r.messages = append(r.messages, *msg)
+	return nil
+}
+
+func (r *memoryMessages) CreateWithCleanup(ctx context.Context, msg *models.UserMessage) error {
+	return r.Create(ctx, msg)
+}
+
+func (r *memoryMessages) DeleteByUserID(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.messages[:0]
+	for _, msg := range r.messages {
+		if msg.UserID != userID {
+			kept = append(kept, msg)
+		}
+	}
+	r.messages = kept
+	return nil
+}
+
+func (r *memoryMessages) GetChatHistory(ctx context.Context, userID int64, limit int) (*models.ChatHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.historyCalls++
+	history := &models.ChatHistory{}
+	for _, msg := range r.messages {
+		if msg.UserID == userID {
+			history.Messages = append(history.Messages, msg)
+		}
+	}
+	if len(history.Messages) > limit {
+		history.Messages = history.Messages[len(history.Messages)-limit:]
+	}
+	return history, nil
+}
+
+func (r *memoryMessages) GetMessagesByDateRange(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.UserMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []models.UserMessage
+	for _, msg := range r.messages {
+		if msg.UserID == userID && !msg.CreatedAt.Before(from) && !msg.CreatedAt.After(to) && len(messages) < limit {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// memoryStore хранилище для тестов обработчика; неиспользуемые репозитории отсутствуют
+type memoryStore struct {
+	store.Store
+	users    *memoryUsers
+	messages *memoryMessages
+}
+
+func (s *memoryStore) User() store.UserRepository         { return s.users }
+func (s *memoryStore) Message() store.MessageRepository   { return s.messages }
+func (s *memoryStore) WordPack() store.WordPackRepository { return nil }
+
+// testMetrics метрики создаются один раз: повторная регистрация в Prometheus паникует
+var testMetrics = sync.OnceValue(func() *metrics.Metrics {
+	return metrics.New(zap.NewNop())
+})
+
+// testHarness обработчик с фейковым Telegram, AI и хранилищем в памяти
+type testHarness struct {
+	handler *Handler
+	sender  *fakeSender
+	ai      *fakeAI
+	store   *memoryStore
+}
+
+// newTestHarness собирает обработчик без сети; responses — ответы AI по порядку
+func newTestHarness(t *testing.T, responses ...string) *testHarness {
+	t.Helper()
+
+	logger := zaptest.NewLogger(t)
+	sender := &fakeSender{}
+	aiClient := &fakeAI{responses: responses}
+	memStore := &memoryStore{
+		users:    &memoryUsers{users: make(map[int64]*models.User)},
+		messages: &memoryMessages{},
+	}
+
+	m := testMetrics()
+	h := NewHandler(
+		sender,
+		user.NewService(memStore, logger),
+		message.NewService(memStore, logger),
+		aiClient,
+		nil,
+		nil,
+		logger,
+		m,
+		m,
+		premium.NewService(memStore.users, nil, nil, logger),
+		referral.NewService(nil, memStore.users, logger),
+		flashcards.NewService(nil, flashcards.DefaultSpacedRepetitionConfig, logger),
+		memStore,
+	)
+	h.sender.sleep = func(time.Duration) {}
+	h.pause = func(time.Duration) {}
+
+	return &testHarness{handler: h, sender: sender, ai: aiClient, store: memStore}
+}
+
+// sendText имитирует сообщение пользователя в личном чате
+func (th *testHarness) sendText(t *testing.T, telegramID int64, text string) {
+	t.Helper()
+
+	msg := &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: telegramID, FirstName: "Test"},
+		Chat:      &tgbotapi.Chat{ID: telegramID, Type: "private"},
+		Text:      text,
+		Date:      int(time.Now().Unix()),
+	}
+	if len(text) > 0 && text[0] == '/' {
+		end := len(text)
+		for i, r := range text {
+			if r == ' ' {
+				end = i
+				break
+			}
+		}
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: end}}
+	}
+
+	if err := th.handler.HandleUpdate(context.Background(), tgbotapi.Update{Message: msg}); err != nil {
+		t.Fatalf("ошибка обработки сообщения %q: %v", text, err)
+	}
+}
+
+// pressButton имитирует нажатие inline-кнопки под сообщением бота
+func (th *testHarness) pressButton(t *testing.T, telegramID int64, data string) {
+	t.Helper()
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:   "callback",
+		From: &tgbotapi.User{ID: telegramID, FirstName: "Test"},
+		Message: &tgbotapi.Message{
+			MessageID: 1,
+			Chat:      &tgbotapi.Chat{ID: telegramID, Type: "private"},
+		},
+		Data: data,
+	}
+
+	if err := th.handler.HandleUpdate(context.Background(), tgbotapi.Update{CallbackQuery: callback}); err != nil {
+		t.Fatalf("ошибка обработки кнопки %q: %v", data, err)
+	}
+}
+
+// user возвращает сохраненного пользователя по Telegram ID
+func (th *testHarness) user(t *testing.T, telegramID int64) *models.User {
+	t.Helper()
+
+	u, err := th.store.users.GetByTelegramID(context.Background(), telegramID)
+	if err != nil {
+		t.Fatalf("пользователь %d не найден: %v", telegramID, err)
+	}
+	return u
+}
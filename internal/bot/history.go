@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/message"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// historyPeriods периоды, за которые можно выгрузить диалог командой /history
+var historyPeriods = map[string]time.Duration{
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// Параметры выгрузки диалога
+const (
+	defaultHistoryPeriod = "7d"                             // период /history без аргументов
+	historyLimit         = message.MaxHistoryExportMessages // больше сообщений не выгружаем за раз
+)
+
+// SetStoredMessagesLimit задает, сколько сообщений пользователя хранится в БД (MAX_STORED_MESSAGES).
+// /history и /review предупреждают, когда более старые сообщения уже удалены.
+func (h *Handler) SetStoredMessagesLimit(limit int) {
+	h.storedMessages = limit
+}
+
+// parseHistoryPeriod разбирает аргумент /history: пусто, 7d или 30d
+func parseHistoryPeriod(args string) (time.Duration, bool) {
+	args = strings.ToLower(strings.TrimSpace(args))
+	if args == "" {
+		args = defaultHistoryPeriod
+	}
+	period, ok := historyPeriods[args]
+	return period, ok
+}
+
+// handleHistoryCommand обрабатывает команду /history [7d|30d] — выгрузка диалога за период
+func (h *Handler) handleHistoryCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+
+	period, ok := parseHistoryPeriod(message.CommandArguments())
+	if !ok {
+		return h.sendMessage(chatID, "📜 Укажите период: <code>/history 7d</code> или <code>/history 30d</code>")
+	}
+
+	to := time.Now()
+	messages, err := h.messageService.GetMessagesByDateRange(ctx, user.ID, to.Add(-period), to, historyLimit)
+	if err != nil {
+		h.logger.Error("ошибка получения истории диалога", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось получить историю диалога")
+	}
+
+	return h.sendMessage(chatID, formatHistory(messages, int(period.Hours()/24), h.storedMessages))
+}
+
+// formatHistory оформляет диалог за период; системные сообщения не показываются.
+// stored — сколько сообщений хранится в БД (0 — без ограничения): если выгрузка
+// упирается в этот предел, более ранние сообщения периода уже удалены.
+func formatHistory(messages []models.UserMessage, days, stored int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📜 <b>Диалог за %d дн.</b>\n", days)
+
+	shown := 0
+	for _, msg := range messages {
+		var author string
+		switch msg.Role {
+		case models.RoleUser:
+			author = "🧑 Ты"
+		case models.RoleAssistant:
+			author = "🤖 LinguaAI"
+		default:
+			continue
+		}
+		fmt.Fprintf(&b, "\n<b>%s</b> <i>%s</i>\n%s\n", author, msg.CreatedAt.Format("02.01 15:04"), html.EscapeString(msg.Content))
+		shown++
+	}
+
+	if shown == 0 {
+		return "📜 За этот период сообщений нет. Напиши мне что-нибудь на английском!"
+	}
+	switch {
+	case stored > 0 && stored < historyLimit && len(messages) >= stored:
+		fmt.Fprintf(&b, "\n<i>Я храню только последние %d сообщений, более ранние за этот период не сохранились.</i>", stored)
+	case len(messages) >= historyLimit:
+		fmt.Fprintf(&b, "\n<i>Показаны первые %d сообщений за период.</i>", historyLimit)
+	}
+	return b.String()
+}
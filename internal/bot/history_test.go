@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestParseHistoryPeriod(t *testing.T) {
+	tests := []struct {
+		args string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 7 * 24 * time.Hour, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{" 30D ", 30 * 24 * time.Hour, true},
+		{"5d", 0, false},
+		{"week", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseHistoryPeriod(tt.args)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%q: ожидалось %v (ok=%v), получено %v (ok=%v)", tt.args, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestFormatHistorySkipsSystemAndEscapes(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	text := formatHistory([]models.UserMessage{
+		{Role: models.RoleSystem, Content: "секретная инструкция", CreatedAt: at},
+		{Role: models.RoleUser, Content: "Is 2 < 3?", CreatedAt: at},
+		{Role: models.RoleAssistant, Content: "Yes!", CreatedAt: at},
+	}, 7, 0)
+
+	for _, want := range []string{"Is 2 &lt; 3?", "Yes!", "16.10 09:30"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("ожидалось %q в %q", want, text)
+		}
+	}
+	if strings.Contains(text, "секретная") {
+		t.Error("системные сообщения не должны попадать в историю")
+	}
+}
+
+func TestFormatHistoryStatesStoredLimit(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	messages := []models.UserMessage{
+		{Role: models.RoleUser, Content: "Hi", CreatedAt: at},
+		{Role: models.RoleAssistant, Content: "Hello!", CreatedAt: at},
+	}
+
+	if text := formatHistory(messages, 30, 2); !strings.Contains(text, "храню только последние 2 сообщений") {
+		t.Errorf("при упоре в MAX_STORED_MESSAGES ожидалось предупреждение, получено %q", text)
+	}
+	if text := formatHistory(messages, 30, 200); strings.Contains(text, "храню только") {
+		t.Errorf("история короче предела, предупреждение не нужно: %q", text)
+	}
+}
+
+func TestHistoryCommandSendsDialog(t *testing.T) {
+	th := newTestHarness(t, "Nice to meet you too!")
+
+	th.sendText(t, 100, "Nice to meet you")
+	th.sender.reset()
+	th.sendText(t, 100, "/history 30d")
+
+	texts := th.sender.texts()
+	if len(texts) == 0 {
+		t.Fatal("ожидалась выгрузка диалога")
+	}
+	got := strings.Join(texts, "\n")
+	if !strings.Contains(got, "Nice to meet you") || !strings.Contains(got, "Nice to meet you too!") {
+		t.Errorf("ожидались сообщения диалога, получено %q", got)
+	}
+}
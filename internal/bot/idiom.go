@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Параметры разбора идиом
+const (
+	idiomCallbackPrefix = "idiom_save_"
+	maxIdiomRunes       = 80  // длиннее — уже не идиома, а текст для перевода
+	idiomCacheSize      = 500 // сколько разборов помнить
+	idiomCacheTTL       = 7 * 24 * time.Hour
+	maxIdiomExamples    = 3
+)
+
+// idiomExplanation разбор идиомы в ответе AI
+type idiomExplanation struct {
+	Idiom       string   `json:"idiom"`
+	IsIdiom     bool     `json:"is_idiom"`
+	Meaning     string   `json:"meaning"`
+	Translation string   `json:"translation"`
+	Origin      string   `json:"origin"`
+	Register    string   `json:"register"`
+	Examples    []string `json:"examples"`
+}
+
+// idiomCacheEntry разбор идиомы и токен для кнопки сохранения
+type idiomCacheEntry struct {
+	explanation idiomExplanation
+	token       string
+	createdAt   time.Time
+}
+
+// idiomCache хранит разборы частых идиом, чтобы не спрашивать AI повторно
+type idiomCache struct {
+	mu      sync.Mutex
+	entries map[string]*idiomCacheEntry // по нормализованной фразе
+	tokens  map[string]string           // токен кнопки -> нормализованная фраза
+	seq     uint64
+	now     func() time.Time
+}
+
+// newIdiomCache создает кэш разборов идиом
+func newIdiomCache() *idiomCache {
+	return &idiomCache{
+		entries: make(map[string]*idiomCacheEntry),
+		tokens:  make(map[string]string),
+		now:     time.Now,
+	}
+}
+
+// get возвращает сохраненный разбор фразы и токен для кнопки сохранения
+func (c *idiomCache) get(key string) (idiomExplanation, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.createdAt) > idiomCacheTTL {
+		return idiomExplanation{}, "", false
+	}
+	return entry.explanation, entry.token, true
+}
+
+// put сохраняет разбор и возвращает токен для кнопки сохранения.
+// Устаревшие записи удаляются, а при переполнении вытесняется самая старая.
+func (c *idiomCache) put(key string, explanation idiomExplanation) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if now.Sub(entry.createdAt) > idiomCacheTTL {
+			c.remove(k)
+		}
+	}
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= idiomCacheSize {
+		oldest := ""
+		for k, entry := range c.entries {
+			if oldest == "" || entry.createdAt.Before(c.entries[oldest].createdAt) {
+				oldest = k
+			}
+		}
+		c.remove(oldest)
+	}
+	c.remove(key)
+
+	c.seq++
+	token := strconv.FormatUint(c.seq, 36)
+	c.entries[key] = &idiomCacheEntry{explanation: explanation, token: token, createdAt: now}
+	c.tokens[token] = key
+	return token
+}
+
+// byToken возвращает разбор по токену кнопки сохранения
+func (c *idiomCache) byToken(token string) (idiomExplanation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.tokens[token]
+	if !ok {
+		return idiomExplanation{}, false
+	}
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.createdAt) > idiomCacheTTL {
+		return idiomExplanation{}, false
+	}
+	return entry.explanation, true
+}
+
+// remove удаляет запись вместе с ее токеном; вызывается под мьютексом
+func (c *idiomCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		delete(c.tokens, entry.token)
+		delete(c.entries, key)
+	}
+}
+
+// normalizeIdiom приводит фразу к ключу кэша: регистр, пробелы и знаки по краям не важны
+func normalizeIdiom(phrase string) string {
+	phrase = strings.ToLower(strings.Join(strings.Fields(phrase), " "))
+	return strings.Trim(phrase, ` .,!?;:"'«»“”`)
+}
+
+// parseIdiomExplanation разбирает JSON-ответ AI
+func parseIdiomExplanation(content string) (idiomExplanation, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return idiomExplanation{}, fmt.Errorf("в ответе нет JSON-объекта")
+	}
+
+	var explanation idiomExplanation
+	if err := json.Unmarshal([]byte(content[start:end+1]), &explanation); err != nil {
+		return idiomExplanation{}, fmt.Errorf("ошибка разбора идиомы: %w", err)
+	}
+
+	explanation.Idiom = strings.TrimSpace(explanation.Idiom)
+	explanation.Meaning = strings.TrimSpace(explanation.Meaning)
+	explanation.Translation = strings.TrimSpace(explanation.Translation)
+	if explanation.Idiom == "" || explanation.Meaning == "" {
+		return idiomExplanation{}, fmt.Errorf("в разборе идиомы нет значения")
+	}
+
+	var examples []string
+	for _, example := range explanation.Examples {
+		if example = strings.TrimSpace(example); example != "" && len(examples) < maxIdiomExamples {
+			examples = append(examples, example)
+		}
+	}
+	explanation.Examples = examples
+	return explanation, nil
+}
+
+// formatIdiomExplanation оформляет разбор идиомы для Telegram
+func formatIdiomExplanation(e idiomExplanation) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "💡 <b>%s</b>\n", html.EscapeString(e.Idiom))
+	if e.Translation != "" {
+		fmt.Fprintf(&b, "🇷🇺 <i>%s</i>\n", html.EscapeString(e.Translation))
+	}
+	if !e.IsIdiom {
+		b.WriteString("\nℹ️ Это не идиома, а обычное выражение.\n")
+	}
+
+	fmt.Fprintf(&b, "\n📖 <b>Значение:</b> %s\n", html.EscapeString(e.Meaning))
+	if e.Origin != "" {
+		fmt.Fprintf(&b, "🏛 <b>Происхождение:</b> %s\n", html.EscapeString(e.Origin))
+	}
+	if e.Register != "" {
+		fmt.Fprintf(&b, "🎭 <b>Где уместна:</b> %s\n", html.EscapeString(e.Register))
+	}
+
+	if len(e.Examples) > 0 {
+		b.WriteString("\n✏️ <b>Примеры:</b>\n")
+		for _, example := range e.Examples {
+			fmt.Fprintf(&b, "• %s\n", html.EscapeString(example))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// handleIdiomCommand обрабатывает команду /idiom <фраза> — разбор идиомы
+func (h *Handler) handleIdiomCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+	phrase := strings.TrimSpace(message.CommandArguments())
+	if phrase == "" {
+		return h.sendMessage(chatID, "💡 Напиши идиому после команды, например:\n<code>/idiom break the ice</code>")
+	}
+	if utf8.RuneCountInString(phrase) > maxIdiomRunes {
+		return h.sendMessage(chatID, fmt.Sprintf("✂️ Идиома не длиннее %d символов. Для перевода текста просто пришли его сообщением.", maxIdiomRunes))
+	}
+
+	key := normalizeIdiom(phrase)
+	explanation, token, ok := h.idioms.get(key)
+	if !ok {
+		if !h.aiAvailable() {
+			return h.sendOfflineMode(ctx, chatID, user)
+		}
+
+		var err error
+		explanation, err = h.explainIdiom(ctx, phrase)
+		if err != nil {
+			h.logger.Error("ошибка разбора идиомы", zap.Error(err), zap.Int64("user_id", user.ID))
+			return h.sendErrorMessage(chatID, "Не удалось разобрать идиому, попробуй позже")
+		}
+		token = h.idioms.put(key, explanation)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatIdiomExplanation(explanation))
+	msg.ParseMode = "HTML"
+	if explanation.Translation != "" {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📚 Сохранить в карточки", idiomCallbackPrefix+token),
+		))
+	}
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// explainIdiom запрашивает у AI разбор идиомы
+func (h *Handler) explainIdiom(ctx context.Context, phrase string) (idiomExplanation, error) {
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.prompts.GetIdiomPrompt()},
+		{Role: "user", Content: phrase},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.3,
+		MaxTokens:   600,
+	})
+	h.aiMetrics.RecordAIRequest("idiom_explanation", err == nil, time.Since(start).Seconds())
+	if err != nil {
+		return idiomExplanation{}, fmt.Errorf("ошибка генерации разбора идиомы: %w", err)
+	}
+
+	return parseIdiomExplanation(response.Content)
+}
+
+// handleIdiomSaveCallback сохраняет разобранную идиому в карточки пользователя
+func (h *Handler) handleIdiomSaveCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	explanation, ok := h.idioms.byToken(strings.TrimPrefix(callback.Data, idiomCallbackPrefix))
+	if !ok {
+		return h.sendMessage(chatID, "🤷 Этот разбор устарел. Повтори команду /idiom, чтобы сохранить идиому.")
+	}
+
+	example := ""
+	if len(explanation.Examples) > 0 {
+		example = explanation.Examples[0]
+	}
+
+	added, err := h.flashcardHandler.flashcardService.SaveIdiom(ctx, user.ID, explanation.Idiom, explanation.Translation, example, user.Level)
+	if err != nil {
+		h.logger.Error("ошибка сохранения идиомы в карточки", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить идиому")
+	}
+
+	idiom := html.EscapeString(explanation.Idiom)
+	if !added {
+		return h.sendMessage(chatID, fmt.Sprintf("📚 Идиома <b>%s</b> уже есть в твоих карточках.", idiom))
+	}
+	return h.sendMessage(chatID, fmt.Sprintf("✅ Идиома <b>%s</b> добавлена в карточки — она появится в ближайшем повторении /flashcards.", idiom))
+}
@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+)
+
+const breakTheIceJSON = `Вот разбор:
+{"idiom": "break the ice", "is_idiom": true, "meaning": "начать общение и снять неловкость", "translation": "растопить лед", "origin": "Корабли прокладывали путь во льду.", "register": "нейтральная", "examples": ["A joke can break the ice.", " ", "She broke the ice with a smile.", "Games break the ice.", "Extra example."]}`
+
+// idiomCardsRepo запоминает карточки, добавленные пользователям
+type idiomCardsRepo struct {
+	store.FlashcardRepository
+	cards map[string]*models.Flashcard
+}
+
+func (r *idiomCardsRepo) AddUserCard(ctx context.Context, userID int64, card *models.Flashcard) (bool, error) {
+	if _, ok := r.cards[strings.ToLower(card.Word)]; ok {
+		return false, nil
+	}
+	r.cards[strings.ToLower(card.Word)] = card
+	return true, nil
+}
+
+func TestParseIdiomExplanation(t *testing.T) {
+	e, err := parseIdiomExplanation(breakTheIceJSON)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if e.Idiom != "break the ice" || e.Translation != "растопить лед" || !e.IsIdiom {
+		t.Errorf("неверный разбор: %+v", e)
+	}
+	if len(e.Examples) != maxIdiomExamples || e.Examples[1] != "She broke the ice with a smile." {
+		t.Errorf("ожидалось %d непустых примера, получено %q", maxIdiomExamples, e.Examples)
+	}
+
+	for _, content := range []string{"Sorry, I can't", `{"idiom": "x"}`, `{"idiom": "x", "meaning": `} {
+		if _, err := parseIdiomExplanation(content); err == nil {
+			t.Errorf("ожидалась ошибка для %q", content)
+		}
+	}
+}
+
+func TestFormatIdiomExplanationEscapesHTML(t *testing.T) {
+	text := formatIdiomExplanation(idiomExplanation{Idiom: "a <b> c", Meaning: "x & y", IsIdiom: false})
+	if !strings.Contains(text, "a &lt;b&gt; c") || !strings.Contains(text, "x &amp; y") {
+		t.Errorf("ожидалось экранирование HTML, получено %q", text)
+	}
+	if !strings.Contains(text, "не идиома") {
+		t.Errorf("ожидалась пометка, что это не идиома: %q", text)
+	}
+}
+
+func TestIdiomCacheEvictsOldest(t *testing.T) {
+	c := newIdiomCache()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	first := c.put("first", idiomExplanation{Idiom: "first"})
+	for i := 1; i < idiomCacheSize; i++ {
+		now = now.Add(time.Second)
+		c.put(strings.Repeat("k", i), idiomExplanation{})
+	}
+	now = now.Add(time.Second)
+	c.put("last", idiomExplanation{Idiom: "last"})
+
+	if _, _, ok := c.get("first"); ok {
+		t.Error("самая старая запись должна быть вытеснена")
+	}
+	if _, ok := c.byToken(first); ok {
+		t.Error("токен вытесненной записи не должен работать")
+	}
+	if len(c.entries) != idiomCacheSize || len(c.tokens) != idiomCacheSize {
+		t.Errorf("ожидалось %d записей, получено %d и %d токенов", idiomCacheSize, len(c.entries), len(c.tokens))
+	}
+
+	now = now.Add(idiomCacheTTL + time.Second)
+	if _, _, ok := c.get("last"); ok {
+		t.Error("устаревший разбор не должен возвращаться")
+	}
+}
+
+func TestIdiomCommandCachesAndSavesCard(t *testing.T) {
+	th := newTestHarness(t, breakTheIceJSON)
+	repo := &idiomCardsRepo{cards: make(map[string]*models.Flashcard)}
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(repo, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+
+	th.sendText(t, 100, "/idiom break the ice")
+	th.sendText(t, 101, "/idiom Break  the ICE!")
+
+	if len(th.ai.calls) != 1 {
+		t.Fatalf("повторный разбор ожидался из кэша, запросов к AI: %d", len(th.ai.calls))
+	}
+	reply, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(reply.Text, "Происхождение") || !strings.Contains(reply.Text, "растопить лед") {
+		t.Fatalf("ожидался разбор идиомы, получено %#v", th.sender.last())
+	}
+	data := *reply.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+
+	th.sender.reset()
+	th.pressButton(t, 101, data)
+	th.pressButton(t, 101, data)
+
+	card, ok := repo.cards["break the ice"]
+	if !ok {
+		t.Fatal("идиома не сохранена в карточки")
+	}
+	if card.Category != store.IdiomCardCategory || card.Example != "A joke can break the ice." || card.Level != models.LevelBeginner {
+		t.Errorf("неверная карточка идиомы: %+v", card)
+	}
+	texts := th.sender.texts()
+	if len(texts) != 2 || !strings.Contains(texts[0], "добавлена") || !strings.Contains(texts[1], "уже есть") {
+		t.Errorf("ожидались сообщения о сохранении и повторе, получено %q", texts)
+	}
+}
+
+func TestIdiomCommandWithoutPhrase(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/idiom")
+	th.sendText(t, 100, "/idiom "+strings.Repeat("word ", 20))
+
+	if len(th.ai.calls) != 0 {
+		t.Error("без фразы или со слишком длинной фразой AI не вызывается")
+	}
+	texts := th.sender.texts()
+	if len(texts) != 2 || !strings.Contains(texts[0], "/idiom break the ice") || !strings.Contains(texts[1], "не длиннее") {
+		t.Errorf("ожидались подсказки, получено %q", texts)
+	}
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"strings"
+	"unicode"
+
+	"lingua-ai/pkg/models"
+)
+
+// inputLanguage язык сообщения ученика
+type inputLanguage int
+
+const (
+	langUnknown inputLanguage = iota // нет букв: эмодзи, числа
+	langRussian
+	langEnglish
+	langOther // третий язык, который бот не поддерживает
+)
+
+// otherCyrillicLetters буквы, которых нет в русском алфавите:
+// украинские, белорусские, сербские, македонские, казахские
+const otherCyrillicLetters = "іїєґўјљњђћџѓќѕәғқңөұүһ"
+
+// ukrainianWords частые украинские слова, написанные только русскими буквами
+var ukrainianWords = wordSet("дякую", "що", "як", "це", "він", "вона", "вибачте", "звуть")
+
+// foreignLatinWords частые слова других языков на латинице, которые не встречаются в английском
+var foreignLatinWords = wordSet(
+	// испанский
+	"hola", "gracias", "como", "estas", "estoy", "que", "por", "favor", "bien", "muy", "pero", "tengo", "quiero", "yo", "usted",
+	// немецкий
+	"ich", "bin", "nicht", "und", "danke", "bitte", "guten", "wie", "geht", "ist", "mir", "sehr",
+	// французский
+	"je", "suis", "bonjour", "merci", "pas", "vous", "oui", "avec", "c'est",
+	// итальянский и португальский
+	"ciao", "grazie", "sono", "buongiorno", "obrigado", "obrigada", "tudo", "bem",
+)
+
+// targetLanguageWords частые слова изучаемых языков (кроме английского): по ним
+// сообщение на изучаемом языке отличается от английского и от других языков
+var targetLanguageWords = map[string]map[string]bool{
+	models.LanguageSpanish: wordSet("hola", "gracias", "como", "estas", "estoy", "que", "por", "favor", "bien", "muy",
+		"pero", "tengo", "quiero", "yo", "usted", "llamo", "soy", "es", "el", "los", "las", "una", "para", "con", "mi"),
+	models.LanguageGerman: wordSet("ich", "bin", "nicht", "und", "danke", "bitte", "guten", "wie", "geht", "ist",
+		"mir", "sehr", "heiße", "der", "das", "ein", "eine", "mit", "du", "wir", "habe"),
+	models.LanguageFrench: wordSet("je", "suis", "bonjour", "merci", "pas", "vous", "oui", "avec", "c'est",
+		"m'appelle", "le", "les", "une", "et", "est", "tu", "nous", "pour", "mon", "très"),
+	models.LanguageItalian: wordSet("ciao", "grazie", "sono", "buongiorno", "mi", "chiamo", "il", "gli", "una",
+		"che", "non", "sei", "anche", "molto", "bene", "io", "per", "con"),
+}
+
+// englishWords частые английские слова: при смешанном тексте перевешивают иностранные вставки
+var englishWords = wordSet(
+	"the", "is", "are", "am", "i", "you", "we", "they", "what", "how", "it", "to", "and", "of",
+	"in", "my", "this", "that", "do", "have", "hello", "hi", "thanks", "yes", "please", "good",
+)
+
+// wordSet собирает множество слов
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// detectLanguage определяет язык сообщения: русский, английский или третий язык.
+// Короткие фразы вроде "Hola" или "Привіт" тоже распознаются как третий язык.
+func detectLanguage(text string) inputLanguage {
+	var latin, cyrillic, otherCyrillic, otherScript int
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			latin++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case (r >= 'а' && r <= 'я') || r == 'ё':
+			cyrillic++
+		case strings.ContainsRune(otherCyrillicLetters, r):
+			cyrillic++
+			otherCyrillic++
+		case unicode.IsLetter(r):
+			otherScript++
+		}
+	}
+
+	letters := latin + cyrillic + otherScript
+	if letters == 0 {
+		return langUnknown
+	}
+	// Китайский, арабский, греческий и другие алфавиты
+	if otherScript*2 > letters {
+		return langOther
+	}
+
+	words := languageWords(text)
+	if cyrillic >= latin {
+		if otherCyrillic > 0 || countWords(words, ukrainianWords) > 0 {
+			return langOther
+		}
+		return langRussian
+	}
+
+	// Латиница: английский, если нет явных признаков другого языка — слов или букв с диакритикой
+	foreign := countWords(words, foreignLatinWords)
+	for _, word := range words {
+		if strings.IndexFunc(word, func(r rune) bool { return r > unicode.MaxASCII }) >= 0 {
+			foreign++
+		}
+	}
+	english := countWords(words, englishWords)
+	if foreign > english && (foreign >= 2 || len(words) <= 3) {
+		return langOther
+	}
+	return langEnglish
+}
+
+// languageWords разбивает текст на слова в нижнем регистре, сохраняя апострофы (c'est)
+func languageWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+}
+
+// countWords считает слова, входящие в множество
+func countWords(words []string, set map[string]bool) int {
+	count := 0
+	for _, word := range words {
+		if set[word] {
+			count++
+		}
+	}
+	return count
+}
+
+// isTargetLanguage проверяет, написано ли сообщение на изучаемом языке lang.
+// Для других языков на латинице слов этого языка должно быть не меньше, чем английских.
+func isTargetLanguage(text, lang string) bool {
+	detected := detectLanguage(text)
+	markers, ok := targetLanguageWords[lang]
+	if !ok {
+		return detected == langEnglish
+	}
+	if detected != langEnglish && detected != langOther {
+		return false
+	}
+
+	words := languageWords(text)
+	target := countWords(words, markers)
+	return target > 0 && target >= countWords(words, englishWords)
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"testing"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want inputLanguage
+	}{
+		{"Hello, how are you today?", langEnglish},
+		{"ok", langEnglish},
+		{"I like café au lait", langEnglish},
+		{"Hola, how are you?", langEnglish},
+		{"Привет, как дела?", langRussian},
+		{"Как сказать «кошка» по-английски?", langRussian},
+		{"👍 123", langUnknown},
+		// Третьи языки
+		{"Привіт! Як справи?", langOther},
+		{"Дякую за допомогу", langOther},
+		{"Сәлеметсіз бе", langOther},
+		{"Hola, ¿cómo estás?", langOther},
+		{"Gracias", langOther},
+		{"Ich bin müde und möchte schlafen", langOther},
+		{"Je ne sais pas", langOther},
+		{"Ciao, come stai?", langOther},
+		{"你好，我想学习英语", langOther},
+		{"مرحبا كيف حالك", langOther},
+		{"Γεια σου", langOther},
+	}
+
+	for _, tt := range tests {
+		if got := detectLanguage(tt.text); got != tt.want {
+			t.Errorf("%q: ожидался язык %d, получено %d", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestIsTargetLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		lang string
+		want bool
+	}{
+		{"Hello, how are you today?", models.LanguageEnglish, true},
+		{"Hola, ¿cómo estás?", models.LanguageEnglish, false},
+		{"Hola, ¿cómo estás?", models.LanguageSpanish, true},
+		{"Me llamo Ana y soy de Rusia", models.LanguageSpanish, true},
+		{"Hello, how are you today?", models.LanguageSpanish, false},
+		{"Ich bin müde und möchte schlafen", models.LanguageGerman, true},
+		{"Ich bin müde und möchte schlafen", models.LanguageSpanish, false},
+		{"Je ne sais pas", models.LanguageFrench, true},
+		{"Ciao, come stai?", models.LanguageItalian, true},
+		{"Привет, как дела?", models.LanguageSpanish, false},
+		// Неизвестный код: как для английского
+		{"Hello there", "xx", true},
+	}
+
+	for _, tt := range tests {
+		if got := isTargetLanguage(tt.text, tt.lang); got != tt.want {
+			t.Errorf("%q (%s): ожидалось %v, получено %v", tt.text, tt.lang, tt.want, got)
+		}
+	}
+}
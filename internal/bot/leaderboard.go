@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// leaderboardKind вид рейтинга
+type leaderboardKind string
+
+const (
+	leaderboardByXP     leaderboardKind = "xp"     // по XP, затем по серии (по умолчанию)
+	leaderboardByStreak leaderboardKind = "streak" // по серии занятий, затем по XP
+)
+
+// leaderboardCallbackPrefix префикс кнопок переключения рейтинга: leaderboard_<вид>
+const leaderboardCallbackPrefix = "leaderboard_"
+
+// leaderboardKeyboard кнопка переключения на другой рейтинг
+func leaderboardKeyboard(kind leaderboardKind) tgbotapi.InlineKeyboardMarkup {
+	button := tgbotapi.NewInlineKeyboardButtonData("🔥 Рейтинг серий", leaderboardCallbackPrefix+string(leaderboardByStreak))
+	if kind == leaderboardByStreak {
+		button = tgbotapi.NewInlineKeyboardButtonData("🏆 Рейтинг по XP", leaderboardCallbackPrefix+string(leaderboardByXP))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button))
+}
+
+// handleStreakCommand обрабатывает команду /streak — рейтинг серий занятий
+func (h *Handler) handleStreakCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	return h.sendLeaderboard(ctx, message.Chat.ID, user, leaderboardByStreak)
+}
+
+// handleLeaderboardCallback переключает рейтинг в том же сообщении
+func (h *Handler) handleLeaderboardCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	kind := leaderboardKind(strings.TrimPrefix(callback.Data, leaderboardCallbackPrefix))
+	if kind != leaderboardByXP && kind != leaderboardByStreak {
+		h.logger.Warn("неизвестный вид рейтинга в кнопке", zap.String("data", callback.Data))
+		return nil
+	}
+
+	text, err := h.buildLeaderboard(ctx, user, kind)
+	if err != nil {
+		h.logger.Error("ошибка получения пользователей для рейтинга", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Ошибка загрузки рейтинга")
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID, text, leaderboardKeyboard(kind))
+	edit.ParseMode = "HTML"
+	_, err = h.sender.Send(edit)
+	return err
+}
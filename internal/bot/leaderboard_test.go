@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// setupLeaderboard создает двух учеников: 100 — лидер по XP, 200 — по серии
+func setupLeaderboard(t *testing.T) *testHarness {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/reminders")
+	th.sendText(t, 200, "/reminders")
+
+	th.store.users.mu.Lock()
+	for _, u := range th.store.users.users {
+		if u.TelegramID == 100 {
+			u.XP, u.StudyStreak = 500, 2
+		} else {
+			u.XP, u.StudyStreak = 50, 30
+		}
+	}
+	th.store.users.mu.Unlock()
+
+	th.sender.reset()
+	return th
+}
+
+func TestStreakCommandRanksByStreak(t *testing.T) {
+	th := setupLeaderboard(t)
+
+	th.sendText(t, 200, "/streak")
+	msg, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok {
+		t.Fatalf("ожидалось сообщение с рейтингом, получено %T", th.sender.last())
+	}
+	if !strings.Contains(msg.Text, "Рейтинг серий") || !strings.Contains(msg.Text, "№1 • ") {
+		t.Errorf("ученик с самой длинной серией должен быть первым, получено %q", msg.Text)
+	}
+
+	keyboard := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if data := *keyboard.InlineKeyboard[0][0].CallbackData; data != leaderboardCallbackPrefix+string(leaderboardByXP) {
+		t.Errorf("ожидалась кнопка рейтинга по XP, получено %q", data)
+	}
+}
+
+func TestLeaderboardToggleEditsMessage(t *testing.T) {
+	th := setupLeaderboard(t)
+
+	th.pressButton(t, 200, leaderboardCallbackPrefix+string(leaderboardByXP))
+	edit, ok := th.sender.last().(tgbotapi.EditMessageTextConfig)
+	if !ok {
+		t.Fatalf("рейтинг должен переключаться редактированием, получено %T", th.sender.last())
+	}
+	if !strings.Contains(edit.Text, "Рейтинг пользователей") || !strings.Contains(edit.Text, "№2 • ") {
+		t.Errorf("в рейтинге по XP ученик с малым XP должен быть вторым, получено %q", edit.Text)
+	}
+	if data := *edit.ReplyMarkup.InlineKeyboard[0][0].CallbackData; data != leaderboardCallbackPrefix+string(leaderboardByStreak) {
+		t.Errorf("ожидалась кнопка рейтинга серий, получено %q", data)
+	}
+}
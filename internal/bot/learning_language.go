@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// learningLanguageCallbackPrefix префикс кнопок выбора языка: learning_lang_<код языка>
+const learningLanguageCallbackPrefix = "learning_lang_"
+
+// SetLearningLanguages задает языки, которые можно выбрать командой /language.
+// Неподдерживаемые коды пропускаются; пустой список оставляет только английский.
+func (h *Handler) SetLearningLanguages(codes []string) {
+	languages := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if models.IsSupportedLearningLanguage(code) && !slices.Contains(languages, code) {
+			languages = append(languages, code)
+		}
+	}
+	if len(languages) == 0 {
+		languages = []string{models.DefaultLearningLanguage}
+	}
+	h.learningLanguages = languages
+}
+
+// handleLanguageCommand показывает изучаемый язык и кнопки выбора другого
+func (h *Handler) handleLanguageCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	current := models.GetLearningLanguage(user.LearningLanguage)
+	if len(h.learningLanguages) < 2 {
+		return h.sendMessage(message.Chat.ID, fmt.Sprintf("%s Сейчас ты изучаешь <b>%s</b> — другие языки пока недоступны.",
+			current.Flag, current.Accusative))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, code := range h.learningLanguages {
+		language := models.GetLearningLanguage(code)
+		label := language.Flag + " " + language.Name
+		if code == current.Code {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, learningLanguageCallbackPrefix+code)))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("🌍 <b>Изучаемый язык</b>\n\nСейчас: %s <b>%s</b>\n\nВыберите язык 👇",
+		current.Flag, current.Name))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handleLearningLanguageCallback сохраняет выбранный язык и начинает диалог заново
+func (h *Handler) handleLearningLanguageCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	code := strings.TrimPrefix(callback.Data, learningLanguageCallbackPrefix)
+	if !slices.Contains(h.learningLanguages, code) {
+		h.logger.Warn("недоступный язык в кнопке выбора", zap.String("data", callback.Data))
+		return h.sendMessage(chatID, "⚠️ Этот язык сейчас недоступен. Выберите другой: /language")
+	}
+
+	language := models.GetLearningLanguage(code)
+	if code == user.LearningLanguage {
+		return h.sendMessage(chatID, fmt.Sprintf("%s Ты уже изучаешь <b>%s</b>.", language.Flag, language.Accusative))
+	}
+
+	if err := h.userService.SetLearningLanguage(ctx, user.ID, code); err != nil {
+		h.logger.Error("ошибка смены изучаемого языка", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сменить язык")
+	}
+	user.LearningLanguage = code
+
+	// Контекст диалога построен на промпте прежнего языка
+	h.startNewDialog(ctx, user)
+
+	return h.sendMessage(chatID, fmt.Sprintf("%s Готово! Теперь изучаем <b>%s</b> — напиши мне что-нибудь на %s.",
+		language.Flag, language.Accusative, language.Prepositional))
+}
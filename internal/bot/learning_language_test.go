@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestSetLearningLanguagesSkipsUnsupported(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.handler.SetLearningLanguages([]string{" ES ", "xx", "en", "es"})
+	if got := strings.Join(th.handler.learningLanguages, ","); got != "es,en" {
+		t.Errorf("ожидались языки es,en, получено %s", got)
+	}
+
+	th.handler.SetLearningLanguages(nil)
+	if got := strings.Join(th.handler.learningLanguages, ","); got != models.DefaultLearningLanguage {
+		t.Errorf("без языков должен остаться английский, получено %s", got)
+	}
+}
+
+func TestLanguageCommandSwitchesLanguage(t *testing.T) {
+	th := newTestHarness(t, "<b>¡Muy bien!</b>")
+	th.handler.SetLearningLanguages([]string{models.LanguageEnglish, models.LanguageSpanish})
+
+	th.sendText(t, 100, "/language")
+	if u := th.user(t, 100); u.LearningLanguage != models.LanguageEnglish {
+		t.Fatalf("новый пользователь должен изучать английский, получено %q", u.LearningLanguage)
+	}
+
+	th.pressButton(t, 100, learningLanguageCallbackPrefix+models.LanguageSpanish)
+	if u := th.user(t, 100); u.LearningLanguage != models.LanguageSpanish {
+		t.Fatalf("ожидался испанский, получено %q", u.LearningLanguage)
+	}
+
+	// Сообщение на испанском разбирается с промптом учителя испанского
+	th.sendText(t, 100, "Hola, me llamo Ana y estoy bien")
+	if len(th.ai.calls) == 0 {
+		t.Fatal("ожидался запрос к AI")
+	}
+	if prompt := th.ai.calls[len(th.ai.calls)-1][0].Content; !strings.Contains(prompt, "испанского") {
+		t.Errorf("ожидался промпт учителя испанского, получено %q", prompt)
+	}
+}
+
+func TestLanguageCallbackRejectsUnconfiguredLanguage(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/language")
+	th.pressButton(t, 100, learningLanguageCallbackPrefix+models.LanguageGerman)
+	if u := th.user(t, 100); u.LearningLanguage != models.LanguageEnglish {
+		t.Errorf("язык не из списка не должен выбираться, получено %q", u.LearningLanguage)
+	}
+}
@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Кнопки выбора стартового уровня: first_level_<level>, first_level_test или first_level_skip
+const (
+	levelPickerCallbackPrefix = "first_level_"
+	levelPickerTestCallback   = levelPickerCallbackPrefix + "test"
+	levelPickerSkipCallback   = levelPickerCallbackPrefix + "skip"
+)
+
+// SetFirstRunLevelPicker включает выбор уровня новыми пользователями перед приветствием.
+// allowSkip добавляет кнопку, которая оставляет уровень по умолчанию и пропускает тур.
+func (h *Handler) SetFirstRunLevelPicker(enabled, allowSkip bool) {
+	h.levelPicker = enabled
+	h.levelPickerSkip = allowSkip
+}
+
+// needsLevelPicker определяет, нужно ли предложить пользователю выбрать стартовый уровень
+func needsLevelPicker(user *models.User) bool {
+	return user.LevelSelectedAt == nil && shouldAutoStartOnboarding(user)
+}
+
+// sendLevelPicker предлагает новому пользователю оценить свой уровень самостоятельно или пройти тест
+func (h *Handler) sendLevelPicker(chatID int64, firstName string) error {
+	text := fmt.Sprintf(`👋 Привет, %s!
+
+Как ты оцениваешь свой английский? От уровня зависят задания, карточки и сложность ответов.
+
+Не уверен — пройди короткий тест, и я подберу уровень сам.`, firstName)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = levelPickerKeyboard(h.levelPickerSkip)
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// levelPickerKeyboard формирует кнопки выбора стартового уровня, теста и, если разрешено, пропуска
+func levelPickerKeyboard(allowSkip bool) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌱 Только начинаю", levelPickerCallbackPrefix+models.LevelBeginner),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌿 Могу общаться на простые темы", levelPickerCallbackPrefix+models.LevelIntermediate),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌳 Свободно говорю", levelPickerCallbackPrefix+models.LevelAdvanced),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎯 Определить тестом", levelPickerTestCallback),
+		),
+	)
+	if allowSkip {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", levelPickerSkipCallback),
+		))
+	}
+	return keyboard
+}
+
+// handleLevelPickerCallback сохраняет выбранный стартовый уровень и показывает приветствие.
+// Кнопка теста запускает тест уровня: уровень сохранится по его результату.
+func (h *Handler) handleLevelPickerCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	if user.LevelSelectedAt != nil {
+		// Повторное нажатие на старую кнопку — уровень уже выбран
+		return nil
+	}
+
+	switch callback.Data {
+	case levelPickerTestCallback:
+		editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "🎯 Определим уровень тестом — это займет пару минут.")
+		if _, err := h.sender.Send(editMsg); err != nil {
+			h.logger.Warn("не удалось обновить сообщение выбора уровня", zap.Error(err))
+		}
+		return h.handleStartLevelTest(ctx, callback.Message, user)
+
+	case levelPickerSkipCallback:
+		if !h.levelPickerSkip {
+			return nil
+		}
+		return h.skipLevelPicker(ctx, callback, user)
+	}
+
+	level := strings.TrimPrefix(callback.Data, levelPickerCallbackPrefix)
+	if !models.IsValidLevel(level) {
+		h.logger.Warn("неверный уровень в кнопке выбора", zap.String("data", callback.Data))
+		return nil
+	}
+
+	chosen, err := h.userService.ChooseInitialLevel(ctx, user.ID, level, models.LevelAssessmentSelf)
+	if err != nil {
+		h.logger.Error("ошибка сохранения стартового уровня", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить уровень")
+	}
+	if !chosen {
+		// Повторное нажатие на старую кнопку — уровень уже выбран
+		return nil
+	}
+	user.Level = level
+	user.LevelAssessment = models.LevelAssessmentSelf
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		fmt.Sprintf("✅ Уровень сохранен: %s", h.getLevelText(level)))
+	if _, err := h.sender.Send(editMsg); err != nil {
+		h.logger.Warn("не удалось обновить сообщение выбора уровня", zap.Error(err))
+	}
+
+	return h.sendWelcome(chatID, user, true)
+}
+
+// skipLevelPicker оставляет уровень по умолчанию и показывает главное меню без тура
+func (h *Handler) skipLevelPicker(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	chosen, err := h.userService.ChooseInitialLevel(ctx, user.ID, user.Level, models.LevelAssessmentSkipped)
+	if err != nil {
+		h.logger.Error("ошибка пропуска выбора уровня", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить уровень")
+	}
+	if !chosen {
+		return nil
+	}
+	user.LevelAssessment = models.LevelAssessmentSkipped
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		fmt.Sprintf("👌 Начнем с уровня %s. Тест уровня доступен в любой момент из меню.", h.getLevelText(user.Level)))
+	if _, err := h.sender.Send(editMsg); err != nil {
+		h.logger.Warn("не удалось обновить сообщение выбора уровня", zap.Error(err))
+	}
+
+	return h.sendWelcome(chatID, user, false)
+}
+
+// completeFirstRunLevelTest сохраняет уровень по результату теста, пройденного вместо выбора
+// при первом запуске. Возвращает false, если стартовый уровень уже был выбран.
+func (h *Handler) completeFirstRunLevelTest(ctx context.Context, user *models.User, level string) bool {
+	chosen, err := h.userService.ChooseInitialLevel(ctx, user.ID, level, models.LevelAssessmentTest)
+	if err != nil {
+		h.logger.Error("ошибка сохранения уровня по тесту", zap.Error(err), zap.Int64("user_id", user.ID))
+		return false
+	}
+	if !chosen {
+		return false
+	}
+
+	now := time.Now()
+	user.Level = level
+	user.LevelSelectedAt = &now
+	user.LevelAssessment = models.LevelAssessmentTest
+	return true
+}
+
+// shouldSuggestLevelTest определяет, пора ли мягко предложить тест тому,
+// кто оценил уровень сам: один раз, когда XP впервые достигает порога
+func shouldSuggestLevelTest(user *models.User, oldXP int) bool {
+	return user.LevelAssessment == models.LevelAssessmentSelf && user.LastTestDate == nil &&
+		oldXP < models.LevelTestSuggestXP && user.XP >= models.LevelTestSuggestXP
+}
+
+// sendLevelTestSuggestion предлагает проверить самостоятельно выбранный уровень тестом
+func (h *Handler) sendLevelTestSuggestion(chatID int64, level string) {
+	text := fmt.Sprintf(`🎯 Ты уже хорошо позанимался на уровне %s!
+
+Хочешь проверить, подходит ли он тебе? Короткий тест подберет уровень точнее — задания станут ещё полезнее.`, h.getLevelText(level))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎯 Пройти тест", menuButtons[ActionLevelTest].Callback),
+		),
+	)
+	if _, err := h.sender.Send(msg); err != nil {
+		h.logger.Warn("не удалось предложить тест уровня", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestNeedsLevelPicker(t *testing.T) {
+	selected := time.Now()
+
+	tests := []struct {
+		name     string
+		user     models.User
+		expected bool
+	}{
+		{"новый пользователь", models.User{}, true},
+		{"уровень уже выбран", models.User{LevelSelectedAt: &selected}, false},
+		{"уже есть XP", models.User{XP: 50}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsLevelPicker(&tt.user); got != tt.expected {
+				t.Errorf("ожидалось %v, получено %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLevelPickerKeyboardLevels(t *testing.T) {
+	for _, allowSkip := range []bool{false, true} {
+		keyboard := levelPickerKeyboard(allowSkip)
+		rows := keyboard.InlineKeyboard
+
+		expectedRows := 4
+		if allowSkip {
+			expectedRows = 5
+		}
+		if len(rows) != expectedRows {
+			t.Fatalf("allowSkip=%v: ожидалось %d рядов кнопок, получено %d", allowSkip, expectedRows, len(rows))
+		}
+
+		for _, row := range rows[:3] {
+			data := *row[0].CallbackData
+			level := strings.TrimPrefix(data, levelPickerCallbackPrefix)
+			if !models.IsValidLevel(level) {
+				t.Errorf("ожидался корректный уровень в кнопке, получено %s", data)
+			}
+		}
+		if data := *rows[3][0].CallbackData; data != levelPickerTestCallback {
+			t.Errorf("ожидалась кнопка теста, получено %s", data)
+		}
+		if allowSkip && *rows[4][0].CallbackData != levelPickerSkipCallback {
+			t.Errorf("ожидалась кнопка пропуска, получено %s", *rows[4][0].CallbackData)
+		}
+	}
+}
+
+func TestLevelPickerSelfAssessment(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.SetFirstRunLevelPicker(true, true)
+
+	th.sendText(t, 100, "/start")
+	th.pressButton(t, 100, levelPickerCallbackPrefix+models.LevelIntermediate)
+
+	u := th.user(t, 100)
+	if u.Level != models.LevelIntermediate {
+		t.Errorf("ожидался уровень %s, получено %s", models.LevelIntermediate, u.Level)
+	}
+	if u.LevelAssessment != models.LevelAssessmentSelf {
+		t.Errorf("ожидался способ %q, получено %q", models.LevelAssessmentSelf, u.LevelAssessment)
+	}
+
+	// Повторное нажатие на кнопку теста после выбора ничего не меняет
+	th.pressButton(t, 100, levelPickerTestCallback)
+	if _, active := th.handler.activeLevelTests[u.ID]; active {
+		t.Error("тест не должен запускаться после выбора уровня")
+	}
+}
+
+func TestLevelPickerTestAssessment(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.SetFirstRunLevelPicker(true, true)
+
+	th.sendText(t, 100, "/start")
+	th.pressButton(t, 100, levelPickerTestCallback)
+
+	u := th.user(t, 100)
+	if u.LevelSelectedAt != nil {
+		t.Fatal("уровень не должен сохраняться до окончания теста")
+	}
+	levelTest, ok := th.handler.activeLevelTests[u.ID]
+	if !ok {
+		t.Fatal("ожидался запущенный тест уровня")
+	}
+
+	// Отвечаем на все вопросы правильно
+	for levelTest.CurrentQuestion < len(levelTest.Questions) {
+		correct := levelTest.Questions[levelTest.CurrentQuestion].CorrectAnswer
+		th.pressButton(t, 100, testAnswerCallbackPrefix+strconv.Itoa(correct))
+	}
+
+	u = th.user(t, 100)
+	if u.Level != models.LevelAdvanced {
+		t.Errorf("ожидался уровень по тесту %s, получено %s", models.LevelAdvanced, u.Level)
+	}
+	if u.LevelAssessment != models.LevelAssessmentTest || u.LevelSelectedAt == nil {
+		t.Errorf("ожидался сохраненный способ %q, получено %q", models.LevelAssessmentTest, u.LevelAssessment)
+	}
+
+	saved := false
+	for _, text := range th.sender.texts() {
+		saved = saved || strings.Contains(text, "Уровень сохранен")
+	}
+	if !saved {
+		t.Error("ожидались результаты теста с сохраненным уровнем")
+	}
+}
+
+func TestLevelPickerSkip(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.SetFirstRunLevelPicker(true, true)
+
+	th.sendText(t, 100, "/start")
+	th.pressButton(t, 100, levelPickerSkipCallback)
+
+	u := th.user(t, 100)
+	if u.Level != models.LevelBeginner || u.LevelAssessment != models.LevelAssessmentSkipped {
+		t.Errorf("ожидался уровень по умолчанию с пропуском, получено %s/%q", u.Level, u.LevelAssessment)
+	}
+}
+
+func TestShouldSuggestLevelTest(t *testing.T) {
+	tested := time.Now()
+
+	tests := []struct {
+		name     string
+		user     models.User
+		oldXP    int
+		expected bool
+	}{
+		{"самооценка, порог пройден", models.User{LevelAssessment: models.LevelAssessmentSelf, XP: models.LevelTestSuggestXP}, 100, true},
+		{"порог уже был пройден", models.User{LevelAssessment: models.LevelAssessmentSelf, XP: 300}, models.LevelTestSuggestXP, false},
+		{"порог не достигнут", models.User{LevelAssessment: models.LevelAssessmentSelf, XP: 100}, 50, false},
+		{"уровень по тесту", models.User{LevelAssessment: models.LevelAssessmentTest, XP: models.LevelTestSuggestXP}, 100, false},
+		{"тест уже пройден", models.User{LevelAssessment: models.LevelAssessmentSelf, XP: models.LevelTestSuggestXP, LastTestDate: &tested}, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldSuggestLevelTest(&tt.user, tt.oldXP); got != tt.expected {
+				t.Errorf("ожидалось %v, получено %v", tt.expected, got)
+			}
+		})
+	}
+}
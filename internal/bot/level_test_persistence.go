@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// levelTestStaleAfter незавершенный тест старше этого срока не восстанавливается
+const levelTestStaleAfter = 24 * time.Hour
+
+// SetLevelTestPersistence включает сохранение тестов уровня в БД (nil — только в памяти),
+// чтобы пользователь мог продолжить тест после перезапуска бота
+func (h *Handler) SetLevelTestPersistence(repo store.LevelTestRepository) {
+	h.levelTestStore = repo
+}
+
+// activeLevelTest возвращает незавершенный тест пользователя. Если теста нет в памяти
+// (бот перезапускался), он восстанавливается из БД.
+func (h *Handler) activeLevelTest(ctx context.Context, userID int64) (*models.LevelTest, bool) {
+	if levelTest, ok := h.activeLevelTests[userID]; ok {
+		return levelTest, true
+	}
+	if h.levelTestStore == nil {
+		return nil, false
+	}
+
+	levelTest, err := h.levelTestStore.Get(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, store.ErrLevelTestNotFound) {
+			h.logger.Warn("не удалось загрузить тест уровня", zap.Error(err), zap.Int64("user_id", userID))
+		}
+		return nil, false
+	}
+	if time.Since(levelTest.StartedAt) > levelTestStaleAfter {
+		h.finishLevelTest(ctx, userID)
+		return nil, false
+	}
+
+	h.logger.Info("тест уровня восстановлен",
+		zap.Int64("user_id", userID),
+		zap.Int("current_question", levelTest.CurrentQuestion))
+	h.activeLevelTests[userID] = levelTest
+	return levelTest, true
+}
+
+// startLevelTestSession запоминает новый тест пользователя вместо незавершенного
+func (h *Handler) startLevelTestSession(ctx context.Context, levelTest *models.LevelTest) error {
+	if h.levelTestStore != nil {
+		if err := h.levelTestStore.Create(ctx, levelTest); err != nil {
+			return err
+		}
+	}
+	h.activeLevelTests[levelTest.UserID] = levelTest
+	return nil
+}
+
+// saveLevelTestProgress сохраняет ответы и текущий вопрос теста
+func (h *Handler) saveLevelTestProgress(ctx context.Context, levelTest *models.LevelTest) {
+	if h.levelTestStore == nil {
+		return
+	}
+
+	if err := h.levelTestStore.Update(ctx, levelTest); err != nil {
+		h.logger.Warn("не удалось сохранить прогресс теста уровня", zap.Error(err), zap.Int64("user_id", levelTest.UserID))
+	}
+}
+
+// finishLevelTest удаляет завершенный или отмененный тест из памяти и из БД
+func (h *Handler) finishLevelTest(ctx context.Context, userID int64) {
+	delete(h.activeLevelTests, userID)
+	if h.levelTestStore == nil {
+		return
+	}
+
+	if err := h.levelTestStore.Delete(ctx, userID); err != nil {
+		h.logger.Warn("не удалось удалить сохраненный тест уровня", zap.Error(err), zap.Int64("user_id", userID))
+	}
+}
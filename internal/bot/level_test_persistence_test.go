@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+)
+
+// memoryLevelTests тесты уровня в памяти; хранятся в JSON, как в БД, чтобы не делить указатели с кэшем
+type memoryLevelTests struct {
+	mu    sync.Mutex
+	tests map[int64][]byte
+}
+
+func newMemoryLevelTests() *memoryLevelTests {
+	return &memoryLevelTests{tests: make(map[int64][]byte)}
+}
+
+func (r *memoryLevelTests) Create(ctx context.Context, test *models.LevelTest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.Marshal(test)
+	if err != nil {
+		return err
+	}
+	r.tests[test.UserID] = data
+	return nil
+}
+
+func (r *memoryLevelTests) Get(ctx context.Context, userID int64) (*models.LevelTest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.tests[userID]
+	if !ok {
+		return nil, fmt.Errorf("%w: user_id %d", store.ErrLevelTestNotFound, userID)
+	}
+	test := &models.LevelTest{}
+	return test, json.Unmarshal(data, test)
+}
+
+func (r *memoryLevelTests) Update(ctx context.Context, test *models.LevelTest) error {
+	r.mu.Lock()
+	_, ok := r.tests[test.UserID]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: user_id %d", store.ErrLevelTestNotFound, test.UserID)
+	}
+	return r.Create(ctx, test)
+}
+
+func (r *memoryLevelTests) Delete(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tests, userID)
+	return nil
+}
+
+func TestLevelTestSurvivesRestart(t *testing.T) {
+	tests := newMemoryLevelTests()
+	th := newTestHarness(t)
+	th.handler.SetLevelTestPersistence(tests)
+
+	th.sendText(t, 100, menuButtons[ActionStartTest].Text)
+	u := th.user(t, 100)
+	question := th.handler.activeLevelTests[u.ID].Questions[0]
+	th.pressButton(t, 100, testAnswerCallback(question.CorrectAnswer))
+
+	// Перезапуск: тесты в памяти потеряны, сохраненный остался
+	th.handler.activeLevelTests = make(map[int64]*models.LevelTest)
+
+	levelTest, ok := th.handler.activeLevelTest(context.Background(), u.ID)
+	if !ok {
+		t.Fatal("ожидалось восстановление теста из БД")
+	}
+	if levelTest.CurrentQuestion != 1 || levelTest.Score != question.Points || len(levelTest.Answers) != 1 {
+		t.Errorf("ожидался прогресс после первого ответа, получено вопрос %d, очки %d, ответов %d",
+			levelTest.CurrentQuestion, levelTest.Score, len(levelTest.Answers))
+	}
+
+	// Продолжаем тест после перезапуска и завершаем его
+	for levelTest.CurrentQuestion < len(levelTest.Questions) {
+		th.pressButton(t, 100, testAnswerCallback(levelTest.Questions[levelTest.CurrentQuestion].CorrectAnswer))
+	}
+	if _, err := tests.Get(context.Background(), u.ID); err == nil {
+		t.Error("завершенный тест должен удаляться из БД")
+	}
+	if th.user(t, 100).LastTestDate == nil {
+		t.Error("ожидалась дата прохождения теста")
+	}
+}
+
+func TestStaleLevelTestIsNotRestored(t *testing.T) {
+	tests := newMemoryLevelTests()
+	th := newTestHarness(t)
+	th.handler.SetLevelTestPersistence(tests)
+
+	stale := &models.LevelTest{UserID: 1, StartedAt: time.Now().Add(-levelTestStaleAfter - time.Hour)}
+	if err := tests.Create(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := th.handler.activeLevelTest(context.Background(), 1); ok {
+		t.Error("устаревший тест не должен восстанавливаться")
+	}
+	if _, err := tests.Get(context.Background(), 1); err == nil {
+		t.Error("устаревший тест должен удаляться из БД")
+	}
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+
+	"lingua-ai/internal/challenge"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MenuAction действие кнопки меню
+type MenuAction string
+
+// Действия кнопок меню
+const (
+	ActionLearning    MenuAction = "learning"
+	ActionStats       MenuAction = "stats"
+	ActionLeaderboard MenuAction = "leaderboard"
+	ActionPremium     MenuAction = "premium"
+	ActionReferral    MenuAction = "referral"
+	ActionHelp        MenuAction = "help"
+	ActionClearDialog MenuAction = "clear_dialog"
+	ActionTopic       MenuAction = "topic_starter"
+	ActionFlashcards  MenuAction = "flashcards"
+	ActionLevelTest   MenuAction = "level_test"
+	ActionWordPack    MenuAction = "word_pack"
+	ActionDictation   MenuAction = "dictation"
+	ActionPhrase      MenuAction = "phrase_of_day"
+	ActionStartTest   MenuAction = "start_test"
+	ActionCancelTest  MenuAction = "cancel_test"
+	ActionBackToMain  MenuAction = "back_to_main"
+)
+
+// MenuButton описание кнопки меню — единый источник для reply и inline клавиатур
+type MenuButton struct {
+	Text     string // Текст кнопки reply-клавиатуры
+	Callback string // Данные inline-кнопки (пусто — кнопка только для reply-клавиатуры)
+}
+
+// menuButtons все кнопки меню
+var menuButtons = map[MenuAction]MenuButton{
+	ActionLearning:    {Text: "📚 Обучение", Callback: "learning_menu"},
+	ActionStats:       {Text: "📊 Статистика", Callback: "main_stats"},
+	ActionLeaderboard: {Text: "🏆 Рейтинг", Callback: "main_rating"},
+	ActionPremium:     {Text: "💎 Премиум", Callback: "main_premium"},
+	ActionReferral:    {Text: "🔗 Реферальная ссылка"},
+	ActionHelp:        {Text: "❓ Помощь", Callback: "main_help"},
+	ActionClearDialog: {Text: "🗑 Очистить диалог"},
+	ActionTopic:       {Text: "💬 Начать разговор", Callback: "menu_topic_starter"},
+	ActionFlashcards:  {Text: "📝 Словарные карточки", Callback: "menu_flashcards"},
+	ActionLevelTest:   {Text: "🎓 Тест уровня", Callback: "menu_level_test"},
+	ActionWordPack:    {Text: "📦 Набор недели", Callback: "menu_word_pack"},
+	ActionDictation:   {Text: "🎧 Диктант"},
+	ActionPhrase:      {Text: "🗣 Фраза дня", Callback: challenge.CallbackData},
+	ActionStartTest:   {Text: "🎯 Начать тест"},
+	ActionCancelTest:  {Text: "❌ Отменить тест"},
+	ActionBackToMain:  {Text: "🔙 Назад в главное меню"},
+}
+
+// legacyButtonTexts тексты кнопок из прежних версий клавиатур,
+// которые еще могут прийти от клиентов с закэшированной клавиатурой
+var legacyButtonTexts = map[string]MenuAction{
+	"🎯 Тест уровня":  ActionLevelTest,
+	"🔙 Назад к меню": ActionBackToMain,
+}
+
+// Раскладки меню
+var (
+	mainMenuLayout = [][]MenuAction{
+		{ActionLearning, ActionStats},
+		{ActionLeaderboard, ActionPremium},
+		{ActionReferral, ActionHelp},
+		{ActionTopic, ActionClearDialog},
+	}
+	learningMenuLayout = [][]MenuAction{
+		{ActionFlashcards, ActionLevelTest},
+		{ActionWordPack, ActionDictation},
+		{ActionPhrase},
+		{ActionBackToMain},
+	}
+	levelTestMenuLayout = [][]MenuAction{
+		{ActionStartTest},
+		{ActionBackToMain},
+	}
+	activeTestMenuLayout = [][]MenuAction{
+		{ActionCancelTest},
+		{ActionBackToMain},
+	}
+	// offlineMenuLayout занятия, которые работают без AI (inline-кнопки режима без AI)
+	offlineMenuLayout = [][]MenuAction{
+		{ActionFlashcards, ActionLevelTest},
+		{ActionWordPack, ActionPhrase},
+	}
+)
+
+// menuActionHandlers обработчики действий меню
+var menuActionHandlers = map[MenuAction]func(h *Handler, ctx context.Context, message *tgbotapi.Message, user *models.User) error{
+	ActionLearning:    (*Handler).handleLearningButton,
+	ActionStats:       (*Handler).handleStatsCommand,
+	ActionLeaderboard: (*Handler).handleLeaderboardButton,
+	ActionPremium:     (*Handler).handlePremiumCommand,
+	ActionReferral:    (*Handler).handleReferralButton,
+	ActionHelp:        (*Handler).handleHelpCommand,
+	ActionClearDialog: (*Handler).handleClearCommand,
+	ActionTopic:       (*Handler).handleTopicStarterButton,
+	ActionFlashcards:  (*Handler).handleFlashcardsButton,
+	ActionLevelTest:   (*Handler).handleLevelTestButton,
+	ActionWordPack:    (*Handler).handleWordPackButton,
+	ActionDictation:   (*Handler).handleDictationButton,
+	ActionPhrase:      (*Handler).handlePhraseOfDayButton,
+	ActionStartTest:   (*Handler).handleStartLevelTest,
+	ActionCancelTest:  (*Handler).handleBackToMainButton,
+	ActionBackToMain:  (*Handler).handleBackToMainButton,
+}
+
+// menuActionByText находит действие по тексту reply-кнопки
+func menuActionByText(text string) (MenuAction, bool) {
+	for action, button := range menuButtons {
+		if button.Text == text {
+			return action, true
+		}
+	}
+	action, ok := legacyButtonTexts[text]
+	return action, ok
+}
+
+// menuActionByCallback находит действие по данным inline-кнопки
+func menuActionByCallback(data string) (MenuAction, bool) {
+	if data == "" {
+		return "", false
+	}
+	for action, button := range menuButtons {
+		if button.Callback == data {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+// isMenuCallback проверяет, относятся ли данные callback к кнопке меню
+func isMenuCallback(data string) bool {
+	_, ok := menuActionByCallback(data)
+	return ok
+}
+
+// replyKeyboardRows формирует строки reply-клавиатуры из раскладки
+func replyKeyboardRows(layout [][]MenuAction) [][]string {
+	rows := make([][]string, 0, len(layout))
+	for _, actions := range layout {
+		row := make([]string, 0, len(actions))
+		for _, action := range actions {
+			row = append(row, menuButtons[action].Text)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// inlineKeyboard формирует inline-клавиатуру из раскладки, пропуская кнопки без callback
+func inlineKeyboard(layout [][]MenuAction) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, actions := range layout {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, action := range actions {
+			button := menuButtons[action]
+			if button.Callback == "" {
+				continue
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(button.Text, button.Callback))
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleMenuAction выполняет действие кнопки меню
+func (h *Handler) handleMenuAction(ctx context.Context, message *tgbotapi.Message, user *models.User, action MenuAction) error {
+	handler, ok := menuActionHandlers[action]
+	if !ok {
+		return nil
+	}
+	return handler(h, ctx, message, user)
+}
+
+// handleFlashcardsButton открывает словарные карточки
+func (h *Handler) handleFlashcardsButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	return h.flashcardHandler.HandleFlashcardsCommand(ctx, message.Chat.ID, user)
+}
+
+// handleBackToMainButton возвращает в главное меню, прерывая активный тест уровня
+func (h *Handler) handleBackToMainButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	if user.CurrentState == models.StateInLevelTest {
+		return h.cancelLevelTest(ctx, message, user)
+	}
+	return h.handleStartCommand(ctx, message, user)
+}
@@ -0,0 +1,84 @@
+package bot
+
+import "testing"
+
+func TestMenuButtonsRouting(t *testing.T) {
+	texts := make(map[string]MenuAction)
+	callbacks := make(map[string]MenuAction)
+
+	for action, button := range menuButtons {
+		if button.Text == "" {
+			t.Errorf("у действия %s нет текста кнопки", action)
+		}
+		if other, dup := texts[button.Text]; dup {
+			t.Errorf("текст %q используется действиями %s и %s", button.Text, other, action)
+		}
+		texts[button.Text] = action
+
+		if got, ok := menuActionByText(button.Text); !ok || got != action {
+			t.Errorf("кнопка %q: ожидалось действие %s, получено %s", button.Text, action, got)
+		}
+
+		if button.Callback != "" {
+			if other, dup := callbacks[button.Callback]; dup {
+				t.Errorf("callback %q используется действиями %s и %s", button.Callback, other, action)
+			}
+			callbacks[button.Callback] = action
+
+			if got, ok := menuActionByCallback(button.Callback); !ok || got != action {
+				t.Errorf("callback %q: ожидалось действие %s, получено %s", button.Callback, action, got)
+			}
+		}
+
+		if _, ok := menuActionHandlers[action]; !ok {
+			t.Errorf("для действия %s нет обработчика", action)
+		}
+	}
+}
+
+func TestLegacyButtonTexts(t *testing.T) {
+	for text, action := range legacyButtonTexts {
+		if _, ok := menuButtons[action]; !ok {
+			t.Errorf("устаревшая кнопка %q ссылается на неизвестное действие %s", text, action)
+		}
+		if got, ok := menuActionByText(text); !ok || got != action {
+			t.Errorf("устаревшая кнопка %q: ожидалось действие %s, получено %s", text, action, got)
+		}
+	}
+}
+
+func TestMenuLayoutsUseKnownButtons(t *testing.T) {
+	layouts := map[string][][]MenuAction{
+		"главное меню":  mainMenuLayout,
+		"обучение":      learningMenuLayout,
+		"тест уровня":   levelTestMenuLayout,
+		"активный тест": activeTestMenuLayout,
+		"без AI":        offlineMenuLayout,
+	}
+
+	for name, layout := range layouts {
+		seen := make(map[MenuAction]bool)
+		for _, row := range layout {
+			for _, action := range row {
+				if _, ok := menuButtons[action]; !ok {
+					t.Errorf("%s: неизвестное действие %s", name, action)
+				}
+				if seen[action] {
+					t.Errorf("%s: кнопка %s повторяется", name, action)
+				}
+				seen[action] = true
+			}
+		}
+	}
+}
+
+func TestInlineKeyboardSkipsReplyOnlyButtons(t *testing.T) {
+	keyboard := inlineKeyboard(mainMenuLayout)
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == nil || *button.CallbackData == "" {
+				t.Errorf("inline-кнопка %q без callback", button.Text)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// XP за сообщения на английском
+const (
+	englishMessageXP = 15 // за полноценное сообщение
+	shortMessageXP   = 3  // за слишком короткое сообщение вроде "ok"
+
+	// DefaultXPMinWords минимум слов для полного XP на уровне beginner
+	DefaultXPMinWords = 3
+
+	// shortHintInterval как часто можно напоминать о развернутых ответах
+	shortHintInterval = 24 * time.Hour
+)
+
+// SetXPMinWords задает минимум слов в сообщении для полного XP на уровне beginner.
+// На следующих уровнях требуется на одно слово больше за каждый уровень; 0 — без ограничения.
+func (h *Handler) SetXPMinWords(words int) {
+	if words < 0 {
+		words = 0
+	}
+	h.xpMinWords = words
+}
+
+// minWordsForLevel возвращает минимум слов для полного XP с учетом уровня
+func minWordsForLevel(base int, level string) int {
+	if base <= 0 {
+		return 0
+	}
+	switch level {
+	case models.LevelIntermediate:
+		return base + 1
+	case models.LevelAdvanced:
+		return base + 2
+	default:
+		return base
+	}
+}
+
+// countEnglishWords считает слова, в которых есть латинские буквы; эмодзи и числа не считаются
+func countEnglishWords(text string) int {
+	count := 0
+	for _, word := range strings.Fields(text) {
+		for _, r := range word {
+			if r < unicode.MaxASCII && unicode.IsLetter(r) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// answersBotQuestion проверяет, отвечает ли ученик на вопрос бота.
+// Короткий ответ на вопрос ("Yes, I do") — нормальная реплика, за нее не снижаем XP.
+func answersBotQuestion(message *tgbotapi.Message, botID int64, recent []DialogMessage) bool {
+	if quote, ok := quotedReply(message, botID); ok {
+		return quote.FromBot && strings.Contains(quote.Text, "?")
+	}
+
+	// Последнее сообщение в recent — текущее сообщение ученика
+	for i := len(recent) - 2; i >= 0; i-- {
+		if recent[i].Role != "assistant" {
+			continue
+		}
+		english, _ := splitAIResponse(recent[i].Content)
+		return strings.Contains(stripHTML(english), "?")
+	}
+	return false
+}
+
+// englishMessageReward возвращает XP за сообщение и признак того, что сообщение слишком короткое
+func englishMessageReward(text, level string, minWords int, answeringQuestion bool) (int, bool) {
+	if answeringQuestion || countEnglishWords(text) >= minWordsForLevel(minWords, level) {
+		return englishMessageXP, false
+	}
+	return shortMessageXP, true
+}
+
+// takeShortHint сообщает, пора ли снова напомнить о развернутых ответах, и отмечает напоминание
+func (dc *DialogContext) takeShortHint(now time.Time) bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if !dc.shortHintAt.IsZero() && now.Sub(dc.shortHintAt) < shortHintInterval {
+		return false
+	}
+	dc.shortHintAt = now
+	return true
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestEnglishMessageReward(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		level     string
+		minWords  int
+		answering bool
+		xp        int
+		short     bool
+	}{
+		{"одно слово", "ok", models.LevelBeginner, 3, false, shortMessageXP, true},
+		{"эмодзи не считаются", "ok 👍 😂", models.LevelBeginner, 3, false, shortMessageXP, true},
+		{"предложение новичка", "I like tea", models.LevelBeginner, 3, false, englishMessageXP, false},
+		{"intermediate нужно больше слов", "I like tea", models.LevelIntermediate, 3, false, shortMessageXP, true},
+		{"advanced", "I really like green tea", models.LevelAdvanced, 3, false, englishMessageXP, false},
+		{"короткий ответ на вопрос", "Yes", models.LevelAdvanced, 3, true, englishMessageXP, false},
+		{"ограничение выключено", "ok", models.LevelAdvanced, 0, false, englishMessageXP, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xp, short := englishMessageReward(tt.text, tt.level, tt.minWords, tt.answering)
+			if xp != tt.xp || short != tt.short {
+				t.Errorf("ожидалось %d XP (short=%v), получено %d XP (short=%v)", tt.xp, tt.short, xp, short)
+			}
+		})
+	}
+}
+
+func TestAnswersBotQuestion(t *testing.T) {
+	const botID = 7
+	message := &tgbotapi.Message{Text: "Yes"}
+
+	asked := []DialogMessage{
+		{Role: "assistant", Content: "Do you like tea?\n<tg-spoiler>🇷🇺 Ты любишь чай?</tg-spoiler>"},
+		{Role: "user", Content: "Yes"},
+	}
+	if !answersBotQuestion(message, botID, asked) {
+		t.Error("ответ на вопрос бота не должен считаться коротким сообщением")
+	}
+
+	// Вопросительный знак только в переводе не делает реплику вопросом
+	statement := []DialogMessage{
+		{Role: "assistant", Content: "Tea is great.\n<tg-spoiler>🇷🇺 Чай — это здорово? Да!</tg-spoiler>"},
+		{Role: "user", Content: "ok"},
+	}
+	if answersBotQuestion(message, botID, statement) {
+		t.Error("реплика без вопроса не должна засчитываться как ответ на вопрос")
+	}
+
+	reply := &tgbotapi.Message{
+		Text:           "Paris",
+		ReplyToMessage: &tgbotapi.Message{Text: "Where do you live?", From: &tgbotapi.User{ID: botID}},
+	}
+	if !answersBotQuestion(reply, botID, statement) {
+		t.Error("ответ на цитату с вопросом бота должен засчитываться")
+	}
+}
+
+func TestTakeShortHintRespectsInterval(t *testing.T) {
+	dc := NewDialogContext(1, models.LevelBeginner, "")
+	now := time.Now()
+
+	if !dc.takeShortHint(now) {
+		t.Fatal("первое напоминание должно показываться")
+	}
+	if dc.takeShortHint(now.Add(time.Hour)) {
+		t.Error("напоминание не должно повторяться чаще интервала")
+	}
+	if !dc.takeShortHint(now.Add(shortHintInterval + time.Minute)) {
+		t.Error("после интервала напоминание должно показываться снова")
+	}
+}
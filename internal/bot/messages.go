@@ -3,16 +3,96 @@ package bot
 import (
 	"fmt"
 	"lingua-ai/pkg/models"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Messages содержит все тексты сообщений бота
-type Messages struct{}
+type Messages struct {
+	locale Locale
+}
 
-// NewMessages создает новый экземпляр сообщений
+// NewMessages создает новый экземпляр сообщений с локалью по умолчанию
 func NewMessages() *Messages {
-	return &Messages{}
+	return &Messages{locale: DefaultLocale}
+}
+
+// SetLocale задает локаль форматирования чисел и дат
+func (m *Messages) SetLocale(locale Locale) {
+	m.locale = locale
+}
+
+// Number форматирует число по локали сообщений
+func (m *Messages) Number(n int) string {
+	return FormatNumber(n, m.locale)
+}
+
+// Date форматирует дату по локали сообщений
+func (m *Messages) Date(t time.Time) string {
+	return FormatDate(t, m.locale)
+}
+
+// progressBar создает текстовый прогресс-бар из 10 делений
+func progressBar(current, total int) string {
+	if total == 0 {
+		return "▱▱▱▱▱▱▱▱▱▱ 0%"
+	}
+
+	percentage := float64(current) / float64(total) * 100
+	filled := int(percentage / 10)
+
+	bar := ""
+	for i := 0; i < 10; i++ {
+		if i < filled {
+			bar += "▰"
+		} else {
+			bar += "▱"
+		}
+	}
+
+	return fmt.Sprintf("%s %.1f%%", bar, percentage)
+}
+
+// levelProgressRange возвращает прогресс внутри текущего ранга,
+// а для максимального ранга — прогресс до следующего рубежа общего XP
+func levelProgressRange(xp int) (current, total int) {
+	switch models.GetLevelByXP(xp) {
+	case models.LevelBeginner:
+		return xp - models.XPThresholdBeginner, models.XPThresholdIntermediate - models.XPThresholdBeginner
+	case models.LevelIntermediate:
+		return xp - models.XPThresholdIntermediate, models.XPThresholdAdvanced - models.XPThresholdIntermediate
+	default:
+		milestone := models.GetNextXPMilestone(xp)
+		return xp - (milestone - models.XPMilestoneStep), models.XPMilestoneStep
+	}
+}
+
+// levelProgressStep возвращает номер заполненного деления прогресс-бара (0-9)
+func levelProgressStep(xp int) int {
+	current, total := levelProgressRange(xp)
+	return current * 10 / total
+}
+
+// LevelProgress возвращает прогресс-бар до следующего ранга или рубежа XP
+func (m *Messages) LevelProgress(xp int) string {
+	current, total := levelProgressRange(xp)
+	bar := progressBar(current, total)
+
+	switch models.GetLevelByXP(xp) {
+	case models.LevelBeginner:
+		return fmt.Sprintf("%s\n🎯 До ранга Активист: %s XP", bar, m.Number(total-current))
+	case models.LevelIntermediate:
+		return fmt.Sprintf("%s\n🎯 До ранга Легенда: %s XP", bar, m.Number(total-current))
+	default:
+		return fmt.Sprintf("🏆 Максимальный ранг достигнут!\n%s\n🏅 До рубежа %s XP: %s XP",
+			bar, m.Number(models.GetNextXPMilestone(xp)), m.Number(total-current))
+	}
+}
+
+// XPProgress возвращает короткое сообщение о продвижении после получения XP
+func (m *Messages) XPProgress(gainedXP, totalXP int) string {
+	return fmt.Sprintf("⭐ +%d XP • всего %s XP\n%s", gainedXP, m.Number(totalXP), m.LevelProgress(totalXP))
 }
 
 // Welcome возвращает приветственное сообщение
@@ -28,10 +108,10 @@ func (m *Messages) Welcome(firstName, levelText string, xp int) string {
 	switch currentLevel {
 	case models.LevelBeginner:
 		levelEmoji = "🔵"
-		progressInfo = fmt.Sprintf("🎯 До среднего уровня: %d XP (%.1f%%)", xpForNext, progress)
+		progressInfo = fmt.Sprintf("🎯 До среднего уровня: %s XP (%.1f%%)", m.Number(xpForNext), progress)
 	case models.LevelIntermediate:
 		levelEmoji = "🟡"
-		progressInfo = fmt.Sprintf("🎯 До продвинутого уровня: %d XP (%.1f%%)", xpForNext, progress)
+		progressInfo = fmt.Sprintf("🎯 До продвинутого уровня: %s XP (%.1f%%)", m.Number(xpForNext), progress)
 	case models.LevelAdvanced:
 		levelEmoji = "🟢"
 		progressInfo = "🏆 Максимальный уровень достигнут!"
@@ -46,7 +126,7 @@ func (m *Messages) Welcome(firstName, levelText string, xp int) string {
 • Я исправлю ошибки и помогу понять правила
 • За правильные ответы — больше очков
 
-📊 <b>Твой уровень:</b> %s %s | ⭐ XP: %d
+📊 <b>Твой уровень:</b> %s %s | ⭐ XP: %s
 %s
 
 💡 <b>Система рангов:</b>
@@ -58,7 +138,7 @@ func (m *Messages) Welcome(firstName, levelText string, xp int) string {
 +3 XP — участие
 
 Try to write something in English 🚀`,
-		firstName, levelEmoji, levelText, xp, progressInfo)
+		firstName, levelEmoji, levelText, m.Number(xp), progressInfo)
 }
 
 // Help возвращает справку по командам
@@ -81,9 +161,21 @@ func (m *Messages) Help() string {
 📊 <b>Команды:</b>  
 • /learning — меню обучения  
 • /stats — твоя статистика и прогресс  
+• /compare — сравнение со средним учеником  
+• /review — разбор частых ошибок в твоих сообщениях  
 • /flashcards — словарные карточки для изучения  
 • /clear — очистить историю диалога  
 • /premium — управление подпиской  
+• /gift — подарить премиум другу  
+• /payments — история платежей  
+• /tour — пройти тур по боту заново  
+• /streakwarnings — вечерние напоминания о серии  
+• /reminders — ежедневные напоминания о занятиях  
+• /timezone — часовой пояс для дневного лимита и серии  
+• /streak — рейтинг серий занятий  
+• /idiom фраза — разбор английской идиомы  
+• /history <code>7d</code> или <code>30d</code> — диалог за период  
+• /language — выбрать изучаемый язык  
 • /help — справка  
 
 🎤 <b>Голосовые сообщения:</b>  
@@ -91,6 +183,12 @@ func (m *Messages) Help() string {
 
 📚 <b>Карточки:</b>  
 • /flashcards — изучай новые слова с интервальным повторением  
+• /cancel — завершить сессию карточек, сохранив прогресс  
+• /when <code>слово</code> — когда слово вернется на повторение  
+• /forget <code>слово</code> — вернуть выученное слово на повторение  
+• /find <code>запрос</code> — найти изученное слово или перевод  
+• /pace — сколько новых слов в день: 5, 10 или 20  
+• /weeklytarget — цель: сколько слов выучить за неделю  
 • Алгоритм запоминания подстраивается под твой прогресс  
 
 💎 <b>Премиум-подписка:</b>  
@@ -103,35 +201,22 @@ func (m *Messages) Help() string {
 }
 
 // Stats возвращает статистику пользователя
-func (m *Messages) Stats(firstName, levelText string, xp, studyStreak int, lastStudyDate string) string {
-	xpForNext, _ := models.GetXPForNextLevel(xp)
-	progress := models.GetLevelProgress(xp)
-
-	var progressInfo string
-	currentLevel := models.GetLevelByXP(xp)
-
-	switch currentLevel {
-	case models.LevelBeginner:
-		progressInfo = fmt.Sprintf("🎯 До ранга активист: %d XP (%.1f%%)", xpForNext, progress)
-	case models.LevelIntermediate:
-		progressInfo = fmt.Sprintf("🎯 До продвинутого легенда: %d XP (%.1f%%)", xpForNext, progress)
-	case models.LevelAdvanced:
-		progressInfo = "🏆 Максимальный ранг достигнут!"
-	}
-
+func (m *Messages) Stats(firstName, levelText string, xp, studyStreak int, lastStudyDate time.Time) string {
 	return fmt.Sprintf(`📊 <b>Твоя статистика</b>
 
 👤 <b>Пользователь:</b> %s  
 📈 <b>Уровень английского:</b> %s  
-⭐ <b>Опыт:</b> %d XP  
+⭐ <b>Опыт:</b> %s XP  
 %s  
 🔥 <b>Серия дней:</b> %d подряд  
 📅 <b>Последнее изучение:</b> %s  
 
 💡 <b>Ранг:</b>  
-🔵 Новичок : 0 — 9,999 XP  
-🟡 Активист : 10,000 — 19,999 XP  
-🟢 Легенда: 20,000+ XP`, firstName, levelText, xp, progressInfo, studyStreak, lastStudyDate)
+🔵 Новичок : 0 — %s XP  
+🟡 Активист : %s — %s XP  
+🟢 Легенда: %s+ XP`, firstName, levelText, m.Number(xp), m.LevelProgress(xp), studyStreak, m.Date(lastStudyDate),
+		m.Number(models.XPThresholdIntermediate-1), m.Number(models.XPThresholdIntermediate),
+		m.Number(models.XPThresholdAdvanced-1), m.Number(models.XPThresholdAdvanced))
 }
 
 // ChatCleared возвращает сообщение об очистке истории
@@ -144,6 +229,31 @@ func (m *Messages) UnknownCommand() string {
 	return "⚠️ Неизвестная команда. Используй <b>/help</b> для справки."
 }
 
+// ShortMessageHint возвращает мягкую просьбу писать развернутыми предложениями
+func (m *Messages) ShortMessageHint(fullXP int) string {
+	return fmt.Sprintf("💬 Попробуй ответить целым предложением — так быстрее запомнишь слова, "+
+		"а за развернутое сообщение начисляется +%d XP.", fullXP)
+}
+
+// UnsupportedLanguage возвращает ответ на сообщение не на русском и не на изучаемом языке
+func (m *Messages) UnsupportedLanguage(language models.LearningLanguage, offerTranslation bool) string {
+	text := fmt.Sprintf("🌍 Похоже, это сообщение не на русском и не на %s.\n\n"+
+		"Я помогаю учить <b>%s</b>: пиши мне на %s, а если не знаешь, как сказать, — спроси по-русски.",
+		language.Prepositional, language.Accusative, language.Prepositional)
+	if offerTranslation {
+		text += fmt.Sprintf("\n\nМогу перевести твое сообщение на %s 👇", language.Accusative)
+	}
+	return text
+}
+
+// AIUnavailable возвращает баннер режима без AI
+func (m *Messages) AIUnavailable() string {
+	return "🛠 <b>Чат временно недоступен</b>\n\n" +
+		"Сейчас я не могу отвечать на сообщения, но заниматься можно и без чата. " +
+		"Как только чат заработает, просто напиши мне снова.\n\n" +
+		"Пока доступны карточки, тест уровня, набор недели и фраза дня 👇"
+}
+
 // Error возвращает сообщение об ошибке
 func (m *Messages) Error(message string) string {
 	return fmt.Sprintf("❌ <b>Ошибка:</b> %s\n\nПопробуйте позже или обратитесь к администратору.", message)
@@ -151,19 +261,12 @@ func (m *Messages) Error(message string) string {
 
 // GetMainKeyboard возвращает основную клавиатуру
 func (m *Messages) GetMainKeyboard() [][]string {
-	return [][]string{
-		{"📚 Обучение", "📊 Статистика"},
-		{"🏆 Рейтинг", "💎 Премиум"},
-		{"🔗 Реферальная ссылка", "❓ Помощь"},
-		{"🗑 Очистить диалог"},
-	}
+	return replyKeyboardRows(mainMenuLayout)
 }
 
+// GetLearningKeyboard возвращает клавиатуру меню обучения
 func (m *Messages) GetLearningKeyboard() [][]string {
-	return [][]string{
-		{"📝 Словарные карточки", "🎓 Тест уровня"},
-		{"🔙 Назад в главное меню"},
-	}
+	return replyKeyboardRows(learningMenuLayout)
 }
 
 func (m *Messages) LevelTestIntro() string {
@@ -206,37 +309,21 @@ func (m *Messages) LevelTestQuestion(questionNum, totalQuestions int, question s
 
 // GetLevelTestKeyboard возвращает клавиатуру для теста уровня
 func (m *Messages) GetLevelTestKeyboard() [][]string {
-	return [][]string{
-		{"🎯 Начать тест"},
-		{"🔙 Назад к меню"},
-	}
+	return replyKeyboardRows(levelTestMenuLayout)
 }
 
 // GetActiveTestKeyboard возвращает клавиатуру для активного теста
 func (m *Messages) GetActiveTestKeyboard() [][]string {
-	return [][]string{
-		{"❌ Отменить тест"},
-		{"🔙 Назад к меню"},
-	}
+	return replyKeyboardRows(activeTestMenuLayout)
 }
 
 // GetTestAnswerKeyboard возвращает клавиатуру с вариантами ответов для теста
 func (m *Messages) GetTestAnswerKeyboard(options []string) [][]tgbotapi.InlineKeyboardButton {
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 
-	// Добавляем кнопки с короткими номерами (варианты показаны в тексте сообщения)
-	buttonTexts := []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣"}
-
+	// Кнопки с короткими номерами (варианты показаны в тексте сообщения)
 	for i := range options {
-		buttonText := buttonTexts[i]
-		if i >= len(buttonTexts) {
-			buttonText = fmt.Sprintf("%d", i+1)
-		}
-
-		button := tgbotapi.NewInlineKeyboardButtonData(
-			buttonText,
-			fmt.Sprintf("test_answer_%d", i),
-		)
+		button := tgbotapi.NewInlineKeyboardButtonData(testAnswerLabel(i), testAnswerCallback(i))
 		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
 	}
 
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevelProgress(t *testing.T) {
+	m := NewMessages()
+
+	tests := []struct {
+		name     string
+		xp       int
+		contains []string
+	}{
+		{"начало ранга", 0, []string{"▱▱▱▱▱▱▱▱▱▱ 0.0%", "До ранга Активист: 10 000 XP"}},
+		{"середина среднего ранга", 15000, []string{"▰▰▰▰▰▱▱▱▱▱ 50.0%", "До ранга Легенда: 5 000 XP"}},
+		{"максимальный ранг", 27500, []string{"Максимальный ранг", "До рубежа 30 000 XP: 2 500 XP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.LevelProgress(tt.xp)
+			for _, part := range tt.contains {
+				if !strings.Contains(got, part) {
+					t.Errorf("ожидалось %q в %q", part, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelProgressStep(t *testing.T) {
+	if levelProgressStep(999) == levelProgressStep(1000) {
+		t.Error("ожидалась смена деления на 1000 XP")
+	}
+	if levelProgressStep(1000) != levelProgressStep(1999) {
+		t.Error("не ожидалась смена деления внутри одного шага")
+	}
+}
+
+func TestMessagesLocale(t *testing.T) {
+	m := NewMessages()
+	if got := m.Number(12345); got != "12\u00a0345" {
+		t.Errorf("по умолчанию ожидался русский формат, получено %q", got)
+	}
+
+	m.SetLocale(LocaleEN)
+	if got := m.Number(12345); got != "12,345" {
+		t.Errorf("ожидался английский формат, получено %q", got)
+	}
+}
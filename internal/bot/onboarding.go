@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// onboardingStep шаг тура по боту
+type onboardingStep struct {
+	title string
+	text  string
+}
+
+// onboardingSteps шаги тура. Шаги не меняют состояние пользователя,
+// поэтому тур можно проходить сколько угодно раз.
+var onboardingSteps = []onboardingStep{
+	{
+		title: "💬 Общение на английском",
+		text: `Просто пиши мне на английском — я отвечу, исправлю ошибки и объясню правила.
+
+Если не знаешь, как сказать, пиши по-русски: я переведу и помогу сформулировать.`,
+	},
+	{
+		title: "🎤 Голосовые сообщения",
+		text: `Отправь голосовое на английском — я распознаю речь и отвечу.
+
+Под ответами есть кнопка озвучки, чтобы слушать произношение.`,
+	},
+	{
+		title: "📚 Обучение",
+		text: `В меню «📚 Обучение» есть:
+• 📝 Словарные карточки с интервальным повторением
+• 📦 Набор слов недели
+• Упражнения под твой уровень`,
+	},
+	{
+		title: "⭐ XP и уровень",
+		text: `За каждое сообщение начисляется XP:
++15 XP — правильно, +10 XP — попытка, +3 XP — участие.
+
+Пройди «🎯 Тест уровня», чтобы задания подходили именно тебе, и следи за рейтингом в «🏆 Рейтинг».`,
+	},
+	{
+		title: "💎 Премиум и друзья",
+		text: `Премиум снимает лимит сообщений и увеличивает длину голосовых.
+
+Пригласи друзей по «🔗 Реферальной ссылке» или подари премиум командой /gift.`,
+	},
+}
+
+// shouldAutoStartOnboarding определяет, нужно ли показать тур новому пользователю
+func shouldAutoStartOnboarding(user *models.User) bool {
+	return user.OnboardingCompletedAt == nil && user.XP == 0
+}
+
+// handleTourCommand обрабатывает команду /tour — повторный запуск тура
+func (h *Handler) handleTourCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	return h.startOnboarding(message.Chat.ID)
+}
+
+// startOnboarding отправляет первый шаг тура новым сообщением
+func (h *Handler) startOnboarding(chatID int64) error {
+	msg := tgbotapi.NewMessage(chatID, onboardingStepText(0))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = onboardingKeyboard(0)
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handleOnboardingCallback обрабатывает кнопки тура: tour_step_<n>, tour_skip, tour_finish
+func (h *Handler) handleOnboardingCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch data := callback.Data; {
+	case strings.HasPrefix(data, "tour_step_"):
+		step, err := strconv.Atoi(strings.TrimPrefix(data, "tour_step_"))
+		if err != nil || step < 0 || step >= len(onboardingSteps) {
+			h.logger.Warn("неверный шаг тура", zap.String("data", data))
+			return nil
+		}
+
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, onboardingStepText(step))
+		editMsg.ParseMode = "HTML"
+		keyboard := onboardingKeyboard(step)
+		editMsg.ReplyMarkup = &keyboard
+
+		_, err = h.sender.Send(editMsg)
+		return err
+
+	case data == "tour_skip":
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID,
+			"👌 Тур пропущен. Вернуться к нему можно в любой момент командой /tour")
+		_, err := h.sender.Send(editMsg)
+		return err
+
+	case data == "tour_finish":
+		bonusGranted, err := h.userService.CompleteOnboarding(ctx, user.ID)
+		if err != nil {
+			h.logger.Error("ошибка завершения тура", zap.Error(err), zap.Int64("user_id", user.ID))
+			return h.sendErrorMessage(chatID, "Не удалось завершить тур")
+		}
+
+		text := "✅ <b>Тур пройден!</b>\n\nНапиши что-нибудь на английском, чтобы начать 🚀"
+		if bonusGranted {
+			text = fmt.Sprintf("🎉 <b>Тур пройден!</b>\n\n+%d XP за знакомство с ботом.\n\nНапиши что-нибудь на английском, чтобы начать 🚀",
+				models.OnboardingBonusXP)
+		}
+
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		editMsg.ParseMode = "HTML"
+		_, err = h.sender.Send(editMsg)
+		return err
+
+	default:
+		h.logger.Warn("неизвестный callback тура", zap.String("data", data))
+		return nil
+	}
+}
+
+// onboardingStepText формирует текст шага тура
+func onboardingStepText(step int) string {
+	s := onboardingSteps[step]
+	return fmt.Sprintf("🧭 <b>Тур по боту (%d/%d)</b>\n\n<b>%s</b>\n\n%s",
+		step+1, len(onboardingSteps), s.title, s.text)
+}
+
+// onboardingKeyboard формирует кнопки навигации по туру
+func onboardingKeyboard(step int) tgbotapi.InlineKeyboardMarkup {
+	var navigation []tgbotapi.InlineKeyboardButton
+	if step > 0 {
+		navigation = append(navigation,
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("tour_step_%d", step-1)))
+	}
+	if step < len(onboardingSteps)-1 {
+		navigation = append(navigation,
+			tgbotapi.NewInlineKeyboardButtonData("Далее ▶️", fmt.Sprintf("tour_step_%d", step+1)))
+	} else {
+		navigation = append(navigation,
+			tgbotapi.NewInlineKeyboardButtonData("✅ Завершить тур", "tour_finish"))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{navigation}
+	if step < len(onboardingSteps)-1 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", "tour_skip"),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
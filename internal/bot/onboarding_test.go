@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestShouldAutoStartOnboarding(t *testing.T) {
+	completed := time.Now()
+
+	tests := []struct {
+		name     string
+		user     models.User
+		expected bool
+	}{
+		{"новый пользователь", models.User{}, true},
+		{"уже есть XP", models.User{XP: 10}, false},
+		{"тур уже пройден", models.User{OnboardingCompletedAt: &completed}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldAutoStartOnboarding(&tt.user); got != tt.expected {
+				t.Errorf("ожидалось %v, получено %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOnboardingKeyboard(t *testing.T) {
+	first := onboardingKeyboard(0)
+	if data := *first.InlineKeyboard[0][0].CallbackData; data != "tour_step_1" {
+		t.Errorf("на первом шаге ожидалась кнопка tour_step_1, получено %s", data)
+	}
+
+	last := onboardingKeyboard(len(onboardingSteps) - 1)
+	if len(last.InlineKeyboard) != 1 {
+		t.Fatalf("на последнем шаге ожидался 1 ряд кнопок, получено %d", len(last.InlineKeyboard))
+	}
+	row := last.InlineKeyboard[0]
+	if data := *row[len(row)-1].CallbackData; data != "tour_finish" {
+		t.Errorf("на последнем шаге ожидалась кнопка tour_finish, получено %s", data)
+	}
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// paceCallbackPrefix префикс кнопок выбора темпа: pace_<новых слов в день>
+const paceCallbackPrefix = "pace_"
+
+// handlePaceCommand обрабатывает команду /pace [число] — сколько новых слов в день
+func (h *Handler) handlePaceCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		return h.showPace(ctx, chatID, user)
+	}
+
+	perDay, err := strconv.Atoi(arg)
+	if err != nil {
+		return h.sendMessage(chatID, fmt.Sprintf("🔢 Укажите число от %d до %d: <code>/pace 10</code>",
+			models.MinNewCardsPerDay, models.MaxNewCardsPerDay))
+	}
+	return h.setPace(ctx, chatID, user, perDay)
+}
+
+// handlePaceCallback обрабатывает кнопку выбора темпа
+func (h *Handler) handlePaceCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	perDay, err := strconv.Atoi(strings.TrimPrefix(callback.Data, paceCallbackPrefix))
+	if err != nil {
+		h.logger.Warn("неверный темп в кнопке выбора", zap.String("data", callback.Data))
+		return nil
+	}
+	return h.setPace(ctx, callback.Message.Chat.ID, user, perDay)
+}
+
+// showPace показывает текущий темп, прогресс за сегодня и кнопки выбора темпа
+func (h *Handler) showPace(ctx context.Context, chatID int64, u *models.User) error {
+	introduced, pace, err := h.flashcardHandler.flashcardService.DailyNewCards(ctx, u.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения темпа новых слов", zap.Error(err), zap.Int64("user_id", u.ID))
+		return h.sendErrorMessage(chatID, "Не удалось получить темп изучения")
+	}
+
+	text := fmt.Sprintf(`🐢 <b>Темп изучения</b>
+
+Сейчас: <b>%d</b> новых слов в день
+Сегодня начато: %d из %d
+
+Выберите темп или укажите свой: <code>/pace 15</code> (от %d до %d)`,
+		pace, min(introduced, pace), pace, models.MinNewCardsPerDay, models.MaxNewCardsPerDay)
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, option := range models.NewCardPaceOptions {
+		label := strconv.Itoa(option)
+		if option == pace {
+			label = "✅ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, paceCallbackPrefix+strconv.Itoa(option)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+
+	_, err = h.sender.Send(msg)
+	return err
+}
+
+// setPace сохраняет темп и предупреждает, если новых слов уровня меньше выбранного темпа
+func (h *Handler) setPace(ctx context.Context, chatID int64, u *models.User, perDay int) error {
+	if err := h.userService.SetNewCardsPerDay(ctx, u.ID, perDay); err != nil {
+		if errors.Is(err, user.ErrInvalidPace) {
+			return h.sendMessage(chatID, fmt.Sprintf("⚠️ Темп должен быть от %d до %d новых слов в день.",
+				models.MinNewCardsPerDay, models.MaxNewCardsPerDay))
+		}
+		h.logger.Error("ошибка сохранения темпа новых слов", zap.Error(err), zap.Int64("user_id", u.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить темп изучения")
+	}
+	u.NewCardsPerDay = perDay
+
+	text := fmt.Sprintf("✅ Темп сохранен: <b>%d</b> новых слов в день.", perDay)
+
+	level := u.Level
+	if level == "" {
+		level = models.LevelBeginner
+	}
+	available, err := h.flashcardHandler.flashcardService.AvailableNewCards(ctx, u.ID, level)
+	if err != nil {
+		h.logger.Warn("не удалось посчитать доступные новые карточки", zap.Error(err), zap.Int64("user_id", u.ID))
+	} else if available < perDay {
+		text += fmt.Sprintf("\n\n⚠️ Для вашего уровня сейчас доступно только %d новых слов — "+
+			"норма может не набираться, пока не появятся новые карточки.", available)
+	}
+
+	return h.sendMessage(chatID, text)
+}
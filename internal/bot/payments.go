@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// maxPaymentHistoryItems сколько последних платежей показывает /payments
+const maxPaymentHistoryItems = 20
+
+// handlePaymentsCommand обрабатывает команду /payments — история платежей пользователя
+func (h *Handler) handlePaymentsCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	payments, err := h.premiumService.GetPaymentHistory(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения истории платежей", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(message.Chat.ID, "Не удалось загрузить историю платежей")
+	}
+
+	if len(payments) == 0 {
+		return h.sendMessage(message.Chat.ID, "💳 Платежей пока не было. Оформить подписку: /premium")
+	}
+	loc := user.Location(h.premiumService.ResetLocation())
+	return h.sendMessage(message.Chat.ID, formatPaymentHistory(payments, loc, h.messages.locale))
+}
+
+// formatPaymentHistory оформляет список платежей: дата, сумма, срок премиума и статус.
+// Даты показываются в поясе loc.
+func formatPaymentHistory(payments []*models.Payment, loc *time.Location, locale Locale) string {
+	var b strings.Builder
+	b.WriteString("💳 <b>История платежей</b>\n")
+
+	shown := payments
+	if len(shown) > maxPaymentHistoryItems {
+		shown = shown[:maxPaymentHistoryItems]
+	}
+	for _, payment := range shown {
+		amount := formatPaymentAmount(payment.Amount, payment.Currency, locale)
+		if payment.Metadata["type"] == "gift" {
+			amount = "🎁 подарок"
+		}
+		fmt.Fprintf(&b, "\n%s <b>%s</b> — %s, премиум на %d дн.\n<code>%s</code>\n",
+			FormatDate(payment.CreatedAt.In(loc), locale),
+			amount,
+			paymentStatusText(payment.Status),
+			payment.PremiumDurationDays,
+			html.EscapeString(payment.PaymentID))
+	}
+
+	if len(payments) > len(shown) {
+		fmt.Fprintf(&b, "\n<i>Показаны последние %d из %d платежей.</i>", len(shown), len(payments))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatPaymentAmount форматирует сумму с валютой по локали: копейки показываются,
+// только если они есть
+func formatPaymentAmount(amount float64, currency string, locale Locale) string {
+	cents := int(math.Round(amount * 100))
+	whole := FormatNumber(cents/100, locale)
+	if cents%100 == 0 {
+		return whole + " " + currency
+	}
+
+	separator := ","
+	if locale == LocaleEN {
+		separator = "."
+	}
+	return fmt.Sprintf("%s%s%02d %s", whole, separator, cents%100, currency)
+}
+
+// paymentStatusText статус платежа со значком, чтобы неоплаченные платежи были заметны
+func paymentStatusText(status string) string {
+	switch status {
+	case "succeeded", "completed":
+		return "✅ оплачен"
+	case "pending":
+		return "⏳ ожидает оплаты"
+	case "canceled", "cancelled":
+		return "🚫 отменен"
+	case "failed":
+		return "❌ ошибка оплаты"
+	default:
+		return "❔ " + status
+	}
+}
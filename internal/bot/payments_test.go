@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// memoryPayments платежи в памяти; остальные методы не нужны тестам истории
+type memoryPayments struct {
+	premium.PaymentRepository
+	payments []*models.Payment
+}
+
+func (r *memoryPayments) GetByUserID(ctx context.Context, userID int64) ([]*models.Payment, error) {
+	var result []*models.Payment
+	for _, payment := range r.payments {
+		if payment.UserID == userID {
+			result = append(result, payment)
+		}
+	}
+	return result, nil
+}
+
+func TestPaymentsCommandShowsAllStatuses(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	u := th.user(t, 100)
+
+	payments := &memoryPayments{payments: []*models.Payment{
+		{UserID: u.ID, Amount: 299, Currency: "RUB", PaymentID: "p-3", Status: "pending", PremiumDurationDays: 30,
+			CreatedAt: time.Date(2026, 3, 12, 10, 0, 0, 0, time.UTC)},
+		{UserID: u.ID, Amount: 99.5, Currency: "RUB", PaymentID: "p-2", Status: "canceled", PremiumDurationDays: 7,
+			CreatedAt: time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)},
+		{UserID: u.ID, Amount: 299, Currency: "RUB", PaymentID: "p-1", Status: "succeeded", PremiumDurationDays: 30,
+			CreatedAt: time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)},
+		{UserID: u.ID + 1, Amount: 1, Currency: "RUB", PaymentID: "other", Status: "succeeded"},
+	}}
+	th.handler.premiumService = premium.NewService(th.store.users, payments, nil, zap.NewNop())
+
+	th.sender.reset()
+	th.sendText(t, 100, "/payments")
+
+	texts := th.sender.texts()
+	if len(texts) != 1 {
+		t.Fatalf("ожидалось одно сообщение, получено %q", texts)
+	}
+	reply := texts[0]
+	for _, want := range []string{
+		"12.03.2026 <b>299 RUB</b> — ⏳ ожидает оплаты, премиум на 30 дн.",
+		"11.03.2026 <b>99,50 RUB</b> — 🚫 отменен, премиум на 7 дн.",
+		"01.02.2026 <b>299 RUB</b> — ✅ оплачен, премиум на 30 дн.",
+		"<code>p-1</code>",
+	} {
+		if !strings.Contains(reply, want) {
+			t.Errorf("в истории платежей ожидалось %q, получено:\n%s", want, reply)
+		}
+	}
+	if strings.Contains(reply, "other") {
+		t.Error("в истории не должно быть чужих платежей")
+	}
+	if strings.Index(reply, "p-3") > strings.Index(reply, "p-1") {
+		t.Error("платежи должны идти от новых к старым")
+	}
+}
+
+func TestPaymentsCommandWithoutPayments(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	th.handler.premiumService = premium.NewService(th.store.users, &memoryPayments{}, nil, zap.NewNop())
+
+	th.sender.reset()
+	th.sendText(t, 100, "/payments")
+
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "Платежей пока не было") {
+		t.Errorf("ожидалось сообщение об отсутствии платежей, получено %q", texts)
+	}
+}
+
+func TestPaymentsCommandUsesUserTimezone(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	u := th.user(t, 100)
+	th.store.users.mu.Lock()
+	th.store.users.users[u.ID].Timezone = "Asia/Tokyo"
+	th.store.users.mu.Unlock()
+
+	// 20:00 UTC 11 марта — в Токио уже 12 марта
+	payments := &memoryPayments{payments: []*models.Payment{
+		{UserID: u.ID, Amount: 12500, Currency: "RUB", PaymentID: "p-1", Status: "succeeded", PremiumDurationDays: 365,
+			CreatedAt: time.Date(2026, 3, 11, 20, 0, 0, 0, time.UTC)},
+	}}
+	th.handler.premiumService = premium.NewService(th.store.users, payments, nil, zap.NewNop())
+
+	th.sender.reset()
+	th.sendText(t, 100, "/payments")
+
+	texts := th.sender.texts()
+	if reply := texts[len(texts)-1]; !strings.Contains(reply, "12.03.2026 <b>12\u00a0500 RUB</b>") {
+		t.Errorf("ожидались дата в поясе пользователя и сумма с разделителем разрядов, получено:\n%s", reply)
+	}
+}
+
+func TestFormatPaymentAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		locale Locale
+		want   string
+	}{
+		{299, LocaleRU, "299 RUB"},
+		{99.5, LocaleRU, "99,50 RUB"},
+		{1499.99, LocaleEN, "1,499.99 RUB"},
+	}
+	for _, tt := range tests {
+		if got := formatPaymentAmount(tt.amount, "RUB", tt.locale); got != tt.want {
+			t.Errorf("для %v (%s) ожидалось %q, получено %q", tt.amount, tt.locale, tt.want, got)
+		}
+	}
+}
+
+func TestPremiumCommandShowsExpiryInUserTimezone(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	u := th.user(t, 100)
+
+	// 20:00 UTC 11 марта — в Токио уже 12 марта
+	expiresAt := time.Date(2099, 3, 11, 20, 0, 0, 0, time.UTC)
+	th.store.users.mu.Lock()
+	stored := th.store.users.users[u.ID]
+	stored.Timezone = "Asia/Tokyo"
+	stored.IsPremium = true
+	stored.PremiumExpiresAt = &expiresAt
+	th.store.users.mu.Unlock()
+	th.handler.premiumService = premium.NewService(th.store.users, &memoryPayments{}, nil, zap.NewNop())
+
+	th.sender.reset()
+	th.sendText(t, 100, "/premium")
+
+	texts := th.sender.texts()
+	if len(texts) == 0 || !strings.Contains(texts[len(texts)-1], "Действует до: 12.03.2099") {
+		t.Errorf("ожидалась дата окончания в поясе пользователя, получено %q", texts)
+	}
+}
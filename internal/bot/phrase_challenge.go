@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/challenge"
+	"lingua-ai/internal/pronunciation"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// PhraseChallengeTTL время, в течение которого ожидается голосовое с фразой дня
+const PhraseChallengeTTL = 30 * time.Minute
+
+// phraseSession активная попытка произнести фразу дня
+type phraseSession struct {
+	phrase    string
+	createdAt time.Time
+}
+
+// SetPhraseChallenge подключает челлендж «Фраза дня» (nil — челлендж выключен)
+func (h *Handler) SetPhraseChallenge(service *challenge.Service) {
+	h.phraseChallenge = service
+}
+
+// handlePhraseOfDayButton открывает фразу дня
+func (h *Handler) handlePhraseOfDayButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	return h.startPhraseChallenge(ctx, message.Chat.ID, user)
+}
+
+// startPhraseChallenge озвучивает фразу дня и ждет голосовое с ее произношением
+func (h *Handler) startPhraseChallenge(ctx context.Context, chatID int64, user *models.User) error {
+	if h.phraseChallenge == nil {
+		return h.sendMessage(chatID, "🗣 Фраза дня сейчас недоступна.")
+	}
+	if h.ttsService == nil {
+		return h.sendMessage(chatID, "🗣 Фраза дня временно недоступна: озвучка отключена.")
+	}
+
+	phrase := h.phraseChallenge.TodayPhrase(user.Level)
+	audioData, err := h.ttsService.SynthesizeText(ctx, phrase)
+	if err != nil {
+		h.logger.Error("ошибка озвучки фразы дня", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось озвучить фразу дня")
+	}
+
+	caption := fmt.Sprintf("🗣 <b>Фраза дня</b>\n\n<i>%s</i>\n\n🎤 Послушай и отправь голосовое, в котором повторяешь эту фразу.",
+		html.EscapeString(phrase))
+	if stats, err := h.phraseChallenge.Stats(ctx, user.ID); err != nil {
+		h.logger.Warn("не удалось получить статистику фразы дня", zap.Error(err), zap.Int64("user_id", user.ID))
+	} else {
+		caption += "\n\n" + phraseChallengeStatsText(stats)
+	}
+
+	audio := tgbotapi.NewAudio(chatID, tgbotapi.FileBytes{
+		Name:  "phrase_of_day.wav",
+		Bytes: audioData,
+	})
+	audio.Caption = caption
+	audio.ParseMode = "HTML"
+
+	if _, err := h.sender.Send(audio); err != nil {
+		return err
+	}
+
+	h.phraseMutex.Lock()
+	h.activePhrases[user.ID] = &phraseSession{phrase: phrase, createdAt: time.Now()}
+	h.phraseMutex.Unlock()
+
+	return nil
+}
+
+// hasActivePhraseChallenge проверяет, ждет ли бот голосовое с фразой дня
+func (h *Handler) hasActivePhraseChallenge(userID int64) bool {
+	h.phraseMutex.Lock()
+	defer h.phraseMutex.Unlock()
+
+	session, ok := h.activePhrases[userID]
+	return ok && time.Since(session.createdAt) <= PhraseChallengeTTL
+}
+
+// takePhraseChallenge возвращает и завершает активную попытку пользователя
+func (h *Handler) takePhraseChallenge(userID int64) (*phraseSession, bool) {
+	h.phraseMutex.Lock()
+	defer h.phraseMutex.Unlock()
+
+	session, ok := h.activePhrases[userID]
+	if !ok {
+		return nil, false
+	}
+	delete(h.activePhrases, userID)
+
+	if time.Since(session.createdAt) > PhraseChallengeTTL {
+		return nil, false
+	}
+	return session, true
+}
+
+// handlePhraseChallengeAnswer оценивает распознанное голосовое с фразой дня
+func (h *Handler) handlePhraseChallengeAnswer(ctx context.Context, chatID int64, user *models.User, transcript string) error {
+	session, ok := h.takePhraseChallenge(user.ID)
+	if !ok {
+		return h.sendMessage(chatID, "⏰ Время попытки истекло. Открой «🗣 Фраза дня» еще раз.")
+	}
+
+	comparison := pronunciation.Compare(session.phrase, transcript)
+	result, err := h.phraseChallenge.RecordAttempt(ctx, user.ID, float64(comparison.Score)/100)
+	if err != nil {
+		h.logger.Error("ошибка сохранения попытки фразы дня", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить результат")
+	}
+
+	h.updateStudyActivity(user)
+	if result.XP > 0 {
+		h.addXP(user, result.XP)
+		h.userMetrics.RecordXP(user.ID, result.XP, "phrase_of_day")
+	}
+
+	var verdict string
+	switch {
+	case comparison.Score == 100:
+		verdict = "🎉 <b>Идеально!</b> Звучит как у носителя."
+	case result.Passed:
+		verdict = "👏 <b>Отлично!</b> Фраза засчитана."
+	default:
+		verdict = fmt.Sprintf("💪 <b>Почти!</b> Для зачета нужно от %.0f%%. Послушай еще раз и повтори.",
+			h.phraseChallenge.MinScore()*100)
+	}
+
+	text := fmt.Sprintf("%s\n\n🎯 Совпадение: <b>%d%%</b>\n📝 Фраза: <i>%s</i>",
+		verdict, comparison.Score, html.EscapeString(session.phrase))
+	if missed := comparison.Mismatched(); len(missed) > 0 && comparison.Score < 100 {
+		text += fmt.Sprintf("\n❗ Не расслышал: %s", html.EscapeString(strings.Join(missed, ", ")))
+	}
+	if result.XP > 0 {
+		text += fmt.Sprintf("\n\n⭐ +%d XP", result.XP)
+	}
+	text += "\n\n" + phraseChallengeStatsText(&result.Stats)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Попробовать еще раз", challenge.CallbackData),
+		),
+	)
+
+	_, err = h.sender.Send(msg)
+	return err
+}
+
+// phraseChallengeStatsText формирует строку с серией и лучшим результатом дня
+func phraseChallengeStatsText(stats *models.PhraseChallengeStats) string {
+	text := fmt.Sprintf("🔥 Серия фразы дня: %d дн. подряд", stats.Streak)
+	if stats.PlayedToday {
+		text += fmt.Sprintf(" • Лучший результат сегодня: %.0f%%", stats.TodayBest*100)
+	}
+	return text
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// telegramMessageLimit максимальная длина одной части сообщения (лимит Telegram — 4096)
+const telegramMessageLimit = 4000
+
+// splitTagReserve запас символов на закрывающие теги в каждой части
+const splitTagReserve = 64
+
+// PostProcessOptions определяет, какие стадии обработки применяются к тексту перед отправкой
+type PostProcessOptions struct {
+	Markdown      bool // **жирный** и # заголовки → HTML
+	EnsureSpoiler bool // перевод переносится в <tg-spoiler>
+	EnglishOnly   bool // остается только английская часть, перевод отбрасывается
+	StripHTML     bool // все теги удаляются, HTML-сущности декодируются
+	MaxLength     int  // длина части при разбиении; 0 — не разбивать
+}
+
+var (
+	// aiReplyOptions — ответы собеседника: разметка модели и перевод в спойлере
+	aiReplyOptions = PostProcessOptions{Markdown: true, EnsureSpoiler: true}
+	// aiTextOptions — прочие ответы AI: упражнения, объяснения, ответы на голосовые
+	aiTextOptions = PostProcessOptions{Markdown: true}
+	// historyOptions — текст для сохранения в историю диалога
+	historyOptions = PostProcessOptions{EnglishOnly: true}
+	// plainTextOptions — текст без разметки: подписи, озвучка, fallback при ошибке HTML
+	plainTextOptions = PostProcessOptions{StripHTML: true}
+	// sendOptions применяются к каждому исходящему сообщению
+	sendOptions = PostProcessOptions{MaxLength: telegramMessageLimit}
+)
+
+// allowedTelegramTags теги, которые Telegram поддерживает в режиме HTML
+var allowedTelegramTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "ins": true,
+	"s": true, "strike": true, "del": true, "a": true, "code": true, "pre": true,
+	"tg-spoiler": true, "blockquote": true,
+}
+
+var (
+	// telegramTagRegexp находит открывающие и закрывающие теги; "5 < 6" тегом не считается
+	telegramTagRegexp = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9-]*)(?:\s[^<>]*)?/?>`)
+
+	breakTagRegexp     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	ruleTagRegexp      = regexp.MustCompile(`(?i)<hr\s*/?>`)
+	listItemRegexp     = regexp.MustCompile(`(?i)<li(?:\s[^<>]*)?>`)
+	blockCloseRegexp   = regexp.MustCompile(`(?i)</(?:ul|ol|li|div|p|h[1-6])>`)
+	markdownBoldRegexp = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	markdownHeadRegexp = regexp.MustCompile(`(?m)^#{1,6}\s+(.+?)\s*$`)
+	markdownRuleRegexp = regexp.MustCompile(`(?m)^\s*(?:-{3,}|_{3,}|\*{3,})\s*$`)
+	extraNewlineRegexp = regexp.MustCompile(`\n{3,}`)
+	htmlEntityRegexp   = regexp.MustCompile(`^&(?:#[0-9]+|#x[0-9a-fA-F]+|[a-zA-Z]+);`)
+)
+
+// PostProcess приводит ответ к виду, который Telegram корректно отобразит.
+// Стадии выполняются в фиксированном порядке: блочные теги → Markdown → белый список тегов →
+// спойлер с переводом → удаление разметки → разбиение по длине.
+func PostProcess(text string, opts PostProcessOptions) []string {
+	return splitMessage(postProcessText(text, opts), opts.MaxLength)
+}
+
+// postProcessText выполняет все стадии PostProcess, кроме разбиения по длине
+func postProcessText(text string, opts PostProcessOptions) string {
+	text = normalizeBlockTags(text)
+	if opts.Markdown {
+		text = markdownToHTML(text)
+	}
+	text = filterTags(text)
+
+	if opts.EnsureSpoiler {
+		text = ensureResponseFormat(text)
+	}
+	if opts.EnglishOnly {
+		// Отделяем перевод даже если модель не использовала спойлер
+		text, _ = splitAIResponse(text)
+	}
+
+	if opts.StripHTML {
+		text = stripHTML(text)
+	}
+
+	return strings.TrimSpace(extraNewlineRegexp.ReplaceAllString(text, "\n\n"))
+}
+
+// normalizeBlockTags заменяет блочные теги, которых нет в Telegram, переносами строк
+func normalizeBlockTags(text string) string {
+	text = breakTagRegexp.ReplaceAllString(text, "\n")
+	text = ruleTagRegexp.ReplaceAllString(text, "\n"+strings.Repeat("-", 20)+"\n")
+	text = listItemRegexp.ReplaceAllString(text, "• ")
+	return blockCloseRegexp.ReplaceAllString(text, "\n")
+}
+
+// markdownToHTML переводит разметку Markdown, которую иногда присылает модель, в HTML
+func markdownToHTML(text string) string {
+	text = markdownRuleRegexp.ReplaceAllString(text, "")
+	text = markdownBoldRegexp.ReplaceAllString(text, "<b>$1</b>")
+	return markdownHeadRegexp.ReplaceAllString(text, "<b>$1</b>")
+}
+
+// filterTags удаляет теги, которые Telegram не поддерживает, оставляя их содержимое,
+// и экранирует одиночные <, > и &, на которых Telegram отклоняет HTML
+func filterTags(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range telegramTagRegexp.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(escapeStray(text[last:loc[0]]))
+		if allowedTelegramTags[strings.ToLower(text[loc[2]:loc[3]])] {
+			sb.WriteString(text[loc[0]:loc[1]])
+		}
+		last = loc[1]
+	}
+	sb.WriteString(escapeStray(text[last:]))
+	return sb.String()
+}
+
+// escapeStray экранирует спецсимволы HTML в тексте без тегов, не трогая готовые сущности
+func escapeStray(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '<':
+			sb.WriteString("&lt;")
+		case c == '>':
+			sb.WriteString("&gt;")
+		case c == '&' && !htmlEntityRegexp.MatchString(text[i:]):
+			sb.WriteString("&amp;")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// stripHTML удаляет все теги и декодирует HTML-сущности
+func stripHTML(text string) string {
+	return html.UnescapeString(telegramTagRegexp.ReplaceAllString(text, ""))
+}
+
+// openTag открытый на момент разбиения тег
+type openTag struct {
+	name string
+	raw  string
+}
+
+// splitMessage разбивает текст на части не длиннее limit символов.
+// Разрез делается по абзацу, строке или пробелу; теги, открытые на границе,
+// закрываются в конце части и открываются заново в следующей.
+func splitMessage(text string, limit int) []string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var reopen string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		// Повторно открытые теги входят в часть, на закрывающие оставляем запас
+		budget := limit - utf8.RuneCountInString(reopen) - splitTagReserve
+		if budget < limit/2 {
+			budget = limit / 2
+		}
+		if len(runes) <= budget {
+			parts = append(parts, strings.TrimSpace(reopen+string(runes)))
+			break
+		}
+
+		cut := splitPoint(runes[:budget])
+		chunk := reopen + string(runes[:cut])
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " \n"))
+
+		open := unclosedTags(chunk)
+		reopen = ""
+		closing := ""
+		for i := len(open) - 1; i >= 0; i-- {
+			closing += "</" + open[i].name + ">"
+		}
+		for _, tag := range open {
+			reopen += tag.raw
+		}
+		parts = append(parts, strings.TrimSpace(chunk)+closing)
+	}
+
+	return parts
+}
+
+// splitPoint выбирает место разреза: конец абзаца, строки или слова, но не внутри тега
+func splitPoint(runes []rune) int {
+	text := string(runes)
+	cut := len(text)
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if idx := strings.LastIndex(text, sep); idx > len(text)/2 {
+			cut = idx
+			break
+		}
+	}
+
+	// Незакрытая "<" в конце означает, что разрез попал внутрь тега
+	if lt := strings.LastIndex(text[:cut], "<"); lt > 0 && lt > strings.LastIndex(text[:cut], ">") {
+		if rest := text[lt+1 : cut]; rest == "" || rest[0] == '/' || isASCIILetter(rest[0]) {
+			cut = lt
+		}
+	}
+	// Не разрываем HTML-сущность вроде &amp;
+	if amp := strings.LastIndex(text[:cut], "&"); amp > 0 && amp > strings.LastIndex(text[:cut], ";") && cut-amp < 10 {
+		cut = amp
+	}
+
+	return utf8.RuneCountInString(text[:cut])
+}
+
+// isASCIILetter проверяет, что байт — латинская буква
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// unclosedTags возвращает теги, которые открыты, но не закрыты в тексте
+func unclosedTags(text string) []openTag {
+	var open []openTag
+	for _, match := range telegramTagRegexp.FindAllStringSubmatch(text, -1) {
+		raw, name := match[0], strings.ToLower(match[1])
+		if strings.HasPrefix(raw, "</") {
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name == name {
+					open = append(open[:i], open[i+1:]...)
+					break
+				}
+			}
+			continue
+		}
+		if !strings.HasSuffix(raw, "/>") {
+			open = append(open, openTag{name: name, raw: raw})
+		}
+	}
+	return open
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var updateGolden = flag.Bool("update", false, "перезаписать golden-файлы PostProcess")
+
+// TestPostProcessGolden прогоняет типичные ответы AI через пайплайн ответа собеседника.
+// Обновить эталоны: go test ./internal/bot -run TestPostProcessGolden -update
+func TestPostProcessGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "postprocess", "*.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("не найдены входные файлы в testdata/postprocess")
+	}
+
+	opts := aiReplyOptions
+	opts.MaxLength = telegramMessageLimit
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := strings.Join(PostProcess(string(raw), opts), "\n=== следующая часть ===\n") + "\n"
+
+			golden := strings.TrimSuffix(input, ".input") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("нет эталона %s (запустите с -update): %v", golden, err)
+			}
+			if got != string(want) {
+				t.Errorf("результат отличается от %s\nожидалось:\n%s\nполучено:\n%s", golden, want, got)
+			}
+		})
+	}
+}
+
+func TestPostProcessStripHTML(t *testing.T) {
+	got := postProcessText("<b>Tom &amp; Jerry</b><br>are <tg-spoiler>friends</tg-spoiler>", plainTextOptions)
+	if got != "Tom & Jerry\nare friends" {
+		t.Errorf("ожидалось %q, получено %q", "Tom & Jerry\nare friends", got)
+	}
+}
+
+func TestPostProcessIsIdempotent(t *testing.T) {
+	text := "**Nice!** Keep going.\n\n<tg-spoiler>🇷🇺 Отлично! Продолжай.</tg-spoiler>"
+
+	once := postProcessText(text, aiReplyOptions)
+	if twice := postProcessText(once, aiReplyOptions); twice != once {
+		t.Errorf("повторная обработка не должна менять текст: %q → %q", once, twice)
+	}
+}
+
+func TestSplitMessageKeepsTagsBalanced(t *testing.T) {
+	sentence := "This sentence is repeated to build a long answer. "
+	text := "<b>" + strings.Repeat(sentence, 10) + "</b>\n\n<tg-spoiler>" + strings.Repeat("Перевод длинного ответа. ", 10) + "</tg-spoiler>"
+
+	parts := splitMessage(text, 200)
+	if len(parts) < 3 {
+		t.Fatalf("ожидалось несколько частей, получено %d", len(parts))
+	}
+
+	for i, part := range parts {
+		if n := utf8.RuneCountInString(part); n > 200 {
+			t.Errorf("часть %d длиннее лимита: %d символов", i, n)
+		}
+		if open := unclosedTags(part); len(open) != 0 {
+			t.Errorf("в части %d остались незакрытые теги: %v", i, open)
+		}
+		if strings.Count(part, "<b>") != strings.Count(part, "</b>") {
+			t.Errorf("в части %d не сбалансирован <b>: %q", i, part)
+		}
+	}
+
+	joined := strings.Join(parts, " ")
+	plain := postProcessText(joined, plainTextOptions)
+	if strings.Count(plain, "repeated") != 10 {
+		t.Errorf("при разбиении потерялся текст: %q", plain)
+	}
+}
+
+func TestSplitMessageShortText(t *testing.T) {
+	parts := splitMessage("<b>Hi!</b>", telegramMessageLimit)
+	if len(parts) != 1 || parts[0] != "<b>Hi!</b>" {
+		t.Errorf("короткий текст не должен разбиваться, получено %q", parts)
+	}
+}
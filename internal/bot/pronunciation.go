@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/pronunciation"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// PronunciationTTL время, в течение которого ожидается голосовое с произношением
+const PronunciationTTL = 30 * time.Minute
+
+// Кнопки проверки произношения
+const (
+	pronounceCardCallbackPrefix = "pronounce_card_" // pronounce_card_<ID карточки>
+	pronounceOfferCallback      = "pronounce_offer" // фраза, предложенная после упражнения
+)
+
+// exerciseBlankPattern пропуск в вопросе упражнения
+var exerciseBlankPattern = regexp.MustCompile(`_{2,}`)
+
+// pronunciationSession фраза, которую пользователь должен произнести
+type pronunciationSession struct {
+	target      string
+	flashcardID int64 // карточка, для которой сохраняется лучшая оценка (0 — не карточка)
+	started     bool  // false — фраза только предложена, голосовое еще не ждем
+	createdAt   time.Time
+}
+
+// offerPronunciation предлагает произнести фразу: проверка начнется по кнопке
+func (h *Handler) offerPronunciation(userID int64, target string) {
+	h.pronounceMutex.Lock()
+	defer h.pronounceMutex.Unlock()
+
+	h.pronunciations[userID] = &pronunciationSession{target: target, createdAt: time.Now()}
+}
+
+// hasActivePronunciation проверяет, ждет ли бот голосовое с произношением
+func (h *Handler) hasActivePronunciation(userID int64) bool {
+	h.pronounceMutex.Lock()
+	defer h.pronounceMutex.Unlock()
+
+	session, ok := h.pronunciations[userID]
+	return ok && session.started && time.Since(session.createdAt) <= PronunciationTTL
+}
+
+// takePronunciation возвращает и завершает начатую проверку произношения
+func (h *Handler) takePronunciation(userID int64) (*pronunciationSession, bool) {
+	h.pronounceMutex.Lock()
+	defer h.pronounceMutex.Unlock()
+
+	session, ok := h.pronunciations[userID]
+	if !ok || !session.started {
+		return nil, false
+	}
+	delete(h.pronunciations, userID)
+
+	if time.Since(session.createdAt) > PronunciationTTL {
+		return nil, false
+	}
+	return session, true
+}
+
+// handlePronunciationCallback начинает проверку произношения слова карточки или фразы упражнения
+func (h *Handler) handlePronunciationCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	session := &pronunciationSession{started: true, createdAt: time.Now()}
+
+	if callback.Data == pronounceOfferCallback {
+		h.pronounceMutex.Lock()
+		offer, ok := h.pronunciations[user.ID]
+		h.pronounceMutex.Unlock()
+		if !ok || time.Since(offer.createdAt) > PronunciationTTL {
+			return h.sendMessage(chatID, "⏰ Эта фраза уже недоступна. Попроси новое упражнение!")
+		}
+		session.target = offer.target
+	} else {
+		flashcardID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, pronounceCardCallbackPrefix), 10, 64)
+		if err != nil {
+			h.logger.Warn("некорректный callback произношения", zap.String("data", callback.Data))
+			return nil
+		}
+		card, err := h.flashcardHandler.flashcardService.GetFlashcard(ctx, flashcardID)
+		if err != nil {
+			h.logger.Error("ошибка получения карточки для произношения", zap.Error(err), zap.Int64("flashcard_id", flashcardID))
+			return h.sendErrorMessage(chatID, "Не удалось найти слово")
+		}
+		session.target = card.Word
+		session.flashcardID = flashcardID
+	}
+
+	h.pronounceMutex.Lock()
+	h.pronunciations[user.ID] = session
+	h.pronounceMutex.Unlock()
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🎤 <b>Скажи вслух:</b>\n\n<i>%s</i>\n\nОтправь голосовое — я сравню его с образцом.",
+		html.EscapeString(session.target)))
+	msg.ParseMode = "HTML"
+	if h.ttsService != nil {
+		if button, err := h.createTTSButton(user.ID, session.target); err != nil {
+			h.logger.Error("ошибка создания кнопки озвучки", zap.Error(err))
+		} else {
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button))
+		}
+	}
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handlePronunciationAnswer оценивает распознанное голосовое с произношением
+func (h *Handler) handlePronunciationAnswer(ctx context.Context, chatID int64, user *models.User, transcript string) error {
+	session, ok := h.takePronunciation(user.ID)
+	if !ok {
+		return h.sendMessage(chatID, "⏰ Время попытки истекло. Нажми «🎤 Произнести» еще раз.")
+	}
+
+	result := pronunciation.Compare(session.target, transcript)
+	h.updateStudyActivity(user)
+
+	if session.flashcardID != 0 {
+		if err := h.flashcardHandler.flashcardService.SavePronunciationScore(ctx, user.ID, session.flashcardID, result.Score); err != nil {
+			h.logger.Error("ошибка сохранения оценки произношения", zap.Error(err), zap.Int64("user_id", user.ID))
+		}
+	}
+
+	var verdict string
+	switch {
+	case result.Score == 100:
+		verdict = "🎉 <b>Идеально!</b>"
+	case result.Score >= 80:
+		verdict = "👏 <b>Очень близко!</b>"
+	case result.Score >= 50:
+		verdict = "👍 <b>Неплохо!</b> Отмеченные слова стоит повторить."
+	default:
+		verdict = "💪 <b>Попробуй еще раз!</b> Послушай образец и повтори медленнее."
+	}
+
+	text := fmt.Sprintf("%s\n\n🎯 Совпадение: <b>%d%%</b>\n📝 %s",
+		verdict, result.Score, formatPronunciationWords(result.Words))
+
+	// Повтор запускает ту же проверку заново
+	retry := pronounceOfferCallback
+	if session.flashcardID != 0 {
+		retry = pronounceCardCallbackPrefix + strconv.FormatInt(session.flashcardID, 10)
+	} else {
+		h.offerPronunciation(user.ID, session.target)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔁 Попробовать еще раз", retry),
+	))
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// formatPronunciationWords выделяет нераспознанные слова образца
+func formatPronunciationWords(words []pronunciation.Word) string {
+	parts := make([]string, len(words))
+	for i, word := range words {
+		if word.Matched {
+			parts[i] = html.EscapeString(word.Text)
+		} else {
+			parts[i] = "<u><b>" + html.EscapeString(word.Text) + "</b></u>"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// exercisePronunciationTarget собирает фразу упражнения с правильным ответом вместо пропуска.
+// Возвращает пустую строку, если в вопросе нет ровно одного пропуска.
+func exercisePronunciationTarget(quiz exerciseQuiz) string {
+	if quiz.Correct < 0 || quiz.Correct >= len(quiz.Options) {
+		return ""
+	}
+	if len(exerciseBlankPattern.FindAllStringIndex(quiz.Question, -1)) != 1 {
+		return ""
+	}
+	return exerciseBlankPattern.ReplaceAllLiteralString(quiz.Question, quiz.Options[quiz.Correct])
+}
@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/pronunciation"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+)
+
+// pronunciationRepo отдает карточку по ID и запоминает оценки произношения
+type pronunciationRepo struct {
+	store.FlashcardRepository
+	scores map[int64]int
+}
+
+func (r *pronunciationRepo) GetFlashcardByID(ctx context.Context, id int64) (*models.Flashcard, error) {
+	return &models.Flashcard{ID: id, Word: "weather", Translation: "погода"}, nil
+}
+
+func (r *pronunciationRepo) SavePronunciationScore(ctx context.Context, userID, flashcardID int64, score int) error {
+	r.scores[flashcardID] = max(r.scores[flashcardID], score)
+	return nil
+}
+
+func TestExercisePronunciationTarget(t *testing.T) {
+	if got := exercisePronunciationTarget(fallbackExerciseQuiz); got != "She goes to work every day." {
+		t.Errorf("ожидалась фраза с правильным ответом, получено %q", got)
+	}
+
+	noBlank := exerciseQuiz{Question: "Translate: кот", Options: []string{"cat", "dog"}, Correct: 0}
+	twoBlanks := exerciseQuiz{Question: "I __ and you __", Options: []string{"go", "went"}, Correct: 0}
+	for _, quiz := range []exerciseQuiz{noBlank, twoBlanks} {
+		if got := exercisePronunciationTarget(quiz); got != "" {
+			t.Errorf("для %q фраза не должна предлагаться, получено %q", quiz.Question, got)
+		}
+	}
+}
+
+func TestFormatPronunciationWords(t *testing.T) {
+	got := formatPronunciationWords(pronunciation.Compare("Fish & chips!", "fish and cheese").Words)
+	if got != "Fish &amp; <u><b>chips!</b></u>" {
+		t.Errorf("неожиданное выделение слов: %q", got)
+	}
+}
+
+func TestFlashcardPronunciationSavesBestScore(t *testing.T) {
+	th := newTestHarness(t)
+	repo := &pronunciationRepo{scores: make(map[int64]int)}
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(repo, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.sendText(t, 100, "/start")
+	user := th.user(t, 100)
+
+	th.pressButton(t, 100, pronounceCardCallbackPrefix+"7")
+	if !th.handler.hasActivePronunciation(user.ID) {
+		t.Fatal("после кнопки бот должен ждать голосовое")
+	}
+	if prompt := th.sender.texts(); !strings.Contains(prompt[len(prompt)-1], "weather") {
+		t.Fatalf("ожидалось приглашение произнести слово, получено %q", prompt)
+	}
+
+	th.sender.reset()
+	if err := th.handler.handlePronunciationAnswer(context.Background(), 100, user, "whether"); err != nil {
+		t.Fatalf("ошибка оценки произношения: %v", err)
+	}
+	reply, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(reply.Text, "71%") || !strings.Contains(reply.Text, "<u><b>weather</b></u>") {
+		t.Fatalf("ожидалась оценка с выделенным словом, получено %#v", th.sender.last())
+	}
+	if repo.scores[7] != 71 {
+		t.Errorf("ожидалась сохраненная оценка 71, получено %d", repo.scores[7])
+	}
+	if th.handler.hasActivePronunciation(user.ID) {
+		t.Error("после оценки проверка должна завершиться")
+	}
+
+	retry := *reply.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+	th.pressButton(t, 100, retry)
+	if err := th.handler.handlePronunciationAnswer(context.Background(), 100, user, "Weather!"); err != nil {
+		t.Fatalf("ошибка повторной оценки: %v", err)
+	}
+	if repo.scores[7] != 100 {
+		t.Errorf("ожидалась лучшая оценка 100, получено %d", repo.scores[7])
+	}
+}
+
+func TestExerciseAnswerOffersPronunciation(t *testing.T) {
+	th := newTestHarness(t, pastSimpleQuizJSON)
+	th.sendText(t, 100, "дай мне упражнение")
+	th.answerExercise(t, 100, th.lastQuizMessageID(t), 1)
+
+	feedback, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || feedback.ReplyMarkup == nil {
+		t.Fatalf("ожидалась кнопка произношения под разбором, получено %#v", th.sender.last())
+	}
+	user := th.user(t, 100)
+	if th.handler.hasActivePronunciation(user.ID) {
+		t.Fatal("до нажатия кнопки голосовое не должно считаться попыткой произношения")
+	}
+
+	th.pressButton(t, 100, pronounceOfferCallback)
+	if !th.handler.hasActivePronunciation(user.ID) {
+		t.Fatal("после кнопки бот должен ждать голосовое")
+	}
+	if prompt := th.sender.texts(); !strings.Contains(prompt[len(prompt)-1], "Yesterday I wrote a letter.") {
+		t.Errorf("ожидалась фраза упражнения с ответом, получено %q", prompt[len(prompt)-1])
+	}
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Параметры быстрых ответов
+const (
+	quickReplyCallbackPrefix = "quick_reply_"
+	quickRepliesMarker       = "SUGGESTIONS:" // строка ответа AI с вариантами быстрых ответов
+	maxQuickReplies          = 3
+	maxQuickReplyRunes       = 40 // длиннее вариант не помещается на кнопку
+	quickReplyTTL            = dialogStaleAfter
+)
+
+// quickReplySet варианты ответа на последний вопрос бота
+type quickReplySet struct {
+	replies   []string
+	createdAt time.Time
+}
+
+// quickReplyStore хранит варианты последнего вопроса для каждого пользователя:
+// кнопки под старыми сообщениями перестают работать, когда приходит новый вопрос
+type quickReplyStore struct {
+	mu      sync.Mutex
+	entries map[int64]quickReplySet
+	now     func() time.Time
+}
+
+// newQuickReplyStore создает хранилище быстрых ответов
+func newQuickReplyStore() *quickReplyStore {
+	return &quickReplyStore{
+		entries: make(map[int64]quickReplySet),
+		now:     time.Now,
+	}
+}
+
+// save запоминает варианты ответа пользователя и удаляет устаревшие записи
+func (s *quickReplyStore) save(userID int64, replies []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, entry := range s.entries {
+		if now.Sub(entry.createdAt) > quickReplyTTL {
+			delete(s.entries, id)
+		}
+	}
+	s.entries[userID] = quickReplySet{replies: replies, createdAt: now}
+}
+
+// take возвращает выбранный вариант и забывает остальные, чтобы ответ не отправили дважды
+func (s *quickReplyStore) take(userID int64, index int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[userID]
+	if !ok || s.now().Sub(entry.createdAt) > quickReplyTTL || index < 0 || index >= len(entry.replies) {
+		return "", false
+	}
+	delete(s.entries, userID)
+	return entry.replies[index], true
+}
+
+// forget удаляет варианты пользователя: на новый ответ без вопроса старые кнопки не действуют
+func (s *quickReplyStore) forget(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, userID)
+}
+
+// SetQuickReplies включает кнопки быстрых ответов после вопроса бота для указанных уровней
+func (h *Handler) SetQuickReplies(enabled bool, levels []string) {
+	h.quickRepliesEnabled = enabled
+	h.quickReplyLevels = make(map[string]bool, len(levels))
+	for _, level := range levels {
+		h.quickReplyLevels[level] = true
+	}
+}
+
+// quickRepliesFor сообщает, предлагать ли пользователю быстрые ответы.
+// В группах не предлагаем: кнопки под сообщением видят все участники.
+func (h *Handler) quickRepliesFor(message *tgbotapi.Message, user *models.User) bool {
+	return h.quickRepliesEnabled && h.quickReplyLevels[user.Level] && !isGroupChat(message)
+}
+
+// withQuickReplies дополняет системный промпт просьбой предложить варианты ответа
+func (h *Handler) withQuickReplies(systemPrompt string, message *tgbotapi.Message, user *models.User) string {
+	if !h.quickRepliesFor(message, user) {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\n" + h.prompts.GetQuickRepliesInstruction()
+}
+
+// extractQuickReplies отделяет от ответа AI строку с вариантами быстрых ответов.
+// Если AI не предложил варианты, ответ возвращается без изменений.
+func extractQuickReplies(content string) (string, []string) {
+	lines := strings.Split(content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if len(line) < len(quickRepliesMarker) || !strings.EqualFold(line[:len(quickRepliesMarker)], quickRepliesMarker) {
+			return content, nil
+		}
+
+		var replies []string
+		for _, option := range strings.Split(line[len(quickRepliesMarker):], "|") {
+			option = strings.Trim(strings.TrimSpace(option), `"*`)
+			if option == "" || len([]rune(option)) > maxQuickReplyRunes {
+				continue
+			}
+			replies = append(replies, option)
+			if len(replies) == maxQuickReplies {
+				break
+			}
+		}
+		return strings.TrimRight(strings.Join(lines[:i], "\n"), " \n"), replies
+	}
+	return content, nil
+}
+
+// quickReplyRows запоминает варианты и возвращает кнопки для них, по одной в ряд
+func (h *Handler) quickReplyRows(userID int64, replies []string) [][]tgbotapi.InlineKeyboardButton {
+	if len(replies) == 0 {
+		h.quickReplies.forget(userID)
+		return nil
+	}
+	h.quickReplies.save(userID, replies)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(replies))
+	for i, reply := range replies {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💬 "+reply, quickReplyCallbackPrefix+strconv.Itoa(i)),
+		))
+	}
+	return rows
+}
+
+// handleQuickReplyCallback отправляет выбранный вариант как сообщение пользователя
+func (h *Handler) handleQuickReplyCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	index, err := strconv.Atoi(strings.TrimPrefix(callback.Data, quickReplyCallbackPrefix))
+	if err != nil {
+		h.logger.Warn("некорректный callback быстрого ответа", zap.String("data", callback.Data))
+		return nil
+	}
+
+	// Во время теста или диктанта вариант из разговора приняли бы за ответ на задание
+	if user.CurrentState == models.StateInLevelTest || h.hasActiveDictation(user.ID) {
+		return nil
+	}
+
+	reply, ok := h.quickReplies.take(user.ID, index)
+	if !ok {
+		return h.sendMessage(callback.Message.Chat.ID, "🤷 Этот вариант уже неактуален. Напиши ответ сам — так даже полезнее!")
+	}
+
+	// Показываем выбранный ответ в чате, чтобы разговор читался последовательно
+	if err := h.sendMessage(callback.Message.Chat.ID, "🗣 <i>"+html.EscapeString(reply)+"</i>"); err != nil {
+		h.logger.Warn("не удалось показать выбранный быстрый ответ", zap.Error(err))
+	}
+
+	message := &tgbotapi.Message{
+		MessageID: callback.Message.MessageID,
+		From:      callback.From,
+		Chat:      callback.Message.Chat,
+		Date:      int(time.Now().Unix()),
+		Text:      reply,
+	}
+	return h.handleMessage(ctx, message, user)
+}
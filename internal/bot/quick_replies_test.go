@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestExtractQuickReplies(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantText    string
+		wantReplies []string
+	}{
+		{
+			"варианты в конце",
+			"<b>Do you like tea?</b>\n\nSUGGESTIONS: Yes, I do | No, I don't | Sometimes",
+			"<b>Do you like tea?</b>",
+			[]string{"Yes, I do", "No, I don't", "Sometimes"},
+		},
+		{
+			"без вариантов",
+			"<b>Great job!</b>\n\n<tg-spoiler>🇷🇺 Отлично!</tg-spoiler>",
+			"<b>Great job!</b>\n\n<tg-spoiler>🇷🇺 Отлично!</tg-spoiler>",
+			nil,
+		},
+		{
+			"регистр, кавычки и лишние варианты",
+			"Question?\nsuggestions: \"Yes\" | **No** | | Maybe | I don't know\n",
+			"Question?",
+			[]string{"Yes", "No", "Maybe"},
+		},
+		{
+			"слишком длинный вариант пропускается",
+			"Why?\nSUGGESTIONS: " + strings.Repeat("very ", 10) + "long | Because",
+			"Why?",
+			[]string{"Because"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, replies := extractQuickReplies(tt.content)
+			if text != tt.wantText {
+				t.Errorf("ожидался текст %q, получено %q", tt.wantText, text)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("ожидались варианты %q, получено %q", tt.wantReplies, replies)
+			}
+		})
+	}
+}
+
+func TestQuickReplyTapIsSentAsUserMessage(t *testing.T) {
+	th := newTestHarness(t,
+		"<b>Nice! Do you drink it every day?</b>\nSUGGESTIONS: Yes, every day | Only sometimes",
+		"<b>Every day is a great habit!</b>",
+	)
+	th.handler.SetQuickReplies(true, []string{models.LevelBeginner})
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	if prompt := th.ai.calls[0][0].Content; !strings.Contains(prompt, quickRepliesMarker) {
+		t.Error("для новичка в промпт ожидалась просьба предложить варианты ответа")
+	}
+	reply, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok {
+		t.Fatalf("ожидалось сообщение, получено %#v", th.sender.last())
+	}
+	if strings.Contains(reply.Text, quickRepliesMarker) {
+		t.Errorf("строка с вариантами не должна попадать к пользователю: %q", reply.Text)
+	}
+	keyboard := reply.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	var quick []string
+	for _, row := range keyboard.InlineKeyboard {
+		if data := row[0].CallbackData; data != nil && strings.HasPrefix(*data, quickReplyCallbackPrefix) {
+			quick = append(quick, row[0].Text)
+		}
+	}
+	if !reflect.DeepEqual(quick, []string{"💬 Yes, every day", "💬 Only sometimes"}) {
+		t.Errorf("ожидались кнопки быстрых ответов, получено %q", quick)
+	}
+
+	th.pressButton(t, 100, quickReplyCallbackPrefix+"1")
+
+	if len(th.ai.calls) != 2 {
+		t.Fatalf("ожидалось 2 запроса к AI, получено %d", len(th.ai.calls))
+	}
+	request := th.ai.calls[1]
+	if last := request[len(request)-1]; last.Role != "user" || last.Content != "Only sometimes" {
+		t.Errorf("выбранный вариант ожидался сообщением ученика, получено %+v", last)
+	}
+
+	// Кнопки одноразовые: ответ уже отправлен
+	th.sender.reset()
+	th.pressButton(t, 100, quickReplyCallbackPrefix+"0")
+	if len(th.ai.calls) != 2 {
+		t.Error("повторное нажатие не должно отправлять ответ еще раз")
+	}
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "неактуален") {
+		t.Errorf("ожидалось сообщение о неактуальном варианте, получено %q", texts)
+	}
+}
+
+func TestQuickRepliesOnlyForConfiguredLevels(t *testing.T) {
+	th := newTestHarness(t, "<b>Great!</b>")
+	th.handler.SetQuickReplies(true, []string{models.LevelBeginner})
+
+	u := &models.User{Level: models.LevelAdvanced}
+	msg := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1, Type: "private"}}
+	if th.handler.quickRepliesFor(msg, u) {
+		t.Error("для продвинутого уровня быстрые ответы не предлагаются")
+	}
+
+	u.Level = models.LevelBeginner
+	if !th.handler.quickRepliesFor(msg, u) {
+		t.Error("для новичка ожидались быстрые ответы")
+	}
+
+	msg.Chat.Type = "group"
+	if th.handler.quickRepliesFor(msg, u) {
+		t.Error("в группе быстрые ответы не предлагаются")
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/skip2/go-qrcode"
+	"go.uber.org/zap"
+)
+
+// referralQRSize размер картинки QR-кода в пикселях
+const referralQRSize = 512
+
+// referralQRCacheSize сколько QR-кодов хранится в кэше; давно не запрошенные вытесняются
+const referralQRCacheSize = 10000
+
+// referralQREntry закэшированный QR-код реферальной ссылки
+type referralQREntry struct {
+	userID int64
+	link   string // ссылка, для которой сгенерирован код
+	fileID string // file_id фото в Telegram после первой отправки
+}
+
+// referralQRCache LRU-кэш file_id отправленных QR-кодов
+type referralQRCache struct {
+	mu      sync.Mutex
+	limit   int
+	order   *list.List // от недавно запрошенных к давним, элементы — *referralQREntry
+	entries map[int64]*list.Element
+}
+
+func newReferralQRCache(limit int) *referralQRCache {
+	return &referralQRCache{
+		limit:   limit,
+		order:   list.New(),
+		entries: make(map[int64]*list.Element),
+	}
+}
+
+func (c *referralQRCache) get(userID int64) (referralQREntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[userID]
+	if !ok {
+		return referralQREntry{}, false
+	}
+	c.order.MoveToFront(elem)
+	return *elem.Value.(*referralQREntry), true
+}
+
+func (c *referralQRCache) put(entry referralQREntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.userID]; ok {
+		*elem.Value.(*referralQREntry) = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[entry.userID] = c.order.PushFront(&entry)
+	for c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*referralQREntry).userID)
+	}
+}
+
+// referralLink формирует реферальную ссылку на бота
+func (h *Handler) referralLink(referralCode string) string {
+	return fmt.Sprintf("https://t.me/%s?start=ref_%s", h.self.UserName, referralCode)
+}
+
+// handleReferralQRCallback отправляет QR-код с реферальной ссылкой пользователя
+func (h *Handler) handleReferralQRCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	referralCode, err := h.referralService.GetOrGenerateReferralCode(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения реферального кода", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Не удалось получить реферальную ссылку")
+	}
+	link := h.referralLink(referralCode)
+	caption := fmt.Sprintf("📷 Покажите этот QR-код другу, чтобы он открыл бота по вашей ссылке:\n%s", link)
+
+	// Повторно отправляем уже загруженное фото по file_id
+	if entry, ok := h.referralQR.get(user.ID); ok && entry.link == link {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(entry.fileID))
+		photo.Caption = caption
+		if _, err := h.sender.Send(photo); err == nil {
+			return nil
+		}
+		h.logger.Warn("не удалось отправить QR-код из кэша, генерируем заново", zap.Int64("user_id", user.ID))
+	}
+
+	image, err := qrcode.Encode(link, qrcode.Medium, referralQRSize)
+	if err != nil {
+		h.logger.Error("ошибка генерации QR-кода", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Не удалось создать QR-код")
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "referral_qr.png", Bytes: image})
+	photo.Caption = caption
+	sent, err := h.sender.Send(photo)
+	if err != nil {
+		return err
+	}
+
+	if len(sent.Photo) > 0 {
+		h.referralQR.put(referralQREntry{
+			userID: user.ID,
+			link:   link,
+			fileID: sent.Photo[len(sent.Photo)-1].FileID,
+		})
+	}
+
+	return nil
+}
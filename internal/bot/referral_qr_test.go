@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/png"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/makiuchi-d/gozxing"
+	qrreader "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// decodeQR распознает QR-код на картинке так же, как камера телефона
+func decodeQR(t *testing.T, data []byte) string {
+	t.Helper()
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("картинка QR-кода не читается: %v", err)
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("ошибка подготовки картинки: %v", err)
+	}
+	result, err := qrreader.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		t.Fatalf("QR-код не распознан: %v", err)
+	}
+	return result.GetText()
+}
+
+func TestReferralQRDecodesToReferralLink(t *testing.T) {
+	th := newTestHarness(t)
+	th.sendText(t, 100, "/start")
+	user := th.user(t, 100)
+	code := "ABC123"
+	user.ReferralCode = &code
+	if err := th.store.users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	th.sender.reset()
+	th.pressButton(t, 100, "referral_qr")
+	photo, ok := th.sender.last().(tgbotapi.PhotoConfig)
+	if !ok {
+		t.Fatalf("ожидалось фото с QR-кодом, получено %#v", th.sender.last())
+	}
+	file, ok := photo.File.(tgbotapi.FileBytes)
+	if !ok {
+		t.Fatalf("ожидалась сгенерированная картинка, получено %#v", photo.File)
+	}
+
+	if got, want := decodeQR(t, file.Bytes), th.handler.referralLink(code); got != want {
+		t.Errorf("QR-код ведет на %q, ожидалось %q", got, want)
+	}
+}
+
+func TestReferralQRCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newReferralQRCache(2)
+	cache.put(referralQREntry{userID: 1, link: "a", fileID: "f1"})
+	cache.put(referralQREntry{userID: 2, link: "b", fileID: "f2"})
+	cache.get(1) // пользователь 1 запрашивал код недавно
+	cache.put(referralQREntry{userID: 3, link: "c", fileID: "f3"})
+
+	if _, ok := cache.get(2); ok {
+		t.Error("давно не запрошенный код должен быть вытеснен")
+	}
+	for _, id := range []int64{1, 3} {
+		if _, ok := cache.get(id); !ok {
+			t.Errorf("код пользователя %d должен остаться в кэше", id)
+		}
+	}
+
+	cache.put(referralQREntry{userID: 1, link: "a2", fileID: "f4"})
+	if entry, _ := cache.get(1); entry.link != "a2" || len(cache.entries) != 2 {
+		t.Errorf("повторная запись должна обновлять код, а не добавлять новый: %+v, в кэше %d", entry, len(cache.entries))
+	}
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleRemindersCommand обрабатывает команду /reminders on|off
+func (h *Handler) handleRemindersCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		return h.setReminders(ctx, message.Chat.ID, user, true)
+	case "off":
+		return h.setReminders(ctx, message.Chat.ID, user, false)
+	}
+
+	status := "🔕 Сейчас напоминания отключены."
+	if user.RemindersEnabled {
+		status = "🔔 Сейчас напоминания включены."
+	}
+	return h.sendMessage(message.Chat.ID, "⏰ <b>Ежедневные напоминания</b>\n\n"+
+		"Если сегодня занятий еще не было, я раз в день мягко напомню об этом. "+
+		"Эта же настройка включает слово дня.\n"+status+"\n\n"+
+		"<code>/reminders on</code> — включить\n<code>/reminders off</code> — отключить")
+}
+
+// handleRemindersOffCallback отключает напоминания кнопкой под напоминанием
+func (h *Handler) handleRemindersOffCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	return h.setReminders(ctx, callback.Message.Chat.ID, user, false)
+}
+
+// setReminders включает или отключает ежедневные напоминания
+func (h *Handler) setReminders(ctx context.Context, chatID int64, user *models.User, enabled bool) error {
+	if err := h.userService.SetRemindersEnabled(ctx, user.ID, enabled); err != nil {
+		h.logger.Error("ошибка сохранения ежедневных напоминаний", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить настройку")
+	}
+	user.RemindersEnabled = enabled
+
+	if enabled {
+		return h.sendMessage(chatID, "🔔 Ежедневные напоминания включены.")
+	}
+	return h.sendMessage(chatID, "🔕 Ежедневные напоминания отключены. Включить снова: <code>/reminders on</code>")
+}
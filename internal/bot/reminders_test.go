@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestRemindersCommandTogglesFlag(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/reminders")
+	if u := th.user(t, 100); !u.RemindersEnabled {
+		t.Fatal("у новых пользователей напоминания должны быть включены")
+	}
+
+	th.sendText(t, 100, "/reminders off")
+	if u := th.user(t, 100); u.RemindersEnabled {
+		t.Fatal("после /reminders off напоминания должны быть отключены")
+	}
+
+	th.sendText(t, 100, "/reminders on")
+	if u := th.user(t, 100); !u.RemindersEnabled {
+		t.Fatal("после /reminders on напоминания должны быть включены")
+	}
+}
+
+func TestRemindersOffButton(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/reminders")
+	th.sender.reset()
+	th.pressButton(t, 100, models.DailyReminderOffCallback)
+
+	if u := th.user(t, 100); u.RemindersEnabled {
+		t.Error("кнопка должна отключать напоминания")
+	}
+	texts := th.sender.texts()
+	if len(texts) == 0 || !strings.Contains(texts[len(texts)-1], "/reminders on") {
+		t.Errorf("ожидалась подсказка, как включить напоминания, получено %q", texts)
+	}
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"lingua-ai/internal/ai"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxQuoteRunes ограничивает длину цитаты, которая передается AI
+const maxQuoteRunes = 1000
+
+// replyQuote сообщение, на которое ответил пользователь
+type replyQuote struct {
+	Text    string
+	FromBot bool // цитируется ответ бота, а не сообщение самого ученика
+}
+
+// quotedReply возвращает текст сообщения, на которое ответил пользователь.
+// Telegram присылает цитируемое сообщение целиком, поэтому текст доступен,
+// даже если его уже нет в сохраненной истории. Перевод из ответов бота отбрасывается.
+func quotedReply(message *tgbotapi.Message, botID int64) (replyQuote, bool) {
+	reply := message.ReplyToMessage
+	if reply == nil {
+		return replyQuote{}, false
+	}
+
+	text := reply.Text
+	if text == "" {
+		text = reply.Caption
+	}
+	fromBot := reply.From != nil && reply.From.ID == botID
+	if fromBot {
+		text, _ = splitAIResponse(text)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return replyQuote{}, false
+	}
+	if runes := []rune(text); len(runes) > maxQuoteRunes {
+		text = string(runes[:maxQuoteRunes]) + "…"
+	}
+
+	return replyQuote{Text: text, FromBot: fromBot}, true
+}
+
+// inDialog проверяет, есть ли цитируемое сообщение среди сообщений, которые видит AI.
+// Последнее сообщение — текущий вопрос ученика, его не учитываем.
+func (q replyQuote) inDialog(recent []DialogMessage) bool {
+	if len(recent) == 0 {
+		return false
+	}
+	text := strings.TrimSuffix(q.Text, "…")
+	for _, msg := range recent[:len(recent)-1] {
+		if strings.Contains(stripHTML(msg.Content), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// focusPrompt формирует указание AI сосредоточиться на цитате
+func (q replyQuote) focusPrompt(inDialog bool) string {
+	author := "свое сообщение"
+	if q.FromBot {
+		author = "твое сообщение"
+	}
+
+	prompt := fmt.Sprintf("Ученик отвечает на %s:\n«%s»\n"+
+		"Сделай этот текст главным предметом ответа: если ученик просит объяснить, перевести, "+
+		"разобрать или использовать слово в предложении — речь именно о нем.", author, q.Text)
+	if !inDialog {
+		prompt += "\nЭтого сообщения нет в доступной тебе истории разговора, опирайся на цитату."
+	}
+	return prompt
+}
+
+// withReplyFocus вставляет указание о цитате перед последним сообщением ученика
+func withReplyFocus(messages []ai.Message, focus string) []ai.Message {
+	if len(messages) == 0 {
+		return messages
+	}
+
+	last := len(messages) - 1
+	result := make([]ai.Message, 0, len(messages)+1)
+	result = append(result, messages[:last]...)
+	result = append(result, ai.Message{Role: "system", Content: focus})
+	return append(result, messages[last])
+}
+
+// applyReplyFocus добавляет к запросу AI цитату, если пользователь ответил на сообщение
+func (h *Handler) applyReplyFocus(message *tgbotapi.Message, messages []ai.Message, recent []DialogMessage) []ai.Message {
+	quote, ok := quotedReply(message, h.self.ID)
+	if !ok {
+		return messages
+	}
+	return withReplyFocus(messages, quote.focusPrompt(quote.inDialog(recent)))
+}
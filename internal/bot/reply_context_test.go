@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"lingua-ai/internal/ai"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const testBotID = 777
+
+func TestQuotedReplyFromBotDropsTranslation(t *testing.T) {
+	message := &tgbotapi.Message{
+		Text: "Объясни это",
+		ReplyToMessage: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: testBotID},
+			Text: "I've been looking forward to it.\n\n🇷🇺 Я с нетерпением этого ждал.",
+		},
+	}
+
+	quote, ok := quotedReply(message, testBotID)
+	if !ok {
+		t.Fatal("ожидалась цитата")
+	}
+	if !quote.FromBot {
+		t.Error("цитата должна быть отмечена как ответ бота")
+	}
+	if quote.Text != "I've been looking forward to it." {
+		t.Errorf("ожидалась английская часть без перевода, получено %q", quote.Text)
+	}
+}
+
+func TestQuotedReplyWithoutText(t *testing.T) {
+	if _, ok := quotedReply(&tgbotapi.Message{Text: "hi"}, testBotID); ok {
+		t.Error("без ответа на сообщение цитаты быть не должно")
+	}
+
+	sticker := &tgbotapi.Message{Text: "what?", ReplyToMessage: &tgbotapi.Message{From: &tgbotapi.User{ID: 1}}}
+	if _, ok := quotedReply(sticker, testBotID); ok {
+		t.Error("ответ на сообщение без текста не должен давать цитату")
+	}
+
+	photo := &tgbotapi.Message{Text: "translate", ReplyToMessage: &tgbotapi.Message{Caption: "A sunny day"}}
+	quote, ok := quotedReply(photo, testBotID)
+	if !ok || quote.Text != "A sunny day" || quote.FromBot {
+		t.Errorf("ожидалась подпись пользователя, получено %+v, %v", quote, ok)
+	}
+}
+
+func TestQuotedReplyIsTruncated(t *testing.T) {
+	message := &tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{Text: strings.Repeat("word ", 500)}}
+
+	quote, ok := quotedReply(message, testBotID)
+	if !ok {
+		t.Fatal("ожидалась цитата")
+	}
+	if n := len([]rune(quote.Text)); n > maxQuoteRunes+1 {
+		t.Errorf("цитата должна быть обрезана до %d символов, получено %d", maxQuoteRunes, n)
+	}
+}
+
+func TestReplyQuoteInDialog(t *testing.T) {
+	quote := replyQuote{Text: "How was your weekend?", FromBot: true}
+	recent := []DialogMessage{
+		{Role: "assistant", Content: "<b>How was your weekend?</b>\n\n<tg-spoiler>🇷🇺 Как прошли выходные?</tg-spoiler>"},
+		{Role: "user", Content: "Explain this"},
+	}
+
+	if !quote.inDialog(recent) {
+		t.Error("цитата из истории должна находиться несмотря на HTML-разметку")
+	}
+	if quote.inDialog(recent[1:]) {
+		t.Error("текущее сообщение ученика не должно считаться историей")
+	}
+	if !strings.Contains(quote.focusPrompt(false), "нет в доступной тебе истории") {
+		t.Error("для сообщения вне истории AI нужно предупредить, что опираться можно только на цитату")
+	}
+}
+
+func TestWithReplyFocusInsertsBeforeCurrentMessage(t *testing.T) {
+	messages := []ai.Message{
+		{Role: "system", Content: "prompt"},
+		{Role: "assistant", Content: "Hello!"},
+		{Role: "user", Content: "Explain this"},
+	}
+
+	got := withReplyFocus(messages, "focus")
+	if len(got) != 4 {
+		t.Fatalf("ожидалось 4 сообщения, получено %d", len(got))
+	}
+	if got[2].Role != "system" || got[2].Content != "focus" {
+		t.Errorf("указание о цитате должно идти перед вопросом ученика, получено %+v", got[2])
+	}
+	if got[3].Content != "Explain this" {
+		t.Errorf("последним должно остаться сообщение ученика, получено %q", got[3].Content)
+	}
+	if len(messages) != 3 {
+		t.Error("исходный срез не должен меняться")
+	}
+}
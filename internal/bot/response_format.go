@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	spoilerOpenTag  = "<tg-spoiler>"
+	spoilerCloseTag = "</tg-spoiler>"
+	russianFlag     = "🇷🇺"
+)
+
+var htmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// splitAIResponse разделяет ответ AI на английскую часть и перевод.
+// Работает и когда модель проигнорировала формат с <tg-spoiler>:
+// перевод ищется по маркеру 🇷🇺, а затем по первой строке с преобладанием кириллицы.
+func splitAIResponse(text string) (english, translation string) {
+	text = strings.TrimSpace(text)
+
+	// Ожидаемый формат: английский текст + <tg-spoiler>перевод</tg-spoiler>
+	if idx := strings.Index(text, spoilerOpenTag); idx != -1 {
+		english = text[:idx]
+		translation = text[idx+len(spoilerOpenTag):]
+		if end := strings.Index(translation, spoilerCloseTag); end != -1 {
+			translation = translation[:end]
+		}
+		return strings.TrimSpace(english), strings.TrimSpace(translation)
+	}
+
+	// Спойлера нет, но есть маркер перевода
+	if idx := strings.Index(text, russianFlag); idx != -1 {
+		return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx:])
+	}
+
+	// Ищем первую строку, где кириллица преобладает над латиницей
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if isMostlyCyrillic(line) {
+			english = strings.TrimSpace(strings.Join(lines[:i], "\n"))
+			translation = strings.TrimSpace(strings.Join(lines[i:], "\n"))
+			if english == "" {
+				// Ответ целиком на русском — отделить английскую часть нельзя
+				return text, ""
+			}
+			return english, translation
+		}
+	}
+
+	return text, ""
+}
+
+// hasExpectedFormat проверяет, что ответ содержит перевод в спойлере
+func hasExpectedFormat(text string) bool {
+	return strings.Contains(text, spoilerOpenTag) && strings.Contains(text, spoilerCloseTag)
+}
+
+// ensureResponseFormat приводит ответ AI к формату "английский текст + перевод в спойлере".
+// Если перевод найти не удалось, текст возвращается без изменений.
+func ensureResponseFormat(text string) string {
+	if hasExpectedFormat(text) {
+		return text
+	}
+
+	english, translation := splitAIResponse(text)
+	if translation == "" {
+		return text
+	}
+
+	// Убираем незакрытый спойлер и оборачиваем перевод заново
+	translation = strings.TrimSpace(strings.ReplaceAll(translation, spoilerCloseTag, ""))
+	if !strings.HasPrefix(translation, russianFlag) {
+		translation = russianFlag + " " + translation
+	}
+
+	return english + "\n\n" + spoilerOpenTag + translation + spoilerCloseTag
+}
+
+// isMostlyCyrillic проверяет, что в строке больше кириллических букв, чем латинских
+func isMostlyCyrillic(line string) bool {
+	plain := html.UnescapeString(htmlTagRegexp.ReplaceAllString(line, ""))
+
+	cyrillic, latin := 0, 0
+	for _, r := range plain {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	return cyrillic > latin
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitAIResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		wantEnglish     string
+		wantTranslation string
+	}{
+		{
+			name:            "корректный формат",
+			input:           "<b>I like coffee.</b>\n\n<tg-spoiler>🇷🇺 Я люблю кофе.</tg-spoiler>",
+			wantEnglish:     "<b>I like coffee.</b>",
+			wantTranslation: "🇷🇺 Я люблю кофе.",
+		},
+		{
+			name:            "незакрытый спойлер",
+			input:           "<b>Hello!</b>\n<tg-spoiler>🇷🇺 Привет!",
+			wantEnglish:     "<b>Hello!</b>",
+			wantTranslation: "🇷🇺 Привет!",
+		},
+		{
+			name:            "маркер перевода без спойлера",
+			input:           "<b>See you later.</b>\n🇷🇺 Увидимся позже.",
+			wantEnglish:     "<b>See you later.</b>",
+			wantTranslation: "🇷🇺 Увидимся позже.",
+		},
+		{
+			name:            "перевод без маркера и спойлера",
+			input:           "I have been to London.\nWe use Present Perfect here.\nЯ был в Лондоне.\nЗдесь используется Present Perfect.",
+			wantEnglish:     "I have been to London.\nWe use Present Perfect here.",
+			wantTranslation: "Я был в Лондоне.\nЗдесь используется Present Perfect.",
+		},
+		{
+			name:            "только английский",
+			input:           "<b>Great job!</b>",
+			wantEnglish:     "<b>Great job!</b>",
+			wantTranslation: "",
+		},
+		{
+			name:            "только русский",
+			input:           "Отличный вопрос! Давай разберем.",
+			wantEnglish:     "Отличный вопрос! Давай разберем.",
+			wantTranslation: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			english, translation := splitAIResponse(tt.input)
+			if english != tt.wantEnglish {
+				t.Errorf("ожидалась английская часть %q, получена %q", tt.wantEnglish, english)
+			}
+			if translation != tt.wantTranslation {
+				t.Errorf("ожидался перевод %q, получен %q", tt.wantTranslation, translation)
+			}
+		})
+	}
+}
+
+func TestEnsureResponseFormat(t *testing.T) {
+	valid := "<b>Hi!</b>\n\n<tg-spoiler>🇷🇺 Привет!</tg-spoiler>"
+	if got := ensureResponseFormat(valid); got != valid {
+		t.Errorf("корректный ответ не должен меняться, получен %q", got)
+	}
+
+	got := ensureResponseFormat("Nice to meet you.\nПриятно познакомиться.")
+	want := "Nice to meet you.\n\n<tg-spoiler>🇷🇺 Приятно познакомиться.</tg-spoiler>"
+	if got != want {
+		t.Errorf("ожидался %q, получен %q", want, got)
+	}
+
+	got = ensureResponseFormat("<b>Thanks!</b>\n<tg-spoiler>🇷🇺 Спасибо!")
+	if !hasExpectedFormat(got) {
+		t.Errorf("незакрытый спойлер должен быть исправлен, получен %q", got)
+	}
+
+	englishOnly := "<b>Well done!</b>"
+	if got := ensureResponseFormat(englishOnly); got != englishOnly {
+		t.Errorf("ответ без перевода не должен меняться, получен %q", got)
+	}
+}
+
+func TestExtractEnglishFromMalformedResponse(t *testing.T) {
+	got := postProcessText("I am fine, thanks.\nЯ в порядке, спасибо.", historyOptions)
+	if strings.Contains(got, "спасибо") {
+		t.Errorf("перевод не должен попадать в английскую часть, получено %q", got)
+	}
+	if got != "I am fine, thanks." {
+		t.Errorf("ожидалось %q, получено %q", "I am fine, thanks.", got)
+	}
+}
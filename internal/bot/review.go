@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/internal/premium"
+	"lingua-ai/pkg/models"
+)
+
+// ReviewMessagesCount сколько последних сообщений ученика разбирает /review
+const ReviewMessagesCount = 15
+
+// reviewMinMessages минимум сообщений, по которым разбор имеет смысл
+const reviewMinMessages = 3
+
+// handleReviewCommand разбирает типичные ошибки в последних сообщениях пользователя.
+// Разбор каждый раз генерируется заново: история меняется с каждым сообщением.
+func (h *Handler) handleReviewCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+
+	if !h.featureEnabled(user, premium.FeatureMistakesReview) {
+		return h.sendMessage(chatID, premiumFeatureHint(premium.FeatureMistakesReview))
+	}
+
+	// В истории есть и ответы бота, поэтому берем окно с запасом
+	history, err := h.messageService.GetChatHistory(ctx, user.ID, ReviewMessagesCount*2)
+	if err != nil {
+		h.logger.Error("ошибка получения истории для разбора ошибок", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось загрузить историю сообщений")
+	}
+
+	texts := recentUserTexts(history.Messages, ReviewMessagesCount)
+	if len(texts) < reviewMinMessages {
+		return h.sendMessage(chatID, fmt.Sprintf("✍️ Для разбора нужно хотя бы %d сообщения. Пообщайся со мной еще немного и возвращайся к /review!", reviewMinMessages))
+	}
+
+	if !h.aiAvailable() {
+		return h.sendOfflineMode(ctx, chatID, user)
+	}
+
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.prompts.GetReviewPrompt(user.Level, user.LearningLanguage)},
+		{Role: "user", Content: strings.Join(texts, "\n")},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.3,
+		MaxTokens:   700,
+	})
+	h.aiMetrics.RecordAIRequest("mistakes_review", err == nil, time.Since(start).Seconds())
+	if err != nil {
+		h.logger.Error("ошибка генерации разбора ошибок", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось подготовить разбор, попробуй позже")
+	}
+
+	// Называем реальное число сообщений: история в БД может быть короче ReviewMessagesCount
+	header := fmt.Sprintf("📝 <b>Разбор твоих ошибок</b> <i>(последние %d сообщ.)</i>\n\n", len(texts))
+	return h.sendMessage(chatID, header+postProcessText(response.Content, aiTextOptions))
+}
+
+// recentUserTexts возвращает до limit последних сообщений ученика в хронологическом порядке
+func recentUserTexts(messages []models.UserMessage, limit int) []string {
+	var texts []string
+	for i := len(messages) - 1; i >= 0 && len(texts) < limit; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		if text := strings.TrimSpace(messages[i].Content); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	for i, j := 0, len(texts)-1; i < j; i, j = i+1, j-1 {
+		texts[i], texts[j] = texts[j], texts[i]
+	}
+	return texts
+}
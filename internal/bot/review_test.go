@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/pkg/models"
+)
+
+// seedDialog добавляет в историю пользователя пары «ученик — бот»
+func seedDialog(th *testHarness, userID int64, texts ...string) {
+	now := time.Now()
+	for i, text := range texts {
+		at := now.Add(time.Duration(i-len(texts)) * time.Minute)
+		th.store.messages.messages = append(th.store.messages.messages,
+			models.UserMessage{UserID: userID, Role: "user", Content: text, CreatedAt: at},
+			models.UserMessage{UserID: userID, Role: "assistant", Content: "reply to " + text, CreatedAt: at},
+		)
+	}
+}
+
+func TestRecentUserTexts(t *testing.T) {
+	messages := []models.UserMessage{
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "answer"},
+		{Role: "user", Content: "  "},
+		{Role: "user", Content: "two"},
+		{Role: "user", Content: "three"},
+	}
+
+	got := recentUserTexts(messages, 2)
+	if strings.Join(got, "|") != "two|three" {
+		t.Errorf("ожидались два последних сообщения ученика по порядку, получено %q", got)
+	}
+}
+
+func TestReviewCommandSendsUserMessagesToAI(t *testing.T) {
+	th := newTestHarness(t, "• <b>I goes</b> → I go")
+	th.handler.SetPremiumFeatures(premium.NewFeatureGate())
+	th.sendText(t, 100, "/start")
+	userID := th.user(t, 100).ID
+	seedDialog(th, userID, "I goes to school", "She have a cat", "Yesterday I go home")
+	th.sender.reset()
+
+	th.sendText(t, 100, "/review")
+
+	if len(th.ai.calls) != 1 {
+		t.Fatalf("ожидался один запрос к AI, получено %d", len(th.ai.calls))
+	}
+	request := th.ai.calls[0]
+	if request[1].Content != "I goes to school\nShe have a cat\nYesterday I go home" {
+		t.Errorf("в AI должны уходить только сообщения ученика, получено %q", request[1].Content)
+	}
+	if last := th.sender.texts(); len(last) == 0 || !strings.Contains(last[len(last)-1], "Разбор твоих ошибок") {
+		t.Fatalf("ожидался разбор ошибок, получено %q", last)
+	}
+
+	// Разбор не кэшируется: повторная команда снова обращается к AI
+	th.ai.responses = append(th.ai.responses, "• ok")
+	th.sendText(t, 100, "/review")
+	if len(th.ai.calls) != 2 {
+		t.Errorf("повторный /review должен генерировать разбор заново, запросов: %d", len(th.ai.calls))
+	}
+}
+
+func TestReviewCommandRequiresPremium(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.SetPremiumFeatures(premium.NewFeatureGate(premium.FeatureMistakesReview))
+	th.sendText(t, 100, "/start")
+	seedDialog(th, th.user(t, 100).ID, "one", "two", "three")
+	th.sender.reset()
+
+	th.sendText(t, 100, "/review")
+
+	if len(th.ai.calls) != 0 {
+		t.Fatalf("без премиума разбор не должен запрашиваться, запросов: %d", len(th.ai.calls))
+	}
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "/premium") {
+		t.Fatalf("ожидалась подсказка о премиуме, получено %q", texts)
+	}
+}
+
+func TestReviewCommandNeedsEnoughMessages(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.SetPremiumFeatures(premium.NewFeatureGate())
+	th.sendText(t, 100, "/start")
+	seedDialog(th, th.user(t, 100).ID, "only one")
+	th.sender.reset()
+
+	th.sendText(t, 100, "/review")
+
+	if len(th.ai.calls) != 0 {
+		t.Fatalf("по одному сообщению разбор не нужен, запросов к AI: %d", len(th.ai.calls))
+	}
+	if texts := th.sender.texts(); len(texts) != 1 || !strings.Contains(texts[0], "хотя бы") {
+		t.Fatalf("ожидалась просьба написать больше, получено %q", texts)
+	}
+}
@@ -0,0 +1,21 @@
+package bot
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender часть Telegram API, через которую бот отправляет сообщения и отвечает на callback.
+// Реализуется *tgbotapi.BotAPI; в тестах подменяется фейком без сети.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// botIdentity возвращает данные бота, если отправитель — настоящий клиент Telegram
+func botIdentity(bot Sender) (tgbotapi.User, *tgbotapi.BotAPI) {
+	api, ok := bot.(*tgbotapi.BotAPI)
+	if !ok || api == nil {
+		return tgbotapi.User{}, nil
+	}
+	return api.Self, api
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleStreakWarningsCommand обрабатывает команду /streakwarnings on|off
+func (h *Handler) handleStreakWarningsCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		return h.setStreakWarnings(ctx, message.Chat.ID, user, true)
+	case "off":
+		return h.setStreakWarnings(ctx, message.Chat.ID, user, false)
+	default:
+		return h.sendMessage(message.Chat.ID, "🔥 <b>Предупреждения о серии</b>\n\n"+
+			"Если к вечеру занятий еще не было, я напомню, что серия под угрозой.\n\n"+
+			"<code>/streakwarnings on</code> — включить\n<code>/streakwarnings off</code> — отключить")
+	}
+}
+
+// handleStreakWarningCallback обрабатывает кнопки под предупреждением о серии
+func (h *Handler) handleStreakWarningCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	if callback.Data == models.StreakWarningOffCallback {
+		return h.setStreakWarnings(ctx, chatID, user, false)
+	}
+
+	until := time.Now().AddDate(0, 0, models.StreakWarningSnoozeDays)
+	if err := h.userService.SnoozeStreakWarnings(ctx, user.ID, until); err != nil {
+		h.logger.Error("ошибка откладывания предупреждений о серии", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось отложить предупреждения")
+	}
+	return h.sendMessage(chatID, fmt.Sprintf("😴 Не буду напоминать о серии %d дней.", models.StreakWarningSnoozeDays))
+}
+
+// setStreakWarnings включает или отключает предупреждения о серии
+func (h *Handler) setStreakWarnings(ctx context.Context, chatID int64, user *models.User, enabled bool) error {
+	if err := h.userService.SetStreakWarnings(ctx, user.ID, enabled); err != nil {
+		h.logger.Error("ошибка сохранения предупреждений о серии", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить настройку")
+	}
+
+	if enabled {
+		return h.sendMessage(chatID, "🔔 Предупреждения о серии включены.")
+	}
+	return h.sendMessage(chatID, "🔕 Предупреждения о серии отключены. Включить снова: <code>/streakwarnings on</code>")
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Параметры потоковых ответов AI в разговоре
+const (
+	streamEditInterval = 1500 * time.Millisecond // черновик правится не чаще, чтобы не упереться в лимиты Telegram
+	streamDraftCursor  = " ▍"                    // признак того, что ответ еще печатается
+)
+
+// generateConversationReply получает ответ AI для разговора. Если провайдер отдает ответ
+// частями, текст печатается в черновике, который правится по мере генерации; если поток
+// не открылся, оборвался или пришел пустым, выполняется обычный запрос, и его ответ
+// заменяет черновик. Неполный текст оборванного потока в историю не попадает.
+// Возвращает ID черновика (0 — черновик не создавался).
+func (h *Handler) generateConversationReply(ctx context.Context, chatID int64, messages []ai.Message, options ai.GenerationOptions) (*ai.Response, int, error) {
+	content, draftID, err := h.streamReply(ctx, chatID, messages, options)
+	if err == nil {
+		return &ai.Response{Content: content}, draftID, nil
+	}
+	if !errors.Is(err, ai.ErrStreamingUnsupported) {
+		h.logger.Warn("потоковый ответ AI не получен, используем обычный запрос", zap.Error(err))
+	}
+
+	response, err := h.aiClient.GenerateResponse(ctx, messages, options)
+	if err != nil && draftID != 0 {
+		h.deleteStreamDraft(chatID, draftID)
+		draftID = 0
+	}
+	return response, draftID, err
+}
+
+// streamReply собирает потоковый ответ AI и показывает его в черновике.
+// Ошибка возвращается, если поток не открылся, оборвался или не принес текста;
+// ID уже показанного черновика возвращается и вместе с ошибкой.
+func (h *Handler) streamReply(ctx context.Context, chatID int64, messages []ai.Message, options ai.GenerationOptions) (string, int, error) {
+	chunks, err := h.aiClient.GenerateResponseStream(ctx, messages, options)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var content strings.Builder
+	var lastEdit time.Time
+	var shown string
+	draftID := 0
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return "", draftID, fmt.Errorf("поток ответа прерван: %w", chunk.Err)
+		}
+		content.WriteString(chunk.Content)
+		if time.Since(lastEdit) < streamEditInterval {
+			continue
+		}
+		text := streamDraftText(content.String())
+		if text == "" || text == shown {
+			continue
+		}
+		lastEdit = time.Now()
+		shown = text
+		draftID = h.showStreamDraft(chatID, draftID, text)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", draftID, fmt.Errorf("поток ответа прерван: %w", err)
+	}
+	if strings.TrimSpace(content.String()) == "" {
+		return "", draftID, fmt.Errorf("пустой потоковый ответ")
+	}
+	return content.String(), draftID, nil
+}
+
+// showStreamDraft отправляет черновик ответа или правит уже отправленный
+func (h *Handler) showStreamDraft(chatID int64, draftID int, text string) int {
+	if draftID == 0 {
+		sent, err := h.sender.Send(tgbotapi.NewMessage(chatID, text))
+		if err != nil {
+			h.logger.Warn("не удалось отправить черновик ответа", zap.Error(err))
+			return 0
+		}
+		return sent.MessageID
+	}
+
+	if _, err := h.sender.Send(tgbotapi.NewEditMessageText(chatID, draftID, text)); err != nil {
+		h.logger.Warn("не удалось обновить черновик ответа", zap.Error(err))
+	}
+	return draftID
+}
+
+// streamDraftText текст черновика: без разметки, недописанного тега и строки быстрых ответов
+func streamDraftText(content string) string {
+	if i := strings.LastIndex(content, "<"); i >= 0 && !strings.Contains(content[i:], ">") {
+		content = content[:i]
+	}
+	content, _ = extractQuickReplies(content)
+	text := strings.TrimSpace(postProcessText(content, plainTextOptions))
+	if text == "" {
+		return ""
+	}
+	if runes := []rune(text); len(runes) > telegramMessageLimit {
+		text = string(runes[:telegramMessageLimit]) + "…"
+	}
+	return text + streamDraftCursor
+}
+
+// sendReplyWithTTS отправляет ответ AI с кнопками. Черновик потокового ответа заменяется
+// окончательным текстом; если ответ не помещается в одно сообщение, черновик удаляется.
+func (h *Handler) sendReplyWithTTS(chatID int64, draftID int, user *models.User, text string, extraRows ...[]tgbotapi.InlineKeyboardButton) error {
+	if draftID == 0 {
+		return h.sendMessageWithTTS(chatID, user, text, extraRows...)
+	}
+
+	if parts := PostProcess(text, sendOptions); len(parts) == 1 {
+		edit := tgbotapi.NewEditMessageText(chatID, draftID, parts[0])
+		edit.ParseMode = "HTML"
+		if rows := h.ttsRows(user, text, extraRows...); len(rows) > 0 {
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+			edit.ReplyMarkup = &keyboard
+		}
+		_, err := h.sender.Send(edit)
+		if err == nil {
+			return nil
+		}
+		h.logger.Warn("не удалось заменить черновик окончательным ответом", zap.Error(err))
+	}
+
+	h.deleteStreamDraft(chatID, draftID)
+	return h.sendMessageWithTTS(chatID, user, text, extraRows...)
+}
+
+// deleteStreamDraft удаляет черновик потокового ответа
+func (h *Handler) deleteStreamDraft(chatID int64, draftID int) {
+	if _, err := h.sender.Request(tgbotapi.NewDeleteMessage(chatID, draftID)); err != nil {
+		h.logger.Warn("не удалось удалить черновик ответа", zap.Error(err))
+	}
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestStreamDraftText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"разметка убирается", "<b>Nice!</b> Do you", "Nice! Do you" + streamDraftCursor},
+		{"недописанный тег отбрасывается", "Great job!\n\n<tg-spo", "Great job!" + streamDraftCursor},
+		{"строка быстрых ответов скрыта", "Do you like tea?\nSUGGESTIONS: Yes | No", "Do you like tea?" + streamDraftCursor},
+		{"пока нечего показать", "<b", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamDraftText(tt.content); got != tt.want {
+				t.Errorf("ожидалось %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConversationReplyIsStreamedIntoDraft(t *testing.T) {
+	// Исправление в ответе добавляет кнопку «Почему?», она должна попасть в окончательный ответ
+	th := newTestHarness(t, "✏️ I like green tea a lot\n<b>Green tea is great!</b> Do you drink it every day?")
+	th.ai.stream = true
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	// Фейковый отправитель нумерует сообщения по порядку отправки, начиная с 1
+	var draftID int
+	for i, c := range th.sender.sent {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok && strings.HasSuffix(msg.Text, streamDraftCursor) {
+			draftID = i + 1
+		}
+	}
+	if draftID == 0 {
+		t.Fatalf("ожидался черновик ответа, отправлено %q", th.sender.texts())
+	}
+
+	final, ok := th.sender.last().(tgbotapi.EditMessageTextConfig)
+	if !ok {
+		t.Fatalf("ожидалась замена черновика окончательным ответом, получено %#v", th.sender.last())
+	}
+	if final.MessageID != draftID || final.ParseMode != "HTML" {
+		t.Errorf("окончательный ответ должен заменить черновик %d в HTML, получено %d %q", draftID, final.MessageID, final.ParseMode)
+	}
+	if !strings.Contains(final.Text, "<b>Green tea is great!</b>") || strings.Contains(final.Text, streamDraftCursor) {
+		t.Errorf("неожиданный окончательный ответ: %q", final.Text)
+	}
+	if final.ReplyMarkup == nil {
+		t.Error("к окончательному ответу ожидались кнопки")
+	}
+	if len(th.ai.calls) != 1 {
+		t.Errorf("ожидался один запрос к AI, получено %d", len(th.ai.calls))
+	}
+}
+
+func TestConversationReplyFallsBackAfterEmptyStream(t *testing.T) {
+	th := newTestHarness(t, "", "<b>Green tea is great!</b>")
+	th.ai.stream = true
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	if len(th.ai.calls) != 2 {
+		t.Fatalf("после пустого потока ожидался обычный запрос, запросов: %d", len(th.ai.calls))
+	}
+	reply, ok := th.sender.last().(tgbotapi.MessageConfig)
+	if !ok || !strings.Contains(reply.Text, "Green tea is great!") {
+		t.Errorf("ожидался ответ обычного запроса, получено %#v", th.sender.last())
+	}
+}
+
+func TestConversationReplyFallsBackAfterTruncatedStream(t *testing.T) {
+	full := "<b>Green tea is great!</b> Do you drink it every day with your friends?"
+	th := newTestHarness(t, full)
+	th.ai.stream = true
+	th.ai.truncate = true
+
+	th.sendText(t, 100, "I like green tea very much")
+
+	if len(th.ai.calls) != 2 {
+		t.Fatalf("после оборванного потока ожидался обычный запрос, запросов: %d", len(th.ai.calls))
+	}
+	final, ok := th.sender.last().(tgbotapi.EditMessageTextConfig)
+	if !ok {
+		t.Fatalf("ожидалась замена черновика полным ответом, получено %#v", th.sender.last())
+	}
+	if !strings.Contains(final.Text, "with your friends?") || strings.Contains(final.Text, streamDraftCursor) {
+		t.Errorf("черновик должен замениться полным ответом, получено %q", final.Text)
+	}
+
+	var saved []string
+	for _, msg := range th.store.messages.messages {
+		if msg.Role == "assistant" {
+			saved = append(saved, msg.Content)
+		}
+	}
+	if len(saved) != 1 || !strings.Contains(saved[0], "with your friends?") {
+		t.Errorf("в историю должен попасть только полный ответ, сохранено %q", saved)
+	}
+}
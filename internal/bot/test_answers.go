@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"lingua-ai/pkg/models"
+)
+
+// testAnswerCallbackPrefix префикс кнопок ответа в тесте уровня: test_answer_<индекс варианта>
+const testAnswerCallbackPrefix = "test_answer_"
+
+// testAnswerLabels подписи кнопок ответа (варианты целиком показаны в тексте сообщения)
+var testAnswerLabels = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣"}
+
+// testAnswerCallback формирует callback кнопки варианта с индексом index
+func testAnswerCallback(index int) string {
+	return testAnswerCallbackPrefix + strconv.Itoa(index)
+}
+
+// testAnswerLabel возвращает подпись кнопки варианта с индексом index
+func testAnswerLabel(index int) string {
+	if index < len(testAnswerLabels) {
+		return testAnswerLabels[index]
+	}
+	return strconv.Itoa(index + 1)
+}
+
+// parseTestAnswerCallback возвращает индекс варианта из callback кнопки ответа
+func parseTestAnswerCallback(data string) (int, bool) {
+	if !strings.HasPrefix(data, testAnswerCallbackPrefix) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(data, testAnswerCallbackPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// parseTypedTestAnswer возвращает индекс варианта из номера, отправленного сообщением
+func parseTypedTestAnswer(text string) (int, bool) {
+	number, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, false
+	}
+	return number - 1, true
+}
+
+// isValidTestAnswer проверяет, что индекс соответствует одному из вариантов вопроса
+func isValidTestAnswer(index int, options []string) bool {
+	return index >= 0 && index < len(options)
+}
+
+// testAnswerNumbers перечисляет номера вариантов для подсказки: "1, 2, 3 или 4"
+func testAnswerNumbers(count int) string {
+	numbers := make([]string, count)
+	for i := range numbers {
+		numbers[i] = strconv.Itoa(i + 1)
+	}
+	if count < 2 {
+		return strings.Join(numbers, "")
+	}
+	return strings.Join(numbers[:count-1], ", ") + " или " + numbers[count-1]
+}
+
+// scoreLevelTestAnswer проверяет ответ на текущий вопрос теста, начисляет баллы и сохраняет ответ.
+// Возвращает false без изменения теста, если вопросов не осталось или индекс вне вариантов.
+func scoreLevelTestAnswer(levelTest *models.LevelTest, answer int) (models.LevelTestQuestion, bool, bool) {
+	if levelTest.CurrentQuestion < 0 || levelTest.CurrentQuestion >= len(levelTest.Questions) {
+		return models.LevelTestQuestion{}, false, false
+	}
+	currentQ := levelTest.Questions[levelTest.CurrentQuestion]
+	if !isValidTestAnswer(answer, currentQ.Options) {
+		return currentQ, false, false
+	}
+
+	isCorrect := answer == currentQ.CorrectAnswer
+	points := 0
+	if isCorrect {
+		points = currentQ.Points
+		levelTest.Score += points
+	}
+
+	levelTest.Answers = append(levelTest.Answers, models.LevelTestAnswer{
+		QuestionID: currentQ.ID,
+		Answer:     answer,
+		IsCorrect:  isCorrect,
+		Points:     points,
+	})
+	return currentQ, isCorrect, true
+}
+
+// correctTestOption возвращает правильный вариант в виде "2. is".
+// Для вопроса с некорректным индексом правильного ответа возвращается пустая строка.
+func correctTestOption(question models.LevelTestQuestion) string {
+	if !isValidTestAnswer(question.CorrectAnswer, question.Options) {
+		return ""
+	}
+	return strconv.Itoa(question.CorrectAnswer+1) + ". " + question.Options[question.CorrectAnswer]
+}
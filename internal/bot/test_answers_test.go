@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+func TestTestAnswerKeyboardRoundTrip(t *testing.T) {
+	options := []string{"am", "is", "are", "be", "been", "being"}
+	keyboard := (&Messages{}).GetTestAnswerKeyboard(options)
+
+	// Последняя строка — кнопка отмены
+	if len(keyboard) != len(options)+1 {
+		t.Fatalf("ожидалось %d строк, получено %d", len(options)+1, len(keyboard))
+	}
+	for i, row := range keyboard[:len(options)] {
+		index, ok := parseTestAnswerCallback(*row[0].CallbackData)
+		if !ok || index != i {
+			t.Errorf("кнопка %d: ожидался индекс %d, получено %d (ok=%v)", i, i, index, ok)
+		}
+	}
+	if keyboard[5][0].Text != "6" {
+		t.Errorf("ожидалась подпись 6 для шестого варианта, получено %q", keyboard[5][0].Text)
+	}
+}
+
+func TestParseTestAnswerCallbackMalformed(t *testing.T) {
+	for _, data := range []string{"test_answer_", "test_answer_x", "test_answer_1.5", "test_cancel"} {
+		if _, ok := parseTestAnswerCallback(data); ok {
+			t.Errorf("callback %q не должен распознаваться как ответ", data)
+		}
+	}
+}
+
+func TestParseTypedTestAnswer(t *testing.T) {
+	options := []string{"a", "b", "c"}
+	tests := []struct {
+		text  string
+		index int
+		valid bool
+	}{
+		{"1", 0, true},
+		{" 3 ", 2, true},
+		{"4", 3, false},
+		{"0", -1, false},
+		{"два", 0, false},
+	}
+
+	for _, tt := range tests {
+		index, ok := parseTypedTestAnswer(tt.text)
+		valid := ok && isValidTestAnswer(index, options)
+		if valid != tt.valid || (valid && index != tt.index) {
+			t.Errorf("%q: ожидалось %d (valid=%v), получено %d (valid=%v)", tt.text, tt.index, tt.valid, index, valid)
+		}
+	}
+
+	if got := testAnswerNumbers(4); got != "1, 2, 3 или 4" {
+		t.Errorf("ожидалось %q, получено %q", "1, 2, 3 или 4", got)
+	}
+}
+
+func TestLevelTestCallbackRejectsOutOfRangeIndex(t *testing.T) {
+	test := &models.LevelTest{
+		Questions: []models.LevelTestQuestion{
+			{ID: 1, Question: "I ___ a student.", Options: []string{"am", "is"}, CorrectAnswer: 0, Points: 1},
+		},
+	}
+	h := &Handler{
+		activeLevelTests: map[int64]*models.LevelTest{1: test},
+		logger:           zap.NewNop(),
+	}
+	callback := &tgbotapi.CallbackQuery{
+		Data:    testAnswerCallback(7),
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 100}},
+	}
+
+	for _, answer := range []int{7, 99, -1} {
+		if err := h.handleLevelTestCallback(context.Background(), callback, &models.User{ID: 1}, answer); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if len(test.Answers) != 0 || test.CurrentQuestion != 0 {
+		t.Errorf("неверный ответ не должен засчитываться: ответов %d, вопрос %d", len(test.Answers), test.CurrentQuestion)
+	}
+}
+
+func TestScoreLevelTestAnswerRejectsOutOfRange(t *testing.T) {
+	test := &models.LevelTest{
+		Questions: []models.LevelTestQuestion{
+			{ID: 1, Options: []string{"am", "is", "are"}, CorrectAnswer: 2, Points: 5},
+		},
+	}
+
+	for _, answer := range []int{99, 3, -1} {
+		if _, _, ok := scoreLevelTestAnswer(test, answer); ok {
+			t.Errorf("ответ %d вне вариантов не должен засчитываться", answer)
+		}
+	}
+	if len(test.Answers) != 0 || test.Score != 0 {
+		t.Fatalf("неверные ответы не должны менять тест: ответов %d, баллов %d", len(test.Answers), test.Score)
+	}
+
+	if _, isCorrect, ok := scoreLevelTestAnswer(test, 2); !ok || !isCorrect {
+		t.Fatalf("ожидался засчитанный правильный ответ, получено ok=%v correct=%v", ok, isCorrect)
+	}
+	if test.Score != 5 || len(test.Answers) != 1 {
+		t.Errorf("ожидалось 5 баллов и 1 ответ, получено %d и %d", test.Score, len(test.Answers))
+	}
+}
+
+func TestCorrectTestOptionGuardsBrokenQuestion(t *testing.T) {
+	question := models.LevelTestQuestion{Options: []string{"am", "is"}, CorrectAnswer: 5}
+	if got := correctTestOption(question); got != "" {
+		t.Errorf("ожидалась пустая строка для некорректного вопроса, получено %q", got)
+	}
+
+	question.CorrectAnswer = 1
+	if got := correctTestOption(question); got != "2. is" {
+		t.Errorf("ожидалось %q, получено %q", "2. is", got)
+	}
+}
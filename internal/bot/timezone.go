@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// timezoneResetArg аргумент /timezone, возвращающий пояс бота по умолчанию (DAILY_RESET_TZ)
+const timezoneResetArg = "reset"
+
+// handleTimezoneCommand обрабатывает команду /timezone [пояс] — часовой пояс для
+// сброса дневного лимита сообщений и подсчета серии занятий
+func (h *Handler) handleTimezoneCommand(ctx context.Context, message *tgbotapi.Message, u *models.User) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		return h.showTimezone(chatID, u)
+	}
+
+	timezone := arg
+	if strings.EqualFold(arg, timezoneResetArg) {
+		timezone = ""
+	}
+
+	if err := h.userService.SetTimezone(ctx, u.ID, timezone); err != nil {
+		if errors.Is(err, user.ErrInvalidTimezone) {
+			return h.sendMessage(chatID, fmt.Sprintf("⚠️ Не знаю часовой пояс <b>%s</b>. Укажите его как в базе IANA, "+
+				"например <code>/timezone Europe/Moscow</code> или <code>/timezone Asia/Yekaterinburg</code>.", html.EscapeString(arg)))
+		}
+		h.logger.Error("ошибка сохранения часового пояса", zap.Error(err), zap.Int64("user_id", u.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить часовой пояс")
+	}
+	u.Timezone = timezone
+
+	resetLoc := h.premiumService.ResetLocation()
+	if timezone == "" {
+		return h.sendMessage(chatID, fmt.Sprintf("🌍 Часовой пояс сброшен — день снова считается по поясу бота <b>%s</b>.",
+			html.EscapeString(resetLoc.String())))
+	}
+	return h.sendMessage(chatID, fmt.Sprintf("🌍 Часовой пояс сохранен: <b>%s</b>, у вас сейчас %s.\n\n"+
+		"Дневной лимит сообщений и серия занятий теперь считаются по вашей полуночи.",
+		html.EscapeString(timezone), time.Now().In(u.Location(resetLoc)).Format("15:04")))
+}
+
+// showTimezone показывает текущий часовой пояс и как его изменить
+func (h *Handler) showTimezone(chatID int64, u *models.User) error {
+	resetLoc := h.premiumService.ResetLocation()
+	current := fmt.Sprintf("не выбран, день считается по поясу бота <b>%s</b>", html.EscapeString(resetLoc.String()))
+	if u.Timezone != "" {
+		current = fmt.Sprintf("<b>%s</b>, у вас сейчас %s",
+			html.EscapeString(u.Timezone), time.Now().In(u.Location(resetLoc)).Format("15:04"))
+	}
+	return h.sendMessage(chatID, "🌍 <b>Часовой пояс</b>\n\n"+
+		"По нему в полночь сбрасывается дневной лимит сообщений и засчитывается день в серии занятий.\n"+
+		"Сейчас: "+current+"\n\n"+
+		"<code>/timezone Europe/Moscow</code> — выбрать пояс\n<code>/timezone reset</code> — вернуть пояс бота")
+}
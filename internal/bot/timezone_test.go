@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimezoneCommand(t *testing.T) {
+	th := newTestHarness(t)
+
+	th.sendText(t, 100, "/timezone Mars/Olympus")
+	if u := th.user(t, 100); u.Timezone != "" {
+		t.Fatalf("неизвестный пояс не должен сохраняться, получено %q", u.Timezone)
+	}
+	if texts := th.sender.texts(); !strings.Contains(texts[len(texts)-1], "Не знаю часовой пояс") {
+		t.Errorf("ожидалось сообщение о неизвестном поясе, получено %q", texts[len(texts)-1])
+	}
+
+	th.sendText(t, 100, "/timezone UTC")
+	if u := th.user(t, 100); u.Timezone != "UTC" {
+		t.Fatalf("ожидался сохраненный пояс UTC, получено %q", u.Timezone)
+	}
+
+	th.sendText(t, 100, "/timezone reset")
+	if u := th.user(t, 100); u.Timezone != "" {
+		t.Errorf("после reset должен использоваться пояс бота по умолчанию, получено %q", u.Timezone)
+	}
+	if texts := th.sender.texts(); !strings.Contains(texts[len(texts)-1], "по поясу бота <b>UTC</b>") {
+		t.Errorf("после reset ожидалось название пояса бота, получено %q", texts[len(texts)-1])
+	}
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// conversationTopics повседневные темы для начала разговора
+var conversationTopics = []string{
+	"еда и любимые блюда",
+	"планы на выходные",
+	"хобби и свободное время",
+	"путешествия и отпуск",
+	"работа или учеба",
+	"погода и время года",
+	"фильмы и сериалы",
+	"музыка",
+	"спорт и прогулки",
+	"семья и друзья",
+	"покупки",
+	"домашние животные",
+	"праздники и дни рождения",
+	"город, в котором ты живешь",
+	"утро и распорядок дня",
+	"книги",
+}
+
+// nextStarterTopic выбирает тему, которую в этом разговоре еще не предлагали, и запоминает ее.
+// Когда предложены все темы, круг начинается заново.
+func (dc *DialogContext) nextStarterTopic(pick func(n int) int) string {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if len(dc.starterTopics) >= len(conversationTopics) {
+		dc.starterTopics = nil
+	}
+
+	fresh := make([]string, 0, len(conversationTopics))
+	for _, topic := range conversationTopics {
+		if !dc.starterTopics[topic] {
+			fresh = append(fresh, topic)
+		}
+	}
+
+	topic := fresh[pick(len(fresh))]
+	if dc.starterTopics == nil {
+		dc.starterTopics = make(map[string]bool)
+	}
+	dc.starterTopics[topic] = true
+	return topic
+}
+
+// handleTopicStarterButton начинает разговор на случайную повседневную тему: бот задает
+// первый вопрос, а ответ ученика идет обычным путем диалога с исправлениями и XP
+func (h *Handler) handleTopicStarterButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+
+	if !h.aiAvailable() {
+		return h.sendOfflineMode(ctx, chatID, user)
+	}
+
+	// Вопрос для начала разговора расходует сообщение из дневного лимита, как и обычный ответ AI
+	canSend, err := h.premiumService.CanSendMessage(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка проверки лимита сообщений", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Ошибка проверки лимита сообщений")
+	}
+	if !canSend {
+		return h.handleMessageLimit(ctx, chatID, user)
+	}
+
+	dialogContext := h.getOrCreateDialogContext(ctx, user.ID, user.Level, user.LearningLanguage)
+	topic := dialogContext.nextStarterTopic(rand.Intn)
+	language := models.GetLearningLanguage(user.LearningLanguage)
+
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.withQuickReplies(h.prompts.GetTopicStarterPrompt(user.Level), message, user)},
+		{Role: "user", Content: fmt.Sprintf("Тема: %s\nЯзык разговора: %s", topic, language.Name)},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.9,
+		MaxTokens:   300,
+	})
+	h.aiMetrics.RecordAIRequest("topic_starter", err == nil, time.Since(start).Seconds())
+	if err != nil {
+		h.logger.Error("ошибка генерации темы для разговора", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось придумать тему, попробуй еще раз")
+	}
+
+	if err := h.premiumService.IncrementMessageCount(ctx, user.ID); err != nil {
+		h.logger.Error("ошибка увеличения счетчика сообщений", zap.Error(err))
+	}
+
+	content, quickReplies := extractQuickReplies(response.Content)
+	content = postProcessText(content, aiReplyOptions)
+
+	// Вопрос становится частью диалога, чтобы ответ ученика AI понял в контексте
+	if _, err := h.messageService.SaveAssistantMessage(ctx, user.ID, postProcessText(content, historyOptions)); err != nil {
+		h.logger.Error("ошибка сохранения вопроса для начала разговора", zap.Error(err))
+	}
+	dialogContext.AddAssistantMessage(content)
+	h.saveDialogContext(ctx, dialogContext)
+
+	h.logger.Info("начат разговор на тему",
+		zap.Int64("user_id", user.ID),
+		zap.String("topic", topic))
+
+	return h.sendReplyWithTTS(chatID, 0, user, content, h.quickReplyRows(user.ID, quickReplies)...)
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNextStarterTopicAvoidsRepeats(t *testing.T) {
+	dc := NewDialogContext(1, "beginner", "")
+	first := func(n int) int { return 0 }
+
+	seen := make(map[string]bool)
+	for range conversationTopics {
+		topic := dc.nextStarterTopic(first)
+		if seen[topic] {
+			t.Fatalf("тема %q предложена повторно до того, как закончились остальные", topic)
+		}
+		seen[topic] = true
+	}
+
+	if topic := dc.nextStarterTopic(first); topic != conversationTopics[0] {
+		t.Errorf("после всех тем круг должен начаться заново, получено %q", topic)
+	}
+}
+
+func TestTopicStarterButtonSeedsDialog(t *testing.T) {
+	th := newTestHarness(t, "<b>Do you have any plans for the weekend?</b>\n\n<tg-spoiler>🇷🇺 Есть планы на выходные?</tg-spoiler>")
+	th.sendText(t, 100, "/start")
+	th.sender.reset()
+
+	th.sendText(t, 100, menuButtons[ActionTopic].Text)
+
+	texts := th.sender.texts()
+	if len(texts) == 0 || !strings.Contains(texts[len(texts)-1], "plans for the weekend") {
+		t.Fatalf("ожидался вопрос для начала разговора, получено %q", texts)
+	}
+
+	request := th.ai.calls[len(th.ai.calls)-1]
+	if !strings.Contains(request[len(request)-1].Content, "Тема: ") {
+		t.Errorf("в запросе к AI должна быть тема, получено %q", request[len(request)-1].Content)
+	}
+
+	user := th.user(t, 100)
+	_, recent := th.handler.dialogContexts[user.ID].Snapshot()
+	if len(recent) == 0 || recent[len(recent)-1].Role != "assistant" {
+		t.Errorf("вопрос должен попасть в контекст диалога, получено %+v", recent)
+	}
+}
+
+func TestTopicStarterButtonCountsTowardMessageLimit(t *testing.T) {
+	th := newTestHarness(t, "<b>What did you do yesterday?</b>")
+	th.sendText(t, 100, "/start")
+
+	th.sendText(t, 100, menuButtons[ActionTopic].Text)
+	user := th.user(t, 100)
+	if user.MessagesCount != 1 {
+		t.Fatalf("вопрос для начала разговора должен расходовать сообщение, счетчик %d", user.MessagesCount)
+	}
+
+	th.store.users.mu.Lock()
+	th.store.users.users[user.ID].MessagesCount = user.MaxMessages
+	th.store.users.mu.Unlock()
+	calls := len(th.ai.calls)
+	th.sender.reset()
+
+	th.sendText(t, 100, menuButtons[ActionTopic].Text)
+	if len(th.ai.calls) != calls {
+		t.Error("после исчерпания лимита AI не должен вызываться")
+	}
+	if texts := th.sender.texts(); len(texts) == 0 || !strings.Contains(texts[len(texts)-1], "Достигнут лимит сообщений") {
+		t.Errorf("ожидалось сообщение о лимите, получено %q", texts)
+	}
+}
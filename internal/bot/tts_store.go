@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Параметры хранилища текстов для озвучки
+const (
+	TTSTextTTL = 24 * time.Hour // Сколько живет текст для кнопок озвучки
+	// maxCallbackDataLen — лимит Telegram на размер callback_data в байтах
+	maxCallbackDataLen = 64
+	// ttsTokenBytes — случайные байты токена: 96 бит не угадать и не повторить после перезапуска
+	ttsTokenBytes = 12
+)
+
+// ttsTextEntry текст для озвучки, его владелец и время сохранения
+type ttsTextEntry struct {
+	text      string
+	userID    int64
+	createdAt time.Time
+}
+
+// ttsTextStore хранит тексты для озвучки на сервере, в callback уходит только короткий токен
+type ttsTextStore struct {
+	mu      sync.Mutex
+	entries map[string]ttsTextEntry
+	now     func() time.Time
+}
+
+// newTTSTextStore создает хранилище текстов для озвучки
+func newTTSTextStore() *ttsTextStore {
+	return &ttsTextStore{
+		entries: make(map[string]ttsTextEntry),
+		now:     time.Now,
+	}
+}
+
+// put сохраняет текст пользователя userID и возвращает случайный токен для callback data.
+// Заодно удаляет устаревшие записи.
+func (s *ttsTextStore) put(userID int64, text string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for token, entry := range s.entries {
+		if now.Sub(entry.createdAt) > TTSTextTTL {
+			delete(s.entries, token)
+		}
+	}
+
+	var token string
+	for token == "" || s.entries[token].text != "" {
+		bytes := make([]byte, ttsTokenBytes)
+		if _, err := rand.Read(bytes); err != nil {
+			return "", fmt.Errorf("ошибка генерации токена озвучки: %w", err)
+		}
+		token = base64.RawURLEncoding.EncodeToString(bytes)
+	}
+	s.entries[token] = ttsTextEntry{text: text, userID: userID, createdAt: now}
+	return token, nil
+}
+
+// get возвращает запись по токену, если она еще не устарела
+func (s *ttsTextStore) get(token string) (ttsTextEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[token]
+	if !ok {
+		return ttsTextEntry{}, false
+	}
+	if s.now().Sub(entry.createdAt) > TTSTextTTL {
+		delete(s.entries, token)
+		return ttsTextEntry{}, false
+	}
+	return entry, true
+}
+
+// size возвращает число сохраненных текстов
+func (s *ttsTextStore) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
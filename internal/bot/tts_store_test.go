@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/tts"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCreateTTSButtonLongSentenceFitsCallbackLimit(t *testing.T) {
+	h := &Handler{ttsTexts: newTTSTextStore()}
+	long := strings.Repeat("This is a rather long English sentence for the voice button. ", 10)
+
+	button, err := h.createTTSButton(1, long)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if button.CallbackData == nil {
+		t.Fatal("ожидалась callback data у кнопки")
+	}
+	data := *button.CallbackData
+	if len(data) > maxCallbackDataLen {
+		t.Fatalf("callback data длиной %d байт превышает лимит Telegram", len(data))
+	}
+	if len(ttsSlowCallbackPrefix+strings.TrimPrefix(data, ttsCallbackPrefix)) > maxCallbackDataLen {
+		t.Fatal("callback замедленной озвучки превышает лимит Telegram")
+	}
+
+	entry, ok := h.ttsTexts.get(strings.TrimPrefix(data, ttsCallbackPrefix))
+	if !ok {
+		t.Fatal("текст должен находиться по токену")
+	}
+	if entry.text != long || entry.userID != 1 {
+		t.Errorf("ожидался исходный текст пользователя 1, получено %q пользователя %d", entry.text, entry.userID)
+	}
+}
+
+func TestTTSTextStoreExpires(t *testing.T) {
+	s := newTTSTextStore()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	token, _ := s.put(1, "Hello")
+	if other, _ := s.put(1, "World"); other == token {
+		t.Fatal("токены должны быть уникальными")
+	}
+
+	now = now.Add(TTSTextTTL + time.Minute)
+	if _, ok := s.get(token); ok {
+		t.Error("устаревший текст не должен находиться")
+	}
+
+	s.put(1, "Fresh")
+	if s.size() != 1 {
+		t.Errorf("ожидалась 1 запись после очистки, получено %d", s.size())
+	}
+}
+
+// silentTTS синтезатор речи, который ничего не озвучивает
+type silentTTS struct{ tts.TTSService }
+
+func (silentTTS) SynthesizeText(ctx context.Context, text string) ([]byte, error) { return nil, nil }
+
+func TestVoiceButtonOnlyWhenVoiceRepliesEnabled(t *testing.T) {
+	h := &Handler{
+		ttsService:      silentTTS{},
+		ttsTexts:        newTTSTextStore(),
+		logger:          zaptest.NewLogger(t),
+		premiumFeatures: premium.NewFeatureGate(premium.FeatureVoiceReplies),
+	}
+	text := "<b>Green tea is great!</b>\n\n<tg-spoiler>🇷🇺 Зеленый чай прекрасен!</tg-spoiler>"
+
+	free := &models.User{ID: 1}
+	if rows := h.ttsRows(free, text); len(rows) != 0 {
+		t.Errorf("бесплатному пользователю кнопка озвучки не нужна, получено %d рядов", len(rows))
+	}
+
+	subscriber := &models.User{ID: 2, IsPremium: true}
+	if rows := h.ttsRows(subscriber, text); len(rows) != 1 {
+		t.Errorf("премиум-пользователю ожидалась кнопка озвучки, получено %d рядов", len(rows))
+	}
+
+	h.premiumFeatures = premium.NewFeatureGate()
+	if rows := h.ttsRows(free, text); len(rows) != 1 {
+		t.Errorf("без ограничения озвучка доступна всем, получено %d рядов", len(rows))
+	}
+}
+
+func TestTTSTokensAreRandom(t *testing.T) {
+	// Токены не должны повторяться в новом хранилище, как последовательные номера после перезапуска
+	first, second := newTTSTextStore(), newTTSTextStore()
+	a, err := first.put(1, "Hello")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	b, _ := second.put(1, "Hello")
+	if a == b {
+		t.Errorf("токены разных хранилищ совпали: %q", a)
+	}
+	if len(ttsSlowCallbackPrefix+a) > maxCallbackDataLen {
+		t.Errorf("токен %q не помещается в callback data", a)
+	}
+}
+
+func TestTTSCallbackRejectsOtherUsersToken(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.ttsService = silentTTS{}
+	th.sendText(t, 100, "/start")
+	th.sendText(t, 200, "/start")
+
+	token, err := th.handler.ttsTexts.put(th.user(t, 100).ID, "Secret sentence")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	th.sender.reset()
+	th.pressButton(t, 200, ttsCallbackPrefix+token)
+	if len(th.sender.sent) != 0 {
+		t.Errorf("чужой текст не должен озвучиваться, отправлено %d сообщений", len(th.sender.sent))
+	}
+	rejected := false
+	for _, request := range th.sender.requests {
+		if answer, ok := request.(tgbotapi.CallbackConfig); ok && strings.Contains(answer.Text, "не для тебя") {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Errorf("ожидался отказ в ответе на нажатие, получено %#v", th.sender.requests)
+	}
+}
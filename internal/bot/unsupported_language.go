@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// foreignTranslateCallback кнопка перевода сообщения, написанного на третьем языке.
+// Ответ бота отправляется реплаем, поэтому исходный текст берется из ReplyToMessage.
+const foreignTranslateCallback = "translate_foreign"
+
+// SetUnsupportedLanguageReply задает ответ на сообщение не на русском и не на изучаемом языке
+// (пустой — стандартный) и включает кнопку перевода такого сообщения на изучаемый язык
+func (h *Handler) SetUnsupportedLanguageReply(reply string, offerTranslation bool) {
+	h.unsupportedLanguageReply = strings.TrimSpace(reply)
+	h.offerForeignTranslation = offerTranslation
+}
+
+// handleUnsupportedLanguage объясняет, какому языку учит бот, и предлагает перевод
+func (h *Handler) handleUnsupportedLanguage(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	h.logger.Info("сообщение на неподдерживаемом языке", zap.Int64("user_id", user.ID))
+
+	language := models.GetLearningLanguage(user.LearningLanguage)
+	text := h.unsupportedLanguageReply
+	if text == "" {
+		text = h.messages.UnsupportedLanguage(language, h.offerForeignTranslation)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyToMessageID = message.MessageID
+	if h.offerForeignTranslation {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(language.Flag+" Перевести на "+language.Accusative, foreignTranslateCallback),
+		))
+	}
+
+	_, err := h.sender.Send(msg)
+	return err
+}
+
+// handleForeignTranslateCallback переводит исходное сообщение на изучаемый язык
+func (h *Handler) handleForeignTranslateCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+	original := callback.Message.ReplyToMessage
+	if original == nil || strings.TrimSpace(original.Text) == "" {
+		return h.sendMessage(chatID, "⚠️ Не удалось найти сообщение для перевода. Отправьте его еще раз.")
+	}
+
+	// Перевод расходует сообщение из дневного лимита, как и обычный ответ AI
+	canSend, err := h.premiumService.CanSendMessage(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка проверки лимита сообщений", zap.Error(err))
+		return h.sendErrorMessage(chatID, "Ошибка проверки лимита сообщений")
+	}
+	if !canSend {
+		return h.handleMessageLimit(ctx, chatID, user)
+	}
+
+	aiMessages := []ai.Message{
+		{Role: "system", Content: h.prompts.GetForeignTranslationPrompt(user.Level, user.LearningLanguage)},
+		{Role: "user", Content: original.Text},
+	}
+
+	start := time.Now()
+	response, err := h.aiClient.GenerateResponse(ctx, aiMessages, ai.GenerationOptions{
+		Temperature: 0.3,
+		MaxTokens:   300,
+	})
+	h.aiMetrics.RecordAIRequest("foreign_translation", err == nil, time.Since(start).Seconds())
+	if err != nil {
+		h.logger.Error("ошибка перевода сообщения на третьем языке", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось перевести сообщение")
+	}
+
+	if err := h.premiumService.IncrementMessageCount(ctx, user.ID); err != nil {
+		h.logger.Error("ошибка увеличения счетчика сообщений", zap.Error(err))
+	}
+
+	// Убираем кнопку, чтобы перевод не запрашивали повторно
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := h.sender.Send(edit); err != nil {
+		h.logger.Warn("не удалось убрать кнопку перевода", zap.Error(err))
+	}
+
+	translation := postProcessText(response.Content, aiReplyOptions)
+	language := models.GetLearningLanguage(user.LearningLanguage)
+	return h.sendMessage(chatID, translation+"\n\n✍️ Попробуй написать это сам на "+language.Prepositional+"!")
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"lingua-ai/pkg/models"
+)
+
+// vocabularyChartWidth ширина самого длинного столбика графика
+const vocabularyChartWidth = 10
+
+// vocabularyTrendText рисует рост словарного запаса: размер и текстовый график по неделям
+func vocabularyTrendText(trend *models.VocabularyTrend) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "📖 <b>Словарный запас:</b> %d\n", trend.Total)
+
+	best := 0
+	for _, week := range trend.Weeks {
+		best = max(best, week.Learned)
+	}
+	if best == 0 {
+		text.WriteString("За последние недели новых выученных слов нет — самое время начать!")
+		return text.String()
+	}
+
+	text.WriteString("<b>Выучено по неделям:</b>\n<code>")
+	for i, week := range trend.Weeks {
+		bar := week.Learned * vocabularyChartWidth / best
+		if week.Learned > 0 && bar == 0 {
+			bar = 1
+		}
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		fmt.Fprintf(&text, "%s %-*s %d", week.Start.Format("02.01"), vocabularyChartWidth, strings.Repeat("▇", bar), week.Learned)
+	}
+	text.WriteString("</code>")
+	return text.String()
+}
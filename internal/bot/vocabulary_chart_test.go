@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestVocabularyTrendText(t *testing.T) {
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	trend := &models.VocabularyTrend{
+		Total: 42,
+		Weeks: []models.VocabularyWeek{
+			{Start: start, Learned: 10},
+			{Start: start.AddDate(0, 0, 7), Learned: 0},
+			{Start: start.AddDate(0, 0, 14), Learned: 1},
+		},
+	}
+
+	text := vocabularyTrendText(trend)
+	if !strings.Contains(text, "Словарный запас:</b> 42") {
+		t.Errorf("ожидался размер словарного запаса, получено %q", text)
+	}
+	for _, line := range []string{
+		"05.10 " + strings.Repeat("▇", vocabularyChartWidth) + " 10",
+		"12.10 " + strings.Repeat(" ", vocabularyChartWidth) + " 0",
+		"19.10 ▇" + strings.Repeat(" ", vocabularyChartWidth-1) + " 1",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("ожидалась строка графика %q в %q", line, text)
+		}
+	}
+}
+
+func TestVocabularyTrendTextWithoutProgress(t *testing.T) {
+	trend := &models.VocabularyTrend{Total: 5, Weeks: []models.VocabularyWeek{{Start: time.Now()}}}
+
+	if text := vocabularyTrendText(trend); strings.Contains(text, "<code>") {
+		t.Errorf("без выученных слов график не нужен, получено %q", text)
+	}
+}
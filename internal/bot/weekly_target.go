@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// weeklyTargetCallbackPrefix префикс кнопок выбора недельной цели: weeklytarget_<слов в неделю>
+const weeklyTargetCallbackPrefix = "weeklytarget_"
+
+// handleWeeklyTargetCommand обрабатывает команду /weeklytarget [число|off] — сколько слов выучить за неделю
+func (h *Handler) handleWeeklyTargetCommand(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	chatID := message.Chat.ID
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	switch arg {
+	case "":
+		return h.showWeeklyTarget(ctx, chatID, user)
+	case "off":
+		return h.setWeeklyTarget(ctx, chatID, user, 0)
+	}
+
+	target, err := strconv.Atoi(arg)
+	if err != nil {
+		return h.sendMessage(chatID, fmt.Sprintf("🔢 Укажите число от %d до %d: <code>/weeklytarget 20</code>",
+			models.MinWeeklyWordTarget, models.MaxWeeklyWordTarget))
+	}
+	return h.setWeeklyTarget(ctx, chatID, user, target)
+}
+
+// handleWeeklyTargetCallback обрабатывает кнопку выбора недельной цели
+func (h *Handler) handleWeeklyTargetCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	target, err := strconv.Atoi(strings.TrimPrefix(callback.Data, weeklyTargetCallbackPrefix))
+	if err != nil {
+		h.logger.Warn("неверная недельная цель в кнопке выбора", zap.String("data", callback.Data))
+		return nil
+	}
+	return h.setWeeklyTarget(ctx, callback.Message.Chat.ID, user, target)
+}
+
+// showWeeklyTarget показывает недельную цель, прогресс и кнопки выбора цели
+func (h *Handler) showWeeklyTarget(ctx context.Context, chatID int64, u *models.User) error {
+	progress, err := h.flashcardHandler.flashcardService.WeeklyProgress(ctx, u.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения недельной цели", zap.Error(err), zap.Int64("user_id", u.ID))
+		return h.sendErrorMessage(chatID, "Не удалось получить недельную цель")
+	}
+
+	text := "🏁 <b>Недельная цель</b>\n\n"
+	if progress.HasTarget() {
+		text += weeklyTargetProgressText(progress) + "\n\n"
+	} else {
+		text += fmt.Sprintf("Цель не задана. На этой неделе выучено слов: %d\n\n", progress.Learned)
+	}
+	text += fmt.Sprintf("За выполнение цели — +%d XP. Выберите цель или укажите свою: <code>/weeklytarget 25</code> (от %d до %d), "+
+		"снять цель: <code>/weeklytarget off</code>",
+		models.WeeklyTargetBonusXP, models.MinWeeklyWordTarget, models.MaxWeeklyWordTarget)
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, option := range models.WeeklyTargetOptions {
+		label := strconv.Itoa(option)
+		if option == progress.Target {
+			label = "✅ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, weeklyTargetCallbackPrefix+strconv.Itoa(option)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+
+	_, err = h.sender.Send(msg)
+	return err
+}
+
+// setWeeklyTarget сохраняет недельную цель; 0 снимает ее
+func (h *Handler) setWeeklyTarget(ctx context.Context, chatID int64, u *models.User, target int) error {
+	if err := h.userService.SetWeeklyWordTarget(ctx, u.ID, target); err != nil {
+		if errors.Is(err, user.ErrInvalidWeeklyTarget) {
+			return h.sendMessage(chatID, fmt.Sprintf("⚠️ Недельная цель должна быть от %d до %d слов.",
+				models.MinWeeklyWordTarget, models.MaxWeeklyWordTarget))
+		}
+		h.logger.Error("ошибка сохранения недельной цели", zap.Error(err), zap.Int64("user_id", u.ID))
+		return h.sendErrorMessage(chatID, "Не удалось сохранить недельную цель")
+	}
+	u.WeeklyWordTarget = target
+
+	if target == 0 {
+		return h.sendMessage(chatID, "🏁 Недельная цель снята. Задать снова: <code>/weeklytarget 20</code>")
+	}
+	return h.sendMessage(chatID, fmt.Sprintf("✅ Цель сохранена: <b>%d</b> новых слов в неделю. Прогресс — в статистике карточек.", target))
+}
+
+// checkWeeklyTarget поздравляет с выполнением недельной цели и начисляет бонус.
+// Вызывается, когда пользователь выучил очередное слово; бонус засчитывается раз в неделю.
+func (h *Handler) checkWeeklyTarget(ctx context.Context, chatID int64, userID int64) {
+	progress, err := h.flashcardHandler.flashcardService.WeeklyProgress(ctx, userID)
+	if err != nil {
+		h.logger.Warn("не удалось проверить недельную цель", zap.Error(err), zap.Int64("user_id", userID))
+		return
+	}
+	if !progress.Completed() {
+		return
+	}
+
+	completed, err := h.userService.CompleteWeeklyTarget(ctx, userID, progress.WeekStart)
+	if err != nil {
+		h.logger.Error("ошибка засчитывания недельной цели", zap.Error(err), zap.Int64("user_id", userID))
+	}
+	if !completed {
+		return
+	}
+
+	text := fmt.Sprintf("🏆 <b>Недельная цель выполнена!</b>\n\nВыучено слов за неделю: %d из %d.\n🎁 Бонус: +%d XP",
+		progress.Learned, progress.Target, models.WeeklyTargetBonusXP)
+	if err := h.sendMessage(chatID, text); err != nil {
+		h.logger.Warn("не удалось отправить поздравление с недельной целью", zap.Error(err), zap.Int64("user_id", userID))
+	}
+}
+
+// weeklyTargetProgressText строка прогресса недельной цели для статистики
+func weeklyTargetProgressText(progress *models.WeeklyTargetProgress) string {
+	if progress.Completed() {
+		return fmt.Sprintf("🏆 Цель недели выполнена: %d из %d слов", progress.Learned, progress.Target)
+	}
+	return fmt.Sprintf("🏁 Цель недели: %d из %d слов (осталось %d)", progress.Learned, progress.Target, progress.Remaining())
+}
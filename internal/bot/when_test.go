@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestFormatWordSchedule(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	pronunciationScore := 85
+
+	cases := []struct {
+		name string
+		card *models.UserFlashcard
+		want []string
+	}{
+		{
+			name: "повторение в будущем",
+			card: &models.UserFlashcard{
+				ReviewCount:  4,
+				CorrectCount: 3,
+				NextReviewAt: now.Add(49 * time.Hour),
+				Flashcard:    &models.Flashcard{Word: "apple", Translation: "яблоко"},
+			},
+			want: []string{"apple", "через 2 дн", "Повторений: 4", "Точность: 75%"},
+		},
+		{
+			name: "пора повторить",
+			card: &models.UserFlashcard{NextReviewAt: now.Add(-time.Minute)},
+			want: []string{"прямо сейчас", "Точность: 0%"},
+		},
+		{
+			name: "выучено",
+			card: &models.UserFlashcard{IsLearned: true, NextReviewAt: now.Add(time.Hour)},
+			want: []string{"выучено"},
+		},
+		{
+			name: "с оценкой произношения",
+			card: &models.UserFlashcard{NextReviewAt: now.Add(time.Hour), BestPronunciationScore: &pronunciationScore},
+			want: []string{"Лучшее произношение: 85%"},
+		},
+	}
+
+	for _, tc := range cases {
+		got := formatWordSchedule(tc.card, now)
+		for _, part := range tc.want {
+			if !strings.Contains(got, part) {
+				t.Errorf("%s: ожидалось %q в %q", tc.name, part, got)
+			}
+		}
+	}
+}
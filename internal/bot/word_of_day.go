@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"lingua-ai/internal/tts"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleWordOfDayTTSCallback озвучивает слово дня. Сообщение отправляет джоба без доступа
+// к хранилищу текстов озвучки, поэтому в кнопке лежит ID карточки, а не токен текста.
+func (h *Handler) handleWordOfDayTTSCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	flashcardID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, models.WordOfDayTTSCallbackPrefix), 10, 64)
+	if err != nil {
+		h.logger.Warn("некорректный callback озвучки слова дня", zap.String("data", callback.Data))
+		return nil
+	}
+
+	card, err := h.flashcardHandler.flashcardService.GetFlashcard(ctx, flashcardID)
+	if err != nil {
+		h.logger.Error("ошибка получения слова дня для озвучки", zap.Error(err), zap.Int64("flashcard_id", flashcardID))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, "❌ Слово больше недоступно"))
+		return nil
+	}
+
+	token, err := h.ttsTexts.put(user.ID, card.Word)
+	if err != nil {
+		h.logger.Error("ошибка сохранения слова дня для озвучки", zap.Error(err))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, "❌ Озвучка временно недоступна"))
+		return nil
+	}
+	return h.handleTTSCallback(ctx, callback, user, token, tts.NormalSpeed)
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// wordOfDayRepo знает только карточку с ID 7
+type wordOfDayRepo struct {
+	store.FlashcardRepository
+}
+
+func (r *wordOfDayRepo) GetFlashcardByID(ctx context.Context, id int64) (*models.Flashcard, error) {
+	if id != 7 {
+		return nil, store.ErrFlashcardNotFound
+	}
+	return &models.Flashcard{ID: id, Word: "weather", Translation: "погода"}, nil
+}
+
+func TestWordOfDayTTSButtonUsesCardWord(t *testing.T) {
+	th := newTestHarness(t)
+	th.handler.flashcardHandler.flashcardService = flashcards.NewService(&wordOfDayRepo{}, flashcards.DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+	th.sendText(t, 100, "/start")
+
+	stored := th.handler.ttsTexts.size()
+	th.pressButton(t, 100, models.WordOfDayTTSCallbackPrefix+"404")
+	if th.handler.ttsTexts.size() != stored {
+		t.Fatal("для неизвестной карточки текст озвучки не должен сохраняться")
+	}
+
+	th.pressButton(t, 100, models.WordOfDayTTSCallbackPrefix+"7")
+	if th.handler.ttsTexts.size() != stored+1 {
+		t.Fatal("слово дня должно попасть в хранилище текстов озвучки")
+	}
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleWordPackButton показывает набор слов недели
+func (h *Handler) handleWordPackButton(ctx context.Context, message *tgbotapi.Message, user *models.User) error {
+	pack, err := h.wordPackService.GetWeeklyPack(ctx, user.ID, user.Level)
+	if err != nil {
+		h.logger.Error("ошибка получения набора недели", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(message.Chat.ID, "Не удалось загрузить набор недели")
+	}
+
+	progressButton := tgbotapi.NewInlineKeyboardButtonData("📈 Мои наборы", "wordpack_progress")
+
+	if pack == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, `📦 <b>Набор недели</b>
+
+🎉 Вы прошли все наборы для вашего уровня! Новые наборы появятся позже.`)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(progressButton))
+		_, err := h.sender.Send(msg)
+		return err
+	}
+
+	messageText := fmt.Sprintf(`📦 <b>Набор недели: %s</b>
+
+%s
+
+📊 Уровень: %s
+📝 Слов в наборе: %d
+
+Добавить слова в ваши карточки для повторения?`,
+		pack.Name, pack.Description, h.getLevelText(pack.Level), pack.WordsCount)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, messageText)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Добавить в карточки", fmt.Sprintf("wordpack_accept_%d", pack.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(progressButton),
+	)
+
+	_, err = h.sender.Send(msg)
+	return err
+}
+
+// handleWordPackCallback обрабатывает inline кнопки наборов слов
+func (h *Handler) handleWordPackCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User) error {
+	chatID := callback.Message.Chat.ID
+
+	switch {
+	case callback.Data == "wordpack_progress":
+		return h.showWordPacksProgress(ctx, chatID, user)
+	case strings.HasPrefix(callback.Data, "wordpack_accept_"):
+		packID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, "wordpack_accept_"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный ID набора: %w", err)
+		}
+
+		pack, added, err := h.wordPackService.AcceptPack(ctx, user.ID, packID)
+		if errors.Is(err, store.ErrWordPackAlreadyAdded) {
+			return h.sendMessage(chatID, "📦 Этот набор уже добавлен в ваши карточки.")
+		}
+		if errors.Is(err, store.ErrWordPackNotFound) {
+			return h.sendMessage(chatID, "❌ Набор не найден. Откройте «📦 Набор недели» заново.")
+		}
+		if err != nil {
+			h.logger.Error("ошибка принятия набора слов", zap.Error(err), zap.Int64("pack_id", packID))
+			return h.sendMessage(chatID, "❌ Не удалось добавить набор. Попробуйте позже.")
+		}
+
+		text := fmt.Sprintf(`✅ Набор <b>%s</b> добавлен!
+
+📝 Новых карточек: %d
+
+Слова появятся в вашей следующей сессии карточек.`, pack.Name, added)
+
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🎯 Начать изучение", "flashcard_start"),
+			),
+		)
+		_, err = h.sender.Send(msg)
+		return err
+	default:
+		return fmt.Errorf("неизвестная команда наборов слов: %s", callback.Data)
+	}
+}
+
+// showWordPacksProgress показывает прогресс по принятым наборам
+func (h *Handler) showWordPacksProgress(ctx context.Context, chatID int64, user *models.User) error {
+	progress, err := h.wordPackService.GetUserPacksProgress(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка получения прогресса наборов", zap.Error(err), zap.Int64("user_id", user.ID))
+		return h.sendErrorMessage(chatID, "Не удалось загрузить прогресс наборов")
+	}
+
+	if len(progress) == 0 {
+		return h.sendMessage(chatID, "📦 Вы еще не добавили ни одного набора слов. Загляните в «📦 Набор недели»!")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📈 <b>Мои наборы слов</b>\n\n")
+	for _, p := range progress {
+		status := "📖"
+		if p.IsCompleted() {
+			status = "✅"
+		}
+		sb.WriteString(fmt.Sprintf("%s <b>%s</b> — %d/%d\n%s\n\n",
+			status, p.Pack.Name, p.LearnedCount, p.Pack.WordsCount,
+			h.flashcardHandler.getProgressBar(p.LearnedCount, p.Pack.WordsCount)))
+	}
+
+	return h.sendMessage(chatID, sb.String())
+}
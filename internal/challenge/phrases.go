@@ -0,0 +1,54 @@
+package challenge
+
+import (
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+// phrasesByLevel фразы для челленджа по уровням. Фраза дня выбирается по номеру дня,
+// поэтому в течение дня все пользователи одного уровня тренируют одну и ту же фразу.
+var phrasesByLevel = map[string][]string{
+	models.LevelBeginner: {
+		"Nice to meet you.",
+		"What time is it now?",
+		"I would like a cup of tea, please.",
+		"Where is the nearest bus stop?",
+		"My brother works in a big hospital.",
+		"Can you help me with my homework?",
+		"We are going to the beach this weekend.",
+	},
+	models.LevelIntermediate: {
+		"I've been thinking about changing my job.",
+		"Could you tell me how to get to the station?",
+		"It's worth visiting the old town at least once.",
+		"She would rather stay at home than go out tonight.",
+		"The weather forecast says it will rain tomorrow.",
+		"I'm looking forward to hearing from you soon.",
+		"We ran out of milk, so I went to the store.",
+	},
+	models.LevelAdvanced: {
+		"Had I known about the delay, I would have taken an earlier train.",
+		"The thoroughly researched article challenged several widely held beliefs.",
+		"It's not the strongest who survive, but those most adaptable to change.",
+		"Whether we like it or not, technology shapes the way we think.",
+		"The negotiations reached a deadlock after three exhausting rounds.",
+		"Her remarkable persistence eventually earned her the recognition she deserved.",
+		"Throughout the century, the city's architecture evolved considerably.",
+	},
+}
+
+// PhraseOfDay возвращает фразу дня для уровня
+func PhraseOfDay(level string, day time.Time) string {
+	phrases, ok := phrasesByLevel[level]
+	if !ok {
+		phrases = phrasesByLevel[models.LevelBeginner]
+	}
+	return phrases[dayNumber(day)%len(phrases)]
+}
+
+// dayNumber номер календарного дня с начала эпохи
+func dayNumber(day time.Time) int {
+	y, m, d := day.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,149 @@
+package challenge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// CallbackData данные inline-кнопки, открывающей фразу дня (меню и напоминания)
+const CallbackData = "phrase_of_day"
+
+// streakLookbackDays за сколько последних дней загружаются результаты для подсчета серии
+const streakLookbackDays = 60
+
+// Config настройки челленджа «Фраза дня»
+type Config struct {
+	MinScore float64 // Оценка, начиная с которой произношение засчитывается
+	XP       int     // Награда за первое успешное произношение за день
+}
+
+// DefaultConfig настройки челленджа по умолчанию
+var DefaultConfig = Config{
+	MinScore: 0.8,
+	XP:       20,
+}
+
+// AttemptResult итог попытки произнести фразу дня
+type AttemptResult struct {
+	Score     float64
+	Passed    bool // Оценка не ниже порога
+	FirstPass bool // Первое успешное произношение за день — начисляется XP
+	XP        int
+	Stats     models.PhraseChallengeStats
+}
+
+// Service сервис челленджа «Фраза дня»
+type Service struct {
+	repo   store.PhraseChallengeRepository
+	logger *zap.Logger
+	cfg    Config
+	loc    *time.Location
+	now    func() time.Time
+}
+
+// NewService создает сервис челленджа
+func NewService(repo store.PhraseChallengeRepository, cfg Config, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		cfg:    cfg,
+		loc:    time.UTC,
+		now:    time.Now,
+	}
+}
+
+// SetLocation задает часовой пояс, в полночь которого начинается новый день челленджа
+func (s *Service) SetLocation(loc *time.Location) {
+	s.loc = loc
+}
+
+// MinScore возвращает порог успешного произношения
+func (s *Service) MinScore() float64 {
+	return s.cfg.MinScore
+}
+
+// today возвращает текущий день челленджа
+func (s *Service) today() time.Time {
+	y, m, d := s.now().In(s.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// TodayPhrase возвращает фразу дня для уровня пользователя
+func (s *Service) TodayPhrase(level string) string {
+	return PhraseOfDay(level, s.today())
+}
+
+// RecordAttempt сохраняет оценку попытки и начисляет награду за первое успешное произношение за день
+func (s *Service) RecordAttempt(ctx context.Context, userID int64, score float64) (*AttemptResult, error) {
+	today := s.today()
+	previousBest, err := s.repo.RecordAttempt(ctx, userID, today, score)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AttemptResult{
+		Score:  score,
+		Passed: score >= s.cfg.MinScore,
+	}
+	if result.Passed && previousBest < s.cfg.MinScore {
+		result.FirstPass = true
+		result.XP = s.cfg.XP
+	}
+
+	stats, err := s.Stats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.Stats = *stats
+
+	s.logger.Info("попытка челленджа «Фраза дня»",
+		zap.Int64("user_id", userID),
+		zap.Float64("score", score),
+		zap.Bool("first_pass", result.FirstPass),
+		zap.Int("streak", stats.Streak))
+
+	return result, nil
+}
+
+// Stats возвращает участие пользователя сегодня и текущую серию успешных дней
+func (s *Service) Stats(ctx context.Context, userID int64) (*models.PhraseChallengeStats, error) {
+	days, err := s.repo.GetRecentDays(ctx, userID, streakLookbackDays)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики челленджа: %w", err)
+	}
+	return buildStats(days, s.today(), s.cfg.MinScore), nil
+}
+
+// buildStats считает сводку по дням участия (самый свежий день первым).
+// Серия — число подряд идущих успешных дней, заканчивающихся сегодня или вчера:
+// пока сегодня фраза не произнесена успешно, серия еще не прервана.
+func buildStats(days []models.PhraseChallengeDay, today time.Time, minScore float64) *models.PhraseChallengeStats {
+	stats := &models.PhraseChallengeStats{DaysPlayed: len(days)}
+
+	todayNum := dayNumber(today)
+	next := todayNum - 1
+	for _, day := range days {
+		n := dayNumber(day.Date)
+		if n == todayNum {
+			stats.PlayedToday = true
+			stats.TodayBest = day.BestScore
+			if day.BestScore >= minScore {
+				stats.Streak++
+			}
+			continue
+		}
+		if n != next || day.BestScore < minScore {
+			break
+		}
+		stats.Streak++
+		next--
+	}
+
+	return stats
+}
@@ -0,0 +1,107 @@
+package challenge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// fakeRepo хранит результаты по дням в памяти
+type fakeRepo struct {
+	best map[time.Time]float64
+}
+
+func (r *fakeRepo) RecordAttempt(ctx context.Context, userID int64, day time.Time, score float64) (float64, error) {
+	prev := r.best[day]
+	r.best[day] = max(prev, score)
+	return prev, nil
+}
+
+func (r *fakeRepo) GetRecentDays(ctx context.Context, userID int64, limit int) ([]models.PhraseChallengeDay, error) {
+	var days []models.PhraseChallengeDay
+	for d := 0; d < limit; d++ {
+		day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -d)
+		if score, ok := r.best[day]; ok {
+			days = append(days, models.PhraseChallengeDay{Date: day, BestScore: score, Attempts: 1})
+		}
+	}
+	return days, nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuildStatsStreak(t *testing.T) {
+	tests := []struct {
+		name   string
+		days   []models.PhraseChallengeDay
+		streak int
+		played bool
+	}{
+		{"нет участия", nil, 0, false},
+		{"сегодня и вчера успешно", []models.PhraseChallengeDay{{Date: day(10), BestScore: 0.9}, {Date: day(9), BestScore: 0.85}}, 2, true},
+		{"сегодня еще не играл", []models.PhraseChallengeDay{{Date: day(9), BestScore: 0.9}, {Date: day(8), BestScore: 0.9}}, 2, false},
+		{"сегодня неудача не прерывает серию", []models.PhraseChallengeDay{{Date: day(10), BestScore: 0.4}, {Date: day(9), BestScore: 0.9}}, 1, true},
+		{"пропущенный день прерывает серию", []models.PhraseChallengeDay{{Date: day(10), BestScore: 0.9}, {Date: day(8), BestScore: 0.9}}, 1, true},
+		{"неудачный день прерывает серию", []models.PhraseChallengeDay{{Date: day(9), BestScore: 0.5}, {Date: day(8), BestScore: 0.9}}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := buildStats(tt.days, day(10), 0.8)
+			if stats.Streak != tt.streak {
+				t.Errorf("ожидалась серия %d, получено %d", tt.streak, stats.Streak)
+			}
+			if stats.PlayedToday != tt.played {
+				t.Errorf("ожидалось участие сегодня %v, получено %v", tt.played, stats.PlayedToday)
+			}
+		})
+	}
+}
+
+func TestRecordAttemptAwardsXPOncePerDay(t *testing.T) {
+	repo := &fakeRepo{best: map[time.Time]float64{day(9): 0.9}}
+	s := NewService(repo, Config{MinScore: 0.8, XP: 20}, zap.NewNop())
+	s.now = func() time.Time { return time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	low, err := s.RecordAttempt(ctx, 1, 0.5)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if low.Passed || low.XP != 0 {
+		t.Errorf("ожидалась незасчитанная попытка без XP, получено passed=%v xp=%d", low.Passed, low.XP)
+	}
+
+	good, _ := s.RecordAttempt(ctx, 1, 0.9)
+	if !good.FirstPass || good.XP != 20 {
+		t.Errorf("ожидалось 20 XP за первое успешное произношение, получено first=%v xp=%d", good.FirstPass, good.XP)
+	}
+	if good.Stats.Streak != 2 {
+		t.Errorf("ожидалась серия 2 дня, получено %d", good.Stats.Streak)
+	}
+
+	again, _ := s.RecordAttempt(ctx, 1, 1.0)
+	if again.FirstPass || again.XP != 0 {
+		t.Errorf("ожидалось отсутствие повторной награды, получено xp=%d", again.XP)
+	}
+}
+
+func TestPhraseOfDayStableWithinDay(t *testing.T) {
+	morning := time.Date(2024, 5, 10, 1, 0, 0, 0, time.UTC)
+	evening := time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC)
+	if PhraseOfDay(models.LevelIntermediate, morning) != PhraseOfDay(models.LevelIntermediate, evening) {
+		t.Error("ожидалась одна фраза в течение дня")
+	}
+	if PhraseOfDay(models.LevelBeginner, morning) == PhraseOfDay(models.LevelBeginner, morning.AddDate(0, 0, 1)) {
+		t.Error("ожидалась новая фраза на следующий день")
+	}
+	if PhraseOfDay("unknown", morning) == "" {
+		t.Error("ожидалась фраза для неизвестного уровня")
+	}
+}
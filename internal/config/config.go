@@ -4,11 +4,19 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"lingua-ai/pkg/models"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
 
+// MaxFlashcardIntervals сколько интервалов повторения допускает FLASHCARD_INTERVALS_DAYS:
+// число интервалов — максимальная сложность карточки, а chk_difficulty в БД разрешает 0..5
+const MaxFlashcardIntervals = 5
+
 // Config содержит все конфигурационные параметры приложения
 type Config struct {
 	Telegram TelegramConfig
@@ -18,22 +26,32 @@ type Config struct {
 	App      AppConfig
 	YooKassa YooKassaConfig
 	TTS      TTSConfig
+	Backup   BackupConfig
+	Log      LogConfig
 }
 
 // TelegramConfig содержит настройки Telegram бота
 type TelegramConfig struct {
-	BotToken   string
-	WebhookURL string
+	BotToken      string
+	WebhookURL    string
+	GroupsEnabled bool // отвечать в группах на упоминания и ответы боту
 }
 
 // AIConfig содержит настройки AI провайдеров
 type AIConfig struct {
-	Provider    string
-	Model       string
-	MaxTokens   int
-	Temperature float64
-	DeepSeek    DeepSeekConfig
-	OpenRouter  OpenRouterConfig
+	Provider     string
+	Model        string
+	MaxTokens    int
+	Temperature  float64
+	DeepSeek     DeepSeekConfig
+	OpenRouter   OpenRouterConfig
+	DebugPrompts bool // логировать собранные промпты (запрещено в production)
+
+	BreakerFailures    int // После скольких ошибок подряд бот переходит в режим без AI
+	BreakerCooldownSec int // Через сколько секунд пробовать обратиться к AI снова
+
+	MaxRetries       int // Сколько раз повторять запрос после сетевой ошибки или ответа 429/5xx
+	RetryBaseDelayMs int // Пауза перед первым повтором в миллисекундах, дальше удваивается
 }
 
 type DeepSeekConfig struct {
@@ -49,7 +67,11 @@ type OpenRouterConfig struct {
 
 // WhisperConfig содержит настройки Whisper API
 type WhisperConfig struct {
-	APIURL string
+	APIURL                string
+	MaxDurationSec        int    // Максимальная длительность аудио для бесплатных пользователей
+	PremiumMaxDurationSec int    // Максимальная длительность аудио для премиум пользователей
+	LanguageCheck         string // Реакция на речь не на изучаемом языке: off, warn, skip
+	LanguageMinWords      int    // Минимум распознанных слов для проверки языка
 }
 
 type DatabaseConfig struct {
@@ -60,12 +82,104 @@ type DatabaseConfig struct {
 	Name          string
 	SSLMode       string
 	MigrationPath string
+	// UserCacheTTLSec время жизни кэша пользователей в секундах (0 — кэш отключен)
+	UserCacheTTLSec int
+}
+
+// BackupConfig содержит настройки периодической выгрузки ключевых таблиц
+type BackupConfig struct {
+	Enabled       bool
+	Destination   string // local или s3
+	Dir           string // Каталог для local
+	IntervalHours int
+	Retention     int // Сколько последних выгрузок хранить
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3Prefix      string
+	S3AccessKey   string
+	S3SecretKey   string
+}
+
+// LogConfig содержит настройки файлов логов и их ротации
+type LogConfig struct {
+	ToFiles    bool   // Писать логи в файлы; false — только stdout/stderr (контейнеры)
+	Dir        string // Каталог файлов логов
+	MaxSizeMB  int    // Размер файла, после которого он архивируется
+	MaxBackups int    // Сколько архивных файлов хранить (0 — без ограничения)
+	MaxAgeDays int    // Сколько дней хранить архивные файлы (0 — без ограничения)
 }
 
 type AppConfig struct {
-	Env      string
-	LogLevel string
-	Port     int
+	Env             string
+	LogLevel        string
+	Port            int
+	StreakGraceDays int      // Сколько пропущенных дней не сбрасывают study streak
+	DailyResetTZ    string   // Часовой пояс, в полночь которого сбрасывается дневной лимит сообщений
+	PremiumFeatures []string // Возможности, доступные только по премиуму
+	DialogMaxMsgs   int      // После скольких сообщений старая часть диалога сворачивается в краткое содержание
+	DialogKeepMsgs  int      // Сколько последних сообщений передается AI дословно
+	ChatHistoryMsgs int      // Сколько сообщений истории из БД передается AI, когда контекст диалога пуст
+	MaxStoredMsgs   int      // Сколько сообщений пользователя хранится в БД, старые удаляются при записи
+
+	ReferralMaxRewards    int // Сколько месяцев премиума можно получить за рефералов за все время
+	ReferralMinMessages   int // Сколько сообщений должен отправить приглашенный, чтобы реферал засчитался
+	ReferralMinActiveDays int // В скольких разных днях должен писать приглашенный
+
+	FlashcardAutoSeed         bool // Пополнять исчерпанный пул карточек уровня с помощью AI
+	FlashcardAutoSeedBatch    int  // Сколько карточек генерировать за одно пополнение
+	FlashcardAutoSeedInterval int  // Минимальный интервал между пополнениями одного уровня, в минутах
+	FlashcardExampleRefreshes int  // Сколько новых примеров можно запросить у AI за сессию карточек (0 — кнопка скрыта)
+	FlashcardSpacedIntro      bool // Вводить новые слова от частых к редким, чередуя категории
+	FlashcardReportThreshold  int  // После скольких жалоб карточка снимается с выдачи до проверки (0 — не снимается)
+	FlashcardRelearnGap       int  // Через сколько карточек повторить карточку с ошибкой в той же сессии (0 — не повторять)
+
+	FlashcardIntervalDays     []int   // Интервалы повторения в днях после верного ответа для сложности 1, 2, ...
+	FlashcardWrongIntervalMin int     // Через сколько минут повторить карточку после ошибки
+	FlashcardIntervalJitter   float64 // Случайное отклонение интервала в долях (0.2 — ±20%)
+
+	DefaultLevel      string // Уровень, с которым создаются новые пользователи
+	FirstRunLevelPick bool   // Предлагать новым пользователям выбрать уровень перед приветствием
+	FirstRunAllowSkip bool   // Разрешить пропустить выбор уровня и тур при первом запуске
+	MessageLocale     string // Локаль чисел и дат в сообщениях бота: ru или en
+
+	PhraseChallenge      bool    // Включить ежедневный челлендж «Фраза дня»
+	PhraseChallengeScore float64 // Совпадение (0..1), с которого произношение фразы засчитывается
+	PhraseChallengeXP    int     // XP за первое успешное произношение фразы за день
+
+	ActiveUsersLimit int // Сколько уникальных пользователей помнят метрики активных за сутки и месяц
+
+	XPMinWords int // Минимум слов в сообщении для полного XP на уровне beginner (0 — без ограничения)
+
+	UnsupportedLanguageReply     string // Ответ на сообщение не на русском и не на изучаемом языке (пустой — стандартный)
+	UnsupportedLanguageTranslate bool   // Предлагать перевести такое сообщение на изучаемый язык
+
+	LearningLanguages []string // Языки, которые можно выбрать командой /language (пустой — только английский)
+
+	StreakWarnings     bool // Предупреждать вечером, что серия занятий прервется в полночь
+	StreakWarningHours int  // За сколько часов до полуночи в поясе пользователя отправлять предупреждение
+
+	DailyReminders    bool // Ежедневно напоминать о занятиях тем, кто сегодня еще не занимался
+	DailyReminderHour int  // Час отправки ежедневного напоминания в поясе пользователя (0–23)
+	DailyReminderRate int  // Сколько ежедневных напоминаний отправлять в секунду
+
+	WordOfDay     bool // Ежедневно присылать слово дня тем, у кого включены напоминания
+	WordOfDayHour int  // Час отправки слова дня в поясе пользователя (0–23)
+
+	WeeklyTargetReminders bool // Напоминать в последний день недели о невыполненной недельной цели
+
+	PremiumExpiryReminders    bool // Напоминать о продлении премиума и сообщать о его окончании
+	PremiumExpiryReminderDays int  // За сколько дней до окончания премиума напоминать о продлении
+
+	DialogPersist     bool // Сохранять контекст диалога в БД, чтобы разговор пережил перезапуск
+	DialogPersistMsgs int  // Сколько последних сообщений диалога хранится в БД
+
+	QuickReplies     bool     // Предлагать варианты ответа кнопками после вопроса бота
+	QuickReplyLevels []string // Уровни, на которых предлагаются быстрые ответы
+
+	RateLimitWarningCooldownSec int // Не чаще одного предупреждения о превышении лимита запросов за этот интервал, в секундах
+
+	AdminToken string // Токен для служебных эндпоинтов /admin (пустой — эндпоинты закрыты)
 }
 
 // YooKassaConfig содержит настройки ЮKassa
@@ -77,8 +191,9 @@ type YooKassaConfig struct {
 
 // TTSConfig содержит настройки Text-to-Speech
 type TTSConfig struct {
-	Enabled  bool   `json:"enabled"`
-	BaseURL  string `json:"base_url"`
+	Enabled      bool     `json:"enabled"`
+	BaseURL      string   `json:"base_url"`
+	FallbackURLs []string `json:"fallback_urls"` // Запасные TTS сервисы с тем же API, по порядку
 }
 
 // Load загружает конфигурацию из переменных окружения и .env
@@ -90,6 +205,7 @@ func Load() (*Config, error) {
 	// Telegram
 	cfg.Telegram.BotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
 	cfg.Telegram.WebhookURL = os.Getenv("TELEGRAM_WEBHOOK_URL")
+	cfg.Telegram.GroupsEnabled = getEnvBoolDefault("TELEGRAM_GROUPS_ENABLED", false)
 
 	// AI
 	cfg.AI.Provider = getEnvDefault("AI_PROVIDER", "deepseek")
@@ -101,9 +217,18 @@ func Load() (*Config, error) {
 	cfg.AI.OpenRouter.APIKey = os.Getenv("OPENROUTER_API_KEY")
 	cfg.AI.OpenRouter.SiteURL = getEnvDefault("OPENROUTER_SITE_URL", "https://lingua-ai.ru")
 	cfg.AI.OpenRouter.SiteName = getEnvDefault("OPENROUTER_SITE_NAME", "Lingua AI")
+	cfg.AI.DebugPrompts = getEnvBoolDefault("AI_DEBUG_PROMPTS", false)
+	cfg.AI.BreakerFailures = getEnvIntDefault("AI_BREAKER_FAILURES", 3)
+	cfg.AI.BreakerCooldownSec = getEnvIntDefault("AI_BREAKER_COOLDOWN_SEC", 60)
+	cfg.AI.MaxRetries = getEnvIntDefault("AI_MAX_RETRIES", 3)
+	cfg.AI.RetryBaseDelayMs = getEnvIntDefault("AI_RETRY_BASE_DELAY_MS", 500)
 
 	// Whisper
 	cfg.Whisper.APIURL = getEnvDefault("WHISPER_API_URL", "http://whisper:8080")
+	cfg.Whisper.MaxDurationSec = getEnvIntDefault("WHISPER_MAX_DURATION_SEC", 180)
+	cfg.Whisper.PremiumMaxDurationSec = getEnvIntDefault("WHISPER_PREMIUM_MAX_DURATION_SEC", 600)
+	cfg.Whisper.LanguageCheck = getEnvDefault("WHISPER_LANGUAGE_CHECK", "warn")
+	cfg.Whisper.LanguageMinWords = getEnvIntDefault("WHISPER_LANGUAGE_MIN_WORDS", 3)
 
 	// Database
 	cfg.Database.Host = getEnvDefault("DB_HOST", "localhost")
@@ -113,6 +238,7 @@ func Load() (*Config, error) {
 	cfg.Database.Name = os.Getenv("DB_NAME")
 	cfg.Database.SSLMode = getEnvDefault("DB_SSL_MODE", "disable")
 	cfg.Database.MigrationPath = getEnvDefault("MIGRATION_PATH", "scripts/migrations")
+	cfg.Database.UserCacheTTLSec = getEnvIntDefault("USER_CACHE_TTL_SEC", 5)
 
 	// YooKassa
 	cfg.YooKassa.ShopID = getEnvDefault("YUKASSA_SHOP_ID", "test_shop_id")
@@ -122,11 +248,80 @@ func Load() (*Config, error) {
 	// TTS
 	cfg.TTS.Enabled = getEnvBoolDefault("TTS_ENABLED", false)
 	cfg.TTS.BaseURL = getEnvDefault("TTS_BASE_URL", "http://alltalk:7851")
+	cfg.TTS.FallbackURLs = getEnvListDefault("TTS_FALLBACK_URLS", "none")
+
+	// Backup
+	cfg.Backup.Enabled = getEnvBoolDefault("BACKUP_ENABLED", false)
+	cfg.Backup.Destination = getEnvDefault("BACKUP_DESTINATION", "local")
+	cfg.Backup.Dir = getEnvDefault("BACKUP_DIR", "backups")
+	cfg.Backup.IntervalHours = getEnvIntDefault("BACKUP_INTERVAL_HOURS", 24)
+	cfg.Backup.Retention = getEnvIntDefault("BACKUP_RETENTION", 7)
+	cfg.Backup.S3Endpoint = os.Getenv("BACKUP_S3_ENDPOINT")
+	cfg.Backup.S3Region = getEnvDefault("BACKUP_S3_REGION", "us-east-1")
+	cfg.Backup.S3Bucket = os.Getenv("BACKUP_S3_BUCKET")
+	cfg.Backup.S3Prefix = os.Getenv("BACKUP_S3_PREFIX")
+	cfg.Backup.S3AccessKey = os.Getenv("BACKUP_S3_ACCESS_KEY")
+	cfg.Backup.S3SecretKey = os.Getenv("BACKUP_S3_SECRET_KEY")
+
+	// Log
+	cfg.Log.ToFiles = getEnvBoolDefault("LOG_TO_FILES", true)
+	cfg.Log.Dir = getEnvDefault("LOG_DIR", "logs")
+	cfg.Log.MaxSizeMB = getEnvIntDefault("LOG_MAX_SIZE_MB", 50)
+	cfg.Log.MaxBackups = getEnvIntDefault("LOG_MAX_BACKUPS", 5)
+	cfg.Log.MaxAgeDays = getEnvIntDefault("LOG_MAX_AGE_DAYS", 14)
 
 	// App
 	cfg.App.Env = getEnvDefault("APP_ENV", "development")
 	cfg.App.LogLevel = getEnvDefault("LOG_LEVEL", "info")
 	cfg.App.Port = getEnvIntDefault("APP_PORT", 8080)
+	cfg.App.StreakGraceDays = getEnvIntDefault("STREAK_GRACE_DAYS", 1)
+	cfg.App.DailyResetTZ = getEnvDefault("DAILY_RESET_TZ", "UTC")
+	cfg.App.DialogMaxMsgs = getEnvIntDefault("DIALOG_MAX_MESSAGES", 20)
+	cfg.App.DialogKeepMsgs = getEnvIntDefault("DIALOG_KEEP_RECENT", 8)
+	cfg.App.ChatHistoryMsgs = getEnvIntDefault("CHAT_HISTORY_LIMIT", 10)
+	cfg.App.MaxStoredMsgs = getEnvIntDefault("MAX_STORED_MESSAGES", 200)
+	cfg.App.ReferralMaxRewards = getEnvIntDefault("REFERRAL_MAX_REWARDS", 3)
+	cfg.App.ReferralMinMessages = getEnvIntDefault("REFERRAL_MIN_MESSAGES", 5)
+	cfg.App.ReferralMinActiveDays = getEnvIntDefault("REFERRAL_MIN_ACTIVE_DAYS", 2)
+	cfg.App.FlashcardAutoSeed = getEnvBoolDefault("FLASHCARD_AUTOSEED", false)
+	cfg.App.FlashcardAutoSeedBatch = getEnvIntDefault("FLASHCARD_AUTOSEED_BATCH", 10)
+	cfg.App.FlashcardAutoSeedInterval = getEnvIntDefault("FLASHCARD_AUTOSEED_INTERVAL_MIN", 60)
+	cfg.App.FlashcardExampleRefreshes = getEnvIntDefault("FLASHCARD_EXAMPLE_REFRESHES", 3)
+	cfg.App.FlashcardSpacedIntro = getEnvBoolDefault("FLASHCARD_SPACED_INTRO", false)
+	cfg.App.FlashcardReportThreshold = getEnvIntDefault("FLASHCARD_REPORT_THRESHOLD", 3)
+	cfg.App.FlashcardRelearnGap = getEnvIntDefault("FLASHCARD_RELEARN_GAP", 0)
+	cfg.App.FlashcardIntervalDays = getEnvIntListDefault("FLASHCARD_INTERVALS_DAYS", "1,3,7,14,30")
+	cfg.App.FlashcardWrongIntervalMin = getEnvIntDefault("FLASHCARD_WRONG_INTERVAL_MIN", 10)
+	cfg.App.FlashcardIntervalJitter = getEnvFloatDefault("FLASHCARD_INTERVAL_JITTER", 0.2)
+	cfg.App.DefaultLevel = getEnvDefault("DEFAULT_USER_LEVEL", models.LevelBeginner)
+	cfg.App.FirstRunLevelPick = getEnvBoolDefault("FIRST_RUN_LEVEL_PICKER", false)
+	cfg.App.FirstRunAllowSkip = getEnvBoolDefault("FIRST_RUN_ALLOW_SKIP", true)
+	cfg.App.MessageLocale = getEnvDefault("MESSAGE_LOCALE", "ru")
+	cfg.App.PhraseChallenge = getEnvBoolDefault("PHRASE_CHALLENGE_ENABLED", true)
+	cfg.App.PhraseChallengeScore = getEnvFloatDefault("PHRASE_CHALLENGE_MIN_SCORE", 0.8)
+	cfg.App.PhraseChallengeXP = getEnvIntDefault("PHRASE_CHALLENGE_XP", 20)
+	cfg.App.ActiveUsersLimit = getEnvIntDefault("ACTIVE_USERS_METRIC_LIMIT", 100000)
+	cfg.App.XPMinWords = getEnvIntDefault("XP_MIN_WORDS", 3)
+	cfg.App.UnsupportedLanguageReply = os.Getenv("UNSUPPORTED_LANGUAGE_REPLY")
+	cfg.App.UnsupportedLanguageTranslate = getEnvBoolDefault("UNSUPPORTED_LANGUAGE_TRANSLATE", true)
+	cfg.App.LearningLanguages = getEnvListDefault("LEARNING_LANGUAGES", models.DefaultLearningLanguage)
+	cfg.App.StreakWarnings = getEnvBoolDefault("STREAK_WARNING_ENABLED", true)
+	cfg.App.StreakWarningHours = getEnvIntDefault("STREAK_WARNING_HOURS", 3)
+	cfg.App.DailyReminders = getEnvBoolDefault("DAILY_REMINDER_ENABLED", true)
+	cfg.App.DailyReminderHour = getEnvIntDefault("DAILY_REMINDER_HOUR", 10)
+	cfg.App.DailyReminderRate = getEnvIntDefault("DAILY_REMINDER_RATE", 20)
+	cfg.App.WordOfDay = getEnvBoolDefault("WORD_OF_DAY_ENABLED", true)
+	cfg.App.WordOfDayHour = getEnvIntDefault("WORD_OF_DAY_HOUR", 9)
+	cfg.App.WeeklyTargetReminders = getEnvBoolDefault("WEEKLY_TARGET_REMINDERS", true)
+	cfg.App.PremiumExpiryReminders = getEnvBoolDefault("PREMIUM_EXPIRY_REMINDERS", true)
+	cfg.App.PremiumExpiryReminderDays = getEnvIntDefault("PREMIUM_EXPIRY_REMINDER_DAYS", 3)
+	cfg.App.DialogPersist = getEnvBoolDefault("DIALOG_PERSIST", true)
+	cfg.App.DialogPersistMsgs = getEnvIntDefault("DIALOG_PERSIST_MESSAGES", 20)
+	cfg.App.QuickReplies = getEnvBoolDefault("QUICK_REPLIES_ENABLED", true)
+	cfg.App.QuickReplyLevels = getEnvListDefault("QUICK_REPLIES_LEVELS", "beginner")
+	cfg.App.RateLimitWarningCooldownSec = getEnvIntDefault("RATE_LIMIT_WARNING_COOLDOWN_SEC", 60)
+	cfg.App.AdminToken = os.Getenv("ADMIN_TOKEN")
+	cfg.App.PremiumFeatures = getEnvListDefault("PREMIUM_FEATURES", "essay_review,extra_test_attempts,long_audio,mistakes_review,transcribe_debug")
 
 	if err := validateConfig(cfg); err != nil {
 		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", err)
@@ -179,6 +374,36 @@ func getEnvBoolDefault(key string, def bool) bool {
 	return b
 }
 
+// getEnvListDefault читает список через запятую; значение "none" означает пустой список
+func getEnvListDefault(key, def string) []string {
+	v := getEnvDefault(key, def)
+	if strings.EqualFold(strings.TrimSpace(v), "none") {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvIntListDefault читает список чисел через запятую; нечисловые элементы становятся 0,
+// чтобы validateConfig сообщил о них, а не пропустил молча
+func getEnvIntListDefault(key, def string) []int {
+	var values []int
+	for _, item := range getEnvListDefault(key, def) {
+		value, err := strconv.Atoi(item)
+		if err != nil {
+			value = 0
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
 // validateConfig проверяет корректность конфигурации
 func validateConfig(config *Config) error {
 	if config.Telegram.BotToken == "" {
@@ -193,6 +418,113 @@ func validateConfig(config *Config) error {
 	if config.AI.Provider != "deepseek" && config.AI.Provider != "openrouter" {
 		return fmt.Errorf("поддерживаются только AI_PROVIDER: deepseek, openrouter")
 	}
+	if config.AI.DebugPrompts && config.App.IsProduction() {
+		return fmt.Errorf("AI_DEBUG_PROMPTS=true недопустим при APP_ENV=production")
+	}
+	if !models.IsValidLevel(config.App.DefaultLevel) {
+		return fmt.Errorf("некорректный DEFAULT_USER_LEVEL %q: допустимы beginner, intermediate, advanced", config.App.DefaultLevel)
+	}
+	if config.App.PhraseChallengeScore <= 0 || config.App.PhraseChallengeScore > 1 {
+		return fmt.Errorf("PHRASE_CHALLENGE_MIN_SCORE должен быть в диапазоне (0, 1]")
+	}
+	if config.App.ActiveUsersLimit <= 0 {
+		return fmt.Errorf("ACTIVE_USERS_METRIC_LIMIT должен быть больше 0")
+	}
+	if config.AI.BreakerFailures < 1 {
+		return fmt.Errorf("AI_BREAKER_FAILURES должен быть больше 0")
+	}
+	if config.AI.BreakerCooldownSec < 1 {
+		return fmt.Errorf("AI_BREAKER_COOLDOWN_SEC должен быть больше 0")
+	}
+	if config.AI.MaxRetries < 0 || config.AI.MaxRetries > 10 {
+		return fmt.Errorf("AI_MAX_RETRIES должен быть от 0 до 10")
+	}
+	if config.AI.RetryBaseDelayMs < 1 {
+		return fmt.Errorf("AI_RETRY_BASE_DELAY_MS должен быть больше 0")
+	}
+	if config.App.FlashcardReportThreshold < 0 {
+		return fmt.Errorf("FLASHCARD_REPORT_THRESHOLD не может быть отрицательным")
+	}
+	if config.App.FlashcardRelearnGap < 0 {
+		return fmt.Errorf("FLASHCARD_RELEARN_GAP не может быть отрицательным")
+	}
+	if len(config.App.FlashcardIntervalDays) > MaxFlashcardIntervals {
+		return fmt.Errorf("FLASHCARD_INTERVALS_DAYS может содержать не больше %d интервалов: сложность карточки в БД ограничена 0..%d",
+			MaxFlashcardIntervals, MaxFlashcardIntervals)
+	}
+	for i, days := range config.App.FlashcardIntervalDays {
+		if days <= 0 {
+			return fmt.Errorf("FLASHCARD_INTERVALS_DAYS должен содержать положительные числа дней")
+		}
+		if i > 0 && days < config.App.FlashcardIntervalDays[i-1] {
+			return fmt.Errorf("интервалы FLASHCARD_INTERVALS_DAYS не должны убывать")
+		}
+	}
+	if config.App.FlashcardWrongIntervalMin < 0 {
+		return fmt.Errorf("FLASHCARD_WRONG_INTERVAL_MIN не может быть отрицательным")
+	}
+	if config.App.FlashcardIntervalJitter < 0 || config.App.FlashcardIntervalJitter >= 1 {
+		return fmt.Errorf("FLASHCARD_INTERVAL_JITTER должен быть в диапазоне [0, 1)")
+	}
+	if config.Log.ToFiles && config.Log.MaxSizeMB < 1 {
+		return fmt.Errorf("LOG_MAX_SIZE_MB должен быть больше 0")
+	}
+	if config.Log.MaxBackups < 0 || config.Log.MaxAgeDays < 0 {
+		return fmt.Errorf("LOG_MAX_BACKUPS и LOG_MAX_AGE_DAYS не могут быть отрицательными")
+	}
+	if config.App.StreakWarningHours < 1 || config.App.StreakWarningHours > 23 {
+		return fmt.Errorf("STREAK_WARNING_HOURS должен быть от 1 до 23")
+	}
+	if config.App.DailyReminderHour < 0 || config.App.DailyReminderHour > 23 {
+		return fmt.Errorf("DAILY_REMINDER_HOUR должен быть от 0 до 23")
+	}
+	if config.App.DailyReminderRate < 1 || config.App.DailyReminderRate > 30 {
+		return fmt.Errorf("DAILY_REMINDER_RATE должен быть от 1 до 30: Telegram ограничивает рассылку 30 сообщениями в секунду")
+	}
+	if config.App.WordOfDayHour < 0 || config.App.WordOfDayHour > 23 {
+		return fmt.Errorf("WORD_OF_DAY_HOUR должен быть от 0 до 23")
+	}
+	if config.App.PremiumExpiryReminderDays < 0 || config.App.PremiumExpiryReminderDays > 30 {
+		return fmt.Errorf("PREMIUM_EXPIRY_REMINDER_DAYS должен быть от 0 до 30")
+	}
+	switch config.App.MessageLocale {
+	case "ru", "en":
+	default:
+		return fmt.Errorf("некорректный MESSAGE_LOCALE %q: допустимы ru, en", config.App.MessageLocale)
+	}
+	switch config.Whisper.LanguageCheck {
+	case "off", "warn", "skip":
+	default:
+		return fmt.Errorf("некорректный WHISPER_LANGUAGE_CHECK %q: допустимы off, warn, skip", config.Whisper.LanguageCheck)
+	}
+	if config.Whisper.LanguageMinWords < 0 {
+		return fmt.Errorf("WHISPER_LANGUAGE_MIN_WORDS не может быть отрицательным")
+	}
+	if config.App.ChatHistoryMsgs < 1 {
+		return fmt.Errorf("CHAT_HISTORY_LIMIT должен быть больше 0")
+	}
+	if config.App.MaxStoredMsgs < config.App.ChatHistoryMsgs {
+		return fmt.Errorf("MAX_STORED_MESSAGES не может быть меньше CHAT_HISTORY_LIMIT: иначе AI не получит нужную историю")
+	}
+	if config.App.DialogPersistMsgs < 1 {
+		return fmt.Errorf("DIALOG_PERSIST_MESSAGES должен быть больше 0")
+	}
+	for _, level := range config.App.QuickReplyLevels {
+		if !models.IsValidLevel(level) {
+			return fmt.Errorf("неверный уровень в QUICK_REPLIES_LEVELS: %s", level)
+		}
+	}
+	for _, lang := range config.App.LearningLanguages {
+		if !models.IsSupportedLearningLanguage(lang) {
+			return fmt.Errorf("неверный язык в LEARNING_LANGUAGES: %s (допустимы en, es, de, fr, it)", lang)
+		}
+	}
+	if config.App.RateLimitWarningCooldownSec < 0 {
+		return fmt.Errorf("RATE_LIMIT_WARNING_COOLDOWN_SEC не может быть отрицательным")
+	}
+	if config.App.XPMinWords < 0 {
+		return fmt.Errorf("XP_MIN_WORDS не может быть отрицательным")
+	}
 	if config.Database.Host == "" {
 		return fmt.Errorf("DB_HOST не установлен")
 	}
@@ -205,7 +537,64 @@ func validateConfig(config *Config) error {
 	if config.Database.Name == "" {
 		return fmt.Errorf("DB_NAME не установлен")
 	}
+	if _, err := time.LoadLocation(config.App.DailyResetTZ); err != nil {
+		return fmt.Errorf("некорректный DAILY_RESET_TZ %q: %w", config.App.DailyResetTZ, err)
+	}
+	if err := validateYooKassaConfig(config); err != nil {
+		return err
+	}
+	if err := validateBackupConfig(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateBackupConfig проверяет настройки выгрузки, если она включена
+func validateBackupConfig(config *Config) error {
+	if !config.Backup.Enabled {
+		return nil
+	}
+	if config.Backup.IntervalHours <= 0 {
+		return fmt.Errorf("BACKUP_INTERVAL_HOURS должен быть положительным")
+	}
+	if config.Backup.Retention <= 0 {
+		return fmt.Errorf("BACKUP_RETENTION должен быть положительным")
+	}
 
+	switch config.Backup.Destination {
+	case "local":
+		if config.Backup.Dir == "" {
+			return fmt.Errorf("BACKUP_DIR не установлен")
+		}
+	case "s3":
+		if config.Backup.S3Endpoint == "" || config.Backup.S3Bucket == "" {
+			return fmt.Errorf("BACKUP_S3_ENDPOINT и BACKUP_S3_BUCKET обязательны для BACKUP_DESTINATION=s3")
+		}
+		if config.Backup.S3AccessKey == "" || config.Backup.S3SecretKey == "" {
+			return fmt.Errorf("BACKUP_S3_ACCESS_KEY и BACKUP_S3_SECRET_KEY обязательны для BACKUP_DESTINATION=s3")
+		}
+	default:
+		return fmt.Errorf("поддерживаются только BACKUP_DESTINATION: local, s3")
+	}
+
+	return nil
+}
+
+// validateYooKassaConfig не дает запустить продакшн с тестовыми настройками ЮKassa
+func validateYooKassaConfig(config *Config) error {
+	if !config.App.IsProduction() {
+		return nil
+	}
+	if config.YooKassa.TestMode {
+		return fmt.Errorf("YUKASSA_TEST_MODE=true недопустим при APP_ENV=production")
+	}
+	if config.YooKassa.ShopID == "" || config.YooKassa.ShopID == "test_shop_id" {
+		return fmt.Errorf("YUKASSA_SHOP_ID не установлен для продакшн окружения")
+	}
+	if config.YooKassa.SecretKey == "" || config.YooKassa.SecretKey == "test_secret_key" {
+		return fmt.Errorf("YUKASSA_SECRET_KEY не установлен для продакшн окружения")
+	}
 	return nil
 }
 
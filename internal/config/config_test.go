@@ -147,6 +147,27 @@ func TestValidateConfig(t *testing.T) {
 			OpenRouter: OpenRouterConfig{
 				APIKey: "test_key",
 			},
+			BreakerFailures:    5,
+			BreakerCooldownSec: 60,
+			RetryBaseDelayMs:   500,
+		},
+		App: AppConfig{
+			DefaultLevel:         "beginner",
+			PhraseChallengeScore: 0.7,
+			ActiveUsersLimit:     1000,
+			StreakWarningHours:   4,
+			DailyReminderRate:    20,
+			ChatHistoryMsgs:      10,
+			MaxStoredMsgs:        10,
+			DialogPersistMsgs:    10,
+			MessageLocale:        "ru",
+		},
+		Whisper: WhisperConfig{
+			LanguageCheck: "warn",
+		},
+		Log: LogConfig{
+			ToFiles:   true,
+			MaxSizeMB: 50,
 		},
 		Database: DatabaseConfig{
 			Host:     "localhost",
@@ -160,4 +181,56 @@ func TestValidateConfig(t *testing.T) {
 	}
 	err = validateConfig(cfg)
 	assert.NoError(t, err)
+
+	// Хранимая история не может быть короче истории, которую получает AI
+	cfg.App.MaxStoredMsgs = cfg.App.ChatHistoryMsgs - 1
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "MAX_STORED_MESSAGES")
+
+	// Изучаемые языки — только поддерживаемые ботом
+	cfg.App.MaxStoredMsgs = 10
+	cfg.App.LearningLanguages = []string{"en", "es"}
+	assert.NoError(t, validateConfig(cfg))
+	cfg.App.LearningLanguages = []string{"en", "jp"}
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "LEARNING_LANGUAGES")
+
+	// Интервалы повторения карточек — положительные и неубывающие
+	cfg.App.LearningLanguages = nil
+	cfg.App.FlashcardIntervalDays = []int{1, 3, 7}
+	assert.NoError(t, validateConfig(cfg))
+	cfg.App.FlashcardIntervalDays = []int{1, 7, 3}
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "FLASHCARD_INTERVALS_DAYS")
+	cfg.App.FlashcardIntervalDays = []int{1, 0}
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "FLASHCARD_INTERVALS_DAYS")
+	cfg.App.FlashcardIntervalDays = []int{1, 3, 7, 14, 30, 60}
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "FLASHCARD_INTERVALS_DAYS")
+	cfg.App.FlashcardIntervalDays = []int{1, 3, 7, 14, 30}
+	assert.NoError(t, validateConfig(cfg))
+
+	// Проверка языка голосовых — только известные режимы
+	cfg.App.FlashcardIntervalDays = nil
+	cfg.Whisper.LanguageCheck = "skip"
+	assert.NoError(t, validateConfig(cfg))
+	cfg.Whisper.LanguageCheck = "block"
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "WHISPER_LANGUAGE_CHECK")
+
+	// Локаль сообщений — только поддерживаемые форматы
+	cfg.Whisper.LanguageCheck = "warn"
+	cfg.App.MessageLocale = "en"
+	assert.NoError(t, validateConfig(cfg))
+	cfg.App.MessageLocale = "de"
+	err = validateConfig(cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "MESSAGE_LOCALE")
 }
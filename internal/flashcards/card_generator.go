@@ -0,0 +1,166 @@
+package flashcards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"lingua-ai/internal/ai"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// Ограничения качества сгенерированных карточек
+const (
+	maxGeneratedWordLen        = 40
+	maxGeneratedTranslationLen = 100
+	maxGeneratedExampleLen     = 200
+	generatedCardCategory      = "generated"
+)
+
+// CardGenerator генерирует новые карточки для пополнения пула уровня
+type CardGenerator interface {
+	GenerateCards(ctx context.Context, level string, count int, exclude []string) ([]*models.Flashcard, error)
+}
+
+// AICardGenerator генерирует карточки через AI
+type AICardGenerator struct {
+	client ai.AIClient
+	logger *zap.Logger
+}
+
+// NewAICardGenerator создает генератор карточек на основе AI клиента
+func NewAICardGenerator(client ai.AIClient, logger *zap.Logger) *AICardGenerator {
+	return &AICardGenerator{
+		client: client,
+		logger: logger,
+	}
+}
+
+// generatedCard карточка в ответе AI
+type generatedCard struct {
+	Word        string `json:"word"`
+	Translation string `json:"translation"`
+	Example     string `json:"example"`
+}
+
+// GenerateCards запрашивает у AI count новых слов уровня level, исключая уже известные
+func (g *AICardGenerator) GenerateCards(ctx context.Context, level string, count int, exclude []string) ([]*models.Flashcard, error) {
+	prompt := fmt.Sprintf(`Составь %d новых английских слов для словарных карточек уровня %s.
+Не используй слова из списка: %s.
+Ответь только JSON-массивом без пояснений в формате:
+[{"word": "english word", "translation": "перевод на русский", "example": "Short English example sentence with the word."}]`,
+		count, level, strings.Join(exclude, ", "))
+
+	response, err := g.client.GenerateResponse(ctx, []ai.Message{
+		{Role: "system", Content: "Ты составляешь словарь для изучающих английский. Отвечай строго валидным JSON."},
+		{Role: "user", Content: prompt},
+	}, ai.GenerationOptions{Temperature: 0.8, MaxTokens: 1500})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации карточек: %w", err)
+	}
+
+	cards, rejected := parseGeneratedCards(response.Content, level, exclude)
+	if rejected > 0 {
+		g.logger.Warn("часть сгенерированных карточек отклонена проверкой качества",
+			zap.String("level", level),
+			zap.Int("accepted", len(cards)),
+			zap.Int("rejected", rejected))
+	}
+	return cards, nil
+}
+
+// GenerateExample запрашивает у AI новый пример употребления слова, отличный от уже известных
+func (g *AICardGenerator) GenerateExample(ctx context.Context, word, translation, level string, avoid []string) (string, error) {
+	prompt := fmt.Sprintf(`Придумай одно новое короткое английское предложение-пример со словом "%s" (%s) для ученика уровня %s.
+Предложение должно отличаться от этих:
+%s
+Ответь только самим предложением без кавычек и пояснений.`,
+		word, translation, level, strings.Join(avoid, "\n"))
+
+	response, err := g.client.GenerateResponse(ctx, []ai.Message{
+		{Role: "system", Content: "Ты составляешь примеры для словарных карточек изучающих английский."},
+		{Role: "user", Content: prompt},
+	}, ai.GenerationOptions{Temperature: 0.9, MaxTokens: 100})
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации примера: %w", err)
+	}
+
+	example := strings.Trim(strings.TrimSpace(response.Content), `"«»`)
+	if !validGeneratedExample(example, word) {
+		return "", fmt.Errorf("сгенерированный пример не прошел проверку качества: %q", example)
+	}
+	return example, nil
+}
+
+var (
+	englishWordRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z' \-]*[A-Za-z]$`)
+	cyrillicRegexp    = regexp.MustCompile(`\p{Cyrillic}`)
+)
+
+// parseGeneratedCards разбирает ответ AI и отбрасывает карточки, не прошедшие проверку качества.
+// Возвращает принятые карточки и число отклоненных.
+func parseGeneratedCards(content, level string, exclude []string) ([]*models.Flashcard, int) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end <= start {
+		return nil, 0
+	}
+
+	var raw []generatedCard
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, 0
+	}
+
+	seen := make(map[string]bool, len(exclude)+len(raw))
+	for _, word := range exclude {
+		seen[strings.ToLower(word)] = true
+	}
+
+	var cards []*models.Flashcard
+	rejected := 0
+	for _, card := range raw {
+		card.Word = strings.TrimSpace(card.Word)
+		card.Translation = strings.TrimSpace(card.Translation)
+		card.Example = strings.TrimSpace(card.Example)
+
+		key := strings.ToLower(card.Word)
+		if !validGeneratedCard(card) || seen[key] {
+			rejected++
+			continue
+		}
+		seen[key] = true
+
+		cards = append(cards, &models.Flashcard{
+			Word:        card.Word,
+			Translation: card.Translation,
+			Example:     card.Example,
+			Level:       level,
+			Category:    generatedCardCategory,
+		})
+	}
+	return cards, rejected
+}
+
+// validGeneratedCard проверяет, что слово английское, перевод русский,
+// а пример на английском и содержит само слово
+func validGeneratedCard(card generatedCard) bool {
+	if len(card.Word) == 0 || len(card.Word) > maxGeneratedWordLen || !englishWordRegexp.MatchString(card.Word) {
+		return false
+	}
+	if len(card.Translation) == 0 || len(card.Translation) > maxGeneratedTranslationLen || !cyrillicRegexp.MatchString(card.Translation) {
+		return false
+	}
+	if len(card.Example) == 0 || len(card.Example) > maxGeneratedExampleLen || cyrillicRegexp.MatchString(card.Example) {
+		return false
+	}
+	if !strings.Contains(strings.ToLower(card.Example), strings.ToLower(card.Word)) {
+		return false
+	}
+	// Пример должен быть предложением, а не одним словом
+	return strings.IndexFunc(card.Example, unicode.IsSpace) > 0
+}
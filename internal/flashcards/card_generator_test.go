@@ -0,0 +1,40 @@
+package flashcards
+
+import (
+	"testing"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestParseGeneratedCardsQualityGuard(t *testing.T) {
+	content := "Вот карточки:\n```json\n[" +
+		`{"word": "river", "translation": "река", "example": "The river is wide."},` +
+		`{"word": "река", "translation": "river", "example": "Река широкая."},` +
+		`{"word": "cloud", "translation": "cloud", "example": "A cloud in the sky."},` +
+		`{"word": "bridge", "translation": "мост", "example": "We crossed it."},` +
+		`{"word": "River", "translation": "река", "example": "A River again."},` +
+		`{"word": "apple", "translation": "яблоко", "example": "I eat an apple."},` +
+		`{"word": "take off", "translation": "взлетать", "example": "Planes take off here."}` +
+		"]\n```"
+
+	cards, rejected := parseGeneratedCards(content, models.LevelBeginner, []string{"Apple"})
+	if len(cards) != 2 {
+		t.Fatalf("ожидалось 2 карточки, получено %d", len(cards))
+	}
+	if cards[0].Word != "river" || cards[1].Word != "take off" {
+		t.Errorf("ожидались river и take off, получено %s и %s", cards[0].Word, cards[1].Word)
+	}
+	if rejected != 5 {
+		t.Errorf("ожидалось 5 отклоненных карточек, получено %d", rejected)
+	}
+	if cards[0].Level != models.LevelBeginner || cards[0].Category != generatedCardCategory {
+		t.Errorf("ожидались уровень и категория пула, получено %s/%s", cards[0].Level, cards[0].Category)
+	}
+}
+
+func TestParseGeneratedCardsInvalidJSON(t *testing.T) {
+	cards, _ := parseGeneratedCards("не могу помочь", models.LevelBeginner, nil)
+	if len(cards) != 0 {
+		t.Errorf("ожидалось отсутствие карточек, получено %d", len(cards))
+	}
+}
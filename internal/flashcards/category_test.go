@@ -0,0 +1,110 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// categoryRepo пул карточек с выборкой новых слов по категории и заданными повторениями
+type categoryRepo struct {
+	poolRepo
+	due []*models.UserFlashcard
+}
+
+func (r *categoryRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return r.due, nil
+}
+
+func (r *categoryRepo) GetNewCardsForUserByCategory(ctx context.Context, userID int64, level, category string, limit int) ([]*models.Flashcard, error) {
+	var cards []*models.Flashcard
+	for _, card := range r.pool {
+		if card.Level == level && card.Category == category && !r.assigned[card.ID] && len(cards) < limit {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func newCategoryRepo() *categoryRepo {
+	return &categoryRepo{poolRepo: poolRepo{
+		assigned: map[int64]bool{},
+		pool: []*models.Flashcard{
+			{ID: 1, Word: "meeting", Translation: "встреча", Level: models.LevelBeginner, Category: "business"},
+			{ID: 2, Word: "ticket", Translation: "билет", Level: models.LevelBeginner, Category: "travel"},
+			{ID: 3, Word: "luggage", Translation: "багаж", Level: models.LevelBeginner, Category: "travel"},
+			{ID: 4, Word: "bread", Translation: "хлеб", Level: models.LevelBeginner, Category: "food"},
+		},
+	}}
+}
+
+func sessionWords(session *models.FlashcardSession) []string {
+	words := make([]string, 0, len(session.CardsToReview))
+	for _, card := range session.CardsToReview {
+		words = append(words, card.Flashcard.Word)
+	}
+	return words
+}
+
+func TestStartCategorySessionPicksNewCardsFromCategory(t *testing.T) {
+	repo := newCategoryRepo()
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	session, err := s.StartCategorySession(context.Background(), 1, models.LevelBeginner, "travel")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	for _, card := range session.CardsToReview {
+		if card.Flashcard.Category != "travel" {
+			t.Errorf("в сессию по теме travel попало слово %q из %q", card.Flashcard.Word, card.Flashcard.Category)
+		}
+	}
+	if len(session.CardsToReview) != 2 {
+		t.Errorf("ожидались 2 слова темы, получено %v", sessionWords(session))
+	}
+}
+
+func TestStartCategorySessionFiltersDueCards(t *testing.T) {
+	repo := newCategoryRepo()
+	repo.due = []*models.UserFlashcard{
+		{ID: 10, FlashcardID: 1, Flashcard: repo.pool[0]},
+		{ID: 11, FlashcardID: 4, Flashcard: repo.pool[3]},
+	}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	session, err := s.StartCategorySession(context.Background(), 1, models.LevelBeginner, "food")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if words := sessionWords(session); len(words) != 1 || words[0] != "bread" {
+		t.Errorf("ожидалось только повторение bread, получено %v", words)
+	}
+
+	// Без темы повторяются все подошедшие карточки
+	session, err = s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(session.CardsToReview) != 2 {
+		t.Errorf("ожидались оба повторения, получено %v", sessionWords(session))
+	}
+}
+
+func TestStartCategorySessionExhaustedCategoryIsNotSeeded(t *testing.T) {
+	repo := newCategoryRepo()
+	generator := &fakeGenerator{words: []string{"doctor"}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPoolSeeding(generator, DefaultPoolSeedConfig)
+
+	_, err := s.StartCategorySession(context.Background(), 1, models.LevelBeginner, "health")
+	if !errors.Is(err, ErrCategoryExhausted) {
+		t.Fatalf("ожидалась ErrCategoryExhausted, получено %v", err)
+	}
+	if generator.calls != 0 {
+		t.Errorf("тематический пул не должен пополняться генератором, вызовов: %d", generator.calls)
+	}
+}
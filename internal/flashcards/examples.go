@@ -0,0 +1,155 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// maxCachedExamples после скольких сохраненных примеров слово больше не отправляется в AI,
+// а примеры показываются по кругу из кеша
+const maxCachedExamples = 5
+
+// ErrExampleLimitReached исчерпан лимит генерации примеров в текущей сессии
+var ErrExampleLimitReached = errors.New("лимит новых примеров в сессии исчерпан")
+
+// ExampleGenerator генерирует новый пример употребления слова
+type ExampleGenerator interface {
+	GenerateExample(ctx context.Context, word, translation, level string, avoid []string) (string, error)
+}
+
+// SetExampleGenerator включает кнопку «Новый пример» с лимитом AI-запросов на сессию
+func (s *Service) SetExampleGenerator(generator ExampleGenerator, refreshLimit int) {
+	s.exampleGen = generator
+	s.exampleRefreshLimit = refreshLimit
+}
+
+// ExamplesEnabled сообщает, доступна ли генерация новых примеров
+func (s *Service) ExamplesEnabled() bool {
+	return s.exampleGen != nil && s.exampleRefreshLimit > 0
+}
+
+// RefreshExample подбирает новый пример для текущей карточки сессии.
+// Пока у слова мало сохраненных примеров, новый пример генерирует AI (не чаще лимита
+// на сессию) и сохраняет его; дальше примеры берутся из кеша по кругу.
+func (s *Service) RefreshExample(ctx context.Context, userID int64) (string, error) {
+	// Запросы к БД и AI идут без блокировки сессии: работаем с ее копией,
+	// а новый пример записываем в сессию отдельно
+	session := s.GetCurrentSession(userID)
+	if session == nil || session.CurrentCard == nil || session.CurrentCard.Flashcard == nil {
+		return "", ErrNoCurrentCard
+	}
+	if !s.ExamplesEnabled() {
+		return "", fmt.Errorf("генерация примеров отключена")
+	}
+
+	card := session.CurrentCard.Flashcard
+	cached, err := s.flashcardRepo.GetFlashcardExamples(ctx, []int64{card.ID})
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения примеров карточки: %w", err)
+	}
+	examples := cached[card.ID]
+
+	if len(examples) >= maxCachedExamples || session.ExampleRefreshes >= s.exampleRefreshLimit {
+		if next, ok := nextCachedExample(examples, card.Example); ok {
+			s.setSessionExample(userID, card.ID, next, false)
+			return next, nil
+		}
+		return "", ErrExampleLimitReached
+	}
+
+	example, err := s.exampleGen.GenerateExample(ctx, card.Word, card.Translation, card.Level, append([]string{card.Example}, examples...))
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации примера: %w", err)
+	}
+	refreshes := s.setSessionExample(userID, card.ID, example, true)
+
+	if err := s.flashcardRepo.AddFlashcardExample(ctx, card.ID, example); err != nil {
+		// Пример все равно показываем, просто он не попадет в кеш
+		s.logger.Error("ошибка сохранения нового примера", zap.Error(err), zap.String("word", card.Word))
+	}
+
+	s.logger.Info("сгенерирован новый пример карточки",
+		zap.Int64("user_id", userID),
+		zap.String("word", card.Word),
+		zap.Int("session_refreshes", refreshes))
+
+	return example, nil
+}
+
+// setSessionExample показывает пример у всех карточек сессии с этим словом. generated —
+// пример сгенерирован AI и учитывается в лимите сессии. Возвращает число сгенерированных
+// за сессию примеров.
+func (s *Service) setSessionExample(userID, flashcardID int64, example string, generated bool) int {
+	active := s.lockSession(userID)
+	if active == nil {
+		return 0
+	}
+	defer active.mu.Unlock()
+
+	if generated {
+		active.session.ExampleRefreshes++
+	}
+	for i := range active.session.CardsToReview {
+		if card := active.session.CardsToReview[i].Flashcard; card != nil && card.ID == flashcardID {
+			card.Example = example
+		}
+	}
+	return active.session.ExampleRefreshes
+}
+
+// nextCachedExample возвращает следующий после текущего сохраненный пример
+func nextCachedExample(examples []string, current string) (string, bool) {
+	for i, example := range examples {
+		if example == current {
+			next := examples[(i+1)%len(examples)]
+			return next, next != current
+		}
+	}
+	if len(examples) == 0 {
+		return "", false
+	}
+	return examples[0], true
+}
+
+// applyAlternateExamples чередует пример каждой карточки сессии между исходным
+// и сохраненными, чтобы при повторениях слово встречалось в разных контекстах
+func (s *Service) applyAlternateExamples(ctx context.Context, cards []models.UserFlashcard) {
+	ids := make([]int64, 0, len(cards))
+	for _, card := range cards {
+		if card.Flashcard != nil {
+			ids = append(ids, card.Flashcard.ID)
+		}
+	}
+
+	examples, err := s.flashcardRepo.GetFlashcardExamples(ctx, ids)
+	if err != nil {
+		s.logger.Warn("не удалось загрузить альтернативные примеры", zap.Error(err))
+		return
+	}
+
+	for _, card := range cards {
+		if card.Flashcard == nil {
+			continue
+		}
+		alternates := examples[card.Flashcard.ID]
+		if len(alternates) == 0 {
+			continue
+		}
+		variants := append([]string{card.Flashcard.Example}, alternates...)
+		card.Flashcard.Example = variants[card.ReviewCount%len(variants)]
+	}
+}
+
+// validGeneratedExample проверяет, что пример на английском, содержит слово и не слишком длинный
+func validGeneratedExample(example, word string) bool {
+	if len(example) == 0 || len(example) > maxGeneratedExampleLen || cyrillicRegexp.MatchString(example) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(example), strings.ToLower(word))
+}
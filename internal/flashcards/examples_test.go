@@ -0,0 +1,135 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// examplesRepo хранит альтернативные примеры в памяти
+type examplesRepo struct {
+	store.FlashcardRepository
+	cards    []*models.UserFlashcard
+	examples map[int64][]string
+}
+
+func (r *examplesRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return r.cards, nil
+}
+
+func (r *examplesRepo) GetFlashcardExamples(ctx context.Context, flashcardIDs []int64) (map[int64][]string, error) {
+	result := make(map[int64][]string)
+	for _, id := range flashcardIDs {
+		result[id] = append([]string(nil), r.examples[id]...)
+	}
+	return result, nil
+}
+
+func (r *examplesRepo) AddFlashcardExample(ctx context.Context, flashcardID int64, example string) error {
+	r.examples[flashcardID] = append(r.examples[flashcardID], example)
+	return nil
+}
+
+// fakeExampleGenerator возвращает пронумерованные примеры
+type fakeExampleGenerator struct {
+	calls int
+	avoid []string
+}
+
+func (g *fakeExampleGenerator) GenerateExample(ctx context.Context, word, translation, level string, avoid []string) (string, error) {
+	g.calls++
+	g.avoid = avoid
+	return "New " + word + " example " + string(rune('0'+g.calls)), nil
+}
+
+func newExamplesService(t *testing.T, limit int, examples map[int64][]string) (*Service, *examplesRepo, *fakeExampleGenerator) {
+	t.Helper()
+	cards := newTestCards("apple")
+	cards[0].Flashcard.Example = "I eat an apple."
+	repo := &examplesRepo{cards: cards, examples: examples}
+	gen := &fakeExampleGenerator{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetExampleGenerator(gen, limit)
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return s, repo, gen
+}
+
+func TestRefreshExampleGeneratesAndCaches(t *testing.T) {
+	s, repo, gen := newExamplesService(t, 2, map[int64][]string{})
+	ctx := context.Background()
+
+	example, err := s.RefreshExample(ctx, 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if example != "New apple example 1" {
+		t.Errorf("ожидался сгенерированный пример, получено %q", example)
+	}
+	if len(repo.examples[1]) != 1 {
+		t.Errorf("ожидался 1 сохраненный пример, получено %d", len(repo.examples[1]))
+	}
+	if len(gen.avoid) != 1 || gen.avoid[0] != "I eat an apple." {
+		t.Errorf("ожидалось исключение исходного примера, получено %v", gen.avoid)
+	}
+	if got := s.GetCurrentSession(1).CurrentCard.Flashcard.Example; got != example {
+		t.Errorf("ожидалось, что карточка покажет новый пример, получено %q", got)
+	}
+}
+
+func TestRefreshExampleSessionLimit(t *testing.T) {
+	s, _, gen := newExamplesService(t, 1, map[int64][]string{})
+	ctx := context.Background()
+
+	if _, err := s.RefreshExample(ctx, 1); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	// Единственный сохраненный пример уже показан — новых вариантов нет
+	if _, err := s.RefreshExample(ctx, 1); !errors.Is(err, ErrExampleLimitReached) {
+		t.Errorf("ожидалась ошибка лимита, получено %v", err)
+	}
+	if gen.calls != 1 {
+		t.Errorf("ожидался 1 вызов AI, получено %d", gen.calls)
+	}
+}
+
+func TestRefreshExampleRotatesFullCacheWithoutAI(t *testing.T) {
+	cached := []string{"Apple one.", "Apple two.", "Apple three.", "Apple four.", "Apple five."}
+	s, _, gen := newExamplesService(t, 3, map[int64][]string{1: cached})
+	ctx := context.Background()
+
+	first, err := s.RefreshExample(ctx, 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	second, _ := s.RefreshExample(ctx, 1)
+	if first == second {
+		t.Errorf("ожидались разные примеры из кеша, получено %q дважды", first)
+	}
+	if gen.calls != 0 {
+		t.Errorf("ожидалось отсутствие вызовов AI при заполненном кеше, получено %d", gen.calls)
+	}
+}
+
+func TestSessionAlternatesExamplesAcrossReviews(t *testing.T) {
+	cards := newTestCards("apple")
+	cards[0].Flashcard.Example = "I eat an apple."
+	cards[0].ReviewCount = 1
+	repo := &examplesRepo{cards: cards, examples: map[int64][]string{1: {"An apple a day."}}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetExampleGenerator(&fakeExampleGenerator{}, 3)
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got := session.CurrentCard.Flashcard.Example; got != "An apple a day." {
+		t.Errorf("ожидался альтернативный пример на втором повторении, получено %q", got)
+	}
+}
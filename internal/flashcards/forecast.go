@@ -0,0 +1,14 @@
+package flashcards
+
+import (
+	"context"
+
+	"lingua-ai/pkg/models"
+)
+
+// ReviewForecast возвращает, сколько карточек подойдет к повторению сегодня, завтра и за неделю.
+// Дни считаются от полуночи часового пояса сервиса.
+func (s *Service) ReviewForecast(ctx context.Context, userID int64) (*models.ReviewForecast, error) {
+	tomorrow := s.dayStart().AddDate(0, 0, 1)
+	return s.flashcardRepo.GetReviewForecast(ctx, userID, tomorrow)
+}
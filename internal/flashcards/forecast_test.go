@@ -0,0 +1,45 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// forecastRepo запоминает границу завтрашнего дня, с которой запрошен прогноз
+type forecastRepo struct {
+	store.FlashcardRepository
+	tomorrow time.Time
+}
+
+func (r *forecastRepo) GetReviewForecast(ctx context.Context, userID int64, tomorrow time.Time) (*models.ReviewForecast, error) {
+	r.tomorrow = tomorrow
+	return &models.ReviewForecast{Today: 3, Tomorrow: 2, Week: 9}, nil
+}
+
+func TestReviewForecastStartsTomorrowInLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := &forecastRepo{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetLocation(moscow)
+	// В UTC еще 16 октября, а в Москве уже 17-е
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 22, 30, 0, 0, time.UTC) }
+
+	forecast, err := s.ReviewForecast(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	want := time.Date(2026, 10, 18, 0, 0, 0, 0, moscow)
+	if !repo.tomorrow.Equal(want) {
+		t.Errorf("ожидалось начало завтрашнего дня %s, получено %s", want, repo.tomorrow)
+	}
+	if forecast.Today != 3 || forecast.Week != 9 {
+		t.Errorf("прогноз должен возвращаться из репозитория как есть, получено %+v", forecast)
+	}
+}
@@ -0,0 +1,55 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// Ошибки возврата слова на повторение
+var (
+	ErrWordNotInDeck  = errors.New("слова нет в колоде")
+	ErrWordNotStudied = errors.New("слово еще не изучалось")
+)
+
+// ForgetWord возвращает слово в повторение: снимает отметку «выучено», сбрасывает
+// сложность и назначает повторение на сейчас, чтобы карточка попала в следующую сессию
+func (s *Service) ForgetWord(ctx context.Context, userID int64, word string) (*models.UserFlashcard, error) {
+	word = strings.TrimSpace(word)
+
+	flashcard, err := s.flashcardRepo.GetFlashcardByWord(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+	if flashcard == nil {
+		return nil, ErrWordNotInDeck
+	}
+
+	// Слово может быть в колоде на нескольких уровнях, поэтому прогресс ищем по слову
+	userCard, err := s.flashcardRepo.GetUserFlashcardByWord(ctx, userID, word)
+	if err != nil {
+		return nil, err
+	}
+	if userCard == nil {
+		return nil, ErrWordNotStudied
+	}
+
+	userCard.IsLearned = false
+	userCard.Difficulty = 0
+	userCard.EasyStreak = 0
+	userCard.NextReviewAt = s.now()
+
+	if err := s.flashcardRepo.UpdateUserFlashcard(ctx, userCard); err != nil {
+		return nil, fmt.Errorf("ошибка возврата слова на повторение: %w", err)
+	}
+
+	s.logger.Info("слово возвращено на повторение",
+		zap.Int64("user_id", userID),
+		zap.Int64("flashcard_id", userCard.FlashcardID))
+	return userCard, nil
+}
@@ -0,0 +1,82 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// forgetRepo колода из одной карточки и прогресс пользователя по ней
+type forgetRepo struct {
+	store.FlashcardRepository
+	deck     *models.Flashcard
+	progress *models.UserFlashcard
+	updated  *models.UserFlashcard
+}
+
+func (r *forgetRepo) GetFlashcardByWord(ctx context.Context, word string) (*models.Flashcard, error) {
+	if r.deck == nil || r.deck.Word != word {
+		return nil, nil
+	}
+	return r.deck, nil
+}
+
+func (r *forgetRepo) GetUserFlashcardByWord(ctx context.Context, userID int64, word string) (*models.UserFlashcard, error) {
+	return r.progress, nil
+}
+
+func (r *forgetRepo) UpdateUserFlashcard(ctx context.Context, card *models.UserFlashcard) error {
+	r.updated = card
+	return nil
+}
+
+func TestForgetWordReturnsCardToReview(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	deck := &models.Flashcard{ID: 7, Word: "apple", Translation: "яблоко"}
+	repo := &forgetRepo{
+		deck: deck,
+		progress: &models.UserFlashcard{
+			FlashcardID:  7,
+			Difficulty:   3,
+			EasyStreak:   4,
+			IsLearned:    true,
+			NextReviewAt: now.Add(90 * 24 * time.Hour),
+			Flashcard:    deck,
+		},
+	}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.now = func() time.Time { return now }
+
+	if _, err := s.ForgetWord(context.Background(), 1, " apple "); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	card := repo.updated
+	if card == nil {
+		t.Fatal("ожидалось сохранение прогресса")
+	}
+	if card.IsLearned || card.Difficulty != 0 || card.EasyStreak != 0 || !card.NextReviewAt.Equal(now) {
+		t.Errorf("карточка должна вернуться на повторение сейчас, получено %+v", card)
+	}
+}
+
+func TestForgetWordUnknownWords(t *testing.T) {
+	repo := &forgetRepo{deck: &models.Flashcard{ID: 7, Word: "apple"}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	if _, err := s.ForgetWord(context.Background(), 1, "pear"); !errors.Is(err, ErrWordNotInDeck) {
+		t.Errorf("ожидалась ошибка ErrWordNotInDeck, получено %v", err)
+	}
+	if _, err := s.ForgetWord(context.Background(), 1, "apple"); !errors.Is(err, ErrWordNotStudied) {
+		t.Errorf("ожидалась ошибка ErrWordNotStudied, получено %v", err)
+	}
+	if repo.updated != nil {
+		t.Error("прогресс не должен меняться для неизвестного слова")
+	}
+}
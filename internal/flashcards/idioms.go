@@ -0,0 +1,48 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// Ограничения карточки идиомы: word и translation ограничены колонками таблицы flashcards
+const (
+	maxIdiomLen            = 255
+	maxIdiomTranslationLen = 500
+)
+
+// SaveIdiom добавляет идиому в карточки пользователя.
+// Возвращает false, если пользователь уже учит эту идиому.
+func (s *Service) SaveIdiom(ctx context.Context, userID int64, idiom, translation, example, level string) (bool, error) {
+	idiom = strings.TrimSpace(idiom)
+	translation = strings.TrimSpace(translation)
+	if idiom == "" || translation == "" || len(idiom) > maxIdiomLen || len(translation) > maxIdiomTranslationLen {
+		return false, fmt.Errorf("некорректная идиома для карточки: %q", idiom)
+	}
+
+	card := &models.Flashcard{
+		Word:        idiom,
+		Translation: translation,
+		Example:     strings.TrimSpace(example),
+		Level:       level,
+		Category:    store.IdiomCardCategory,
+	}
+
+	added, err := s.flashcardRepo.AddUserCard(ctx, userID, card)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сохранения идиомы: %w", err)
+	}
+
+	if added {
+		s.logger.Info("идиома добавлена в карточки пользователя",
+			zap.Int64("user_id", userID),
+			zap.String("idiom", idiom))
+	}
+	return added, nil
+}
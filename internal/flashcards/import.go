@@ -0,0 +1,134 @@
+package flashcards
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidImportCSV файл импорта не разбирается как CSV
+var ErrInvalidImportCSV = errors.New("некорректный CSV")
+
+// importColumns столбцы CSV для импорта карточек в порядке следования
+var importColumns = []string{"word", "translation", "example", "level", "category"}
+
+// ImportRowError ошибка в строке CSV при импорте карточек
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportSummary итог импорта карточек
+type ImportSummary struct {
+	Inserted int              `json:"inserted"`
+	Skipped  int              `json:"skipped"` // слово уже есть в пуле или повторяется в файле
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportCSV добавляет в общий пул карточки из CSV со столбцами
+// word,translation,example,level,category. Строка заголовка необязательна, пустая
+// категория означает general. Строки с ошибками пропускаются и попадают в итог.
+func (s *Service) ImportCSV(ctx context.Context, r io.Reader) (*ImportSummary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	summary := &ImportSummary{Errors: []ImportRowError{}}
+	seen := make(map[string]bool)
+	var cards []*models.Flashcard
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportCSV, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && isImportHeader(record) {
+			continue
+		}
+
+		card, err := parseImportRecord(record)
+		if err != nil {
+			summary.Errors = append(summary.Errors, ImportRowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		key := strings.ToLower(card.Word)
+		if seen[key] {
+			summary.Skipped++
+			continue
+		}
+		seen[key] = true
+		cards = append(cards, card)
+	}
+
+	if len(cards) > 0 {
+		inserted, err := s.flashcardRepo.BulkCreate(ctx, cards)
+		if err != nil {
+			return nil, err
+		}
+		summary.Inserted = inserted
+		summary.Skipped += len(cards) - inserted
+	}
+
+	s.logger.Info("импорт карточек из CSV",
+		zap.Int("inserted", summary.Inserted),
+		zap.Int("skipped", summary.Skipped),
+		zap.Int("errors", len(summary.Errors)))
+
+	return summary, nil
+}
+
+// isImportHeader проверяет, что строка — заголовок со стандартными названиями столбцов
+func isImportHeader(record []string) bool {
+	return len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), importColumns[0])
+}
+
+// parseImportRecord проверяет строку CSV и собирает из нее карточку
+func parseImportRecord(record []string) (*models.Flashcard, error) {
+	if len(record) < 4 || len(record) > len(importColumns) {
+		return nil, fmt.Errorf("ожидается %d столбцов (%s), получено %d",
+			len(importColumns), strings.Join(importColumns, ","), len(record))
+	}
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+
+	card := &models.Flashcard{
+		Word:        record[0],
+		Translation: record[1],
+		Example:     record[2],
+		Level:       strings.ToLower(record[3]),
+		Category:    "general",
+	}
+	if len(record) == len(importColumns) && record[4] != "" {
+		card.Category = strings.ToLower(record[4])
+	}
+
+	switch {
+	case card.Word == "":
+		return nil, errors.New("пустое слово")
+	case card.Translation == "":
+		return nil, errors.New("пустой перевод")
+	case !models.IsValidLevel(card.Level):
+		return nil, fmt.Errorf("неизвестный уровень %q", card.Level)
+	case !models.IsValidFlashcardCategory(card.Category):
+		return nil, fmt.Errorf("неизвестная категория %q", card.Category)
+	}
+	return card, nil
+}
@@ -0,0 +1,78 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// importRepo пропускает слова, которые уже есть в пуле, и запоминает добавленные
+type importRepo struct {
+	store.FlashcardRepository
+	existing map[string]bool
+	created  []*models.Flashcard
+}
+
+func (r *importRepo) BulkCreate(ctx context.Context, flashcards []*models.Flashcard) (int, error) {
+	for _, card := range flashcards {
+		if r.existing[strings.ToLower(card.Word)] {
+			continue
+		}
+		r.created = append(r.created, card)
+	}
+	return len(r.created), nil
+}
+
+func TestImportCSV(t *testing.T) {
+	repo := &importRepo{existing: map[string]bool{"apple": true}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	csv := `word,translation,example,level,category
+ticket,билет,"I bought a ticket, finally.",beginner,travel
+Apple,яблоко,,beginner,food
+meeting,встреча,The meeting starts at 9.,Intermediate,
+ticket,билет,,beginner,travel
+,пусто,,beginner,general
+house,,,beginner,general
+river,река,,expert,general
+cake,торт,,beginner,sweets
+`
+	summary, err := s.ImportCSV(context.Background(), strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if summary.Inserted != 2 || summary.Skipped != 2 {
+		t.Errorf("ожидалось 2 добавленных и 2 пропущенных, получено %+v", summary)
+	}
+	var lines []int
+	for _, rowErr := range summary.Errors {
+		lines = append(lines, rowErr.Line)
+	}
+	if len(lines) != 4 || lines[0] != 6 || lines[3] != 9 {
+		t.Errorf("ожидались ошибки в строках 6-9, получено %+v", summary.Errors)
+	}
+
+	meeting := repo.created[1]
+	if meeting.Word != "meeting" || meeting.Level != models.LevelIntermediate || meeting.Category != "general" {
+		t.Errorf("ожидалась карточка meeting уровня intermediate в general, получено %+v", meeting)
+	}
+	if repo.created[0].Example != "I bought a ticket, finally." {
+		t.Errorf("пример в кавычках должен сохраниться целиком, получено %q", repo.created[0].Example)
+	}
+}
+
+func TestImportCSVRejectsMalformedFile(t *testing.T) {
+	s := NewService(&importRepo{}, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	_, err := s.ImportCSV(context.Background(), strings.NewReader("word,\"translation\nbroken"))
+	if !errors.Is(err, ErrInvalidImportCSV) {
+		t.Errorf("ожидалась ErrInvalidImportCSV, получено %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package flashcards
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// newCardCandidateFactor во сколько раз больше кандидатов запрашивается,
+// чтобы после отсева похожих слов хватило карточек на сессию
+const newCardCandidateFactor = 3
+
+// Пороги похожести слов
+const (
+	minRelatedStem   = 5 // Минимальная длина слова, от которого ищутся однокоренные
+	minConfusableLen = 6 // Минимальная длина слов, отличающихся одной буквой (affect/effect)
+)
+
+// relatedSuffixes словообразовательные окончания, которые связывают однокоренные слова
+var relatedSuffixes = []string{
+	"s", "es", "ed", "d", "ing", "er", "ers", "est", "ier", "iest", "ies", "ied",
+	"ly", "ily", "ness", "iness", "ful", "less", "ment", "ion", "tion", "ation",
+	"able", "ible", "ity", "ive", "al", "ous",
+}
+
+// SetSpacedIntroduction включает выдачу новых слов от частых к редким с чередованием категорий
+func (s *Service) SetSpacedIntroduction(enabled bool) {
+	s.newCardOrder = store.NewCardOrderRandom
+	if enabled {
+		s.newCardOrder = store.NewCardOrderSpaced
+	}
+}
+
+// pickNewCards выбирает до limit новых карточек. При постепенном вводе слов пропускаются слова,
+// похожие на еще не выученные или друг на друга; если отсеяны все кандидаты, выдаются они же
+// без отсева, чтобы занятие не заканчивалось при оставшихся словах. Непустая category ограничивает
+// выбор одной категорией. Второе значение — сколько кандидатов вернула база: 0 означает, что пул исчерпан.
+func (s *Service) pickNewCards(ctx context.Context, userID int64, level, category string, limit int) ([]*models.Flashcard, int, error) {
+	spaced := s.newCardOrder == store.NewCardOrderSpaced
+	fetch := limit
+	if spaced {
+		fetch = limit * newCardCandidateFactor
+	}
+
+	var candidates []*models.Flashcard
+	var err error
+	if category == "" {
+		candidates, err = s.flashcardRepo.GetNewCardsForUser(ctx, userID, level, fetch, s.newCardOrder)
+	} else {
+		candidates, err = s.flashcardRepo.GetNewCardsForUserByCategory(ctx, userID, level, category, fetch)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(candidates) == 0 {
+		return nil, 0, nil
+	}
+	if !spaced {
+		return candidates[:min(limit, len(candidates))], len(candidates), nil
+	}
+
+	unlearned, err := s.flashcardRepo.GetUnlearnedFlashcards(ctx, userID)
+	if err != nil {
+		// Без списка невыученных слов сессия все равно полезна
+		s.logger.Warn("не удалось получить невыученные карточки, похожие слова не отсеиваются",
+			zap.Error(err), zap.Int64("user_id", userID))
+	}
+
+	picked := spaceSimilarCards(candidates, unlearned, limit)
+	if len(picked) == 0 {
+		s.logger.Info("все новые слова похожи на начатые, выдаем их без отсева",
+			zap.Int64("user_id", userID),
+			zap.Int("candidates", len(candidates)))
+		return candidates[:min(limit, len(candidates))], len(candidates), nil
+	}
+	if skipped := len(candidates) - len(picked); skipped > 0 && len(picked) < limit {
+		s.logger.Info("похожие слова отложены до изучения уже начатых",
+			zap.Int64("user_id", userID),
+			zap.Int("skipped", skipped))
+	}
+
+	return picked, len(candidates), nil
+}
+
+// spaceSimilarCards оставляет кандидатов, не похожих ни на невыученные слова, ни на уже выбранные.
+// Порядок кандидатов сохраняется.
+func spaceSimilarCards(candidates, unlearned []*models.Flashcard, limit int) []*models.Flashcard {
+	taken := append([]*models.Flashcard(nil), unlearned...)
+	picked := make([]*models.Flashcard, 0, limit)
+
+	for _, card := range candidates {
+		if len(picked) == limit {
+			break
+		}
+		if hasSimilarCard(card, taken) {
+			continue
+		}
+		picked = append(picked, card)
+		taken = append(taken, card)
+	}
+
+	return picked
+}
+
+// hasSimilarCard проверяет, есть ли среди cards слово, похожее на card
+func hasSimilarCard(card *models.Flashcard, cards []*models.Flashcard) bool {
+	for _, other := range cards {
+		if similarCards(card, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// similarCards считает слова похожими, если у них одно основное значение, они однокоренные
+// (happy/happiness) или это длинные слова, отличающиеся одной буквой (affect/effect)
+func similarCards(a, b *models.Flashcard) bool {
+	wordA := strings.ToLower(strings.TrimSpace(a.Word))
+	wordB := strings.ToLower(strings.TrimSpace(b.Word))
+	if wordA == wordB {
+		return true
+	}
+	if relatedWords(wordA, wordB) {
+		return true
+	}
+	return sharesMeaning(a.Translation, b.Translation)
+}
+
+// relatedWords проверяет, что одно слово образовано от другого известным окончанием
+// или что слова одной длины отличаются одной буквой
+func relatedWords(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra) < minRelatedStem {
+		return false
+	}
+
+	// Основа — короткое слово целиком или без конечной e/y (happy → happiness, create → creation)
+	stems := []string{string(ra)}
+	if last := ra[len(ra)-1]; last == 'e' || last == 'y' {
+		stems = append(stems, string(ra[:len(ra)-1]))
+	}
+	longer := string(rb)
+	for _, stem := range stems {
+		if !strings.HasPrefix(longer, stem) {
+			continue
+		}
+		if slices.Contains(relatedSuffixes, strings.TrimPrefix(longer, stem)) {
+			return true
+		}
+	}
+
+	return len(ra) == len(rb) && len(ra) >= minConfusableLen && oneEditApart(ra, rb)
+}
+
+// commonPrefix возвращает длину общего начала слов
+func commonPrefix(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// oneEditApart проверяет, что слова отличаются ровно одной заменой, вставкой или удалением буквы
+func oneEditApart(a, b []rune) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+
+	i := commonPrefix(a, b)
+	if len(a) == len(b) {
+		return string(a[i+1:]) == string(b[i+1:])
+	}
+	return string(a[i:]) == string(b[i+1:])
+}
+
+// sharesMeaning проверяет, что у переводов одно основное (первое) значение: "большой, крупный"
+// и "большой" похожи, а "дом, здание" и "здание" — нет, второстепенные значения часто совпадают
+func sharesMeaning(a, b string) bool {
+	primaryA, primaryB := primaryMeaning(a), primaryMeaning(b)
+	return primaryA != "" && primaryA == primaryB
+}
+
+// primaryMeaning возвращает первое значение перевода
+func primaryMeaning(translation string) string {
+	meanings := translationMeanings(translation)
+	if len(meanings) == 0 {
+		return ""
+	}
+	return meanings[0]
+}
+
+// translationMeanings разбивает перевод на отдельные значения в исходном порядке
+func translationMeanings(translation string) []string {
+	parts := strings.FieldsFunc(strings.ToLower(translation), func(r rune) bool {
+		return r == ',' || r == ';' || r == '/'
+	})
+
+	meanings := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			meanings = append(meanings, part)
+		}
+	}
+	return meanings
+}
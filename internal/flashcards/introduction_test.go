@@ -0,0 +1,150 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+func card(id int64, word, translation string) *models.Flashcard {
+	return &models.Flashcard{ID: id, Word: word, Translation: translation, Level: models.LevelBeginner}
+}
+
+func TestSimilarCards(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *models.Flashcard
+		similar bool
+	}{
+		{"одна основа", card(1, "happy", "счастливый"), card(2, "happiness", "счастье"), true},
+		{"одна буква", card(1, "affect", "влиять"), card(2, "effect", "эффект"), true},
+		{"общий перевод", card(1, "big", "большой, крупный"), card(2, "large", "большой"), true},
+		{"регистр", card(1, "House", "дом"), card(2, "house", "жилище"), true},
+		{"разные слова", card(1, "apple", "яблоко"), card(2, "river", "река"), false},
+		{"короткие слова", card(1, "cat", "кот"), card(2, "car", "машина"), false},
+		{"производное от глагола", card(1, "create", "создавать"), card(2, "creation", "создание"), true},
+		{"общее начало без окончания", card(1, "close", "закрывать"), card(2, "closet", "шкаф"), false},
+		{"короткая основа", card(1, "care", "забота"), card(2, "career", "карьера"), false},
+		{"одна буква в коротком слове", card(1, "house", "дом"), card(2, "horse", "лошадь"), false},
+		{"общее второстепенное значение", card(1, "house", "дом, здание"), card(2, "building", "здание"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarCards(tt.a, tt.b); got != tt.similar {
+				t.Errorf("ожидалось %v, получено %v", tt.similar, got)
+			}
+		})
+	}
+}
+
+func TestSpaceSimilarCardsSkipsUnlearnedAndBatchDuplicates(t *testing.T) {
+	candidates := []*models.Flashcard{
+		card(1, "happiness", "счастье"),
+		card(2, "river", "река"),
+		card(3, "stream", "река, ручей"),
+		card(4, "cloud", "облако"),
+		card(5, "window", "окно"),
+	}
+	unlearned := []*models.Flashcard{card(10, "happy", "счастливый")}
+
+	picked := spaceSimilarCards(candidates, unlearned, 2)
+
+	if len(picked) != 2 || picked[0].Word != "river" || picked[1].Word != "cloud" {
+		words := make([]string, len(picked))
+		for i, c := range picked {
+			words[i] = c.Word
+		}
+		t.Errorf("ожидалось [river cloud], получено %v", words)
+	}
+}
+
+// orderRepo запоминает запрошенный порядок и отдает заранее заданных кандидатов
+type orderRepo struct {
+	poolRepo
+	order     store.NewCardOrder
+	unlearned []*models.Flashcard
+}
+
+func (r *orderRepo) GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int, order store.NewCardOrder) ([]*models.Flashcard, error) {
+	r.order = order
+	return r.poolRepo.GetNewCardsForUser(ctx, userID, level, limit, order)
+}
+
+func (r *orderRepo) GetUnlearnedFlashcards(ctx context.Context, userID int64) ([]*models.Flashcard, error) {
+	return r.unlearned, nil
+}
+
+func TestStartSessionDefersSimilarWords(t *testing.T) {
+	repo := &orderRepo{
+		poolRepo: poolRepo{
+			pool:     []*models.Flashcard{card(1, "happiness", "счастье"), card(2, "river", "река")},
+			assigned: map[int64]bool{},
+		},
+		unlearned: []*models.Flashcard{card(10, "happy", "счастливый")},
+	}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetSpacedIntroduction(true)
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if session == nil || len(session.CardsToReview) != 1 || session.CardsToReview[0].Flashcard.Word != "river" {
+		t.Fatalf("ожидалась сессия только со словом river, получено %+v", session)
+	}
+	if repo.order != store.NewCardOrderSpaced {
+		t.Errorf("ожидался порядок NewCardOrderSpaced, получено %v", repo.order)
+	}
+}
+
+func TestStartSessionFallsBackWhenAllNewWordsAreSimilar(t *testing.T) {
+	repo := &orderRepo{
+		poolRepo: poolRepo{
+			pool:     []*models.Flashcard{card(1, "happiness", "счастье")},
+			assigned: map[int64]bool{},
+		},
+		unlearned: []*models.Flashcard{card(10, "happy", "счастливый")},
+	}
+	metrics := &fakePoolMetrics{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPoolMetrics(metrics)
+	s.SetSpacedIntroduction(true)
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if session == nil || len(session.CardsToReview) != 1 {
+		t.Fatalf("пока есть новые слова, сессия не должна быть пустой, получено %+v", session)
+	}
+	if metrics.exhausted != 0 {
+		t.Errorf("отложенные слова не означают исчерпанный пул, метрика записана %d раз", metrics.exhausted)
+	}
+}
+
+func TestStartSessionKeepsSimilarWordsWithoutSpacedIntroduction(t *testing.T) {
+	repo := &orderRepo{
+		poolRepo: poolRepo{
+			pool:     []*models.Flashcard{card(1, "happiness", "счастье"), card(2, "river", "река")},
+			assigned: map[int64]bool{},
+		},
+		unlearned: []*models.Flashcard{card(10, "happy", "счастливый")},
+	}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if session == nil || len(session.CardsToReview) != 2 {
+		t.Fatalf("без постепенного ввода похожие слова не отсеиваются, получено %+v", session)
+	}
+	if repo.order != store.NewCardOrderRandom {
+		t.Errorf("ожидался порядок NewCardOrderRandom, получено %v", repo.order)
+	}
+}
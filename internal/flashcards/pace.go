@@ -0,0 +1,94 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// newCardsPerSession сколько новых карточек добавляется в одну сессию
+const newCardsPerSession = 10
+
+// ErrDailyPaceReached дневная норма новых слов выбрана, а повторять пока нечего
+var ErrDailyPaceReached = errors.New("дневная норма новых слов выполнена")
+
+// PaceSource возвращает выбранный пользователем темп: сколько новых слов в день
+type PaceSource interface {
+	GetNewCardsPerDay(ctx context.Context, userID int64) (int, error)
+}
+
+// SetPaceSource задает источник дневной нормы новых слов.
+// Пока источник не задан, действует норма по умолчанию.
+func (s *Service) SetPaceSource(source PaceSource) {
+	s.pace = source
+}
+
+// SetLocation задает часовой пояс, в полночь которого начинается новый день для нормы новых слов
+func (s *Service) SetLocation(loc *time.Location) {
+	if loc != nil {
+		s.loc = loc
+	}
+}
+
+// dayStart возвращает начало текущих суток в поясе сервиса
+func (s *Service) dayStart() time.Time {
+	y, m, d := s.now().In(s.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, s.loc)
+}
+
+// newCardsPerDay возвращает дневную норму новых слов пользователя
+func (s *Service) newCardsPerDay(ctx context.Context, userID int64) (int, error) {
+	if s.pace == nil {
+		return models.DefaultNewCardsPerDay, nil
+	}
+	return s.pace.GetNewCardsPerDay(ctx, userID)
+}
+
+// DailyNewCards возвращает, сколько новых слов пользователь начал сегодня, и его дневную норму
+func (s *Service) DailyNewCards(ctx context.Context, userID int64) (introduced, pace int, err error) {
+	pace, err = s.newCardsPerDay(ctx, userID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ошибка получения темпа: %w", err)
+	}
+
+	introduced, err = s.flashcardRepo.CountNewCardsSince(ctx, userID, s.dayStart())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return introduced, pace, nil
+}
+
+// AvailableNewCards возвращает, сколько карточек уровня пользователь еще не начинал
+func (s *Service) AvailableNewCards(ctx context.Context, userID int64, level string) (int, error) {
+	return s.flashcardRepo.CountAvailableNewCards(ctx, userID, level)
+}
+
+// newCardAllowance возвращает, сколько новых карточек можно добавить в сессию с учетом дневной нормы
+func (s *Service) newCardAllowance(ctx context.Context, userID int64) (int, error) {
+	introduced, pace, err := s.DailyNewCards(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := pace - introduced
+	if remaining > newCardsPerSession {
+		remaining = newCardsPerSession
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	s.logger.Info("дневная норма новых слов",
+		zap.Int64("user_id", userID),
+		zap.Int("introduced", introduced),
+		zap.Int("pace", pace),
+		zap.Int("allowance", remaining))
+
+	return remaining, nil
+}
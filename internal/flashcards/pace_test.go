@@ -0,0 +1,95 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// fixedPace возвращает одну и ту же дневную норму для всех пользователей
+type fixedPace int
+
+func (p fixedPace) GetNewCardsPerDay(ctx context.Context, userID int64) (int, error) {
+	return int(p), nil
+}
+
+func newPacePool(count int) *poolRepo {
+	repo := &poolRepo{assigned: map[int64]bool{}}
+	for i := 1; i <= count; i++ {
+		// Короткие разные слова, чтобы отсев похожих слов не срабатывал
+		repo.pool = append(repo.pool, &models.Flashcard{
+			ID:          int64(i),
+			Word:        fmt.Sprintf("w%02d", i),
+			Translation: fmt.Sprintf("слово %d", i),
+			Level:       models.LevelBeginner,
+		})
+	}
+	return repo
+}
+
+func TestStartSessionRespectsDailyPace(t *testing.T) {
+	repo := newPacePool(30)
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPaceSource(fixedPace(5))
+	ctx := context.Background()
+
+	session, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(session.CardsToReview) != 5 {
+		t.Fatalf("ожидалось 5 новых карточек, получено %d", len(session.CardsToReview))
+	}
+
+	_, err = s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+	if !errors.Is(err, ErrDailyPaceReached) {
+		t.Errorf("ожидалась ошибка ErrDailyPaceReached, получено %v", err)
+	}
+
+	introduced, pace, err := s.DailyNewCards(ctx, 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if introduced != 5 || pace != 5 {
+		t.Errorf("ожидалось 5 из 5, получено %d из %d", introduced, pace)
+	}
+}
+
+func TestStartSessionSplitsLargePaceIntoSessions(t *testing.T) {
+	repo := newPacePool(30)
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPaceSource(fixedPace(20))
+	ctx := context.Background()
+
+	for i, want := range []int{newCardsPerSession, newCardsPerSession} {
+		session, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+		if err != nil {
+			t.Fatalf("сессия %d: неожиданная ошибка: %v", i, err)
+		}
+		if len(session.CardsToReview) != want {
+			t.Errorf("сессия %d: ожидалось %d карточек, получено %d", i, want, len(session.CardsToReview))
+		}
+	}
+
+	if _, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner); !errors.Is(err, ErrDailyPaceReached) {
+		t.Errorf("ожидалась ошибка ErrDailyPaceReached, получено %v", err)
+	}
+}
+
+func TestStartSessionUsesDefaultPaceWithoutSource(t *testing.T) {
+	repo := newPacePool(30)
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(session.CardsToReview) != models.DefaultNewCardsPerDay {
+		t.Errorf("ожидалось %d карточек, получено %d", models.DefaultNewCardsPerDay, len(session.CardsToReview))
+	}
+}
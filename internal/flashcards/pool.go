@@ -0,0 +1,130 @@
+package flashcards
+
+import (
+	"context"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// PoolSeedConfig настройки пополнения пула карточек
+type PoolSeedConfig struct {
+	BatchSize   int           // Сколько карточек генерировать за раз
+	MinInterval time.Duration // Не чаще одного пополнения уровня за этот интервал
+}
+
+// DefaultPoolSeedConfig настройки пополнения по умолчанию
+var DefaultPoolSeedConfig = PoolSeedConfig{
+	BatchSize:   10,
+	MinInterval: time.Hour,
+}
+
+// maxExcludedWords сколько известных слов уровня передается генератору для исключения повторов
+const maxExcludedWords = 150
+
+// PoolMetrics записывает метрики пула карточек
+type PoolMetrics interface {
+	RecordFlashcardPoolExhausted(level string)
+	RecordFlashcardsSeeded(level string, count int)
+}
+
+// SetPoolMetrics задает получателя метрик пула карточек
+func (s *Service) SetPoolMetrics(metrics PoolMetrics) {
+	s.poolMetrics = metrics
+}
+
+// SetPoolSeeding включает автоматическое пополнение пула карточек генератором
+func (s *Service) SetPoolSeeding(generator CardGenerator, cfg PoolSeedConfig) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultPoolSeedConfig.BatchSize
+	}
+	s.generator = generator
+	s.seedConfig = cfg
+}
+
+// handleExhaustedPool предупреждает о том, что у пользователя закончились новые
+// карточки уровня, и при включенном пополнении генерирует новые.
+// Возвращает новые карточки для пользователя, если пул удалось пополнить.
+func (s *Service) handleExhaustedPool(ctx context.Context, userID int64, level string, limit int) []*models.Flashcard {
+	s.logger.Warn("пул карточек уровня исчерпан для пользователя",
+		zap.Int64("user_id", userID),
+		zap.String("level", level),
+		zap.Bool("auto_seed", s.generator != nil))
+	if s.poolMetrics != nil {
+		s.poolMetrics.RecordFlashcardPoolExhausted(level)
+	}
+
+	if s.generator == nil || !s.reserveSeed(level) {
+		return nil
+	}
+
+	added := s.seedPool(ctx, level)
+	if added == 0 {
+		return nil
+	}
+
+	cards, _, err := s.pickNewCards(ctx, userID, level, "", limit)
+	if err != nil {
+		s.logger.Error("ошибка получения карточек после пополнения", zap.Error(err))
+		return nil
+	}
+	return cards
+}
+
+// reserveSeed проверяет интервал между пополнениями уровня и занимает слот пополнения
+func (s *Service) reserveSeed(level string) bool {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+
+	now := s.now()
+	if last, ok := s.lastSeed[level]; ok && now.Sub(last) < s.seedConfig.MinInterval {
+		s.logger.Info("пополнение пула карточек пропущено: недавно уже пополняли",
+			zap.String("level", level),
+			zap.Time("last_seed", last))
+		return false
+	}
+	s.lastSeed[level] = now
+	return true
+}
+
+// seedPool генерирует и сохраняет новые карточки уровня, возвращает число добавленных
+func (s *Service) seedPool(ctx context.Context, level string) int {
+	existing, err := s.flashcardRepo.GetFlashcardsByLevel(ctx, level, maxExcludedWords)
+	if err != nil {
+		s.logger.Error("ошибка получения карточек уровня для пополнения", zap.Error(err))
+		return 0
+	}
+	exclude := make([]string, 0, len(existing))
+	for _, card := range existing {
+		exclude = append(exclude, card.Word)
+	}
+
+	generated, err := s.generator.GenerateCards(ctx, level, s.seedConfig.BatchSize, exclude)
+	if err != nil {
+		s.logger.Error("ошибка генерации карточек для пополнения пула", zap.Error(err), zap.String("level", level))
+		return 0
+	}
+
+	added := 0
+	for _, card := range generated {
+		created, err := s.flashcardRepo.CreateFlashcard(ctx, card)
+		if err != nil {
+			s.logger.Error("ошибка сохранения сгенерированной карточки", zap.Error(err), zap.String("word", card.Word))
+			continue
+		}
+		if created {
+			added++
+		}
+	}
+
+	s.logger.Info("пул карточек пополнен",
+		zap.String("level", level),
+		zap.Int("generated", len(generated)),
+		zap.Int("added", added))
+	if s.poolMetrics != nil {
+		s.poolMetrics.RecordFlashcardsSeeded(level, added)
+	}
+	return added
+}
@@ -0,0 +1,162 @@
+package flashcards
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// poolRepo хранит общий пул карточек и карточки, уже выданные пользователю
+type poolRepo struct {
+	store.FlashcardRepository
+	pool     []*models.Flashcard
+	assigned map[int64]bool
+}
+
+func (r *poolRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return nil, nil
+}
+
+func (r *poolRepo) GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int, order store.NewCardOrder) ([]*models.Flashcard, error) {
+	var cards []*models.Flashcard
+	for _, card := range r.pool {
+		if card.Level == level && !r.assigned[card.ID] && len(cards) < limit {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (r *poolRepo) GetFlashcardsByLevel(ctx context.Context, level string, limit int) ([]*models.Flashcard, error) {
+	return r.pool, nil
+}
+
+func (r *poolRepo) CreateFlashcard(ctx context.Context, flashcard *models.Flashcard) (bool, error) {
+	for _, card := range r.pool {
+		if strings.EqualFold(card.Word, flashcard.Word) && card.Level == flashcard.Level {
+			return false, nil
+		}
+	}
+	flashcard.ID = int64(len(r.pool) + 1)
+	r.pool = append(r.pool, flashcard)
+	return true, nil
+}
+
+func (r *poolRepo) GetUnlearnedFlashcards(ctx context.Context, userID int64) ([]*models.Flashcard, error) {
+	return nil, nil
+}
+
+// CountNewCardsSince считает все выданные карточки: в тестах они выданы сегодня
+func (r *poolRepo) CountNewCardsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	return len(r.assigned), nil
+}
+
+func (r *poolRepo) CreateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error {
+	r.assigned[userFlashcard.FlashcardID] = true
+	return nil
+}
+
+// fakeGenerator возвращает заданные слова и считает вызовы
+type fakeGenerator struct {
+	words   []string
+	calls   int
+	exclude []string
+}
+
+func (g *fakeGenerator) GenerateCards(ctx context.Context, level string, count int, exclude []string) ([]*models.Flashcard, error) {
+	g.calls++
+	g.exclude = exclude
+	cards := make([]*models.Flashcard, 0, len(g.words))
+	for _, word := range g.words {
+		cards = append(cards, &models.Flashcard{Word: word, Translation: "перевод " + word, Level: level})
+	}
+	return cards, nil
+}
+
+// fakePoolMetrics запоминает записанные метрики пула
+type fakePoolMetrics struct {
+	exhausted int
+	seeded    int
+}
+
+func (m *fakePoolMetrics) RecordFlashcardPoolExhausted(level string) { m.exhausted++ }
+func (m *fakePoolMetrics) RecordFlashcardsSeeded(level string, count int) {
+	m.seeded += count
+}
+
+func TestExhaustedPoolRecordsMetricWithoutSeeding(t *testing.T) {
+	repo := &poolRepo{assigned: map[int64]bool{}}
+	metrics := &fakePoolMetrics{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPoolMetrics(metrics)
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if session != nil {
+		t.Error("ожидалось отсутствие сессии при пустом пуле")
+	}
+	if metrics.exhausted != 1 {
+		t.Errorf("ожидалась 1 метрика исчерпания пула, получено %d", metrics.exhausted)
+	}
+}
+
+func TestExhaustedPoolSeedsNewCards(t *testing.T) {
+	repo := &poolRepo{
+		pool:     []*models.Flashcard{{ID: 1, Word: "apple", Level: models.LevelBeginner}},
+		assigned: map[int64]bool{1: true},
+	}
+	gen := &fakeGenerator{words: []string{"river", "Apple", "cloud"}}
+	metrics := &fakePoolMetrics{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPoolMetrics(metrics)
+	s.SetPoolSeeding(gen, PoolSeedConfig{BatchSize: 3, MinInterval: time.Hour})
+
+	session, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if session == nil {
+		t.Fatal("ожидалась сессия из сгенерированных карточек")
+	}
+	if len(session.CardsToReview) != 2 {
+		t.Errorf("ожидалось 2 новые карточки (дубликат отброшен), получено %d", len(session.CardsToReview))
+	}
+	if metrics.seeded != 2 {
+		t.Errorf("ожидалось 2 добавленные карточки в метрике, получено %d", metrics.seeded)
+	}
+	if len(gen.exclude) != 1 || gen.exclude[0] != "apple" {
+		t.Errorf("ожидалось исключение известных слов [apple], получено %v", gen.exclude)
+	}
+}
+
+func TestExhaustedPoolSeedingRespectsInterval(t *testing.T) {
+	repo := &poolRepo{assigned: map[int64]bool{}}
+	gen := &fakeGenerator{}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetPoolSeeding(gen, PoolSeedConfig{BatchSize: 5, MinInterval: time.Hour})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Генератор ничего не вернул — повторная попытка в пределах интервала не делается
+	s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+	s.StartFlashcardSession(ctx, 2, models.LevelBeginner)
+	if gen.calls != 1 {
+		t.Errorf("ожидался 1 вызов генератора в пределах интервала, получено %d", gen.calls)
+	}
+
+	now = now.Add(time.Hour)
+	s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+	if gen.calls != 2 {
+		t.Errorf("ожидался повторный вызов генератора после интервала, получено %d", gen.calls)
+	}
+}
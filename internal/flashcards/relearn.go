@@ -0,0 +1,45 @@
+package flashcards
+
+import (
+	"lingua-ai/pkg/models"
+)
+
+// maxRelearnRepeats сколько раз за сессию одна карточка может вернуться после ошибок
+const maxRelearnRepeats = 2
+
+// SetRelearnGap включает повтор карточек с ошибкой в той же сессии: карточка
+// возвращается после gap других карточек. 0 — не повторять до следующей сессии.
+func (s *Service) SetRelearnGap(gap int) {
+	if gap < 0 {
+		gap = 0
+	}
+	s.relearnGap = gap
+}
+
+// requeueForRelearn вставляет копию текущей карточки дальше в очередь сессии, чтобы
+// пользователь закрепил слово, на котором ошибся. Вызывается до перехода к следующей
+// карточке: вставка может переложить срез, и указатель CurrentCard после нее
+// должен быть выставлен заново.
+func (s *Service) requeueForRelearn(session *models.FlashcardSession) bool {
+	if s.relearnGap == 0 {
+		return false
+	}
+
+	card := *session.CurrentCard
+	if session.RelearnCounts[card.ID] >= maxRelearnRepeats {
+		return false
+	}
+	if session.RelearnCounts == nil {
+		session.RelearnCounts = make(map[int64]int)
+	}
+	session.RelearnCounts[card.ID]++
+
+	pos := session.CardsCompleted + 1 + s.relearnGap
+	if pos > len(session.CardsToReview) {
+		pos = len(session.CardsToReview)
+	}
+	session.CardsToReview = append(session.CardsToReview, models.UserFlashcard{})
+	copy(session.CardsToReview[pos+1:], session.CardsToReview[pos:])
+	session.CardsToReview[pos] = card
+	return true
+}
@@ -0,0 +1,84 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// answerSequence отвечает на карточки сессии по очереди и возвращает показанные слова
+func answerSequence(t *testing.T, s *Service, correct ...bool) []string {
+	t.Helper()
+
+	var words []string
+	for _, isCorrect := range correct {
+		session := s.GetCurrentSession(1)
+		if session == nil {
+			t.Fatalf("сессия завершилась раньше ожидаемого, показаны %v", words)
+		}
+		words = append(words, session.CurrentCard.Flashcard.Word)
+
+		difficulty := 3
+		if !isCorrect {
+			difficulty = 5
+		}
+		if _, err := s.AnswerCard(context.Background(), 1, isCorrect, difficulty); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	return words
+}
+
+func TestRelearnRequeuesWrongCardAfterGap(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple", "house", "river", "cloud")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetRelearnGap(2)
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	words := answerSequence(t, s, false, true, true, true, true)
+
+	want := []string{"apple", "house", "river", "apple", "cloud"}
+	for i := range want {
+		if words[i] != want[i] {
+			t.Fatalf("ожидался порядок %v, получено %v", want, words)
+		}
+	}
+	if s.GetCurrentSession(1) != nil {
+		t.Error("после повтора сессия должна завершиться")
+	}
+	if repo.cards[0].ReviewCount != 2 || repo.cards[0].CorrectCount != 1 {
+		t.Errorf("в базе должен остаться результат повтора, получено %+v", repo.cards[0])
+	}
+}
+
+func TestRelearnLimitsRepeatsPerCard(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetRelearnGap(3)
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	answerSequence(t, s, false, false, false)
+	if s.GetCurrentSession(1) != nil {
+		t.Errorf("карточка должна вернуться не больше %d раз", maxRelearnRepeats)
+	}
+}
+
+func TestRelearnDisabledByDefault(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple", "house")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	answerSequence(t, s, false, true)
+	if s.GetCurrentSession(1) != nil {
+		t.Error("без настройки карточка с ошибкой не должна повторяться в сессии")
+	}
+}
@@ -0,0 +1,87 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// DefaultReportThreshold после скольких жалоб карточка снимается с выдачи до проверки
+const DefaultReportThreshold = 3
+
+// Ошибки жалоб на карточки
+var (
+	ErrInvalidReportReason     = errors.New("неизвестная причина жалобы")
+	ErrInvalidReportResolution = errors.New("неизвестное решение по жалобе")
+)
+
+// ReportService сервис жалоб пользователей на ошибки в карточках
+type ReportService struct {
+	reportRepo store.FlashcardReportRepository
+	threshold  int
+	logger     *zap.Logger
+}
+
+// NewReportService создает сервис жалоб. Карточка снимается с выдачи, когда на нее
+// поступает threshold жалоб от разных пользователей; 0 — автоматически не снимается.
+func NewReportService(reportRepo store.FlashcardReportRepository, threshold int, logger *zap.Logger) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		threshold:  threshold,
+		logger:     logger,
+	}
+}
+
+// ReportCard сохраняет жалобу пользователя. Возвращает true, если карточка снята с выдачи.
+func (s *ReportService) ReportCard(ctx context.Context, userID, flashcardID int64, reason string) (bool, error) {
+	if !models.IsValidReportReason(reason) {
+		return false, ErrInvalidReportReason
+	}
+
+	suspended, err := s.reportRepo.AddReport(ctx, flashcardID, userID, reason, s.threshold)
+	if err != nil {
+		return false, err
+	}
+
+	s.logger.Info("жалоба на карточку",
+		zap.Int64("user_id", userID),
+		zap.Int64("flashcard_id", flashcardID),
+		zap.String("reason", reason),
+		zap.Bool("suspended", suspended))
+	if suspended {
+		s.logger.Warn("карточка снята с выдачи до проверки администратором",
+			zap.Int64("flashcard_id", flashcardID),
+			zap.Int("threshold", s.threshold))
+	}
+
+	return suspended, nil
+}
+
+// OpenReports возвращает карточки с нерассмотренными жалобами
+func (s *ReportService) OpenReports(ctx context.Context) ([]models.FlashcardReportSummary, error) {
+	return s.reportRepo.ListOpenReports(ctx)
+}
+
+// ResolveReports закрывает жалобы на карточку решением администратора
+func (s *ReportService) ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error) {
+	if resolution != models.ReportResolutionRestore && resolution != models.ReportResolutionSuspend {
+		return 0, ErrInvalidReportResolution
+	}
+
+	resolved, err := s.reportRepo.ResolveReports(ctx, flashcardID, resolution)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка рассмотрения жалоб: %w", err)
+	}
+
+	s.logger.Info("жалобы на карточку рассмотрены",
+		zap.Int64("flashcard_id", flashcardID),
+		zap.String("resolution", resolution),
+		zap.Int("reports", resolved))
+
+	return resolved, nil
+}
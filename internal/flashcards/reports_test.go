@@ -0,0 +1,75 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+type reportRepo struct {
+	store.FlashcardReportRepository
+	suspendAt  int
+	reason     string
+	resolution string
+}
+
+func (r *reportRepo) AddReport(ctx context.Context, flashcardID, userID int64, reason string, suspendAt int) (bool, error) {
+	r.reason = reason
+	r.suspendAt = suspendAt
+	return true, nil
+}
+
+func (r *reportRepo) ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error) {
+	r.resolution = resolution
+	return 2, nil
+}
+
+func TestReportCardPassesThreshold(t *testing.T) {
+	repo := &reportRepo{}
+	service := NewReportService(repo, 5, zap.NewNop())
+
+	suspended, err := service.ReportCard(context.Background(), 1, 7, models.ReportReasonExample)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !suspended {
+		t.Error("ожидалось снятие карточки с выдачи")
+	}
+	if repo.suspendAt != 5 || repo.reason != models.ReportReasonExample {
+		t.Errorf("ожидались порог 5 и причина %q, получено %d и %q", models.ReportReasonExample, repo.suspendAt, repo.reason)
+	}
+}
+
+func TestReportCardRejectsUnknownReason(t *testing.T) {
+	repo := &reportRepo{}
+	service := NewReportService(repo, DefaultReportThreshold, zap.NewNop())
+
+	if _, err := service.ReportCard(context.Background(), 1, 7, "spam"); !errors.Is(err, ErrInvalidReportReason) {
+		t.Errorf("ожидалась ErrInvalidReportReason, получено %v", err)
+	}
+	if repo.reason != "" {
+		t.Error("жалоба с неизвестной причиной не должна сохраняться")
+	}
+}
+
+func TestResolveReportsValidatesResolution(t *testing.T) {
+	repo := &reportRepo{}
+	service := NewReportService(repo, DefaultReportThreshold, zap.NewNop())
+
+	if _, err := service.ResolveReports(context.Background(), 7, "delete"); !errors.Is(err, ErrInvalidReportResolution) {
+		t.Errorf("ожидалась ErrInvalidReportResolution, получено %v", err)
+	}
+
+	resolved, err := service.ResolveReports(context.Background(), 7, models.ReportResolutionRestore)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if resolved != 2 || repo.resolution != models.ReportResolutionRestore {
+		t.Errorf("ожидалось 2 закрытые жалобы с решением restore, получено %d и %q", resolved, repo.resolution)
+	}
+}
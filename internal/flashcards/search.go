@@ -0,0 +1,31 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lingua-ai/pkg/models"
+)
+
+// VocabularySearchLimit сколько найденных слов максимум возвращает поиск по словарю
+const VocabularySearchLimit = 50
+
+// SearchVocabulary ищет среди слов, которые пользователь уже учил, те, у которых слово
+// или перевод содержит query. truncated — совпадений больше VocabularySearchLimit.
+func (s *Service) SearchVocabulary(ctx context.Context, userID int64, query string) (cards []*models.UserFlashcard, truncated bool, err error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, false, nil
+	}
+
+	// Запрашиваем на одну карточку больше, чтобы понять, что совпадения не поместились
+	cards, err = s.flashcardRepo.SearchUserFlashcards(ctx, userID, query, VocabularySearchLimit+1)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка поиска по словарю: %w", err)
+	}
+	if len(cards) > VocabularySearchLimit {
+		return cards[:VocabularySearchLimit], true, nil
+	}
+	return cards, false, nil
+}
@@ -0,0 +1,58 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// searchRepo возвращает total совпадений, но не больше запрошенного лимита
+type searchRepo struct {
+	store.FlashcardRepository
+	total int
+	limit int
+}
+
+func (r *searchRepo) SearchUserFlashcards(ctx context.Context, userID int64, query string, limit int) ([]*models.UserFlashcard, error) {
+	r.limit = limit
+	cards := make([]*models.UserFlashcard, min(r.total, limit))
+	for i := range cards {
+		cards[i] = &models.UserFlashcard{ID: int64(i + 1)}
+	}
+	return cards, nil
+}
+
+func TestSearchVocabularyCapsResults(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int
+		wantLen       int
+		wantTruncated bool
+	}{
+		{"помещаются", VocabularySearchLimit, VocabularySearchLimit, false},
+		{"обрезаются", VocabularySearchLimit + 20, VocabularySearchLimit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &searchRepo{total: tt.total}
+			service := NewService(repo, DefaultSpacedRepetitionConfig, zaptest.NewLogger(t))
+
+			cards, truncated, err := service.SearchVocabulary(context.Background(), 1, "дом")
+			if err != nil {
+				t.Fatalf("ошибка поиска: %v", err)
+			}
+			if len(cards) != tt.wantLen || truncated != tt.wantTruncated {
+				t.Errorf("ожидалось %d карточек (обрезано: %v), получено %d (%v)",
+					tt.wantLen, tt.wantTruncated, len(cards), truncated)
+			}
+			if repo.limit != VocabularySearchLimit+1 {
+				t.Errorf("ожидался лимит %d, получено %d", VocabularySearchLimit+1, repo.limit)
+			}
+		})
+	}
+}
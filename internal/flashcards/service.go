@@ -2,8 +2,13 @@ package flashcards
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
 	"time"
 
 	"lingua-ai/internal/store"
@@ -12,33 +17,101 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// EasyFastTrackStreak сколько ответов "легко" подряд достаточно, чтобы считать слово выученным
+	EasyFastTrackStreak = 2
+	// easyAnswerDifficulty максимальная оценка сложности, которая считается ответом "легко"
+	easyAnswerDifficulty = 2
+)
+
+// Ошибки сессии карточек
+var (
+	ErrNoActiveSession   = errors.New("активная сессия не найдена")
+	ErrNoCurrentCard     = errors.New("текущая карточка не найдена")
+	ErrCategoryExhausted = errors.New("в категории нет слов для изучения")
+)
+
 // Service сервис для работы со словарными карточками
 type Service struct {
 	flashcardRepo  store.FlashcardRepository
 	logger         *zap.Logger
-	activeSessions map[int64]*models.FlashcardSession // Активные сессии пользователей
+	activeSessions map[int64]*activeSession // Активные сессии пользователей
+	sessionsMu     sync.RWMutex             // Защищает только карту: сессии блокируются по отдельности
+
+	// Пополнение пула карточек (выключено, пока не задан генератор)
+	generator   CardGenerator
+	seedConfig  PoolSeedConfig
+	poolMetrics PoolMetrics
+	seedMu      sync.Mutex
+	lastSeed    map[string]time.Time
+	now         func() time.Time
+
+	// Генерация новых примеров (выключена, пока не задан генератор)
+	exampleGen          ExampleGenerator
+	exampleRefreshLimit int
+
+	// Дневная норма новых слов
+	pace PaceSource
+	loc  *time.Location
+
+	// Порядок выдачи новых слов
+	newCardOrder store.NewCardOrder
+
+	// Через сколько карточек повторить карточку с ошибкой в той же сессии (0 — не повторять)
+	relearnGap int
+
+	// Недельные цели по выученным словам (не заданы, пока не задан источник)
+	weeklyTarget WeeklyTargetSource
+
+	// Интервалы повторения и источник случайности для их разброса
+	repetition SpacedRepetitionConfig
+	random     func() float64
+}
+
+// activeSession сессия пользователя со своей блокировкой: ответы одного пользователя
+// обрабатываются по очереди, не задерживая сессии остальных
+type activeSession struct {
+	mu      sync.Mutex
+	session *models.FlashcardSession
+	ended   bool // сессия завершена или заменена и больше не лежит в activeSessions
 }
 
 // NewService создает новый сервис карточек
-func NewService(flashcardRepo store.FlashcardRepository, logger *zap.Logger) *Service {
+func NewService(flashcardRepo store.FlashcardRepository, repetition SpacedRepetitionConfig, logger *zap.Logger) *Service {
 	return &Service{
 		flashcardRepo:  flashcardRepo,
 		logger:         logger,
-		activeSessions: make(map[int64]*models.FlashcardSession),
+		activeSessions: make(map[int64]*activeSession),
+		seedConfig:     DefaultPoolSeedConfig,
+		lastSeed:       make(map[string]time.Time),
+		now:            time.Now,
+		loc:            time.UTC,
+		repetition:     repetition.withDefaults(),
+		random:         rand.Float64,
 	}
 }
 
 // StartFlashcardSession начинает новую сессию изучения карточек
 func (s *Service) StartFlashcardSession(ctx context.Context, userID int64, userLevel string) (*models.FlashcardSession, error) {
+	return s.StartCategorySession(ctx, userID, userLevel, "")
+}
+
+// StartCategorySession начинает сессию по одной категории: повторяются и добавляются
+// только ее слова. Пустая категория — обычная сессия по всем словам уровня.
+func (s *Service) StartCategorySession(ctx context.Context, userID int64, userLevel, category string) (*models.FlashcardSession, error) {
 	s.logger.Info("начинаем сессию карточек",
 		zap.Int64("user_id", userID),
-		zap.String("user_level", userLevel))
+		zap.String("user_level", userLevel),
+		zap.String("category", category))
 
 	// Получаем карточки для повторения
 	cardsToReview, err := s.flashcardRepo.GetCardsToReview(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения карточек для повторения: %w", err)
 	}
+	if category != "" {
+		cardsToReview = filterCardsByCategory(cardsToReview, category)
+	}
 
 	s.logger.Info("карточки для повторения",
 		zap.Int64("user_id", userID),
@@ -53,7 +126,15 @@ func (s *Service) StartFlashcardSession(ctx context.Context, userID int64, userL
 				zap.Int64("user_id", userID))
 		}
 
-		newCards, err := s.flashcardRepo.GetNewCardsForUser(ctx, userID, userLevel, 10)
+		allowance, err := s.newCardAllowance(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if allowance == 0 {
+			return nil, ErrDailyPaceReached
+		}
+
+		newCards, candidates, err := s.pickNewCards(ctx, userID, userLevel, category, allowance)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка получения новых карточек: %w", err)
 		}
@@ -63,6 +144,14 @@ func (s *Service) StartFlashcardSession(ctx context.Context, userID int64, userL
 			zap.String("user_level", userLevel),
 			zap.Int("new_cards_count", len(newCards)))
 
+		if candidates == 0 {
+			// Пополнение генерирует слова без категории, поэтому тематический пул не пополняем
+			if category != "" {
+				return nil, ErrCategoryExhausted
+			}
+			newCards = s.handleExhaustedPool(ctx, userID, userLevel, allowance)
+		}
+
 		// Создаем UserFlashcard записи для новых карточек
 		for _, card := range newCards {
 			userFlashcard := &models.UserFlashcard{
@@ -105,35 +194,127 @@ func (s *Service) StartFlashcardSession(ctx context.Context, userID int64, userL
 		session.CardsToReview[i] = *card
 	}
 
+	if s.ExamplesEnabled() {
+		s.applyAlternateExamples(ctx, session.CardsToReview)
+	}
+
 	// Устанавливаем первую карточку
 	if len(session.CardsToReview) > 0 {
 		session.CurrentCard = &session.CardsToReview[0]
 	}
 
-	// Сохраняем активную сессию
-	s.activeSessions[userID] = session
+	// Сохраняем активную сессию; прежняя сессия, если была, больше не принимает ответы
+	active := &activeSession{session: session}
+	snapshot := snapshotSession(session)
+	s.sessionsMu.Lock()
+	previous := s.activeSessions[userID]
+	s.activeSessions[userID] = active
+	s.sessionsMu.Unlock()
+	if previous != nil {
+		previous.mu.Lock()
+		previous.ended = true
+		previous.mu.Unlock()
+	}
 
 	s.logger.Info("начата сессия карточек",
 		zap.Int64("user_id", userID),
 		zap.Int("cards_count", len(cardsToReview)))
 
-	return session, nil
+	return snapshot, nil
+}
+
+// lockSession находит и блокирует сессию пользователя; nil — активной сессии нет.
+// Разблокировать нужно через active.mu.Unlock.
+func (s *Service) lockSession(userID int64) *activeSession {
+	s.sessionsMu.RLock()
+	active := s.activeSessions[userID]
+	s.sessionsMu.RUnlock()
+	if active == nil {
+		return nil
+	}
+
+	active.mu.Lock()
+	if active.ended {
+		active.mu.Unlock()
+		return nil
+	}
+	return active
+}
+
+// snapshotSession копирует сессию вместе с карточками, чтобы обработчики читали ее
+// без блокировки. Текущая карточка сессии всегда CardsToReview[CardsCompleted].
+func snapshotSession(session *models.FlashcardSession) *models.FlashcardSession {
+	snapshot := *session
+	snapshot.CardsToReview = make([]models.UserFlashcard, len(session.CardsToReview))
+	for i, card := range session.CardsToReview {
+		snapshot.CardsToReview[i] = copyCard(card)
+	}
+	snapshot.RelearnCounts = maps.Clone(session.RelearnCounts)
+	snapshot.CurrentCard = nil
+	if session.CurrentCard != nil && session.CardsCompleted < len(snapshot.CardsToReview) {
+		snapshot.CurrentCard = &snapshot.CardsToReview[session.CardsCompleted]
+	}
+	return &snapshot
+}
+
+// copyCard копирует карточку вместе со словом, которое правит RefreshExample
+func copyCard(card models.UserFlashcard) models.UserFlashcard {
+	if card.Flashcard != nil {
+		flashcard := *card.Flashcard
+		card.Flashcard = &flashcard
+	}
+	return card
 }
 
-// GetCurrentSession получает текущую активную сессию пользователя
+// filterCardsByCategory оставляет карточки одной категории
+func filterCardsByCategory(cards []*models.UserFlashcard, category string) []*models.UserFlashcard {
+	filtered := make([]*models.UserFlashcard, 0, len(cards))
+	for _, card := range cards {
+		if card.Flashcard != nil && card.Flashcard.Category == category {
+			filtered = append(filtered, card)
+		}
+	}
+	return filtered
+}
+
+// ResumeOrStartSession возвращает незавершенную активную сессию, если она есть,
+// иначе начинает новую по категории category. Второе значение сообщает, была ли сессия продолжена.
+func (s *Service) ResumeOrStartSession(ctx context.Context, userID int64, userLevel, category string) (*models.FlashcardSession, bool, error) {
+	if session := s.GetCurrentSession(userID); session != nil && session.CurrentCard != nil {
+		s.logger.Info("продолжаем активную сессию карточек",
+			zap.Int64("user_id", userID),
+			zap.Int("cards_completed", session.CardsCompleted))
+		return session, true, nil
+	}
+
+	session, err := s.StartCategorySession(ctx, userID, userLevel, category)
+	return session, false, err
+}
+
+// GetCurrentSession возвращает копию текущей активной сессии пользователя (nil — сессии нет).
+// Изменения копии на сессию не влияют.
 func (s *Service) GetCurrentSession(userID int64) *models.FlashcardSession {
-	return s.activeSessions[userID]
+	active := s.lockSession(userID)
+	if active == nil {
+		return nil
+	}
+	defer active.mu.Unlock()
+	return snapshotSession(active.session)
 }
 
 // AnswerCard обрабатывает ответ пользователя на карточку
 func (s *Service) AnswerCard(ctx context.Context, userID int64, isCorrect bool, difficulty int) (*models.FlashcardAnswer, error) {
-	session := s.activeSessions[userID]
-	if session == nil {
-		return nil, fmt.Errorf("активная сессия не найдена")
+	// Сессия заблокирована на весь ответ: повторное нажатие кнопки не должно засчитать
+	// карточку дважды. Остальные пользователи этой блокировкой не задерживаются.
+	active := s.lockSession(userID)
+	if active == nil {
+		return nil, ErrNoActiveSession
 	}
+	defer active.mu.Unlock()
+	session := active.session
 
 	if session.CurrentCard == nil {
-		return nil, fmt.Errorf("текущая карточка не найдена")
+		return nil, ErrNoCurrentCard
 	}
 
 	currentCard := session.CurrentCard
@@ -148,31 +329,60 @@ func (s *Service) AnswerCard(ctx context.Context, userID int64, isCorrect bool,
 	// Вычисляем новую сложность и интервал повторения
 	answer := s.calculateSpacedRepetition(currentCard, isCorrect, difficulty)
 
+	if isEasyAnswer(isCorrect, difficulty) {
+		currentCard.EasyStreak++
+	} else {
+		currentCard.EasyStreak = 0
+	}
+
 	// Обновляем карточку
 	now := time.Now()
 	currentCard.LastReviewedAt = &now
 	currentCard.NextReviewAt = now.Add(answer.NextReviewIn)
 	currentCard.Difficulty = answer.Difficulty
 
+	wasLearned := currentCard.IsLearned
+
 	// Если карточка выучена (достаточно повторений и хорошая точность)
 	if currentCard.ReviewCount >= 3 &&
 		float64(currentCard.CorrectCount)/float64(currentCard.ReviewCount) >= 0.7 {
 		currentCard.IsLearned = true
 	}
 
+	// Слово, которое пользователь уже знает, не держим в очереди ради трех повторений
+	if !currentCard.IsLearned && currentCard.EasyStreak >= EasyFastTrackStreak {
+		currentCard.IsLearned = true
+		answer.FastTracked = true
+	}
+
+	// Запоминаем момент, когда слово стало выученным, для динамики словарного запаса
+	if currentCard.IsLearned && !wasLearned {
+		currentCard.LearnedAt = &now
+		answer.Learned = true
+	}
+
 	// Сохраняем изменения в БД
 	err := s.flashcardRepo.UpdateUserFlashcard(ctx, currentCard)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка обновления карточки: %w", err)
 	}
 
-	// Переходим к следующей карточке
+	// Карточку с ошибкой показываем еще раз ближе к концу сессии
+	if !isCorrect && s.requeueForRelearn(session) {
+		answer.RequeuedInSession = true
+	}
+
+	// Переходим к следующей карточке. Результат ответа сам сообщает, осталась ли
+	// она, чтобы обработчик не запрашивал сессию, которую мы могли только что завершить.
 	session.CardsCompleted++
 	if session.CardsCompleted < len(session.CardsToReview) {
 		session.CurrentCard = &session.CardsToReview[session.CardsCompleted]
+		answer.HasMoreCards = true
+		next := copyCard(*session.CurrentCard)
+		answer.NextCard = &next
 	} else {
 		// Сессия завершена - сохраняем прогресс и очищаем
-		s.EndSession(userID)
+		s.endSessionLocked(userID, active)
 	}
 
 	s.logger.Info("ответ на карточку обработан",
@@ -184,6 +394,44 @@ func (s *Service) AnswerCard(ctx context.Context, userID int64, isCorrect bool,
 	return answer, nil
 }
 
+// SkipCard откладывает текущую карточку в конец сессии, не записывая ответ
+// и не меняя статистику интервального повторения. Возвращает false, если
+// в сессии осталась только текущая карточка и пропускать некуда.
+func (s *Service) SkipCard(userID int64) (bool, error) {
+	active := s.lockSession(userID)
+	if active == nil {
+		return false, ErrNoActiveSession
+	}
+	defer active.mu.Unlock()
+
+	session := active.session
+	if session.CurrentCard == nil {
+		return false, ErrNoCurrentCard
+	}
+
+	idx := session.CardsCompleted
+	last := len(session.CardsToReview) - 1
+	if idx >= last {
+		return false, nil
+	}
+
+	skipped := session.CardsToReview[idx]
+	copy(session.CardsToReview[idx:], session.CardsToReview[idx+1:])
+	session.CardsToReview[last] = skipped
+	session.CurrentCard = &session.CardsToReview[idx]
+
+	s.logger.Info("карточка отложена в конец сессии",
+		zap.Int64("user_id", userID),
+		zap.String("word", skipped.Flashcard.Word))
+
+	return true, nil
+}
+
+// isEasyAnswer проверяет, что пользователь ответил верно и оценил карточку как легкую
+func isEasyAnswer(isCorrect bool, userDifficulty int) bool {
+	return isCorrect && userDifficulty <= easyAnswerDifficulty
+}
+
 // calculateSpacedRepetition вычисляет интервал повторения по алгоритму SM-2
 func (s *Service) calculateSpacedRepetition(card *models.UserFlashcard, isCorrect bool, userDifficulty int) *models.FlashcardAnswer {
 	// Алгоритм основан на SuperMemo SM-2
@@ -193,27 +441,13 @@ func (s *Service) calculateSpacedRepetition(card *models.UserFlashcard, isCorrec
 
 	if isCorrect {
 		// Увеличиваем сложность при правильном ответе
-		newDifficulty = card.Difficulty + 1
-		if newDifficulty > 5 {
-			newDifficulty = 5
-		}
+		newDifficulty = min(card.Difficulty+1, s.repetition.maxDifficulty())
 
-		// Интервалы для правильных ответов (в днях)
-		switch newDifficulty {
-		case 0, 1:
-			interval = 1 * 24 * time.Hour // 1 день
-		case 2:
-			interval = 3 * 24 * time.Hour // 3 дня
-		case 3:
-			interval = 7 * 24 * time.Hour // 1 неделя
-		case 4:
-			interval = 14 * 24 * time.Hour // 2 недели
-		case 5:
-			interval = 30 * 24 * time.Hour // 1 месяц
-		}
+		// Интервал для правильного ответа берется из таблицы интервалов
+		interval = s.repetition.interval(newDifficulty)
 
 		// Корректируем интервал на основе пользовательской оценки сложности
-		if userDifficulty <= 2 { // Легко
+		if userDifficulty <= easyAnswerDifficulty { // Легко
 			interval = time.Duration(float64(interval) * 1.5)
 		} else if userDifficulty >= 4 { // Сложно
 			interval = time.Duration(float64(interval) * 0.7)
@@ -222,12 +456,11 @@ func (s *Service) calculateSpacedRepetition(card *models.UserFlashcard, isCorrec
 	} else {
 		// При неправильном ответе сбрасываем прогресс
 		newDifficulty = max(0, card.Difficulty-1)
-		interval = 10 * time.Minute // Повторить через 10 минут
+		interval = s.repetition.WrongInterval
 	}
 
-	// Добавляем случайность ±20% для избежания кучности
-	randomFactor := 0.8 + (0.4 * float64(time.Now().UnixNano()%100) / 100.0)
-	interval = time.Duration(float64(interval) * randomFactor)
+	// Добавляем случайный разброс, чтобы повторения не скапливались
+	interval = s.repetition.jitter(interval, s.random())
 
 	return &models.FlashcardAnswer{
 		IsCorrect:    isCorrect,
@@ -252,11 +485,12 @@ func (s *Service) GetUserStats(ctx context.Context, userID int64) (map[string]in
 	}
 
 	// Проверяем активную сессию
-	session := s.activeSessions[userID]
-	if session != nil {
+	if active := s.lockSession(userID); active != nil {
+		session := active.session
 		stats["active_session"] = true
 		stats["session_progress"] = fmt.Sprintf("%d/%d", session.CardsCompleted, len(session.CardsToReview))
 		stats["session_accuracy"] = float64(session.CorrectAnswers) / math.Max(float64(session.CardsCompleted), 1) * 100
+		active.mu.Unlock()
 	} else {
 		stats["active_session"] = false
 	}
@@ -264,38 +498,65 @@ func (s *Service) GetUserStats(ctx context.Context, userID int64) (map[string]in
 	return stats, nil
 }
 
+// LearnedWordsCount возвращает число выученных пользователем слов
+func (s *Service) LearnedWordsCount(ctx context.Context, userID int64) (int, error) {
+	count, err := s.flashcardRepo.GetLearnedWordsCount(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения количества выученных слов: %w", err)
+	}
+	return count, nil
+}
+
 // EndSession завершает активную сессию пользователя
 func (s *Service) EndSession(userID int64) {
-	session := s.activeSessions[userID]
-	if session != nil {
-		// Сохраняем прогресс всех карточек в сессии
-		for i := range session.CardsToReview {
-			card := &session.CardsToReview[i]
-			if card.ReviewCount > 0 {
-				// Обновляем карточку в БД
-				err := s.flashcardRepo.UpdateUserFlashcard(context.Background(), card)
-				if err != nil {
-					s.logger.Error("ошибка сохранения прогресса карточки при завершении сессии",
-						zap.Int64("user_id", userID),
-						zap.String("word", card.Flashcard.Word),
-						zap.Error(err))
-				}
+	active := s.lockSession(userID)
+	if active == nil {
+		return
+	}
+	defer active.mu.Unlock()
+
+	s.endSessionLocked(userID, active)
+}
+
+// endSessionLocked удаляет сессию и сохраняет прогресс ее карточек; вызывается под active.mu.
+// Общая блокировка берется только на удаление из карты, запись в БД идет без нее.
+func (s *Service) endSessionLocked(userID int64, active *activeSession) {
+	s.sessionsMu.Lock()
+	if s.activeSessions[userID] == active {
+		delete(s.activeSessions, userID)
+	}
+	s.sessionsMu.Unlock()
+	active.ended = true
+
+	// Сохраняем прогресс всех карточек в сессии
+	for i := range active.session.CardsToReview {
+		card := &active.session.CardsToReview[i]
+		if card.ReviewCount > 0 {
+			// Обновляем карточку в БД
+			err := s.flashcardRepo.UpdateUserFlashcard(context.Background(), card)
+			if err != nil {
+				s.logger.Error("ошибка сохранения прогресса карточки при завершении сессии",
+					zap.Int64("user_id", userID),
+					zap.String("word", card.Flashcard.Word),
+					zap.Error(err))
 			}
 		}
 	}
 
-	delete(s.activeSessions, userID)
 	s.logger.Info("сессия карточек завершена", zap.Int64("user_id", userID))
 }
 
 // GetSessionProgress получает прогресс текущей сессии
 func (s *Service) GetSessionProgress(userID int64) map[string]interface{} {
-	session := s.activeSessions[userID]
-	if session == nil {
+	active := s.lockSession(userID)
+	if active == nil {
 		return map[string]interface{}{
 			"active": false,
 		}
 	}
+	defer active.mu.Unlock()
+
+	session := active.session
 
 	progress := map[string]interface{}{
 		"active":      true,
@@ -324,21 +585,15 @@ func (s *Service) GetRecommendedStudyTime(ctx context.Context, userID int64) (st
 	if count == 0 {
 		// Проверим когда будет доступна следующая карточка
 		nextCard, err := s.flashcardRepo.GetNextCardToReview(ctx, userID)
-		if err != nil {
+		if errors.Is(err, store.ErrFlashcardNotFound) {
 			return "Сегодня нет карточек для повторения! 🎉", nil
 		}
+		if err != nil {
+			return "", fmt.Errorf("ошибка получения следующей карточки: %w", err)
+		}
 
-		if nextCard != nil {
-			timeUntilNext := time.Until(nextCard.NextReviewAt)
-			if timeUntilNext > 0 {
-				if timeUntilNext < time.Hour {
-					return fmt.Sprintf("Следующая карточка будет доступна через %d мин", int(timeUntilNext.Minutes())), nil
-				} else if timeUntilNext < 24*time.Hour {
-					return fmt.Sprintf("Следующая карточка будет доступна через %d ч", int(timeUntilNext.Hours())), nil
-				} else {
-					return fmt.Sprintf("Следующая карточка будет доступна через %d дн", int(timeUntilNext.Hours()/24)), nil
-				}
-			}
+		if timeUntilNext := time.Until(nextCard.NextReviewAt); timeUntilNext > 0 {
+			return "Следующая карточка будет доступна " + FormatTimeUntil(timeUntilNext), nil
 		}
 
 		return "Сегодня нет карточек для повторения! 🎉", nil
@@ -353,6 +608,52 @@ func (s *Service) GetRecommendedStudyTime(ctx context.Context, userID int64) (st
 	return fmt.Sprintf("Рекомендуемое время изучения: %d мин (%d карточек)", estimatedMinutes, count), nil
 }
 
+// FormatTimeUntil форматирует оставшееся время в человекочитаемом виде: «через 5 мин», «через 3 ч», «через 2 дн»
+func FormatTimeUntil(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("через %d мин", int(d.Minutes()))
+	} else if d < 24*time.Hour {
+		return fmt.Sprintf("через %d ч", int(d.Hours()))
+	}
+	return fmt.Sprintf("через %d дн", int(d.Hours()/24))
+}
+
+// GetWordSchedule возвращает прогресс пользователя по слову.
+// Если пользователь еще не начинал учить слово, возвращает nil.
+func (s *Service) GetWordSchedule(ctx context.Context, userID int64, word string) (*models.UserFlashcard, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, nil
+	}
+
+	userCard, err := s.flashcardRepo.GetUserFlashcardByWord(ctx, userID, word)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания слова: %w", err)
+	}
+	return userCard, nil
+}
+
+// GetFlashcard возвращает карточку по ID
+func (s *Service) GetFlashcard(ctx context.Context, flashcardID int64) (*models.Flashcard, error) {
+	card, err := s.flashcardRepo.GetFlashcardByID(ctx, flashcardID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения карточки: %w", err)
+	}
+	return card, nil
+}
+
+// SavePronunciationScore запоминает оценку произношения слова, если она лучше прежней
+func (s *Service) SavePronunciationScore(ctx context.Context, userID, flashcardID int64, score int) error {
+	if err := s.flashcardRepo.SavePronunciationScore(ctx, userID, flashcardID, score); err != nil {
+		return err
+	}
+	s.logger.Info("сохранена оценка произношения",
+		zap.Int64("user_id", userID),
+		zap.Int64("flashcard_id", flashcardID),
+		zap.Int("score", score))
+	return nil
+}
+
 // max возвращает максимум из двух чисел
 func max(a, b int) int {
 	if a > b {
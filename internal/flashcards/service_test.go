@@ -0,0 +1,450 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// fakeFlashcardRepo отдает фиксированный набор карточек и считает запросы
+type fakeFlashcardRepo struct {
+	store.FlashcardRepository
+	cards       []*models.UserFlashcard
+	reviewCalls int
+}
+
+func (r *fakeFlashcardRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	r.reviewCalls++
+	return r.cards, nil
+}
+
+func (r *fakeFlashcardRepo) UpdateUserFlashcard(ctx context.Context, card *models.UserFlashcard) error {
+	for i, stored := range r.cards {
+		if stored.ID == card.ID {
+			saved := *card
+			r.cards[i] = &saved
+		}
+	}
+	return nil
+}
+
+func newTestCards(words ...string) []*models.UserFlashcard {
+	cards := make([]*models.UserFlashcard, len(words))
+	for i, word := range words {
+		cards[i] = &models.UserFlashcard{
+			ID:        int64(i + 1),
+			Flashcard: &models.Flashcard{ID: int64(i + 1), Word: word},
+		}
+	}
+	return cards
+}
+
+func TestResumeOrStartSessionResumesActiveSession(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple", "house")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	ctx := context.Background()
+
+	started, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := s.AnswerCard(ctx, 1, true, 3); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	// За время сессии набор карточек к повторению изменился
+	repo.cards = newTestCards("river")
+
+	// Экран статистики → «Начать изучение» при активной сессии
+	session, resumed, err := s.ResumeOrStartSession(ctx, 1, models.LevelBeginner, "")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !resumed {
+		t.Error("ожидалось продолжение активной сессии")
+	}
+	if session.SessionStarted != started.SessionStarted || session.CardsCompleted != 1 {
+		t.Error("ожидалась та же сессия, а не новая")
+	}
+	if session.CurrentCard.Flashcard.Word != "house" {
+		t.Errorf("ожидалась текущая карточка house, получено %s", session.CurrentCard.Flashcard.Word)
+	}
+	if repo.reviewCalls != 1 {
+		t.Errorf("ожидался 1 запрос карточек, получено %d", repo.reviewCalls)
+	}
+}
+
+func TestResumeOrStartSessionStartsWhenFinished(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	ctx := context.Background()
+
+	if _, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	s.activeSessions[1].session.CurrentCard = nil
+
+	session, resumed, err := s.ResumeOrStartSession(ctx, 1, models.LevelBeginner, "")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if resumed {
+		t.Error("завершенная сессия не должна продолжаться")
+	}
+	if session == nil || session.CurrentCard == nil {
+		t.Error("ожидалась новая сессия с текущей карточкой")
+	}
+}
+
+func TestSkipCardMovesCurrentCardToEnd(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple", "house", "river")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	skipped, err := s.SkipCard(1)
+	if err != nil || !skipped {
+		t.Fatalf("ожидался успешный пропуск, получено %v, %v", skipped, err)
+	}
+	session := s.GetCurrentSession(1)
+
+	var order []string
+	for _, card := range session.CardsToReview {
+		order = append(order, card.Flashcard.Word)
+	}
+	if got := strings.Join(order, ","); got != "house,river,apple" {
+		t.Errorf("ожидался порядок house,river,apple, получено %s", got)
+	}
+	if session.CurrentCard.Flashcard.Word != "house" {
+		t.Errorf("ожидалась текущая карточка house, получено %s", session.CurrentCard.Flashcard.Word)
+	}
+	if session.CardsCompleted != 0 {
+		t.Errorf("пропуск не должен засчитываться как ответ, получено %d", session.CardsCompleted)
+	}
+	apple := session.CardsToReview[2]
+	if apple.ReviewCount != 0 || apple.LastReviewedAt != nil {
+		t.Error("пропуск не должен менять статистику карточки")
+	}
+}
+
+func TestSkipCardLastRemainingCard(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	if _, err := s.SkipCard(1); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("без активной сессии ожидалась ErrNoActiveSession, получено %v", err)
+	}
+
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	skipped, err := s.SkipCard(1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if skipped {
+		t.Error("единственную оставшуюся карточку нельзя пропустить")
+	}
+}
+
+// answerSingleCard проходит сессию из одной карточки одним ответом
+func answerSingleCard(t *testing.T, s *Service, isCorrect bool, difficulty int) *models.FlashcardAnswer {
+	t.Helper()
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	answer, err := s.AnswerCard(context.Background(), 1, isCorrect, difficulty)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return answer
+}
+
+func TestAnswerCardEasyStreakFastTracksToLearned(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	first := answerSingleCard(t, s, true, 1)
+	if first.FastTracked || repo.cards[0].IsLearned {
+		t.Fatal("одного ответа \"легко\" недостаточно для выученного слова")
+	}
+	if repo.cards[0].EasyStreak != 1 {
+		t.Errorf("ожидалась серия 1, получено %d", repo.cards[0].EasyStreak)
+	}
+
+	second := answerSingleCard(t, s, true, 1)
+	if !second.FastTracked {
+		t.Error("ожидался досрочный перевод в выученные")
+	}
+	if !repo.cards[0].IsLearned {
+		t.Errorf("карточка должна быть выучена после %d ответов \"легко\" подряд", EasyFastTrackStreak)
+	}
+}
+
+func TestAnswerCardEasyStreakResetsOnOtherAnswer(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	answerSingleCard(t, s, true, 1)
+	answerSingleCard(t, s, false, 5)
+	if repo.cards[0].EasyStreak != 0 {
+		t.Fatalf("ошибка должна сбрасывать серию, получено %d", repo.cards[0].EasyStreak)
+	}
+
+	answer := answerSingleCard(t, s, true, 1)
+	if answer.FastTracked || repo.cards[0].IsLearned {
+		t.Error("серия \"легко\" прервана ошибкой — карточка не должна считаться выученной")
+	}
+}
+
+func TestAnswerCardRecordsLearnedAtOnce(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	answerSingleCard(t, s, true, 1)
+	if repo.cards[0].LearnedAt != nil {
+		t.Fatal("слово еще не выучено — момент выучивания не должен записываться")
+	}
+
+	answerSingleCard(t, s, true, 1)
+	learnedAt := repo.cards[0].LearnedAt
+	if learnedAt == nil {
+		t.Fatal("ожидался момент выучивания слова")
+	}
+
+	answerSingleCard(t, s, true, 1)
+	if repo.cards[0].LearnedAt == nil || !repo.cards[0].LearnedAt.Equal(*learnedAt) {
+		t.Error("повторный ответ по выученному слову не должен менять момент выучивания")
+	}
+}
+
+func TestAnswerCardReportsRemainingCards(t *testing.T) {
+	repo := &fakeFlashcardRepo{cards: newTestCards("apple", "house")}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	if _, err := s.StartFlashcardSession(context.Background(), 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	first, err := s.AnswerCard(context.Background(), 1, true, 3)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !first.HasMoreCards || first.NextCard == nil || first.NextCard.Flashcard.Word != "house" {
+		t.Fatalf("после первой карточки ожидалась следующая, получено %+v", first)
+	}
+
+	last, err := s.AnswerCard(context.Background(), 1, false, 5)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if last.HasMoreCards || last.NextCard != nil {
+		t.Errorf("после последней карточки сессия должна завершиться, получено %+v", last)
+	}
+	if s.GetCurrentSession(1) != nil {
+		t.Error("сессия должна быть завершена после последнего ответа")
+	}
+	if _, err := s.AnswerCard(context.Background(), 1, true, 3); !errors.Is(err, ErrNoActiveSession) {
+		t.Errorf("ответ после завершения ожидал ErrNoActiveSession, получено %v", err)
+	}
+}
+
+// concurrentFlashcardRepo отдает каждому пользователю свои карточки; безопасен для горутин
+type concurrentFlashcardRepo struct {
+	store.FlashcardRepository
+	mu      sync.Mutex
+	updates map[int64]int
+}
+
+func (r *concurrentFlashcardRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	cards := newTestCards("apple", "house")
+	for _, card := range cards {
+		card.UserID = userID
+	}
+	return cards, nil
+}
+
+func (r *concurrentFlashcardRepo) UpdateUserFlashcard(ctx context.Context, card *models.UserFlashcard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates[card.UserID]++
+	return nil
+}
+
+// Запускать с -race: ответы разных пользователей обрабатываются параллельно
+func TestAnswerCardConcurrentUsers(t *testing.T) {
+	const users = 50
+	repo := &concurrentFlashcardRepo{updates: make(map[int64]int)}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, users*3)
+	for userID := int64(1); userID <= users; userID++ {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			if _, err := s.StartFlashcardSession(ctx, userID, models.LevelBeginner); err != nil {
+				errs <- err
+				return
+			}
+			for range 2 {
+				if _, err := s.AnswerCard(ctx, userID, true, 3); err != nil {
+					errs <- err
+				}
+				s.GetSessionProgress(userID)
+			}
+		}(userID)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("неожиданная ошибка: %v", err)
+	}
+	for userID := int64(1); userID <= users; userID++ {
+		if s.GetCurrentSession(userID) != nil {
+			t.Errorf("сессия пользователя %d должна завершиться после последней карточки", userID)
+		}
+		// Два ответа плюс сохранение обеих карточек при завершении сессии
+		if repo.updates[userID] != 4 {
+			t.Errorf("пользователь %d: ожидалось 4 сохранения, получено %d", userID, repo.updates[userID])
+		}
+	}
+}
+
+// blockingFlashcardRepo задерживает сохранение карточек пользователя 1, пока не закрыт release
+type blockingFlashcardRepo struct {
+	concurrentFlashcardRepo
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingFlashcardRepo) UpdateUserFlashcard(ctx context.Context, card *models.UserFlashcard) error {
+	if card.UserID == 1 {
+		close(r.blocked)
+		<-r.release
+	}
+	return r.concurrentFlashcardRepo.UpdateUserFlashcard(ctx, card)
+}
+
+func TestAnswerCardDoesNotBlockOtherUsersDuringSave(t *testing.T) {
+	repo := &blockingFlashcardRepo{
+		concurrentFlashcardRepo: concurrentFlashcardRepo{updates: make(map[int64]int)},
+		blocked:                 make(chan struct{}),
+		release:                 make(chan struct{}),
+	}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	ctx := context.Background()
+	for userID := int64(1); userID <= 2; userID++ {
+		if _, err := s.StartFlashcardSession(ctx, userID, models.LevelBeginner); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.AnswerCard(ctx, 1, true, 3)
+		done <- err
+	}()
+	<-repo.blocked
+
+	// Пока карточка пользователя 1 сохраняется, пользователь 2 отвечает без ожидания
+	if _, err := s.AnswerCard(ctx, 2, true, 3); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if progress := s.GetSessionProgress(2); progress["completed"] != 1 {
+		t.Errorf("ожидалась одна пройденная карточка пользователя 2, получено %v", progress["completed"])
+	}
+
+	close(repo.release)
+	if err := <-done; err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+}
+
+// Запускать с -race: обработчики читают копию сессии, пока ответ меняет ее карточки
+func TestGetCurrentSessionIsSafeDuringAnswers(t *testing.T) {
+	repo := &concurrentFlashcardRepo{updates: make(map[int64]int)}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetRelearnGap(1)
+	ctx := context.Background()
+	if _, err := s.StartFlashcardSession(ctx, 1, models.LevelBeginner); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			session := s.GetCurrentSession(1)
+			if session == nil {
+				return
+			}
+			if session.CurrentCard != nil {
+				_ = session.CurrentCard.ReviewCount + len(session.CurrentCard.Flashcard.Word)
+			}
+			for _, card := range session.CardsToReview {
+				_ = card.Difficulty
+			}
+		}
+	}()
+
+	for range 6 {
+		if _, err := s.AnswerCard(ctx, 1, false, 5); err != nil {
+			break
+		}
+	}
+	wg.Wait()
+}
+
+// nextCardRepo без карточек на сегодня; следующую карточку отдает по заданному результату
+type nextCardRepo struct {
+	store.FlashcardRepository
+	next *models.UserFlashcard
+	err  error
+}
+
+func (r *nextCardRepo) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
+	return nil, nil
+}
+
+func (r *nextCardRepo) GetNextCardToReview(ctx context.Context, userID int64) (*models.UserFlashcard, error) {
+	return r.next, r.err
+}
+
+func TestGetRecommendedStudyTimeWithoutDueCards(t *testing.T) {
+	notFound := fmt.Errorf("%w: у пользователя 1 нет карточек на повторение", store.ErrFlashcardNotFound)
+	tests := []struct {
+		name    string
+		repo    *nextCardRepo
+		want    string
+		wantErr bool
+	}{
+		{"карточек нет", &nextCardRepo{err: notFound}, "Сегодня нет карточек", false},
+		{"следующая позже", &nextCardRepo{next: &models.UserFlashcard{NextReviewAt: time.Now().Add(3 * time.Hour)}}, "Следующая карточка будет доступна", false},
+		{"ошибка базы", &nextCardRepo{err: errors.New("connection refused")}, "", true},
+	}
+
+	for _, tt := range tests {
+		s := NewService(tt.repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+		got, err := s.GetRecommendedStudyTime(context.Background(), 1)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: неожиданная ошибка %v", tt.name, err)
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: ожидалось %q, получено %q", tt.name, tt.want, got)
+		}
+	}
+}
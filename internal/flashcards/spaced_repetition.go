@@ -0,0 +1,58 @@
+package flashcards
+
+import "time"
+
+// SpacedRepetitionConfig интервалы интервального повторения
+type SpacedRepetitionConfig struct {
+	// Intervals интервал после верного ответа для сложности 1, 2, ...;
+	// число интервалов — максимальная сложность карточки
+	Intervals []time.Duration
+	// WrongInterval через сколько повторить карточку после ошибки
+	WrongInterval time.Duration
+	// Jitter случайное отклонение интервала в долях (0.2 — ±20%), чтобы повторения не скапливались в один день
+	Jitter float64
+}
+
+// DefaultSpacedRepetitionConfig интервалы по умолчанию: 1, 3, 7, 14 и 30 дней, ±20%
+var DefaultSpacedRepetitionConfig = SpacedRepetitionConfig{
+	Intervals: []time.Duration{
+		1 * 24 * time.Hour,  // 1 день
+		3 * 24 * time.Hour,  // 3 дня
+		7 * 24 * time.Hour,  // 1 неделя
+		14 * 24 * time.Hour, // 2 недели
+		30 * 24 * time.Hour, // 1 месяц
+	},
+	WrongInterval: 10 * time.Minute,
+	Jitter:        0.2,
+}
+
+// withDefaults заполняет незаданные значения значениями по умолчанию
+func (c SpacedRepetitionConfig) withDefaults() SpacedRepetitionConfig {
+	if len(c.Intervals) == 0 {
+		c.Intervals = DefaultSpacedRepetitionConfig.Intervals
+	}
+	if c.WrongInterval <= 0 {
+		c.WrongInterval = DefaultSpacedRepetitionConfig.WrongInterval
+	}
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		c.Jitter = DefaultSpacedRepetitionConfig.Jitter
+	}
+	return c
+}
+
+// maxDifficulty максимальная сложность карточки
+func (c SpacedRepetitionConfig) maxDifficulty() int {
+	return len(c.Intervals)
+}
+
+// interval возвращает интервал после верного ответа для сложности difficulty
+func (c SpacedRepetitionConfig) interval(difficulty int) time.Duration {
+	index := min(max(difficulty, 1), len(c.Intervals)) - 1
+	return c.Intervals[index]
+}
+
+// jitter применяет к интервалу случайное отклонение; random возвращает число из [0, 1)
+func (c SpacedRepetitionConfig) jitter(interval time.Duration, random float64) time.Duration {
+	factor := 1 - c.Jitter + 2*c.Jitter*random
+	return time.Duration(float64(interval) * factor)
+}
@@ -0,0 +1,120 @@
+package flashcards
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+const day = 24 * time.Hour
+
+// newRepetitionService сервис без разброса интервалов
+func newRepetitionService(cfg SpacedRepetitionConfig) *Service {
+	s := NewService(nil, cfg, zap.NewNop())
+	s.random = func() float64 { return 0.5 } // середина диапазона: множитель 1
+	return s
+}
+
+func TestSpacedRepetitionDefaultIntervals(t *testing.T) {
+	s := newRepetitionService(DefaultSpacedRepetitionConfig)
+
+	// Ответ "нормально" (3) не меняет интервал из таблицы
+	tests := []struct {
+		difficulty     int
+		wantDifficulty int
+		want           time.Duration
+	}{
+		{0, 1, 1 * day},
+		{1, 2, 3 * day},
+		{2, 3, 7 * day},
+		{3, 4, 14 * day},
+		{4, 5, 30 * day},
+		{5, 5, 30 * day}, // максимальная сложность не растет
+	}
+	for _, tt := range tests {
+		answer := s.calculateSpacedRepetition(&models.UserFlashcard{Difficulty: tt.difficulty}, true, 3)
+		if answer.Difficulty != tt.wantDifficulty || answer.NextReviewIn != tt.want {
+			t.Errorf("сложность %d: ожидалось %d и %v, получено %d и %v",
+				tt.difficulty, tt.wantDifficulty, tt.want, answer.Difficulty, answer.NextReviewIn)
+		}
+	}
+}
+
+func TestSpacedRepetitionWrongAnswer(t *testing.T) {
+	s := newRepetitionService(DefaultSpacedRepetitionConfig)
+
+	answer := s.calculateSpacedRepetition(&models.UserFlashcard{Difficulty: 3}, false, 3)
+	if answer.Difficulty != 2 || answer.NextReviewIn != 10*time.Minute {
+		t.Errorf("ожидалась сложность 2 и повтор через 10 минут, получено %d и %v", answer.Difficulty, answer.NextReviewIn)
+	}
+
+	answer = s.calculateSpacedRepetition(&models.UserFlashcard{Difficulty: 0}, false, 3)
+	if answer.Difficulty != 0 {
+		t.Errorf("сложность не должна уходить ниже 0, получено %d", answer.Difficulty)
+	}
+}
+
+func TestSpacedRepetitionCustomConfig(t *testing.T) {
+	s := newRepetitionService(SpacedRepetitionConfig{
+		Intervals:     []time.Duration{2 * day, 5 * day, 10 * day},
+		WrongInterval: time.Hour,
+	})
+
+	tests := []struct {
+		difficulty     int
+		wantDifficulty int
+		want           time.Duration
+	}{
+		{0, 1, 2 * day},
+		{1, 2, 5 * day},
+		{2, 3, 10 * day},
+		{3, 3, 10 * day},
+		// Сложность из прежней, более длинной таблицы приводится к новой
+		{5, 3, 10 * day},
+	}
+	for _, tt := range tests {
+		answer := s.calculateSpacedRepetition(&models.UserFlashcard{Difficulty: tt.difficulty}, true, 3)
+		if answer.Difficulty != tt.wantDifficulty || answer.NextReviewIn != tt.want {
+			t.Errorf("сложность %d: ожидалось %d и %v, получено %d и %v",
+				tt.difficulty, tt.wantDifficulty, tt.want, answer.Difficulty, answer.NextReviewIn)
+		}
+	}
+
+	if answer := s.calculateSpacedRepetition(&models.UserFlashcard{Difficulty: 2}, false, 3); answer.NextReviewIn != time.Hour {
+		t.Errorf("ожидался повтор через час после ошибки, получено %v", answer.NextReviewIn)
+	}
+}
+
+func TestSpacedRepetitionJitterBounds(t *testing.T) {
+	s := NewService(nil, DefaultSpacedRepetitionConfig, zap.NewNop())
+	card := &models.UserFlashcard{Difficulty: 2}
+
+	for _, tt := range []struct {
+		random float64
+		want   time.Duration
+	}{
+		{0, time.Duration(float64(7*day) * 0.8)},
+		{0.5, 7 * day},
+		{0.999, time.Duration(float64(7*day) * (0.8 + 0.4*0.999))},
+	} {
+		s.random = func() float64 { return tt.random }
+		if got := s.calculateSpacedRepetition(card, true, 3).NextReviewIn; got != tt.want {
+			t.Errorf("random %v: ожидалось %v, получено %v", tt.random, tt.want, got)
+		}
+	}
+}
+
+func TestSpacedRepetitionConfigDefaults(t *testing.T) {
+	cfg := SpacedRepetitionConfig{Jitter: 1.5}.withDefaults()
+	if len(cfg.Intervals) != 5 || cfg.WrongInterval != 10*time.Minute || cfg.Jitter != 0.2 {
+		t.Errorf("ожидались интервалы по умолчанию, получено %+v", cfg)
+	}
+
+	// Нулевой разброс — допустимая настройка, а не пропущенное значение
+	if cfg := (SpacedRepetitionConfig{Jitter: 0}).withDefaults(); cfg.Jitter != 0 {
+		t.Errorf("ожидался нулевой разброс, получено %v", cfg.Jitter)
+	}
+}
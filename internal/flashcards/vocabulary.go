@@ -0,0 +1,62 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+// DefaultVocabularyWeeks за сколько последних недель показывается рост словарного запаса
+const DefaultVocabularyWeeks = 8
+
+// VocabularyTrend возвращает размер словарного запаса и число слов, выученных
+// за каждую из последних weeks недель (текущая неделя — последняя)
+func (s *Service) VocabularyTrend(ctx context.Context, userID int64, weeks int) (*models.VocabularyTrend, error) {
+	if weeks <= 0 {
+		weeks = DefaultVocabularyWeeks
+	}
+
+	total, err := s.flashcardRepo.GetLearnedWordsCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	first := WeekStart(s.now().In(s.loc)).AddDate(0, 0, -7*(weeks-1))
+	learned, err := s.flashcardRepo.GetLearnedTimes(ctx, userID, first)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения динамики словарного запаса: %w", err)
+	}
+
+	return &models.VocabularyTrend{
+		Total: total,
+		Weeks: learnedPerWeek(learned, first, weeks, s.loc),
+	}, nil
+}
+
+// learnedPerWeek раскладывает моменты выучивания слов по неделям, начиная с first
+func learnedPerWeek(learned []time.Time, first time.Time, weeks int, loc *time.Location) []models.VocabularyWeek {
+	result := make([]models.VocabularyWeek, weeks)
+	for i := range result {
+		result[i].Start = first.AddDate(0, 0, 7*i)
+	}
+
+	for _, t := range learned {
+		start := WeekStart(t.In(loc))
+		for i := range result {
+			if result[i].Start.Equal(start) {
+				result[i].Learned++
+				break
+			}
+		}
+	}
+	return result
+}
+
+// WeekStart возвращает полночь понедельника недели, в которую попадает t
+func WeekStart(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -daysSinceMonday).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,89 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+
+	"go.uber.org/zap"
+)
+
+// fakeVocabularyRepo отдает моменты выучивания слов после since
+type fakeVocabularyRepo struct {
+	store.FlashcardRepository
+	learned []time.Time
+	since   time.Time
+}
+
+func (r *fakeVocabularyRepo) GetLearnedWordsCount(ctx context.Context, userID int64) (int, error) {
+	return 120, nil
+}
+
+func (r *fakeVocabularyRepo) GetLearnedTimes(ctx context.Context, userID int64, since time.Time) ([]time.Time, error) {
+	r.since = since
+	var result []time.Time
+	for _, t := range r.learned {
+		if !t.Before(since) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		day  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},   // понедельник
+		{time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)}, // пятница
+		{time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)}, // воскресенье
+	}
+	for _, tt := range tests {
+		if got := WeekStart(tt.day); !got.Equal(tt.want) {
+			t.Errorf("%s: ожидалось %s, получено %s", tt.day.Weekday(), tt.want, got)
+		}
+	}
+}
+
+func TestVocabularyTrendGroupsByWeekInLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := &fakeVocabularyRepo{learned: []time.Time{
+		time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC),   // раньше периода
+		time.Date(2026, 9, 28, 9, 0, 0, 0, time.UTC),   // первая неделя периода
+		time.Date(2026, 10, 4, 22, 0, 0, 0, time.UTC),  // в Москве уже понедельник 5 октября
+		time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC),  // неделя с 5 октября
+		time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC), // текущая неделя
+	}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetLocation(moscow)
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, moscow) }
+
+	trend, err := s.VocabularyTrend(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if trend.Total != 120 {
+		t.Errorf("ожидалось 120 слов всего, получено %d", trend.Total)
+	}
+	wantSince := time.Date(2026, 9, 28, 0, 0, 0, 0, moscow)
+	if !repo.since.Equal(wantSince) {
+		t.Errorf("ожидалась граница %s, получено %s", wantSince, repo.since)
+	}
+
+	want := []int{1, 2, 1}
+	if len(trend.Weeks) != len(want) {
+		t.Fatalf("ожидалось %d недель, получено %d", len(want), len(trend.Weeks))
+	}
+	for i, week := range trend.Weeks {
+		if week.Learned != want[i] {
+			t.Errorf("неделя с %s: ожидалось %d слов, получено %d", week.Start.Format("02.01"), want[i], week.Learned)
+		}
+	}
+	if got := trend.Weeks[2].Start; !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, moscow)) {
+		t.Errorf("последней ожидалась текущая неделя, получено %s", got)
+	}
+}
@@ -0,0 +1,45 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+
+	"lingua-ai/pkg/models"
+)
+
+// WeeklyTargetSource возвращает недельную цель пользователя по выученным словам (0 — не задана)
+type WeeklyTargetSource interface {
+	GetWeeklyWordTarget(ctx context.Context, userID int64) (int, error)
+}
+
+// SetWeeklyTargetSource задает источник недельных целей.
+// Пока источник не задан, цель считается не заданной.
+func (s *Service) SetWeeklyTargetSource(source WeeklyTargetSource) {
+	s.weeklyTarget = source
+}
+
+// WeeklyProgress возвращает недельную цель пользователя и сколько слов выучено с начала
+// текущей недели. Неделя начинается в полночь понедельника часового пояса сервиса.
+func (s *Service) WeeklyProgress(ctx context.Context, userID int64) (*models.WeeklyTargetProgress, error) {
+	start := WeekStart(s.now().In(s.loc))
+	progress := &models.WeeklyTargetProgress{
+		WeekStart: start,
+		WeekEnd:   start.AddDate(0, 0, 7),
+	}
+
+	if s.weeklyTarget != nil {
+		target, err := s.weeklyTarget.GetWeeklyWordTarget(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		progress.Target = target
+	}
+
+	learned, err := s.flashcardRepo.GetLearnedTimes(ctx, userID, start)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения выученных за неделю слов: %w", err)
+	}
+	progress.Learned = len(learned)
+
+	return progress, nil
+}
@@ -0,0 +1,54 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fixedWeeklyTarget источник недельной цели с постоянным значением
+type fixedWeeklyTarget int
+
+func (t fixedWeeklyTarget) GetWeeklyWordTarget(ctx context.Context, userID int64) (int, error) {
+	return int(t), nil
+}
+
+func TestWeeklyProgressCountsCurrentWeekInLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := &fakeVocabularyRepo{learned: []time.Time{
+		time.Date(2026, 10, 11, 20, 0, 0, 0, time.UTC), // в Москве еще воскресенье прошлой недели
+		time.Date(2026, 10, 11, 22, 0, 0, 0, time.UTC), // в Москве уже понедельник
+		time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+	}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	s.SetLocation(moscow)
+	s.SetWeeklyTargetSource(fixedWeeklyTarget(2))
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, moscow) }
+
+	progress, err := s.WeeklyProgress(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if progress.Learned != 2 || progress.Target != 2 || !progress.Completed() {
+		t.Errorf("ожидалась выполненная цель 2 из 2, получено %+v", progress)
+	}
+	wantStart := time.Date(2026, 10, 12, 0, 0, 0, 0, moscow)
+	if !progress.WeekStart.Equal(wantStart) || !progress.WeekEnd.Equal(wantStart.AddDate(0, 0, 7)) {
+		t.Errorf("неверные границы недели: %s — %s", progress.WeekStart, progress.WeekEnd)
+	}
+}
+
+func TestWeeklyProgressWithoutSource(t *testing.T) {
+	s := NewService(&fakeVocabularyRepo{}, DefaultSpacedRepetitionConfig, zap.NewNop())
+
+	progress, err := s.WeeklyProgress(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if progress.HasTarget() || progress.Completed() {
+		t.Errorf("без источника цель не задана, получено %+v", progress)
+	}
+}
@@ -0,0 +1,57 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// WordOfDayCandidates сколько случайных карточек уровня просматривается при выборе слова дня
+const WordOfDayCandidates = 10
+
+// WordOfDayRepeatAfter через сколько дней слово дня может прийти пользователю снова
+const WordOfDayRepeatAfter = 60 * 24 * time.Hour
+
+// PickWordOfDay выбирает случайную карточку уровня, которую пользователю не присылали
+// последние WordOfDayRepeatAfter. Возвращает nil, если подходящей карточки не нашлось.
+func (s *Service) PickWordOfDay(ctx context.Context, userID int64, level string) (*models.Flashcard, error) {
+	if level == "" {
+		level = models.LevelBeginner
+	}
+
+	candidates, err := s.flashcardRepo.GetRandomFlashcards(ctx, level, WordOfDayCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выбора слова дня: %w", err)
+	}
+
+	sentIDs, err := s.flashcardRepo.GetSentWordIDs(ctx, userID, time.Now().Add(-WordOfDayRepeatAfter))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения отправленных слов дня: %w", err)
+	}
+	sent := make(map[int64]bool, len(sentIDs))
+	for _, id := range sentIDs {
+		sent[id] = true
+	}
+
+	for _, card := range candidates {
+		if !sent[card.ID] {
+			return card, nil
+		}
+	}
+	return nil, nil
+}
+
+// RecordWordOfDay запоминает отправленное слово дня, чтобы оно не повторялось
+func (s *Service) RecordWordOfDay(ctx context.Context, userID, flashcardID int64) error {
+	if err := s.flashcardRepo.RecordSentWord(ctx, userID, flashcardID); err != nil {
+		return err
+	}
+	s.logger.Info("отправлено слово дня",
+		zap.Int64("user_id", userID),
+		zap.Int64("flashcard_id", flashcardID))
+	return nil
+}
@@ -0,0 +1,60 @@
+package flashcards
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// wordOfDayRepo отдает карточки в заданном порядке и помнит отправленные слова
+type wordOfDayRepo struct {
+	store.FlashcardRepository
+	cards []*models.Flashcard
+	sent  []int64
+	level string
+}
+
+func (r *wordOfDayRepo) GetRandomFlashcards(ctx context.Context, level string, limit int) ([]*models.Flashcard, error) {
+	r.level = level
+	return r.cards, nil
+}
+
+func (r *wordOfDayRepo) GetSentWordIDs(ctx context.Context, userID int64, since time.Time) ([]int64, error) {
+	return r.sent, nil
+}
+
+func (r *wordOfDayRepo) RecordSentWord(ctx context.Context, userID, flashcardID int64) error {
+	r.sent = append(r.sent, flashcardID)
+	return nil
+}
+
+func TestPickWordOfDaySkipsRecentlySent(t *testing.T) {
+	repo := &wordOfDayRepo{cards: []*models.Flashcard{{ID: 1, Word: "cat"}, {ID: 2, Word: "dog"}}}
+	s := NewService(repo, DefaultSpacedRepetitionConfig, zap.NewNop())
+	ctx := context.Background()
+
+	card, err := s.PickWordOfDay(ctx, 10, "")
+	if err != nil || card == nil || card.ID != 1 {
+		t.Fatalf("ожидалась первая карточка, получено %+v, %v", card, err)
+	}
+	if repo.level != models.LevelBeginner {
+		t.Errorf("без уровня слово выбирается для начинающих, получено %q", repo.level)
+	}
+
+	if err := s.RecordWordOfDay(ctx, 10, card.ID); err != nil {
+		t.Fatalf("ошибка записи слова дня: %v", err)
+	}
+	if card, _ := s.PickWordOfDay(ctx, 10, models.LevelBeginner); card == nil || card.ID != 2 {
+		t.Fatalf("отправленное слово не должно повторяться, получено %+v", card)
+	}
+
+	repo.sent = append(repo.sent, 2)
+	if card, err := s.PickWordOfDay(ctx, 10, models.LevelBeginner); card != nil || err != nil {
+		t.Errorf("когда все слова недавно отправлены, выбирать нечего, получено %+v, %v", card, err)
+	}
+}
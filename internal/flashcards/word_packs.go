@@ -0,0 +1,66 @@
+package flashcards
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// WordPackService сервис для работы с тематическими наборами слов недели
+type WordPackService struct {
+	wordPackRepo store.WordPackRepository
+	logger       *zap.Logger
+}
+
+// NewWordPackService создает новый сервис наборов слов
+func NewWordPackService(wordPackRepo store.WordPackRepository, logger *zap.Logger) *WordPackService {
+	return &WordPackService{
+		wordPackRepo: wordPackRepo,
+		logger:       logger,
+	}
+}
+
+// GetWeeklyPack возвращает набор недели для уровня пользователя.
+// Возвращает nil, если все наборы уровня уже пройдены.
+func (s *WordPackService) GetWeeklyPack(ctx context.Context, userID int64, level string) (*models.WordPack, error) {
+	packs, err := s.wordPackRepo.GetAvailablePacks(ctx, userID, level)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения наборов слов: %w", err)
+	}
+
+	return selectWeeklyPack(packs, time.Now()), nil
+}
+
+// AcceptPack добавляет слова набора в очередь повторения пользователя
+func (s *WordPackService) AcceptPack(ctx context.Context, userID, packID int64) (*models.WordPack, int, error) {
+	pack, err := s.wordPackRepo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	added, err := s.wordPackRepo.AcceptPack(ctx, userID, packID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pack, added, nil
+}
+
+// GetUserPacksProgress возвращает прогресс пользователя по принятым наборам
+func (s *WordPackService) GetUserPacksProgress(ctx context.Context, userID int64) ([]*models.WordPackProgress, error) {
+	return s.wordPackRepo.GetUserPacksProgress(ctx, userID)
+}
+
+// selectWeeklyPack выбирает набор по номеру недели, чтобы в течение недели предлагался один и тот же набор
+func selectWeeklyPack(packs []*models.WordPack, now time.Time) *models.WordPack {
+	if len(packs) == 0 {
+		return nil
+	}
+	year, week := now.ISOWeek()
+	return packs[(year*53+week)%len(packs)]
+}
@@ -0,0 +1,90 @@
+package flashcards
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+func TestSelectWeeklyPack(t *testing.T) {
+	packs := []*models.WordPack{{ID: 1}, {ID: 2}, {ID: 3}}
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		packs []*models.WordPack
+		now   time.Time
+		want  int64 // 0 — набора нет
+	}{
+		{"нет доступных наборов", nil, monday, 0},
+		{"единственный набор", packs[:1], monday, 1},
+		{"начало недели", packs, monday, packs[(2026*53+42)%3].ID},
+		{"та же неделя — тот же набор", packs, monday.AddDate(0, 0, 6), packs[(2026*53+42)%3].ID},
+		{"следующая неделя — следующий набор", packs, monday.AddDate(0, 0, 7), packs[(2026*53+43)%3].ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectWeeklyPack(tt.packs, tt.now)
+			switch {
+			case tt.want == 0 && got != nil:
+				t.Errorf("ожидалось отсутствие набора, получено %d", got.ID)
+			case tt.want != 0 && (got == nil || got.ID != tt.want):
+				t.Errorf("ожидался набор %d, получено %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// packRepo отдает набор по ID и результат принятия набора
+type packRepo struct {
+	store.WordPackRepository
+	pack      *models.WordPack
+	added     int
+	acceptErr error
+}
+
+func (r *packRepo) GetPackByID(ctx context.Context, packID int64) (*models.WordPack, error) {
+	if r.pack == nil {
+		return nil, store.ErrWordPackNotFound
+	}
+	return r.pack, nil
+}
+
+func (r *packRepo) AcceptPack(ctx context.Context, userID, packID int64) (int, error) {
+	return r.added, r.acceptErr
+}
+
+func TestAcceptPack(t *testing.T) {
+	pack := &models.WordPack{ID: 5, Name: "Travel Essentials", WordsCount: 10}
+	tests := []struct {
+		name      string
+		repo      *packRepo
+		wantAdded int
+		wantErr   error
+	}{
+		{"новый набор", &packRepo{pack: pack, added: 10}, 10, nil},
+		{"часть слов уже изучается", &packRepo{pack: pack, added: 7}, 7, nil},
+		{"набор уже принят", &packRepo{pack: pack, acceptErr: store.ErrWordPackAlreadyAdded}, 0, store.ErrWordPackAlreadyAdded},
+		{"набора нет", &packRepo{}, 0, store.ErrWordPackNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewWordPackService(tt.repo, zap.NewNop())
+			got, added, err := service.AcceptPack(context.Background(), 1, 5)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ожидалась ошибка %v, получено %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (got != pack || added != tt.wantAdded) {
+				t.Errorf("ожидалось %d новых карточек набора %d, получено %d (%+v)", tt.wantAdded, pack.ID, added, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Имена файлов логов в каталоге Options.Dir
+const (
+	appLogFile   = "app.log"   // все записи логгера
+	errorLogFile = "error.log" // внутренние ошибки самого логгера
+)
+
+// Options настройки логгера
+type Options struct {
+	ToFiles bool   // писать ли логи в файлы; false — только stdout/stderr, как в контейнерах
+	Dir     string // каталог файлов логов
+	Rotate  RotateOptions
+}
+
+// New создает логгер разработки: записи идут в stdout и, если включены файлы,
+// в ротируемый app.log; ошибки самого логгера — в stderr и error.log
+func New(opts Options) (*zap.Logger, error) {
+	config := zap.NewDevelopmentConfig()
+	encoder := zapcore.NewConsoleEncoder(config.EncoderConfig)
+
+	out := []zapcore.WriteSyncer{zapcore.Lock(os.Stdout)}
+	errOut := []zapcore.WriteSyncer{zapcore.Lock(os.Stderr)}
+
+	if opts.ToFiles {
+		// Создаем директорию для логов если её нет
+		if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+			return nil, fmt.Errorf("ошибка создания директории логов: %w", err)
+		}
+
+		appLog, err := NewRotatingFile(filepath.Join(opts.Dir, appLogFile), opts.Rotate)
+		if err != nil {
+			return nil, err
+		}
+		errorLog, err := NewRotatingFile(filepath.Join(opts.Dir, errorLogFile), opts.Rotate)
+		if err != nil {
+			appLog.Close()
+			return nil, err
+		}
+		out = append(out, appLog)
+		errOut = append(errOut, errorLog)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(out...), config.Level)
+	return zap.New(core,
+		zap.Development(),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.WarnLevel),
+		zap.ErrorOutput(zapcore.NewMultiWriteSyncer(errOut...)),
+	), nil
+}
@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat формат времени в имени архивного файла: app-20240131T235959.000.log
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOptions параметры ротации файла логов
+type RotateOptions struct {
+	MaxSize    int64         // размер файла в байтах, после которого он архивируется
+	MaxBackups int           // сколько архивных файлов хранить (0 — без ограничения)
+	MaxAge     time.Duration // сколько хранить архивные файлы (0 — без ограничения)
+}
+
+// RotatingFile файл логов, который архивируется при превышении размера и в начале
+// нового дня; старые архивы удаляются по количеству и возрасту
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+	now  func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedOn time.Time // день, в который начат текущий файл
+}
+
+// NewRotatingFile открывает файл логов на дозапись с ротацией
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, opts: opts, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	r.removeOldBackups()
+	return r, nil
+}
+
+// Write записывает запись в файл, предварительно архивируя его при необходимости
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && (r.size+int64(len(p)) > r.opts.MaxSize || !sameDay(r.openedOn, r.now())) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync сбрасывает файл на диск
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
+
+// Close закрывает файл
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// open открывает файл и запоминает его текущий размер и дату начала
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла логов: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("ошибка чтения файла логов: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedOn = r.now()
+	if r.size > 0 {
+		// Продолжаем файл прошлого запуска: его день — день последней записи
+		r.openedOn = info.ModTime()
+	}
+	return nil
+}
+
+// rotate переименовывает текущий файл в архивный и начинает новый
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла логов: %w", err)
+	}
+	if err := os.Rename(r.path, r.backupName(r.now())); err != nil {
+		return fmt.Errorf("ошибка архивации файла логов: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.removeOldBackups()
+	return nil
+}
+
+// backupName имя архивного файла для момента t
+func (r *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.path)
+	return strings.TrimSuffix(r.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backups возвращает архивные файлы от новых к старым
+func (r *RotatingFile) backups() []string {
+	ext := filepath.Ext(r.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(r.path, ext) + "-*" + ext)
+	if err != nil {
+		return nil
+	}
+
+	// Время в имени сортируется как строка
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	return matches
+}
+
+// removeOldBackups удаляет архивы сверх лимита количества и старше лимита возраста.
+// Ошибки удаления не мешают писать логи.
+func (r *RotatingFile) removeOldBackups() {
+	cutoff := time.Time{}
+	if r.opts.MaxAge > 0 {
+		cutoff = r.now().Add(-r.opts.MaxAge)
+	}
+
+	for i, path := range r.backups() {
+		expired := r.opts.MaxBackups > 0 && i >= r.opts.MaxBackups
+		if !expired && !cutoff.IsZero() {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				expired = true
+			}
+		}
+		if expired {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// sameDay сообщает, приходятся ли моменты на один календарный день
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
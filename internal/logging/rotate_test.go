@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestFile открывает ротируемый файл с управляемыми часами
+func newTestFile(t *testing.T, opts RotateOptions, now *time.Time) (*RotatingFile, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := NewRotatingFile(path, opts)
+	if err != nil {
+		t.Fatalf("ошибка открытия файла: %v", err)
+	}
+	r.now = func() time.Time { return *now }
+	r.openedOn = *now
+	t.Cleanup(func() { r.Close() })
+	return r, path
+}
+
+func write(t *testing.T, r *RotatingFile, text string) {
+	t.Helper()
+	if _, err := r.Write([]byte(text)); err != nil {
+		t.Fatalf("ошибка записи: %v", err)
+	}
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	r, path := newTestFile(t, RotateOptions{MaxSize: 10}, &now)
+
+	write(t, r, "12345678\n")
+	now = now.Add(time.Millisecond)
+	write(t, r, "abc\n")
+
+	backups := r.backups()
+	if len(backups) != 1 {
+		t.Fatalf("ожидался 1 архив, получено %d", len(backups))
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "12345678\n" {
+		t.Errorf("в архиве ожидалась первая запись, получено %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "abc\n" {
+		t.Errorf("в текущем файле ожидалась новая запись, получено %q", data)
+	}
+	if !strings.HasSuffix(backups[0], ".log") || !strings.Contains(filepath.Base(backups[0]), "app-20240301T") {
+		t.Errorf("неожиданное имя архива: %s", backups[0])
+	}
+}
+
+func TestRotatingFileRotatesOnNewDay(t *testing.T) {
+	now := time.Date(2024, 3, 1, 23, 59, 0, 0, time.Local)
+	r, _ := newTestFile(t, RotateOptions{MaxSize: 1 << 20}, &now)
+
+	write(t, r, "вечер\n")
+	write(t, r, "еще вечер\n")
+	if len(r.backups()) != 0 {
+		t.Fatal("в течение дня файл не должен архивироваться")
+	}
+
+	now = now.Add(2 * time.Minute)
+	write(t, r, "утро\n")
+	if len(r.backups()) != 1 {
+		t.Errorf("в начале нового дня ожидался архив, получено %d", len(r.backups()))
+	}
+}
+
+func TestRotatingFileRemovesOldBackups(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	r, _ := newTestFile(t, RotateOptions{MaxSize: 1, MaxBackups: 2}, &now)
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		write(t, r, "x")
+	}
+	if got := len(r.backups()); got != 2 {
+		t.Errorf("ожидалось 2 архива, получено %d", got)
+	}
+
+	// Архивы старше MaxAge удаляются при следующей ротации
+	r.opts.MaxAge = time.Hour
+	for _, backup := range r.backups() {
+		old := now.Add(-2 * time.Hour)
+		os.Chtimes(backup, old, old)
+	}
+	now = now.Add(time.Second)
+	write(t, r, "y")
+	if got := len(r.backups()); got != 1 {
+		t.Errorf("ожидался только свежий архив, получено %d", got)
+	}
+}
+
+func TestNewWithoutFilesCreatesNothing(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+
+	logger, err := New(Options{ToFiles: false, Dir: dir})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	logger.Info("только stdout")
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("без файлов каталог логов не должен создаваться: %v", err)
+	}
+}
+
+func TestNewWritesToRotatingFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+
+	logger, err := New(Options{ToFiles: true, Dir: dir, Rotate: RotateOptions{MaxSize: 1 << 20}})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	logger.Info("запись в файл")
+
+	data, err := os.ReadFile(filepath.Join(dir, appLogFile))
+	if err != nil || !strings.Contains(string(data), "запись в файл") {
+		t.Errorf("ожидалась запись в %s, получено %q, %v", appLogFile, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, errorLogFile)); err != nil {
+		t.Errorf("ожидался файл %s: %v", errorLogFile, err)
+	}
+}
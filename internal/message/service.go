@@ -3,6 +3,7 @@ package message
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"lingua-ai/internal/store"
 	"lingua-ai/pkg/models"
@@ -34,7 +35,7 @@ func (s *Service) CreateMessage(ctx context.Context, req *models.CreateMessageRe
 	// Проверяем, существует ли пользователь
 	_, err := s.store.User().GetByID(ctx, req.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("пользователь не найден: %w", err)
+		return nil, fmt.Errorf("ошибка проверки пользователя: %w", err)
 	}
 
 	// Создаем сообщение
@@ -74,6 +75,23 @@ func (s *Service) GetChatHistory(ctx context.Context, userID int64, limit int) (
 	return history, nil
 }
 
+// MaxHistoryExportMessages сколько сообщений максимум выгружается за один запрос истории
+const MaxHistoryExportMessages = 200
+
+// GetMessagesByDateRange получает сообщения пользователя за период; limit ограничен MaxHistoryExportMessages
+func (s *Service) GetMessagesByDateRange(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.UserMessage, error) {
+	if limit <= 0 || limit > MaxHistoryExportMessages {
+		limit = MaxHistoryExportMessages
+	}
+
+	messages, err := s.store.Message().GetMessagesByDateRange(ctx, userID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории за период: %w", err)
+	}
+
+	return messages, nil
+}
+
 // ClearChatHistory очищает историю диалога пользователя
 func (s *Service) ClearChatHistory(ctx context.Context, userID int64) error {
 	if err := s.store.Message().DeleteByUserID(ctx, userID); err != nil {
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultActiveUsersLimit сколько уникальных пользователей хранится в одном окне по умолчанию
+const DefaultActiveUsersLimit = 100000
+
+// activeUserSet множество пользователей, активных в текущем периоде (сутки или месяц).
+// При смене периода множество очищается. Размер ограничен: пользователи сверх лимита
+// не запоминаются, а период помечается как переполненный.
+type activeUserSet struct {
+	mu       sync.Mutex
+	period   string
+	users    map[int64]struct{}
+	limit    int
+	overflow bool
+}
+
+func newActiveUserSet(limit int) *activeUserSet {
+	return &activeUserSet{
+		users: make(map[int64]struct{}),
+		limit: limit,
+	}
+}
+
+// add отмечает пользователя активным в периоде. Возвращает true,
+// если пользователь не поместился в лимит впервые за период.
+func (s *activeUserSet) add(userID int64, period string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollover(period)
+
+	if _, ok := s.users[userID]; ok {
+		return false
+	}
+	if s.limit > 0 && len(s.users) >= s.limit {
+		first := !s.overflow
+		s.overflow = true
+		return first
+	}
+
+	s.users[userID] = struct{}{}
+	return false
+}
+
+// count возвращает количество активных пользователей в периоде
+func (s *activeUserSet) count(period string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollover(period)
+	return len(s.users)
+}
+
+// setLimit меняет лимит; уже учтенные пользователи остаются до смены периода
+func (s *activeUserSet) setLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+}
+
+// rollover очищает множество при наступлении нового периода. Вызывается под мьютексом.
+func (s *activeUserSet) rollover(period string) {
+	if s.period == period {
+		return
+	}
+	s.period = period
+	s.overflow = false
+	// Новая карта вместо clear, чтобы память после пика не удерживалась
+	s.users = make(map[int64]struct{})
+}
+
+// dayPeriod ключ суток в часовом поясе loc
+func dayPeriod(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+// monthPeriod ключ календарного месяца в часовом поясе loc
+func monthPeriod(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01")
+}
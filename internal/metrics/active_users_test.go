@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestActiveUserSetCountsUniqueUsers(t *testing.T) {
+	s := newActiveUserSet(10)
+
+	s.add(1, "2026-03-10")
+	s.add(2, "2026-03-10")
+	s.add(1, "2026-03-10")
+
+	if got := s.count("2026-03-10"); got != 2 {
+		t.Errorf("ожидалось 2 уникальных пользователя, получено %d", got)
+	}
+}
+
+func TestActiveUserSetRollsOverOnNewPeriod(t *testing.T) {
+	s := newActiveUserSet(10)
+	s.add(1, "2026-03-10")
+	s.add(2, "2026-03-10")
+
+	if got := s.count("2026-03-11"); got != 0 {
+		t.Errorf("в новых сутках счетчик должен обнулиться, получено %d", got)
+	}
+
+	s.add(3, "2026-03-11")
+	if got := s.count("2026-03-11"); got != 1 {
+		t.Errorf("ожидался 1 пользователь за новые сутки, получено %d", got)
+	}
+}
+
+func TestActiveUserSetIsBounded(t *testing.T) {
+	s := newActiveUserSet(2)
+
+	if s.add(1, "d") || s.add(2, "d") {
+		t.Fatal("до лимита переполнения быть не должно")
+	}
+	if !s.add(3, "d") {
+		t.Error("первое превышение лимита должно быть отмечено")
+	}
+	if s.add(4, "d") {
+		t.Error("о переполнении сообщается один раз за период")
+	}
+	if got := s.count("d"); got != 2 {
+		t.Errorf("множество не должно расти сверх лимита, получено %d", got)
+	}
+
+	// Уже учтенный пользователь не считается переполнением
+	if s.add(1, "d") {
+		t.Error("повторный вход учтенного пользователя не должен отмечать переполнение")
+	}
+}
+
+func TestActiveUserSetConcurrentAdds(t *testing.T) {
+	s := newActiveUserSet(1000)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := int64(0); id < 100; id++ {
+				s.add(id, "d")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.count("d"); got != 100 {
+		t.Errorf("ожидалось 100 уникальных пользователей, получено %d", got)
+	}
+}
+
+func TestActivePeriodsUseLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	// 22:30 UTC — в UTC+3 уже следующие сутки и следующий месяц
+	now := time.Date(2026, 3, 31, 22, 30, 0, 0, time.UTC)
+
+	if got := dayPeriod(now, loc); got != "2026-04-01" {
+		t.Errorf("ожидались сутки 2026-04-01, получено %s", got)
+	}
+	if got := monthPeriod(now, loc); got != "2026-04" {
+		t.Errorf("ожидался месяц 2026-04, получено %s", got)
+	}
+}
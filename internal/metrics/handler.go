@@ -1,34 +1,153 @@
 package metrics
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
+// DefaultHealthCheckTimeout сколько /health ждет ответа каждой зависимости
+const DefaultHealthCheckTimeout = 3 * time.Second
+
+// HealthCheck проверка доступности внешней зависимости; nil — зависимость работает
+type HealthCheck func(ctx context.Context) error
+
+// dependencyCheck проверка зависимости для /health
+type dependencyCheck struct {
+	name     string
+	check    HealthCheck
+	required bool // без зависимости сервис не работает: ее недоступность дает 503
+}
+
+// DependencyStatus результат проверки одной зависимости в ответе /health
+type DependencyStatus struct {
+	Status    string `json:"status"` // ok или unavailable
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthResponse тело ответа /health
+type HealthResponse struct {
+	Status       string                      `json:"status"` // ok, degraded или unavailable
+	Service      string                      `json:"service"`
+	AI           string                      `json:"ai"` // состояние выключателя AI провайдера
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
 // Handler обрабатывает HTTP запросы для метрик
 type Handler struct {
 	metrics *Metrics
 	logger  *zap.Logger
+
+	checks       []dependencyCheck
+	checkTimeout time.Duration
 }
 
 // NewHandler создает новый обработчик метрик
 func NewHandler(metrics *Metrics, logger *zap.Logger) *Handler {
 	return &Handler{
-		metrics: metrics,
-		logger:  logger,
+		metrics:      metrics,
+		logger:       logger,
+		checkTimeout: DefaultHealthCheckTimeout,
 	}
 }
 
+// SetHealthCheck добавляет проверку зависимости в /health. Недоступность обязательной
+// зависимости делает сервис нерабочим (503), остальных — только деградирует его.
+func (h *Handler) SetHealthCheck(name string, check HealthCheck, required bool) {
+	h.checks = append(h.checks, dependencyCheck{name: name, check: check, required: required})
+}
+
 // MetricsHandler возвращает HTTP handler для Prometheus метрик
 func (h *Handler) MetricsHandler() http.Handler {
 	return promhttp.Handler()
 }
 
-// HealthHandler возвращает статус здоровья сервиса
+// HealthHandler возвращает статус здоровья сервиса и его зависимостей.
+// Недоступность необязательных зависимостей (AI, Whisper) не делает сервис
+// нерабочим: статус degraded, код 200. Без обязательных — unavailable, код 503.
 func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	response := HealthResponse{Status: "ok", Service: "lingua-ai", AI: "available"}
+	if h.metrics != nil && !h.metrics.AIAvailable() {
+		response.Status = "degraded"
+		response.AI = "unavailable"
+	}
+
+	response.Dependencies = h.checkDependencies(r.Context())
+	for name, dependency := range response.Dependencies {
+		if dependency.Status == "ok" {
+			continue
+		}
+		h.logger.Warn("зависимость недоступна", zap.String("dependency", name), zap.String("error", dependency.Error))
+		if dependency.Required {
+			response.Status = "unavailable"
+		} else if response.Status == "ok" {
+			response.Status = "degraded"
+		}
+	}
+
+	code := http.StatusOK
+	if response.Status == "unavailable" {
+		code = http.StatusServiceUnavailable
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok","service":"lingua-ai"}`))
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("ошибка записи ответа /health", zap.Error(err))
+	}
+}
+
+// checkDependencies параллельно проверяет зависимости, каждую не дольше checkTimeout
+func (h *Handler) checkDependencies(ctx context.Context) map[string]DependencyStatus {
+	if len(h.checks) == 0 {
+		return nil
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]DependencyStatus, len(h.checks))
+	)
+	for _, dc := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.checkTimeout)
+			defer cancel()
+
+			// Проверка может не учитывать контекст, поэтому таймаут соблюдаем сами
+			start := time.Now()
+			done := make(chan error, 1)
+			go func() { done <- dc.check(checkCtx) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-checkCtx.Done():
+				err = checkCtx.Err()
+			}
+			status := DependencyStatus{
+				Status:    "ok",
+				Required:  dc.required,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				status.Status = "unavailable"
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[dc.name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
 }
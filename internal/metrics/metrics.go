@@ -3,6 +3,7 @@ package metrics
 import (
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -19,6 +20,22 @@ type Metrics struct {
 	aiRequests   *prometheus.CounterVec
 	xpEarned     *prometheus.CounterVec
 
+	// Метрики фоновых задач
+	jobRuns     *prometheus.CounterVec
+	jobDuration *prometheus.HistogramVec
+	jobLastRun  *prometheus.GaugeVec
+	jobRows     *prometheus.GaugeVec
+
+	// Метрики пула словарных карточек
+	flashcardPoolExhausted *prometheus.CounterVec
+	flashcardsSeeded       *prometheus.CounterVec
+
+	// Обращения к TTS сервисам цепочки
+	ttsRequests *prometheus.CounterVec
+
+	// Повторные запросы к AI провайдеру
+	aiRetries *prometheus.CounterVec
+
 	// Гистограммы
 	aiResponseTime *prometheus.HistogramVec
 	xpPerAction    prometheus.Histogram
@@ -27,6 +44,18 @@ type Metrics struct {
 	activeUsers   prometheus.Gauge
 	lastUserLogin prometheus.Gauge
 
+	// Уникальные активные пользователи за сутки и календарный месяц
+	dailyActiveUsers   prometheus.GaugeFunc
+	monthlyActiveUsers prometheus.GaugeFunc
+	dailyUsers         *activeUserSet
+	monthlyUsers       *activeUserSet
+	activeLoc          *time.Location
+	now                func() time.Time
+
+	// Доступность AI провайдера: 0 — бот работает в режиме без AI
+	aiAvailable prometheus.GaugeFunc
+	aiStatus    func() bool
+
 	// Мьютекс для thread-safety
 	mu sync.RWMutex
 }
@@ -34,7 +63,11 @@ type Metrics struct {
 // New создает новый экземпляр метрик
 func New(logger *zap.Logger) *Metrics {
 	m := &Metrics{
-		logger: logger,
+		logger:       logger,
+		dailyUsers:   newActiveUserSet(DefaultActiveUsersLimit),
+		monthlyUsers: newActiveUserSet(DefaultActiveUsersLimit),
+		activeLoc:    time.UTC,
+		now:          time.Now,
 
 		// Счетчики пользователей
 		userLogins: prometheus.NewCounterVec(
@@ -72,6 +105,78 @@ func New(logger *zap.Logger) *Metrics {
 			[]string{"source"}, // russian_message, exercise_request, daily_bonus
 		),
 
+		// Запуски фоновых задач
+		jobRuns: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scheduler_job_runs_total",
+				Help: "Количество запусков фоновых задач",
+			},
+			[]string{"job", "status"}, // status: success, failed
+		),
+
+		// Длительность фоновых задач
+		jobDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "scheduler_job_duration_seconds",
+				Help:    "Длительность выполнения фоновых задач в секундах",
+				Buckets: []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+			},
+			[]string{"job"},
+		),
+
+		// Время последнего запуска фоновой задачи
+		jobLastRun: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "scheduler_job_last_run_timestamp_seconds",
+				Help: "Unix-время последнего запуска фоновой задачи",
+			},
+			[]string{"job"},
+		),
+
+		// Записей обработано последним запуском
+		jobRows: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "scheduler_job_last_rows_processed",
+				Help: "Сколько записей обработал последний запуск фоновой задачи",
+			},
+			[]string{"job"},
+		),
+
+		// Исчерпание пула карточек уровня
+		flashcardPoolExhausted: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "flashcard_pool_exhausted_total",
+				Help: "Сколько раз у пользователя закончились новые карточки уровня",
+			},
+			[]string{"level"},
+		),
+
+		// Карточки, добавленные автоматическим пополнением
+		flashcardsSeeded: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "flashcards_seeded_total",
+				Help: "Количество карточек, сгенерированных AI для пополнения пула",
+			},
+			[]string{"level"},
+		),
+
+		// Обращения к TTS сервисам
+		ttsRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tts_requests_total",
+				Help: "Количество обращений к TTS сервисам по сервису и результату",
+			},
+			[]string{"service", "status"},
+		),
+
+		aiRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ai_retries_total",
+				Help: "Количество повторных запросов к AI провайдеру после временных ошибок",
+			},
+			[]string{"provider", "reason"}, // reason: network, rate_limit, server_error
+		),
+
 		// Гистограмма времени ответа AI
 		aiResponseTime: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -108,6 +213,41 @@ func New(logger *zap.Logger) *Metrics {
 		),
 	}
 
+	// Значения считаются при сборе метрик, чтобы смена суток была видна и без новых входов
+	m.dailyActiveUsers = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "daily_active_users",
+			Help: "Уникальные пользователи, писавшие боту за текущие сутки",
+		},
+		func() float64 {
+			day, _ := m.activePeriods()
+			return float64(m.dailyUsers.count(day))
+		},
+	)
+	m.monthlyActiveUsers = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "monthly_active_users",
+			Help: "Уникальные пользователи, писавшие боту за текущий календарный месяц",
+		},
+		func() float64 {
+			_, month := m.activePeriods()
+			return float64(m.monthlyUsers.count(month))
+		},
+	)
+
+	m.aiAvailable = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "ai_provider_available",
+			Help: "Доступен ли AI провайдер (0 — бот работает в режиме без AI)",
+		},
+		func() float64 {
+			if m.AIAvailable() {
+				return 1
+			}
+			return 0
+		},
+	)
+
 	// Регистрируем все метрики
 	prometheus.MustRegister(
 		m.userLogins,
@@ -118,11 +258,60 @@ func New(logger *zap.Logger) *Metrics {
 		m.xpPerAction,
 		m.activeUsers,
 		m.lastUserLogin,
+		m.jobRuns,
+		m.jobDuration,
+		m.jobLastRun,
+		m.jobRows,
+		m.flashcardPoolExhausted,
+		m.flashcardsSeeded,
+		m.ttsRequests,
+		m.aiRetries,
+		m.dailyActiveUsers,
+		m.monthlyActiveUsers,
+		m.aiAvailable,
 	)
 
 	return m
 }
 
+// SetActiveUsersConfig задает часовой пояс смены суток и лимит пользователей,
+// которых помнят метрики daily_active_users и monthly_active_users
+func (m *Metrics) SetActiveUsersConfig(loc *time.Location, limit int) {
+	m.mu.Lock()
+	if loc != nil {
+		m.activeLoc = loc
+	}
+	m.mu.Unlock()
+
+	if limit > 0 {
+		m.dailyUsers.setLimit(limit)
+		m.monthlyUsers.setLimit(limit)
+	}
+}
+
+// activePeriods возвращает ключи текущих суток и месяца
+func (m *Metrics) activePeriods() (day, month string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	return dayPeriod(now, m.activeLoc), monthPeriod(now, m.activeLoc)
+}
+
+// recordActiveUser отмечает пользователя активным за сутки и месяц
+func (m *Metrics) recordActiveUser(userID int64) {
+	day, month := m.activePeriods()
+
+	if m.dailyUsers.add(userID, day) {
+		m.logger.Warn("достигнут лимит учета активных пользователей за сутки, метрика занижена",
+			zap.String("day", day))
+	}
+	if m.monthlyUsers.add(userID, month) {
+		m.logger.Warn("достигнут лимит учета активных пользователей за месяц, метрика занижена",
+			zap.String("month", month))
+	}
+}
+
 // IncrementCounter увеличивает счетчик
 func (m *Metrics) IncrementCounter(name string, labels ...string) {
 	m.mu.Lock()
@@ -192,6 +381,7 @@ func (m *Metrics) RecordUserLogin(userID int64) {
 	m.IncrementCounter("user_logins_total", "total")
 	m.IncrementCounter("user_logins_total", "daily")
 	m.SetGauge("last_user_login", float64(userID))
+	m.recordActiveUser(userID)
 }
 
 // RecordUserMessage записывает сообщение пользователя
@@ -218,6 +408,58 @@ func (m *Metrics) RecordXP(userID int64, amount int, source string) {
 	m.ObserveHistogram("xp_per_action", float64(amount))
 }
 
+// SetAIStatus задает источник доступности AI провайдера для метрик и /health
+func (m *Metrics) SetAIStatus(status func() bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.aiStatus = status
+}
+
+// AIAvailable сообщает, доступен ли AI провайдер. Без источника считается доступным.
+func (m *Metrics) AIAvailable() bool {
+	m.mu.RLock()
+	status := m.aiStatus
+	m.mu.RUnlock()
+	return status == nil || status()
+}
+
+// RecordJobRun записывает результат запуска фоновой задачи
+func (m *Metrics) RecordJobRun(job string, duration time.Duration, rows int64, err error) {
+	status := "success"
+	if err != nil {
+		status = "failed"
+	}
+
+	m.jobRuns.WithLabelValues(job, status).Inc()
+	m.jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	m.jobLastRun.WithLabelValues(job).Set(float64(time.Now().Unix()))
+	m.jobRows.WithLabelValues(job).Set(float64(rows))
+}
+
+// RecordFlashcardPoolExhausted отмечает, что у пользователя закончились новые карточки уровня
+func (m *Metrics) RecordFlashcardPoolExhausted(level string) {
+	m.flashcardPoolExhausted.WithLabelValues(level).Inc()
+}
+
+// RecordFlashcardsSeeded учитывает карточки, добавленные в пул уровня
+func (m *Metrics) RecordFlashcardsSeeded(level string, count int) {
+	m.flashcardsSeeded.WithLabelValues(level).Add(float64(count))
+}
+
+// RecordTTSRequest записывает обращение к TTS сервису: какой сервис озвучил текст или не справился
+func (m *Metrics) RecordTTSRequest(service string, success bool) {
+	status := "success"
+	if !success {
+		status = "failed"
+	}
+	m.ttsRequests.WithLabelValues(service, status).Inc()
+}
+
+// RecordAIRetry записывает повтор запроса к AI провайдеру после временной ошибки
+func (m *Metrics) RecordAIRetry(provider, reason string) {
+	m.aiRetries.WithLabelValues(provider, reason).Inc()
+}
+
 // Handler возвращает HTTP handler для метрик
 func (m *Metrics) Handler() http.Handler {
 	return promhttp.Handler()
@@ -1,11 +1,30 @@
 package metrics
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"go.uber.org/zap"
 )
 
+// gaugeValue возвращает текущее значение gauge так, как его увидит /metrics
+func gaugeValue(t *testing.T, gauge prometheus.Metric) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := gauge.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetGauge().GetValue()
+}
+
 func TestMetrics(t *testing.T) {
 	logger := zap.NewNop()
 	m := New(logger)
@@ -24,4 +43,98 @@ func TestMetrics(t *testing.T) {
 	m.RecordUserMessage("text")
 	m.RecordAIRequest("english_practice", true, 2.0)
 	m.RecordXP(123, 10, "exercise_request")
+
+	// Активные пользователи: повторный вход не увеличивает счетчик, новые сутки обнуляют его
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.RecordUserLogin(123)
+	m.RecordUserLogin(456)
+	if got := gaugeValue(t, m.dailyActiveUsers); got != 2 {
+		t.Errorf("ожидалось 2 активных за сутки, получено %v", got)
+	}
+
+	now = now.AddDate(0, 0, 1)
+	m.RecordUserLogin(123)
+	if got := gaugeValue(t, m.dailyActiveUsers); got != 1 {
+		t.Errorf("ожидался 1 активный за новые сутки, получено %v", got)
+	}
+	if got := gaugeValue(t, m.monthlyActiveUsers); got != 2 {
+		t.Errorf("ожидалось 2 активных за месяц, получено %v", got)
+	}
+
+	// Доступность AI: без источника провайдер считается доступным
+	if got := gaugeValue(t, m.aiAvailable); got != 1 {
+		t.Errorf("ожидалось ai_provider_available=1, получено %v", got)
+	}
+	m.SetAIStatus(func() bool { return false })
+	if got := gaugeValue(t, m.aiAvailable); got != 0 {
+		t.Errorf("ожидалось ai_provider_available=0, получено %v", got)
+	}
+}
+
+func TestHealthHandlerReportsAIStatus(t *testing.T) {
+	m := &Metrics{}
+	h := NewHandler(m, zap.NewNop())
+
+	for _, tt := range []struct {
+		available bool
+		want      string
+	}{
+		{true, `"status":"ok"`},
+		{false, `"status":"degraded"`},
+	} {
+		m.SetAIStatus(func() bool { return tt.available })
+		rec := httptest.NewRecorder()
+		h.HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("ожидался код 200, получено %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("ожидалось %s в ответе, получено %s", tt.want, rec.Body.String())
+		}
+	}
+}
+
+func TestHealthHandlerChecksDependencies(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	// Не учитывает контекст: ответ придет позже таймаута проверки
+	hang := func(ctx context.Context) error { time.Sleep(time.Second); return nil }
+
+	for _, tt := range []struct {
+		name       string
+		database   HealthCheck
+		whisper    HealthCheck
+		wantCode   int
+		wantStatus string
+	}{
+		{"все доступны", ok, ok, http.StatusOK, "ok"},
+		{"whisper недоступен", ok, down, http.StatusOK, "degraded"},
+		{"база недоступна", down, ok, http.StatusServiceUnavailable, "unavailable"},
+		{"база не отвечает", hang, ok, http.StatusServiceUnavailable, "unavailable"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&Metrics{}, zap.NewNop())
+			h.checkTimeout = 20 * time.Millisecond
+			h.SetHealthCheck("database", tt.database, true)
+			h.SetHealthCheck("whisper", tt.whisper, false)
+
+			rec := httptest.NewRecorder()
+			h.HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("ожидался код %d, получено %d", tt.wantCode, rec.Code)
+			}
+
+			var response HealthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("некорректный JSON: %v", err)
+			}
+			if response.Status != tt.wantStatus || len(response.Dependencies) != 2 {
+				t.Errorf("ожидался статус %q с двумя зависимостями, получено %+v", tt.wantStatus, response)
+			}
+			if response.Dependencies["whisper"].Required {
+				t.Error("whisper не должен считаться обязательной зависимостью")
+			}
+		})
+	}
 }
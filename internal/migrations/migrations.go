@@ -2,9 +2,13 @@ package migrations
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"lingua-ai/internal/config"
 
@@ -17,29 +21,16 @@ import (
 func RunMigrations(cfg *config.Config, logger *zap.Logger) error {
 	logger.Info("начало применения миграций")
 
-	// Устанавливаем путь к миграциям
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("ошибка установки диалекта: %w", err)
-	}
-
-	// Создаем временное подключение к базе данных для миграций
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=%s",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", dsn)
+	db, migrationPath, err := open(cfg, logger)
 	if err != nil {
-		return fmt.Errorf("ошибка подключения к базе данных для миграций: %w", err)
+		return err
 	}
 	defer db.Close()
 
-	// Определяем правильный путь к миграциям
-	migrationPath := getMigrationPath(cfg.Database.MigrationPath, logger)
+	// Проверяем, что миграции с CONCURRENTLY не будут выполняться в транзакции
+	if err := checkNonTransactional(migrationPath, logger); err != nil {
+		return err
+	}
 
 	// Применяем миграции
 	if err := goose.Up(db, migrationPath); err != nil {
@@ -54,9 +45,111 @@ func RunMigrations(cfg *config.Config, logger *zap.Logger) error {
 func GetMigrationStatus(cfg *config.Config, logger *zap.Logger) error {
 	logger.Info("проверка статуса миграций")
 
+	db, migrationPath, err := open(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Получаем статус миграций
+	if err := goose.Status(db, migrationPath); err != nil {
+		return fmt.Errorf("ошибка получения статуса миграций: %w", err)
+	}
+
+	logger.Info("статус миграций получен")
+	return nil
+}
+
+// MigrationStatus состояние одной миграции
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Status возвращает список всех миграций с отметкой, применены ли они
+func Status(cfg *config.Config, logger *zap.Logger) ([]MigrationStatus, error) {
+	db, migrationPath, err := open(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	all, err := goose.CollectMigrations(migrationPath, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения миграций: %w", err)
+	}
+
+	// На чистой базе таблицы версий еще нет
+	if _, err := goose.EnsureDBVersion(db); err != nil {
+		return nil, fmt.Errorf("ошибка проверки таблицы версий: %w", err)
+	}
+
+	// Для каждой версии учитывается последняя запись: откат добавляет строку с is_applied = false
+	query := fmt.Sprintf(`SELECT tstamp, is_applied FROM %s WHERE version_id = $1 ORDER BY id DESC LIMIT 1`, goose.TableName())
+
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, migration := range all {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    filepath.Base(migration.Source),
+		}
+
+		err := db.QueryRow(query, migration.Version).Scan(&status.AppliedAt, &status.Applied)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("ошибка получения статуса миграции %d: %w", migration.Version, err)
+		}
+		if !status.Applied {
+			status.AppliedAt = time.Time{}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up применяет все ожидающие миграции
+func Up(cfg *config.Config, logger *zap.Logger) error {
+	return RunMigrations(cfg, logger)
+}
+
+// Down откатывает последнюю примененную миграцию
+func Down(cfg *config.Config, logger *zap.Logger) error {
+	db, migrationPath, err := open(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := goose.Down(db, migrationPath); err != nil {
+		return fmt.Errorf("ошибка отката миграции: %w", err)
+	}
+
+	logger.Info("последняя миграция откачена")
+	return nil
+}
+
+// Version возвращает текущую версию схемы базы данных
+func Version(cfg *config.Config, logger *zap.Logger) (int64, error) {
+	db, _, err := open(cfg, logger)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	version, err := goose.GetDBVersion(db)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения версии схемы: %w", err)
+	}
+	return version, nil
+}
+
+// open создает подключение к базе данных для миграций и определяет путь к ним
+func open(cfg *config.Config, logger *zap.Logger) (*sql.DB, string, error) {
 	// Устанавливаем путь к миграциям
 	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("ошибка установки диалекта: %w", err)
+		return nil, "", fmt.Errorf("ошибка установки диалекта: %w", err)
 	}
 
 	// Создаем временное подключение к базе данных для миграций
@@ -71,22 +164,67 @@ func GetMigrationStatus(cfg *config.Config, logger *zap.Logger) error {
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		return fmt.Errorf("ошибка подключения к базе данных для миграций: %w", err)
+		return nil, "", fmt.Errorf("ошибка подключения к базе данных для миграций: %w", err)
 	}
-	defer db.Close()
 
 	// Определяем правильный путь к миграциям
-	migrationPath := getMigrationPath(cfg.Database.MigrationPath, logger)
+	return db, getMigrationPath(cfg.Database.MigrationPath, logger), nil
+}
 
-	// Получаем статус миграций
-	if err := goose.Status(db, migrationPath); err != nil {
-		return fmt.Errorf("ошибка получения статуса миграций: %w", err)
+// noTransactionAnnotation аннотация goose для миграций, выполняемых вне транзакции
+const noTransactionAnnotation = "-- +goose NO TRANSACTION"
+
+// concurrentlyRegexp находит команды, которые PostgreSQL не выполняет внутри транзакции
+var concurrentlyRegexp = regexp.MustCompile(`(?i)\b(CREATE|DROP|REINDEX)\b[^;]*\bCONCURRENTLY\b`)
+
+// checkNonTransactional проверяет, что миграции с CONCURRENTLY помечены NO TRANSACTION.
+// Иначе goose обернет их в транзакцию и PostgreSQL отклонит команду уже во время применения.
+func checkNonTransactional(migrationPath string, logger *zap.Logger) error {
+	files, err := filepath.Glob(filepath.Join(migrationPath, "*.sql"))
+	if err != nil {
+		return fmt.Errorf("ошибка чтения списка миграций: %w", err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения миграции %s: %w", filepath.Base(file), err)
+		}
+
+		noTx := hasNoTransactionAnnotation(string(content))
+		if concurrentlyRegexp.MatchString(stripSQLComments(string(content))) && !noTx {
+			return fmt.Errorf("миграция %s использует CONCURRENTLY, но не помечена %q",
+				filepath.Base(file), noTransactionAnnotation)
+		}
+		if noTx {
+			logger.Debug("миграция выполняется вне транзакции", zap.String("file", filepath.Base(file)))
+		}
 	}
 
-	logger.Info("статус миграций получен")
 	return nil
 }
 
+// hasNoTransactionAnnotation проверяет наличие аннотации NO TRANSACTION
+func hasNoTransactionAnnotation(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), noTransactionAnnotation) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSQLComments удаляет однострочные комментарии, чтобы не учитывать упоминания в пояснениях
+func stripSQLComments(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if idx := strings.Index(line, "--"); idx != -1 {
+			lines[i] = line[:idx]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // getMigrationPath определяет правильный путь к миграциям
 func getMigrationPath(configPath string, logger *zap.Logger) string {
 	// Сначала проверяем, существует ли путь из конфигурации
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRepositoryMigrationsUseNoTransactionForConcurrentIndexes(t *testing.T) {
+	if err := checkNonTransactional(filepath.Join("..", "..", "scripts", "migrations"), zap.NewNop()); err != nil {
+		t.Fatalf("миграции репозитория не прошли проверку: %v", err)
+	}
+}
+
+func TestCheckNonTransactional(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "CONCURRENTLY без аннотации",
+			content: "-- +goose Up\nCREATE INDEX CONCURRENTLY idx_a ON a (id);\n",
+			wantErr: true,
+		},
+		{
+			name:    "CONCURRENTLY с аннотацией",
+			content: "-- +goose NO TRANSACTION\n-- +goose Up\nCREATE INDEX CONCURRENTLY idx_a ON a (id);\n",
+			wantErr: false,
+		},
+		{
+			name:    "CONCURRENTLY только в комментарии",
+			content: "-- +goose Up\n-- индекс можно пересоздать CREATE INDEX CONCURRENTLY\nCREATE INDEX idx_a ON a (id);\n",
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "001_test.sql"), []byte(tc.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := checkNonTransactional(dir, zap.NewNop())
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: ожидалась ошибка %v, получено %v", tc.name, tc.wantErr, err)
+		}
+	}
+}
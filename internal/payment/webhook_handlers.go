@@ -3,6 +3,7 @@ package payment
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -125,10 +126,14 @@ func (h *WebhookHandler) handlePreCheckoutQuery(query *PreCheckoutQuery) error {
 	// Проверяем, что пользователь существует
 	_, err = h.userService.GetUserByTelegramID(userID)
 	if err != nil {
+		reason := "Не удалось проверить пользователя, попробуйте позже"
+		if errors.Is(err, store.ErrUserNotFound) {
+			reason = "Пользователь не найден"
+		}
 		return h.telegramService.AnswerPreCheckoutQuery(
 			query.ID,
 			false,
-			"Пользователь не найден",
+			reason,
 		)
 	}
 
@@ -174,7 +179,7 @@ func (h *WebhookHandler) handleSuccessfulPayment(payment *SuccessfulPayment) err
 	// Получаем пользователя
 	user, err := h.userService.GetUserByTelegramID(userID)
 	if err != nil {
-		return fmt.Errorf("пользователь не найден: %w", err)
+		return fmt.Errorf("ошибка получения пользователя: %w", err)
 	}
 
 	// Создаем запись о платеже
@@ -255,7 +260,7 @@ func (a *UserServiceAdapter) GetUserByTelegramID(telegramID int64) (*User, error
 	// Получаем пользователя по Telegram ID через userService
 	user, err := a.userService.GetUserByTelegramID(context.Background(), telegramID)
 	if err != nil {
-		return nil, fmt.Errorf("пользователь не найден: %w", err)
+		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
 	}
 
 	return &User{
@@ -0,0 +1,100 @@
+package premium
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+// Feature возможность бота, которую можно сделать доступной только по премиуму
+type Feature string
+
+// Возможности, доступ к которым настраивается через PREMIUM_FEATURES
+const (
+	FeatureEssayReview       Feature = "essay_review"        // Подробная проверка длинных текстов
+	FeatureVoiceReplies      Feature = "voice_replies"       // Озвучка ответов бота
+	FeatureExtraTestAttempts Feature = "extra_test_attempts" // Тест уровня чаще одного раза в день
+	FeatureLongAudio         Feature = "long_audio"          // Повышенный лимит длительности голосовых
+	FeatureMistakesReview    Feature = "mistakes_review"     // Разбор типичных ошибок по команде /review
+	FeatureTranscribeDebug   Feature = "transcribe_debug"    // Тайминги распознавания голосовых по /transcribe_debug
+)
+
+// featureTitles названия возможностей для сообщений пользователю
+var featureTitles = map[Feature]string{
+	FeatureEssayReview:       "Подробная проверка эссе",
+	FeatureVoiceReplies:      "Озвучка ответов",
+	FeatureExtraTestAttempts: "Повторные попытки теста уровня",
+	FeatureLongAudio:         "Длинные голосовые сообщения",
+	FeatureMistakesReview:    "Разбор ошибок",
+	FeatureTranscribeDebug:   "Подробности распознавания речи",
+}
+
+// DefaultPremiumFeatures возможности, доступные только по премиуму по умолчанию
+var DefaultPremiumFeatures = []Feature{
+	FeatureEssayReview,
+	FeatureExtraTestAttempts,
+	FeatureLongAudio,
+	FeatureMistakesReview,
+	FeatureTranscribeDebug,
+}
+
+// Title возвращает название возможности для пользователя
+func (f Feature) Title() string {
+	if title, ok := featureTitles[f]; ok {
+		return title
+	}
+	return string(f)
+}
+
+// FeatureGate определяет, какие возможности доступны только премиум-пользователям.
+// Возможности, которых нет в карте, доступны всем.
+type FeatureGate map[Feature]bool
+
+// NewFeatureGate создает карту премиум-возможностей
+func NewFeatureGate(features ...Feature) FeatureGate {
+	gate := make(FeatureGate, len(features))
+	for _, feature := range features {
+		gate[feature] = true
+	}
+	return gate
+}
+
+// ParseFeatureGate разбирает список премиум-возможностей из конфигурации
+func ParseFeatureGate(names []string) (FeatureGate, error) {
+	gate := make(FeatureGate, len(names))
+	for _, name := range names {
+		feature := Feature(strings.TrimSpace(name))
+		if feature == "" {
+			continue
+		}
+		if _, ok := featureTitles[feature]; !ok {
+			return nil, fmt.Errorf("неизвестная премиум-возможность %q", feature)
+		}
+		gate[feature] = true
+	}
+	return gate, nil
+}
+
+// PremiumOnly проверяет, требует ли возможность премиум
+func (g FeatureGate) PremiumOnly(feature Feature) bool {
+	return g[feature]
+}
+
+// Enabled проверяет, доступна ли возможность пользователю
+func (g FeatureGate) Enabled(user *models.User, feature Feature) bool {
+	if !g.PremiumOnly(feature) {
+		return true
+	}
+	return hasActivePremium(user, time.Now())
+}
+
+// hasActivePremium проверяет премиум с учетом срока действия, даже если
+// фоновая деактивация еще не успела снять флаг IsPremium
+func hasActivePremium(user *models.User, now time.Time) bool {
+	if user == nil || !user.IsPremium {
+		return false
+	}
+	return user.PremiumExpiresAt == nil || now.Before(*user.PremiumExpiresAt)
+}
@@ -0,0 +1,55 @@
+package premium
+
+import (
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestFeatureGateFreeVsPremium(t *testing.T) {
+	gate := NewFeatureGate(DefaultPremiumFeatures...)
+
+	expired := time.Now().Add(-time.Hour)
+	active := time.Now().Add(24 * time.Hour)
+
+	free := &models.User{}
+	premium := &models.User{IsPremium: true, PremiumExpiresAt: &active}
+	lapsed := &models.User{IsPremium: true, PremiumExpiresAt: &expired}
+
+	features := []Feature{FeatureEssayReview, FeatureVoiceReplies, FeatureExtraTestAttempts, FeatureLongAudio, FeatureMistakesReview, FeatureTranscribeDebug}
+	for _, feature := range features {
+		premiumOnly := gate.PremiumOnly(feature)
+
+		if got := gate.Enabled(free, feature); got == premiumOnly {
+			t.Errorf("%s для бесплатного пользователя: получено %v", feature, got)
+		}
+		if !gate.Enabled(premium, feature) {
+			t.Errorf("%s должна быть доступна премиум-пользователю", feature)
+		}
+		if got := gate.Enabled(lapsed, feature); got == premiumOnly {
+			t.Errorf("%s для истекшего премиума: получено %v", feature, got)
+		}
+	}
+
+	if gate.PremiumOnly(FeatureVoiceReplies) {
+		t.Error("озвучка по умолчанию должна быть доступна всем")
+	}
+}
+
+func TestParseFeatureGate(t *testing.T) {
+	gate, err := ParseFeatureGate([]string{" voice_replies", "long_audio", ""})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !gate.PremiumOnly(FeatureVoiceReplies) || !gate.PremiumOnly(FeatureLongAudio) {
+		t.Error("ожидались премиум-возможности voice_replies и long_audio")
+	}
+	if gate.PremiumOnly(FeatureEssayReview) {
+		t.Error("essay_review не указана и должна быть доступна всем")
+	}
+
+	if _, err := ParseFeatureGate([]string{"teleport"}); err == nil {
+		t.Error("ожидалась ошибка для неизвестной возможности")
+	}
+}
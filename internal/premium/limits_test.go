@@ -0,0 +1,123 @@
+package premium
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+// memoryUsers пользователи в памяти для проверки лимитов сообщений
+type memoryUsers struct {
+	users map[int64]*models.User
+	gifts []*models.Payment // сохраненные подарки премиума
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	u := *r.users[id]
+	return &u, nil
+}
+
+func (r *memoryUsers) Update(ctx context.Context, user *models.User) error {
+	u := *user
+	r.users[user.ID] = &u
+	return nil
+}
+
+func (r *memoryUsers) IncrementMessagesCount(ctx context.Context, userID int64) error {
+	r.users[userID].MessagesCount++
+	return nil
+}
+
+func (r *memoryUsers) ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error) {
+	return 0, nil
+}
+
+// ApplyPremiumGift повторяет транзакцию PostgreSQL: без получателя подарок не сохраняется
+func (r *memoryUsers) ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error) {
+	u, ok := r.users[payment.UserID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("пользователь %d не найден", payment.UserID)
+	}
+	start := time.Now()
+	if u.PremiumExpiresAt != nil && u.PremiumExpiresAt.After(start) {
+		start = *u.PremiumExpiresAt
+	}
+	expiresAt := start.AddDate(0, 0, payment.PremiumDurationDays)
+	u.IsPremium = true
+	u.PremiumExpiresAt = &expiresAt
+	u.MaxMessages = 0
+	r.gifts = append(r.gifts, payment)
+	return expiresAt, nil
+}
+
+func TestFreeMessageLimitIsConsistent(t *testing.T) {
+	now := time.Now()
+	future := now.Add(24 * time.Hour)
+	past := now.Add(-time.Minute)
+	users := &memoryUsers{users: map[int64]*models.User{
+		// Новый пользователь: лимит задается при создании
+		1: {ID: 1, MaxMessages: DefaultFreeMessageLimit, MessagesCount: 3, MessagesResetDate: now},
+		// Активный премиум: лимита нет
+		2: {ID: 2, IsPremium: true, PremiumExpiresAt: &future, MessagesCount: 3, MessagesResetDate: now},
+		// Премиум только что закончился
+		3: {ID: 3, IsPremium: true, PremiumExpiresAt: &past, MessagesCount: 3, MessagesResetDate: now},
+	}}
+	service := NewService(users, nil, nil, zap.NewNop())
+	ctx := context.Background()
+
+	tests := []struct {
+		userID        int64
+		wantPremium   bool
+		wantMax       int
+		wantRemaining any
+	}{
+		{1, false, DefaultFreeMessageLimit, DefaultFreeMessageLimit - 3},
+		{2, true, 0, "∞"},
+		{3, false, DefaultFreeMessageLimit, DefaultFreeMessageLimit - 3},
+	}
+	for _, tt := range tests {
+		stats, err := service.GetUserStats(ctx, tt.userID)
+		if err != nil {
+			t.Fatalf("пользователь %d: %v", tt.userID, err)
+		}
+		if stats["is_premium"] != tt.wantPremium || stats["max_messages"] != tt.wantMax || stats["remaining_messages"] != tt.wantRemaining {
+			t.Errorf("пользователь %d: неожиданная статистика %v", tt.userID, stats)
+		}
+
+		// Проверка лимита переводит истекший премиум на тот же бесплатный лимит
+		if _, err := service.CanSendMessage(ctx, tt.userID); err != nil {
+			t.Fatalf("пользователь %d: %v", tt.userID, err)
+		}
+		if u := users.users[tt.userID]; !u.IsPremium && u.MaxMessages != DefaultFreeMessageLimit {
+			t.Errorf("пользователь %d: ожидался лимит %d, сохранен %d", tt.userID, DefaultFreeMessageLimit, u.MaxMessages)
+		}
+	}
+}
+
+func TestDailyResetUsesUserTimezone(t *testing.T) {
+	// Пояса отличаются на 26 часов, поэтому в UTC+14 всегда уже наступил следующий день
+	resetLoc := mustLoadLocation(t, "Etc/GMT+12")
+	mustLoadLocation(t, "Etc/GMT-14")
+
+	today := calendarDate(time.Now(), resetLoc)
+	repo := &memoryUsers{users: map[int64]*models.User{
+		1: {ID: 1, MessagesCount: 5, MaxMessages: 5, MessagesResetDate: today},
+		2: {ID: 2, MessagesCount: 5, MaxMessages: 5, MessagesResetDate: today, Timezone: "Etc/GMT-14"},
+	}}
+	s := NewService(repo, nil, nil, zap.NewNop())
+	s.SetResetLocation(resetLoc)
+
+	for id, want := range map[int64]int{1: 5, 2: 0} {
+		if err := s.resetDailyCounterIfNeeded(context.Background(), id); err != nil {
+			t.Fatalf("ошибка сброса счетчика: %v", err)
+		}
+		if got := repo.users[id].MessagesCount; got != want {
+			t.Errorf("пользователь %d: ожидался счетчик %d, получено %d", id, want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package premium
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,6 +17,7 @@ type Service struct {
 	paymentRepo PaymentRepository
 	logger      *zap.Logger
 	yukassa     YukassaClient
+	resetLoc    *time.Location // часовой пояс, в котором наступает полночь сброса лимитов
 }
 
 // UserRepository интерфейс для работы с пользователями
@@ -23,13 +25,17 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	IncrementMessagesCount(ctx context.Context, userID int64) error
+	ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error)
+	ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error)
 }
 
 // PaymentRepository интерфейс для работы с платежами
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *models.Payment) error
 	GetByPaymentID(ctx context.Context, paymentID string) (*models.Payment, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*models.Payment, error)
 	Update(ctx context.Context, payment *models.Payment) error
+	CountGiftsByGifter(ctx context.Context, gifterID int64, since time.Time) (int, error)
 }
 
 // YukassaClient интерфейс для работы с YooKassa API
@@ -45,9 +51,59 @@ func NewService(userRepo UserRepository, paymentRepo PaymentRepository, yukassa
 		paymentRepo: paymentRepo,
 		yukassa:     yukassa,
 		logger:      logger,
+		resetLoc:    time.UTC,
 	}
 }
 
+// SetResetLocation задает часовой пояс, в котором сбрасывается дневной лимит сообщений
+func (s *Service) SetResetLocation(loc *time.Location) {
+	if loc != nil {
+		s.resetLoc = loc
+	}
+}
+
+// ResetLocation возвращает часовой пояс дневного сброса лимитов
+func (s *Service) ResetLocation() *time.Location {
+	return s.resetLoc
+}
+
+// DefaultFreeMessageLimit дневной лимит сообщений бесплатного тарифа:
+// действует и для новых пользователей, и после окончания премиума
+const DefaultFreeMessageLimit = 7
+
+// MinLivePlanPrice минимальная цена плана в боевом режиме ЮKassa.
+// Цены ниже этого порога (1/2/3 RUB) используются только для тестовых платежей.
+const MinLivePlanPrice = 10.0
+
+// Параметры подарков премиума
+const (
+	GiftDurationDays    = 7  // Длительность подаренного премиума
+	MaxGiftsPerPeriod   = 3  // Максимум подарков от одного пользователя за период
+	GiftLimitPeriodDays = 30 // Период для подсчета лимита подарков
+)
+
+// Ошибки подарков премиума, которые показываются пользователю
+var (
+	ErrSelfGift          = errors.New("нельзя подарить премиум самому себе")
+	ErrGifterNotPremium  = errors.New("дарить премиум могут только пользователи с активной подпиской")
+	ErrGiftLimitExceeded = errors.New("превышен лимит подарков")
+	ErrPlanNotFound      = errors.New("план премиума не найден")
+)
+
+// ValidatePlans проверяет, что тестовые цены не попадут к реальным пользователям
+func (s *Service) ValidatePlans(testMode bool) error {
+	if testMode {
+		return nil
+	}
+	for _, plan := range s.GetPremiumPlans() {
+		if plan.Price < MinLivePlanPrice {
+			return fmt.Errorf("план %q имеет тестовую цену %.2f %s, недопустимую вне тестового режима ЮKassa",
+				plan.Name, plan.Price, plan.Currency)
+		}
+	}
+	return nil
+}
+
 // GetPremiumPlans возвращает доступные планы премиум-подписки
 func (s *Service) GetPremiumPlans() []models.PremiumPlan {
 	return []models.PremiumPlan{
@@ -112,7 +168,7 @@ func (s *Service) CreatePayment(ctx context.Context, userID int64, planID int) (
 	}
 
 	if selectedPlan == nil {
-		return nil, "", "", fmt.Errorf("план с ID %d не найден", planID)
+		return nil, "", "", fmt.Errorf("%w: ID %d", ErrPlanNotFound, planID)
 	}
 
 	// Создаем платеж через YooKassa
@@ -189,6 +245,11 @@ func (s *Service) GetPaymentByID(ctx context.Context, paymentID string) (*models
 	return s.paymentRepo.GetByPaymentID(ctx, paymentID)
 }
 
+// GetPaymentHistory возвращает все платежи пользователя, включая ожидающие и неудачные, от новых к старым
+func (s *Service) GetPaymentHistory(ctx context.Context, userID int64) ([]*models.Payment, error) {
+	return s.paymentRepo.GetByUserID(ctx, userID)
+}
+
 // UpdatePayment обновляет платеж
 func (s *Service) UpdatePayment(ctx context.Context, payment *models.Payment) error {
 	return s.paymentRepo.Update(ctx, payment)
@@ -224,6 +285,60 @@ func (s *Service) activatePremium(ctx context.Context, userID int64, durationDay
 	return nil
 }
 
+// GiftPremium дарит премиум-подписку другому пользователю.
+// Создает запись в payments с metadata.type = "gift" для отчетности и лимитов и в той же
+// транзакции продлевает премиум получателя: подарок не может сохраниться без премиума.
+func (s *Service) GiftPremium(ctx context.Context, gifterID, recipientID int64) (*models.Payment, error) {
+	if gifterID == recipientID {
+		return nil, ErrSelfGift
+	}
+
+	gifter, err := s.CheckPremiumStatus(ctx, gifterID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки премиума дарителя: %w", err)
+	}
+	if !gifter.IsPremium {
+		return nil, ErrGifterNotPremium
+	}
+
+	count, err := s.paymentRepo.CountGiftsByGifter(ctx, gifterID, time.Now().AddDate(0, 0, -GiftLimitPeriodDays))
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxGiftsPerPeriod {
+		return nil, ErrGiftLimitExceeded
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		UserID:              recipientID,
+		Amount:              0,
+		Currency:            "RUB",
+		PaymentID:           fmt.Sprintf("gift_%d_%d_%d", gifterID, recipientID, now.UnixNano()),
+		Status:              "completed",
+		PremiumDurationDays: GiftDurationDays,
+		CreatedAt:           now,
+		CompletedAt:         &now,
+		Metadata: map[string]any{
+			"type":      "gift",
+			"gifter_id": gifterID,
+		},
+	}
+
+	expiresAt, err := s.userRepo.ApplyPremiumGift(ctx, payment)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка активации подаренного премиума: %w", err)
+	}
+
+	s.logger.Info("премиум подарен",
+		zap.Int64("gifter_id", gifterID),
+		zap.Int64("recipient_id", recipientID),
+		zap.Int("duration_days", GiftDurationDays),
+		zap.Time("expires_at", expiresAt))
+
+	return payment, nil
+}
+
 // CheckPremiumStatus проверяет статус премиум-подписки пользователя
 func (s *Service) CheckPremiumStatus(ctx context.Context, userID int64) (*models.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -237,7 +352,7 @@ func (s *Service) CheckPremiumStatus(ctx context.Context, userID int64) (*models
 			// Премиум истек, деактивируем
 			user.IsPremium = false
 			user.PremiumExpiresAt = nil
-			user.MaxMessages = 50 // Возвращаем лимит
+			user.MaxMessages = DefaultFreeMessageLimit // Возвращаем лимит бесплатного тарифа
 
 			if err := s.userRepo.Update(ctx, user); err != nil {
 				s.logger.Error("ошибка деактивации премиума", zap.Error(err), zap.Int64("user_id", userID))
@@ -287,21 +402,48 @@ func (s *Service) IncrementMessageCount(ctx context.Context, userID int64) error
 	return err
 }
 
-// resetDailyCounterIfNeeded сбрасывает счетчик сообщений, если прошел день
+// calendarDate возвращает календарную дату момента t в часовом поясе loc.
+// Дата представлена полуночью UTC — так pgx читает колонку DATE, поэтому
+// результат можно сравнивать с MessagesResetDate и писать обратно в базу.
+func calendarDate(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// needsDailyReset проверяет, наступила ли в поясе loc новая дата после resetDate.
+// Сравниваются календарные даты, а не сутки по 24 часа, поэтому переходы на
+// летнее/зимнее время не сдвигают момент сброса.
+func needsDailyReset(resetDate, now time.Time, loc *time.Location) bool {
+	y, m, d := resetDate.Date()
+	stored := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return stored.Before(calendarDate(now, loc))
+}
+
+// NextResetAt возвращает момент ближайшей полуночи после now в поясе loc
+func NextResetAt(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	y, m, d := local.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
+
+// resetDailyCounterIfNeeded сбрасывает счетчик сообщений, если прошел день.
+// День считается в часовом поясе пользователя, а если он не задан — в поясе сброса.
 func (s *Service) resetDailyCounterIfNeeded(ctx context.Context, userID int64) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("ошибка получения пользователя: %w", err)
 	}
 
-	today := time.Now().Truncate(24 * time.Hour)
-	resetDate := user.MessagesResetDate.Truncate(24 * time.Hour)
+	now := time.Now()
+	loc := user.Location(s.resetLoc)
+
+	// Если дата сброса раньше сегодняшней, сбрасываем счетчик
+	if needsDailyReset(user.MessagesResetDate, now, loc) {
+		today := calendarDate(now, loc)
 
-	// Если дата сброса не сегодня, сбрасываем счетчик
-	if !resetDate.Equal(today) {
 		s.logger.Info("сбрасываем дневной счетчик сообщений",
 			zap.Int64("user_id", userID),
-			zap.Time("last_reset", resetDate),
+			zap.Time("last_reset", user.MessagesResetDate),
 			zap.Time("today", today))
 
 		user.MessagesCount = 0
@@ -315,6 +457,18 @@ func (s *Service) resetDailyCounterIfNeeded(ctx context.Context, userID int64) e
 	return nil
 }
 
+// ResetAllDailyCounters сбрасывает дневные счетчики всех пользователей без своего
+// часового пояса, у которых в поясе сброса уже наступил новый день
+func (s *Service) ResetAllDailyCounters(ctx context.Context) (int64, error) {
+	today := calendarDate(time.Now(), s.resetLoc)
+
+	reset, err := s.userRepo.ResetDailyMessageCounts(ctx, today)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сброса дневных счетчиков: %w", err)
+	}
+	return reset, nil
+}
+
 // GetUserStats возвращает статистику пользователя по сообщениям
 func (s *Service) GetUserStats(ctx context.Context, userID int64) (map[string]any, error) {
 	// Сначала проверяем и сбрасываем счетчик, если прошел день
@@ -337,7 +491,7 @@ func (s *Service) GetUserStats(ctx context.Context, userID int64) (map[string]an
 		if time.Now().After(*user.PremiumExpiresAt) {
 			// Премиум истек, но не изменяем данные здесь
 			isPremium = false
-			maxMessages = 50
+			maxMessages = DefaultFreeMessageLimit
 		}
 	}
 
@@ -352,7 +506,8 @@ func (s *Service) GetUserStats(ctx context.Context, userID int64) (map[string]an
 		stats["remaining_messages"] = "∞"
 		if user.PremiumExpiresAt != nil {
 			stats["remaining_messages"] = "∞"
-			stats["premium_expires_at"] = user.PremiumExpiresAt.Format("02.01.2006")
+			// Время, а не строка: дату форматирует бот по локали и поясу пользователя
+			stats["premium_expires_at"] = *user.PremiumExpiresAt
 		}
 	} else {
 		stats["remaining_messages"] = maxMessages - user.MessagesCount
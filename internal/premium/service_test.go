@@ -0,0 +1,176 @@
+package premium
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("часовой пояс %s недоступен: %v", name, err)
+	}
+	return loc
+}
+
+// dbDate имитирует значение колонки DATE, прочитанное pgx (полночь UTC)
+func dbDate(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNeedsDailyReset(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	cases := []struct {
+		name      string
+		resetDate time.Time
+		now       time.Time
+		loc       *time.Location
+		want      bool
+	}{
+		{
+			name:      "тот же день в UTC",
+			resetDate: dbDate(2025, 1, 10),
+			now:       time.Date(2025, 1, 10, 23, 59, 0, 0, time.UTC),
+			loc:       time.UTC,
+			want:      false,
+		},
+		{
+			name:      "полночь в Москве наступает раньше UTC",
+			resetDate: dbDate(2025, 1, 10),
+			now:       time.Date(2025, 1, 10, 21, 10, 0, 0, time.UTC), // 00:10 11 января по Москве
+			loc:       moscow,
+			want:      true,
+		},
+		{
+			name:      "в Нью-Йорке еще вчера, хотя в UTC уже новый день",
+			resetDate: dbDate(2025, 1, 10),
+			now:       time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC), // 22:00 10 января в Нью-Йорке
+			loc:       newYork,
+			want:      false,
+		},
+		{
+			name:      "дата сброса в будущем не вызывает повторных сбросов",
+			resetDate: dbDate(2025, 1, 11),
+			now:       time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC),
+			loc:       newYork,
+			want:      false,
+		},
+		{
+			name:      "ночь перехода на летнее время: день еще не сменился",
+			resetDate: dbDate(2025, 3, 30),
+			now:       time.Date(2025, 3, 30, 21, 30, 0, 0, time.UTC), // 23:30 CEST
+			loc:       berlin,
+			want:      false,
+		},
+		{
+			name:      "день после перехода на летнее время",
+			resetDate: dbDate(2025, 3, 30),
+			now:       time.Date(2025, 3, 30, 22, 5, 0, 0, time.UTC), // 00:05 31 марта CEST
+			loc:       berlin,
+			want:      true,
+		},
+		{
+			name:      "ночь перехода на зимнее время",
+			resetDate: dbDate(2025, 10, 25),
+			now:       time.Date(2025, 10, 25, 22, 30, 0, 0, time.UTC), // 00:30 26 октября CEST
+			loc:       berlin,
+			want:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		if got := needsDailyReset(tc.resetDate, tc.now, tc.loc); got != tc.want {
+			t.Errorf("%s: ожидалось %v, получено %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCalendarDate(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+
+	got := calendarDate(time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC), moscow)
+	if !got.Equal(dbDate(2025, 1, 11)) {
+		t.Errorf("ожидалось 2025-01-11, получено %v", got)
+	}
+}
+
+func TestNextResetAt(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	// С 29 на 30 марта сутки короче на час: до полуночи 23 часа
+	now := time.Date(2025, 3, 30, 0, 0, 0, 0, berlin)
+	next := NextResetAt(now, berlin)
+	if want := time.Date(2025, 3, 31, 0, 0, 0, 0, berlin); !next.Equal(want) {
+		t.Errorf("ожидалось %v, получено %v", want, next)
+	}
+	if d := next.Sub(now); d != 23*time.Hour {
+		t.Errorf("ожидалось 23h до полуночи, получено %v", d)
+	}
+}
+
+// giftPayments считает подарки, сохраненные вместе с премиумом получателя
+type giftPayments struct {
+	PaymentRepository
+	users *memoryUsers
+}
+
+func (r *giftPayments) CountGiftsByGifter(ctx context.Context, gifterID int64, since time.Time) (int, error) {
+	return len(r.users.gifts), nil
+}
+
+func TestGiftPremium(t *testing.T) {
+	inTenDays := time.Now().AddDate(0, 0, 10)
+	tests := []struct {
+		name        string
+		recipient   *models.User // nil — получателя нет в базе
+		wantExpires time.Time
+	}{
+		{"бесплатному пользователю", &models.User{ID: 2, MaxMessages: DefaultFreeMessageLimit}, time.Now().AddDate(0, 0, GiftDurationDays)},
+		{"продлевает активный премиум", &models.User{ID: 2, IsPremium: true, PremiumExpiresAt: &inTenDays}, inTenDays.AddDate(0, 0, GiftDurationDays)},
+		{"неизвестному пользователю", nil, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gifterExpires := time.Now().AddDate(0, 1, 0)
+			users := &memoryUsers{users: map[int64]*models.User{
+				1: {ID: 1, IsPremium: true, PremiumExpiresAt: &gifterExpires},
+			}}
+			if tt.recipient != nil {
+				users.users[2] = tt.recipient
+			}
+			service := NewService(users, &giftPayments{users: users}, nil, zap.NewNop())
+
+			payment, err := service.GiftPremium(context.Background(), 1, 2)
+			if tt.recipient == nil {
+				if err == nil || len(users.gifts) != 0 {
+					t.Fatalf("подарок неизвестному пользователю не должен сохраняться: err=%v, подарков %d", err, len(users.gifts))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ошибка подарка: %v", err)
+			}
+			if payment.Metadata["type"] != "gift" || len(users.gifts) != 1 {
+				t.Errorf("ожидался один сохраненный подарок, получено %+v (%d)", payment, len(users.gifts))
+			}
+
+			got := users.users[2]
+			if !got.IsPremium || got.MaxMessages != 0 {
+				t.Errorf("получатель должен стать премиум без лимита, получено %+v", got)
+			}
+			if diff := got.PremiumExpiresAt.Sub(tt.wantExpires); diff < -time.Minute || diff > time.Minute {
+				t.Errorf("ожидалось окончание %v, получено %v", tt.wantExpires, got.PremiumExpiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package pronunciation оценивает, насколько распознанная речь совпадает с ожидаемым текстом
+package pronunciation
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// WordMatchThreshold минимальная похожесть слова, при которой оно считается произнесенным верно.
+// Допускает одну ошибку распознавания в словах от пяти букв.
+const WordMatchThreshold = 0.8
+
+// Word слово ожидаемого текста и признак того, что оно было произнесено
+type Word struct {
+	Text    string
+	Matched bool
+}
+
+// Result результат сравнения произношения с ожидаемым текстом
+type Result struct {
+	Score int    // похожесть в процентах, 0-100
+	Words []Word // слова ожидаемого текста в исходном виде и порядке
+}
+
+// Mismatched возвращает слова ожидаемого текста, которые не были распознаны
+func (r Result) Mismatched() []string {
+	var words []string
+	for _, word := range r.Words {
+		if !word.Matched {
+			words = append(words, word.Text)
+		}
+	}
+	return words
+}
+
+// Compare сравнивает распознанный текст с ожидаемым.
+// Оценка — нормализованное расстояние Левенштейна по символам без учета регистра и пунктуации,
+// совпадение слов определяется выравниванием слов ожидаемого текста по распознанным.
+func Compare(expected, heard string) Result {
+	expectedWords := strings.Fields(expected)
+	normExpected := normalizeWords(expected)
+	normHeard := normalizeWords(heard)
+
+	result := Result{
+		Score: int(math.Round(Similarity(strings.Join(normExpected, " "), strings.Join(normHeard, " ")) * 100)),
+		Words: make([]Word, 0, len(expectedWords)),
+	}
+
+	matched := alignWords(normExpected, normHeard)
+	for _, text := range expectedWords {
+		word := Word{Text: text}
+		// Составное слово (well-known) при нормализации делится на части: все должны совпасть.
+		// Знаки без букв (тире, &) частей не дают и считаются совпавшими.
+		parts := len(normalizeWords(text))
+		word.Matched = true
+		for _, ok := range matched[:parts] {
+			word.Matched = word.Matched && ok
+		}
+		matched = matched[parts:]
+		result.Words = append(result.Words, word)
+	}
+	return result
+}
+
+// CountWords возвращает число слов текста без учета пунктуации
+func CountWords(text string) int {
+	return len(normalizeWords(text))
+}
+
+// Similarity возвращает похожесть строк от 0 до 1: 1 минус расстояние Левенштейна,
+// деленное на длину более длинной строки
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// alignWords выравнивает слова так, чтобы совпало как можно больше похожих пар,
+// и возвращает признак совпадения для каждого ожидаемого слова
+func alignWords(expected, heard []string) []bool {
+	// Наибольшая общая подпоследовательность с похожестью вместо точного равенства
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(heard)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(heard) - 1; j >= 0; j-- {
+			if wordsMatch(expected[i], heard[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	matched := make([]bool, len(expected))
+	for i, j := 0, 0; i < len(expected) && j < len(heard); {
+		switch {
+		case wordsMatch(expected[i], heard[j]):
+			matched[i] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matched
+}
+
+// wordsMatch проверяет, достаточно ли похожи два нормализованных слова
+func wordsMatch(a, b string) bool {
+	return a == b || Similarity(a, b) >= WordMatchThreshold
+}
+
+// normalizeWords приводит текст к словам в нижнем регистре без пунктуации
+func normalizeWords(text string) []string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '\'':
+			return unicode.ToLower(r)
+		case r == '’':
+			return '\''
+		default:
+			return ' '
+		}
+	}, text)
+	return strings.Fields(cleaned)
+}
+
+// levenshtein вычисляет расстояние Левенштейна между двумя строками
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,108 @@
+package pronunciation
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"apple", "apple", 1},
+		{"apple", "", 0},
+		{"apple", "aple", 0.8},
+		{"kitten", "sitting", 1 - 3.0/7},
+	}
+
+	for _, tc := range cases {
+		if got := Similarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, ожидалось %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		name       string
+		expected   string
+		heard      string
+		score      int
+		mismatched []string
+	}{
+		{
+			name:     "точное совпадение без учета регистра и пунктуации",
+			expected: "I drink coffee every morning.",
+			heard:    "i drink coffee every morning",
+			score:    100,
+		},
+		{
+			name:       "искаженное слово",
+			expected:   "The weather is nice",
+			heard:      "The whether is nice",
+			score:      89,
+			mismatched: []string{"weather"},
+		},
+		{
+			name:       "пропущенное слово",
+			expected:   "She goes to work",
+			heard:      "She goes work",
+			score:      81,
+			mismatched: []string{"to"},
+		},
+		{
+			name:     "мелкая ошибка распознавания в длинном слове",
+			expected: "beautiful",
+			heard:    "beautifull",
+			score:    90,
+		},
+		{
+			name:       "составное слово",
+			expected:   "a well-known fact",
+			heard:      "a well shown fact",
+			score:      88,
+			mismatched: []string{"well-known"},
+		},
+		{
+			name:       "ничего не распознано",
+			expected:   "Hello",
+			heard:      "",
+			score:      0,
+			mismatched: []string{"Hello"},
+		},
+	}
+
+	for _, tc := range cases {
+		result := Compare(tc.expected, tc.heard)
+		if result.Score != tc.score {
+			t.Errorf("%s: оценка %d, ожидалось %d", tc.name, result.Score, tc.score)
+		}
+		if got := strings.Join(result.Mismatched(), "|"); got != strings.Join(tc.mismatched, "|") {
+			t.Errorf("%s: несовпавшие слова %q, ожидалось %q", tc.name, result.Mismatched(), tc.mismatched)
+		}
+	}
+}
+
+func TestCompareKeepsExpectedWordOrder(t *testing.T) {
+	result := Compare("Nice to meet you!", "nice meet you")
+	if mismatched := result.Mismatched(); len(mismatched) != 1 || mismatched[0] != "to" {
+		t.Errorf("ожидалось одно пропущенное слово, получено %q", mismatched)
+	}
+
+	var words []string
+	for _, word := range result.Words {
+		words = append(words, word.Text)
+	}
+	if strings.Join(words, " ") != "Nice to meet you!" {
+		t.Errorf("слова должны идти в исходном виде и порядке, получено %q", words)
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	if got := CountWords("I don't like it — really!"); got != 5 {
+		t.Errorf("CountWords = %d, ожидалось 5", got)
+	}
+}
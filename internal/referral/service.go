@@ -11,11 +11,28 @@ import (
 	"go.uber.org/zap"
 )
 
+// RewardPolicy задает правила начисления премиума за рефералов
+type RewardPolicy struct {
+	ReferralsPerReward int // Сколько засчитанных рефералов дают месяц премиума
+	MaxRewards         int // Сколько месяцев премиума можно получить за рефералов за все время
+	MinMessages        int // Сколько сообщений должен отправить приглашенный, чтобы реферал засчитался
+	MinActiveDays      int // В скольких разных днях приглашенный должен писать боту
+}
+
+// DefaultRewardPolicy правила начисления наград по умолчанию
+var DefaultRewardPolicy = RewardPolicy{
+	ReferralsPerReward: 10,
+	MaxRewards:         3,
+	MinMessages:        5,
+	MinActiveDays:      2,
+}
+
 // Service представляет сервис для управления реферальной системой
 type Service struct {
 	referralRepo store.ReferralRepository
 	userRepo     store.UserRepository
 	logger       *zap.Logger
+	policy       RewardPolicy
 }
 
 // NewService создает новый сервис рефералов
@@ -24,9 +41,23 @@ func NewService(referralRepo store.ReferralRepository, userRepo store.UserReposi
 		referralRepo: referralRepo,
 		userRepo:     userRepo,
 		logger:       logger,
+		policy:       DefaultRewardPolicy,
 	}
 }
 
+// SetRewardPolicy задает правила начисления наград за рефералов
+func (s *Service) SetRewardPolicy(policy RewardPolicy) {
+	if policy.ReferralsPerReward <= 0 {
+		policy.ReferralsPerReward = DefaultRewardPolicy.ReferralsPerReward
+	}
+	s.policy = policy
+}
+
+// RewardPolicy возвращает текущие правила начисления наград
+func (s *Service) RewardPolicy() RewardPolicy {
+	return s.policy
+}
+
 // GetOrGenerateReferralCode получает существующий или генерирует новый реферальный код
 func (s *Service) GetOrGenerateReferralCode(ctx context.Context, userID int64) (string, error) {
 	// Получаем пользователя
@@ -110,42 +141,16 @@ func (s *Service) CreateReferral(ctx context.Context, referrerID, referredID int
 	}
 
 	// Обновляем поле referred_by у приглашенного пользователя
-	referredUser, err := s.userRepo.GetByID(ctx, referredID)
-	if err != nil {
-		s.logger.Error("ошибка получения приглашенного пользователя", zap.Error(err))
+	if err := s.userRepo.SetReferredBy(ctx, referredID, referrerID); err != nil {
+		s.logger.Error("ошибка обновления referred_by", zap.Error(err))
 		// Не возвращаем ошибку, так как реферал уже создан
-	} else {
-		referredUser.ReferredBy = &referrerID
-		if err := s.userRepo.Update(ctx, referredUser); err != nil {
-			s.logger.Error("ошибка обновления referred_by", zap.Error(err))
-			// Не возвращаем ошибку, так как реферал уже создан
-		}
 	}
 
-	// Обновляем счетчик referral_count у реферера
-	referrerUser, err := s.userRepo.GetByID(ctx, referrerID)
-	if err != nil {
-		s.logger.Error("ошибка получения реферера", zap.Error(err))
+	// Обновляем счетчик referral_count у реферера.
+	// Премиум здесь не начисляется: награда дается только за засчитанных рефералов в ActivateReferral
+	if err := s.userRepo.IncrementReferralCount(ctx, referrerID); err != nil {
+		s.logger.Error("ошибка обновления referral_count", zap.Error(err))
 		// Не возвращаем ошибку, так как реферал уже создан
-	} else {
-		referrerUser.ReferralCount++
-
-		// Проверяем, достиг ли пользователь 10 приглашений для получения премиума
-		if referrerUser.ReferralCount >= 10 && !referrerUser.IsPremium {
-			// Предоставляем премиум на месяц
-			referrerUser.IsPremium = true
-			premiumExpiry := time.Now().AddDate(0, 1, 0) // +1 месяц
-			referrerUser.PremiumExpiresAt = &premiumExpiry
-
-			s.logger.Info("пользователь получил премиум за рефералы",
-				zap.Int64("user_id", referrerID),
-				zap.Int("referral_count", referrerUser.ReferralCount))
-		}
-
-		if err := s.userRepo.Update(ctx, referrerUser); err != nil {
-			s.logger.Error("ошибка обновления referral_count", zap.Error(err))
-			// Не возвращаем ошибку, так как реферал уже создан
-		}
 	}
 
 	s.logger.Info("создан новый реферал",
@@ -155,30 +160,101 @@ func (s *Service) CreateReferral(ctx context.Context, referrerID, referredID int
 	return nil
 }
 
-// ActivateReferral активирует реферал (когда приглашенный пользователь становится активным)
-func (s *Service) ActivateReferral(ctx context.Context, referredID int64) error {
+// ActivateReferral засчитывает реферал, когда приглашенный пользователь проявил
+// достаточную активность, и начисляет награду приглашающему.
+// Возвращает true, если реферал был засчитан этим вызовом.
+func (s *Service) ActivateReferral(ctx context.Context, referredID int64) (bool, error) {
 	// Получаем реферал
 	referral, err := s.referralRepo.GetReferralByReferredID(ctx, referredID)
 	if err != nil {
-		return fmt.Errorf("реферал не найден: %w", err)
+		return false, fmt.Errorf("реферал не найден: %w", err)
 	}
 
-	// Проверяем, что статус еще не завершен
-	if referral.Status == string(models.ReferralStatusCompleted) {
-		return fmt.Errorf("реферал уже активирован")
+	// Засчитанные и отмененные рефералы повторно не обрабатываем
+	if referral.Status != string(models.ReferralStatusPending) {
+		return false, nil
+	}
+
+	messages, activeDays, err := s.referralRepo.GetReferredEngagement(ctx, referredID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки активности приглашенного: %w", err)
+	}
+	if !s.isEngaged(messages, activeDays) {
+		s.logger.Info("реферал пока не засчитан: недостаточная активность приглашенного",
+			zap.Int64("referral_id", referral.ID),
+			zap.Int64("referrer_id", referral.ReferrerID),
+			zap.Int64("referred_id", referredID),
+			zap.Int("messages", messages),
+			zap.Int("active_days", activeDays),
+			zap.Int("min_messages", s.policy.MinMessages),
+			zap.Int("min_active_days", s.policy.MinActiveDays))
+		return false, nil
 	}
 
 	// Обновляем статус на completed
 	now := time.Now()
-	err = s.referralRepo.UpdateReferralStatus(ctx, referral.ID, string(models.ReferralStatusCompleted), &now)
+	_, err = s.referralRepo.UpdateReferralStatus(ctx, referral.ID, string(models.ReferralStatusCompleted), &now)
 	if err != nil {
-		return fmt.Errorf("ошибка активации реферала: %w", err)
+		return false, fmt.Errorf("ошибка активации реферала: %w", err)
 	}
+	// Триггер по таблице referrals пересчитал referral_count пригласившего
+	s.invalidateUser(referral.ReferrerID)
 
 	s.logger.Info("реферал активирован",
 		zap.Int64("referral_id", referral.ID),
 		zap.Int64("referred_id", referredID))
 
+	if err := s.evaluateReward(ctx, referral.ReferrerID); err != nil {
+		// Реферал уже засчитан, награду проверим при следующей активации
+		s.logger.Error("ошибка начисления награды за рефералов",
+			zap.Error(err),
+			zap.Int64("referrer_id", referral.ReferrerID))
+	}
+
+	return true, nil
+}
+
+// isEngaged проверяет, достаточно ли активен приглашенный пользователь
+func (s *Service) isEngaged(messages, activeDays int) bool {
+	return messages >= s.policy.MinMessages && activeDays >= s.policy.MinActiveDays
+}
+
+// earnedRewards возвращает число наград, заработанных за completed рефералов (без учета лимита)
+func (s *Service) earnedRewards(completed int) int {
+	return completed / s.policy.ReferralsPerReward
+}
+
+// evaluateReward начисляет приглашающему месяц премиума за каждые
+// ReferralsPerReward засчитанных рефералов, но не больше MaxRewards за все время
+func (s *Service) evaluateReward(ctx context.Context, referrerID int64) error {
+	completed, err := s.referralRepo.CountCompletedReferrals(ctx, referrerID)
+	if err != nil {
+		return err
+	}
+
+	earned := s.earnedRewards(completed)
+	if earned == 0 {
+		return nil
+	}
+
+	granted, err := s.userRepo.GrantReferralReward(ctx, referrerID, earned, s.policy.MaxRewards)
+	if err != nil {
+		return err
+	}
+
+	if granted {
+		s.logger.Info("пользователь получил премиум за рефералов",
+			zap.Int64("user_id", referrerID),
+			zap.Int("completed_referrals", completed))
+		return nil
+	}
+
+	if earned > s.policy.MaxRewards {
+		s.logger.Warn("награда за рефералов не начислена: исчерпан лимит",
+			zap.Int64("user_id", referrerID),
+			zap.Int("completed_referrals", completed),
+			zap.Int("max_rewards", s.policy.MaxRewards))
+	}
 	return nil
 }
 
@@ -189,6 +265,11 @@ func (s *Service) GetReferralStats(ctx context.Context, userID int64) (*models.R
 		return nil, fmt.Errorf("ошибка получения статистики рефералов: %w", err)
 	}
 
+	stats.MaxRewards = s.policy.MaxRewards
+	if user, err := s.userRepo.GetByID(ctx, userID); err == nil {
+		stats.RewardsEarned = user.ReferralRewardMonths
+	}
+
 	return stats, nil
 }
 
@@ -212,7 +293,7 @@ func (s *Service) ValidateReferralCode(ctx context.Context, referralCode string)
 	// Получаем пользователя по коду
 	user, err := s.referralRepo.GetUserByReferralCode(ctx, referralCode)
 	if err != nil {
-		return nil, fmt.Errorf("неверный реферальный код")
+		return nil, fmt.Errorf("неверный реферальный код: %w", err)
 	}
 
 	return user, nil
@@ -220,11 +301,19 @@ func (s *Service) ValidateReferralCode(ctx context.Context, referralCode string)
 
 // CancelReferral отменяет реферал
 func (s *Service) CancelReferral(ctx context.Context, referralID int64) error {
-	err := s.referralRepo.UpdateReferralStatus(ctx, referralID, string(models.ReferralStatusCancelled), nil)
+	referrerID, err := s.referralRepo.UpdateReferralStatus(ctx, referralID, string(models.ReferralStatusCancelled), nil)
 	if err != nil {
 		return fmt.Errorf("ошибка отмены реферала: %w", err)
 	}
+	s.invalidateUser(referrerID)
 
 	s.logger.Info("реферал отменен", zap.Int64("referral_id", referralID))
 	return nil
 }
+
+// invalidateUser сбрасывает кэш пользователя, измененного в обход репозитория пользователей
+func (s *Service) invalidateUser(userID int64) {
+	if invalidator, ok := s.userRepo.(store.UserCacheInvalidator); ok {
+		invalidator.InvalidateUser(userID)
+	}
+}
@@ -0,0 +1,142 @@
+package referral
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// fakeReferralRepo хранит рефералы в памяти
+type fakeReferralRepo struct {
+	store.ReferralRepository
+	referrals  map[int64]*models.Referral // по referred_id
+	messages   int
+	activeDays int
+}
+
+func (r *fakeReferralRepo) GetReferralByReferredID(ctx context.Context, referredID int64) (*models.Referral, error) {
+	return r.referrals[referredID], nil
+}
+
+func (r *fakeReferralRepo) UpdateReferralStatus(ctx context.Context, referralID int64, status string, completedAt *time.Time) (int64, error) {
+	for _, ref := range r.referrals {
+		if ref.ID == referralID {
+			ref.Status = status
+			return ref.ReferrerID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *fakeReferralRepo) CountCompletedReferrals(ctx context.Context, userID int64) (int, error) {
+	count := 0
+	for _, ref := range r.referrals {
+		if ref.ReferrerID == userID && ref.Status == string(models.ReferralStatusCompleted) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeReferralRepo) GetReferredEngagement(ctx context.Context, referredID int64) (int, int, error) {
+	return r.messages, r.activeDays, nil
+}
+
+// fakeUserRepo повторяет условие GrantReferralReward из PostgreSQL
+type fakeUserRepo struct {
+	store.UserRepository
+	rewards     map[int64]int
+	invalidated []int64
+}
+
+func (r *fakeUserRepo) InvalidateUser(userID int64) {
+	r.invalidated = append(r.invalidated, userID)
+}
+
+func (r *fakeUserRepo) GrantReferralReward(ctx context.Context, userID int64, earned, maxRewards int) (bool, error) {
+	if r.rewards[userID] >= min(earned, maxRewards) {
+		return false, nil
+	}
+	r.rewards[userID]++
+	return true, nil
+}
+
+func newTestService(referrals int) (*Service, *fakeReferralRepo, *fakeUserRepo) {
+	refRepo := &fakeReferralRepo{referrals: make(map[int64]*models.Referral)}
+	for i := 1; i <= referrals; i++ {
+		refRepo.referrals[int64(100+i)] = &models.Referral{
+			ID:         int64(i),
+			ReferrerID: 1,
+			ReferredID: int64(100 + i),
+			Status:     string(models.ReferralStatusPending),
+		}
+	}
+	userRepo := &fakeUserRepo{rewards: make(map[int64]int)}
+	s := NewService(refRepo, userRepo, zap.NewNop())
+	s.SetRewardPolicy(RewardPolicy{ReferralsPerReward: 2, MaxRewards: 2, MinMessages: 5, MinActiveDays: 2})
+	return s, refRepo, userRepo
+}
+
+func TestActivateReferralRequiresEngagement(t *testing.T) {
+	s, refRepo, _ := newTestService(1)
+	ctx := context.Background()
+
+	refRepo.messages, refRepo.activeDays = 10, 1
+	activated, err := s.ActivateReferral(ctx, 101)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if activated {
+		t.Fatal("реферал не должен засчитываться при активности в один день")
+	}
+	if refRepo.referrals[101].Status != string(models.ReferralStatusPending) {
+		t.Errorf("ожидался статус pending, получено %s", refRepo.referrals[101].Status)
+	}
+
+	refRepo.messages, refRepo.activeDays = 5, 2
+	activated, err = s.ActivateReferral(ctx, 101)
+	if err != nil || !activated {
+		t.Fatalf("ожидалась активация, получено %v, %v", activated, err)
+	}
+
+	activated, err = s.ActivateReferral(ctx, 101)
+	if err != nil || activated {
+		t.Errorf("повторная активация должна игнорироваться, получено %v, %v", activated, err)
+	}
+}
+
+func TestActivateReferralRewardCap(t *testing.T) {
+	s, refRepo, userRepo := newTestService(8)
+	ctx := context.Background()
+	refRepo.messages, refRepo.activeDays = 5, 2
+
+	for id := int64(101); id <= 108; id++ {
+		if _, err := s.ActivateReferral(ctx, id); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+
+	// 8 рефералов по 2 на награду дали бы 4 месяца, но лимит — 2
+	if got := userRepo.rewards[1]; got != 2 {
+		t.Errorf("ожидалось 2 награды с учетом лимита, получено %d", got)
+	}
+}
+
+func TestActivateReferralInvalidatesReferrer(t *testing.T) {
+	s, refRepo, userRepo := newTestService(1)
+	refRepo.messages, refRepo.activeDays = 5, 2
+
+	if _, err := s.ActivateReferral(context.Background(), 101); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	// Триггер пересчитал referral_count, кэшированная копия пригласившего устарела
+	if len(userRepo.invalidated) == 0 || userRepo.invalidated[0] != 1 {
+		t.Errorf("ожидалась инвалидация пригласившего, получено %v", userRepo.invalidated)
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/backup"
+)
+
+// BackupJob периодически выгружает ключевые таблицы в хранилище
+type BackupJob struct {
+	exporter *backup.Exporter
+	interval time.Duration
+	lastRun  time.Time
+	lastRows int64
+	logger   *zap.Logger
+}
+
+// NewBackupJob создает джобу выгрузки таблиц
+func NewBackupJob(exporter *backup.Exporter, interval time.Duration, logger *zap.Logger) *BackupJob {
+	return &BackupJob{
+		exporter: exporter,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *BackupJob) Name() string {
+	return "backup"
+}
+
+// LastRowsProcessed возвращает число строк, выгруженных последним запуском
+func (j *BackupJob) LastRowsProcessed() int64 {
+	return j.lastRows
+}
+
+// Run запускает выгрузку, если с прошлого запуска прошло не меньше интервала
+func (j *BackupJob) Run(ctx context.Context) error {
+	// Планировщик общий для всех джоб, поэтому интервал выгрузки отслеживаем сами
+	if !IsManualRun(ctx) && !j.lastRun.IsZero() && time.Since(j.lastRun) < j.interval {
+		j.logger.Debug("выгрузка таблиц пропущена, интервал еще не прошел",
+			zap.Time("last_run", j.lastRun))
+		j.lastRows = 0
+		return nil
+	}
+
+	j.logger.Info("запуск джобы выгрузки таблиц")
+
+	result, err := j.exporter.Export(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка выгрузки таблиц: %w", err)
+	}
+	j.lastRun = time.Now()
+	j.lastRows = 0
+	for _, count := range result.Rows {
+		j.lastRows += count
+	}
+
+	fields := []zap.Field{
+		zap.Duration("duration", result.Duration),
+		zap.Int("removed_files", result.Removed),
+	}
+	for table, count := range result.Rows {
+		fields = append(fields, zap.Int64("rows_"+table, count))
+	}
+	j.logger.Info("джоба выгрузки таблиц завершена", fields...)
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+)
+
+// Значения по умолчанию для ежедневных напоминаний
+const (
+	DefaultDailyReminderHour = 10 // час отправки в поясе пользователя
+	DefaultDailyReminderRate = 20 // сообщений в секунду: ниже лимита Telegram в 30 сообщений
+)
+
+// dailyReminderPhrases мотивирующие фразы, чередуются по дням
+var dailyReminderPhrases = []string{
+	"Пара минут практики сегодня — и завтра слова вспомнятся легче.",
+	"Маленький шаг каждый день работает лучше, чем марафон раз в неделю.",
+	"Новые слова ждут тебя: одно упражнение — и день засчитан.",
+	"Регулярность — главный секрет тех, кто заговорил на языке.",
+}
+
+// localTimeJobStep как часто джобы, которые отправляют сообщения по часам в поясе
+// пользователя, проверяют, у кого наступило время отправки. Кратно 15 минутам,
+// чтобы попадать и в пояса со смещением :30 и :45.
+const localTimeJobStep = 15 * time.Minute
+
+// DailyReminderJob раз в день в фиксированный час по поясу пользователя мягко напоминает
+// о занятиях тем, кто сегодня еще не занимался, но заходил в бота на этой неделе
+type DailyReminderJob struct {
+	userService *user.Service
+	bot         Sender
+	logger      *zap.Logger
+	loc         *time.Location // пояс пользователей, не выбравших свой
+	hour        int            // час отправки в поясе пользователя
+	interval    time.Duration  // пауза между сообщениями, чтобы не упираться в flood control
+	now         func() time.Time
+	wait        func(ctx context.Context, d time.Duration) error
+	lastSent    int64
+}
+
+// NewDailyReminderJob создает джобу ежедневных напоминаний. Сутки считаются в поясе
+// пользователя, а если он не выбран — в поясе loc, том же, в котором сбрасываются
+// дневные лимиты; rate — сколько напоминаний можно отправить в секунду.
+func NewDailyReminderJob(userService *user.Service, bot Sender, loc *time.Location, hour, rate int, logger *zap.Logger) *DailyReminderJob {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if hour < 0 || hour > 23 {
+		hour = DefaultDailyReminderHour
+	}
+	if rate <= 0 {
+		rate = DefaultDailyReminderRate
+	}
+	return &DailyReminderJob{
+		userService: userService,
+		bot:         bot,
+		logger:      logger,
+		loc:         loc,
+		hour:        hour,
+		interval:    time.Second / time.Duration(rate),
+		now:         time.Now,
+		wait:        waitContext,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *DailyReminderJob) Name() string {
+	return "daily_reminder"
+}
+
+// LastRowsProcessed возвращает число напоминаний, отправленных последним запуском
+func (j *DailyReminderJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
+// NextRunAt возвращает ближайшую проверку: час отправки у пользователей из разных
+// поясов наступает в разное время, поэтому джоба запускается каждые localTimeJobStep
+func (j *DailyReminderJob) NextRunAt(now time.Time) time.Time {
+	return now.Truncate(localTimeJobStep).Add(localTimeJobStep)
+}
+
+// Run отправляет напоминания пользователям, у которых в их поясе уже наступил час отправки.
+// Ручной запуск через админку не ждет часа отправки.
+func (j *DailyReminderJob) Run(ctx context.Context) error {
+	now := j.now()
+	manual := IsManualRun(ctx)
+
+	// У пользователя, которому пора напомнить, сутки начались не позже now-hour:
+	// с этой границы он не занимался и не получал напоминание. Точно сутки считаются
+	// по его поясу ниже.
+	studiedBefore := now.Add(-time.Duration(j.hour) * time.Hour)
+	if manual {
+		studiedBefore = now
+	}
+	users, err := j.userService.GetDailyReminderUsers(ctx, studiedBefore)
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей для ежедневного напоминания: %w", err)
+	}
+
+	j.lastSent = 0
+	for _, u := range users {
+		dayStart, due := dailyReminderDue(now, u, j.loc, j.hour)
+		if !due && !manual {
+			continue
+		}
+		if !u.LastStudyDate.Before(dayStart) {
+			continue
+		}
+		if u.DailyReminderSentAt != nil && !u.DailyReminderSentAt.Before(dayStart) {
+			continue
+		}
+
+		// Отмечаем до отправки, чтобы параллельный запуск не отправил напоминание дважды
+		marked, err := j.userService.MarkDailyReminderSent(ctx, u.ID, dayStart)
+		if err != nil {
+			j.logger.Error("ошибка отметки ежедневного напоминания", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if j.lastSent > 0 {
+			if err := j.wait(ctx, j.interval); err != nil {
+				return err
+			}
+		}
+		if _, err := j.bot.Send(j.reminderMessage(u, now)); err != nil {
+			j.logger.Error("ошибка отправки ежедневного напоминания", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		j.lastSent++
+	}
+
+	if j.lastSent > 0 || manual {
+		j.logger.Info("ежедневные напоминания отправлены",
+			zap.Int64("sent", j.lastSent),
+			zap.Int("candidates", len(users)))
+	}
+	return nil
+}
+
+// reminderMessage формирует напоминание с текущей серией и кнопками
+func (j *DailyReminderJob) reminderMessage(u *models.User, now time.Time) tgbotapi.MessageConfig {
+	phrase := dailyReminderPhrases[now.In(u.Location(j.loc)).YearDay()%len(dailyReminderPhrases)]
+
+	streak := "Начни новую серию занятий уже сегодня 🌱"
+	if u.StudyStreak > 0 {
+		streak = fmt.Sprintf("🔥 Твоя серия: <b>%d</b> — продолжим?", u.StudyStreak)
+	}
+
+	text := fmt.Sprintf("👋 <b>Время позаниматься!</b>\n\n%s\n\n%s", phrase, streak)
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 К карточкам", "flashcard_start"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Не напоминать", models.DailyReminderOffCallback),
+		),
+	)
+	return msg
+}
+
+// dailyReminderDue возвращает начало текущих суток в поясе пользователя (без пояса — в fallback)
+// и наступил ли у него час отправки
+func dailyReminderDue(now time.Time, u *models.User, fallback *time.Location, hour int) (dayStart time.Time, due bool) {
+	at, dayStart := dailyReminderTime(now, u.Location(fallback), hour)
+	return dayStart, !now.Before(at)
+}
+
+// dailyReminderTime возвращает час отправки и начало текущих суток в поясе loc
+func dailyReminderTime(now time.Time, loc *time.Location, hour int) (at, dayStart time.Time) {
+	y, m, d := now.In(loc).Date()
+	dayStart = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	return dayStart.Add(time.Duration(hour) * time.Hour), dayStart
+}
+
+// waitContext ждет d или отмены контекста
+func waitContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
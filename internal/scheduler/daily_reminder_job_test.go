@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestDailyReminderNextRunAt(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	job := NewDailyReminderJob(nil, nil, loc, 10, 20, zap.NewNop())
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 10, 16, 8, 0, 0, 0, loc), time.Date(2026, 10, 16, 8, 15, 0, 0, loc)},
+		{time.Date(2026, 10, 16, 9, 59, 0, 0, loc), time.Date(2026, 10, 16, 10, 0, 0, 0, loc)},
+		// Пояса со смещением :30 тоже попадают в шаг проверки
+		{time.Date(2026, 10, 16, 10, 20, 0, 0, time.FixedZone("IST", 5*60*60+30*60)), time.Date(2026, 10, 16, 10, 30, 0, 0, time.FixedZone("IST", 5*60*60+30*60))},
+	}
+	for _, tt := range tests {
+		if got := job.NextRunAt(tt.now); !got.Equal(tt.want) {
+			t.Errorf("для %v ожидалось %v, получено %v", tt.now, tt.want, got)
+		}
+	}
+}
+
+func TestDailyReminderDueUsesUserTimezone(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 10, 16, 6, 30, 0, 0, time.UTC) // 09:30 по Москве, 13:30 в Новосибирске, 07:30 в Лондоне
+
+	tests := []struct {
+		name         string
+		timezone     string
+		wantDue      bool
+		wantDayStart time.Time
+	}{
+		{"пояс бота", "", true, time.Date(2026, 10, 16, 0, 0, 0, 0, msk)},
+		{"час уже прошел", "Asia/Novosibirsk", true, time.Date(2026, 10, 15, 17, 0, 0, 0, time.UTC)},
+		{"час еще не наступил", "Europe/London", false, time.Date(2026, 10, 15, 23, 0, 0, 0, time.UTC)},
+		{"ночь", "America/New_York", false, time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		dayStart, due := dailyReminderDue(now, &models.User{Timezone: tt.timezone}, msk, 9)
+		if due != tt.wantDue || !dayStart.Equal(tt.wantDayStart) {
+			t.Errorf("%s: ожидалось %v/%v, получено %v/%v", tt.name, tt.wantDayStart, tt.wantDue, dayStart, due)
+		}
+	}
+}
+
+func TestDailyReminderDefaults(t *testing.T) {
+	job := NewDailyReminderJob(nil, nil, nil, 24, 0, zap.NewNop())
+	if job.hour != DefaultDailyReminderHour || job.loc != time.UTC {
+		t.Errorf("ожидался час %d в UTC, получено %d в %v", DefaultDailyReminderHour, job.hour, job.loc)
+	}
+	if want := time.Second / DefaultDailyReminderRate; job.interval != want {
+		t.Errorf("ожидалась пауза %v между сообщениями, получено %v", want, job.interval)
+	}
+}
+
+func TestDailyReminderMessage(t *testing.T) {
+	job := NewDailyReminderJob(nil, nil, nil, 10, 20, zap.NewNop())
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	msg := job.reminderMessage(&models.User{TelegramID: 100, StudyStreak: 12}, now)
+	if msg.ChatID != 100 || !strings.Contains(msg.Text, "<b>12</b>") {
+		t.Errorf("ожидалась текущая серия в напоминании, получено %q", msg.Text)
+	}
+	if !strings.Contains(job.reminderMessage(&models.User{TelegramID: 100}, now).Text, "новую серию") {
+		t.Error("без серии ожидалось предложение начать новую")
+	}
+}
+
+func TestWaitContextStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitContext(ctx, time.Hour); err == nil {
+		t.Error("ожидалась ошибка отмененного контекста")
+	}
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/premium"
+)
+
+// DailyResetJob сбрасывает дневные лимиты сообщений всем пользователям в полночь
+// пояса сброса, не дожидаясь, пока пользователь сам напишет боту
+type DailyResetJob struct {
+	premiumService *premium.Service
+	logger         *zap.Logger
+	lastReset      int64
+}
+
+// NewDailyResetJob создает джобу сброса дневных лимитов
+func NewDailyResetJob(premiumService *premium.Service, logger *zap.Logger) *DailyResetJob {
+	return &DailyResetJob{
+		premiumService: premiumService,
+		logger:         logger,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *DailyResetJob) Name() string {
+	return "daily_reset"
+}
+
+// LastRowsProcessed возвращает число пользователей, сброшенных последним запуском
+func (j *DailyResetJob) LastRowsProcessed() int64 {
+	return j.lastReset
+}
+
+// Run сбрасывает счетчики всем, у кого в поясе сброса уже наступил новый день.
+// Повторный запуск в тот же день ничего не меняет.
+func (j *DailyResetJob) Run(ctx context.Context) error {
+	reset, err := j.premiumService.ResetAllDailyCounters(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка сброса дневных лимитов: %w", err)
+	}
+	j.lastReset = reset
+
+	j.logger.Info("дневные лимиты сообщений сброшены",
+		zap.Int64("users", reset),
+		zap.String("location", j.premiumService.ResetLocation().String()))
+	return nil
+}
+
+// NextRunAt возвращает ближайшую полночь в поясе сброса.
+// Общий планировщик срабатывает раз в несколько часов, поэтому у джобы свое расписание.
+func (j *DailyResetJob) NextRunAt(now time.Time) time.Time {
+	return premium.NextResetAt(now, j.premiumService.ResetLocation())
+}
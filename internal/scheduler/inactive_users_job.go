@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"lingua-ai/internal/ai"
+	"lingua-ai/internal/challenge"
 	"lingua-ai/internal/message"
 	"lingua-ai/internal/user"
 	"lingua-ai/pkg/models"
@@ -21,8 +22,10 @@ type InactiveUsersJob struct {
 	userService    *user.Service
 	messageService *message.Service
 	aiClient       ai.AIClient
-	bot            *tgbotapi.BotAPI
+	bot            Sender
 	logger         *zap.Logger
+	lastSent       int64
+	phraseButton   bool // добавлять ли к напоминанию кнопку «Фраза дня»
 }
 
 // NewInactiveUsersJob создает новую джобу для неактивных пользователей
@@ -30,7 +33,7 @@ func NewInactiveUsersJob(
 	userService *user.Service,
 	messageService *message.Service,
 	aiClient ai.AIClient,
-	bot *tgbotapi.BotAPI,
+	bot Sender,
 	logger *zap.Logger,
 ) *InactiveUsersJob {
 	return &InactiveUsersJob{
@@ -42,6 +45,21 @@ func NewInactiveUsersJob(
 	}
 }
 
+// SetPhraseOfDayButton включает кнопку челленджа «Фраза дня» в напоминаниях
+func (j *InactiveUsersJob) SetPhraseOfDayButton(enabled bool) {
+	j.phraseButton = enabled
+}
+
+// Name возвращает имя джобы
+func (j *InactiveUsersJob) Name() string {
+	return "inactive_users"
+}
+
+// LastRowsProcessed возвращает число заданий, отправленных последним запуском
+func (j *InactiveUsersJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
 // Run запускает джобу проверки неактивных пользователей
 func (j *InactiveUsersJob) Run(ctx context.Context) error {
 	j.logger.Info("запуск джобы проверки неактивных пользователей")
@@ -56,6 +74,7 @@ func (j *InactiveUsersJob) Run(ctx context.Context) error {
 	j.logger.Info("найдено неактивных пользователей", zap.Int("count", len(inactiveUsers)))
 
 	// Отправляем задания каждому неактивному пользователю
+	j.lastSent = 0
 	for _, user := range inactiveUsers {
 		if err := j.sendTaskToUser(ctx, user); err != nil {
 			j.logger.Error("ошибка отправки задания пользователю",
@@ -64,9 +83,10 @@ func (j *InactiveUsersJob) Run(ctx context.Context) error {
 				zap.String("username", user.Username))
 			continue
 		}
+		j.lastSent++
 	}
 
-	j.logger.Info("джоба проверки неактивных пользователей завершена")
+	j.logger.Info("джоба проверки неактивных пользователей завершена", zap.Int64("sent", j.lastSent))
 	return nil
 }
 
@@ -106,6 +126,13 @@ func (j *InactiveUsersJob) sendTaskToUser(ctx context.Context, user *models.User
 	// Отправляем сообщение с безопасной обработкой HTML
 	msg := tgbotapi.NewMessage(user.TelegramID, messageText)
 	msg.ParseMode = "HTML"
+	if j.phraseButton {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗣 Фраза дня", challenge.CallbackData),
+			),
+		)
+	}
 
 	_, err = j.bot.Send(msg)
 	if err != nil {
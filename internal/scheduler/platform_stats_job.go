@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/user"
+)
+
+// PlatformStatsJob пересчитывает средние показатели учеников для /compare,
+// чтобы команда не гоняла тяжелый агрегирующий запрос на каждый вызов
+type PlatformStatsJob struct {
+	userService *user.Service
+	logger      *zap.Logger
+	lastUsers   int64
+}
+
+// NewPlatformStatsJob создает джобу пересчета средних показателей
+func NewPlatformStatsJob(userService *user.Service, logger *zap.Logger) *PlatformStatsJob {
+	return &PlatformStatsJob{
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *PlatformStatsJob) Name() string {
+	return "platform_stats"
+}
+
+// LastRowsProcessed возвращает число учеников, попавших в последний пересчет
+func (j *PlatformStatsJob) LastRowsProcessed() int64 {
+	return j.lastUsers
+}
+
+// Run пересчитывает средние показатели
+func (j *PlatformStatsJob) Run(ctx context.Context) error {
+	stats, err := j.userService.RefreshPlatformStats(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка пересчета средних показателей: %w", err)
+	}
+	j.lastUsers = int64(stats.Users)
+	return nil
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+)
+
+// PremiumExpiredLookback за какой срок после окончания премиума еще сообщать о нем.
+// Давно истекшие подписки без уведомления (например, до включения джобы) пропускаются.
+const PremiumExpiredLookback = 7 * 24 * time.Hour
+
+// PremiumExpiryJob раз в час напоминает о продлении премиума за несколько дней до окончания
+// и сообщает об окончании подписки вместе с бесплатными лимитами
+type PremiumExpiryJob struct {
+	userService    *user.Service
+	premiumService *premium.Service
+	bot            Sender
+	logger         *zap.Logger
+	window         time.Duration // за сколько до окончания напоминать; 0 — не напоминать
+	now            func() time.Time
+	lastSent       int64
+}
+
+// NewPremiumExpiryJob создает джобу напоминаний об окончании премиума.
+// reminderDays — за сколько дней до окончания напоминать о продлении (0 — только уведомление об окончании).
+func NewPremiumExpiryJob(userService *user.Service, premiumService *premium.Service, bot Sender, reminderDays int, logger *zap.Logger) *PremiumExpiryJob {
+	return &PremiumExpiryJob{
+		userService:    userService,
+		premiumService: premiumService,
+		bot:            bot,
+		logger:         logger,
+		window:         time.Duration(max(reminderDays, 0)) * 24 * time.Hour,
+		now:            time.Now,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *PremiumExpiryJob) Name() string {
+	return "premium_expiry"
+}
+
+// LastRowsProcessed возвращает число сообщений, отправленных последним запуском
+func (j *PremiumExpiryJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
+// NextRunAt возвращает начало следующего часа: уведомление об окончании приходит не позже чем через час
+func (j *PremiumExpiryJob) NextRunAt(now time.Time) time.Time {
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
+
+// Run отправляет напоминания о продлении и уведомления об окончании премиума.
+// Каждое сообщение отправляется один раз на срок подписки: после продления возможны новые.
+func (j *PremiumExpiryJob) Run(ctx context.Context) error {
+	now := j.now()
+	j.lastSent = 0
+
+	if j.window > 0 {
+		if err := j.sendReminders(ctx, now); err != nil {
+			return err
+		}
+	}
+	if err := j.sendExpiredNotices(ctx, now); err != nil {
+		return err
+	}
+
+	j.logger.Info("напоминания об окончании премиума отправлены", zap.Int64("sent", j.lastSent))
+	return nil
+}
+
+// sendReminders напоминает о продлении тем, у кого премиум закончится в пределах окна
+func (j *PremiumExpiryJob) sendReminders(ctx context.Context, now time.Time) error {
+	users, err := j.userService.GetPremiumExpiringUsers(ctx, now, now.Add(j.window))
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей с заканчивающимся премиумом: %w", err)
+	}
+
+	for _, u := range users {
+		// Отмечаем до отправки, чтобы параллельный запуск не отправил напоминание дважды
+		marked, err := j.userService.MarkPremiumExpiryReminded(ctx, u.ID, *u.PremiumExpiresAt)
+		if err != nil {
+			j.logger.Error("ошибка отметки напоминания о продлении премиума", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if _, err := j.bot.Send(j.reminderMessage(u, now)); err != nil {
+			j.logger.Error("ошибка отправки напоминания о продлении премиума", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		j.lastSent++
+	}
+	return nil
+}
+
+// sendExpiredNotices отключает закончившийся премиум и сообщает об этом пользователю
+func (j *PremiumExpiryJob) sendExpiredNotices(ctx context.Context, now time.Time) error {
+	users, err := j.userService.GetPremiumExpiredUsers(ctx, now.Add(-PremiumExpiredLookback), now)
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей с закончившимся премиумом: %w", err)
+	}
+
+	for _, u := range users {
+		marked, err := j.userService.MarkPremiumExpiredNotified(ctx, u.ID, *u.PremiumExpiresAt)
+		if err != nil {
+			j.logger.Error("ошибка отметки уведомления об окончании премиума", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		// Проверка статуса отключает истекший премиум и возвращает бесплатный лимит
+		downgraded, err := j.premiumService.CheckPremiumStatus(ctx, u.ID)
+		if err != nil {
+			j.logger.Error("ошибка отключения истекшего премиума", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+
+		if _, err := j.bot.Send(j.expiredMessage(downgraded)); err != nil {
+			j.logger.Error("ошибка отправки уведомления об окончании премиума", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		j.lastSent++
+	}
+	return nil
+}
+
+// reminderMessage формирует напоминание о продлении с планами премиума
+func (j *PremiumExpiryJob) reminderMessage(u *models.User, now time.Time) tgbotapi.MessageConfig {
+	expiresAt := u.PremiumExpiresAt.In(j.premiumService.ResetLocation())
+	text := fmt.Sprintf(`⏳ <b>Премиум скоро закончится</b>
+
+Подписка действует до %s — осталось %s.
+Продли её, чтобы сохранить безлимитные сообщения и все премиум-возможности 👇`,
+		expiresAt.Format("02.01.2006 15:04"), premiumTimeLeft(u.PremiumExpiresAt.Sub(now)))
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = premiumPlansKeyboard(j.premiumService.GetPremiumPlans())
+	return msg
+}
+
+// expiredMessage формирует уведомление об окончании премиума с бесплатными лимитами
+func (j *PremiumExpiryJob) expiredMessage(u *models.User) tgbotapi.MessageConfig {
+	remaining := max(u.MaxMessages-u.MessagesCount, 0)
+	text := fmt.Sprintf(`💤 <b>Премиум закончился</b>
+
+Аккаунт переведен на бесплатный тариф: %d сообщений в день, сегодня осталось %d.
+Карточки, прогресс и серия занятий сохранены. Вернуть премиум можно в любой момент 👇`,
+		u.MaxMessages, remaining)
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = premiumPlansKeyboard(j.premiumService.GetPremiumPlans())
+	return msg
+}
+
+// premiumPlansKeyboard кнопки планов премиума, как в /premium
+func premiumPlansKeyboard(plans []models.PremiumPlan) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, plan := range plans {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("💶 %s - %.0f %s", plan.Name, plan.Price, plan.Currency),
+				fmt.Sprintf("premium_plan_%d", plan.ID),
+			),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// premiumTimeLeft оставшееся время подписки: в днях, а в последние сутки — в часах и минутах
+func premiumTimeLeft(left time.Duration) string {
+	if left < 24*time.Hour {
+		return formatTimeLeft(left)
+	}
+	return fmt.Sprintf("%d дн.", int((left+12*time.Hour)/(24*time.Hour)))
+}
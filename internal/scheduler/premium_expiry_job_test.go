@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/pkg/models"
+)
+
+func TestPremiumExpiryNextRunAt(t *testing.T) {
+	job := NewPremiumExpiryJob(nil, nil, nil, 3, zap.NewNop())
+
+	now := time.Date(2026, 10, 16, 9, 42, 0, 0, time.UTC)
+	if got, want := job.NextRunAt(now), time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ожидалось %v, получено %v", want, got)
+	}
+}
+
+func TestPremiumExpiryMessages(t *testing.T) {
+	premiumService := premium.NewService(nil, nil, nil, zap.NewNop())
+	job := NewPremiumExpiryJob(nil, premiumService, nil, 3, zap.NewNop())
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(47 * time.Hour)
+	u := &models.User{TelegramID: 100, IsPremium: true, PremiumExpiresAt: &expiresAt}
+
+	reminder := job.reminderMessage(u, now)
+	if !strings.Contains(reminder.Text, "18.10.2026 08:00") || !strings.Contains(reminder.Text, "2 дн.") {
+		t.Errorf("ожидались срок и остаток подписки, получено %q", reminder.Text)
+	}
+
+	expired := job.expiredMessage(&models.User{TelegramID: 100, MaxMessages: premium.DefaultFreeMessageLimit, MessagesCount: 9})
+	if !strings.Contains(expired.Text, "7 сообщений") || !strings.Contains(expired.Text, "осталось 0") {
+		t.Errorf("ожидались бесплатные лимиты, получено %q", expired.Text)
+	}
+}
+
+func TestPremiumPlansKeyboardReusesPlanCallbacks(t *testing.T) {
+	plans := premium.NewService(nil, nil, nil, zap.NewNop()).GetPremiumPlans()
+	keyboard := premiumPlansKeyboard(plans)
+
+	if len(keyboard.InlineKeyboard) != len(plans) {
+		t.Fatalf("ожидалось %d кнопок планов, получено %d", len(plans), len(keyboard.InlineKeyboard))
+	}
+	if data := *keyboard.InlineKeyboard[0][0].CallbackData; data != "premium_plan_1" {
+		t.Errorf("ожидалась кнопка плана premium_plan_1, получено %q", data)
+	}
+}
+
+func TestPremiumTimeLeft(t *testing.T) {
+	tests := map[time.Duration]string{
+		3 * time.Hour:                  "3 ч",
+		47 * time.Hour:                 "2 дн.",
+		3*24*time.Hour - 5*time.Minute: "3 дн.",
+	}
+	for left, want := range tests {
+		if got := premiumTimeLeft(left); got != want {
+			t.Errorf("для %v ожидалось %q, получено %q", left, want, got)
+		}
+	}
+}
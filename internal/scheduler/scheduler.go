@@ -2,15 +2,29 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// Ошибки ручного запуска задач
+var (
+	ErrJobNotFound = errors.New("задача не найдена")
+	ErrJobRunning  = errors.New("задача уже выполняется")
+)
+
 // Scheduler управляет запуском периодических задач
 type Scheduler struct {
-	logger *zap.Logger
-	jobs   []Job
+	logger   *zap.Logger
+	recorder JobRecorder
+
+	mu       sync.RWMutex
+	jobs     []*jobEntry          // задачи, запускаемые по общему тикеру
+	registry map[string]*jobEntry // все известные планировщику задачи по имени
 }
 
 // Job интерфейс для периодических задач
@@ -18,24 +32,117 @@ type Job interface {
 	Run(ctx context.Context) error
 }
 
+// NamedJob задача с собственным именем для метрик и админки.
+// Для задач без имени используется имя типа.
+type NamedJob interface {
+	Name() string
+}
+
+// RowsReporter задача, сообщающая, сколько записей обработал последний запуск
+type RowsReporter interface {
+	LastRowsProcessed() int64
+}
+
+// TimedJob задача со своим расписанием вместо общего тикера
+type TimedJob interface {
+	Job
+	// NextRunAt возвращает время следующего запуска после now
+	NextRunAt(now time.Time) time.Time
+}
+
+// JobRecorder записывает метрики выполнения задач
+type JobRecorder interface {
+	RecordJobRun(job string, duration time.Duration, rows int64, err error)
+}
+
+// JobStats сводка о здоровье задачи
+type JobStats struct {
+	Name           string    `json:"name"`
+	Runs           int64     `json:"runs"`
+	Errors         int64     `json:"errors"`
+	LastRun        time.Time `json:"last_run"`
+	LastDurationMs int64     `json:"last_duration_ms"`
+	LastRows       int64     `json:"last_rows"`
+	LastError      string    `json:"last_error,omitempty"`
+	LastSuccess    time.Time `json:"last_success"`
+}
+
+// manualRunKey ключ контекста ручного запуска
+type manualRunKey struct{}
+
+// IsManualRun сообщает, запущена ли задача вручную через RunJob.
+// Задачи с собственным интервалом могут по нему не пропускать запуск.
+func IsManualRun(ctx context.Context) bool {
+	manual, _ := ctx.Value(manualRunKey{}).(bool)
+	return manual
+}
+
+// jobEntry задача и ее статистика
+type jobEntry struct {
+	name string
+	job  Job
+
+	// runMu не дает одной задаче выполняться параллельно (по расписанию и вручную)
+	runMu sync.Mutex
+
+	mu    sync.Mutex
+	stats JobStats
+}
+
 // NewScheduler создает новый планировщик задач
 func NewScheduler(logger *zap.Logger) *Scheduler {
 	return &Scheduler{
-		logger: logger,
-		jobs:   make([]Job, 0),
+		logger:   logger,
+		jobs:     make([]*jobEntry, 0),
+		registry: make(map[string]*jobEntry),
 	}
 }
 
+// SetRecorder задает получателя метрик выполнения задач
+func (s *Scheduler) SetRecorder(recorder JobRecorder) {
+	s.recorder = recorder
+}
+
 // AddJob добавляет задачу в планировщик
 func (s *Scheduler) AddJob(job Job) {
-	s.jobs = append(s.jobs, job)
+	entry := s.register(job)
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, entry)
+	s.mu.Unlock()
+}
+
+// register добавляет задачу в реестр без запуска по общему тикеру
+func (s *Scheduler) register(job Job) *jobEntry {
+	name := jobName(job)
+	entry := &jobEntry{name: name, job: job, stats: JobStats{Name: name}}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.registry[name]; exists {
+		s.logger.Warn("задача с таким именем уже зарегистрирована, заменяем", zap.String("job", name))
+	}
+	s.registry[name] = entry
+	return entry
+}
+
+// jobName возвращает имя задачи
+func jobName(job Job) string {
+	if named, ok := job.(NamedJob); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", job)
 }
 
 // Start запускает планировщик с указанным интервалом
 func (s *Scheduler) Start(ctx context.Context, interval time.Duration) {
+	s.mu.RLock()
+	jobsCount := len(s.jobs)
+	s.mu.RUnlock()
+
 	s.logger.Info("запуск планировщика задач",
 		zap.Duration("interval", interval),
-		zap.Int("jobs_count", len(s.jobs)))
+		zap.Int("jobs_count", jobsCount))
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -54,15 +161,117 @@ func (s *Scheduler) Start(ctx context.Context, interval time.Duration) {
 	}
 }
 
-// runJobs запускает все зарегистрированные задачи
-func (s *Scheduler) runJobs(ctx context.Context) {
-	for i, job := range s.jobs {
-		s.logger.Debug("запуск задачи", zap.Int("job_index", i))
+// StartTimed регистрирует задачу и запускает ее сразу, а затем по ее собственному расписанию
+func (s *Scheduler) StartTimed(ctx context.Context, job TimedJob) {
+	entry := s.register(job)
+
+	for {
+		s.runEntry(ctx, entry)
 
-		if err := job.Run(ctx); err != nil {
-			s.logger.Error("ошибка выполнения задачи",
-				zap.Error(err),
-				zap.Int("job_index", i))
+		now := time.Now()
+		timer := time.NewTimer(job.NextRunAt(now).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
 		}
 	}
 }
+
+// runJobs запускает все задачи общего тикера
+func (s *Scheduler) runJobs(ctx context.Context) {
+	s.mu.RLock()
+	jobs := append([]*jobEntry(nil), s.jobs...)
+	s.mu.RUnlock()
+
+	for _, entry := range jobs {
+		s.runEntry(ctx, entry)
+	}
+}
+
+// RunJob немедленно выполняет зарегистрированную задачу по имени и возвращает
+// ее сводку после запуска. Если задача уже выполняется, возвращает ErrJobRunning.
+func (s *Scheduler) RunJob(ctx context.Context, name string) (JobStats, error) {
+	s.mu.RLock()
+	entry, ok := s.registry[name]
+	s.mu.RUnlock()
+	if !ok {
+		return JobStats{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	s.logger.Info("ручной запуск задачи", zap.String("job", name))
+	err := s.runEntry(context.WithValue(ctx, manualRunKey{}, true), entry)
+
+	entry.mu.Lock()
+	stats := entry.stats
+	entry.mu.Unlock()
+	return stats, err
+}
+
+// runEntry выполняет задачу и записывает ее статистику и метрики.
+// Если задача уже выполняется, запуск пропускается с ErrJobRunning.
+func (s *Scheduler) runEntry(ctx context.Context, entry *jobEntry) error {
+	if !entry.runMu.TryLock() {
+		s.logger.Warn("задача уже выполняется, запуск пропущен", zap.String("job", entry.name))
+		return ErrJobRunning
+	}
+	defer entry.runMu.Unlock()
+
+	s.logger.Debug("запуск задачи", zap.String("job", entry.name))
+
+	started := time.Now()
+	err := entry.job.Run(ctx)
+	duration := time.Since(started)
+
+	var rows int64
+	if reporter, ok := entry.job.(RowsReporter); ok {
+		rows = reporter.LastRowsProcessed()
+	}
+
+	entry.mu.Lock()
+	entry.stats.Runs++
+	entry.stats.LastRun = started
+	entry.stats.LastDurationMs = duration.Milliseconds()
+	entry.stats.LastRows = rows
+	if err != nil {
+		entry.stats.Errors++
+		entry.stats.LastError = err.Error()
+	} else {
+		entry.stats.LastError = ""
+		entry.stats.LastSuccess = started
+	}
+	entry.mu.Unlock()
+
+	if s.recorder != nil {
+		s.recorder.RecordJobRun(entry.name, duration, rows, err)
+	}
+
+	if err != nil {
+		s.logger.Error("ошибка выполнения задачи",
+			zap.Error(err),
+			zap.String("job", entry.name),
+			zap.Duration("duration", duration))
+	}
+	return err
+}
+
+// Stats возвращает сводку по всем зарегистрированным задачам, отсортированную по имени
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.RLock()
+	entries := make([]*jobEntry, 0, len(s.registry))
+	for _, entry := range s.registry {
+		entries = append(entries, entry)
+	}
+	s.mu.RUnlock()
+
+	stats := make([]JobStats, 0, len(entries))
+	for _, entry := range entries {
+		entry.mu.Lock()
+		stats = append(stats, entry.stats)
+		entry.mu.Unlock()
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testJob задача с управляемым результатом
+type testJob struct {
+	name string
+	rows int64
+	err  error
+	runs int
+}
+
+func (j *testJob) Name() string                  { return j.name }
+func (j *testJob) LastRowsProcessed() int64      { return j.rows }
+func (j *testJob) Run(ctx context.Context) error { j.runs++; return j.err }
+
+// testRecorder запоминает записанные метрики
+type testRecorder struct {
+	runs map[string]int
+	errs map[string]int
+}
+
+func (r *testRecorder) RecordJobRun(job string, duration time.Duration, rows int64, err error) {
+	r.runs[job]++
+	if err != nil {
+		r.errs[job]++
+	}
+}
+
+func TestSchedulerRecordsJobStats(t *testing.T) {
+	s := NewScheduler(zap.NewNop())
+	recorder := &testRecorder{runs: map[string]int{}, errs: map[string]int{}}
+	s.SetRecorder(recorder)
+
+	ok := &testJob{name: "ok", rows: 7}
+	failing := &testJob{name: "failing", err: errors.New("boom")}
+	s.AddJob(ok)
+	s.AddJob(failing)
+
+	s.runJobs(context.Background())
+	failing.err = nil
+	s.runJobs(context.Background())
+
+	stats := s.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("ожидалось 2 задачи, получено %d", len(stats))
+	}
+	// Сводка отсортирована по имени
+	if stats[0].Name != "failing" || stats[1].Name != "ok" {
+		t.Fatalf("неожиданный порядок задач: %s, %s", stats[0].Name, stats[1].Name)
+	}
+
+	if stats[0].Runs != 2 || stats[0].Errors != 1 {
+		t.Errorf("ожидалось 2 запуска и 1 ошибка, получено %d и %d", stats[0].Runs, stats[0].Errors)
+	}
+	if stats[0].LastError != "" || stats[0].LastSuccess.IsZero() {
+		t.Error("после успешного запуска ошибка должна сбрасываться")
+	}
+	if stats[1].LastRows != 7 {
+		t.Errorf("ожидалось 7 обработанных записей, получено %d", stats[1].LastRows)
+	}
+	if recorder.runs["failing"] != 2 || recorder.errs["failing"] != 1 {
+		t.Errorf("метрики записаны неверно: %v, %v", recorder.runs, recorder.errs)
+	}
+}
+
+func TestJobNameFallsBackToType(t *testing.T) {
+	type anonymousJob struct{ Job }
+	if got := jobName(anonymousJob{}); got != "scheduler.anonymousJob" {
+		t.Errorf("ожидалось имя типа, получено %s", got)
+	}
+}
+
+// blockingJob задача, которая ждет сигнала для завершения
+type blockingJob struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (j *blockingJob) Name() string { return "blocking" }
+func (j *blockingJob) Run(ctx context.Context) error {
+	close(j.started)
+	<-j.release
+	return nil
+}
+
+func TestRunJobPreventsOverlap(t *testing.T) {
+	s := NewScheduler(zap.NewNop())
+	job := &blockingJob{started: make(chan struct{}), release: make(chan struct{})}
+	s.AddJob(job)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.runJobs(context.Background())
+	}()
+	<-job.started
+
+	if _, err := s.RunJob(context.Background(), "blocking"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("ожидалась ErrJobRunning, получено %v", err)
+	}
+
+	close(job.release)
+	wg.Wait()
+
+	if stats := s.Stats(); stats[0].Runs != 1 {
+		t.Errorf("ожидался 1 запуск, получено %d", stats[0].Runs)
+	}
+}
+
+func TestRunJobByName(t *testing.T) {
+	s := NewScheduler(zap.NewNop())
+	job := &testJob{name: "digest", rows: 3}
+	s.AddJob(job)
+
+	stats, err := s.RunJob(context.Background(), "digest")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if job.runs != 1 || stats.Runs != 1 || stats.LastRows != 3 {
+		t.Errorf("неожиданная сводка: %+v", stats)
+	}
+
+	if IsManualRun(context.Background()) {
+		t.Error("обычный контекст не должен считаться ручным запуском")
+	}
+	if _, err := s.RunJob(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("ожидалась ErrJobNotFound, получено %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package scheduler
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender отправляет сообщения в Telegram. В работе это диспетчер бота, который
+// соблюдает лимиты чатов и повторяет отправку после ответа 429.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
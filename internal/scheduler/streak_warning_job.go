@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+)
+
+// DefaultStreakWarningHours за сколько часов до полуночи предупреждать о серии под угрозой
+const DefaultStreakWarningHours = 3
+
+// StreakWarningJob вечером по поясу пользователя предупреждает тех, кто сегодня еще
+// не занимался, что их серия прервется в полночь
+type StreakWarningJob struct {
+	userService *user.Service
+	bot         Sender
+	botUsername string // для ссылки на быстрое упражнение
+	logger      *zap.Logger
+	loc         *time.Location // пояс пользователей, не выбравших свой
+	hours       int            // окно перед полуночью, в котором отправляются предупреждения
+	now         func() time.Time
+	lastSent    int64
+}
+
+// NewStreakWarningJob создает джобу предупреждений о серии. Полночь считается в поясе
+// пользователя, а если он не выбран — в поясе loc, том же, в котором сбрасываются дневные лимиты.
+func NewStreakWarningJob(userService *user.Service, bot Sender, botUsername string, loc *time.Location, hours int, logger *zap.Logger) *StreakWarningJob {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if hours <= 0 || hours >= 24 {
+		hours = DefaultStreakWarningHours
+	}
+	return &StreakWarningJob{
+		userService: userService,
+		bot:         bot,
+		botUsername: botUsername,
+		logger:      logger,
+		loc:         loc,
+		hours:       hours,
+		now:         time.Now,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *StreakWarningJob) Name() string {
+	return "streak_warning"
+}
+
+// LastRowsProcessed возвращает число предупреждений, отправленных последним запуском
+func (j *StreakWarningJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
+// NextRunAt возвращает ближайшую проверку: вечернее окно у пользователей из разных
+// поясов начинается в разное время, поэтому джоба запускается каждые localTimeJobStep
+func (j *StreakWarningJob) NextRunAt(now time.Time) time.Time {
+	return now.Truncate(localTimeJobStep).Add(localTimeJobStep)
+}
+
+// Run отправляет предупреждения пользователям, у которых в их поясе идет вечернее окно
+// перед полуночью. Ручной запуск через админку не ждет окна.
+func (j *StreakWarningJob) Run(ctx context.Context) error {
+	now := j.now()
+	manual := IsManualRun(ctx)
+
+	users, err := j.userService.GetStreakAtRiskUsers(ctx, now)
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей с серией под угрозой: %w", err)
+	}
+
+	j.lastSent = 0
+	for _, u := range users {
+		start, midnight := streakWarningWindow(now, u.Location(j.loc), j.hours)
+		if now.Before(start) && !manual {
+			continue
+		}
+
+		// Отмечаем до отправки, чтобы параллельный запуск не отправил предупреждение дважды
+		dayStart := midnight.AddDate(0, 0, -1)
+		marked, err := j.userService.MarkStreakWarningSent(ctx, u.ID, dayStart)
+		if err != nil {
+			j.logger.Error("ошибка отметки предупреждения о серии", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if _, err := j.bot.Send(j.warningMessage(u, midnight.Sub(now))); err != nil {
+			j.logger.Error("ошибка отправки предупреждения о серии", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		j.lastSent++
+	}
+
+	if j.lastSent > 0 || manual {
+		j.logger.Info("предупреждения о серии отправлены",
+			zap.Int64("sent", j.lastSent),
+			zap.Int("at_risk", len(users)))
+	}
+	return nil
+}
+
+// warningMessage формирует предупреждение со ссылкой на быстрое упражнение
+func (j *StreakWarningJob) warningMessage(u *models.User, left time.Duration) tgbotapi.MessageConfig {
+	text := fmt.Sprintf(`🔥 <b>Твоя серия из %d %s под угрозой!</b>
+
+Сегодня занятий еще не было, а до полуночи осталось %s.
+Хватит одного короткого упражнения, чтобы сохранить серию 💪`,
+		u.StudyStreak, daysWord(u.StudyStreak), formatTimeLeft(left))
+
+	quickLink := fmt.Sprintf("https://t.me/%s?start=%s", j.botUsername, models.QuickStudyStartParam)
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("⚡ Быстрое упражнение", quickLink),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("😴 Не напоминать %d дней", models.StreakWarningSnoozeDays),
+				models.StreakWarningSnoozeCallback),
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Отключить", models.StreakWarningOffCallback),
+		),
+	)
+	return msg
+}
+
+// streakWarningWindow возвращает начало вечернего окна и ближайшую полночь в поясе loc
+func streakWarningWindow(now time.Time, loc *time.Location, hours int) (start, midnight time.Time) {
+	y, m, d := now.In(loc).Date()
+	midnight = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	return midnight.Add(-time.Duration(hours) * time.Hour), midnight
+}
+
+// formatTimeLeft форматирует оставшееся время: "2 ч 15 мин" или "40 мин"
+func formatTimeLeft(left time.Duration) string {
+	minutes := int(left.Round(time.Minute).Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%d мин", max(minutes, 1))
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d ч", minutes/60)
+	}
+	return fmt.Sprintf("%d ч %d мин", minutes/60, minutes%60)
+}
+
+// daysWord склоняет слово «день» после «из»: из 1 дня, из 5 дней, из 21 дня
+func daysWord(n int) string {
+	if n%10 == 1 && n%100 != 11 {
+		return "дня"
+	}
+	return "дней"
+}
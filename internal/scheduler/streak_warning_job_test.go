@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestStreakWarningNextRunAt(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	job := NewStreakWarningJob(nil, nil, "", loc, 3, zap.NewNop())
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 3, 10, 12, 0, 0, 0, loc), time.Date(2026, 3, 10, 12, 15, 0, 0, loc)},
+		{time.Date(2026, 3, 10, 20, 50, 0, 0, loc), time.Date(2026, 3, 10, 21, 0, 0, 0, loc)},
+		{time.Date(2026, 3, 10, 23, 59, 0, 0, loc), time.Date(2026, 3, 11, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := job.NextRunAt(tt.now); !got.Equal(tt.want) {
+			t.Errorf("для %v ожидалось %v, получено %v", tt.now, tt.want, got)
+		}
+	}
+}
+
+func TestStreakWarningWindowInUserTimezone(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 3, 10, 18, 30, 0, 0, time.UTC) // 21:30 по Москве, 19:30 в Берлине
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("ошибка загрузки пояса: %v", err)
+	}
+
+	start, midnight := streakWarningWindow(now, (&models.User{}).Location(msk), 3)
+	if now.Before(start) || !midnight.Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, msk)) {
+		t.Errorf("в Москве окно уже началось: получено %v–%v", start, midnight)
+	}
+	start, _ = streakWarningWindow(now, (&models.User{Timezone: "Europe/Berlin"}).Location(msk), 3)
+	if !start.Equal(time.Date(2026, 3, 10, 21, 0, 0, 0, berlin)) || !now.Before(start) {
+		t.Errorf("в Берлине окно начнется в 21:00, получено %v", start)
+	}
+}
+
+func TestStreakWarningHoursFallback(t *testing.T) {
+	for _, hours := range []int{0, -1, 24} {
+		job := NewStreakWarningJob(nil, nil, "", nil, hours, zap.NewNop())
+		if job.hours != DefaultStreakWarningHours || job.loc != time.UTC {
+			t.Errorf("для %d ч ожидалось окно %d ч в UTC, получено %d ч в %v", hours, DefaultStreakWarningHours, job.hours, job.loc)
+		}
+	}
+}
+
+func TestDaysWord(t *testing.T) {
+	tests := map[int]string{1: "дня", 2: "дней", 5: "дней", 11: "дней", 21: "дня", 101: "дня", 111: "дней"}
+	for n, want := range tests {
+		if got := daysWord(n); got != want {
+			t.Errorf("для %d ожидалось %q, получено %q", n, want, got)
+		}
+	}
+}
+
+func TestFormatTimeLeft(t *testing.T) {
+	tests := map[time.Duration]string{
+		40 * time.Minute:             "40 мин",
+		20 * time.Second:             "1 мин",
+		2 * time.Hour:                "2 ч",
+		2*time.Hour + 15*time.Minute: "2 ч 15 мин",
+		2*time.Hour + 59*time.Minute + 50*time.Second: "3 ч",
+	}
+	for left, want := range tests {
+		if got := formatTimeLeft(left); got != want {
+			t.Errorf("для %v ожидалось %q, получено %q", left, want, got)
+		}
+	}
+}
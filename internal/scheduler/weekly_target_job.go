@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+)
+
+// WeeklyTargetReminderHours за сколько часов до конца недели напоминать о невыполненной цели
+const WeeklyTargetReminderHours = 12
+
+// WeeklyTargetReminderJob в последний день недели напоминает пользователям,
+// что их недельная цель по выученным словам еще не выполнена
+type WeeklyTargetReminderJob struct {
+	userService      *user.Service
+	flashcardService *flashcards.Service
+	bot              Sender
+	logger           *zap.Logger
+	loc              *time.Location
+	now              func() time.Time
+	lastSent         int64
+}
+
+// NewWeeklyTargetReminderJob создает джобу напоминаний о недельной цели.
+// Неделя считается в поясе loc — том же, в котором ее считает сервис карточек.
+func NewWeeklyTargetReminderJob(userService *user.Service, flashcardService *flashcards.Service, bot Sender, loc *time.Location, logger *zap.Logger) *WeeklyTargetReminderJob {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &WeeklyTargetReminderJob{
+		userService:      userService,
+		flashcardService: flashcardService,
+		bot:              bot,
+		logger:           logger,
+		loc:              loc,
+		now:              time.Now,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *WeeklyTargetReminderJob) Name() string {
+	return "weekly_target"
+}
+
+// LastRowsProcessed возвращает число напоминаний, отправленных последним запуском
+func (j *WeeklyTargetReminderJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
+// NextRunAt возвращает начало ближайшего окна напоминаний перед концом недели
+func (j *WeeklyTargetReminderJob) NextRunAt(now time.Time) time.Time {
+	start, _, _ := weeklyTargetWindow(now, j.loc)
+	if now.Before(start) {
+		return start
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// Run отправляет напоминания, если сейчас окно перед концом недели.
+// Ручной запуск через админку работает в любое время.
+func (j *WeeklyTargetReminderJob) Run(ctx context.Context) error {
+	now := j.now()
+	start, weekStart, weekEnd := weeklyTargetWindow(now, j.loc)
+	if now.Before(start) && !IsManualRun(ctx) {
+		j.lastSent = 0
+		return nil
+	}
+
+	users, err := j.userService.GetWeeklyTargetReminderUsers(ctx, weekStart)
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей с недельной целью: %w", err)
+	}
+
+	j.lastSent = 0
+	for _, u := range users {
+		progress, err := j.flashcardService.WeeklyProgress(ctx, u.ID)
+		if err != nil {
+			j.logger.Error("ошибка получения прогресса недельной цели", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !progress.HasTarget() || progress.Completed() {
+			continue
+		}
+
+		// Отмечаем до отправки, чтобы параллельный запуск не отправил напоминание дважды
+		marked, err := j.userService.MarkWeeklyTargetReminded(ctx, u.ID, weekStart)
+		if err != nil {
+			j.logger.Error("ошибка отметки напоминания о недельной цели", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if _, err := j.bot.Send(j.reminderMessage(u, progress, weekEnd.Sub(now))); err != nil {
+			j.logger.Error("ошибка отправки напоминания о недельной цели", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		j.lastSent++
+	}
+
+	j.logger.Info("напоминания о недельной цели отправлены",
+		zap.Int64("sent", j.lastSent),
+		zap.Int("with_target", len(users)))
+	return nil
+}
+
+// reminderMessage формирует напоминание с кнопкой перехода к карточкам
+func (j *WeeklyTargetReminderJob) reminderMessage(u *models.User, progress *models.WeeklyTargetProgress, left time.Duration) tgbotapi.MessageConfig {
+	text := fmt.Sprintf(`🏁 <b>Неделя почти закончилась!</b>
+
+Выучено слов: %d из %d — осталось %d.
+До конца недели %s: успей добрать слова и получить +%d XP 💪`,
+		progress.Learned, progress.Target, progress.Remaining(), formatTimeLeft(left), models.WeeklyTargetBonusXP)
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 К карточкам", "flashcard_start"),
+		),
+	)
+	return msg
+}
+
+// weeklyTargetWindow возвращает начало окна напоминаний, начало и конец текущей недели в поясе loc
+func weeklyTargetWindow(now time.Time, loc *time.Location) (start, weekStart, weekEnd time.Time) {
+	weekStart = flashcards.WeekStart(now.In(loc))
+	weekEnd = weekStart.AddDate(0, 0, 7)
+	return weekEnd.Add(-WeeklyTargetReminderHours * time.Hour), weekStart, weekEnd
+}
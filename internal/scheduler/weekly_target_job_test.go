@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWeeklyTargetNextRunAt(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	job := NewWeeklyTargetReminderJob(nil, nil, nil, loc, zap.NewNop())
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		// Среда — напоминание в воскресенье в полдень
+		{time.Date(2026, 10, 14, 9, 0, 0, 0, loc), time.Date(2026, 10, 18, 12, 0, 0, 0, loc)},
+		// Воскресенье в окне — следующее напоминание через неделю
+		{time.Date(2026, 10, 18, 15, 0, 0, 0, loc), time.Date(2026, 10, 25, 12, 0, 0, 0, loc)},
+		// Понедельник по Москве, хотя в UTC еще воскресенье
+		{time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 12, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := job.NextRunAt(tt.now); !got.Equal(tt.want) {
+			t.Errorf("для %v ожидалось %v, получено %v", tt.now, tt.want, got)
+		}
+	}
+}
+
+func TestWeeklyTargetSkipsBeforeWindow(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	job := NewWeeklyTargetReminderJob(nil, nil, nil, loc, zap.NewNop())
+	job.now = func() time.Time { return time.Date(2026, 10, 17, 20, 0, 0, 0, loc) }
+
+	// До окна пользователи не запрашиваются: сервисы не заданы и не должны использоваться
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if job.LastRowsProcessed() != 0 {
+		t.Errorf("ожидалось 0 отправленных напоминаний, получено %d", job.LastRowsProcessed())
+	}
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/internal/flashcards"
+	"lingua-ai/internal/user"
+	"lingua-ai/pkg/models"
+)
+
+// DefaultWordOfDayHour час отправки слова дня в поясе пользователя
+const DefaultWordOfDayHour = 9
+
+// WordOfDayJob раз в день в фиксированный час по поясу пользователя присылает активным
+// пользователям слово их уровня с переводом, примером и кнопкой озвучки
+type WordOfDayJob struct {
+	userService      *user.Service
+	flashcardService *flashcards.Service
+	bot              Sender
+	logger           *zap.Logger
+	loc              *time.Location // пояс пользователей, не выбравших свой
+	hour             int            // час отправки в поясе пользователя
+	interval         time.Duration  // пауза между сообщениями, чтобы не упираться в flood control
+	now              func() time.Time
+	wait             func(ctx context.Context, d time.Duration) error
+	lastSent         int64
+}
+
+// NewWordOfDayJob создает джобу слова дня. Сутки считаются в поясе пользователя, а если
+// он не выбран — в поясе loc, том же, в котором сбрасываются дневные лимиты;
+// rate — сколько слов можно отправить в секунду.
+func NewWordOfDayJob(userService *user.Service, flashcardService *flashcards.Service, bot Sender, loc *time.Location, hour, rate int, logger *zap.Logger) *WordOfDayJob {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if hour < 0 || hour > 23 {
+		hour = DefaultWordOfDayHour
+	}
+	if rate <= 0 {
+		rate = DefaultDailyReminderRate
+	}
+	return &WordOfDayJob{
+		userService:      userService,
+		flashcardService: flashcardService,
+		bot:              bot,
+		logger:           logger,
+		loc:              loc,
+		hour:             hour,
+		interval:         time.Second / time.Duration(rate),
+		now:              time.Now,
+		wait:             waitContext,
+	}
+}
+
+// Name возвращает имя джобы
+func (j *WordOfDayJob) Name() string {
+	return "word_of_day"
+}
+
+// LastRowsProcessed возвращает число слов, отправленных последним запуском
+func (j *WordOfDayJob) LastRowsProcessed() int64 {
+	return j.lastSent
+}
+
+// NextRunAt возвращает ближайшую проверку: час отправки у пользователей из разных
+// поясов наступает в разное время, поэтому джоба запускается каждые localTimeJobStep
+func (j *WordOfDayJob) NextRunAt(now time.Time) time.Time {
+	return now.Truncate(localTimeJobStep).Add(localTimeJobStep)
+}
+
+// Run отправляет слово дня пользователям, у которых в их поясе уже наступил час отправки.
+// Ручной запуск через админку не ждет часа отправки.
+func (j *WordOfDayJob) Run(ctx context.Context) error {
+	now := j.now()
+	manual := IsManualRun(ctx)
+
+	// У пользователя, которому пора отправить слово, сутки начались не позже now-hour:
+	// последнее слово отправлено раньше этой границы. Точно сутки считаются по его поясу ниже.
+	sentBefore := now.Add(-time.Duration(j.hour) * time.Hour)
+	if manual {
+		sentBefore = now
+	}
+	users, err := j.userService.GetWordOfDayUsers(ctx, sentBefore)
+	if err != nil {
+		return fmt.Errorf("ошибка получения пользователей для слова дня: %w", err)
+	}
+
+	j.lastSent = 0
+	for _, u := range users {
+		dayStart, due := dailyReminderDue(now, u, j.loc, j.hour)
+		if !due && !manual {
+			continue
+		}
+		if u.WordOfDaySentAt != nil && !u.WordOfDaySentAt.Before(dayStart) {
+			continue
+		}
+
+		card, err := j.flashcardService.PickWordOfDay(ctx, u.ID, u.Level)
+		if err != nil {
+			j.logger.Error("ошибка выбора слова дня", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+
+		// Отмечаем до отправки, чтобы параллельный запуск не отправил слово дважды.
+		// Без подходящего слова тоже отмечаем: иначе пользователь выбирался бы каждые localTimeJobStep
+		marked, err := j.userService.MarkWordOfDaySent(ctx, u.ID, dayStart)
+		if err != nil {
+			j.logger.Error("ошибка отметки слова дня", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if !marked || card == nil {
+			continue
+		}
+
+		if j.lastSent > 0 {
+			if err := j.wait(ctx, j.interval); err != nil {
+				return err
+			}
+		}
+		if _, err := j.bot.Send(wordOfDayMessage(u, card)); err != nil {
+			j.logger.Error("ошибка отправки слова дня", zap.Error(err), zap.Int64("user_id", u.ID))
+			continue
+		}
+		if err := j.flashcardService.RecordWordOfDay(ctx, u.ID, card.ID); err != nil {
+			j.logger.Warn("не удалось запомнить слово дня", zap.Error(err), zap.Int64("user_id", u.ID))
+		}
+		j.lastSent++
+	}
+
+	if j.lastSent > 0 || manual {
+		j.logger.Info("слова дня отправлены",
+			zap.Int64("sent", j.lastSent),
+			zap.Int("candidates", len(users)))
+	}
+	return nil
+}
+
+// wordOfDayMessage формирует сообщение со словом, переводом, примером и кнопками
+func wordOfDayMessage(u *models.User, card *models.Flashcard) tgbotapi.MessageConfig {
+	text := fmt.Sprintf("📖 <b>Слово дня</b>\n\n<b>%s</b> — %s",
+		html.EscapeString(card.Word), html.EscapeString(card.Translation))
+	if card.Example != "" {
+		text += "\n\n💬 <i>" + html.EscapeString(card.Example) + "</i>"
+	}
+
+	msg := tgbotapi.NewMessage(u.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔊 Озвучить", models.WordOfDayTTSCallbackPrefix+strconv.FormatInt(card.ID, 10)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Не присылать", models.DailyReminderOffCallback),
+		),
+	)
+	return msg
+}
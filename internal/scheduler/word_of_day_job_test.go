@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+func TestWordOfDayNextRunAt(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	job := NewWordOfDayJob(nil, nil, nil, loc, 9, 20, zap.NewNop())
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 10, 16, 8, 0, 0, 0, loc), time.Date(2026, 10, 16, 8, 15, 0, 0, loc)},
+		{time.Date(2026, 10, 16, 8, 44, 59, 0, loc), time.Date(2026, 10, 16, 8, 45, 0, 0, loc)},
+		{time.Date(2026, 10, 16, 23, 50, 0, 0, loc), time.Date(2026, 10, 17, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := job.NextRunAt(tt.now); !got.Equal(tt.want) {
+			t.Errorf("для %v ожидалось %v, получено %v", tt.now, tt.want, got)
+		}
+	}
+}
+
+func TestWordOfDayMessage(t *testing.T) {
+	card := &models.Flashcard{ID: 42, Word: "rain & snow", Translation: "дождь и снег", Example: "Rain is coming."}
+	msg := wordOfDayMessage(&models.User{TelegramID: 100}, card)
+
+	if msg.ChatID != 100 || !strings.Contains(msg.Text, "rain &amp; snow") || !strings.Contains(msg.Text, "Rain is coming.") {
+		t.Errorf("ожидались слово, перевод и пример, получено %q", msg.Text)
+	}
+
+	keyboard := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if got := *keyboard.InlineKeyboard[0][0].CallbackData; got != models.WordOfDayTTSCallbackPrefix+"42" {
+		t.Errorf("кнопка озвучки должна ссылаться на карточку, получено %q", got)
+	}
+	if got := *keyboard.InlineKeyboard[1][0].CallbackData; got != models.DailyReminderOffCallback {
+		t.Errorf("вторая кнопка должна отключать напоминания, получено %q", got)
+	}
+}
@@ -0,0 +1,361 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+// userCacheSweepThreshold размер кэша, после которого удаляются устаревшие записи
+const userCacheSweepThreshold = 10000
+
+// userCacheEntry запись кэша пользователей
+type userCacheEntry struct {
+	user      *models.User
+	expiresAt time.Time
+}
+
+// UserCacheInvalidator сбрасывает кэшированного пользователя после изменений
+// в обход UserRepository, например триггером по таблице referrals
+type UserCacheInvalidator interface {
+	InvalidateUser(userID int64)
+}
+
+// cachedUserRepository оборачивает UserRepository коротким TTL-кэшем.
+// Чтения по ID и Telegram ID обслуживаются из кэша, любые записи
+// проходят в базу и инвалидируют запись пользователя.
+type cachedUserRepository struct {
+	UserRepository
+
+	ttl        time.Duration
+	mu         sync.RWMutex
+	byID       map[int64]userCacheEntry
+	telegramID map[int64]int64 // Telegram ID -> ID пользователя
+	resetLoc   *time.Location  // Пояс дней серии для пользователей, не выбравших свой
+	now        func() time.Time
+}
+
+// NewCachedUserRepository создает репозиторий пользователей с TTL-кэшем.
+// resetLoc должен совпадать с поясом, переданным в NewUserRepository.
+func NewCachedUserRepository(repo UserRepository, ttl time.Duration, resetLoc *time.Location) UserRepository {
+	if ttl <= 0 {
+		return repo
+	}
+
+	return &cachedUserRepository{
+		UserRepository: repo,
+		ttl:            ttl,
+		byID:           make(map[int64]userCacheEntry),
+		telegramID:     make(map[int64]int64),
+		resetLoc:       resetLoc,
+		now:            time.Now,
+	}
+}
+
+// GetByID получает пользователя по ID, используя кэш
+func (r *cachedUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	if user, ok := r.get(id); ok {
+		return user, nil
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.set(user)
+	return user, nil
+}
+
+// GetByTelegramID получает пользователя по Telegram ID, используя кэш
+func (r *cachedUserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.mu.RLock()
+	id, ok := r.telegramID[telegramID]
+	r.mu.RUnlock()
+	if ok {
+		if user, ok := r.get(id); ok {
+			return user, nil
+		}
+	}
+
+	user, err := r.UserRepository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	r.set(user)
+	return user, nil
+}
+
+// Update обновляет пользователя. Update пишет не все колонки, поэтому
+// запись инвалидируется, а не заменяется переданной структурой.
+func (r *cachedUserRepository) Update(ctx context.Context, user *models.User) error {
+	defer r.invalidate(user.ID)
+	return r.UserRepository.Update(ctx, user)
+}
+
+// UpdateState обновляет состояние пользователя
+func (r *cachedUserRepository) UpdateState(ctx context.Context, userID int64, state string) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.UpdateState(ctx, userID, state)
+}
+
+// AddXP добавляет опыт пользователю
+func (r *cachedUserRepository) AddXP(ctx context.Context, userID int64, xp int) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.AddXP(ctx, userID, xp)
+}
+
+// UpdateLastSeen обновляет время последней активности
+func (r *cachedUserRepository) UpdateLastSeen(ctx context.Context, userID int64) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.UpdateLastSeen(ctx, userID)
+}
+
+// UpdateStudyActivity обновляет активность обучения. Повторные занятия в тот же день
+// ничего не пишут в базу, поэтому такая запись кэша остается актуальной.
+func (r *cachedUserRepository) UpdateStudyActivity(ctx context.Context, userID int64) (int, error) {
+	cached, ok := r.get(userID)
+
+	streak, err := r.UserRepository.UpdateStudyActivity(ctx, userID)
+	if err != nil || !ok || !sameDay(cached.LastStudyDate, r.now(), cached.Location(r.resetLoc)) || cached.StudyStreak != streak {
+		r.invalidate(userID)
+	}
+	return streak, err
+}
+
+// IncrementMessagesCount увеличивает счетчик сообщений
+func (r *cachedUserRepository) IncrementMessagesCount(ctx context.Context, userID int64) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.IncrementMessagesCount(ctx, userID)
+}
+
+// ResetDailyMessageCounts обнуляет счетчики всех пользователей, поэтому кэш очищается целиком
+func (r *cachedUserRepository) ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error) {
+	defer r.invalidateAll()
+	return r.UserRepository.ResetDailyMessageCounts(ctx, today)
+}
+
+// AdjustExerciseDifficultyBias изменяет смещение сложности упражнений
+func (r *cachedUserRepository) AdjustExerciseDifficultyBias(ctx context.Context, userID int64, delta int) (int, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.AdjustExerciseDifficultyBias(ctx, userID, delta)
+}
+
+// MarkOnboardingCompleted отмечает первое прохождение тура
+func (r *cachedUserRepository) MarkOnboardingCompleted(ctx context.Context, userID int64) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkOnboardingCompleted(ctx, userID)
+}
+
+// SetInitialLevel сохраняет стартовый уровень пользователя
+func (r *cachedUserRepository) SetInitialLevel(ctx context.Context, userID int64, level, assessment string) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetInitialLevel(ctx, userID, level, assessment)
+}
+
+// SetLevelAssessment сохраняет способ определения уровня
+func (r *cachedUserRepository) SetLevelAssessment(ctx context.Context, userID int64, assessment string) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetLevelAssessment(ctx, userID, assessment)
+}
+
+// SetLearningLanguage сохраняет изучаемый язык пользователя
+func (r *cachedUserRepository) SetLearningLanguage(ctx context.Context, userID int64, language string) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetLearningLanguage(ctx, userID, language)
+}
+
+// SetNewCardsPerDay сохраняет темп изучения новых карточек
+func (r *cachedUserRepository) SetNewCardsPerDay(ctx context.Context, userID int64, perDay int) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetNewCardsPerDay(ctx, userID, perDay)
+}
+
+// SetStreakWarnings сохраняет настройки предупреждений о серии
+func (r *cachedUserRepository) SetStreakWarnings(ctx context.Context, userID int64, enabled bool, snoozedUntil *time.Time) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetStreakWarnings(ctx, userID, enabled, snoozedUntil)
+}
+
+// SetRemindersEnabled сохраняет настройку ежедневных напоминаний
+func (r *cachedUserRepository) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetRemindersEnabled(ctx, userID, enabled)
+}
+
+// SetFlashcardCategory сохраняет последнюю выбранную категорию карточек
+func (r *cachedUserRepository) SetFlashcardCategory(ctx context.Context, userID int64, category string) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetFlashcardCategory(ctx, userID, category)
+}
+
+// SetTimezone сохраняет часовой пояс пользователя
+func (r *cachedUserRepository) SetTimezone(ctx context.Context, userID int64, timezone string) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetTimezone(ctx, userID, timezone)
+}
+
+// SetWeeklyWordTarget сохраняет недельную цель по выученным словам
+func (r *cachedUserRepository) SetWeeklyWordTarget(ctx context.Context, userID int64, target int) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetWeeklyWordTarget(ctx, userID, target)
+}
+
+// ApplyPremiumGift сохраняет подарок и продлевает премиум получателя
+func (r *cachedUserRepository) ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error) {
+	defer r.invalidate(payment.UserID)
+	return r.UserRepository.ApplyPremiumGift(ctx, payment)
+}
+
+// GrantReferralReward начисляет премиум за рефералов
+func (r *cachedUserRepository) GrantReferralReward(ctx context.Context, userID int64, earned, maxRewards int) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.GrantReferralReward(ctx, userID, earned, maxRewards)
+}
+
+// SetReferredBy сохраняет, кто пригласил пользователя
+func (r *cachedUserRepository) SetReferredBy(ctx context.Context, userID, referrerID int64) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.SetReferredBy(ctx, userID, referrerID)
+}
+
+// IncrementReferralCount увеличивает счетчик приглашенных пользователей
+func (r *cachedUserRepository) IncrementReferralCount(ctx context.Context, userID int64) error {
+	defer r.invalidate(userID)
+	return r.UserRepository.IncrementReferralCount(ctx, userID)
+}
+
+// InvalidateUser удаляет пользователя из кэша
+func (r *cachedUserRepository) InvalidateUser(userID int64) {
+	r.invalidate(userID)
+}
+
+// MarkStreakWarningSent отмечает отправку предупреждения о серии
+func (r *cachedUserRepository) MarkStreakWarningSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkStreakWarningSent(ctx, userID, dayStart)
+}
+
+// MarkDailyReminderSent отмечает отправку ежедневного напоминания
+func (r *cachedUserRepository) MarkDailyReminderSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkDailyReminderSent(ctx, userID, dayStart)
+}
+
+// MarkWordOfDaySent отмечает отправку слова дня
+func (r *cachedUserRepository) MarkWordOfDaySent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkWordOfDaySent(ctx, userID, dayStart)
+}
+
+// MarkWeeklyTargetCompleted отмечает выполнение недельной цели
+func (r *cachedUserRepository) MarkWeeklyTargetCompleted(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkWeeklyTargetCompleted(ctx, userID, weekStart)
+}
+
+// MarkWeeklyTargetReminded отмечает напоминание о недельной цели
+func (r *cachedUserRepository) MarkWeeklyTargetReminded(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkWeeklyTargetReminded(ctx, userID, weekStart)
+}
+
+// MarkPremiumExpiryReminded отмечает напоминание об окончании премиума
+func (r *cachedUserRepository) MarkPremiumExpiryReminded(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkPremiumExpiryReminded(ctx, userID, expiresAt)
+}
+
+// MarkPremiumExpiredNotified отмечает уведомление об истекшем премиуме
+func (r *cachedUserRepository) MarkPremiumExpiredNotified(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.MarkPremiumExpiredNotified(ctx, userID, expiresAt)
+}
+
+// RecordExerciseAnswer сохраняет результат ответа на упражнение
+func (r *cachedUserRepository) RecordExerciseAnswer(ctx context.Context, userID int64, correct bool, window int) (int, int, error) {
+	defer r.invalidate(userID)
+	return r.UserRepository.RecordExerciseAnswer(ctx, userID, correct, window)
+}
+
+// get возвращает копию пользователя из кэша, если запись не устарела
+func (r *cachedUserRepository) get(id int64) (*models.User, bool) {
+	r.mu.RLock()
+	entry, ok := r.byID[id]
+	r.mu.RUnlock()
+
+	if !ok || r.now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return cloneUser(entry.user), true
+}
+
+// set сохраняет копию пользователя в кэш
+func (r *cachedUserRepository) set(user *models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if len(r.byID) >= userCacheSweepThreshold {
+		for id, entry := range r.byID {
+			if now.After(entry.expiresAt) {
+				delete(r.telegramID, entry.user.TelegramID)
+				delete(r.byID, id)
+			}
+		}
+	}
+
+	r.byID[user.ID] = userCacheEntry{user: cloneUser(user), expiresAt: now.Add(r.ttl)}
+	r.telegramID[user.TelegramID] = user.ID
+}
+
+// invalidate удаляет пользователя из кэша
+func (r *cachedUserRepository) invalidate(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.byID[id]; ok {
+		delete(r.telegramID, entry.user.TelegramID)
+		delete(r.byID, id)
+	}
+}
+
+// invalidateAll очищает кэш целиком
+func (r *cachedUserRepository) invalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID = make(map[int64]userCacheEntry)
+	r.telegramID = make(map[int64]int64)
+}
+
+// cloneUser создает копию пользователя, чтобы вызывающий код не изменял кэш
+func cloneUser(user *models.User) *models.User {
+	clone := *user
+	if user.PremiumExpiresAt != nil {
+		expiresAt := *user.PremiumExpiresAt
+		clone.PremiumExpiresAt = &expiresAt
+	}
+	if user.LastTestDate != nil {
+		lastTestDate := *user.LastTestDate
+		clone.LastTestDate = &lastTestDate
+	}
+	if user.ReferralCode != nil {
+		referralCode := *user.ReferralCode
+		clone.ReferralCode = &referralCode
+	}
+	if user.OnboardingCompletedAt != nil {
+		completedAt := *user.OnboardingCompletedAt
+		clone.OnboardingCompletedAt = &completedAt
+	}
+	if user.ReferredBy != nil {
+		referredBy := *user.ReferredBy
+		clone.ReferredBy = &referredBy
+	}
+	if user.LevelSelectedAt != nil {
+		selectedAt := *user.LevelSelectedAt
+		clone.LevelSelectedAt = &selectedAt
+	}
+	return &clone
+}
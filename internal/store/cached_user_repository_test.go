@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"lingua-ai/pkg/models"
+)
+
+// countingUserRepository считает обращения к базе для проверки кэша
+type countingUserRepository struct {
+	UserRepository
+
+	queries int
+	user    models.User
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	r.queries++
+	user := r.user
+	return &user, nil
+}
+
+func (r *countingUserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.queries++
+	user := r.user
+	return &user, nil
+}
+
+func (r *countingUserRepository) IncrementMessagesCount(ctx context.Context, userID int64) error {
+	r.queries++
+	r.user.MessagesCount++
+	return nil
+}
+
+func (r *countingUserRepository) UpdateLastSeen(ctx context.Context, userID int64) error {
+	r.queries++
+	return nil
+}
+
+// simulateMessage повторяет обращения к пользователю при обработке одного сообщения
+func simulateMessage(ctx context.Context, repo UserRepository, telegramID int64) {
+	user, _ := repo.GetByTelegramID(ctx, telegramID) // GetOrCreateUser
+	_ = repo.UpdateLastSeen(ctx, user.ID)
+	_, _ = repo.GetByID(ctx, user.ID) // проверка лимита сообщений
+	_, _ = repo.GetByID(ctx, user.ID) // проверка премиума
+	_ = repo.IncrementMessagesCount(ctx, user.ID)
+	_, _ = repo.GetByID(ctx, user.ID) // статистика
+}
+
+func TestCachedUserRepositoryInvalidation(t *testing.T) {
+	ctx := context.Background()
+	base := &countingUserRepository{user: models.User{ID: 1, TelegramID: 100}}
+	repo := NewCachedUserRepository(base, time.Minute, time.UTC)
+
+	if _, err := repo.GetByTelegramID(ctx, 100); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	cached, _ := repo.GetByID(ctx, 1)
+	if base.queries != 1 {
+		t.Errorf("ожидался 1 запрос к базе, получено %d", base.queries)
+	}
+
+	// Изменение копии не должно затрагивать кэш
+	cached.MessagesCount = 42
+	if again, _ := repo.GetByID(ctx, 1); again.MessagesCount != 0 {
+		t.Errorf("кэш изменен через возвращенную копию: %d", again.MessagesCount)
+	}
+
+	if err := repo.IncrementMessagesCount(ctx, 1); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	user, _ := repo.GetByID(ctx, 1)
+	if user.MessagesCount != 1 {
+		t.Errorf("ожидался счетчик 1 после инвалидации, получен %d", user.MessagesCount)
+	}
+}
+
+func TestCachedUserRepositoryExpiration(t *testing.T) {
+	ctx := context.Background()
+	base := &countingUserRepository{user: models.User{ID: 1, TelegramID: 100}}
+	repo := NewCachedUserRepository(base, time.Second, time.UTC).(*cachedUserRepository)
+
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	_, _ = repo.GetByID(ctx, 1)
+	now = now.Add(2 * time.Second)
+	_, _ = repo.GetByID(ctx, 1)
+
+	if base.queries != 2 {
+		t.Errorf("ожидалось 2 запроса после истечения TTL, получено %d", base.queries)
+	}
+}
+
+func TestNewCachedUserRepositoryDisabled(t *testing.T) {
+	base := &countingUserRepository{}
+	if repo := NewCachedUserRepository(base, 0, time.UTC); repo != UserRepository(base) {
+		t.Error("при нулевом TTL ожидался исходный репозиторий")
+	}
+}
+
+// userReadMethods методы UserRepository, которые не изменяют таблицу users.
+// Create добавляет новую строку, которой еще нет в кэше.
+var userReadMethods = map[string]bool{
+	"Create":                       true,
+	"GetByID":                      true,
+	"GetByTelegramID":              true,
+	"GetByUsername":                true,
+	"GetStats":                     true,
+	"GetTopUsersByStreak":          true,
+	"GetTopUsersByStreakOnly":      true,
+	"GetAll":                       true,
+	"GetInactiveUsers":             true,
+	"GetStreakAtRiskUsers":         true,
+	"GetDailyReminderUsers":        true,
+	"GetWordOfDayUsers":            true,
+	"GetWeeklyTargetReminderUsers": true,
+	"GetPremiumExpiringUsers":      true,
+	"GetPremiumExpiredUsers":       true,
+	"GetPlatformStats":             true,
+}
+
+// stubUserRepository репозиторий без реализации: вызов любого метода, кроме
+// UpdateStudyActivity, паникует на nil-интерфейсе
+type stubUserRepository struct {
+	UserRepository
+}
+
+func (stubUserRepository) UpdateStudyActivity(ctx context.Context, userID int64) (int, error) {
+	return 1, nil
+}
+
+// TestCachedUserRepositoryInvalidatesOnEveryWrite проверяет, что каждый метод
+// записи интерфейса UserRepository инвалидирует кэш, включая добавленные позже
+func TestCachedUserRepositoryInvalidatesOnEveryWrite(t *testing.T) {
+	const userID = 1
+	iface := reflect.TypeOf((*UserRepository)(nil)).Elem()
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		method := iface.Method(i)
+		if userReadMethods[method.Name] {
+			continue
+		}
+
+		t.Run(method.Name, func(t *testing.T) {
+			repo := NewCachedUserRepository(stubUserRepository{}, time.Minute, time.UTC).(*cachedUserRepository)
+			repo.set(&models.User{ID: userID, TelegramID: 100})
+
+			args := make([]reflect.Value, method.Type.NumIn())
+			for j := range args {
+				args[j] = userMethodArg(method.Type.In(j), userID)
+			}
+			func() {
+				// Обернутый метод паникует на заглушке, но отложенная инвалидация срабатывает
+				defer func() { _ = recover() }()
+				reflect.ValueOf(repo).MethodByName(method.Name).Call(args)
+			}()
+
+			if _, ok := repo.get(userID); ok {
+				t.Errorf("%s не инвалидирует кэш пользователя", method.Name)
+			}
+		})
+	}
+}
+
+// userMethodArg подбирает аргумент метода, указывающий на пользователя userID
+func userMethodArg(typ reflect.Type, userID int64) reflect.Value {
+	switch typ {
+	case reflect.TypeOf((*context.Context)(nil)).Elem():
+		return reflect.ValueOf(context.Background())
+	case reflect.TypeOf(int64(0)):
+		return reflect.ValueOf(userID)
+	case reflect.TypeOf(&models.User{}):
+		return reflect.ValueOf(&models.User{ID: userID})
+	case reflect.TypeOf(&models.Payment{}):
+		return reflect.ValueOf(&models.Payment{UserID: userID})
+	}
+	return reflect.Zero(typ)
+}
+
+func BenchmarkUserQueriesPerMessage(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("без кэша", func(b *testing.B) {
+		base := &countingUserRepository{user: models.User{ID: 1, TelegramID: 100}}
+		for i := 0; i < b.N; i++ {
+			simulateMessage(ctx, base, 100)
+		}
+		b.ReportMetric(float64(base.queries)/float64(b.N), "queries/msg")
+	})
+
+	b.Run("с кэшем", func(b *testing.B) {
+		base := &countingUserRepository{user: models.User{ID: 1, TelegramID: 100}}
+		repo := NewCachedUserRepository(base, time.Minute, time.UTC)
+		for i := 0; i < b.N; i++ {
+			simulateMessage(ctx, repo, 100)
+		}
+		b.ReportMetric(float64(base.queries)/float64(b.N), "queries/msg")
+	})
+}
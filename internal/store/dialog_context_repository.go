@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"lingua-ai/pkg/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DialogContextRepository определяет интерфейс для сохраненных контекстов диалога
+type DialogContextRepository interface {
+	Get(ctx context.Context, userID int64) (*models.SavedDialogContext, error)
+	Save(ctx context.Context, dialog *models.SavedDialogContext) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// PostgresDialogContextRepository реализует DialogContextRepository для PostgreSQL
+type PostgresDialogContextRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewDialogContextRepository создает новый репозиторий контекстов диалога
+func NewDialogContextRepository(db *pgxpool.Pool, logger *zap.Logger) DialogContextRepository {
+	return &PostgresDialogContextRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get получает сохраненный контекст диалога пользователя
+func (r *PostgresDialogContextRepository) Get(ctx context.Context, userID int64) (*models.SavedDialogContext, error) {
+	query := `
+		SELECT user_id, summary, messages, updated_at
+		FROM dialog_contexts
+		WHERE user_id = $1`
+
+	dialog := &models.SavedDialogContext{}
+	var messages []byte
+	err := r.db.QueryRow(ctx, query, userID).Scan(&dialog.UserID, &dialog.Summary, &messages, &dialog.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user_id %d", ErrDialogContextNotFound, userID)
+		}
+		return nil, fmt.Errorf("ошибка получения контекста диалога: %w", err)
+	}
+
+	if err := json.Unmarshal(messages, &dialog.Messages); err != nil {
+		return nil, fmt.Errorf("ошибка разбора сообщений контекста диалога: %w", err)
+	}
+
+	return dialog, nil
+}
+
+// Save сохраняет контекст диалога пользователя, заменяя предыдущий
+func (r *PostgresDialogContextRepository) Save(ctx context.Context, dialog *models.SavedDialogContext) error {
+	messages, err := json.Marshal(dialog.Messages)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации сообщений контекста диалога: %w", err)
+	}
+
+	query := `
+		INSERT INTO dialog_contexts (user_id, summary, messages, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET summary = EXCLUDED.summary,
+		    messages = EXCLUDED.messages,
+		    updated_at = EXCLUDED.updated_at`
+
+	if _, err := r.db.Exec(ctx, query, dialog.UserID, dialog.Summary, messages, dialog.UpdatedAt); err != nil {
+		return fmt.Errorf("ошибка сохранения контекста диалога: %w", err)
+	}
+
+	return nil
+}
+
+// Delete удаляет сохраненный контекст диалога пользователя
+func (r *PostgresDialogContextRepository) Delete(ctx context.Context, userID int64) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM dialog_contexts WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка удаления контекста диалога: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package store
+
+import "errors"
+
+// Ошибки хранилища. Репозитории оборачивают их с подробностями через %w,
+// вызывающий код проверяет их через errors.Is, а не по тексту ошибки.
+var (
+	ErrUserNotFound         = errors.New("пользователь не найден")
+	ErrUserAlreadyExists    = errors.New("пользователь уже существует")
+	ErrPaymentNotFound      = errors.New("платеж не найден")
+	ErrReferralNotFound     = errors.New("реферал не найден")
+	ErrWordPackNotFound     = errors.New("набор слов не найден")
+	ErrWordPackAlreadyAdded = errors.New("набор слов уже добавлен")
+
+	ErrFlashcardNotFound       = errors.New("карточка не найдена")
+	ErrFlashcardReportNotFound = errors.New("открытые жалобы на карточку не найдены")
+	ErrDialogContextNotFound   = errors.New("сохраненный контекст диалога не найден")
+	ErrLevelTestNotFound       = errors.New("незавершенный тест уровня не найден")
+)
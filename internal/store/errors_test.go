@@ -0,0 +1,24 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNotFoundErrorsSurviveWrapping(t *testing.T) {
+	sentinels := []error{ErrUserNotFound, ErrPaymentNotFound, ErrReferralNotFound, ErrWordPackNotFound, ErrDialogContextNotFound, ErrFlashcardNotFound}
+
+	for _, sentinel := range sentinels {
+		// Так ошибки проходят через репозиторий и сервисный слой
+		err := fmt.Errorf("ошибка получения: %w", fmt.Errorf("%w: ID %d", sentinel, 7))
+		if !errors.Is(err, sentinel) {
+			t.Errorf("ожидалось, что %q распознается через errors.Is", err)
+		}
+		for _, other := range sentinels {
+			if other != sentinel && errors.Is(err, other) {
+				t.Errorf("ошибка %q не должна совпадать с %q", err, other)
+			}
+		}
+	}
+}
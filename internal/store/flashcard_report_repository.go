@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"lingua-ai/pkg/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// FlashcardReportRepository определяет интерфейс для жалоб на карточки
+type FlashcardReportRepository interface {
+	AddReport(ctx context.Context, flashcardID, userID int64, reason string, suspendAt int) (bool, error)
+	ListOpenReports(ctx context.Context) ([]models.FlashcardReportSummary, error)
+	ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error)
+}
+
+// PostgresFlashcardReportRepository реализует FlashcardReportRepository для PostgreSQL
+type PostgresFlashcardReportRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewFlashcardReportRepository создает новый репозиторий жалоб на карточки
+func NewFlashcardReportRepository(db *pgxpool.Pool, logger *zap.Logger) FlashcardReportRepository {
+	return &PostgresFlashcardReportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddReport сохраняет жалобу пользователя на карточку. Когда открытых жалоб набирается
+// suspendAt (0 — без автоматического снятия), карточка снимается с выдачи.
+// Возвращает true, если карточка была снята с выдачи этой жалобой.
+func (r *PostgresFlashcardReportRepository) AddReport(ctx context.Context, flashcardID, userID int64, reason string, suspendAt int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Повторная жалоба того же пользователя до рассмотрения не учитывается
+	_, err = tx.Exec(ctx, `
+		INSERT INTO flashcard_reports (flashcard_id, user_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (flashcard_id, user_id) WHERE resolved_at IS NULL DO NOTHING`,
+		flashcardID, userID, reason)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сохранения жалобы на карточку: %w", err)
+	}
+
+	suspended := false
+	if suspendAt > 0 {
+		var open int
+		err = tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM flashcard_reports
+			WHERE flashcard_id = $1 AND resolved_at IS NULL`, flashcardID).Scan(&open)
+		if err != nil {
+			return false, fmt.Errorf("ошибка подсчета жалоб на карточку: %w", err)
+		}
+
+		if open >= suspendAt {
+			result, err := tx.Exec(ctx, `
+				UPDATE flashcards SET suspended = true
+				WHERE id = $1 AND suspended = false`, flashcardID)
+			if err != nil {
+				return false, fmt.Errorf("ошибка снятия карточки с выдачи: %w", err)
+			}
+			suspended = result.RowsAffected() > 0
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return suspended, nil
+}
+
+// ListOpenReports возвращает карточки с нерассмотренными жалобами, снятые с выдачи — первыми
+func (r *PostgresFlashcardReportRepository) ListOpenReports(ctx context.Context) ([]models.FlashcardReportSummary, error) {
+	query := `
+		SELECT f.id, f.word, f.translation, COALESCE(f.example, ''), f.suspended,
+		       fr.reason, COUNT(*), MAX(fr.created_at)
+		FROM flashcard_reports fr
+		JOIN flashcards f ON f.id = fr.flashcard_id
+		WHERE fr.resolved_at IS NULL
+		GROUP BY f.id, fr.reason
+		ORDER BY f.suspended DESC, f.id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения жалоб на карточки: %w", err)
+	}
+	defer rows.Close()
+
+	// Строки сгруппированы по карточке и причине, собираем сводку по карточке
+	var summaries []models.FlashcardReportSummary
+	for rows.Next() {
+		var row models.FlashcardReportSummary
+		var reason string
+		var count int
+		if err := rows.Scan(&row.FlashcardID, &row.Word, &row.Translation, &row.Example, &row.Suspended,
+			&reason, &count, &row.LastReportedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования жалобы на карточку: %w", err)
+		}
+
+		last := len(summaries) - 1
+		if last < 0 || summaries[last].FlashcardID != row.FlashcardID {
+			row.Reasons = make(map[string]int)
+			summaries = append(summaries, row)
+			last++
+		} else if row.LastReportedAt.After(summaries[last].LastReportedAt) {
+			summaries[last].LastReportedAt = row.LastReportedAt
+		}
+		summaries[last].Reasons[reason] = count
+		summaries[last].Reports += count
+	}
+
+	return summaries, rows.Err()
+}
+
+// ResolveReports закрывает открытые жалобы на карточку решением администратора:
+// restore возвращает карточку в выдачу, suspend оставляет ее снятой.
+// Возвращает количество закрытых жалоб.
+func (r *PostgresFlashcardReportRepository) ResolveReports(ctx context.Context, flashcardID int64, resolution string) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE flashcard_reports SET resolved_at = NOW(), resolution = $2
+		WHERE flashcard_id = $1 AND resolved_at IS NULL`, flashcardID, resolution)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка закрытия жалоб на карточку: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("%w: карточка %d", ErrFlashcardReportNotFound, flashcardID)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE flashcards SET suspended = $2 WHERE id = $1`,
+		flashcardID, resolution == models.ReportResolutionSuspend)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления статуса карточки: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -2,34 +2,79 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"lingua-ai/pkg/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// NewCardOrder порядок, в котором пользователю выдаются новые карточки
+type NewCardOrder int
+
+const (
+	// NewCardOrderRandom случайные карточки уровня
+	NewCardOrderRandom NewCardOrder = iota
+	// NewCardOrderSpaced сначала частые слова, затем редкие; категории внутри частотной группы
+	// чередуются, а порядок внутри категории случайный
+	NewCardOrderSpaced
+)
+
+// frequencyBucketSize сколько соседних по частоте слов считаются одной группой
+const frequencyBucketSize = 50
+
+// IdiomCardCategory категория идиом, которые пользователи сохранили себе из /idiom.
+// Такие карточки не выдаются другим пользователям как новые слова.
+const IdiomCardCategory = "idioms"
+
 // FlashcardRepository интерфейс для работы со словарными карточками
 type FlashcardRepository interface {
 	// Flashcards
 	GetFlashcardByID(ctx context.Context, id int64) (*models.Flashcard, error)
+	GetFlashcardByWord(ctx context.Context, word string) (*models.Flashcard, error)
 	GetFlashcardsByLevel(ctx context.Context, level string, limit int) ([]*models.Flashcard, error)
 	GetFlashcardsByCategory(ctx context.Context, category string, limit int) ([]*models.Flashcard, error)
 	GetRandomFlashcards(ctx context.Context, level string, limit int) ([]*models.Flashcard, error)
+	CreateFlashcard(ctx context.Context, flashcard *models.Flashcard) (bool, error)
+	BulkCreate(ctx context.Context, flashcards []*models.Flashcard) (int, error)
+	AddUserCard(ctx context.Context, userID int64, flashcard *models.Flashcard) (bool, error)
+
+	// Альтернативные примеры
+	AddFlashcardExample(ctx context.Context, flashcardID int64, example string) error
+	GetFlashcardExamples(ctx context.Context, flashcardIDs []int64) (map[int64][]string, error)
 
 	// User Flashcards
 	GetUserFlashcard(ctx context.Context, userID, flashcardID int64) (*models.UserFlashcard, error)
+	GetUserFlashcardByWord(ctx context.Context, userID int64, word string) (*models.UserFlashcard, error)
+	SearchUserFlashcards(ctx context.Context, userID int64, query string, limit int) ([]*models.UserFlashcard, error)
 	CreateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error
 	UpdateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error
+	SavePronunciationScore(ctx context.Context, userID, flashcardID int64, score int) error
 	GetUserFlashcardsForReview(ctx context.Context, userID int64, limit int) ([]*models.UserFlashcard, error)
 	GetUserFlashcardStats(ctx context.Context, userID int64) (map[string]interface{}, error)
 	GetLearnedWordsCount(ctx context.Context, userID int64) (int, error)
+	GetLearnedTimes(ctx context.Context, userID int64, since time.Time) ([]time.Time, error)
 
 	// Spaced Repetition
 	GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error)
-	GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int) ([]*models.Flashcard, error)
+	GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int, order NewCardOrder) ([]*models.Flashcard, error)
+	GetNewCardsForUserByCategory(ctx context.Context, userID int64, level, category string, limit int) ([]*models.Flashcard, error)
+	GetUnlearnedFlashcards(ctx context.Context, userID int64) ([]*models.Flashcard, error)
 	GetNextCardToReview(ctx context.Context, userID int64) (*models.UserFlashcard, error)
+	GetReviewForecast(ctx context.Context, userID int64, tomorrow time.Time) (*models.ReviewForecast, error)
+
+	// Темп новых карточек
+	CountNewCardsSince(ctx context.Context, userID int64, since time.Time) (int, error)
+	CountAvailableNewCards(ctx context.Context, userID int64, level string) (int, error)
+
+	// Слово дня
+	GetSentWordIDs(ctx context.Context, userID int64, since time.Time) ([]int64, error)
+	RecordSentWord(ctx context.Context, userID, flashcardID int64) error
 }
 
 // flashcardRepository реализация FlashcardRepository
@@ -59,6 +104,9 @@ func (r *flashcardRepository) GetFlashcardByID(ctx context.Context, id int64) (*
 		&flashcard.Example, &flashcard.Level, &flashcard.Category, &flashcard.CreatedAt,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: ID %d", ErrFlashcardNotFound, id)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения карточки: %w", err)
 	}
@@ -66,6 +114,208 @@ func (r *flashcardRepository) GetFlashcardByID(ctx context.Context, id int64) (*
 	return flashcard, nil
 }
 
+// GetFlashcardByWord ищет карточку по слову без учета регистра.
+// Возвращает nil, если слова нет в колоде.
+func (r *flashcardRepository) GetFlashcardByWord(ctx context.Context, word string) (*models.Flashcard, error) {
+	query := `
+		SELECT id, word, translation, example, level, category, created_at
+		FROM flashcards
+		WHERE LOWER(word) = LOWER($1)
+		ORDER BY id ASC
+		LIMIT 1`
+
+	flashcard := &models.Flashcard{}
+	err := r.db.QueryRow(ctx, query, word).Scan(
+		&flashcard.ID, &flashcard.Word, &flashcard.Translation,
+		&flashcard.Example, &flashcard.Level, &flashcard.Category, &flashcard.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка поиска карточки по слову: %w", err)
+	}
+
+	return flashcard, nil
+}
+
+// CreateFlashcard добавляет карточку в общий пул, если слова этого уровня там еще нет.
+// Возвращает true, если карточка добавлена.
+func (r *flashcardRepository) CreateFlashcard(ctx context.Context, flashcard *models.Flashcard) (bool, error) {
+	query := `
+		INSERT INTO flashcards (word, translation, example, level, category)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM flashcards WHERE LOWER(word) = LOWER($1) AND level = $4
+		)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(ctx, query,
+		flashcard.Word, flashcard.Translation, flashcard.Example, flashcard.Level, flashcard.Category,
+	).Scan(&flashcard.ID, &flashcard.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка создания карточки: %w", err)
+	}
+
+	return true, nil
+}
+
+// bulkCreateBatchSize сколько карточек вставляется одним запросом при импорте
+const bulkCreateBatchSize = 500
+
+// BulkCreate добавляет карточки в общий пул пачками, пропуская слова, которые уже есть
+// в пуле на любом уровне. Добавленным карточкам проставляются ID и дата создания.
+// Возвращает число добавленных карточек.
+func (r *flashcardRepository) BulkCreate(ctx context.Context, flashcards []*models.Flashcard) (int, error) {
+	query := `
+		INSERT INTO flashcards (word, translation, example, level, category)
+		SELECT c.word, c.translation, c.example, c.level, c.category
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[])
+		     AS c(word, translation, example, level, category)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM flashcards f WHERE LOWER(f.word) = LOWER(c.word)
+		)
+		ON CONFLICT DO NOTHING
+		RETURNING id, LOWER(word), created_at`
+
+	inserted := 0
+	for start := 0; start < len(flashcards); start += bulkCreateBatchSize {
+		batch := flashcards[start:min(start+bulkCreateBatchSize, len(flashcards))]
+
+		words := make([]string, len(batch))
+		translations := make([]string, len(batch))
+		examples := make([]string, len(batch))
+		levels := make([]string, len(batch))
+		categories := make([]string, len(batch))
+		byWord := make(map[string]*models.Flashcard, len(batch))
+		for i, card := range batch {
+			words[i], translations[i], examples[i] = card.Word, card.Translation, card.Example
+			levels[i], categories[i] = card.Level, card.Category
+			byWord[strings.ToLower(card.Word)] = card
+		}
+
+		rows, err := r.db.Query(ctx, query, words, translations, examples, levels, categories)
+		if err != nil {
+			return inserted, fmt.Errorf("ошибка импорта карточек: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var word string
+			var createdAt time.Time
+			if err := rows.Scan(&id, &word, &createdAt); err != nil {
+				rows.Close()
+				return inserted, fmt.Errorf("ошибка чтения импортированной карточки: %w", err)
+			}
+			if card, ok := byWord[word]; ok {
+				card.ID, card.CreatedAt = id, createdAt
+			}
+			inserted++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return inserted, fmt.Errorf("ошибка импорта карточек: %w", err)
+		}
+	}
+
+	return inserted, nil
+}
+
+// AddUserCard добавляет карточку в очередь повторения пользователя, создавая ее при необходимости.
+// Если слово этого уровня уже есть, используется существующая карточка.
+// Возвращает false, если пользователь уже учит это слово.
+func (r *flashcardRepository) AddUserCard(ctx context.Context, userID int64, flashcard *models.Flashcard) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO flashcards (word, translation, example, level, category)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM flashcards WHERE LOWER(word) = LOWER($1) AND level = $4
+		)`,
+		flashcard.Word, flashcard.Translation, flashcard.Example, flashcard.Level, flashcard.Category,
+	)
+	if err != nil {
+		return false, fmt.Errorf("ошибка создания карточки: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT id, created_at FROM flashcards
+		WHERE LOWER(word) = LOWER($1) AND level = $2
+		ORDER BY id
+		LIMIT 1`, flashcard.Word, flashcard.Level,
+	).Scan(&flashcard.ID, &flashcard.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка получения карточки: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO user_flashcards (user_id, flashcard_id, next_review_at, source)
+		VALUES ($1, $2, NOW(), 'custom')
+		ON CONFLICT (user_id, flashcard_id) DO NOTHING`, userID, flashcard.ID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка добавления карточки пользователю: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// AddFlashcardExample сохраняет альтернативный пример для карточки (повторы игнорируются)
+func (r *flashcardRepository) AddFlashcardExample(ctx context.Context, flashcardID int64, example string) error {
+	query := `
+		INSERT INTO flashcard_examples (flashcard_id, example)
+		VALUES ($1, $2)
+		ON CONFLICT (flashcard_id, example) DO NOTHING`
+
+	if _, err := r.db.Exec(ctx, query, flashcardID, example); err != nil {
+		return fmt.Errorf("ошибка сохранения примера карточки: %w", err)
+	}
+
+	return nil
+}
+
+// GetFlashcardExamples возвращает альтернативные примеры для набора карточек в порядке добавления
+func (r *flashcardRepository) GetFlashcardExamples(ctx context.Context, flashcardIDs []int64) (map[int64][]string, error) {
+	examples := make(map[int64][]string)
+	if len(flashcardIDs) == 0 {
+		return examples, nil
+	}
+
+	query := `
+		SELECT flashcard_id, example
+		FROM flashcard_examples
+		WHERE flashcard_id = ANY($1)
+		ORDER BY flashcard_id, id`
+
+	rows, err := r.db.Query(ctx, query, flashcardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения примеров карточек: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flashcardID int64
+		var example string
+		if err := rows.Scan(&flashcardID, &example); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования примера карточки: %w", err)
+		}
+		examples[flashcardID] = append(examples[flashcardID], example)
+	}
+
+	return examples, rows.Err()
+}
+
 // GetFlashcardsByLevel получает карточки по уровню
 func (r *flashcardRepository) GetFlashcardsByLevel(ctx context.Context, level string, limit int) ([]*models.Flashcard, error) {
 	query := `
@@ -130,12 +380,12 @@ func (r *flashcardRepository) GetFlashcardsByCategory(ctx context.Context, categ
 	return flashcards, nil
 }
 
-// GetRandomFlashcards получает случайные карточки
+// GetRandomFlashcards получает случайные карточки, кроме снятых с выдачи
 func (r *flashcardRepository) GetRandomFlashcards(ctx context.Context, level string, limit int) ([]*models.Flashcard, error) {
 	query := `
 		SELECT id, word, translation, example, level, category, created_at
 		FROM flashcards 
-		WHERE level = $1
+		WHERE level = $1 AND suspended = FALSE
 		ORDER BY RANDOM()
 		LIMIT $2`
 
@@ -166,7 +416,8 @@ func (r *flashcardRepository) GetRandomFlashcards(ctx context.Context, level str
 func (r *flashcardRepository) GetUserFlashcard(ctx context.Context, userID, flashcardID int64) (*models.UserFlashcard, error) {
 	query := `
 		SELECT uf.id, uf.user_id, uf.flashcard_id, uf.difficulty, uf.review_count, 
-		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.created_at,
+		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.easy_streak, uf.created_at,
+		       uf.best_pronunciation_score,
 		       f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
 		FROM user_flashcards uf
 		JOIN flashcards f ON uf.flashcard_id = f.id
@@ -179,11 +430,15 @@ func (r *flashcardRepository) GetUserFlashcard(ctx context.Context, userID, flas
 	err := r.db.QueryRow(ctx, query, userID, flashcardID).Scan(
 		&userFlashcard.ID, &userFlashcard.UserID, &userFlashcard.FlashcardID,
 		&userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
-		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.CreatedAt,
+		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
+		&userFlashcard.BestPronunciationScore,
 		&userFlashcard.Flashcard.ID, &userFlashcard.Flashcard.Word, &userFlashcard.Flashcard.Translation,
 		&userFlashcard.Flashcard.Example, &userFlashcard.Flashcard.Level, &userFlashcard.Flashcard.Category, &userFlashcard.Flashcard.CreatedAt,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: пользователь %d, карточка %d", ErrFlashcardNotFound, userID, flashcardID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения пользовательской карточки: %w", err)
 	}
@@ -191,12 +446,100 @@ func (r *flashcardRepository) GetUserFlashcard(ctx context.Context, userID, flas
 	return userFlashcard, nil
 }
 
-// CreateUserFlashcard создает новую запись прогресса пользователя
+// GetUserFlashcardByWord получает прогресс пользователя по слову (без учета регистра).
+// Возвращает nil, если пользователь еще не начинал учить это слово.
+func (r *flashcardRepository) GetUserFlashcardByWord(ctx context.Context, userID int64, word string) (*models.UserFlashcard, error) {
+	query := `
+		SELECT uf.id, uf.user_id, uf.flashcard_id, uf.difficulty, uf.review_count, 
+		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.easy_streak, uf.created_at,
+		       uf.best_pronunciation_score,
+		       f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
+		FROM user_flashcards uf
+		JOIN flashcards f ON uf.flashcard_id = f.id
+		WHERE uf.user_id = $1 AND LOWER(f.word) = LOWER($2)
+		ORDER BY uf.next_review_at ASC
+		LIMIT 1`
+
+	userFlashcard := &models.UserFlashcard{
+		Flashcard: &models.Flashcard{},
+	}
+
+	err := r.db.QueryRow(ctx, query, userID, word).Scan(
+		&userFlashcard.ID, &userFlashcard.UserID, &userFlashcard.FlashcardID,
+		&userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
+		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
+		&userFlashcard.BestPronunciationScore,
+		&userFlashcard.Flashcard.ID, &userFlashcard.Flashcard.Word, &userFlashcard.Flashcard.Translation,
+		&userFlashcard.Flashcard.Example, &userFlashcard.Flashcard.Level, &userFlashcard.Flashcard.Category, &userFlashcard.Flashcard.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения карточки по слову: %w", err)
+	}
+
+	return userFlashcard, nil
+}
+
+// SearchUserFlashcards ищет среди начатых пользователем карточек те, у которых слово
+// или перевод содержит query (без учета регистра). Результаты отсортированы по слову.
+func (r *flashcardRepository) SearchUserFlashcards(ctx context.Context, userID int64, query string, limit int) ([]*models.UserFlashcard, error) {
+	sqlQuery := `
+		SELECT uf.id, uf.user_id, uf.flashcard_id, uf.difficulty, uf.review_count, 
+		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.easy_streak, uf.created_at,
+		       uf.best_pronunciation_score,
+		       f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
+		FROM user_flashcards uf
+		JOIN flashcards f ON uf.flashcard_id = f.id
+		WHERE uf.user_id = $1 AND (f.word ILIKE $2 OR f.translation ILIKE $2)
+		ORDER BY LOWER(f.word), f.id
+		LIMIT $3`
+
+	rows, err := r.db.Query(ctx, sqlQuery, userID, "%"+escapeLikePattern(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска карточек пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*models.UserFlashcard
+	for rows.Next() {
+		userFlashcard := &models.UserFlashcard{
+			Flashcard: &models.Flashcard{},
+		}
+		err := rows.Scan(
+			&userFlashcard.ID, &userFlashcard.UserID, &userFlashcard.FlashcardID,
+			&userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
+			&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
+			&userFlashcard.BestPronunciationScore,
+			&userFlashcard.Flashcard.ID, &userFlashcard.Flashcard.Word, &userFlashcard.Flashcard.Translation,
+			&userFlashcard.Flashcard.Example, &userFlashcard.Flashcard.Level, &userFlashcard.Flashcard.Category, &userFlashcard.Flashcard.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования найденной карточки", zap.Error(err))
+			continue
+		}
+		cards = append(cards, userFlashcard)
+	}
+
+	return cards, nil
+}
+
+// escapeLikePattern экранирует спецсимволы LIKE, чтобы запрос искался буквально
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CreateUserFlashcard создает новую запись прогресса пользователя.
+// Если запись уже создана параллельной сессией (двойное нажатие «Начать изучение»),
+// новая не добавляется: в userFlashcard загружается существующий прогресс.
 func (r *flashcardRepository) CreateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error {
 	query := `
 		INSERT INTO user_flashcards (user_id, flashcard_id, difficulty, review_count, 
-		                           correct_count, next_review_at, is_learned)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		                           correct_count, next_review_at, is_learned, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'session')
+		ON CONFLICT (user_id, flashcard_id) DO NOTHING
 		RETURNING id, created_at`
 
 	err := r.db.QueryRow(ctx, query,
@@ -205,6 +548,9 @@ func (r *flashcardRepository) CreateUserFlashcard(ctx context.Context, userFlash
 		userFlashcard.IsLearned,
 	).Scan(&userFlashcard.ID, &userFlashcard.CreatedAt)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.loadExistingUserFlashcard(ctx, userFlashcard)
+	}
 	if err != nil {
 		return fmt.Errorf("ошибка создания пользовательской карточки: %w", err)
 	}
@@ -212,18 +558,43 @@ func (r *flashcardRepository) CreateUserFlashcard(ctx context.Context, userFlash
 	return nil
 }
 
+// loadExistingUserFlashcard загружает уже существующий прогресс пользователя по карточке
+func (r *flashcardRepository) loadExistingUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error {
+	query := `
+		SELECT id, difficulty, review_count, correct_count, last_reviewed_at, next_review_at,
+		       is_learned, easy_streak, created_at
+		FROM user_flashcards
+		WHERE user_id = $1 AND flashcard_id = $2`
+
+	err := r.db.QueryRow(ctx, query, userFlashcard.UserID, userFlashcard.FlashcardID).Scan(
+		&userFlashcard.ID, &userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
+		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned,
+		&userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка получения существующей пользовательской карточки: %w", err)
+	}
+
+	r.logger.Debug("пользовательская карточка уже существует",
+		zap.Int64("user_id", userFlashcard.UserID),
+		zap.Int64("flashcard_id", userFlashcard.FlashcardID))
+	return nil
+}
+
 // UpdateUserFlashcard обновляет прогресс пользователя по карточке
 func (r *flashcardRepository) UpdateUserFlashcard(ctx context.Context, userFlashcard *models.UserFlashcard) error {
 	query := `
 		UPDATE user_flashcards 
 		SET difficulty = $3, review_count = $4, correct_count = $5, 
-		    last_reviewed_at = $6, next_review_at = $7, is_learned = $8
+		    last_reviewed_at = $6, next_review_at = $7, is_learned = $8, easy_streak = $9,
+		    learned_at = COALESCE(learned_at, $10)
 		WHERE user_id = $1 AND flashcard_id = $2`
 
 	_, err := r.db.Exec(ctx, query,
 		userFlashcard.UserID, userFlashcard.FlashcardID, userFlashcard.Difficulty,
 		userFlashcard.ReviewCount, userFlashcard.CorrectCount, userFlashcard.LastReviewedAt,
-		userFlashcard.NextReviewAt, userFlashcard.IsLearned,
+		userFlashcard.NextReviewAt, userFlashcard.IsLearned, userFlashcard.EasyStreak,
+		userFlashcard.LearnedAt,
 	)
 
 	if err != nil {
@@ -233,15 +604,66 @@ func (r *flashcardRepository) UpdateUserFlashcard(ctx context.Context, userFlash
 	return nil
 }
 
+// SavePronunciationScore сохраняет оценку произношения слова, если она лучше прежней
+func (r *flashcardRepository) SavePronunciationScore(ctx context.Context, userID, flashcardID int64, score int) error {
+	query := `
+		UPDATE user_flashcards
+		SET best_pronunciation_score = GREATEST(COALESCE(best_pronunciation_score, 0), $3)
+		WHERE user_id = $1 AND flashcard_id = $2`
+
+	if _, err := r.db.Exec(ctx, query, userID, flashcardID, score); err != nil {
+		return fmt.Errorf("ошибка сохранения оценки произношения: %w", err)
+	}
+
+	return nil
+}
+
+// GetSentWordIDs возвращает ID карточек, отправленных пользователю словом дня начиная с since
+func (r *flashcardRepository) GetSentWordIDs(ctx context.Context, userID int64, since time.Time) ([]int64, error) {
+	query := `SELECT flashcard_id FROM sent_words WHERE user_id = $1 AND sent_at >= $2`
+
+	rows, err := r.db.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения отправленных слов дня: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования отправленного слова дня: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// RecordSentWord запоминает, что карточка отправлена пользователю словом дня
+func (r *flashcardRepository) RecordSentWord(ctx context.Context, userID, flashcardID int64) error {
+	query := `
+		INSERT INTO sent_words (user_id, flashcard_id, sent_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, flashcard_id) DO UPDATE SET sent_at = EXCLUDED.sent_at`
+
+	if _, err := r.db.Exec(ctx, query, userID, flashcardID); err != nil {
+		return fmt.Errorf("ошибка сохранения слова дня: %w", err)
+	}
+
+	return nil
+}
+
 // GetUserFlashcardsForReview получает карточки для повторения
 func (r *flashcardRepository) GetUserFlashcardsForReview(ctx context.Context, userID int64, limit int) ([]*models.UserFlashcard, error) {
 	query := `
 		SELECT uf.id, uf.user_id, uf.flashcard_id, uf.difficulty, uf.review_count, 
-		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.created_at,
+		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.easy_streak, uf.created_at,
 		       f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
 		FROM user_flashcards uf
 		JOIN flashcards f ON uf.flashcard_id = f.id
 		WHERE uf.user_id = $1 AND uf.next_review_at <= CURRENT_TIMESTAMP AND uf.is_learned = FALSE
+		  AND f.suspended = FALSE
 		ORDER BY uf.next_review_at ASC
 		LIMIT $2`
 
@@ -260,7 +682,7 @@ func (r *flashcardRepository) GetUserFlashcardsForReview(ctx context.Context, us
 		err := rows.Scan(
 			&userFlashcard.ID, &userFlashcard.UserID, &userFlashcard.FlashcardID,
 			&userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
-			&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.CreatedAt,
+			&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
 			&userFlashcard.Flashcard.ID, &userFlashcard.Flashcard.Word, &userFlashcard.Flashcard.Translation,
 			&userFlashcard.Flashcard.Example, &userFlashcard.Flashcard.Level, &userFlashcard.Flashcard.Category, &userFlashcard.Flashcard.CreatedAt,
 		)
@@ -306,6 +728,29 @@ func (r *flashcardRepository) GetUserFlashcardStats(ctx context.Context, userID
 	return stats, nil
 }
 
+// GetReviewForecast считает карточки к повторению по дням: tomorrow — начало завтрашнего
+// дня пользователя, от него отсчитываются завтра и остаток недели
+func (r *flashcardRepository) GetReviewForecast(ctx context.Context, userID int64, tomorrow time.Time) (*models.ReviewForecast, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE uf.next_review_at < $2),
+			COUNT(*) FILTER (WHERE uf.next_review_at >= $2 AND uf.next_review_at < $2 + INTERVAL '1 day'),
+			COUNT(*) FILTER (WHERE uf.next_review_at < $2 + make_interval(days => $3))
+		FROM user_flashcards uf
+		JOIN flashcards f ON uf.flashcard_id = f.id
+		WHERE uf.user_id = $1 AND uf.is_learned = FALSE AND f.suspended = FALSE`
+
+	forecast := &models.ReviewForecast{}
+	err := r.db.QueryRow(ctx, query, userID, tomorrow, models.ReviewForecastDays-1).Scan(
+		&forecast.Today, &forecast.Tomorrow, &forecast.Week,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прогноза повторений: %w", err)
+	}
+
+	return forecast, nil
+}
+
 // GetLearnedWordsCount получает количество выученных слов
 func (r *flashcardRepository) GetLearnedWordsCount(ctx context.Context, userID int64) (int, error) {
 	query := `SELECT COUNT(*) FROM user_flashcards WHERE user_id = $1 AND is_learned = TRUE`
@@ -319,17 +764,46 @@ func (r *flashcardRepository) GetLearnedWordsCount(ctx context.Context, userID i
 	return count, nil
 }
 
+// GetLearnedTimes возвращает моменты, когда пользователь выучил слова, начиная с since
+func (r *flashcardRepository) GetLearnedTimes(ctx context.Context, userID int64, since time.Time) ([]time.Time, error) {
+	query := `
+		SELECT learned_at FROM user_flashcards
+		WHERE user_id = $1 AND learned_at >= $2
+		ORDER BY learned_at`
+
+	rows, err := r.db.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения дат выученных слов: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var learnedAt time.Time
+		if err := rows.Scan(&learnedAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения даты выученного слова: %w", err)
+		}
+		times = append(times, learnedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения дат выученных слов: %w", err)
+	}
+
+	return times, nil
+}
+
 // GetCardsToReview получает карточки, которые нужно повторить
 func (r *flashcardRepository) GetCardsToReview(ctx context.Context, userID int64) ([]*models.UserFlashcard, error) {
 	return r.GetUserFlashcardsForReview(ctx, userID, 50) // Максимум 50 карточек за раз
 }
 
 // GetNewCardsForUser получает новые карточки для пользователя
-func (r *flashcardRepository) GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int) ([]*models.Flashcard, error) {
+func (r *flashcardRepository) GetNewCardsForUser(ctx context.Context, userID int64, level string, limit int, order NewCardOrder) ([]*models.Flashcard, error) {
 	r.logger.Info("получение новых карточек для пользователя",
 		zap.Int64("user_id", userID),
 		zap.String("level", level),
-		zap.Int("limit", limit))
+		zap.Int("limit", limit),
+		zap.Bool("spaced", order == NewCardOrderSpaced))
 
 	// Сначала проверим общее количество карточек для отладки
 	var totalCards int
@@ -358,11 +832,32 @@ func (r *flashcardRepository) GetNewCardsForUser(ctx context.Context, userID int
 		SELECT f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
 		FROM flashcards f
 		LEFT JOIN user_flashcards uf ON f.id = uf.flashcard_id AND uf.user_id = $1
-		WHERE uf.id IS NULL AND f.level = $2
+		WHERE uf.id IS NULL AND f.level = $2 AND f.suspended = false AND f.category <> $4
 		ORDER BY RANDOM()
 		LIMIT $3`
+	args := []interface{}{userID, level, limit, IdiomCardCategory}
+
+	if order == NewCardOrderSpaced {
+		// Карточки без частотного ранга идут последней группой
+		query = `
+			WITH candidates AS (
+				SELECT f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at,
+				       CASE WHEN f.frequency_rank > 0 THEN (f.frequency_rank - 1) / $5 ELSE 2147483647 END AS bucket
+				FROM flashcards f
+				LEFT JOIN user_flashcards uf ON f.id = uf.flashcard_id AND uf.user_id = $1
+				WHERE uf.id IS NULL AND f.level = $2 AND f.suspended = false AND f.category <> $4
+			), spaced AS (
+				SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.bucket, c.category ORDER BY RANDOM()) AS turn
+				FROM candidates c
+			)
+			SELECT id, word, translation, example, level, category, created_at
+			FROM spaced
+			ORDER BY bucket, turn, RANDOM()
+			LIMIT $3`
+		args = append(args, frequencyBucketSize)
+	}
 
-	rows, err := r.db.Query(ctx, query, userID, level, limit)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения новых карточек: %w", err)
 	}
@@ -390,15 +885,105 @@ func (r *flashcardRepository) GetNewCardsForUser(ctx context.Context, userID int
 	return flashcards, nil
 }
 
-// GetNextCardToReview получает следующую карточку для повторения (с ближайшим временем)
+// GetNewCardsForUserByCategory получает новые карточки уровня из одной категории
+func (r *flashcardRepository) GetNewCardsForUserByCategory(ctx context.Context, userID int64, level, category string, limit int) ([]*models.Flashcard, error) {
+	query := `
+		SELECT f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
+		FROM flashcards f
+		LEFT JOIN user_flashcards uf ON f.id = uf.flashcard_id AND uf.user_id = $1
+		WHERE uf.id IS NULL AND f.level = $2 AND f.category = $3 AND f.suspended = false
+		ORDER BY RANDOM()
+		LIMIT $4`
+
+	rows, err := r.db.Query(ctx, query, userID, level, category, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения новых карточек категории: %w", err)
+	}
+	defer rows.Close()
+
+	var flashcards []*models.Flashcard
+	for rows.Next() {
+		flashcard := &models.Flashcard{}
+		if err := rows.Scan(
+			&flashcard.ID, &flashcard.Word, &flashcard.Translation,
+			&flashcard.Example, &flashcard.Level, &flashcard.Category, &flashcard.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования новой карточки категории: %w", err)
+		}
+		flashcards = append(flashcards, flashcard)
+	}
+
+	return flashcards, rows.Err()
+}
+
+// GetUnlearnedFlashcards возвращает карточки, которые пользователь начал, но еще не выучил
+func (r *flashcardRepository) GetUnlearnedFlashcards(ctx context.Context, userID int64) ([]*models.Flashcard, error) {
+	query := `
+		SELECT f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
+		FROM user_flashcards uf
+		JOIN flashcards f ON uf.flashcard_id = f.id
+		WHERE uf.user_id = $1 AND uf.is_learned = false`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения невыученных карточек: %w", err)
+	}
+	defer rows.Close()
+
+	var flashcards []*models.Flashcard
+	for rows.Next() {
+		flashcard := &models.Flashcard{}
+		if err := rows.Scan(
+			&flashcard.ID, &flashcard.Word, &flashcard.Translation,
+			&flashcard.Example, &flashcard.Level, &flashcard.Category, &flashcard.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования невыученной карточки: %w", err)
+		}
+		flashcards = append(flashcards, flashcard)
+	}
+
+	return flashcards, rows.Err()
+}
+
+// CountNewCardsSince считает карточки, которые занятия ввели пользователю начиная с since.
+// Импорт наборов и слова, добавленные вручную, в дневной лимит не входят.
+func (r *flashcardRepository) CountNewCardsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM user_flashcards WHERE user_id = $1 AND created_at >= $2 AND source = 'session'`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета новых карточек за день: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountAvailableNewCards считает карточки уровня, которые пользователь еще не начинал
+func (r *flashcardRepository) CountAvailableNewCards(ctx context.Context, userID int64, level string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM flashcards f
+		LEFT JOIN user_flashcards uf ON f.id = uf.flashcard_id AND uf.user_id = $1
+		WHERE uf.id IS NULL AND f.level = $2 AND f.suspended = false AND f.category <> $3`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID, level, IdiomCardCategory).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета доступных новых карточек: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetNextCardToReview получает следующую карточку для повторения (с ближайшим временем).
+// Если карточек нет, возвращает ErrFlashcardNotFound.
 func (r *flashcardRepository) GetNextCardToReview(ctx context.Context, userID int64) (*models.UserFlashcard, error) {
 	query := `
 		SELECT uf.id, uf.user_id, uf.flashcard_id, uf.difficulty, uf.review_count, 
-		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.created_at,
+		       uf.correct_count, uf.last_reviewed_at, uf.next_review_at, uf.is_learned, uf.easy_streak, uf.created_at,
 		       f.id, f.word, f.translation, f.example, f.level, f.category, f.created_at
 		FROM user_flashcards uf
 		JOIN flashcards f ON uf.flashcard_id = f.id
-		WHERE uf.user_id = $1 AND uf.is_learned = FALSE
+		WHERE uf.user_id = $1 AND uf.is_learned = FALSE AND f.suspended = FALSE
 		ORDER BY uf.next_review_at ASC
 		LIMIT 1`
 
@@ -410,15 +995,15 @@ func (r *flashcardRepository) GetNextCardToReview(ctx context.Context, userID in
 	err := row.Scan(
 		&userFlashcard.ID, &userFlashcard.UserID, &userFlashcard.FlashcardID,
 		&userFlashcard.Difficulty, &userFlashcard.ReviewCount, &userFlashcard.CorrectCount,
-		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.CreatedAt,
+		&userFlashcard.LastReviewedAt, &userFlashcard.NextReviewAt, &userFlashcard.IsLearned, &userFlashcard.EasyStreak, &userFlashcard.CreatedAt,
 		&flashcard.ID, &flashcard.Word, &flashcard.Translation,
 		&flashcard.Example, &flashcard.Level, &flashcard.Category, &flashcard.CreatedAt,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: у пользователя %d нет карточек на повторение", ErrFlashcardNotFound, userID)
+	}
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			return nil, nil // Нет карточек
-		}
 		return nil, fmt.Errorf("ошибка получения следующей карточки для повторения: %w", err)
 	}
 
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"lingua-ai/pkg/models"
+)
+
+// TestCreateUserFlashcardTwice проверяет, что повторное создание прогресса по карточке
+// (двойное нажатие «Начать изучение») возвращает существующую запись.
+// Нужна тестовая БД из BENCH_DATABASE_URL; без переменной тест пропускается.
+func TestCreateUserFlashcardTwice(t *testing.T) {
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("BENCH_DATABASE_URL не задан")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("ошибка подключения к БД: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	var userID, flashcardID int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO users (telegram_id, first_name, created_at, updated_at)
+		VALUES (-(extract(epoch from clock_timestamp()) * 1000000)::bigint, 'test', NOW(), NOW())
+		RETURNING id`).Scan(&userID)
+	if err != nil {
+		t.Fatalf("ошибка создания пользователя: %v", err)
+	}
+	err = db.QueryRow(ctx, `
+		INSERT INTO flashcards (word, translation, example, level, category)
+		VALUES ('idempotency-test', 'тест', '', 'beginner', 'test')
+		RETURNING id`).Scan(&flashcardID)
+	if err != nil {
+		t.Fatalf("ошибка создания карточки: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+		_, _ = db.Exec(ctx, `DELETE FROM flashcards WHERE id = $1`, flashcardID)
+	})
+
+	repo := &flashcardRepository{db: db, logger: zap.NewNop()}
+	first := &models.UserFlashcard{UserID: userID, FlashcardID: flashcardID, NextReviewAt: time.Now()}
+	if err := repo.CreateUserFlashcard(ctx, first); err != nil {
+		t.Fatalf("ошибка первого создания: %v", err)
+	}
+	second := &models.UserFlashcard{UserID: userID, FlashcardID: flashcardID, NextReviewAt: time.Now()}
+	if err := repo.CreateUserFlashcard(ctx, second); err != nil {
+		t.Fatalf("повторное создание не должно возвращать ошибку: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("ожидалась существующая запись %d, получено %d", first.ID, second.ID)
+	}
+}
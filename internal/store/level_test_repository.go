@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"lingua-ai/pkg/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// LevelTestRepository определяет интерфейс для незавершенных тестов уровня
+type LevelTestRepository interface {
+	Create(ctx context.Context, test *models.LevelTest) error
+	Get(ctx context.Context, userID int64) (*models.LevelTest, error)
+	Update(ctx context.Context, test *models.LevelTest) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// PostgresLevelTestRepository реализует LevelTestRepository для PostgreSQL
+type PostgresLevelTestRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewLevelTestRepository создает новый репозиторий тестов уровня
+func NewLevelTestRepository(db *pgxpool.Pool, logger *zap.Logger) LevelTestRepository {
+	return &PostgresLevelTestRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create сохраняет новый тест пользователя, заменяя незавершенный предыдущий
+func (r *PostgresLevelTestRepository) Create(ctx context.Context, test *models.LevelTest) error {
+	questions, err := json.Marshal(test.Questions)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации вопросов теста: %w", err)
+	}
+	answers, err := json.Marshal(test.Answers)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации ответов теста: %w", err)
+	}
+
+	query := `
+		INSERT INTO level_tests (user_id, current_question, questions, answers, score, max_score, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET current_question = EXCLUDED.current_question,
+		    questions = EXCLUDED.questions,
+		    answers = EXCLUDED.answers,
+		    score = EXCLUDED.score,
+		    max_score = EXCLUDED.max_score,
+		    started_at = EXCLUDED.started_at,
+		    updated_at = EXCLUDED.updated_at`
+
+	_, err = r.db.Exec(ctx, query,
+		test.UserID, test.CurrentQuestion, questions, answers, test.Score, test.MaxScore, test.StartedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения теста уровня: %w", err)
+	}
+
+	return nil
+}
+
+// Get получает незавершенный тест пользователя
+func (r *PostgresLevelTestRepository) Get(ctx context.Context, userID int64) (*models.LevelTest, error) {
+	query := `
+		SELECT user_id, current_question, questions, answers, score, max_score, started_at
+		FROM level_tests
+		WHERE user_id = $1`
+
+	test := &models.LevelTest{}
+	var questions, answers []byte
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&test.UserID, &test.CurrentQuestion, &questions, &answers, &test.Score, &test.MaxScore, &test.StartedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user_id %d", ErrLevelTestNotFound, userID)
+		}
+		return nil, fmt.Errorf("ошибка получения теста уровня: %w", err)
+	}
+
+	if err := json.Unmarshal(questions, &test.Questions); err != nil {
+		return nil, fmt.Errorf("ошибка разбора вопросов теста: %w", err)
+	}
+	if err := json.Unmarshal(answers, &test.Answers); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответов теста: %w", err)
+	}
+
+	return test, nil
+}
+
+// Update сохраняет ответы, счет и текущий вопрос теста
+func (r *PostgresLevelTestRepository) Update(ctx context.Context, test *models.LevelTest) error {
+	answers, err := json.Marshal(test.Answers)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации ответов теста: %w", err)
+	}
+
+	query := `
+		UPDATE level_tests
+		SET current_question = $2, answers = $3, score = $4, updated_at = NOW()
+		WHERE user_id = $1`
+
+	result, err := r.db.Exec(ctx, query, test.UserID, test.CurrentQuestion, answers, test.Score)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления теста уровня: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: user_id %d", ErrLevelTestNotFound, test.UserID)
+	}
+
+	return nil
+}
+
+// Delete удаляет тест пользователя после завершения или отмены
+func (r *PostgresLevelTestRepository) Delete(ctx context.Context, userID int64) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM level_tests WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("ошибка удаления теста уровня: %w", err)
+	}
+
+	return nil
+}
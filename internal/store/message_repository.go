@@ -13,20 +13,37 @@ import (
 
 // Константы для управления историей сообщений
 const (
-	MaxMessagesPerUser = 10 // Максимальное количество сообщений на пользователя
+	MaxMessagesPerUser = 10 // Максимальное количество сообщений на пользователя по умолчанию
 )
 
+// trimMessagesQuery удаляет самые старые несистемные сообщения сверх лимита.
+// OFFSET по индексу (user_id, created_at DESC) не сканирует всю историю пользователя.
+const trimMessagesQuery = `
+	DELETE FROM user_messages
+	WHERE id IN (
+		SELECT id FROM user_messages
+		WHERE user_id = $1 AND role <> 'system'
+		ORDER BY created_at DESC
+		OFFSET $2
+	)`
+
 // messageRepository реализует MessageRepository
 type messageRepository struct {
-	db     *pgxpool.Pool
-	logger *zap.Logger
+	db          *pgxpool.Pool
+	logger      *zap.Logger
+	maxMessages int // сколько несистемных сообщений пользователя хранится
 }
 
-// NewMessageRepository создает новый репозиторий сообщений
-func NewMessageRepository(db *pgxpool.Pool, logger *zap.Logger) MessageRepository {
+// NewMessageRepository создает новый репозиторий сообщений; maxMessages < 1 — лимит по умолчанию
+func NewMessageRepository(db *pgxpool.Pool, logger *zap.Logger, maxMessages int) MessageRepository {
+	if maxMessages < 1 {
+		maxMessages = MaxMessagesPerUser
+	}
+
 	return &messageRepository{
-		db:     db,
-		logger: logger,
+		db:          db,
+		logger:      logger,
+		maxMessages: maxMessages,
 	}
 }
 
@@ -89,7 +106,7 @@ func (r *messageRepository) GetByUserID(ctx context.Context, userID int64, limit
 // GetChatHistory получает историю диалога пользователя
 func (r *messageRepository) GetChatHistory(ctx context.Context, userID int64, limit int) (*models.ChatHistory, error) {
 	// Получаем пользователя
-	userRepo := NewUserRepository(r.db, r.logger)
+	userRepo := NewUserRepository(r.db, r.logger, DefaultStreakGraceDays, time.UTC)
 	user, err := userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
@@ -112,6 +129,37 @@ func (r *messageRepository) GetChatHistory(ctx context.Context, userID int64, li
 	}, nil
 }
 
+// GetMessagesByDateRange получает сообщения пользователя за период от старых к новым
+func (r *messageRepository) GetMessagesByDateRange(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.UserMessage, error) {
+	query := `
+		SELECT id, user_id, role, content, created_at
+		FROM user_messages
+		WHERE user_id = $1 AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at
+		LIMIT $4`
+
+	rows, err := r.db.Query(ctx, query, userID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сообщений за период: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []models.UserMessage
+	for rows.Next() {
+		var msg models.UserMessage
+		if err := rows.Scan(&msg.ID, &msg.UserID, &msg.Role, &msg.Content, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования сообщения: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по сообщениям: %w", err)
+	}
+
+	return messages, nil
+}
+
 // DeleteByUserID удаляет все сообщения пользователя
 func (r *messageRepository) DeleteByUserID(ctx context.Context, userID int64) error {
 	query := `DELETE FROM user_messages WHERE user_id = $1`
@@ -196,40 +244,17 @@ func (r *messageRepository) CreateWithCleanup(ctx context.Context, msg *models.U
 		return fmt.Errorf("ошибка создания сообщения: %w", err)
 	}
 
-	// Проверяем количество сообщений и очищаем при необходимости
-	var messageCount int
-	countQuery := `SELECT COUNT(*) FROM user_messages WHERE user_id = $1`
-	err = tx.QueryRow(ctx, countQuery, msg.UserID).Scan(&messageCount)
+	// Удаляем самые старые сообщения сверх лимита, системные не трогаем
+	result, err := tx.Exec(ctx, trimMessagesQuery, msg.UserID, r.maxMessages)
 	if err != nil {
-		return fmt.Errorf("ошибка подсчета сообщений: %w", err)
-	}
-
-	// Если превышен лимит - удаляем старые сообщения
-	if messageCount > MaxMessagesPerUser {
-		cleanupQuery := `
-			DELETE FROM user_messages 
-			WHERE id IN (
-				SELECT id FROM (
-					SELECT id, 
-						   ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC) as rn
-					FROM user_messages 
-					WHERE user_id = $1
-				) ranked
-				WHERE rn > $2
-			)`
-
-		result, err := tx.Exec(ctx, cleanupQuery, msg.UserID, MaxMessagesPerUser)
-		if err != nil {
-			return fmt.Errorf("ошибка автоочистки старых сообщений: %w", err)
-		}
+		return fmt.Errorf("ошибка автоочистки старых сообщений: %w", err)
+	}
 
-		deletedCount := result.RowsAffected()
-		if deletedCount > 0 {
-			r.logger.Debug("автоочистка старых сообщений",
-				zap.Int64("user_id", msg.UserID),
-				zap.Int64("deleted_count", deletedCount),
-				zap.Int("max_messages", MaxMessagesPerUser))
-		}
+	if deletedCount := result.RowsAffected(); deletedCount > 0 {
+		r.logger.Debug("автоочистка старых сообщений",
+			zap.Int64("user_id", msg.UserID),
+			zap.Int64("deleted_count", deletedCount),
+			zap.Int("max_messages", r.maxMessages))
 	}
 
 	// Коммитим транзакцию
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// benchHistorySize сколько сообщений накапливал активный пользователь до ограничения истории
+const benchHistorySize = 5000
+
+// benchMessageRepository подключается к тестовой БД из BENCH_DATABASE_URL и создает
+// пользователя с историей из size сообщений; без переменной бенчмарк пропускается
+func benchMessageRepository(b *testing.B, size int) (*messageRepository, int64) {
+	b.Helper()
+
+	dsn := os.Getenv("BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_URL не задан")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatalf("ошибка подключения к БД: %v", err)
+	}
+	b.Cleanup(db.Close)
+
+	var userID int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO users (telegram_id, first_name, created_at, updated_at)
+		VALUES (-(extract(epoch from clock_timestamp()) * 1000000)::bigint, 'bench', NOW(), NOW())
+		RETURNING id`).Scan(&userID)
+	if err != nil {
+		b.Fatalf("ошибка создания пользователя: %v", err)
+	}
+	b.Cleanup(func() {
+		_, _ = db.Exec(ctx, `DELETE FROM user_messages WHERE user_id = $1`, userID)
+		_, _ = db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO user_messages (user_id, role, content, created_at)
+		SELECT $1, CASE WHEN i % 2 = 0 THEN 'user' ELSE 'assistant' END, 'message ' || i,
+		       NOW() - make_interval(secs => $2 - i)
+		FROM generate_series(1, $2) AS i`, userID, size)
+	if err != nil {
+		b.Fatalf("ошибка заполнения истории: %v", err)
+	}
+
+	return &messageRepository{db: db, logger: zap.NewNop(), maxMessages: MaxMessagesPerUser}, userID
+}
+
+// BenchmarkChatHistoryFetch сравнивает чтение истории у пользователя с накопленной
+// и с ограниченной историей: go test -bench ChatHistory ./internal/store/
+func BenchmarkChatHistoryFetch(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		size int
+	}{
+		{"до ограничения", benchHistorySize},
+		{"после ограничения", MaxMessagesPerUser},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			repo, userID := benchMessageRepository(b, bc.size)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetByUserID(ctx, userID, MaxMessagesPerUser); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"lingua-ai/pkg/models"
 
@@ -81,8 +83,8 @@ func (r *PostgresPaymentRepository) GetByPaymentID(ctx context.Context, paymentI
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("платеж не найден")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrPaymentNotFound, paymentID)
 		}
 		return nil, fmt.Errorf("ошибка получения платежа: %w", err)
 	}
@@ -90,6 +92,47 @@ func (r *PostgresPaymentRepository) GetByPaymentID(ctx context.Context, paymentI
 	return payment, nil
 }
 
+// GetByUserID получает все платежи пользователя в любом статусе, от новых к старым
+func (r *PostgresPaymentRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.Payment, error) {
+	query := `
+		SELECT id, user_id, amount, currency, payment_id, status,
+		       premium_duration_days, created_at, completed_at, metadata
+		FROM payments
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения платежей пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+	for rows.Next() {
+		payment := &models.Payment{}
+		if err := rows.Scan(
+			&payment.ID,
+			&payment.UserID,
+			&payment.Amount,
+			&payment.Currency,
+			&payment.PaymentID,
+			&payment.Status,
+			&payment.PremiumDurationDays,
+			&payment.CreatedAt,
+			&payment.CompletedAt,
+			&payment.Metadata,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения платежа: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка получения платежей пользователя: %w", err)
+	}
+
+	return payments, nil
+}
+
 // Update обновляет платеж
 func (r *PostgresPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
 	query := `
@@ -115,3 +158,20 @@ func (r *PostgresPaymentRepository) Update(ctx context.Context, payment *models.
 
 	return nil
 }
+
+// CountGiftsByGifter возвращает количество подарков премиума, сделанных пользователем с указанной даты
+func (r *PostgresPaymentRepository) CountGiftsByGifter(ctx context.Context, gifterID int64, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM payments
+		WHERE metadata->>'type' = 'gift'
+		  AND (metadata->>'gifter_id')::BIGINT = $1
+		  AND created_at >= $2`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, gifterID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета подарков: %w", err)
+	}
+
+	return count, nil
+}
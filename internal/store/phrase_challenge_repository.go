@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// PhraseChallengeRepository определяет интерфейс для результатов челленджа «Фраза дня»
+type PhraseChallengeRepository interface {
+	RecordAttempt(ctx context.Context, userID int64, day time.Time, score float64) (float64, error)
+	GetRecentDays(ctx context.Context, userID int64, limit int) ([]models.PhraseChallengeDay, error)
+}
+
+// PostgresPhraseChallengeRepository реализует PhraseChallengeRepository для PostgreSQL
+type PostgresPhraseChallengeRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewPhraseChallengeRepository создает новый репозиторий челленджа
+func NewPhraseChallengeRepository(db *pgxpool.Pool, logger *zap.Logger) PhraseChallengeRepository {
+	return &PostgresPhraseChallengeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordAttempt сохраняет попытку за день и обновляет лучшую оценку.
+// Возвращает лучшую оценку за день до этой попытки (0, если попыток не было).
+func (r *PostgresPhraseChallengeRepository) RecordAttempt(ctx context.Context, userID int64, day time.Time, score float64) (float64, error) {
+	// CTE видит состояние до вставки, поэтому prev содержит прежний результат
+	query := `
+		WITH prev AS (
+			SELECT best_score FROM phrase_challenge_results
+			WHERE user_id = $1 AND challenge_date = $2
+		)
+		INSERT INTO phrase_challenge_results (user_id, challenge_date, best_score, attempts)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (user_id, challenge_date) DO UPDATE
+		SET best_score = GREATEST(phrase_challenge_results.best_score, EXCLUDED.best_score),
+		    attempts = phrase_challenge_results.attempts + 1,
+		    updated_at = NOW()
+		RETURNING COALESCE((SELECT best_score FROM prev), 0)`
+
+	var previousBest float64
+	if err := r.db.QueryRow(ctx, query, userID, day, score).Scan(&previousBest); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения попытки челленджа: %w", err)
+	}
+
+	return previousBest, nil
+}
+
+// GetRecentDays возвращает последние дни участия пользователя, начиная с самого свежего
+func (r *PostgresPhraseChallengeRepository) GetRecentDays(ctx context.Context, userID int64, limit int) ([]models.PhraseChallengeDay, error) {
+	query := `
+		SELECT challenge_date, best_score, attempts
+		FROM phrase_challenge_results
+		WHERE user_id = $1
+		ORDER BY challenge_date DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения результатов челленджа: %w", err)
+	}
+	defer rows.Close()
+
+	var days []models.PhraseChallengeDay
+	for rows.Next() {
+		var day models.PhraseChallengeDay
+		if err := rows.Scan(&day.Date, &day.BestScore, &day.Attempts); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования результата челленджа: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
@@ -2,12 +2,16 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"lingua-ai/internal/config"
+	"lingua-ai/internal/premium"
 	"lingua-ai/pkg/models"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -19,6 +23,11 @@ type Store interface {
 	Flashcard() FlashcardRepository
 	Referral() ReferralRepository
 	Payment() PaymentRepository
+	WordPack() WordPackRepository
+	PhraseChallenge() PhraseChallengeRepository
+	FlashcardReport() FlashcardReportRepository
+	DialogContext() DialogContextRepository
+	LevelTest() LevelTestRepository
 	DB() *pgxpool.Pool
 	Close() error
 }
@@ -32,6 +41,11 @@ type store struct {
 	flashcard FlashcardRepository
 	referral  ReferralRepository
 	payment   PaymentRepository
+	wordPack  WordPackRepository
+	phrases   PhraseChallengeRepository
+	reports   FlashcardReportRepository
+	dialogs   DialogContextRepository
+	tests     LevelTestRepository
 }
 
 // UserRepository интерфейс для работы с пользователями
@@ -39,16 +53,49 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id int64) (*models.User, error)
 	GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	UpdateState(ctx context.Context, userID int64, state string) error
 	AddXP(ctx context.Context, userID int64, xp int) error
 	UpdateLastSeen(ctx context.Context, userID int64) error
-	UpdateStudyActivity(ctx context.Context, userID int64) error
+	UpdateStudyActivity(ctx context.Context, userID int64) (int, error)
 	GetStats(ctx context.Context, userID int64) (*models.UserStats, error)
 	GetTopUsersByStreak(ctx context.Context, limit int) ([]*models.User, error)
+	GetTopUsersByStreakOnly(ctx context.Context, limit int) ([]*models.User, error)
 	GetAll(ctx context.Context) ([]*models.User, error)
 	GetInactiveUsers(ctx context.Context, inactiveDuration time.Duration) ([]*models.User, error)
 	IncrementMessagesCount(ctx context.Context, userID int64) error
+	ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error)
+	AdjustExerciseDifficultyBias(ctx context.Context, userID int64, delta int) (int, error)
+	MarkOnboardingCompleted(ctx context.Context, userID int64) (bool, error)
+	GrantReferralReward(ctx context.Context, userID int64, earned, maxRewards int) (bool, error)
+	SetReferredBy(ctx context.Context, userID, referrerID int64) error
+	IncrementReferralCount(ctx context.Context, userID int64) error
+	ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error)
+	SetInitialLevel(ctx context.Context, userID int64, level, assessment string) (bool, error)
+	SetLevelAssessment(ctx context.Context, userID int64, assessment string) error
+	SetLearningLanguage(ctx context.Context, userID int64, language string) error
+	SetNewCardsPerDay(ctx context.Context, userID int64, perDay int) error
+	GetStreakAtRiskUsers(ctx context.Context, now time.Time) ([]*models.User, error)
+	MarkStreakWarningSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error)
+	SetStreakWarnings(ctx context.Context, userID int64, enabled bool, snoozedUntil *time.Time) error
+	GetDailyReminderUsers(ctx context.Context, studiedBefore, activeSince time.Time) ([]*models.User, error)
+	MarkDailyReminderSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error)
+	GetWordOfDayUsers(ctx context.Context, sentBefore, activeSince time.Time) ([]*models.User, error)
+	MarkWordOfDaySent(ctx context.Context, userID int64, dayStart time.Time) (bool, error)
+	SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error
+	SetFlashcardCategory(ctx context.Context, userID int64, category string) error
+	SetTimezone(ctx context.Context, userID int64, timezone string) error
+	SetWeeklyWordTarget(ctx context.Context, userID int64, target int) error
+	MarkWeeklyTargetCompleted(ctx context.Context, userID int64, weekStart time.Time) (bool, error)
+	GetWeeklyTargetReminderUsers(ctx context.Context, weekStart time.Time) ([]*models.User, error)
+	MarkWeeklyTargetReminded(ctx context.Context, userID int64, weekStart time.Time) (bool, error)
+	GetPremiumExpiringUsers(ctx context.Context, from, until time.Time) ([]*models.User, error)
+	MarkPremiumExpiryReminded(ctx context.Context, userID int64, expiresAt time.Time) (bool, error)
+	GetPremiumExpiredUsers(ctx context.Context, since, now time.Time) ([]*models.User, error)
+	MarkPremiumExpiredNotified(ctx context.Context, userID int64, expiresAt time.Time) (bool, error)
+	RecordExerciseAnswer(ctx context.Context, userID int64, correct bool, window int) (int, int, error)
+	GetPlatformStats(ctx context.Context, activeSince time.Time) (*models.PlatformStats, error)
 }
 
 // MessageRepository интерфейс для работы с сообщениями
@@ -57,6 +104,7 @@ type MessageRepository interface {
 	CreateWithCleanup(ctx context.Context, msg *models.UserMessage) error
 	GetByUserID(ctx context.Context, userID int64, limit int) ([]models.UserMessage, error)
 	GetChatHistory(ctx context.Context, userID int64, limit int) (*models.ChatHistory, error)
+	GetMessagesByDateRange(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.UserMessage, error)
 	GetMessageCount(ctx context.Context, userID int64) (int, error)
 	CleanupOldMessages(ctx context.Context, userID int64, keepCount int) error
 	DeleteByUserID(ctx context.Context, userID int64) error
@@ -66,7 +114,9 @@ type MessageRepository interface {
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *models.Payment) error
 	GetByPaymentID(ctx context.Context, paymentID string) (*models.Payment, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*models.Payment, error)
 	Update(ctx context.Context, payment *models.Payment) error
+	CountGiftsByGifter(ctx context.Context, gifterID int64, since time.Time) (int, error)
 }
 
 // NewStore создает новое подключение к базе данных
@@ -74,6 +124,11 @@ func NewStore(cfg *config.Config, logger *zap.Logger) (Store, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	resetLoc, err := time.LoadLocation(cfg.App.DailyResetTZ)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки часового пояса DAILY_RESET_TZ: %w", err)
+	}
+
 	// Создание пула подключений
 	poolConfig, err := pgxpool.ParseConfig(cfg.Database.GetDSN())
 	if err != nil {
@@ -105,11 +160,20 @@ func NewStore(cfg *config.Config, logger *zap.Logger) (Store, error) {
 	}
 
 	// Инициализация репозиториев
-	s.user = NewUserRepository(db, logger)
-	s.msg = NewMessageRepository(db, logger)
+	s.user = NewCachedUserRepository(
+		NewUserRepository(db, logger, cfg.App.StreakGraceDays, resetLoc),
+		time.Duration(cfg.Database.UserCacheTTLSec)*time.Second,
+		resetLoc,
+	)
+	s.msg = NewMessageRepository(db, logger, cfg.App.MaxStoredMsgs)
 	s.flashcard = NewFlashcardRepository(db, logger)
 	s.referral = NewReferralRepository(db, logger)
 	s.payment = NewPaymentRepository(db, logger)
+	s.wordPack = NewWordPackRepository(db, logger)
+	s.phrases = NewPhraseChallengeRepository(db, logger)
+	s.reports = NewFlashcardReportRepository(db, logger)
+	s.dialogs = NewDialogContextRepository(db, logger)
+	s.tests = NewLevelTestRepository(db, logger)
 
 	return s, nil
 }
@@ -139,6 +203,31 @@ func (s *store) Payment() PaymentRepository {
 	return s.payment
 }
 
+// WordPack возвращает репозиторий наборов слов
+func (s *store) WordPack() WordPackRepository {
+	return s.wordPack
+}
+
+// PhraseChallenge возвращает репозиторий челленджа «Фраза дня»
+func (s *store) PhraseChallenge() PhraseChallengeRepository {
+	return s.phrases
+}
+
+// FlashcardReport возвращает репозиторий жалоб на карточки
+func (s *store) FlashcardReport() FlashcardReportRepository {
+	return s.reports
+}
+
+// DialogContext возвращает репозиторий сохраненных контекстов диалога
+func (s *store) DialogContext() DialogContextRepository {
+	return s.dialogs
+}
+
+// LevelTest возвращает репозиторий незавершенных тестов уровня
+func (s *store) LevelTest() LevelTestRepository {
+	return s.tests
+}
+
 // DB возвращает подключение к базе данных
 func (s *store) DB() *pgxpool.Pool {
 	return s.db
@@ -153,15 +242,27 @@ func (s *store) Close() error {
 
 // userRepository реализует UserRepository
 type userRepository struct {
-	db     *pgxpool.Pool
-	logger *zap.Logger
+	db              *pgxpool.Pool
+	logger          *zap.Logger
+	streakGraceDays int            // Сколько пропущенных дней подряд не сбрасывают streak
+	resetLoc        *time.Location // Пояс дней серии для пользователей, не выбравших свой
 }
 
-// NewUserRepository создает новый репозиторий пользователей
-func NewUserRepository(db *pgxpool.Pool, logger *zap.Logger) UserRepository {
+// NewUserRepository создает новый репозиторий пользователей. resetLoc — пояс дневного
+// сброса (DAILY_RESET_TZ), по которому считаются дни серии без /timezone.
+func NewUserRepository(db *pgxpool.Pool, logger *zap.Logger, streakGraceDays int, resetLoc *time.Location) UserRepository {
+	if streakGraceDays < 0 {
+		streakGraceDays = DefaultStreakGraceDays
+	}
+	if resetLoc == nil {
+		resetLoc = time.UTC
+	}
+
 	return &userRepository{
-		db:     db,
-		logger: logger,
+		db:              db,
+		logger:          logger,
+		streakGraceDays: streakGraceDays,
+		resetLoc:        resetLoc,
 	}
 }
 
@@ -186,7 +287,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 		user.CurrentState = "idle" // Статус по умолчанию
 	}
 	if user.MaxMessages == 0 {
-		user.MaxMessages = 7 // Новый лимит по умолчанию для бесплатных пользователей
+		user.MaxMessages = premium.DefaultFreeMessageLimit
 	}
 
 	err := r.db.QueryRow(ctx, query,
@@ -196,6 +297,10 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 		user.ReferralCount, user.ReferredBy,
 	).Scan(&user.ID)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return fmt.Errorf("%w: telegram_id %d", ErrUserAlreadyExists, user.TelegramID)
+	}
 	if err != nil {
 		return fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
@@ -214,7 +319,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 	query := `
 		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
 		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date,
-		       referral_code, referral_count, referred_by
+		       referral_code, referral_count, referred_by, exercise_difficulty_bias, onboarding_completed_at, referral_reward_months, level_selected_at, new_cards_per_day, weekly_word_target, level_assessment, learning_language, reminders_enabled, flashcard_category, timezone
 		FROM users WHERE id = $1`
 
 	user := &models.User{}
@@ -222,9 +327,12 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 		&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
 		&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState, &user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
 		&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
-		&user.ReferralCode, &user.ReferralCount, &user.ReferredBy,
+		&user.ReferralCode, &user.ReferralCount, &user.ReferredBy, &user.ExerciseDifficultyBias, &user.OnboardingCompletedAt, &user.ReferralRewardMonths, &user.LevelSelectedAt, &user.NewCardsPerDay, &user.WeeklyWordTarget, &user.LevelAssessment, &user.LearningLanguage, &user.RemindersEnabled, &user.FlashcardCategory, &user.Timezone,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: id %d", ErrUserNotFound, id)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения пользователя по ID: %w", err)
 	}
@@ -237,7 +345,7 @@ func (r *userRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 	query := `
 		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
 		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date,
-		       referral_code, referral_count, referred_by
+		       referral_code, referral_count, referred_by, exercise_difficulty_bias, onboarding_completed_at, referral_reward_months, level_selected_at, new_cards_per_day, weekly_word_target, level_assessment, learning_language, reminders_enabled, flashcard_category, timezone
 		FROM users WHERE telegram_id = $1`
 
 	user := &models.User{}
@@ -245,9 +353,12 @@ func (r *userRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 		&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
 		&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState, &user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
 		&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
-		&user.ReferralCode, &user.ReferralCount, &user.ReferredBy,
+		&user.ReferralCode, &user.ReferralCount, &user.ReferredBy, &user.ExerciseDifficultyBias, &user.OnboardingCompletedAt, &user.ReferralRewardMonths, &user.LevelSelectedAt, &user.NewCardsPerDay, &user.WeeklyWordTarget, &user.LevelAssessment, &user.LearningLanguage, &user.RemindersEnabled, &user.FlashcardCategory, &user.Timezone,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: telegram_id %d", ErrUserNotFound, telegramID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения пользователя по Telegram ID: %w", err)
 	}
@@ -255,13 +366,41 @@ func (r *userRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 	return user, nil
 }
 
-// Update обновляет пользователя
+// GetByUsername получает пользователя по username (без учета регистра)
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date,
+		       referral_code, referral_count, referred_by, exercise_difficulty_bias, onboarding_completed_at, referral_reward_months, level_selected_at, new_cards_per_day, weekly_word_target, level_assessment, learning_language, reminders_enabled, flashcard_category, timezone
+		FROM users WHERE LOWER(username) = LOWER($1)`
+
+	user := &models.User{}
+	err := r.db.QueryRow(ctx, query, username).Scan(
+		&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+		&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState, &user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+		&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+		&user.ReferralCode, &user.ReferralCount, &user.ReferredBy, &user.ExerciseDifficultyBias, &user.OnboardingCompletedAt, &user.ReferralRewardMonths, &user.LevelSelectedAt, &user.NewCardsPerDay, &user.WeeklyWordTarget, &user.LevelAssessment, &user.LearningLanguage, &user.RemindersEnabled, &user.FlashcardCategory, &user.Timezone,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: username %s", ErrUserNotFound, username)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователя по username: %w", err)
+	}
+
+	return user, nil
+}
+
+// Update обновляет пользователя. referral_count и referred_by не пишутся: счетчик
+// пересчитывает триггер по таблице referrals, и устаревшая копия пользователя
+// не должна его перезаписывать. Для них есть IncrementReferralCount и SetReferredBy.
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users 
 		SET username = $2, first_name = $3, last_name = $4, level = $5, xp = $6, current_state = $7, last_seen = $8, updated_at = $9,
 		    is_premium = $10, premium_expires_at = $11, messages_count = $12, max_messages = $13, messages_reset_date = $14, last_test_date = $15,
-		    referral_code = $16, referral_count = $17, referred_by = $18
+		    referral_code = $16
 		WHERE id = $1`
 
 	user.UpdatedAt = time.Now()
@@ -270,7 +409,7 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 		user.ID, user.Username, user.FirstName, user.LastName,
 		user.Level, user.XP, user.CurrentState, user.LastSeen, user.UpdatedAt,
 		user.IsPremium, user.PremiumExpiresAt, user.MessagesCount, user.MaxMessages, user.MessagesResetDate, user.LastTestDate,
-		user.ReferralCode, user.ReferralCount, user.ReferredBy,
+		user.ReferralCode,
 	)
 
 	if err != nil {
@@ -278,13 +417,31 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", user.ID)
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, user.ID)
 	}
 
 	r.logger.Info("пользователь обновлен", zap.Int64("user_id", user.ID))
 	return nil
 }
 
+// ResetDailyMessageCounts обнуляет счетчики сообщений всех пользователей,
+// у которых дата последнего сброса раньше today. Пользователей со своим часовым
+// поясом не трогает: их день начинается в другое время, и счетчик сбрасывается
+// при первом сообщении нового дня.
+func (r *userRepository) ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error) {
+	query := `
+		UPDATE users
+		SET messages_count = 0, messages_reset_date = $1, updated_at = $2
+		WHERE (messages_reset_date IS NULL OR messages_reset_date < $1) AND timezone = ''`
+
+	result, err := r.db.Exec(ctx, query, today, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сброса дневных счетчиков сообщений: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // IncrementMessagesCount увеличивает счетчик сообщений пользователя
 func (r *userRepository) IncrementMessagesCount(ctx context.Context, userID int64) error {
 	query := `UPDATE users SET messages_count = messages_count + 1, updated_at = $2 WHERE id = $1`
@@ -311,13 +468,213 @@ func (r *userRepository) IncrementMessagesCount(ctx context.Context, userID int6
 		zap.Int64("rows_affected", rowsAffected))
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", userID)
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
 	}
 
 	r.logger.Info("счетчик сообщений увеличен", zap.Int64("user_id", userID))
 	return nil
 }
 
+// SetReferredBy сохраняет, кто пригласил пользователя
+func (r *userRepository) SetReferredBy(ctx context.Context, userID, referrerID int64) error {
+	query := `UPDATE users SET referred_by = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, referrerID)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения пригласившего пользователя: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// IncrementReferralCount атомарно увеличивает счетчик приглашенных пользователей
+func (r *userRepository) IncrementReferralCount(ctx context.Context, userID int64) error {
+	query := `UPDATE users SET referral_count = referral_count + 1, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления счетчика рефералов: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// AdjustExerciseDifficultyBias атомарно изменяет смещение сложности упражнений в допустимых пределах
+func (r *userRepository) AdjustExerciseDifficultyBias(ctx context.Context, userID int64, delta int) (int, error) {
+	query := `
+		UPDATE users
+		SET exercise_difficulty_bias = LEAST(GREATEST(exercise_difficulty_bias + $2, $3), $4), updated_at = NOW()
+		WHERE id = $1
+		RETURNING exercise_difficulty_bias`
+
+	var bias int
+	err := r.db.QueryRow(ctx, query, userID, delta,
+		models.MinExerciseDifficultyBias, models.MaxExerciseDifficultyBias).Scan(&bias)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления сложности упражнений: %w", err)
+	}
+
+	return bias, nil
+}
+
+// MarkOnboardingCompleted отмечает первое прохождение тура.
+// Возвращает true, только если отметка поставлена этим вызовом.
+func (r *userRepository) MarkOnboardingCompleted(ctx context.Context, userID int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET onboarding_completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND onboarding_completed_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки прохождения тура: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// SetInitialLevel сохраняет уровень, выбранный пользователем при первом запуске,
+// и способ его определения. Возвращает true, только если уровень выбран этим вызовом.
+func (r *userRepository) SetInitialLevel(ctx context.Context, userID int64, level, assessment string) (bool, error) {
+	query := `
+		UPDATE users
+		SET level = $2, level_assessment = $3, level_selected_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND level_selected_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, userID, level, assessment)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сохранения стартового уровня: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// SetLevelAssessment сохраняет, как определен текущий уровень пользователя
+func (r *userRepository) SetLevelAssessment(ctx context.Context, userID int64, assessment string) error {
+	query := `
+		UPDATE users
+		SET level_assessment = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, assessment)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения способа определения уровня: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// SetLearningLanguage сохраняет изучаемый язык пользователя
+func (r *userRepository) SetLearningLanguage(ctx context.Context, userID int64, language string) error {
+	query := `
+		UPDATE users
+		SET learning_language = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, language)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения изучаемого языка: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// SetNewCardsPerDay сохраняет темп изучения новых карточек
+func (r *userRepository) SetNewCardsPerDay(ctx context.Context, userID int64, perDay int) error {
+	query := `
+		UPDATE users
+		SET new_cards_per_day = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, perDay)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения темпа новых карточек: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// GrantReferralReward продлевает премиум на месяц за рефералов, если пользователь
+// заработал больше наград, чем уже получил, и не превышен лимит maxRewards.
+// Условие проверяется в одном UPDATE, поэтому параллельные активации не дают лишних наград.
+func (r *userRepository) GrantReferralReward(ctx context.Context, userID int64, earned, maxRewards int) (bool, error) {
+	query := `
+		UPDATE users
+		SET referral_reward_months = referral_reward_months + 1,
+		    is_premium = TRUE,
+		    premium_expires_at = GREATEST(COALESCE(premium_expires_at, NOW()), NOW()) + INTERVAL '1 month',
+		    updated_at = NOW()
+		WHERE id = $1 AND referral_reward_months < LEAST($2::INTEGER, $3::INTEGER)`
+
+	result, err := r.db.Exec(ctx, query, userID, earned, maxRewards)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начисления награды за рефералов: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// ApplyPremiumGift в одной транзакции сохраняет платеж-подарок и продлевает премиум
+// получателя payment.UserID на payment.PremiumDurationDays дней. Активная подписка
+// продлевается от даты окончания, а не сокращается. Возвращает новую дату окончания.
+func (r *userRepository) ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `
+		UPDATE users
+		SET is_premium = TRUE,
+		    premium_expires_at = GREATEST(COALESCE(premium_expires_at, NOW()), NOW()) + make_interval(days => $2),
+		    max_messages = 0,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING premium_expires_at`, payment.UserID, payment.PremiumDurationDays).Scan(&expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%w: ID %d", ErrUserNotFound, payment.UserID)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка продления премиума: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments (
+			user_id, amount, currency, payment_id, status,
+			premium_duration_days, created_at, completed_at, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		payment.UserID, payment.Amount, payment.Currency, payment.PaymentID, payment.Status,
+		payment.PremiumDurationDays, payment.CreatedAt, payment.CompletedAt, payment.Metadata,
+	).Scan(&payment.ID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка сохранения подарка: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return expiresAt, nil
+}
+
 // UpdateLastSeen обновляет время последнего посещения
 func (r *userRepository) UpdateLastSeen(ctx context.Context, userID int64) error {
 	query := `UPDATE users SET last_seen = $2, updated_at = $3 WHERE id = $1`
@@ -330,7 +687,7 @@ func (r *userRepository) UpdateLastSeen(ctx context.Context, userID int64) error
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", userID)
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
 	}
 
 	return nil
@@ -343,13 +700,16 @@ func (r *userRepository) GetStats(ctx context.Context, userID int64) (*models.Us
 			u.id as user_id,
 			u.xp as total_xp,
 			u.study_streak,
-			u.last_study_date
+			u.last_study_date,
+			u.exercise_answers,
+			u.exercise_correct
 		FROM users u
 		WHERE u.id = $1`
 
 	stats := &models.UserStats{}
 	err := r.db.QueryRow(ctx, query, userID).Scan(
 		&stats.UserID, &stats.TotalXP, &stats.StudyStreak, &stats.LastStudyDate,
+		&stats.ExerciseAnswers, &stats.ExerciseCorrect,
 	)
 
 	if err != nil {
@@ -371,7 +731,7 @@ func (r *userRepository) UpdateState(ctx context.Context, userID int64, state st
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", userID)
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
 	}
 
 	r.logger.Info("состояние пользователя обновлено",
@@ -392,7 +752,7 @@ func (r *userRepository) AddXP(ctx context.Context, userID int64, xp int) error
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", userID)
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
 	}
 
 	r.logger.Info("XP добавлен пользователю",
@@ -401,42 +761,36 @@ func (r *userRepository) AddXP(ctx context.Context, userID int64, xp int) error
 	return nil
 }
 
-// UpdateStudyActivity обновляет активность обучения пользователя
-func (r *userRepository) UpdateStudyActivity(ctx context.Context, userID int64) error {
+// uniqueViolationCode код ошибки PostgreSQL при нарушении уникальности
+const uniqueViolationCode = "23505"
+
+// DefaultStreakGraceDays количество пропущенных дней, которые не сбрасывают streak
+const DefaultStreakGraceDays = 1
+
+// UpdateStudyActivity обновляет активность обучения пользователя и возвращает актуальный streak
+func (r *userRepository) UpdateStudyActivity(ctx context.Context, userID int64) (int, error) {
 	// Получаем текущего пользователя
 	user, err := r.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("ошибка получения пользователя: %w", err)
+		return 0, fmt.Errorf("ошибка получения пользователя: %w", err)
 	}
 
 	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	lastStudyDay := time.Date(user.LastStudyDate.Year(), user.LastStudyDate.Month(), user.LastStudyDate.Day(), 0, 0, 0, 0, user.LastStudyDate.Location())
-
-	var newStreak int
-	if today.Equal(lastStudyDay) {
-		// Пользователь уже занимался сегодня, streak не меняется
-		newStreak = user.StudyStreak
-	} else if today.Sub(lastStudyDay) <= 24*time.Hour {
-		// Занимался вчера или сегодня (в пределах 24 часов)
-		newStreak = user.StudyStreak + 1
-	} else if today.Sub(lastStudyDay) <= 48*time.Hour {
-		// Пропустил 1 день - даем шанс, сохраняем текущий streak
-		newStreak = user.StudyStreak
-	} else {
-		// Пропустил больше 1 дня - сбрасываем streak
-		newStreak = 1
-	}
-
-	// Обновляем пользователя
+	newStreak, changed := studyActivityUpdate(user, now, r.resetLoc, r.streakGraceDays)
+	if !changed {
+		return newStreak, nil
+	}
+
+	// Обновляем пользователя. last_study_date хранит момент занятия с поясом,
+	// календарный день определяется потом в поясе пользователя.
 	query := `UPDATE users SET study_streak = $2, last_study_date = $3, last_seen = $4, updated_at = $5 WHERE id = $1`
 	result, err := r.db.Exec(ctx, query, userID, newStreak, now, now, now)
 	if err != nil {
-		return fmt.Errorf("ошибка обновления активности обучения: %w", err)
+		return 0, fmt.Errorf("ошибка обновления активности обучения: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("пользователь с ID %d не найден", userID)
+		return 0, fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
 	}
 
 	r.logger.Info("активность обучения обновлена",
@@ -444,7 +798,89 @@ func (r *userRepository) UpdateStudyActivity(ctx context.Context, userID int64)
 		zap.Int("old_streak", user.StudyStreak),
 		zap.Int("new_streak", newStreak))
 
-	return nil
+	return newStreak, nil
+}
+
+// studyActivityUpdate вычисляет streak после занятия в момент now и сообщает, нужно ли
+// его записывать. Повторные занятия в тот же день ничего не меняют, поэтому обработчики
+// могут отмечать занятие при каждом действии пользователя. Дни считаются в часовом
+// поясе пользователя, а если он не задан — в поясе дневного сброса resetLoc.
+func studyActivityUpdate(user *models.User, now time.Time, resetLoc *time.Location, graceDays int) (int, bool) {
+	loc := user.Location(resetLoc)
+	streak := nextStudyStreak(user.LastStudyDate, user.StudyStreak, now, loc, graceDays)
+	return streak, !sameDay(user.LastStudyDate, now, loc) || streak != user.StudyStreak
+}
+
+// nextStudyStreak вычисляет streak после занятия в момент now.
+// Занятие на следующий день увеличивает streak, пропуск до graceDays дней
+// сохраняет его, более длинный перерыв начинает streak заново. Дни считаются в поясе loc.
+func nextStudyStreak(lastStudyDate time.Time, streak int, now time.Time, loc *time.Location, graceDays int) int {
+	if lastStudyDate.IsZero() {
+		return 1
+	}
+
+	switch days := daysBetween(lastStudyDate, now, loc); {
+	case days <= 0:
+		// Уже занимался сегодня, streak не меняется
+		return max(streak, 1)
+	case days == 1:
+		// Занимался вчера
+		return streak + 1
+	case days <= 1+graceDays:
+		// Пропуск в пределах grace-окна — сохраняем текущий streak
+		return max(streak, 1)
+	default:
+		// Перерыв больше grace-окна — начинаем заново
+		return 1
+	}
+}
+
+// daysBetween возвращает количество календарных дней между датами в поясе loc.
+// Обе даты приводятся к одному поясу, иначе их дни могут начинаться в разное время.
+func daysBetween(from, to time.Time, loc *time.Location) int {
+	from, to = from.In(loc), to.In(loc)
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+// sameDay проверяет, что моменты приходятся на один календарный день в поясе loc
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	return !a.IsZero() && daysBetween(a, b, loc) == 0
+}
+
+// GetPlatformStats считает средние и децили XP, серии и выученных слов
+// по ученикам, которые заходили начиная с activeSince и набрали хоть немного XP
+func (r *userRepository) GetPlatformStats(ctx context.Context, activeSince time.Time) (*models.PlatformStats, error) {
+	query := `
+		WITH learned AS (
+			SELECT user_id, COUNT(*)::int AS words
+			FROM user_flashcards
+			WHERE is_learned = true
+			GROUP BY user_id
+		), active AS (
+			SELECT u.xp, COALESCE(u.study_streak, 0) AS streak, COALESCE(l.words, 0) AS words
+			FROM users u
+			LEFT JOIN learned l ON l.user_id = u.id
+			WHERE u.xp > 0 AND u.last_seen >= $1
+		)
+		SELECT COUNT(*)::int,
+		       COALESCE(AVG(xp), 0)::float8, COALESCE(AVG(streak), 0)::float8, COALESCE(AVG(words), 0)::float8,
+		       COALESCE(PERCENTILE_DISC(ARRAY[0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9]) WITHIN GROUP (ORDER BY xp), '{}'),
+		       COALESCE(PERCENTILE_DISC(ARRAY[0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9]) WITHIN GROUP (ORDER BY streak), '{}'),
+		       COALESCE(PERCENTILE_DISC(ARRAY[0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9]) WITHIN GROUP (ORDER BY words), '{}')
+		FROM active`
+
+	stats := &models.PlatformStats{ComputedAt: time.Now()}
+	err := r.db.QueryRow(ctx, query, activeSince).Scan(
+		&stats.Users, &stats.AvgXP, &stats.AvgStreak, &stats.AvgWords,
+		&stats.XPDeciles, &stats.StreakDeciles, &stats.WordsDeciles,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета средних показателей учеников: %w", err)
+	}
+
+	return stats, nil
 }
 
 // GetTopUsersByStreak получает топ пользователей по XP и study streak
@@ -483,6 +919,41 @@ func (r *userRepository) GetTopUsersByStreak(ctx context.Context, limit int) ([]
 	return users, nil
 }
 
+// GetTopUsersByStreakOnly получает топ пользователей по study streak, при равной серии — по XP
+func (r *userRepository) GetTopUsersByStreakOnly(ctx context.Context, limit int) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date
+		FROM users
+		ORDER BY study_streak DESC, xp DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения топ пользователей по серии: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя", zap.Error(err))
+			continue
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 // GetInactiveUsers получает пользователей, неактивных более указанного времени
 func (r *userRepository) GetInactiveUsers(ctx context.Context, inactiveDuration time.Duration) ([]*models.User, error) {
 	cutoffTime := time.Now().Add(-inactiveDuration)
@@ -526,6 +997,493 @@ func (r *userRepository) GetInactiveUsers(ctx context.Context, inactiveDuration
 	return users, nil
 }
 
+// GetStreakAtRiskUsers получает пользователей, которые в свои текущие сутки еще не занимались,
+// но сохранят серию, если позанимаются сегодня. Сутки считаются в поясе пользователя,
+// а если он не задан — в resetLoc. Пропускает отключивших и отложивших предупреждения,
+// а также тех, кому предупреждение в эти сутки уже отправлено.
+func (r *userRepository) GetStreakAtRiskUsers(ctx context.Context, now time.Time) ([]*models.User, error) {
+	// Запрос отбирает с запасом: сутки пользователей в разных поясах начинаются
+	// в разное время, точную проверку делает streakAtRisk
+	oldestAlive := now.AddDate(0, 0, -(2 + r.streakGraceDays))
+
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date, timezone, streak_warning_sent_at
+		FROM users
+		WHERE study_streak > 0
+		  AND last_study_date < $1
+		  AND last_study_date >= $2
+		  AND streak_warnings_enabled
+		  AND (streak_warnings_snoozed_until IS NULL OR streak_warnings_snoozed_until <= NOW())
+		ORDER BY study_streak DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, now, oldestAlive)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей с серией под угрозой: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+			&user.Timezone, &user.StreakWarningSentAt,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя с серией под угрозой", zap.Error(err))
+			continue
+		}
+		if streakAtRisk(user, now, r.resetLoc, r.streakGraceDays) {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// streakAtRisk проверяет, что сегодня (в поясе пользователя) занятий еще не было, серия пока
+// жива, и предупреждение в эти сутки не отправлялось
+func streakAtRisk(user *models.User, now time.Time, resetLoc *time.Location, graceDays int) bool {
+	loc := user.Location(resetLoc)
+	days := daysBetween(user.LastStudyDate, now, loc)
+	if days < 1 || days > 1+graceDays {
+		return false
+	}
+	if user.StreakWarningSentAt == nil {
+		return true
+	}
+	y, m, d := now.In(loc).Date()
+	return user.StreakWarningSentAt.Before(time.Date(y, m, d, 0, 0, 0, 0, loc))
+}
+
+// MarkStreakWarningSent отмечает, что предупреждение о серии отправлено.
+// Возвращает false, если с начала суток dayStart предупреждение уже отмечено.
+func (r *userRepository) MarkStreakWarningSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET streak_warning_sent_at = NOW()
+		WHERE id = $1 AND (streak_warning_sent_at IS NULL OR streak_warning_sent_at < $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, dayStart)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки предупреждения о серии: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// SetStreakWarnings включает или отключает предупреждения о серии и откладывает их до snoozedUntil
+func (r *userRepository) SetStreakWarnings(ctx context.Context, userID int64, enabled bool, snoozedUntil *time.Time) error {
+	query := `
+		UPDATE users
+		SET streak_warnings_enabled = $2, streak_warnings_snoozed_until = $3, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, enabled, snoozedUntil)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настроек предупреждений о серии: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// GetDailyReminderUsers получает пользователей с включенными напоминаниями, которые
+// не занимались с studiedBefore и не получали напоминание после этой границы,
+// но заходили в бота начиная с activeSince. Начало суток в поясе пользователя
+// проверяет вызывающий код.
+func (r *userRepository) GetDailyReminderUsers(ctx context.Context, studiedBefore, activeSince time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date, timezone, daily_reminder_sent_at
+		FROM users
+		WHERE reminders_enabled
+		  AND last_study_date < $1
+		  AND last_seen >= $2
+		  AND (daily_reminder_sent_at IS NULL OR daily_reminder_sent_at < $1)
+		ORDER BY last_seen DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, studiedBefore, activeSince)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей для ежедневного напоминания: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+			&user.Timezone, &user.DailyReminderSentAt,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя для ежедневного напоминания", zap.Error(err))
+			continue
+		}
+		user.RemindersEnabled = true
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// MarkDailyReminderSent отмечает, что ежедневное напоминание отправлено.
+// Возвращает false, если с начала суток dayStart напоминание уже отмечено.
+func (r *userRepository) MarkDailyReminderSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET daily_reminder_sent_at = NOW()
+		WHERE id = $1 AND (daily_reminder_sent_at IS NULL OR daily_reminder_sent_at < $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, dayStart)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки ежедневного напоминания: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// GetWordOfDayUsers получает пользователей с включенными напоминаниями, которые заходили
+// в бота начиная с activeSince и последний раз получили слово дня раньше sentBefore
+func (r *userRepository) GetWordOfDayUsers(ctx context.Context, sentBefore, activeSince time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date, timezone, word_of_day_sent_at
+		FROM users
+		WHERE reminders_enabled
+		  AND last_seen >= $2
+		  AND (word_of_day_sent_at IS NULL OR word_of_day_sent_at < $1)
+		ORDER BY last_seen DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, sentBefore, activeSince)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей для слова дня: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate, &user.Timezone, &user.WordOfDaySentAt,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя для слова дня", zap.Error(err))
+			continue
+		}
+		user.RemindersEnabled = true
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// MarkWordOfDaySent отмечает, что слово дня отправлено.
+// Возвращает false, если с начала суток dayStart слово уже отмечено.
+func (r *userRepository) MarkWordOfDaySent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET word_of_day_sent_at = NOW()
+		WHERE id = $1 AND (word_of_day_sent_at IS NULL OR word_of_day_sent_at < $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, dayStart)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки слова дня: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// SetRemindersEnabled включает или отключает ежедневные напоминания о занятиях
+func (r *userRepository) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	query := `
+		UPDATE users
+		SET reminders_enabled = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, enabled)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настройки ежедневных напоминаний: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// SetFlashcardCategory сохраняет последнюю выбранную категорию карточек
+func (r *userRepository) SetFlashcardCategory(ctx context.Context, userID int64, category string) error {
+	query := `
+		UPDATE users
+		SET flashcard_category = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, category)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения категории карточек: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// SetTimezone сохраняет часовой пояс пользователя; пустая строка — пояс DAILY_RESET_TZ
+func (r *userRepository) SetTimezone(ctx context.Context, userID int64, timezone string) error {
+	query := `
+		UPDATE users
+		SET timezone = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, timezone)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения часового пояса: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// RecordExerciseAnswer учитывает ответ на упражнение во всей статистике и в окне адаптации.
+// Возвращает число ответов и верных ответов в окне с учетом этого ответа; когда окно
+// набирает window ответов, счетчики окна в базе обнуляются для следующего.
+func (r *userRepository) RecordExerciseAnswer(ctx context.Context, userID int64, correct bool, window int) (int, int, error) {
+	query := `
+		WITH prev AS (
+			SELECT exercise_window_answers + 1 AS answers, exercise_window_correct + $2 AS correct
+			FROM users WHERE id = $1
+			FOR UPDATE
+		)
+		UPDATE users
+		SET exercise_answers = exercise_answers + 1,
+		    exercise_correct = exercise_correct + $2,
+		    exercise_window_answers = CASE WHEN prev.answers >= $3 THEN 0 ELSE prev.answers END,
+		    exercise_window_correct = CASE WHEN prev.answers >= $3 THEN 0 ELSE prev.correct END
+		FROM prev
+		WHERE users.id = $1
+		RETURNING prev.answers, prev.correct`
+
+	delta := 0
+	if correct {
+		delta = 1
+	}
+
+	var answers, correctCount int
+	err := r.db.QueryRow(ctx, query, userID, delta, window).Scan(&answers, &correctCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("ошибка сохранения ответа на упражнение: %w", err)
+	}
+
+	return answers, correctCount, nil
+}
+
+// SetWeeklyWordTarget сохраняет недельную цель по выученным словам (0 — цель снята)
+func (r *userRepository) SetWeeklyWordTarget(ctx context.Context, userID int64, target int) error {
+	query := `
+		UPDATE users
+		SET weekly_word_target = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, userID, target)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения недельной цели: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// MarkWeeklyTargetCompleted отмечает выполнение недельной цели.
+// Возвращает false, если с начала недели weekStart цель уже отмечена выполненной.
+func (r *userRepository) MarkWeeklyTargetCompleted(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET weekly_target_completed_at = NOW()
+		WHERE id = $1 AND (weekly_target_completed_at IS NULL OR weekly_target_completed_at < $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, weekStart)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки выполнения недельной цели: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// GetWeeklyTargetReminderUsers получает пользователей с недельной целью, которые с начала
+// недели weekStart еще не выполнили ее и не получили напоминание
+func (r *userRepository) GetWeeklyTargetReminderUsers(ctx context.Context, weekStart time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date, weekly_word_target
+		FROM users
+		WHERE weekly_word_target > 0
+		  AND (weekly_target_completed_at IS NULL OR weekly_target_completed_at < $1)
+		  AND (weekly_target_reminded_at IS NULL OR weekly_target_reminded_at < $1)
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей с недельной целью: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+			&user.WeeklyWordTarget,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя с недельной целью", zap.Error(err))
+			continue
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// MarkWeeklyTargetReminded отмечает напоминание о недельной цели.
+// Возвращает false, если с начала недели weekStart напоминание уже отмечено.
+func (r *userRepository) MarkWeeklyTargetReminded(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET weekly_target_reminded_at = NOW()
+		WHERE id = $1 AND (weekly_target_reminded_at IS NULL OR weekly_target_reminded_at < $2)`
+
+	result, err := r.db.Exec(ctx, query, userID, weekStart)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки напоминания о недельной цели: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// GetPremiumExpiringUsers получает премиум-пользователей, у которых подписка заканчивается
+// в промежутке (from, until] и о продлении которой еще не напоминали
+func (r *userRepository) GetPremiumExpiringUsers(ctx context.Context, from, until time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date
+		FROM users
+		WHERE is_premium = TRUE
+		  AND premium_expires_at > $1 AND premium_expires_at <= $2
+		  AND premium_reminded_for IS DISTINCT FROM premium_expires_at
+		ORDER BY premium_expires_at
+	`
+
+	return r.queryPremiumUsers(ctx, query, from, until)
+}
+
+// MarkPremiumExpiryReminded отмечает напоминание о продлении для срока expiresAt.
+// Возвращает false, если срок изменился или напоминание для него уже отмечено.
+func (r *userRepository) MarkPremiumExpiryReminded(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET premium_reminded_for = premium_expires_at
+		WHERE id = $1 AND premium_expires_at = $2 AND premium_reminded_for IS DISTINCT FROM premium_expires_at`
+
+	result, err := r.db.Exec(ctx, query, userID, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки напоминания о продлении премиума: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// GetPremiumExpiredUsers получает пользователей, чей премиум закончился в промежутке (since, now],
+// но еще не отключен и об окончании которого еще не сообщали
+func (r *userRepository) GetPremiumExpiredUsers(ctx context.Context, since, now time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name, level, xp, study_streak, last_study_date, current_state, last_seen, created_at, updated_at,
+		       is_premium, premium_expires_at, messages_count, max_messages, messages_reset_date, last_test_date
+		FROM users
+		WHERE is_premium = TRUE
+		  AND premium_expires_at > $1 AND premium_expires_at <= $2
+		  AND premium_expired_notified_for IS DISTINCT FROM premium_expires_at
+		ORDER BY premium_expires_at
+	`
+
+	return r.queryPremiumUsers(ctx, query, since, now)
+}
+
+// MarkPremiumExpiredNotified отмечает уведомление об окончании премиума со сроком expiresAt.
+// Возвращает false, если срок изменился или уведомление для него уже отмечено.
+func (r *userRepository) MarkPremiumExpiredNotified(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET premium_expired_notified_for = premium_expires_at
+		WHERE id = $1 AND premium_expires_at = $2 AND premium_expired_notified_for IS DISTINCT FROM premium_expires_at`
+
+	result, err := r.db.Exec(ctx, query, userID, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки уведомления об окончании премиума: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// queryPremiumUsers выполняет запрос пользователей для напоминаний о премиуме
+func (r *userRepository) queryPremiumUsers(ctx context.Context, query string, args ...any) ([]*models.User, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователей с заканчивающимся премиумом: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName,
+			&user.Level, &user.XP, &user.StudyStreak, &user.LastStudyDate, &user.CurrentState,
+			&user.LastSeen, &user.CreatedAt, &user.UpdatedAt,
+			&user.IsPremium, &user.PremiumExpiresAt, &user.MessagesCount, &user.MaxMessages, &user.MessagesResetDate, &user.LastTestDate,
+		)
+		if err != nil {
+			r.logger.Error("ошибка сканирования пользователя с премиумом", zap.Error(err))
+			continue
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 // GetAll получает всех пользователей
 func (r *userRepository) GetAll(ctx context.Context) ([]*models.User, error) {
 	query := `
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,11 +18,12 @@ type ReferralRepository interface {
 	CreateReferral(ctx context.Context, referral *models.Referral) error
 	GetReferralByReferredID(ctx context.Context, referredID int64) (*models.Referral, error)
 	GetReferralsByReferrerID(ctx context.Context, referrerID int64) ([]*models.Referral, error)
-	UpdateReferralStatus(ctx context.Context, referralID int64, status string, completedAt *time.Time) error
+	UpdateReferralStatus(ctx context.Context, referralID int64, status string, completedAt *time.Time) (int64, error)
 	GetReferralStats(ctx context.Context, userID int64) (*models.ReferralStats, error)
 	GetUserByReferralCode(ctx context.Context, referralCode string) (*models.User, error)
 	GenerateReferralCode(ctx context.Context) (string, error)
 	CountCompletedReferrals(ctx context.Context, userID int64) (int, error)
+	GetReferredEngagement(ctx context.Context, referredID int64) (messages int, activeDays int, err error)
 }
 
 // PostgresReferralRepository реализует ReferralRepository для PostgreSQL
@@ -78,8 +80,8 @@ func (r *PostgresReferralRepository) GetReferralByReferredID(ctx context.Context
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("реферал не найден")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReferralNotFound
 		}
 		return nil, fmt.Errorf("ошибка получения реферала: %w", err)
 	}
@@ -121,19 +123,24 @@ func (r *PostgresReferralRepository) GetReferralsByReferrerID(ctx context.Contex
 	return referrals, nil
 }
 
-// UpdateReferralStatus обновляет статус реферала
-func (r *PostgresReferralRepository) UpdateReferralStatus(ctx context.Context, referralID int64, status string, completedAt *time.Time) error {
+// UpdateReferralStatus обновляет статус реферала и возвращает ID пригласившего
+func (r *PostgresReferralRepository) UpdateReferralStatus(ctx context.Context, referralID int64, status string, completedAt *time.Time) (int64, error) {
 	query := `
 		UPDATE referrals 
 		SET status = $1, completed_at = $2
-		WHERE id = $3`
+		WHERE id = $3
+		RETURNING referrer_id`
 
-	_, err := r.db.Exec(ctx, query, status, completedAt, referralID)
+	var referrerID int64
+	err := r.db.QueryRow(ctx, query, status, completedAt, referralID).Scan(&referrerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("реферал %d не найден", referralID)
+	}
 	if err != nil {
-		return fmt.Errorf("ошибка обновления статуса реферала: %w", err)
+		return 0, fmt.Errorf("ошибка обновления статуса реферала: %w", err)
 	}
 
-	return nil
+	return referrerID, nil
 }
 
 // GetReferralStats получает статистику рефералов пользователя
@@ -204,8 +211,8 @@ func (r *PostgresReferralRepository) GetUserByReferralCode(ctx context.Context,
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("пользователь с таким реферальным кодом не найден")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: реферальный код %s", ErrUserNotFound, referralCode)
 		}
 		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
 	}
@@ -241,3 +248,19 @@ func (r *PostgresReferralRepository) CountCompletedReferrals(ctx context.Context
 
 	return count, nil
 }
+
+// GetReferredEngagement возвращает активность приглашенного пользователя:
+// число его сообщений и число разных дней, в которые он писал
+func (r *PostgresReferralRepository) GetReferredEngagement(ctx context.Context, referredID int64) (int, int, error) {
+	query := `
+		SELECT COUNT(*), COUNT(DISTINCT created_at::date)
+		FROM user_messages
+		WHERE user_id = $1 AND role = 'user'`
+
+	var messages, activeDays int
+	if err := r.db.QueryRow(ctx, query, referredID).Scan(&messages, &activeDays); err != nil {
+		return 0, 0, fmt.Errorf("ошибка получения активности приглашенного: %w", err)
+	}
+
+	return messages, activeDays, nil
+}
@@ -8,39 +8,79 @@ import (
 )
 
 func TestUpdateStudyActivity(t *testing.T) {
-	// Создаем тестовую базу данных (в реальном тесте нужно использовать testcontainers)
-	// Здесь мы просто проверяем логику без реальной БД
+	// Проверяем логику расчета streak, которую использует UpdateStudyActivity
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name           string
 		lastStudyDate  time.Time
 		currentStreak  int
+		graceDays      int
 		expectedStreak int
 	}{
 		{
 			name:           "первый день обучения",
-			lastStudyDate:  time.Now().AddDate(0, 0, -2), // 2 дня назад
+			lastStudyDate:  now.AddDate(0, 0, -2), // 2 дня назад
 			currentStreak:  0,
+			graceDays:      DefaultStreakGraceDays,
+			expectedStreak: 1,
+		},
+		{
+			name:           "нет даты занятий",
+			lastStudyDate:  time.Time{},
+			currentStreak:  0,
+			graceDays:      DefaultStreakGraceDays,
 			expectedStreak: 1,
 		},
 		{
 			name:           "второй день подряд",
-			lastStudyDate:  time.Now().AddDate(0, 0, -1), // вчера
+			lastStudyDate:  now.AddDate(0, 0, -1), // вчера
 			currentStreak:  1,
+			graceDays:      DefaultStreakGraceDays,
 			expectedStreak: 2,
 		},
+		{
+			name:           "вчера поздно вечером",
+			lastStudyDate:  time.Date(2026, 3, 9, 23, 59, 0, 0, time.UTC),
+			currentStreak:  4,
+			graceDays:      DefaultStreakGraceDays,
+			expectedStreak: 5,
+		},
 		{
 			name:           "уже занимался сегодня",
-			lastStudyDate:  time.Now(), // сегодня
+			lastStudyDate:  now.Add(-time.Hour), // сегодня
 			currentStreak:  5,
+			graceDays:      DefaultStreakGraceDays,
 			expectedStreak: 5, // не меняется
 		},
 		{
-			name:           "пропустил день",
-			lastStudyDate:  time.Now().AddDate(0, 0, -3), // 3 дня назад
+			name:           "пропустил один день в пределах grace",
+			lastStudyDate:  now.AddDate(0, 0, -2),
+			currentStreak:  7,
+			graceDays:      DefaultStreakGraceDays,
+			expectedStreak: 7, // сохраняется
+		},
+		{
+			name:           "пропустил один день без grace",
+			lastStudyDate:  now.AddDate(0, 0, -2),
+			currentStreak:  7,
+			graceDays:      0,
+			expectedStreak: 1,
+		},
+		{
+			name:           "пропустил два дня",
+			lastStudyDate:  now.AddDate(0, 0, -3), // 3 дня назад
 			currentStreak:  10,
+			graceDays:      DefaultStreakGraceDays,
 			expectedStreak: 1, // сбрасывается
 		},
+		{
+			name:           "пропустил несколько дней в пределах широкого grace",
+			lastStudyDate:  now.AddDate(0, 0, -3),
+			currentStreak:  10,
+			graceDays:      2,
+			expectedStreak: 10,
+		},
 	}
 
 	for _, tt := range tests {
@@ -56,22 +96,7 @@ func TestUpdateStudyActivity(t *testing.T) {
 				LastStudyDate: tt.lastStudyDate,
 			}
 
-			// Проверяем логику расчета streak
-			now := time.Now()
-			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			lastStudyDay := time.Date(user.LastStudyDate.Year(), user.LastStudyDate.Month(), user.LastStudyDate.Day(), 0, 0, 0, 0, user.LastStudyDate.Location())
-
-			var newStreak int
-			if today.Equal(lastStudyDay) {
-				newStreak = user.StudyStreak
-			} else if today.Sub(lastStudyDay) == 24*time.Hour {
-				newStreak = user.StudyStreak + 1
-			} else if today.Sub(lastStudyDay) > 24*time.Hour {
-				newStreak = 1
-			} else {
-				newStreak = 1
-			}
-
+			newStreak := nextStudyStreak(user.LastStudyDate, user.StudyStreak, now, time.UTC, tt.graceDays)
 			if newStreak != tt.expectedStreak {
 				t.Errorf("ожидался streak %d, получен %d", tt.expectedStreak, newStreak)
 			}
@@ -79,6 +104,126 @@ func TestUpdateStudyActivity(t *testing.T) {
 	}
 }
 
+func TestStudyActivityUpdateSequence(t *testing.T) {
+	// Обработчики отмечают занятие при каждом действии, поэтому повторы в тот же день
+	// не должны ни менять streak, ни вызывать запись
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC) }
+	user := &models.User{ID: 1, Timezone: "UTC"}
+
+	steps := []struct {
+		name      string
+		now       time.Time
+		streak    int
+		wantWrite bool
+	}{
+		{"первое занятие", day(1, 9), 1, true},
+		{"тот же день", day(1, 21), 1, false},
+		{"следующий день", day(2, 8), 2, true},
+		{"повтор на следующий день", day(2, 23), 2, false},
+		{"пропуск одного дня", day(4, 10), 2, true},
+		{"пропуск двух дней", day(7, 10), 1, true},
+	}
+
+	for _, step := range steps {
+		streak, write := studyActivityUpdate(user, step.now, time.UTC, DefaultStreakGraceDays)
+		if streak != step.streak || write != step.wantWrite {
+			t.Fatalf("%s: ожидались streak %d и запись %v, получено %d и %v",
+				step.name, step.streak, step.wantWrite, streak, write)
+		}
+		if write {
+			user.StudyStreak, user.LastStudyDate = streak, step.now
+		}
+	}
+}
+
+func TestStudyActivityUpdateUsesUserTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("Asia/Tokyo"); err != nil {
+		t.Skipf("часовой пояс недоступен: %v", err)
+	}
+
+	// 20:00 UTC 1 марта и 10:00 UTC 2 марта — разные дни по UTC, но один день в Токио
+	evening := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	nextMorning := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tokyo := &models.User{ID: 1, Timezone: "Asia/Tokyo", StudyStreak: 3, LastStudyDate: evening}
+	if streak, write := studyActivityUpdate(tokyo, nextMorning, time.UTC, DefaultStreakGraceDays); streak != 3 || write {
+		t.Errorf("в Токио это тот же день: ожидались streak 3 без записи, получено %d и %v", streak, write)
+	}
+
+	utc := &models.User{ID: 2, Timezone: "UTC", StudyStreak: 3, LastStudyDate: evening}
+	if streak, write := studyActivityUpdate(utc, nextMorning, time.UTC, DefaultStreakGraceDays); streak != 4 || !write {
+		t.Errorf("по UTC это следующий день: ожидались streak 4 с записью, получено %d и %v", streak, write)
+	}
+}
+
+func TestStudyActivityUpdateFallsBackToResetLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	// 20:00 и 22:00 UTC — один день по UTC, но в Москве второе занятие уже на следующий день
+	evening := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	lateEvening := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	user := &models.User{ID: 1, StudyStreak: 3, LastStudyDate: evening}
+
+	if streak, write := studyActivityUpdate(user, lateEvening, moscow, DefaultStreakGraceDays); streak != 4 || !write {
+		t.Errorf("без /timezone дни считаются в поясе сброса: ожидались streak 4 с записью, получено %d и %v", streak, write)
+	}
+	if streak, write := studyActivityUpdate(user, lateEvening, time.UTC, DefaultStreakGraceDays); streak != 3 || write {
+		t.Errorf("по UTC это тот же день: ожидались streak 3 без записи, получено %d и %v", streak, write)
+	}
+}
+
+func TestStreakAtRiskUsesUserTimezone(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// 22:00 UTC 2 марта: в Москве уже 3 марта, по UTC еще 2 марта
+	now := time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC)
+	lastStudy := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	user := &models.User{StudyStreak: 5, LastStudyDate: lastStudy, Timezone: "Europe/Moscow"}
+	if !streakAtRisk(user, now, time.UTC, DefaultStreakGraceDays) {
+		t.Error("в Москве начались новые сутки без занятий: серия под угрозой")
+	}
+	user.Timezone = ""
+	if streakAtRisk(user, now, time.UTC, DefaultStreakGraceDays) {
+		t.Error("в поясе сброса UTC сегодня уже было занятие")
+	}
+	if !streakAtRisk(user, now, moscow, DefaultStreakGraceDays) {
+		t.Error("без своего пояса сутки считаются в поясе сброса")
+	}
+
+	// Предупреждение, отправленное в прошлые московские сутки, не мешает новому
+	user.Timezone = "Europe/Moscow"
+	sentAt := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	user.StreakWarningSentAt = &sentAt
+	if !streakAtRisk(user, now, time.UTC, DefaultStreakGraceDays) {
+		t.Error("предупреждение отправлено в прошлые сутки пользователя")
+	}
+	sentAt = time.Date(2026, 3, 2, 21, 30, 0, 0, time.UTC)
+	if streakAtRisk(user, now, time.UTC, DefaultStreakGraceDays) {
+		t.Error("предупреждение уже отправлено в текущие сутки пользователя")
+	}
+
+	// Серия, прерванная дольше grace-окна, не под угрозой
+	user.StreakWarningSentAt = nil
+	user.LastStudyDate = now.AddDate(0, 0, -5)
+	if streakAtRisk(user, now, time.UTC, DefaultStreakGraceDays) {
+		t.Error("серия уже прервана")
+	}
+}
+
+func TestDaysBetweenConvertsBothDates(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	// 22:00 UTC 1 марта — это уже 2 марта по Москве, как и 08:00 по Москве
+	lastStudy := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, moscow)
+	if days := daysBetween(lastStudy, now, moscow); days != 0 {
+		t.Errorf("ожидался тот же день, получено %d", days)
+	}
+	if days := daysBetween(lastStudy, now, time.UTC); days != 1 {
+		t.Errorf("по UTC ожидался следующий день, получено %d", days)
+	}
+}
+
 func TestGetTopUsersByStreak(t *testing.T) {
 	// Тест структуры запроса
 	query := `
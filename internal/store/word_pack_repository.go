@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lingua-ai/pkg/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// WordPackRepository определяет интерфейс для работы с тематическими наборами слов
+type WordPackRepository interface {
+	GetPackByID(ctx context.Context, packID int64) (*models.WordPack, error)
+	GetAvailablePacks(ctx context.Context, userID int64, level string) ([]*models.WordPack, error)
+	AcceptPack(ctx context.Context, userID, packID int64) (int, error)
+	GetUserPacksProgress(ctx context.Context, userID int64) ([]*models.WordPackProgress, error)
+}
+
+// PostgresWordPackRepository реализует WordPackRepository для PostgreSQL
+type PostgresWordPackRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewWordPackRepository создает новый репозиторий наборов слов
+func NewWordPackRepository(db *pgxpool.Pool, logger *zap.Logger) WordPackRepository {
+	return &PostgresWordPackRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetPackByID получает набор по ID
+func (r *PostgresWordPackRepository) GetPackByID(ctx context.Context, packID int64) (*models.WordPack, error) {
+	query := `
+		SELECT wp.id, wp.name, COALESCE(wp.description, ''), wp.level, wp.category, wp.created_at,
+		       (SELECT COUNT(*) FROM word_pack_items wpi WHERE wpi.pack_id = wp.id)
+		FROM word_packs wp
+		WHERE wp.id = $1`
+
+	pack := &models.WordPack{}
+	err := r.db.QueryRow(ctx, query, packID).Scan(
+		&pack.ID, &pack.Name, &pack.Description, &pack.Level, &pack.Category, &pack.CreatedAt, &pack.WordsCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: ID %d", ErrWordPackNotFound, packID)
+		}
+		return nil, fmt.Errorf("ошибка получения набора слов: %w", err)
+	}
+
+	return pack, nil
+}
+
+// GetAvailablePacks получает активные наборы уровня, которые пользователь еще не брал
+func (r *PostgresWordPackRepository) GetAvailablePacks(ctx context.Context, userID int64, level string) ([]*models.WordPack, error) {
+	query := `
+		SELECT wp.id, wp.name, COALESCE(wp.description, ''), wp.level, wp.category, wp.created_at,
+		       (SELECT COUNT(*) FROM word_pack_items wpi WHERE wpi.pack_id = wp.id) AS words_count
+		FROM word_packs wp
+		WHERE wp.is_active = true
+		  AND wp.level = $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_word_packs uwp
+		      WHERE uwp.pack_id = wp.id AND uwp.user_id = $1
+		  )
+		ORDER BY wp.id`
+
+	rows, err := r.db.Query(ctx, query, userID, level)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения наборов слов: %w", err)
+	}
+	defer rows.Close()
+
+	var packs []*models.WordPack
+	for rows.Next() {
+		pack := &models.WordPack{}
+		if err := rows.Scan(
+			&pack.ID, &pack.Name, &pack.Description, &pack.Level, &pack.Category, &pack.CreatedAt, &pack.WordsCount,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования набора слов: %w", err)
+		}
+		if pack.WordsCount > 0 {
+			packs = append(packs, pack)
+		}
+	}
+
+	return packs, rows.Err()
+}
+
+// AcceptPack отмечает набор как принятый и добавляет его слова в очередь повторения.
+// Возвращает количество новых карточек, добавленных пользователю.
+func (r *PostgresWordPackRepository) AcceptPack(ctx context.Context, userID, packID int64) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO user_word_packs (user_id, pack_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, pack_id) DO NOTHING`, userID, packID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения набора пользователя: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, ErrWordPackAlreadyAdded
+	}
+
+	result, err = tx.Exec(ctx, `
+		INSERT INTO user_flashcards (user_id, flashcard_id, next_review_at, source)
+		SELECT $1, wpi.flashcard_id, NOW(), 'pack'
+		FROM word_pack_items wpi
+		WHERE wpi.pack_id = $2
+		ON CONFLICT (user_id, flashcard_id) DO NOTHING`, userID, packID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка добавления карточек набора: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	added := int(result.RowsAffected())
+	r.logger.Info("набор слов принят",
+		zap.Int64("user_id", userID),
+		zap.Int64("pack_id", packID),
+		zap.Int("cards_added", added))
+
+	return added, nil
+}
+
+// GetUserPacksProgress получает прогресс пользователя по принятым наборам
+func (r *PostgresWordPackRepository) GetUserPacksProgress(ctx context.Context, userID int64) ([]*models.WordPackProgress, error) {
+	query := `
+		SELECT wp.id, wp.name, COALESCE(wp.description, ''), wp.level, wp.category, wp.created_at,
+		       COUNT(wpi.flashcard_id) AS words_count,
+		       COUNT(uf.id) FILTER (WHERE uf.is_learned) AS learned_count,
+		       uwp.accepted_at
+		FROM user_word_packs uwp
+		JOIN word_packs wp ON wp.id = uwp.pack_id
+		LEFT JOIN word_pack_items wpi ON wpi.pack_id = wp.id
+		LEFT JOIN user_flashcards uf ON uf.flashcard_id = wpi.flashcard_id AND uf.user_id = uwp.user_id
+		WHERE uwp.user_id = $1
+		GROUP BY wp.id, uwp.accepted_at
+		ORDER BY uwp.accepted_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прогресса по наборам: %w", err)
+	}
+	defer rows.Close()
+
+	var progress []*models.WordPackProgress
+	for rows.Next() {
+		p := &models.WordPackProgress{}
+		if err := rows.Scan(
+			&p.Pack.ID, &p.Pack.Name, &p.Pack.Description, &p.Pack.Level, &p.Pack.Category, &p.Pack.CreatedAt,
+			&p.Pack.WordsCount, &p.LearnedCount, &p.AcceptedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования прогресса набора: %w", err)
+		}
+		progress = append(progress, p)
+	}
+
+	return progress, rows.Err()
+}
@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ChainMetrics записывает, какой сервис цепочки озвучил текст
+type ChainMetrics interface {
+	RecordTTSRequest(service string, success bool)
+}
+
+// NamedService TTS сервис цепочки с именем для логов и метрик
+type NamedService struct {
+	Name    string
+	Service TTSService
+}
+
+// ChainService пробует TTS сервисы по порядку, пока один из них не озвучит текст
+type ChainService struct {
+	logger   *zap.Logger
+	services []NamedService
+	metrics  ChainMetrics
+}
+
+// NewChainService создает цепочку TTS сервисов: первый — основной, остальные — запасные
+func NewChainService(logger *zap.Logger, services ...NamedService) *ChainService {
+	return &ChainService{
+		logger:   logger,
+		services: services,
+	}
+}
+
+// SetMetrics задает получателя метрик цепочки
+func (c *ChainService) SetMetrics(metrics ChainMetrics) {
+	c.metrics = metrics
+}
+
+// SynthesizeText преобразует текст в аудио первым доступным сервисом цепочки
+func (c *ChainService) SynthesizeText(ctx context.Context, text string) ([]byte, error) {
+	return c.SynthesizeTextWithSpeed(ctx, text, NormalSpeed)
+}
+
+// SynthesizeTextWithSpeed преобразует текст в аудио с заданной скоростью.
+// Если сервис вернул ошибку, текст передается следующему; ошибка возвращается,
+// только когда не справился ни один.
+func (c *ChainService) SynthesizeTextWithSpeed(ctx context.Context, text string, speed float64) ([]byte, error) {
+	if len(c.services) == 0 {
+		return nil, errors.New("не задано ни одного TTS сервиса")
+	}
+
+	var errs []error
+	for i, s := range c.services {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		audio, err := s.Service.SynthesizeTextWithSpeed(ctx, text, speed)
+		c.record(s.Name, err == nil)
+		if err == nil {
+			if i > 0 {
+				c.logger.Info("🎵 аудио сгенерировано запасным TTS сервисом", zap.String("service", s.Name))
+			}
+			return audio, nil
+		}
+
+		c.logger.Warn("🎵 TTS сервис не смог озвучить текст",
+			zap.String("service", s.Name),
+			zap.Bool("has_fallback", i < len(c.services)-1),
+			zap.Error(err))
+		errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+	}
+
+	return nil, fmt.Errorf("ни один TTS сервис не озвучил текст: %w", errors.Join(errs...))
+}
+
+// record записывает результат обращения к сервису цепочки
+func (c *ChainService) record(service string, success bool) {
+	if c.metrics != nil {
+		c.metrics.RecordTTSRequest(service, success)
+	}
+}
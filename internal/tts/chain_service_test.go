@@ -0,0 +1,70 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// stubService TTS сервис с заранее заданным результатом
+type stubService struct {
+	audio []byte
+	err   error
+	calls int
+}
+
+func (s *stubService) SynthesizeText(ctx context.Context, text string) ([]byte, error) {
+	return s.SynthesizeTextWithSpeed(ctx, text, NormalSpeed)
+}
+
+func (s *stubService) SynthesizeTextWithSpeed(ctx context.Context, text string, speed float64) ([]byte, error) {
+	s.calls++
+	return s.audio, s.err
+}
+
+// recordedMetrics запоминает результаты обращений к сервисам цепочки
+type recordedMetrics map[string]bool
+
+func (m recordedMetrics) RecordTTSRequest(service string, success bool) {
+	m[service] = success
+}
+
+func TestChainServiceFallsBackWhenPrimaryFails(t *testing.T) {
+	primary := &stubService{err: errors.New("connection refused")}
+	fallback := &stubService{audio: []byte("audio")}
+	metrics := recordedMetrics{}
+
+	chain := NewChainService(zap.NewNop(),
+		NamedService{Name: "piper", Service: primary},
+		NamedService{Name: "fallback_1", Service: fallback},
+	)
+	chain.SetMetrics(metrics)
+
+	audio, err := chain.SynthesizeTextWithSpeed(context.Background(), "Hello", SlowSpeed)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if string(audio) != "audio" || primary.calls != 1 || fallback.calls != 1 {
+		t.Errorf("ожидалось аудио от запасного сервиса, получено %q (вызовов %d/%d)", audio, primary.calls, fallback.calls)
+	}
+	if success, ok := metrics["piper"]; !ok || success {
+		t.Error("ожидалась метрика неудачного обращения к основному сервису")
+	}
+	if !metrics["fallback_1"] {
+		t.Error("ожидалась метрика успешного обращения к запасному сервису")
+	}
+}
+
+func TestChainServiceReturnsErrorWhenAllFail(t *testing.T) {
+	cause := errors.New("unavailable")
+	chain := NewChainService(zap.NewNop(),
+		NamedService{Name: "piper", Service: &stubService{err: cause}},
+		NamedService{Name: "fallback_1", Service: &stubService{err: cause}},
+	)
+
+	if _, err := chain.SynthesizeText(context.Background(), "Hello"); !errors.Is(err, cause) {
+		t.Errorf("ожидалась ошибка с причиной %v, получено %v", cause, err)
+	}
+}
@@ -2,8 +2,18 @@ package tts
 
 import "context"
 
+const (
+	// NormalSpeed — обычная скорость речи
+	NormalSpeed = 1.0
+	// SlowSpeed — замедленная скорость речи для начинающих
+	SlowSpeed = 0.7
+)
+
 // TTSService представляет интерфейс для Text-to-Speech сервиса
 type TTSService interface {
 	// SynthesizeText преобразует текст в аудио
 	SynthesizeText(ctx context.Context, text string) ([]byte, error)
+	// SynthesizeTextWithSpeed преобразует текст в аудио с заданной скоростью речи
+	// (1.0 — обычная, меньше 1.0 — медленнее)
+	SynthesizeTextWithSpeed(ctx context.Context, text string, speed float64) ([]byte, error)
 }
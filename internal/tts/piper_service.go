@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,8 +15,18 @@ import (
 
 // SynthesizeRequest представляет запрос к Piper TTS API
 type SynthesizeRequest struct {
-	Text     string `json:"text"`
-	Language string `json:"language,omitempty"`
+	Text     string  `json:"text"`
+	Language string  `json:"language,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
+}
+
+// maxAudioCacheEntries ограничивает размер кэша сгенерированного аудио
+const maxAudioCacheEntries = 200
+
+// audioCacheKey — ключ кэша аудио: один и тот же текст на разной скорости звучит по-разному
+type audioCacheKey struct {
+	text  string
+	speed float64
 }
 
 // PiperService предоставляет функциональность Text-to-Speech через Piper TTS API
@@ -23,6 +34,9 @@ type PiperService struct {
 	logger  *zap.Logger
 	baseURL string
 	client  *http.Client
+
+	cacheMu sync.Mutex
+	cache   map[audioCacheKey][]byte
 }
 
 // NewPiperService создает новый Piper TTS сервис
@@ -33,20 +47,51 @@ func NewPiperService(logger *zap.Logger, baseURL string) *PiperService {
 		client: &http.Client{
 			Timeout: 30 * time.Second, // Таймаут для генерации аудио
 		},
+		cache: make(map[audioCacheKey][]byte),
 	}
 }
 
 // SynthesizeText преобразует текст в аудио через Piper TTS
 func (s *PiperService) SynthesizeText(ctx context.Context, text string) ([]byte, error) {
+	return s.SynthesizeTextWithSpeed(ctx, text, NormalSpeed)
+}
+
+// SynthesizeTextWithSpeed преобразует текст в аудио с заданной скоростью речи.
+// Результат кэшируется по паре текст+скорость.
+func (s *PiperService) SynthesizeTextWithSpeed(ctx context.Context, text string, speed float64) ([]byte, error) {
+	if speed <= 0 {
+		speed = NormalSpeed
+	}
+	key := audioCacheKey{text: text, speed: speed}
+
+	s.cacheMu.Lock()
+	cached, ok := s.cache[key]
+	s.cacheMu.Unlock()
+	if ok {
+		s.logger.Debug("🎵 аудио взято из кэша",
+			zap.String("text", text),
+			zap.Float64("speed", speed))
+		return cached, nil
+	}
+
 	s.logger.Info("🎵 генерируем аудио через Piper TTS",
 		zap.String("text", text),
-		zap.Int("text_length", len(text)))
+		zap.Int("text_length", len(text)),
+		zap.Float64("speed", speed))
 
-	audioData, err := s.generateAudio(ctx, text)
+	audioData, err := s.generateAudio(ctx, text, speed)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации аудио: %w", err)
 	}
 
+	s.cacheMu.Lock()
+	if len(s.cache) >= maxAudioCacheEntries {
+		// Кэш небольшой, поэтому при переполнении просто начинаем заново
+		s.cache = make(map[audioCacheKey][]byte)
+	}
+	s.cache[key] = audioData
+	s.cacheMu.Unlock()
+
 	s.logger.Info("🎵 аудио успешно сгенерировано",
 		zap.String("text", text),
 		zap.Int("audio_size", len(audioData)))
@@ -55,7 +100,7 @@ func (s *PiperService) SynthesizeText(ctx context.Context, text string) ([]byte,
 }
 
 // generateAudio отправляет запрос к Piper TTS API и получает аудио
-func (s *PiperService) generateAudio(ctx context.Context, text string) ([]byte, error) {
+func (s *PiperService) generateAudio(ctx context.Context, text string, speed float64) ([]byte, error) {
 	url := fmt.Sprintf("%s/synthesize-raw", s.baseURL)
 
 	// Создаем JSON запрос
@@ -63,6 +108,9 @@ func (s *PiperService) generateAudio(ctx context.Context, text string) ([]byte,
 		Text:     text,
 		Language: "", // будет определен автоматически
 	}
+	if speed != NormalSpeed {
+		request.Speed = speed
+	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSynthesizeTextWithSpeedCachesByTextAndSpeed(t *testing.T) {
+	var calls int
+	var speeds []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req SynthesizeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("некорректный запрос: %v", err)
+		}
+		speeds = append(speeds, req.Speed)
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	s := NewPiperService(zap.NewNop(), server.URL)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.SynthesizeText(ctx, "Hello"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if _, err := s.SynthesizeTextWithSpeed(ctx, "Hello", SlowSpeed); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("ожидалось 2 запроса к TTS, получено %d", calls)
+	}
+	if speeds[0] != 0 {
+		t.Errorf("для обычной скорости speed не должен передаваться, получено %v", speeds[0])
+	}
+	if speeds[1] != SlowSpeed {
+		t.Errorf("ожидалась скорость %v, получено %v", SlowSpeed, speeds[1])
+	}
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// PlatformStatsActiveDays за сколько последних дней ученик должен заходить, чтобы попасть в средние
+const PlatformStatsActiveDays = 30
+
+// RefreshPlatformStats пересчитывает средние показатели учеников и сохраняет их для /compare
+func (s *Service) RefreshPlatformStats(ctx context.Context) (*models.PlatformStats, error) {
+	stats, err := s.store.User().GetPlatformStats(ctx, time.Now().AddDate(0, 0, -PlatformStatsActiveDays))
+	if err != nil {
+		return nil, err
+	}
+
+	s.platformMu.Lock()
+	s.platformStats = stats
+	s.platformMu.Unlock()
+
+	s.logger.Info("средние показатели учеников обновлены",
+		zap.Int("users", stats.Users),
+		zap.Float64("avg_xp", stats.AvgXP))
+	return stats, nil
+}
+
+// PlatformStats возвращает сохраненные средние показатели учеников.
+// Если джоба еще не успела их посчитать, считает сразу.
+func (s *Service) PlatformStats(ctx context.Context) (*models.PlatformStats, error) {
+	s.platformMu.RLock()
+	stats := s.platformStats
+	s.platformMu.RUnlock()
+
+	if stats != nil {
+		return stats, nil
+	}
+	return s.RefreshPlatformStats(ctx)
+}
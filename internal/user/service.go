@@ -2,28 +2,49 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"lingua-ai/internal/premium"
 	"lingua-ai/internal/store"
 	"lingua-ai/pkg/models"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // Service представляет сервис для работы с пользователями
 type Service struct {
-	store  store.Store
-	logger *zap.Logger
+	store        store.Store
+	logger       *zap.Logger
+	defaultLevel string // Уровень, с которым создаются новые пользователи
+
+	platformMu    sync.RWMutex
+	platformStats *models.PlatformStats // средние показатели учеников, обновляются джобой
+
+	creating singleflight.Group // создание пользователя по Telegram ID: одновременные вызовы схлопываются в один
 }
 
 // NewService создает новый сервис пользователей
 func NewService(store store.Store, logger *zap.Logger) *Service {
 	return &Service{
-		store:  store,
-		logger: logger,
+		store:        store,
+		logger:       logger,
+		defaultLevel: models.LevelBeginner,
+	}
+}
+
+// SetDefaultLevel задает уровень, с которым создаются новые пользователи
+func (s *Service) SetDefaultLevel(level string) error {
+	if !models.IsValidLevel(level) {
+		return fmt.Errorf("неверный уровень по умолчанию: %s", level)
 	}
+	s.defaultLevel = level
+	return nil
 }
 
 // CreateUser создает нового пользователя
@@ -40,11 +61,20 @@ func (s *Service) CreateUser(ctx context.Context, req *models.CreateUserRequest)
 		Username:   req.Username,
 		FirstName:  req.FirstName,
 		LastName:   req.LastName,
-		Level:      models.LevelBeginner,
+		Level:      s.defaultLevel,
 		XP:         0,
+
+		MaxMessages:      premium.DefaultFreeMessageLimit,
+		NewCardsPerDay:   models.DefaultNewCardsPerDay,
+		LearningLanguage: models.DefaultLearningLanguage,
+		RemindersEnabled: true,
 	}
 
 	if err := s.store.User().Create(ctx, user); err != nil {
+		// Пользователя успел создать параллельный вызов: возвращаем сохраненного
+		if errors.Is(err, store.ErrUserAlreadyExists) {
+			return s.store.User().GetByTelegramID(ctx, req.TelegramID)
+		}
 		return nil, fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
@@ -185,7 +215,7 @@ func (s *Service) AddXP(ctx context.Context, userID int64, xp int) error {
 
 	// Проверяем, нужно ли повысить уровень
 	oldLevel := user.Level
-	user.Level = s.calculateLevel(user.XP)
+	user.Level = models.PromoteLevelByXP(user.Level, user.XP)
 
 	if err := s.store.User().Update(ctx, user); err != nil {
 		return fmt.Errorf("ошибка обновления пользователя: %w", err)
@@ -211,18 +241,6 @@ func (s *Service) GetUserStats(ctx context.Context, userID int64) (*models.UserS
 	return stats, nil
 }
 
-// calculateLevel рассчитывает уровень пользователя на основе XP
-func (s *Service) calculateLevel(xp int) string {
-	switch {
-	case xp < 100:
-		return models.LevelBeginner
-	case xp < 500:
-		return models.LevelIntermediate
-	default:
-		return models.LevelAdvanced
-	}
-}
-
 // GetOrCreateUser получает пользователя или создает нового
 func (s *Service) GetOrCreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*models.User, error) {
 	// Пытаемся получить существующего пользователя
@@ -237,26 +255,386 @@ func (s *Service) GetOrCreateUser(ctx context.Context, telegramID int64, usernam
 		return user, nil
 	}
 
-	// Если пользователь не найден, создаем нового
+	// Создаем нового только если пользователь действительно не найден:
+	// при сбое БД создание привело бы к дублю или ошибке уникальности
+	if err != nil && !errors.Is(err, store.ErrUserNotFound) {
+		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
+	}
+
+	// Обновления обрабатываются параллельно, и первые сообщения нового пользователя
+	// приходят почти одновременно: создаем его один раз, остальные вызовы ждут результат.
+	// Общий вызов не зависит от отмены контекста первого вызывающего, иначе она
+	// провалила бы всех ожидающих. Вызовы, разминувшиеся с ним по времени, разбирает
+	// CreateUser по ошибке уникальности.
+	sharedCtx := context.WithoutCancel(ctx)
+	created, err, shared := s.creating.Do(strconv.FormatInt(telegramID, 10), func() (interface{}, error) {
+		return s.CreateUser(sharedCtx, &models.CreateUserRequest{
+			TelegramID: telegramID,
+			Username:   username,
+			FirstName:  firstName,
+			LastName:   lastName,
+		})
+	})
 	if err != nil {
-		// Проверяем, что это действительно ошибка "не найден"
-		if !strings.Contains(err.Error(), "не найден") && !strings.Contains(err.Error(), "no rows") {
-			// Логируем ошибку, но продолжаем создание
-			s.logger.Warn("ошибка получения пользователя, создаем нового",
-				zap.Int64("telegram_id", telegramID),
-				zap.Error(err))
+		return nil, err
+	}
+
+	user = created.(*models.User)
+	if shared {
+		// Каждому вызывающему свою копию: обработчики меняют поля пользователя
+		copied := *user
+		user = &copied
+	}
+	return user, nil
+}
+
+// AdjustExerciseDifficultyBias изменяет смещение сложности упражнений по отзыву пользователя
+func (s *Service) AdjustExerciseDifficultyBias(ctx context.Context, userID int64, delta int) (int, error) {
+	bias, err := s.store.User().AdjustExerciseDifficultyBias(ctx, userID, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("сложность упражнений изменена",
+		zap.Int64("user_id", userID),
+		zap.Int("delta", delta),
+		zap.Int("bias", bias))
+
+	return bias, nil
+}
+
+// RecordExerciseAnswer учитывает ответ на упражнение и, когда набирается окно из
+// models.ExerciseAdaptWindow ответов, подстраивает сложность упражнений под точность.
+// Возвращает сдвиг сложности (0 — сложность не пересматривалась) и новое смещение сложности.
+func (s *Service) RecordExerciseAnswer(ctx context.Context, userID int64, correct bool) (int, int, error) {
+	answers, correctCount, err := s.store.User().RecordExerciseAnswer(ctx, userID, correct, models.ExerciseAdaptWindow)
+	if err != nil {
+		return 0, 0, err
+	}
+	if answers < models.ExerciseAdaptWindow {
+		return 0, 0, nil
+	}
+
+	delta := models.ExerciseAccuracyDelta(answers, correctCount)
+	if delta == 0 {
+		return 0, 0, nil
+	}
+
+	bias, err := s.AdjustExerciseDifficultyBias(ctx, userID, delta)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s.logger.Info("сложность упражнений подстроена под точность ответов",
+		zap.Int64("user_id", userID),
+		zap.Int("answers", answers),
+		zap.Int("correct", correctCount))
+	return delta, bias, nil
+}
+
+// CompleteOnboarding отмечает прохождение тура и начисляет бонус только за первое прохождение.
+// Возвращает true, если бонус был начислен.
+func (s *Service) CompleteOnboarding(ctx context.Context, userID int64) (bool, error) {
+	first, err := s.store.User().MarkOnboardingCompleted(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !first {
+		return false, nil
+	}
+
+	if err := s.AddXP(ctx, userID, models.OnboardingBonusXP); err != nil {
+		return false, fmt.Errorf("ошибка начисления бонуса за тур: %w", err)
+	}
+
+	s.logger.Info("тур по боту пройден впервые", zap.Int64("user_id", userID))
+	return true, nil
+}
+
+// ChooseInitialLevel сохраняет стартовый уровень нового пользователя и способ его определения
+// (самооценка, тест или пропуск выбора). Возвращает false, если стартовый уровень уже был выбран раньше.
+func (s *Service) ChooseInitialLevel(ctx context.Context, userID int64, level, assessment string) (bool, error) {
+	if !models.IsValidLevel(level) {
+		return false, fmt.Errorf("неверный уровень: %s", level)
+	}
+	if !models.IsValidLevelAssessment(assessment) {
+		return false, fmt.Errorf("неверный способ определения уровня: %s", assessment)
+	}
+
+	chosen, err := s.store.User().SetInitialLevel(ctx, userID, level, assessment)
+	if err != nil {
+		return false, err
+	}
+	if chosen {
+		s.logger.Info("выбран стартовый уровень",
+			zap.Int64("user_id", userID),
+			zap.String("level", level),
+			zap.String("assessment", assessment))
+	}
+	return chosen, nil
+}
+
+// SetLevelAssessment запоминает, как определен текущий уровень пользователя
+func (s *Service) SetLevelAssessment(ctx context.Context, userID int64, assessment string) error {
+	if !models.IsValidLevelAssessment(assessment) {
+		return fmt.Errorf("неверный способ определения уровня: %s", assessment)
+	}
+	return s.store.User().SetLevelAssessment(ctx, userID, assessment)
+}
+
+// SetLearningLanguage сохраняет изучаемый язык пользователя
+func (s *Service) SetLearningLanguage(ctx context.Context, userID int64, language string) error {
+	if !models.IsSupportedLearningLanguage(language) {
+		return fmt.Errorf("неподдерживаемый изучаемый язык: %s", language)
+	}
+
+	if err := s.store.User().SetLearningLanguage(ctx, userID, language); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменен изучаемый язык",
+		zap.Int64("user_id", userID),
+		zap.String("language", language))
+	return nil
+}
+
+// ErrInvalidPace темп новых карточек вне допустимых пределов
+var ErrInvalidPace = fmt.Errorf("темп должен быть от %d до %d новых карточек в день",
+	models.MinNewCardsPerDay, models.MaxNewCardsPerDay)
+
+// SetNewCardsPerDay задает, сколько новых карточек пользователь начинает в день
+func (s *Service) SetNewCardsPerDay(ctx context.Context, userID int64, perDay int) error {
+	if !models.IsValidNewCardsPerDay(perDay) {
+		return ErrInvalidPace
+	}
+
+	if err := s.store.User().SetNewCardsPerDay(ctx, userID, perDay); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменен темп новых карточек",
+		zap.Int64("user_id", userID),
+		zap.Int("per_day", perDay))
+	return nil
+}
+
+// GetNewCardsPerDay возвращает темп новых карточек пользователя
+func (s *Service) GetNewCardsPerDay(ctx context.Context, userID int64) (int, error) {
+	user, err := s.store.User().GetByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения темпа новых карточек: %w", err)
+	}
+	if !models.IsValidNewCardsPerDay(user.NewCardsPerDay) {
+		return models.DefaultNewCardsPerDay, nil
+	}
+	return user.NewCardsPerDay, nil
+}
+
+// ErrInvalidWeeklyTarget недельная цель вне допустимых пределов
+var ErrInvalidWeeklyTarget = fmt.Errorf("недельная цель должна быть от %d до %d слов",
+	models.MinWeeklyWordTarget, models.MaxWeeklyWordTarget)
+
+// SetWeeklyWordTarget задает, сколько слов пользователь хочет выучить за неделю; 0 снимает цель
+func (s *Service) SetWeeklyWordTarget(ctx context.Context, userID int64, target int) error {
+	if target != 0 && !models.IsValidWeeklyWordTarget(target) {
+		return ErrInvalidWeeklyTarget
+	}
+
+	if err := s.store.User().SetWeeklyWordTarget(ctx, userID, target); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменена недельная цель",
+		zap.Int64("user_id", userID),
+		zap.Int("target", target))
+	return nil
+}
+
+// GetWeeklyWordTarget возвращает недельную цель пользователя (0 — цель не задана)
+func (s *Service) GetWeeklyWordTarget(ctx context.Context, userID int64) (int, error) {
+	user, err := s.store.User().GetByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения недельной цели: %w", err)
+	}
+	if !models.IsValidWeeklyWordTarget(user.WeeklyWordTarget) {
+		return 0, nil
+	}
+	return user.WeeklyWordTarget, nil
+}
+
+// CompleteWeeklyTarget отмечает выполнение недельной цели и начисляет бонус.
+// Возвращает false, если на этой неделе цель уже засчитана.
+func (s *Service) CompleteWeeklyTarget(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	completed, err := s.store.User().MarkWeeklyTargetCompleted(ctx, userID, weekStart)
+	if err != nil || !completed {
+		return false, err
+	}
+
+	if err := s.AddXP(ctx, userID, models.WeeklyTargetBonusXP); err != nil {
+		return true, fmt.Errorf("ошибка начисления бонуса за недельную цель: %w", err)
+	}
+
+	s.logger.Info("недельная цель выполнена",
+		zap.Int64("user_id", userID),
+		zap.Time("week_start", weekStart))
+	return true, nil
+}
+
+// GetWeeklyTargetReminderUsers получает пользователей, которым может понадобиться
+// напоминание о невыполненной недельной цели
+func (s *Service) GetWeeklyTargetReminderUsers(ctx context.Context, weekStart time.Time) ([]*models.User, error) {
+	return s.store.User().GetWeeklyTargetReminderUsers(ctx, weekStart)
+}
+
+// MarkWeeklyTargetReminded отмечает напоминание о цели. Возвращает false, если на этой неделе оно уже отправлено.
+func (s *Service) MarkWeeklyTargetReminded(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	return s.store.User().MarkWeeklyTargetReminded(ctx, userID, weekStart)
+}
+
+// GetPremiumExpiringUsers получает премиум-пользователей, чья подписка заканчивается
+// в промежутке (from, until] и кому еще не напоминали о продлении
+func (s *Service) GetPremiumExpiringUsers(ctx context.Context, from, until time.Time) ([]*models.User, error) {
+	return s.store.User().GetPremiumExpiringUsers(ctx, from, until)
+}
+
+// MarkPremiumExpiryReminded отмечает напоминание о продлении. Возвращает false,
+// если для этого срока подписки оно уже отправлено или подписка успела продлиться.
+func (s *Service) MarkPremiumExpiryReminded(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	return s.store.User().MarkPremiumExpiryReminded(ctx, userID, expiresAt)
+}
+
+// GetPremiumExpiredUsers получает пользователей, чей премиум закончился в промежутке (since, now]
+// и еще не отключен
+func (s *Service) GetPremiumExpiredUsers(ctx context.Context, since, now time.Time) ([]*models.User, error) {
+	return s.store.User().GetPremiumExpiredUsers(ctx, since, now)
+}
+
+// MarkPremiumExpiredNotified отмечает уведомление об окончании премиума. Возвращает false,
+// если для этого срока оно уже отправлено или подписка успела продлиться.
+func (s *Service) MarkPremiumExpiredNotified(ctx context.Context, userID int64, expiresAt time.Time) (bool, error) {
+	return s.store.User().MarkPremiumExpiredNotified(ctx, userID, expiresAt)
+}
+
+// GetStreakAtRiskUsers получает пользователей, чья серия прервется, если они не позанимаются
+// сегодня — в сутки, которые в их поясе идут в момент now
+func (s *Service) GetStreakAtRiskUsers(ctx context.Context, now time.Time) ([]*models.User, error) {
+	return s.store.User().GetStreakAtRiskUsers(ctx, now)
+}
+
+// MarkStreakWarningSent отмечает предупреждение о серии. Возвращает false, если сегодня оно уже отправлено.
+func (s *Service) MarkStreakWarningSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	return s.store.User().MarkStreakWarningSent(ctx, userID, dayStart)
+}
+
+// SetStreakWarnings включает или отключает предупреждения о серии
+func (s *Service) SetStreakWarnings(ctx context.Context, userID int64, enabled bool) error {
+	if err := s.store.User().SetStreakWarnings(ctx, userID, enabled, nil); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменены предупреждения о серии",
+		zap.Int64("user_id", userID),
+		zap.Bool("enabled", enabled))
+	return nil
+}
+
+// GetDailyReminderUsers получает кандидатов для ежедневного напоминания: они не занимались
+// и не получали напоминание с studiedBefore, но заходили в бота за последние
+// DailyReminderActiveDays дней
+func (s *Service) GetDailyReminderUsers(ctx context.Context, studiedBefore time.Time) ([]*models.User, error) {
+	return s.store.User().GetDailyReminderUsers(ctx, studiedBefore, studiedBefore.AddDate(0, 0, -models.DailyReminderActiveDays))
+}
+
+// MarkDailyReminderSent отмечает ежедневное напоминание. Возвращает false, если сегодня оно уже отправлено.
+func (s *Service) MarkDailyReminderSent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	return s.store.User().MarkDailyReminderSent(ctx, userID, dayStart)
+}
+
+// GetWordOfDayUsers получает пользователей для слова дня: напоминания у них включены,
+// последнее слово отмечено раньше sentBefore, и они заходили в бота
+// за последние models.DailyReminderActiveDays дней
+func (s *Service) GetWordOfDayUsers(ctx context.Context, sentBefore time.Time) ([]*models.User, error) {
+	return s.store.User().GetWordOfDayUsers(ctx, sentBefore, sentBefore.AddDate(0, 0, -models.DailyReminderActiveDays))
+}
+
+// MarkWordOfDaySent отмечает слово дня. Возвращает false, если сегодня оно уже отправлено.
+func (s *Service) MarkWordOfDaySent(ctx context.Context, userID int64, dayStart time.Time) (bool, error) {
+	return s.store.User().MarkWordOfDaySent(ctx, userID, dayStart)
+}
+
+// SetRemindersEnabled включает или отключает ежедневные напоминания о занятиях
+func (s *Service) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	if err := s.store.User().SetRemindersEnabled(ctx, userID, enabled); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменены ежедневные напоминания",
+		zap.Int64("user_id", userID),
+		zap.Bool("enabled", enabled))
+	return nil
+}
+
+// ErrInvalidFlashcardCategory неизвестная категория карточек
+var ErrInvalidFlashcardCategory = errors.New("неизвестная категория карточек")
+
+// SetFlashcardCategory запоминает категорию, по которой пользователь учит карточки.
+// Пустая строка — все категории.
+func (s *Service) SetFlashcardCategory(ctx context.Context, userID int64, category string) error {
+	if !models.IsValidFlashcardCategory(category) {
+		return ErrInvalidFlashcardCategory
+	}
+
+	if err := s.store.User().SetFlashcardCategory(ctx, userID, category); err != nil {
+		return err
+	}
+
+	s.logger.Info("изменена категория карточек",
+		zap.Int64("user_id", userID),
+		zap.String("category", category))
+	return nil
+}
+
+// ErrInvalidTimezone часовой пояс не найден в базе IANA
+var ErrInvalidTimezone = errors.New("неизвестный часовой пояс")
+
+// SetTimezone сохраняет часовой пояс пользователя (имя IANA, например Europe/Moscow).
+// Пустая строка возвращает пояс бота по умолчанию (DAILY_RESET_TZ).
+func (s *Service) SetTimezone(ctx context.Context, userID int64, timezone string) error {
+	if timezone != "" {
+		if _, err := models.LoadTimezone(timezone); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
 		}
 	}
 
-	// Создаем нового пользователя
-	req := &models.CreateUserRequest{
-		TelegramID: telegramID,
-		Username:   username,
-		FirstName:  firstName,
-		LastName:   lastName,
+	if err := s.store.User().SetTimezone(ctx, userID, timezone); err != nil {
+		return err
 	}
 
-	return s.CreateUser(ctx, req)
+	s.logger.Info("изменен часовой пояс",
+		zap.Int64("user_id", userID),
+		zap.String("timezone", timezone))
+	return nil
+}
+
+// SnoozeStreakWarnings откладывает предупреждения о серии до until
+func (s *Service) SnoozeStreakWarnings(ctx context.Context, userID int64, until time.Time) error {
+	if err := s.store.User().SetStreakWarnings(ctx, userID, true, &until); err != nil {
+		return err
+	}
+
+	s.logger.Info("предупреждения о серии отложены",
+		zap.Int64("user_id", userID),
+		zap.Time("until", until))
+	return nil
+}
+
+// GetUserByUsername получает пользователя по username
+func (s *Service) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.store.User().GetByUsername(ctx, strings.TrimPrefix(username, "@"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения пользователя по username: %w", err)
+	}
+	return user, nil
 }
 
 // GetUserByID получает пользователя по ID
@@ -268,13 +646,13 @@ func (s *Service) GetUserByID(ctx context.Context, userID int64) (*models.User,
 	return user, nil
 }
 
-// UpdateStudyActivity обновляет активность обучения пользователя
-func (s *Service) UpdateStudyActivity(ctx context.Context, userID int64) error {
-	err := s.store.User().UpdateStudyActivity(ctx, userID)
+// UpdateStudyActivity обновляет активность обучения пользователя и возвращает актуальный streak
+func (s *Service) UpdateStudyActivity(ctx context.Context, userID int64) (int, error) {
+	streak, err := s.store.User().UpdateStudyActivity(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("ошибка обновления активности обучения: %w", err)
+		return 0, fmt.Errorf("ошибка обновления активности обучения: %w", err)
 	}
-	return nil
+	return streak, nil
 }
 
 // GetTopUsersByStreak получает топ пользователей по study streak
@@ -286,6 +664,15 @@ func (s *Service) GetTopUsersByStreak(ctx context.Context, limit int) ([]*models
 	return users, nil
 }
 
+// GetTopUsersByStreakOnly получает топ пользователей по серии занятий, при равной серии — по XP
+func (s *Service) GetTopUsersByStreakOnly(ctx context.Context, limit int) ([]*models.User, error) {
+	users, err := s.store.User().GetTopUsersByStreakOnly(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения топ пользователей по серии: %w", err)
+	}
+	return users, nil
+}
+
 // GetAllUsers получает всех пользователей для рейтинга
 func (s *Service) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 	users, err := s.store.User().GetAll(ctx)
@@ -351,3 +738,13 @@ func (s *Service) IncrementMessagesCount(ctx context.Context, userID int64) erro
 	// Обновляем пользователя
 	return s.Update(ctx, user)
 }
+
+// ResetDailyMessageCounts обнуляет дневные счетчики сообщений всех пользователей (для интерфейса premium.UserRepository)
+func (s *Service) ResetDailyMessageCounts(ctx context.Context, today time.Time) (int64, error) {
+	return s.store.User().ResetDailyMessageCounts(ctx, today)
+}
+
+// ApplyPremiumGift сохраняет подарок премиума и продлевает премиум получателя одной транзакцией
+func (s *Service) ApplyPremiumGift(ctx context.Context, payment *models.Payment) (time.Time, error) {
+	return s.store.User().ApplyPremiumGift(ctx, payment)
+}
@@ -0,0 +1,292 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"lingua-ai/internal/premium"
+	"lingua-ai/internal/store"
+	"lingua-ai/pkg/models"
+
+	"go.uber.org/zap"
+)
+
+// fakeUserRepo возвращает заданную ошибку поиска и считает созданных пользователей
+type fakeUserRepo struct {
+	store.UserRepository
+	getErr  error
+	created int
+	perDay  int
+	weekly  int
+
+	platform      *models.PlatformStats
+	platformCalls int
+}
+
+func (r *fakeUserRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	return nil, r.getErr
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error {
+	r.created++
+	return nil
+}
+
+func (r *fakeUserRepo) GetPlatformStats(ctx context.Context, activeSince time.Time) (*models.PlatformStats, error) {
+	r.platformCalls++
+	return r.platform, nil
+}
+
+func (r *fakeUserRepo) SetNewCardsPerDay(ctx context.Context, userID int64, perDay int) error {
+	r.perDay = perDay
+	return nil
+}
+
+func (r *fakeUserRepo) SetWeeklyWordTarget(ctx context.Context, userID int64, target int) error {
+	r.weekly = target
+	return nil
+}
+
+type fakeStore struct {
+	store.Store
+	users store.UserRepository
+}
+
+func (s *fakeStore) User() store.UserRepository {
+	return s.users
+}
+
+func TestGetOrCreateUserCreatesWhenNotFound(t *testing.T) {
+	repo := &fakeUserRepo{getErr: fmt.Errorf("%w: telegram_id 42", store.ErrUserNotFound)}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+
+	user, err := service.GetOrCreateUser(context.Background(), 42, "user", "Имя", "")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if repo.created != 1 {
+		t.Errorf("ожидалось создание 1 пользователя, получено %d", repo.created)
+	}
+	if user.Level != models.LevelBeginner {
+		t.Errorf("ожидался уровень %s, получено %s", models.LevelBeginner, user.Level)
+	}
+	if user.MaxMessages != premium.DefaultFreeMessageLimit {
+		t.Errorf("ожидался лимит бесплатного тарифа %d, получено %d", premium.DefaultFreeMessageLimit, user.MaxMessages)
+	}
+}
+
+func TestGetOrCreateUserReturnsStoreError(t *testing.T) {
+	dbErr := errors.New("соединение потеряно")
+	repo := &fakeUserRepo{getErr: dbErr}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+
+	_, err := service.GetOrCreateUser(context.Background(), 42, "user", "Имя", "")
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("ожидалась ошибка БД, получено %v", err)
+	}
+	if repo.created != 0 {
+		t.Errorf("пользователь не должен создаваться при сбое БД, создано %d", repo.created)
+	}
+}
+
+func TestSetNewCardsPerDayValidatesBounds(t *testing.T) {
+	repo := &fakeUserRepo{}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+	ctx := context.Background()
+
+	for _, perDay := range []int{0, -5, models.MaxNewCardsPerDay + 1} {
+		if err := service.SetNewCardsPerDay(ctx, 1, perDay); !errors.Is(err, ErrInvalidPace) {
+			t.Errorf("темп %d: ожидалась ошибка ErrInvalidPace, получено %v", perDay, err)
+		}
+	}
+	if repo.perDay != 0 {
+		t.Errorf("неверный темп не должен сохраняться, сохранено %d", repo.perDay)
+	}
+
+	if err := service.SetNewCardsPerDay(ctx, 1, 20); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if repo.perDay != 20 {
+		t.Errorf("ожидался сохраненный темп 20, получено %d", repo.perDay)
+	}
+}
+
+func TestSetWeeklyWordTargetValidatesBounds(t *testing.T) {
+	repo := &fakeUserRepo{}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+	ctx := context.Background()
+
+	for _, target := range []int{-1, models.MinWeeklyWordTarget - 1, models.MaxWeeklyWordTarget + 1} {
+		if err := service.SetWeeklyWordTarget(ctx, 1, target); !errors.Is(err, ErrInvalidWeeklyTarget) {
+			t.Errorf("цель %d: ожидалась ошибка ErrInvalidWeeklyTarget, получено %v", target, err)
+		}
+	}
+	if repo.weekly != 0 {
+		t.Errorf("неверная цель не должна сохраняться, сохранено %d", repo.weekly)
+	}
+
+	if err := service.SetWeeklyWordTarget(ctx, 1, 20); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if repo.weekly != 20 {
+		t.Errorf("ожидалась сохраненная цель 20, получено %d", repo.weekly)
+	}
+
+	// 0 снимает цель
+	if err := service.SetWeeklyWordTarget(ctx, 1, 0); err != nil || repo.weekly != 0 {
+		t.Errorf("ожидалось снятие цели, получено %d, %v", repo.weekly, err)
+	}
+}
+
+func TestPlatformStatsCachedUntilRefresh(t *testing.T) {
+	repo := &fakeUserRepo{platform: &models.PlatformStats{Users: 10}}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+	ctx := context.Background()
+
+	// Джоба еще не отработала: считаем по запросу и запоминаем
+	for i := 0; i < 3; i++ {
+		stats, err := service.PlatformStats(ctx)
+		if err != nil || stats.Users != 10 {
+			t.Fatalf("ожидалась статистика 10 учеников, получено %+v, %v", stats, err)
+		}
+	}
+	if repo.platformCalls != 1 {
+		t.Errorf("ожидался 1 запрос к базе, получено %d", repo.platformCalls)
+	}
+
+	repo.platform = &models.PlatformStats{Users: 25}
+	if _, err := service.RefreshPlatformStats(ctx); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if stats, _ := service.PlatformStats(ctx); stats.Users != 25 {
+		t.Errorf("после пересчета ожидалось 25 учеников, получено %d", stats.Users)
+	}
+}
+
+// slowCreateRepo находит только созданных пользователей и создает их с задержкой,
+// чтобы одновременные вызовы успели разойтись до окончания вставки
+type slowCreateRepo struct {
+	store.UserRepository
+	mu      sync.Mutex
+	users   map[int64]*models.User
+	created int
+}
+
+func (r *slowCreateRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[telegramID]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("%w: telegram_id %d", store.ErrUserNotFound, telegramID)
+}
+
+func (r *slowCreateRepo) UpdateLastSeen(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (r *slowCreateRepo) Create(ctx context.Context, user *models.User) error {
+	time.Sleep(50 * time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created++
+	user.ID = int64(r.created)
+	stored := *user
+	r.users[user.TelegramID] = &stored
+	return nil
+}
+
+func TestGetOrCreateUserDeduplicatesConcurrentCreates(t *testing.T) {
+	repo := &slowCreateRepo{users: make(map[int64]*models.User)}
+	service := NewService(&fakeStore{users: repo}, zap.NewNop())
+
+	const callers = 10
+	users := make([]*models.User, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			users[i], errs[i] = service.GetOrCreateUser(context.Background(), 42, "user", "Имя", "")
+		}(i)
+	}
+	wg.Wait()
+
+	if repo.created != 1 {
+		t.Fatalf("ожидалось создание 1 пользователя, создано %d", repo.created)
+	}
+	for i := range users {
+		if errs[i] != nil {
+			t.Fatalf("вызов %d: неожиданная ошибка: %v", i, errs[i])
+		}
+		if users[i].ID != 1 || users[i].TelegramID != 42 {
+			t.Errorf("вызов %d: ожидался пользователь с ID 1, получено %+v", i, users[i])
+		}
+	}
+}
+
+// racingCreateRepo изображает пользователя, созданного другим вызовом между поиском и вставкой
+type racingCreateRepo struct {
+	store.UserRepository
+	lookups int
+}
+
+func (r *racingCreateRepo) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	r.lookups++
+	if r.lookups <= 2 {
+		return nil, fmt.Errorf("%w: telegram_id %d", store.ErrUserNotFound, telegramID)
+	}
+	return &models.User{ID: 7, TelegramID: telegramID}, nil
+}
+
+func (r *racingCreateRepo) Create(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: telegram_id %d", store.ErrUserAlreadyExists, user.TelegramID)
+}
+
+func TestGetOrCreateUserRereadsAfterUniqueViolation(t *testing.T) {
+	service := NewService(&fakeStore{users: &racingCreateRepo{}}, zap.NewNop())
+
+	user, err := service.GetOrCreateUser(context.Background(), 42, "user", "Имя", "")
+	if err != nil {
+		t.Fatalf("ошибка уникальности не должна доходить до пользователя: %v", err)
+	}
+	if user.ID != 7 {
+		t.Errorf("ожидался пользователь, созданный параллельным вызовом, получено %+v", user)
+	}
+}
+
+func TestGetOrCreateUserIgnoresCallerCancellation(t *testing.T) {
+	repo := &slowCreateRepo{users: make(map[int64]*models.User)}
+	service := NewService(&fakeStore{users: &cancelAwareRepo{slowCreateRepo: repo}}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.GetOrCreateUser(ctx, 42, "user", "Имя", ""); err != nil {
+		t.Fatalf("общее создание не должно зависеть от отмены контекста вызывающего: %v", err)
+	}
+	if repo.created != 1 {
+		t.Errorf("ожидалось создание 1 пользователя, создано %d", repo.created)
+	}
+}
+
+// cancelAwareRepo не создает пользователя на отмененном контексте
+type cancelAwareRepo struct {
+	*slowCreateRepo
+}
+
+func (r *cancelAwareRepo) Create(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.slowCreateRepo.Create(ctx, user)
+}
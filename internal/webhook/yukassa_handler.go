@@ -6,12 +6,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"lingua-ai/internal/premium"
+	"lingua-ai/internal/store"
 
 	"go.uber.org/zap"
 )
@@ -127,6 +129,11 @@ func (h *YooKassaWebhookHandler) handlePaymentSucceeded(ctx context.Context, web
 
 	// Получаем платеж из БД
 	payment, err := h.premiumService.GetPaymentByID(ctx, paymentID)
+	if errors.Is(err, store.ErrPaymentNotFound) {
+		// Повторная доставка не поможет — подтверждаем webhook, чтобы ЮKassa не слала его снова
+		h.logger.Warn("webhook для неизвестного платежа", zap.String("payment_id", paymentID))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("ошибка получения платежа: %w", err)
 	}
@@ -159,6 +166,11 @@ func (h *YooKassaWebhookHandler) handlePaymentCanceled(ctx context.Context, webh
 
 	// Получаем платеж из БД
 	payment, err := h.premiumService.GetPaymentByID(ctx, paymentID)
+	if errors.Is(err, store.ErrPaymentNotFound) {
+		// Повторная доставка не поможет — подтверждаем webhook, чтобы ЮKassa не слала его снова
+		h.logger.Warn("webhook для неизвестного платежа", zap.String("payment_id", paymentID))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("ошибка получения платежа: %w", err)
 	}
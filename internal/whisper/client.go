@@ -18,12 +18,20 @@ import (
 	"lingua-ai/internal/audio"
 )
 
+// Лимиты длительности аудио по умолчанию
+const (
+	DefaultMaxDuration        = 3 * time.Minute  // Для бесплатных пользователей
+	DefaultPremiumMaxDuration = 10 * time.Minute // Для премиум пользователей
+)
+
 // Client представляет клиент для работы с Whisper API
 type Client struct {
-	apiURL       string
-	httpClient   *http.Client
-	logger       *zap.Logger
-	vadProcessor *audio.VADProcessor
+	apiURL             string
+	httpClient         *http.Client
+	logger             *zap.Logger
+	vadProcessor       *audio.VADProcessor
+	maxDuration        time.Duration
+	premiumMaxDuration time.Duration
 }
 
 // NewClient создает новый клиент Whisper
@@ -33,11 +41,41 @@ func NewClient(apiURL string, logger *zap.Logger) *Client {
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second, // Увеличиваем таймаут для обработки аудио
 		},
-		logger:       logger,
-		vadProcessor: audio.NewVADProcessor(logger),
+		logger:             logger,
+		vadProcessor:       audio.NewVADProcessor(logger),
+		maxDuration:        DefaultMaxDuration,
+		premiumMaxDuration: DefaultPremiumMaxDuration,
+	}
+}
+
+// SetDurationLimits задает максимальную длительность аудио для обычных и премиум пользователей.
+// Нулевые значения оставляют текущие лимиты без изменений.
+func (c *Client) SetDurationLimits(maxDuration, premiumMaxDuration time.Duration) {
+	if maxDuration > 0 {
+		c.maxDuration = maxDuration
+	}
+	if premiumMaxDuration > 0 {
+		c.premiumMaxDuration = premiumMaxDuration
 	}
 }
 
+// MaxDuration возвращает максимальную длительность аудио для пользователя
+func (c *Client) MaxDuration(isPremium bool) time.Duration {
+	if isPremium {
+		return c.premiumMaxDuration
+	}
+	return c.maxDuration
+}
+
+// GetAudioDuration возвращает длительность аудиофайла
+func (c *Client) GetAudioDuration(filePath string) (time.Duration, error) {
+	seconds, err := c.vadProcessor.GetAudioDuration(filePath)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
 // TranscribeRequest представляет запрос на транскрибацию
 type TranscribeRequest struct {
 	AudioFile string `json:"audio_file"`
@@ -53,12 +91,15 @@ type TranscribeResponse struct {
 	Text     string  `json:"text"`
 	Language string  `json:"language"`
 	Duration float64 `json:"duration"`
-	Segments []struct {
-		Start  float64 `json:"start"`
-		End    float64 `json:"end"`
-		Text   string  `json:"text"`
-		Tokens []int   `json:"tokens"`
-	} `json:"segments"`
+	Segments []Segment `json:"segments"`
+}
+
+// Segment фрагмент распознанной речи с границами в секундах
+type Segment struct {
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Text   string  `json:"text"`
+	Tokens []int   `json:"tokens"`
 }
 
 // TranscribeFile транскрибирует аудио файл
@@ -152,6 +193,7 @@ func (c *Client) TranscribeFile(ctx context.Context, filePath string) (*Transcri
 	c.logger.Info("транскрибация завершена",
 		zap.String("file", filePath),
 		zap.String("text", response.Text),
+		zap.String("language", response.Language),
 		zap.Float64("duration", response.Duration))
 
 	return &response, nil
@@ -3,6 +3,7 @@ package whisper
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -80,12 +81,7 @@ func TestTranscribeResponse_JSON(t *testing.T) {
 		Text:     "Hello world",
 		Language: "en",
 		Duration: 2.5,
-		Segments: []struct {
-			Start  float64 `json:"start"`
-			End    float64 `json:"end"`
-			Text   string  `json:"text"`
-			Tokens []int   `json:"tokens"`
-		}{
+		Segments: []Segment{
 			{
 				Start:  0.0,
 				End:    2.5,
@@ -111,3 +107,23 @@ func TestTranscribeResponse_JSON(t *testing.T) {
 		t.Errorf("ожидался 1 сегмент, получено %d", len(response.Segments))
 	}
 }
+
+func TestDurationLimits(t *testing.T) {
+	client := NewClient("http://localhost:8080", zap.NewNop())
+
+	if got := client.MaxDuration(false); got != DefaultMaxDuration {
+		t.Errorf("ожидался лимит %v, получен %v", DefaultMaxDuration, got)
+	}
+	if got := client.MaxDuration(true); got != DefaultPremiumMaxDuration {
+		t.Errorf("ожидался премиум лимит %v, получен %v", DefaultPremiumMaxDuration, got)
+	}
+
+	client.SetDurationLimits(time.Minute, 0)
+
+	if got := client.MaxDuration(false); got != time.Minute {
+		t.Errorf("ожидался лимит %v, получен %v", time.Minute, got)
+	}
+	if got := client.MaxDuration(true); got != DefaultPremiumMaxDuration {
+		t.Errorf("нулевое значение не должно менять премиум лимит, получен %v", got)
+	}
+}
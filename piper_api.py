@@ -23,6 +23,7 @@ app = FastAPI(title="Piper TTS API", version="1.0.0")
 class SynthesizeRequest(BaseModel):
     text: str
     language: Optional[str] = None
+    speed: Optional[float] = None
 
 # Настройка CORS
 app.add_middleware(
@@ -53,7 +54,7 @@ def detect_language(text: str) -> str:
         return "ru"
     return "en"
 
-def synthesize_speech(text: str, language: Optional[str] = None) -> bytes:
+def synthesize_speech(text: str, language: Optional[str] = None, speed: Optional[float] = None) -> bytes:
     """Синтезирует речь из текста"""
     if not text.strip():
         raise ValueError("Текст не может быть пустым")
@@ -87,6 +88,10 @@ def synthesize_speech(text: str, language: Optional[str] = None) -> bytes:
             "--output_file", temp_path
         ]
         
+        # length_scale > 1 замедляет речь, поэтому берем обратную величину скорости
+        if speed and speed > 0 and speed != 1.0:
+            cmd += ["--length_scale", f"{1.0 / speed:.2f}"]
+        
         result = subprocess.run(cmd, capture_output=True, text=True, timeout=30)
         
         if result.returncode != 0:
@@ -172,7 +177,7 @@ async def synthesize_raw(request: SynthesizeRequest):
         if len(request.text) > 1000:
             raise HTTPException(status_code=400, detail="Текст слишком длинный (максимум 1000 символов)")
         
-        audio_data = synthesize_speech(request.text, request.language)
+        audio_data = synthesize_speech(request.text, request.language, request.speed)
         
         from fastapi.responses import Response
         return Response(
@@ -0,0 +1,7 @@
+package models
+
+// DailyReminderOffCallback кнопка под ежедневным напоминанием: больше не напоминать
+const DailyReminderOffCallback = "reminders_off"
+
+// DailyReminderActiveDays напоминание получают те, кто заходил в бота за столько последних дней
+const DailyReminderActiveDays = 7
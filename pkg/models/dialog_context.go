@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SavedDialogContext сохраненный контекст диалога: краткое содержание и последние реплики.
+// Восстанавливается после перезапуска бота, чтобы разговор продолжился с того же места.
+type SavedDialogContext struct {
+	UserID    int64                `json:"user_id"`
+	Summary   string               `json:"summary"`
+	Messages  []SavedDialogMessage `json:"messages"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// SavedDialogMessage реплика сохраненного диалога
+type SavedDialogMessage struct {
+	Role      string    `json:"role"` // "user" или "assistant"
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Constants для упражнений-квизов
+const (
+	// ExerciseCorrectXP опыт за верный ответ на упражнение
+	ExerciseCorrectXP = 10
+
+	// ExerciseAdaptWindow после скольких ответов пересматривается сложность упражнений
+	ExerciseAdaptWindow = 10
+	// ExerciseRaiseAccuracy доля верных ответов в окне, с которой упражнения становятся сложнее
+	ExerciseRaiseAccuracy = 0.8
+	// ExerciseLowerAccuracy доля верных ответов в окне, до которой упражнения становятся проще
+	ExerciseLowerAccuracy = 0.4
+)
+
+// ExerciseAccuracyDelta возвращает, как сдвинуть сложность упражнений по итогам окна ответов:
+// +1 — сложнее, -1 — проще, 0 — оставить
+func ExerciseAccuracyDelta(answers, correct int) int {
+	if answers <= 0 {
+		return 0
+	}
+	accuracy := float64(correct) / float64(answers)
+	switch {
+	case accuracy >= ExerciseRaiseAccuracy:
+		return 1
+	case accuracy <= ExerciseLowerAccuracy:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+// FlashcardCategory тематическая категория словарных карточек
+type FlashcardCategory struct {
+	Code string
+	Name string // «✈️ Путешествия» — для кнопок и сообщений
+}
+
+// AllFlashcardCategoriesName подпись сессии без фильтра по категории
+const AllFlashcardCategoriesName = "🔀 Все темы"
+
+// FlashcardCategories категории, по которым можно учить слова отдельно, в порядке показа
+var FlashcardCategories = []FlashcardCategory{
+	{Code: "general", Name: "🗂 Общая лексика"},
+	{Code: "business", Name: "💼 Бизнес"},
+	{Code: "travel", Name: "✈️ Путешествия"},
+	{Code: "food", Name: "🍽 Еда"},
+	{Code: "technology", Name: "💻 Технологии"},
+	{Code: "education", Name: "🎓 Образование"},
+	{Code: "health", Name: "🩺 Здоровье"},
+}
+
+// GetFlashcardCategory возвращает категорию по коду
+func GetFlashcardCategory(code string) (FlashcardCategory, bool) {
+	for _, category := range FlashcardCategories {
+		if category.Code == code {
+			return category, true
+		}
+	}
+	return FlashcardCategory{}, false
+}
+
+// IsValidFlashcardCategory проверяет код категории; пустой код — все категории
+func IsValidFlashcardCategory(code string) bool {
+	if code == "" {
+		return true
+	}
+	_, ok := GetFlashcardCategory(code)
+	return ok
+}
+
+// FlashcardCategoryName возвращает название категории для сообщений
+func FlashcardCategoryName(code string) string {
+	if category, ok := GetFlashcardCategory(code); ok {
+		return category.Name
+	}
+	return AllFlashcardCategoriesName
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Причины жалобы на карточку
+const (
+	ReportReasonTranslation = "translation" // неверный перевод
+	ReportReasonExample     = "example"     // битый или неподходящий пример
+	ReportReasonOther       = "other"       // другая ошибка
+)
+
+// IsValidReportReason проверяет, что причина жалобы известна
+func IsValidReportReason(reason string) bool {
+	switch reason {
+	case ReportReasonTranslation, ReportReasonExample, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// Решения администратора по жалобам
+const (
+	ReportResolutionRestore = "restore" // карточка исправлена или жалобы необоснованны — вернуть в выдачу
+	ReportResolutionSuspend = "suspend" // убрать карточку из выдачи насовсем
+)
+
+// FlashcardReportSummary открытые жалобы на одну карточку
+type FlashcardReportSummary struct {
+	FlashcardID    int64          `json:"flashcard_id"`
+	Word           string         `json:"word"`
+	Translation    string         `json:"translation"`
+	Example        string         `json:"example"`
+	Suspended      bool           `json:"suspended"`
+	Reports        int            `json:"reports"`
+	Reasons        map[string]int `json:"reasons"`
+	LastReportedAt time.Time      `json:"last_reported_at"`
+}
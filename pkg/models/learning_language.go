@@ -0,0 +1,48 @@
+package models
+
+// Коды изучаемых языков (ISO 639-1)
+const (
+	LanguageEnglish = "en"
+	LanguageSpanish = "es"
+	LanguageGerman  = "de"
+	LanguageFrench  = "fr"
+	LanguageItalian = "it"
+)
+
+// DefaultLearningLanguage язык, который изучают пользователи, не выбравшие другой
+const DefaultLearningLanguage = LanguageEnglish
+
+// LearningLanguage изучаемый язык с названиями в падежах для сообщений и промптов
+type LearningLanguage struct {
+	Code          string
+	Flag          string
+	Name          string // «Английский» — для кнопок и сообщений
+	Genitive      string // «английского» — учитель английского
+	Dative        string // «английскому» — обучаешь английскому
+	Accusative    string // «английский» — перевести на английский
+	Instrumental  string // «английским» — помогаешь с английским
+	Prepositional string // «английском» — на английском
+}
+
+// learningLanguages поддерживаемые изучаемые языки
+var learningLanguages = map[string]LearningLanguage{
+	LanguageEnglish: {Code: LanguageEnglish, Flag: "🇬🇧", Name: "Английский", Genitive: "английского", Dative: "английскому", Accusative: "английский", Instrumental: "английским", Prepositional: "английском"},
+	LanguageSpanish: {Code: LanguageSpanish, Flag: "🇪🇸", Name: "Испанский", Genitive: "испанского", Dative: "испанскому", Accusative: "испанский", Instrumental: "испанским", Prepositional: "испанском"},
+	LanguageGerman:  {Code: LanguageGerman, Flag: "🇩🇪", Name: "Немецкий", Genitive: "немецкого", Dative: "немецкому", Accusative: "немецкий", Instrumental: "немецким", Prepositional: "немецком"},
+	LanguageFrench:  {Code: LanguageFrench, Flag: "🇫🇷", Name: "Французский", Genitive: "французского", Dative: "французскому", Accusative: "французский", Instrumental: "французским", Prepositional: "французском"},
+	LanguageItalian: {Code: LanguageItalian, Flag: "🇮🇹", Name: "Итальянский", Genitive: "итальянского", Dative: "итальянскому", Accusative: "итальянский", Instrumental: "итальянским", Prepositional: "итальянском"},
+}
+
+// IsSupportedLearningLanguage проверяет, что бот умеет учить языку с этим кодом
+func IsSupportedLearningLanguage(code string) bool {
+	_, ok := learningLanguages[code]
+	return ok
+}
+
+// GetLearningLanguage возвращает изучаемый язык по коду; для неизвестного кода — английский
+func GetLearningLanguage(code string) LearningLanguage {
+	if language, ok := learningLanguages[code]; ok {
+		return language
+	}
+	return learningLanguages[DefaultLearningLanguage]
+}
@@ -0,0 +1,90 @@
+package models
+
+// Единые правила определения уровня.
+//
+// Уровень назначается двумя способами:
+//   - по результату теста уровня (процент правильных ответов):
+//     0–59% — beginner, 60–79% — intermediate, 80–100% — advanced;
+//   - по накопленному XP: 0–9 999 — beginner, 10 000–19 999 — intermediate,
+//     20 000+ — advanced.
+//
+// Оба способа согласованы через PromoteLevelByXP: XP может только повысить
+// уровень, но никогда не понижает уровень, подтвержденный тестом.
+
+// Constants для порогов теста уровня (в процентах правильных ответов)
+const (
+	TestPercentIntermediate = 60 // 60% и выше — intermediate
+	TestPercentAdvanced     = 80 // 80% и выше — advanced
+)
+
+// LevelRank возвращает порядковый номер уровня (0 — beginner) или -1 для неизвестного уровня
+func LevelRank(level string) int {
+	switch level {
+	case LevelBeginner:
+		return 0
+	case LevelIntermediate:
+		return 1
+	case LevelAdvanced:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// GetLevelByXP определяет уровень пользователя на основе его XP
+func GetLevelByXP(xp int) string {
+	switch {
+	case xp >= XPThresholdAdvanced:
+		return LevelAdvanced
+	case xp >= XPThresholdIntermediate:
+		return LevelIntermediate
+	default:
+		return LevelBeginner
+	}
+}
+
+// GetLevelByTestScore определяет рекомендуемый уровень по результату теста
+func GetLevelByTestScore(score, maxScore int) string {
+	if maxScore <= 0 || score <= 0 {
+		return LevelBeginner
+	}
+	// Целочисленная арифметика, чтобы границы не зависели от округления float
+	switch percent := score * 100; {
+	case percent >= TestPercentAdvanced*maxScore:
+		return LevelAdvanced
+	case percent >= TestPercentIntermediate*maxScore:
+		return LevelIntermediate
+	default:
+		return LevelBeginner
+	}
+}
+
+// PromoteLevelByXP возвращает уровень с учетом XP: выбирается более высокий
+// из текущего уровня и уровня по XP, поэтому уровень из теста не понижается
+func PromoteLevelByXP(current string, xp int) string {
+	byXP := GetLevelByXP(xp)
+	if LevelRank(current) >= LevelRank(byXP) {
+		return current
+	}
+	return byXP
+}
+
+// Способы определения стартового уровня
+const (
+	LevelAssessmentSelf    = "self"    // Пользователь оценил уровень сам
+	LevelAssessmentTest    = "test"    // Уровень подтвержден тестом
+	LevelAssessmentSkipped = "skipped" // Выбор пропущен, оставлен уровень по умолчанию
+)
+
+// LevelTestSuggestXP после какого XP оценившему уровень самостоятельно предлагается пройти тест
+const LevelTestSuggestXP = 150
+
+// IsValidLevelAssessment проверяет способ определения уровня
+func IsValidLevelAssessment(assessment string) bool {
+	switch assessment {
+	case LevelAssessmentSelf, LevelAssessmentTest, LevelAssessmentSkipped:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import "testing"
+
+func TestGetLevelByXP(t *testing.T) {
+	tests := []struct {
+		xp   int
+		want string
+	}{
+		{-10, LevelBeginner},
+		{0, LevelBeginner},
+		{XPThresholdIntermediate - 1, LevelBeginner},
+		{XPThresholdIntermediate, LevelIntermediate},
+		{XPThresholdAdvanced - 1, LevelIntermediate},
+		{XPThresholdAdvanced, LevelAdvanced},
+		{XPThresholdAdvanced * 5, LevelAdvanced},
+	}
+	for _, tt := range tests {
+		if got := GetLevelByXP(tt.xp); got != tt.want {
+			t.Errorf("GetLevelByXP(%d): ожидалось %s, получено %s", tt.xp, tt.want, got)
+		}
+	}
+}
+
+func TestGetLevelByTestScore(t *testing.T) {
+	tests := []struct {
+		score, maxScore int
+		want            string
+	}{
+		{0, 0, LevelBeginner},
+		{5, 0, LevelBeginner},
+		{0, 10, LevelBeginner},
+		{5, 10, LevelBeginner},
+		{59, 100, LevelBeginner},
+		{6, 10, LevelIntermediate},
+		{60, 100, LevelIntermediate},
+		{79, 100, LevelIntermediate},
+		{8, 10, LevelAdvanced},
+		{80, 100, LevelAdvanced},
+		{10, 10, LevelAdvanced},
+		// 3 из 5 ровно 60%: граница не должна зависеть от округления
+		{3, 5, LevelIntermediate},
+		{4, 5, LevelAdvanced},
+	}
+	for _, tt := range tests {
+		if got := GetLevelByTestScore(tt.score, tt.maxScore); got != tt.want {
+			t.Errorf("GetLevelByTestScore(%d, %d): ожидалось %s, получено %s", tt.score, tt.maxScore, tt.want, got)
+		}
+	}
+}
+
+func TestPromoteLevelByXP(t *testing.T) {
+	tests := []struct {
+		current string
+		xp      int
+		want    string
+	}{
+		{LevelBeginner, 0, LevelBeginner},
+		{LevelBeginner, XPThresholdIntermediate, LevelIntermediate},
+		{LevelIntermediate, XPThresholdAdvanced, LevelAdvanced},
+		// Уровень из теста не понижается из-за малого XP
+		{LevelAdvanced, 50, LevelAdvanced},
+		{LevelIntermediate, 0, LevelIntermediate},
+		// Неизвестный уровень заменяется уровнем по XP
+		{"", 0, LevelBeginner},
+		{"unknown", XPThresholdAdvanced, LevelAdvanced},
+	}
+	for _, tt := range tests {
+		if got := PromoteLevelByXP(tt.current, tt.xp); got != tt.want {
+			t.Errorf("PromoteLevelByXP(%q, %d): ожидалось %s, получено %s", tt.current, tt.xp, tt.want, got)
+		}
+	}
+}
+
+func TestLevelRankOrdersAllLevels(t *testing.T) {
+	levels := []string{LevelBeginner, LevelIntermediate, LevelAdvanced}
+	for i := 1; i < len(levels); i++ {
+		if LevelRank(levels[i-1]) >= LevelRank(levels[i]) {
+			t.Errorf("уровень %s должен быть ниже %s", levels[i-1], levels[i])
+		}
+	}
+	if LevelRank("unknown") != -1 {
+		t.Error("неизвестный уровень должен иметь ранг -1")
+	}
+}
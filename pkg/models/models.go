@@ -26,9 +26,23 @@ type User struct {
 	ReferralCode      *string    `json:"referral_code" db:"referral_code"`             // Уникальный реферальный код
 	ReferralCount     int        `json:"referral_count" db:"referral_count"`           // Количество приглашенных пользователей
 
-	ReferredBy *int64    `json:"referred_by" db:"referred_by"` // ID пользователя, который пригласил
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ReferredBy             *int64     `json:"referred_by" db:"referred_by"`                           // ID пользователя, который пригласил
+	ExerciseDifficultyBias int        `json:"exercise_difficulty_bias" db:"exercise_difficulty_bias"` // Смещение сложности упражнений (-2..+2)
+	OnboardingCompletedAt  *time.Time `json:"onboarding_completed_at" db:"onboarding_completed_at"`   // Когда впервые пройден тур по боту
+	ReferralRewardMonths   int        `json:"referral_reward_months" db:"referral_reward_months"`     // Сколько месяцев премиума получено за рефералов
+	LevelSelectedAt        *time.Time `json:"level_selected_at" db:"level_selected_at"`               // Когда выбран стартовый уровень при первом запуске
+	LevelAssessment        string     `json:"level_assessment" db:"level_assessment"`                 // Как определен стартовый уровень: self, test или skipped
+	LearningLanguage       string     `json:"learning_language" db:"learning_language"`               // Код изучаемого языка (en, es, de, ...)
+	NewCardsPerDay         int        `json:"new_cards_per_day" db:"new_cards_per_day"`               // Сколько новых карточек в день начинать (темп /pace)
+	WeeklyWordTarget       int        `json:"weekly_word_target" db:"weekly_word_target"`             // Сколько слов выучить за неделю (0 — цель не задана)
+	RemindersEnabled       bool       `json:"reminders_enabled" db:"reminders_enabled"`               // Получать ежедневное напоминание о занятиях
+	FlashcardCategory      string     `json:"flashcard_category" db:"flashcard_category"`             // Последняя выбранная тема карточек ("" — все темы)
+	Timezone               string     `json:"timezone" db:"timezone"`                                 // Часовой пояс IANA для дневных границ ("" — пояс DAILY_RESET_TZ)
+	StreakWarningSentAt    *time.Time `json:"streak_warning_sent_at" db:"streak_warning_sent_at"`     // Когда последний раз отправлено предупреждение о серии (читается только для рассылки)
+	DailyReminderSentAt    *time.Time `json:"daily_reminder_sent_at" db:"daily_reminder_sent_at"`     // Когда последний раз отправлено ежедневное напоминание (читается только для рассылки)
+	WordOfDaySentAt        *time.Time `json:"word_of_day_sent_at" db:"word_of_day_sent_at"`           // Когда последний раз отправлено слово дня (читается только для рассылки)
+	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // UserMessage представляет сообщение в диалоге
@@ -46,6 +60,9 @@ type UserStats struct {
 	TotalXP       int       `json:"total_xp" db:"total_xp"`
 	StudyStreak   int       `json:"study_streak" db:"study_streak"` // дни подряд
 	LastStudyDate time.Time `json:"last_study_date" db:"last_study_date"`
+
+	ExerciseAnswers int `json:"exercise_answers" db:"exercise_answers"` // Сколько раз пользователь ответил на упражнения
+	ExerciseCorrect int `json:"exercise_correct" db:"exercise_correct"` // Сколько из них верно
 }
 
 // CreateUserRequest представляет запрос на создание пользователя
@@ -169,8 +186,34 @@ const (
 	XPThresholdBeginner     = 0     // 0 - 9,999 XP
 	XPThresholdIntermediate = 10000 // 10,000 - 19,999 XP
 	XPThresholdAdvanced     = 20000 // 20,000+ XP
+
+	XPMilestoneStep = 10000 // Шаг рубежей общего XP после максимального ранга
+)
+
+// Constants для смещения сложности упражнений
+const (
+	MinExerciseDifficultyBias = -2 // Упражнения заметно проще уровня
+	MaxExerciseDifficultyBias = 2  // Упражнения заметно сложнее уровня
 )
 
+// Constants для темпа изучения новых карточек
+const (
+	DefaultNewCardsPerDay = 10 // Темп по умолчанию
+	MinNewCardsPerDay     = 1
+	MaxNewCardsPerDay     = 50
+)
+
+// NewCardPaceOptions варианты темпа, которые предлагаются кнопками
+var NewCardPaceOptions = []int{5, 10, 20}
+
+// IsValidNewCardsPerDay проверяет, что темп новых карточек в допустимых пределах
+func IsValidNewCardsPerDay(n int) bool {
+	return n >= MinNewCardsPerDay && n <= MaxNewCardsPerDay
+}
+
+// OnboardingBonusXP бонус за первое прохождение тура по боту
+const OnboardingBonusXP = 20
+
 // Constants для ролей сообщений
 const (
 	RoleUser      = "user"
@@ -227,27 +270,39 @@ type UserFlashcard struct {
 	LastReviewedAt *time.Time `json:"last_reviewed_at" db:"last_reviewed_at"`
 	NextReviewAt   time.Time  `json:"next_review_at" db:"next_review_at"` // Когда нужно повторить
 	IsLearned      bool       `json:"is_learned" db:"is_learned"`         // Выучено ли слово
+	EasyStreak     int        `json:"easy_streak" db:"easy_streak"`       // Сколько раз подряд карточка отмечена "легко"
+	LearnedAt      *time.Time `json:"learned_at" db:"learned_at"`         // Когда слово стало выученным
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 
+	// Лучшая оценка произношения в процентах (nil — произношение не проверялось)
+	BestPronunciationScore *int `json:"best_pronunciation_score,omitempty" db:"best_pronunciation_score"`
+
 	// Связанная карточка (для JOIN запросов)
 	Flashcard *Flashcard `json:"flashcard,omitempty" db:"-"`
 }
 
 // FlashcardSession представляет сессию изучения карточек
 type FlashcardSession struct {
-	UserID         int64           `json:"user_id"`
-	CurrentCard    *UserFlashcard  `json:"current_card"`
-	CardsToReview  []UserFlashcard `json:"cards_to_review"`
-	SessionStarted time.Time       `json:"session_started"`
-	CardsCompleted int             `json:"cards_completed"`
-	CorrectAnswers int             `json:"correct_answers"`
+	UserID           int64           `json:"user_id"`
+	CurrentCard      *UserFlashcard  `json:"current_card"`
+	CardsToReview    []UserFlashcard `json:"cards_to_review"`
+	SessionStarted   time.Time       `json:"session_started"`
+	CardsCompleted   int             `json:"cards_completed"`
+	CorrectAnswers   int             `json:"correct_answers"`
+	ExampleRefreshes int             `json:"example_refreshes"`        // Сколько новых примеров сгенерировано AI за сессию
+	RelearnCounts    map[int64]int   `json:"relearn_counts,omitempty"` // Сколько раз карточка возвращена в сессию после ошибки
 }
 
 // FlashcardAnswer представляет ответ пользователя на карточку
 type FlashcardAnswer struct {
-	IsCorrect    bool          `json:"is_correct"`
-	Difficulty   int           `json:"difficulty"`     // Новая сложность (1-5)
-	NextReviewIn time.Duration `json:"next_review_in"` // Через сколько повторить
+	IsCorrect         bool           `json:"is_correct"`
+	Difficulty        int            `json:"difficulty"`          // Новая сложность (1-5)
+	NextReviewIn      time.Duration  `json:"next_review_in"`      // Через сколько повторить
+	FastTracked       bool           `json:"fast_tracked"`        // Карточка выучена досрочно после серии "легко"
+	HasMoreCards      bool           `json:"has_more_cards"`      // В сессии остались карточки; false — сессия завершена
+	NextCard          *UserFlashcard `json:"next_card,omitempty"` // Следующая карточка сессии, если она есть
+	RequeuedInSession bool           `json:"requeued_in_session"` // Карточка с ошибкой будет показана еще раз в этой сессии
+	Learned           bool           `json:"learned"`             // Слово стало выученным после этого ответа
 }
 
 // IsValidState проверяет корректность состояния пользователя
@@ -260,17 +315,6 @@ func IsValidState(state string) bool {
 	}
 }
 
-// GetLevelByXP определяет уровень пользователя на основе его XP
-func GetLevelByXP(xp int) string {
-	if xp >= XPThresholdAdvanced {
-		return LevelAdvanced
-	} else if xp >= XPThresholdIntermediate {
-		return LevelIntermediate
-	} else {
-		return LevelBeginner
-	}
-}
-
 // GetXPForNextLevel возвращает количество XP до следующего уровня
 func GetXPForNextLevel(currentXP int) (int, string) {
 	currentLevel := GetLevelByXP(currentXP)
@@ -287,6 +331,14 @@ func GetXPForNextLevel(currentXP int) (int, string) {
 	}
 }
 
+// GetNextXPMilestone возвращает следующий рубеж общего XP (кратный XPMilestoneStep)
+func GetNextXPMilestone(xp int) int {
+	if xp < 0 {
+		xp = 0
+	}
+	return (xp/XPMilestoneStep + 1) * XPMilestoneStep
+}
+
 // GetLevelProgress возвращает прогресс в текущем уровне (в процентах)
 func GetLevelProgress(xp int) float64 {
 	currentLevel := GetLevelByXP(xp)
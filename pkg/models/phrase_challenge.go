@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// PhraseChallengeDay результат пользователя в челлендже «Фраза дня» за один день
+type PhraseChallengeDay struct {
+	Date      time.Time `json:"date" db:"challenge_date"`
+	BestScore float64   `json:"best_score" db:"best_score"` // Лучшая оценка произношения за день (0..1)
+	Attempts  int       `json:"attempts" db:"attempts"`
+}
+
+// PhraseChallengeStats сводка участия пользователя в челлендже
+type PhraseChallengeStats struct {
+	PlayedToday bool    `json:"played_today"`
+	TodayBest   float64 `json:"today_best"`
+	Streak      int     `json:"streak"`      // Сколько дней подряд пользователь произносит фразу дня хорошо
+	DaysPlayed  int     `json:"days_played"` // В скольких из последних дней пользователь участвовал
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PlatformStats средние показатели активных учеников для сравнения в /compare
+type PlatformStats struct {
+	Users     int     `json:"users"`
+	AvgXP     float64 `json:"avg_xp"`
+	AvgStreak float64 `json:"avg_streak"`
+	AvgWords  float64 `json:"avg_words"`
+
+	// Децили: значения показателя, ниже которых 10%, 20%, ..., 90% учеников
+	XPDeciles     []int `json:"xp_deciles"`
+	StreakDeciles []int `json:"streak_deciles"`
+	WordsDeciles  []int `json:"words_deciles"`
+
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// PercentileRank возвращает, у какой доли учеников (в процентах, с шагом 10) показатель меньше value
+func PercentileRank(deciles []int, value int) int {
+	rank := 0
+	for i, cutoff := range deciles {
+		if value > cutoff {
+			rank = (i + 1) * 10
+		}
+	}
+	return rank
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestPercentileRank(t *testing.T) {
+	deciles := []int{0, 5, 10, 20, 40, 60, 100, 150, 300}
+	tests := []struct {
+		value int
+		want  int
+	}{
+		{0, 0},
+		{1, 10},
+		{10, 20},
+		{41, 50},
+		{300, 80},
+		{301, 90},
+	}
+	for _, tt := range tests {
+		if got := PercentileRank(deciles, tt.value); got != tt.want {
+			t.Errorf("PercentileRank(%d): ожидалось %d, получено %d", tt.value, tt.want, got)
+		}
+	}
+
+	if got := PercentileRank(nil, 100); got != 0 {
+		t.Errorf("без данных ожидалось 0, получено %d", got)
+	}
+}
@@ -39,6 +39,8 @@ type ReferralStats struct {
 	CompletedReferrals int `json:"completed_referrals"`
 	PendingReferrals   int `json:"pending_referrals"`
 	ReferralsToPremium int `json:"referrals_to_premium"`
+	RewardsEarned      int `json:"rewards_earned"` // Сколько месяцев премиума уже получено за рефералов
+	MaxRewards         int `json:"max_rewards"`    // Лимит наград за рефералов за все время
 }
 
 // ReferralRequest представляет запрос на создание реферала
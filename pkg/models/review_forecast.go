@@ -0,0 +1,11 @@
+package models
+
+// ReviewForecastDays на сколько дней вперед строится прогноз повторений, включая сегодня
+const ReviewForecastDays = 7
+
+// ReviewForecast сколько карточек подойдет к повторению в ближайшие дни
+type ReviewForecast struct {
+	Today    int `json:"today"`    // К повторению сегодня, включая просроченные
+	Tomorrow int `json:"tomorrow"` // Подойдут к повторению завтра
+	Week     int `json:"week"`     // Всего за ReviewForecastDays дней, включая сегодня и завтра
+}
@@ -0,0 +1,13 @@
+package models
+
+// Кнопки и ссылка предупреждения о серии под угрозой
+const (
+	StreakWarningOffCallback    = "streakwarn_off"    // больше не предупреждать
+	StreakWarningSnoozeCallback = "streakwarn_snooze" // не предупреждать StreakWarningSnoozeDays дней
+
+	// QuickStudyStartParam параметр ссылки t.me/<бот>?start=quick, открывающей быстрое упражнение
+	QuickStudyStartParam = "quick"
+)
+
+// StreakWarningSnoozeDays на сколько дней откладываются предупреждения о серии
+const StreakWarningSnoozeDays = 7
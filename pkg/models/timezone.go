@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// timezoneCache уже загруженные часовые пояса: time.LoadLocation каждый раз читает базу tz
+var timezoneCache sync.Map
+
+// LoadTimezone загружает часовой пояс по имени из базы IANA (например, Europe/Moscow).
+// Пустое имя и Local не принимаются: пояс сервера пользователь не выбирает.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("неизвестный часовой пояс")
+	}
+	if loc, ok := timezoneCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timezoneCache.Store(name, loc)
+	return loc, nil
+}
+
+// Location возвращает часовой пояс пользователя, а если он не задан — fallback
+func (u *User) Location(fallback *time.Location) *time.Location {
+	if u.Timezone == "" {
+		return fallback
+	}
+	loc, err := LoadTimezone(u.Timezone)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// VocabularyTrend рост словарного запаса: всего выученных слов и сколько выучено по неделям
+type VocabularyTrend struct {
+	Total int              `json:"total"`
+	Weeks []VocabularyWeek `json:"weeks"` // от самой ранней недели к текущей
+}
+
+// VocabularyWeek сколько слов выучено за неделю, начинающуюся в понедельник Start
+type VocabularyWeek struct {
+	Start   time.Time `json:"start"`
+	Learned int       `json:"learned"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Constants для недельной цели по выученным словам
+const (
+	MinWeeklyWordTarget = 5
+	MaxWeeklyWordTarget = 200
+
+	// WeeklyTargetBonusXP бонус за выполнение недельной цели
+	WeeklyTargetBonusXP = 50
+)
+
+// WeeklyTargetOptions варианты недельной цели, которые предлагаются кнопками
+var WeeklyTargetOptions = []int{10, 20, 30}
+
+// IsValidWeeklyWordTarget проверяет, что недельная цель в допустимых пределах
+func IsValidWeeklyWordTarget(n int) bool {
+	return n >= MinWeeklyWordTarget && n <= MaxWeeklyWordTarget
+}
+
+// WeeklyTargetProgress прогресс недельной цели по выученным словам
+type WeeklyTargetProgress struct {
+	Target    int       `json:"target"`     // Цель на неделю (0 — не задана)
+	Learned   int       `json:"learned"`    // Сколько слов выучено с начала недели
+	WeekStart time.Time `json:"week_start"` // Полночь понедельника текущей недели
+	WeekEnd   time.Time `json:"week_end"`   // Полночь следующего понедельника
+}
+
+// HasTarget сообщает, задана ли цель
+func (p *WeeklyTargetProgress) HasTarget() bool {
+	return p.Target > 0
+}
+
+// Completed сообщает, выполнена ли заданная цель
+func (p *WeeklyTargetProgress) Completed() bool {
+	return p.HasTarget() && p.Learned >= p.Target
+}
+
+// Remaining сколько слов осталось выучить до цели
+func (p *WeeklyTargetProgress) Remaining() int {
+	return max(p.Target-p.Learned, 0)
+}
@@ -0,0 +1,4 @@
+package models
+
+// WordOfDayTTSCallbackPrefix кнопка озвучки под словом дня: word_of_day_tts_<ID карточки>
+const WordOfDayTTSCallbackPrefix = "word_of_day_tts_"
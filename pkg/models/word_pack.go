@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// WordPack представляет тематический набор слов (например, "Travel Essentials")
+type WordPack struct {
+	ID          int64     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Level       string    `json:"level" db:"level"`
+	Category    string    `json:"category" db:"category"`
+	WordsCount  int       `json:"words_count" db:"words_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// WordPackProgress представляет прогресс пользователя по принятому набору
+type WordPackProgress struct {
+	Pack         WordPack  `json:"pack"`
+	LearnedCount int       `json:"learned_count"`
+	AcceptedAt   time.Time `json:"accepted_at"`
+}
+
+// IsCompleted проверяет, выучены ли все слова набора
+func (p *WordPackProgress) IsCompleted() bool {
+	return p.Pack.WordsCount > 0 && p.LearnedCount >= p.Pack.WordsCount
+}
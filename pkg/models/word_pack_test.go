@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestWordPackProgressIsCompleted(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   int
+		learned int
+		want    bool
+	}{
+		{"ничего не выучено", 10, 0, false},
+		{"выучена часть", 10, 9, false},
+		{"выучены все слова", 10, 10, true},
+		{"слова выучены повторно", 10, 12, true},
+		{"пустой набор не считается пройденным", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &WordPackProgress{Pack: WordPack{WordsCount: tt.words}, LearnedCount: tt.learned}
+			if got := p.IsCompleted(); got != tt.want {
+				t.Errorf("IsCompleted() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
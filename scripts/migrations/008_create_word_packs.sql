@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Создание таблицы тематических наборов слов
+CREATE TABLE IF NOT EXISTS word_packs (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    level VARCHAR(20) NOT NULL DEFAULT 'beginner',
+    category VARCHAR(50) NOT NULL DEFAULT 'general',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(name, level)
+);
+
+-- Слова, входящие в набор
+CREATE TABLE IF NOT EXISTS word_pack_items (
+    pack_id BIGINT NOT NULL REFERENCES word_packs(id) ON DELETE CASCADE,
+    flashcard_id BIGINT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    PRIMARY KEY (pack_id, flashcard_id)
+);
+
+-- Наборы, принятые пользователями
+CREATE TABLE IF NOT EXISTS user_word_packs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pack_id BIGINT NOT NULL REFERENCES word_packs(id) ON DELETE CASCADE,
+    accepted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, pack_id)
+);
+
+-- Создание индексов для оптимизации
+CREATE INDEX IF NOT EXISTS idx_word_packs_level ON word_packs(level);
+CREATE INDEX IF NOT EXISTS idx_word_pack_items_flashcard_id ON word_pack_items(flashcard_id);
+CREATE INDEX IF NOT EXISTS idx_user_word_packs_user_id ON user_word_packs(user_id);
+
+-- Начальные наборы: по одному на каждую тематическую категорию и уровень
+INSERT INTO word_packs (name, description, level, category)
+SELECT DISTINCT
+    CASE category
+        WHEN 'travel' THEN 'Travel Essentials'
+        WHEN 'food' THEN 'Food & Drinks'
+        WHEN 'business' THEN 'Business English'
+        WHEN 'technology' THEN 'Tech Talk'
+        WHEN 'education' THEN 'Study Time'
+        WHEN 'health' THEN 'Health & Body'
+    END,
+    CASE category
+        WHEN 'travel' THEN 'Слова для путешествий: транспорт, отели, ориентирование'
+        WHEN 'food' THEN 'Еда, напитки и походы в ресторан'
+        WHEN 'business' THEN 'Лексика для работы и деловой переписки'
+        WHEN 'technology' THEN 'Технологии, гаджеты и интернет'
+        WHEN 'education' THEN 'Учеба, школа и университет'
+        WHEN 'health' THEN 'Здоровье, тело и визит к врачу'
+    END,
+    level,
+    category
+FROM flashcards
+WHERE category IN ('travel', 'food', 'business', 'technology', 'education', 'health')
+ON CONFLICT (name, level) DO NOTHING;
+
+INSERT INTO word_pack_items (pack_id, flashcard_id)
+SELECT wp.id, f.id
+FROM word_packs wp
+JOIN flashcards f ON f.level = wp.level AND f.category = wp.category
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS user_word_packs;
+DROP TABLE IF EXISTS word_pack_items;
+DROP TABLE IF EXISTS word_packs;
+
+-- +goose StatementEnd
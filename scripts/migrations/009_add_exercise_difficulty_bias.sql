@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Смещение сложности упражнений по отзывам пользователя (-2 проще ... +2 сложнее)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_difficulty_bias INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE users ADD CONSTRAINT chk_exercise_difficulty_bias
+    CHECK (exercise_difficulty_bias >= -2 AND exercise_difficulty_bias <= 2);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_exercise_difficulty_bias;
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_difficulty_bias;
+
+-- +goose StatementEnd
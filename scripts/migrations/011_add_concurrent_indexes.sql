@@ -0,0 +1,29 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- Индексы для больших таблиц строятся CONCURRENTLY, чтобы не блокировать запись в продакшене.
+-- CREATE INDEX CONCURRENTLY нельзя выполнять внутри транзакции, поэтому миграция
+-- помечена NO TRANSACTION и каждая команда выполняется отдельно.
+-- Прерванная сборка оставляет невалидный индекс, который IF NOT EXISTS бы пропустил,
+-- поэтому перед созданием индекс удаляется: повторный запуск миграции безопасен.
+
+-- Полнотекстовый поиск по истории сообщений
+DROP INDEX CONCURRENTLY IF EXISTS idx_user_messages_content_fts;
+CREATE INDEX CONCURRENTLY idx_user_messages_content_fts
+    ON user_messages USING GIN (to_tsvector('simple', content));
+
+-- История платежей пользователя, новые сверху
+DROP INDEX CONCURRENTLY IF EXISTS idx_payments_user_created;
+CREATE INDEX CONCURRENTLY idx_payments_user_created
+    ON payments (user_id, created_at DESC);
+
+-- Выборка карточек к повторению: только невыученные
+DROP INDEX CONCURRENTLY IF EXISTS idx_user_flashcards_due;
+CREATE INDEX CONCURRENTLY idx_user_flashcards_due
+    ON user_flashcards (user_id, next_review_at)
+    WHERE is_learned = FALSE;
+
+-- +goose Down
+DROP INDEX CONCURRENTLY IF EXISTS idx_user_flashcards_due;
+DROP INDEX CONCURRENTLY IF EXISTS idx_payments_user_created;
+DROP INDEX CONCURRENTLY IF EXISTS idx_user_messages_content_fts;
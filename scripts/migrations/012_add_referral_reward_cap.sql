@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сколько месяцев премиума пользователь уже получил за рефералов (ограничено REFERRAL_MAX_REWARDS)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_reward_months INTEGER NOT NULL DEFAULT 0;
+
+-- Премиум за рефералов теперь начисляет приложение с учетом лимита наград,
+-- триггер только пересчитывает счетчик завершенных рефералов
+CREATE OR REPLACE FUNCTION trigger_check_referral_premium() 
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'completed' AND (OLD.status IS NULL OR OLD.status != 'completed') THEN
+        UPDATE users 
+        SET referral_count = (
+            SELECT COUNT(*) 
+            FROM referrals 
+            WHERE referrer_id = NEW.referrer_id AND status = 'completed'
+        )
+        WHERE id = NEW.referrer_id;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+CREATE OR REPLACE FUNCTION trigger_check_referral_premium() 
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'completed' AND (OLD.status IS NULL OR OLD.status != 'completed') THEN
+        PERFORM check_referral_premium(NEW.referrer_id);
+        
+        UPDATE users 
+        SET referral_count = (
+            SELECT COUNT(*) 
+            FROM referrals 
+            WHERE referrer_id = NEW.referrer_id AND status = 'completed'
+        )
+        WHERE id = NEW.referrer_id;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE users DROP COLUMN IF EXISTS referral_reward_months;
+
+-- +goose StatementEnd
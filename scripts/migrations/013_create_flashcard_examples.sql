@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Альтернативные примеры употребления слов, сгенерированные AI по кнопке «Новый пример»
+CREATE TABLE IF NOT EXISTS flashcard_examples (
+    id BIGSERIAL PRIMARY KEY,
+    flashcard_id BIGINT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    example TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (flashcard_id, example)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flashcard_examples_flashcard_id ON flashcard_examples(flashcard_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS flashcard_examples;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Когда пользователь сам выбрал стартовый уровень при первом запуске (NULL — не выбирал)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS level_selected_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS level_selected_at;
+
+-- +goose StatementEnd
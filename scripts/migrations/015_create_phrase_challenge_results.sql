@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Результаты ежедневного челленджа «Фраза дня»: лучшая оценка произношения за день
+CREATE TABLE IF NOT EXISTS phrase_challenge_results (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    challenge_date DATE NOT NULL,
+    best_score REAL NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, challenge_date)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS phrase_challenge_results;
+
+-- +goose StatementEnd
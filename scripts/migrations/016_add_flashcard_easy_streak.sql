@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сколько раз подряд пользователь отметил карточку как "легко" (для быстрого перевода в выученные)
+ALTER TABLE user_flashcards ADD COLUMN IF NOT EXISTS easy_streak INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE user_flashcards DROP COLUMN IF EXISTS easy_streak;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сколько новых словарных карточек пользователь хочет начинать в день (команда /pace)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS new_cards_per_day INTEGER NOT NULL DEFAULT 10;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS new_cards_per_day;
+
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Частотный ранг слова внутри уровня (1 — самое употребимое, 0 — ранг неизвестен).
+-- Используется, чтобы вводить новые слова от частых к редким.
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS frequency_rank INTEGER NOT NULL DEFAULT 0;
+
+-- Ранг заполняется из частотного списка слов (например, UPDATE по списку частот корпуса).
+-- Порядок добавления карточек с частотой не связан, поэтому изначально ранг не выставляется:
+-- карточки без ранга вводятся после ранжированных, чередуя категории.
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_level_frequency ON flashcards(level, frequency_rank);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_flashcards_level_frequency;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS frequency_rank;
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Карточки, снятые с выдачи до проверки администратором
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT false;
+
+-- Жалобы пользователей на ошибки в карточках
+CREATE TABLE IF NOT EXISTS flashcard_reports (
+    id BIGSERIAL PRIMARY KEY,
+    flashcard_id BIGINT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolution VARCHAR(20)
+);
+
+-- Пока жалоба не рассмотрена, повторная жалоба того же пользователя не учитывается
+CREATE UNIQUE INDEX IF NOT EXISTS idx_flashcard_reports_open
+    ON flashcard_reports(flashcard_id, user_id) WHERE resolved_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS flashcard_reports;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS suspended;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Вечернее предупреждение о том, что серия занятий под угрозой
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_warnings_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_warnings_snoozed_until TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_warning_sent_at TIMESTAMP NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS streak_warning_sent_at;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_warnings_snoozed_until;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_warnings_enabled;
+
+-- +goose StatementEnd
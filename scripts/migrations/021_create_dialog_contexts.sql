@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Контекст диалога (краткое содержание и последние реплики), переживающий перезапуск бота
+CREATE TABLE IF NOT EXISTS dialog_contexts (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    summary TEXT NOT NULL DEFAULT '',
+    messages JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS dialog_contexts;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Момент, когда слово стало выученным: из него строится динамика словарного запаса
+ALTER TABLE user_flashcards ADD COLUMN IF NOT EXISTS learned_at TIMESTAMP WITHOUT TIME ZONE;
+
+-- Для уже выученных слов точный момент неизвестен, берем последнее повторение
+UPDATE user_flashcards
+SET learned_at = COALESCE(last_reviewed_at, created_at)
+WHERE is_learned = TRUE AND learned_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_flashcards_user_learned_at
+    ON user_flashcards(user_id, learned_at) WHERE learned_at IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_user_flashcards_user_learned_at;
+ALTER TABLE user_flashcards DROP COLUMN IF EXISTS learned_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Недельная цель по выученным словам (0 — цель не задана)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS weekly_word_target INTEGER NOT NULL DEFAULT 0;
+-- Когда цель последний раз выполнена и когда напомнили о невыполненной цели
+ALTER TABLE users ADD COLUMN IF NOT EXISTS weekly_target_completed_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS weekly_target_reminded_at TIMESTAMP NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_target_reminded_at;
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_target_completed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_word_target;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Точность ответов на упражнения-квизы: всего и в текущем окне адаптации сложности
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_answers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_correct INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_window_answers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_window_correct INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_window_correct;
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_window_answers;
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_correct;
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_answers;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Для какого срока окончания премиума отправлены напоминание о продлении и уведомление
+-- об окончании: после продления срок меняется, и напоминания снова становятся возможны
+ALTER TABLE users ADD COLUMN IF NOT EXISTS premium_reminded_for TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS premium_expired_notified_for TIMESTAMP NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS premium_expired_notified_for;
+ALTER TABLE users DROP COLUMN IF EXISTS premium_reminded_for;
+
+-- +goose StatementEnd
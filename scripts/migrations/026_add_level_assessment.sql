@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Как определен стартовый уровень: self — оценил сам, test — по тесту, skipped — выбор пропущен ('' — не выбирал)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS level_assessment VARCHAR(16) NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS level_assessment;
+
+-- +goose StatementEnd
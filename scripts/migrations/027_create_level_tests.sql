@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Незавершенный тест уровня: вопросы, ответы и текущий вопрос переживают перезапуск бота
+CREATE TABLE IF NOT EXISTS level_tests (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_question INTEGER NOT NULL DEFAULT 0,
+    questions JSONB NOT NULL DEFAULT '[]',
+    answers JSONB NOT NULL DEFAULT '[]',
+    score INTEGER NOT NULL DEFAULT 0,
+    max_score INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS level_tests;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Изучаемый язык (код ISO 639-1); у существующих пользователей остается английский
+ALTER TABLE users ADD COLUMN IF NOT EXISTS learning_language VARCHAR(8) NOT NULL DEFAULT 'en';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS learning_language;
+
+-- +goose StatementEnd
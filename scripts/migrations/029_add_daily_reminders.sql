@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Ежедневное напоминание о занятиях в фиксированный час
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reminders_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_reminder_sent_at TIMESTAMP NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS daily_reminder_sent_at;
+ALTER TABLE users DROP COLUMN IF EXISTS reminders_enabled;
+
+-- +goose StatementEnd
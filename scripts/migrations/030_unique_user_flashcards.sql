@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Прогресс по карточке у пользователя один: на этом ограничении держится
+-- INSERT ... ON CONFLICT при параллельном старте сессий карточек.
+-- В базах, где ограничение из 002 не было создано, сначала убираем дубликаты.
+DELETE FROM user_flashcards uf
+USING user_flashcards older
+WHERE uf.user_id = older.user_id
+  AND uf.flashcard_id = older.flashcard_id
+  AND uf.id > older.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_flashcards_user_id_flashcard_id_key
+    ON user_flashcards (user_id, flashcard_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Ограничение объявлено еще в 002_create_flashcards и на нем держится CreateUserFlashcard,
+-- поэтому откат его не удаляет
+SELECT 1;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Единый дневной лимит бесплатного тарифа (premium.DefaultFreeMessageLimit):
+-- после окончания премиума пользователям раньше возвращалось 50 сообщений вместо 7
+ALTER TABLE users ALTER COLUMN max_messages SET DEFAULT 7;
+UPDATE users SET max_messages = 7 WHERE is_premium = FALSE AND max_messages = 50;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users ALTER COLUMN max_messages SET DEFAULT 15;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Лучшая оценка произношения слова в процентах (NULL — произношение не проверялось)
+ALTER TABLE user_flashcards ADD COLUMN IF NOT EXISTS best_pronunciation_score INTEGER;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE user_flashcards DROP COLUMN IF EXISTS best_pronunciation_score;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Последняя выбранная категория карточек; пустая строка — все категории
+ALTER TABLE users ADD COLUMN IF NOT EXISTS flashcard_category VARCHAR(50) NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS flashcard_category;
+
+-- +goose StatementEnd
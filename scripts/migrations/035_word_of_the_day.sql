@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Слово дня: когда пользователю последний раз отправлено слово
+ALTER TABLE users ADD COLUMN IF NOT EXISTS word_of_day_sent_at TIMESTAMP NULL;
+
+-- Отправленные слова дня, чтобы одно слово не повторялось слишком часто
+CREATE TABLE IF NOT EXISTS sent_words (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    flashcard_id BIGINT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, flashcard_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS sent_words;
+ALTER TABLE users DROP COLUMN IF EXISTS word_of_day_sent_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Откуда карточка попала к пользователю: session — новая карточка в занятии,
+-- pack — импорт набора слов, custom — добавлена вручную через /find или свое слово.
+-- Дневной лимит новых карточек считает только session.
+ALTER TABLE user_flashcards ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'session';
+ALTER TABLE user_flashcards ADD CONSTRAINT chk_user_flashcard_source CHECK (source IN ('session', 'pack', 'custom'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE user_flashcards DROP CONSTRAINT IF EXISTS chk_user_flashcard_source;
+ALTER TABLE user_flashcards DROP COLUMN IF EXISTS source;
+
+-- +goose StatementEnd
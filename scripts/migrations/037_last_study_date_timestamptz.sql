@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- last_study_date хранился без пояса во времени сервера бота. Переводим колонку в
+-- TIMESTAMPTZ, чтобы дни серии одинаково считались в поясе пользователя или DAILY_RESET_TZ.
+-- Старые значения считаются записанными в поясе сессии базы: бот и база в docker-compose
+-- работают в одном поясе. Если пояса различаются, задайте его перед миграцией:
+-- PGTZ=Europe/Moscow go run ./cmd/migrate up
+ALTER TABLE users
+    ALTER COLUMN last_study_date TYPE TIMESTAMP WITH TIME ZONE
+    USING last_study_date AT TIME ZONE current_setting('TimeZone');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    ALTER COLUMN last_study_date TYPE TIMESTAMP WITHOUT TIME ZONE
+    USING last_study_date AT TIME ZONE current_setting('TimeZone');
+
+-- +goose StatementEnd
@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сроки премиума, отметки рассылок и даты повторения карточек хранились без пояса, и
+-- их сравнение с границами суток в поясе пользователя зависело от пояса сервера.
+-- Переводим колонки в TIMESTAMPTZ, как last_study_date в 037: старые значения считаются
+-- записанными в поясе сессии базы (при необходимости задайте PGTZ перед миграцией).
+ALTER TABLE users
+    ALTER COLUMN premium_expires_at TYPE TIMESTAMP WITH TIME ZONE
+        USING premium_expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN premium_reminded_for TYPE TIMESTAMP WITH TIME ZONE
+        USING premium_reminded_for AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN premium_expired_notified_for TYPE TIMESTAMP WITH TIME ZONE
+        USING premium_expired_notified_for AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN streak_warning_sent_at TYPE TIMESTAMP WITH TIME ZONE
+        USING streak_warning_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN streak_warnings_snoozed_until TYPE TIMESTAMP WITH TIME ZONE
+        USING streak_warnings_snoozed_until AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN daily_reminder_sent_at TYPE TIMESTAMP WITH TIME ZONE
+        USING daily_reminder_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN word_of_day_sent_at TYPE TIMESTAMP WITH TIME ZONE
+        USING word_of_day_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN weekly_target_completed_at TYPE TIMESTAMP WITH TIME ZONE
+        USING weekly_target_completed_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN weekly_target_reminded_at TYPE TIMESTAMP WITH TIME ZONE
+        USING weekly_target_reminded_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE user_flashcards
+    ALTER COLUMN next_review_at TYPE TIMESTAMP WITH TIME ZONE
+        USING next_review_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN learned_at TYPE TIMESTAMP WITH TIME ZONE
+        USING learned_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE
+        USING created_at AT TIME ZONE current_setting('TimeZone');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE user_flashcards
+    ALTER COLUMN next_review_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING next_review_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN learned_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING learned_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE users
+    ALTER COLUMN premium_expires_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING premium_expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN premium_reminded_for TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING premium_reminded_for AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN premium_expired_notified_for TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING premium_expired_notified_for AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN streak_warning_sent_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING streak_warning_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN streak_warnings_snoozed_until TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING streak_warnings_snoozed_until AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN daily_reminder_sent_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING daily_reminder_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN word_of_day_sent_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING word_of_day_sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN weekly_target_completed_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING weekly_target_completed_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN weekly_target_reminded_at TYPE TIMESTAMP WITHOUT TIME ZONE
+        USING weekly_target_reminded_at AT TIME ZONE current_setting('TimeZone');
+
+-- +goose StatementEnd